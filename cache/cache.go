@@ -0,0 +1,93 @@
+package cache
+
+import (
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+	"warunk-bem/domain"
+)
+
+const DefaultTTL = 10 * time.Minute
+
+// Namespace berversi. Perubahan stok atau data produk menaikkan NamespaceProduk,
+// perubahan saldo, profil atau favorite menaikkan UserNamespace milik user tersebut.
+const NamespaceProduk = "produk"
+
+func UserNamespace(userID string) string {
+	return "user:" + userID
+}
+
+// Key menyusun key cache dari versi setiap namespace dan bagian key lainnya,
+// sehingga key lama otomatis tidak terbaca setelah salah satu namespace di-Bump
+func Key(ctx context.Context, c domain.Cache, namespaces []string, parts ...interface{}) string {
+	segments := make([]string, 0, len(namespaces)+len(parts))
+	for _, ns := range namespaces {
+		version, err := c.Version(ctx, ns)
+		if err != nil {
+			log.Println("cannot read cache version: ", err.Error())
+		}
+		segments = append(segments, fmt.Sprintf("%s@%d", ns, version))
+	}
+
+	for _, part := range parts {
+		segments = append(segments, fmt.Sprint(part))
+	}
+
+	return strings.Join(segments, ":")
+}
+
+// Hash meringkas parameter query (filter, sort, dll) menjadi bagian key yang pendek
+func Hash(params ...interface{}) string {
+	b, _ := json.Marshal(params)
+	sum := sha1.Sum(b)
+	return hex.EncodeToString(sum[:8])
+}
+
+// Remember membaca key dari cache, jika tidak ada memanggil load lalu menyimpan hasilnya.
+// Cache yang gagal dibaca atau ditulis tidak menggagalkan request.
+func Remember[T any](ctx context.Context, c domain.Cache, key string, ttl time.Duration, load func() (T, error)) (T, error) {
+	var res T
+
+	if val, ok, err := c.Get(ctx, key); err == nil && ok {
+		if err := json.Unmarshal(val, &res); err == nil {
+			return res, nil
+		}
+	}
+
+	res, err := load()
+	if err != nil {
+		return res, err
+	}
+
+	if b, err := json.Marshal(res); err == nil {
+		if err := c.Set(ctx, key, b, ttl); err != nil {
+			log.Println("cannot write cache: ", err.Error())
+		}
+	}
+
+	return res, nil
+}
+
+// Invalidate menaikkan versi namespace sehingga semua key di bawahnya tidak terpakai lagi
+func Invalidate(ctx context.Context, c domain.Cache, namespaces ...string) {
+	for _, ns := range namespaces {
+		if err := c.Bump(ctx, ns); err != nil {
+			log.Println("cannot invalidate cache: ", err.Error())
+		}
+	}
+}
+
+// InvalidateProduk dipanggil setiap stok atau data produk berubah
+func InvalidateProduk(ctx context.Context, c domain.Cache) {
+	Invalidate(ctx, c, NamespaceProduk)
+}
+
+// InvalidateUser dipanggil setiap saldo atau data milik user berubah
+func InvalidateUser(ctx context.Context, c domain.Cache, userID string) {
+	Invalidate(ctx, c, UserNamespace(userID))
+}
@@ -0,0 +1,43 @@
+package http
+
+import (
+	"net/http"
+	"warunk-bem/domain"
+	"warunk-bem/dtos"
+
+	"github.com/gin-gonic/gin"
+)
+
+type CacheHandler struct {
+	Cache domain.Cache
+}
+
+func NewCacheHandler(protectedAdmin *gin.RouterGroup, c domain.Cache) {
+	handler := &CacheHandler{
+		Cache: c,
+	}
+
+	protectedAdmin = protectedAdmin.Group("/cache")
+	protectedAdmin.GET("/stats", handler.Stats)
+}
+
+// Stats godoc
+// @Summary      Get Cache Stats
+// @Description  Hit rate and operation counters since the server started
+// @Tags         Admin - Cache
+// @Produce      json
+// @Success      200 {object} dtos.CacheStatsOKResponse
+// @Failure      401 {object} dtos.UnauthorizedResponse
+// @Failure      403 {object} dtos.ForbiddenResponse
+// @Router       /cache/stats [get]
+// @Security BearerAuth
+func (ch *CacheHandler) Stats(c *gin.Context) {
+	c.JSON(
+		http.StatusOK,
+		dtos.NewResponse(
+			http.StatusOK,
+			"Success Get Cache Stats",
+			ch.Cache.Stats(),
+		),
+	)
+}
@@ -0,0 +1,124 @@
+package cache
+
+import (
+	"context"
+	"sync"
+	"time"
+	"warunk-bem/domain"
+)
+
+type memoryEntry struct {
+	value     []byte
+	expiresAt time.Time
+}
+
+// memorySweepInterval jarak minimal antar pembersihan entry kedaluwarsa saat Set
+const memorySweepInterval = time.Minute
+
+// memoryCache menyimpan cache di memori proses, cocok untuk test dan development tanpa Redis
+type memoryCache struct {
+	mu        sync.RWMutex
+	entries   map[string]memoryEntry
+	versions  map[string]int64
+	now       func() time.Time
+	lastSweep time.Time
+	metrics
+}
+
+func NewMemoryCache() domain.Cache {
+	return &memoryCache{
+		entries:   make(map[string]memoryEntry),
+		versions:  make(map[string]int64),
+		now:       time.Now,
+		lastSweep: time.Now(),
+	}
+}
+
+func (e memoryEntry) expired(now time.Time) bool {
+	return !e.expiresAt.IsZero() && now.After(e.expiresAt)
+}
+
+func (mc *memoryCache) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	mc.mu.RLock()
+	entry, ok := mc.entries[key]
+	mc.mu.RUnlock()
+
+	if !ok || entry.expired(mc.now()) {
+		if ok {
+			// Cek ulang di bawah lock agar entry baru dari Set bersamaan tidak ikut terhapus
+			mc.mu.Lock()
+			if current, ok := mc.entries[key]; ok && current.expired(mc.now()) {
+				delete(mc.entries, key)
+			}
+			mc.mu.Unlock()
+		}
+		mc.misses.Add(1)
+		return nil, false, nil
+	}
+
+	mc.hits.Add(1)
+	return append([]byte(nil), entry.value...), true, nil
+}
+
+func (mc *memoryCache) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	entry := memoryEntry{value: append([]byte(nil), value...)}
+	if ttl > 0 {
+		entry.expiresAt = mc.now().Add(ttl)
+	}
+
+	mc.mu.Lock()
+	mc.entries[key] = entry
+	mc.sweep()
+	mc.mu.Unlock()
+
+	mc.sets.Add(1)
+	return nil
+}
+
+// sweep menghapus entry kedaluwarsa paling sering sekali per memorySweepInterval.
+// Setelah Bump pembaca pindah ke key berversi baru sehingga key lama tidak pernah
+// dibaca lagi dan tidak terhapus lewat Get, mc.mu harus sudah dikunci pemanggil.
+func (mc *memoryCache) sweep() {
+	now := mc.now()
+	if now.Sub(mc.lastSweep) < memorySweepInterval {
+		return
+	}
+	mc.lastSweep = now
+
+	for key, entry := range mc.entries {
+		if entry.expired(now) {
+			delete(mc.entries, key)
+		}
+	}
+}
+
+func (mc *memoryCache) Delete(ctx context.Context, keys ...string) error {
+	mc.mu.Lock()
+	for _, key := range keys {
+		delete(mc.entries, key)
+	}
+	mc.mu.Unlock()
+
+	mc.deletes.Add(int64(len(keys)))
+	return nil
+}
+
+func (mc *memoryCache) Version(ctx context.Context, namespace string) (int64, error) {
+	mc.mu.RLock()
+	defer mc.mu.RUnlock()
+
+	return mc.versions[namespace], nil
+}
+
+func (mc *memoryCache) Bump(ctx context.Context, namespace string) error {
+	mc.mu.Lock()
+	mc.versions[namespace]++
+	mc.mu.Unlock()
+
+	mc.bumps.Add(1)
+	return nil
+}
+
+func (mc *memoryCache) Stats() domain.CacheStats {
+	return mc.metrics.stats("memory")
+}
@@ -0,0 +1,158 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// fakeClock dipakai sebagai now pada memoryCache agar kedaluwarsa bisa diuji tanpa menunggu
+type fakeClock struct {
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	return c.now
+}
+
+func (c *fakeClock) Advance(d time.Duration) {
+	c.now = c.now.Add(d)
+}
+
+func newTestMemoryCache() (*memoryCache, *fakeClock) {
+	clock := &fakeClock{now: time.Date(2024, 1, 1, 8, 0, 0, 0, time.UTC)}
+	mc := NewMemoryCache().(*memoryCache)
+	mc.now = clock.Now
+	mc.lastSweep = clock.now
+	return mc, clock
+}
+
+func TestMemoryCacheGet(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name    string
+		ttl     time.Duration
+		advance time.Duration
+		wantHit bool
+	}{
+		{name: "fresh", ttl: time.Minute, advance: 30 * time.Second, wantHit: true},
+		{name: "expired", ttl: time.Minute, advance: 2 * time.Minute, wantHit: false},
+		{name: "without ttl", ttl: 0, advance: 24 * time.Hour, wantHit: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mc, clock := newTestMemoryCache()
+			_ = mc.Set(ctx, "key", []byte("value"), tt.ttl)
+			clock.Advance(tt.advance)
+
+			val, ok, err := mc.Get(ctx, "key")
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if ok != tt.wantHit {
+				t.Fatalf("hit = %v, want %v", ok, tt.wantHit)
+			}
+			if ok && string(val) != "value" {
+				t.Fatalf("value = %q, want %q", val, "value")
+			}
+			if _, stored := mc.entries["key"]; stored != tt.wantHit {
+				t.Fatalf("entry stored = %v after get, want %v", stored, tt.wantHit)
+			}
+		})
+	}
+}
+
+func TestMemoryCacheSweepsBumpedKeys(t *testing.T) {
+	ctx := context.Background()
+	mc, clock := newTestMemoryCache()
+
+	// Setiap Bump membuat key berversi baru, key lama tidak pernah dibaca lagi
+	for i := 0; i < 5; i++ {
+		key := Key(ctx, mc, []string{NamespaceProduk}, "list")
+		_ = mc.Set(ctx, key, []byte("produk"), time.Minute)
+		_ = mc.Bump(ctx, NamespaceProduk)
+	}
+	_ = mc.Set(ctx, "permanent", []byte("x"), 0)
+
+	if len(mc.entries) != 6 {
+		t.Fatalf("entries = %d, want 6 before expiry", len(mc.entries))
+	}
+
+	// Belum lewat interval sweep, entry kedaluwarsa masih tersimpan
+	clock.Advance(memorySweepInterval / 2)
+	_ = mc.Set(ctx, "fresh-1", []byte("x"), time.Hour)
+	if len(mc.entries) != 7 {
+		t.Fatalf("entries = %d, want 7 before sweep interval", len(mc.entries))
+	}
+
+	clock.Advance(memorySweepInterval)
+	_ = mc.Set(ctx, "fresh-2", []byte("x"), time.Hour)
+
+	for _, key := range []string{"permanent", "fresh-1", "fresh-2"} {
+		if _, ok := mc.entries[key]; !ok {
+			t.Errorf("entry %q was swept, want kept", key)
+		}
+	}
+	if len(mc.entries) != 3 {
+		t.Fatalf("entries = %d, want 3 after sweep", len(mc.entries))
+	}
+}
+
+func TestRemember(t *testing.T) {
+	ctx := context.Background()
+	mc, clock := newTestMemoryCache()
+
+	loads := 0
+	load := func() (int, error) {
+		loads++
+		return loads, nil
+	}
+
+	for i := 0; i < 2; i++ {
+		got, err := Remember(ctx, mc, "angka", time.Minute, load)
+		if err != nil || got != 1 {
+			t.Fatalf("call %d: got %d, %v, want cached 1", i, got, err)
+		}
+	}
+
+	clock.Advance(2 * time.Minute)
+	got, _ := Remember(ctx, mc, "angka", time.Minute, load)
+	if got != 2 {
+		t.Fatalf("got %d after expiry, want reload 2", got)
+	}
+
+	_, err := Remember(ctx, mc, "gagal", time.Minute, func() (int, error) {
+		return 0, errors.New("mongo down")
+	})
+	if err == nil {
+		t.Fatal("expected load error")
+	}
+	if _, ok, _ := mc.Get(ctx, "gagal"); ok {
+		t.Fatal("failed load must not be cached")
+	}
+}
+
+func TestKeyChangesAfterInvalidate(t *testing.T) {
+	ctx := context.Background()
+	mc, _ := newTestMemoryCache()
+	userID := "user-1"
+
+	before := Key(ctx, mc, []string{NamespaceProduk, UserNamespace(userID)}, "detail", 1)
+	InvalidateUser(ctx, mc, "user-2")
+	if got := Key(ctx, mc, []string{NamespaceProduk, UserNamespace(userID)}, "detail", 1); got != before {
+		t.Fatalf("key changed after invalidating another user: %q -> %q", before, got)
+	}
+
+	InvalidateUser(ctx, mc, userID)
+	if got := Key(ctx, mc, []string{NamespaceProduk, UserNamespace(userID)}, "detail", 1); got == before {
+		t.Fatalf("key %q unchanged after invalidating user", got)
+	}
+
+	stats := mc.Stats()
+	if stats.Driver != "memory" || stats.Bumps != 2 {
+		t.Fatalf("stats = %+v, want memory driver with 2 bumps", stats)
+	}
+}
@@ -0,0 +1,34 @@
+package cache
+
+import (
+	"sync/atomic"
+	"warunk-bem/domain"
+)
+
+// metrics dipakai bersama oleh semua implementasi cache
+type metrics struct {
+	hits    atomic.Int64
+	misses  atomic.Int64
+	sets    atomic.Int64
+	deletes atomic.Int64
+	bumps   atomic.Int64
+	errors  atomic.Int64
+}
+
+func (m *metrics) stats(driver string) domain.CacheStats {
+	stats := domain.CacheStats{
+		Driver:  driver,
+		Hits:    m.hits.Load(),
+		Misses:  m.misses.Load(),
+		Sets:    m.sets.Load(),
+		Deletes: m.deletes.Load(),
+		Bumps:   m.bumps.Load(),
+		Errors:  m.errors.Load(),
+	}
+
+	if total := stats.Hits + stats.Misses; total > 0 {
+		stats.HitRate = float64(stats.Hits) / float64(total)
+	}
+
+	return stats
+}
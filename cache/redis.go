@@ -0,0 +1,90 @@
+package cache
+
+import (
+	"context"
+	"time"
+	"warunk-bem/domain"
+
+	"github.com/go-redis/redis/v8"
+)
+
+const versionKeyPrefix = "cache_version:"
+
+type redisCache struct {
+	client *redis.Client
+	metrics
+}
+
+func NewRedisCache(client *redis.Client) domain.Cache {
+	return &redisCache{client: client}
+}
+
+func (rc *redisCache) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	val, err := rc.client.Get(ctx, key).Bytes()
+	if err == redis.Nil {
+		rc.misses.Add(1)
+		return nil, false, nil
+	}
+	if err != nil {
+		rc.errors.Add(1)
+		rc.misses.Add(1)
+		return nil, false, err
+	}
+
+	rc.hits.Add(1)
+	return val, true, nil
+}
+
+func (rc *redisCache) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	err := rc.client.Set(ctx, key, value, ttl).Err()
+	if err != nil {
+		rc.errors.Add(1)
+		return err
+	}
+
+	rc.sets.Add(1)
+	return nil
+}
+
+func (rc *redisCache) Delete(ctx context.Context, keys ...string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+
+	err := rc.client.Del(ctx, keys...).Err()
+	if err != nil {
+		rc.errors.Add(1)
+		return err
+	}
+
+	rc.deletes.Add(int64(len(keys)))
+	return nil
+}
+
+func (rc *redisCache) Version(ctx context.Context, namespace string) (int64, error) {
+	version, err := rc.client.Get(ctx, versionKeyPrefix+namespace).Int64()
+	if err == redis.Nil {
+		return 0, nil
+	}
+	if err != nil {
+		rc.errors.Add(1)
+		return 0, err
+	}
+
+	return version, nil
+}
+
+func (rc *redisCache) Bump(ctx context.Context, namespace string) error {
+	err := rc.client.Incr(ctx, versionKeyPrefix+namespace).Err()
+	if err != nil {
+		rc.errors.Add(1)
+		return err
+	}
+
+	rc.bumps.Add(1)
+	return nil
+}
+
+func (rc *redisCache) Stats() domain.CacheStats {
+	return rc.metrics.stats("redis")
+}
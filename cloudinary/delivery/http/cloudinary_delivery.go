@@ -2,17 +2,16 @@ package http
 
 import (
 	"net/http"
-	"warunk-bem/cloudinary/usecase"
 	"warunk-bem/domain"
 	"warunk-bem/dtos"
 
 	"github.com/gin-gonic/gin"
 )
 
-func FileUpload() gin.HandlerFunc {
+func FileUpload(mu domain.ClourdinaryUsecase) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		//upload
-		formfile, _, err := c.Request.FormFile("file")
+		formfile, header, err := c.Request.FormFile("file")
 		if err != nil {
 			c.JSON(
 				http.StatusInternalServerError,
@@ -24,7 +23,7 @@ func FileUpload() gin.HandlerFunc {
 			return
 		}
 
-		uploadUrl, err := usecase.NewMediaUpload().FileUpload(domain.File{File: formfile})
+		uploadUrl, err := mu.FileUpload(domain.File{File: formfile, Filename: header.Filename, Size: header.Size})
 		if err != nil {
 			c.JSON(
 				http.StatusInternalServerError,
@@ -46,7 +45,7 @@ func FileUpload() gin.HandlerFunc {
 	}
 }
 
-func RemoteUpload() gin.HandlerFunc {
+func RemoteUpload(mu domain.ClourdinaryUsecase) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		var url domain.Url
 
@@ -62,7 +61,7 @@ func RemoteUpload() gin.HandlerFunc {
 			return
 		}
 
-		uploadUrl, err := usecase.NewMediaUpload().RemoteUpload(url)
+		uploadUrl, err := mu.RemoteUpload(url)
 		if err != nil {
 			c.JSON(
 				http.StatusInternalServerError,
@@ -1,48 +1,178 @@
 package usecase
 
 import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"syscall"
+	"time"
 	"warunk-bem/domain"
-	"warunk-bem/helpers"
 
 	"github.com/go-playground/validator/v10"
 )
 
 var (
 	validate = validator.New()
+
+	allowedMediaTypes = map[string]bool{
+		"image/jpeg": true,
+		"image/png":  true,
+		"image/webp": true,
+	}
+)
+
+const (
+	uploadTimeout     = 30 * time.Second
+	maxRemoteRedirect = 3
 )
 
-type media struct{}
+// sharedAddressSpace (RFC 6598) tidak termasuk IsPrivate tetapi dipakai jaringan internal penyedia cloud
+var sharedAddressSpace = &net.IPNet{IP: net.IPv4(100, 64, 0, 0), Mask: net.CIDRMask(10, 32)}
+
+// remoteClient dipakai untuk mengunduh file dari URL kiriman user, alamat internal ditolak
+// setelah DNS di-resolve agar server tidak bisa dipakai mengakses jaringan internal (SSRF)
+var remoteClient = &http.Client{
+	Transport: &http.Transport{
+		Proxy: nil,
+		DialContext: (&net.Dialer{
+			Timeout: 10 * time.Second,
+			Control: rejectInternalAddress,
+		}).DialContext,
+		TLSHandshakeTimeout: 10 * time.Second,
+	},
+	CheckRedirect: func(req *http.Request, via []*http.Request) error {
+		if len(via) >= maxRemoteRedirect {
+			return fmt.Errorf("redirect maksimal %d kali", maxRemoteRedirect)
+		}
+		return checkRemoteScheme(req.URL)
+	},
+}
+
+type media struct {
+	Storage domain.MediaStorage
+	MaxSize int64
+}
 
-func NewMediaUpload() domain.ClourdinaryUsecase {
-	return &media{}
+func NewMediaUpload(storage domain.MediaStorage, maxSize int64) domain.ClourdinaryUsecase {
+	return &media{
+		Storage: storage,
+		MaxSize: maxSize,
+	}
 }
 
-func (*media) FileUpload(file domain.File) (string, error) {
+func (m *media) FileUpload(file domain.File) (string, error) {
 	//validate
 	err := validate.Struct(file)
 	if err != nil {
 		return "", err
 	}
 
-	//upload
-	uploadUrl, err := helpers.ImageUploadHelper(file.File)
-	if err != nil {
-		return "", err
+	if file.Size > m.MaxSize {
+		return "", fmt.Errorf("ukuran file maksimal %d KB", m.MaxSize>>10)
 	}
-	return uploadUrl, nil
+
+	//upload
+	return m.upload(file.File, file.Filename)
 }
 
-func (*media) RemoteUpload(url domain.Url) (string, error) {
+func (m *media) RemoteUpload(url domain.Url) (string, error) {
 	//validate
 	err := validate.Struct(url)
 	if err != nil {
 		return "", err
 	}
 
+	ctx, cancel := context.WithTimeout(context.Background(), uploadTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url.Url, nil)
+	if err != nil {
+		return "", err
+	}
+
+	err = checkRemoteScheme(req.URL)
+	if err != nil {
+		return "", err
+	}
+
+	resp, err := remoteClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("gagal mengunduh file: %s", resp.Status)
+	}
+
 	//upload
-	uploadUrl, errUrl := helpers.ImageUploadHelper(url.Url)
-	if errUrl != nil {
+	return m.upload(resp.Body, url.Url)
+}
+
+func (m *media) Destroy(url string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), uploadTimeout)
+	defer cancel()
+
+	return m.Storage.Delete(ctx, url)
+}
+
+// upload membaca file dengan batas ukuran lalu memeriksa tipe konten dari isi file,
+// bukan dari nama file, sebelum diteruskan ke storage
+func (m *media) upload(r io.Reader, filename string) (string, error) {
+	data, err := io.ReadAll(io.LimitReader(r, m.MaxSize+1))
+	if err != nil {
 		return "", err
 	}
-	return uploadUrl, nil
+
+	if int64(len(data)) > m.MaxSize {
+		return "", fmt.Errorf("ukuran file maksimal %d KB", m.MaxSize>>10)
+	}
+
+	if len(data) == 0 {
+		return "", errors.New("file kosong")
+	}
+
+	contentType := http.DetectContentType(data)
+	if !allowedMediaTypes[contentType] {
+		return "", errors.New("format file tidak diizinkan, gunakan gambar JPEG, PNG atau WEBP")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), uploadTimeout)
+	defer cancel()
+
+	return m.Storage.Upload(ctx, bytes.NewReader(data), filename, contentType)
+}
+
+func checkRemoteScheme(u *url.URL) error {
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return errors.New("url harus memakai http atau https")
+	}
+
+	return nil
+}
+
+// rejectInternalAddress dijalankan untuk setiap koneksi dengan alamat IP hasil resolve DNS,
+// termasuk koneksi dari redirect
+func rejectInternalAddress(network string, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+
+	ip := net.ParseIP(host)
+	if ip == nil {
+		return fmt.Errorf("alamat %s tidak valid", host)
+	}
+
+	if ip.IsLoopback() || ip.IsPrivate() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() ||
+		ip.IsInterfaceLocalMulticast() || ip.IsMulticast() || ip.IsUnspecified() || sharedAddressSpace.Contains(ip) {
+		return errors.New("url mengarah ke alamat internal")
+	}
+
+	return nil
 }
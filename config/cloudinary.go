@@ -1,40 +1,23 @@
 package config
 
 import (
-	"log"
 	"os"
-
-	"github.com/joho/godotenv"
 )
 
+// Variabel environment sudah dimuat oleh godotenv di main, cukup dibaca dari os
+
 func EnvCloudName() string {
-	err := godotenv.Load()
-	if err != nil {
-		log.Fatal("Error loading .env file")
-	}
 	return os.Getenv("CLOUDINARY_CLOUD_NAME")
 }
 
 func EnvCloudAPIKey() string {
-	err := godotenv.Load()
-	if err != nil {
-		log.Fatal("Error loading .env file")
-	}
 	return os.Getenv("CLOUDINARY_API_KEY")
 }
 
 func EnvCloudAPISecret() string {
-	err := godotenv.Load()
-	if err != nil {
-		log.Fatal("Error loading .env file")
-	}
 	return os.Getenv("CLOUDINARY_API_SECRET")
 }
 
 func EnvCloudUploadFolder() string {
-	err := godotenv.Load()
-	if err != nil {
-		log.Fatal("Error loading .env file")
-	}
 	return os.Getenv("CLOUDINARY_UPLOAD_FOLDER")
 }
@@ -0,0 +1,48 @@
+package config
+
+import (
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// PaymentProviderFake hanya untuk development, harus dipilih eksplisit lewat PAYMENT_PROVIDER
+	PaymentProviderFake = "fake"
+
+	defaultPaymentExpiry    = 30 * time.Minute
+	defaultPaymentMinAmount = 10000
+)
+
+type Payment struct {
+	// Provider kosong berarti pembayaran online tidak aktif
+	Provider      string
+	WebhookSecret string
+	Expiry        time.Duration
+	MinAmount     float64
+	// Simulate membuka endpoint simulasi webhook untuk admin, hanya saat APP_ENV=development
+	Simulate bool
+}
+
+func EnvPayment() Payment {
+	provider := strings.ToLower(strings.TrimSpace(os.Getenv("PAYMENT_PROVIDER")))
+
+	expiry, err := time.ParseDuration(os.Getenv("PAYMENT_EXPIRY"))
+	if err != nil || expiry <= 0 {
+		expiry = defaultPaymentExpiry
+	}
+
+	minAmount, err := strconv.ParseFloat(os.Getenv("PAYMENT_MIN_AMOUNT"), 64)
+	if err != nil || minAmount <= 0 {
+		minAmount = defaultPaymentMinAmount
+	}
+
+	return Payment{
+		Provider:      provider,
+		WebhookSecret: os.Getenv("PAYMENT_WEBHOOK_SECRET"),
+		Expiry:        expiry,
+		MinAmount:     minAmount,
+		Simulate:      strings.ToLower(os.Getenv("APP_ENV")) == "development",
+	}
+}
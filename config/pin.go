@@ -0,0 +1,43 @@
+package config
+
+import (
+	"os"
+	"strconv"
+	"time"
+)
+
+const (
+	defaultPinMaxAttempts       = 5
+	defaultPinLockDuration      = 15 * time.Minute
+	defaultPinCheckoutThreshold = 50000
+)
+
+type Pin struct {
+	MaxAttempts  int64
+	LockDuration time.Duration
+	// CheckoutThreshold total belanja di atas nilai ini wajib memakai PIN jika user sudah mengatur PIN
+	CheckoutThreshold float64
+}
+
+func EnvPin() Pin {
+	maxAttempts, err := strconv.ParseInt(os.Getenv("PIN_MAX_ATTEMPTS"), 10, 64)
+	if err != nil || maxAttempts <= 0 {
+		maxAttempts = defaultPinMaxAttempts
+	}
+
+	lockDuration, err := time.ParseDuration(os.Getenv("PIN_LOCK_DURATION"))
+	if err != nil || lockDuration <= 0 {
+		lockDuration = defaultPinLockDuration
+	}
+
+	checkoutThreshold, err := strconv.ParseFloat(os.Getenv("PIN_CHECKOUT_THRESHOLD"), 64)
+	if err != nil || checkoutThreshold < 0 {
+		checkoutThreshold = defaultPinCheckoutThreshold
+	}
+
+	return Pin{
+		MaxAttempts:       maxAttempts,
+		LockDuration:      lockDuration,
+		CheckoutThreshold: checkoutThreshold,
+	}
+}
@@ -0,0 +1,24 @@
+package config
+
+import (
+	"os"
+	"time"
+)
+
+const defaultPreOrderHoldDuration = 48 * time.Hour
+
+type PreOrder struct {
+	// HoldDuration lama saldo pre-order ditahan menunggu sesi warunk berikutnya dibuka
+	HoldDuration time.Duration
+}
+
+func EnvPreOrder() PreOrder {
+	holdDuration, err := time.ParseDuration(os.Getenv("PREORDER_HOLD_DURATION"))
+	if err != nil || holdDuration <= 0 {
+		holdDuration = defaultPreOrderHoldDuration
+	}
+
+	return PreOrder{
+		HoldDuration: holdDuration,
+	}
+}
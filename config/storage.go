@@ -0,0 +1,80 @@
+package config
+
+import (
+	"os"
+	"strconv"
+	"strings"
+)
+
+const (
+	StorageDriverCloudinary = "cloudinary"
+	StorageDriverLocal      = "local"
+	StorageDriverS3         = "s3"
+
+	defaultMediaMaxSize = 2 << 20
+)
+
+type Storage struct {
+	Driver  string
+	MaxSize int64
+
+	LocalDir     string
+	LocalURL     string
+	LocalRoute   string
+	S3Endpoint   string
+	S3Region     string
+	S3Bucket     string
+	S3AccessKey  string
+	S3SecretKey  string
+	S3PublicURL  string
+	S3PathStyle  bool
+	S3UploadPath string
+}
+
+func EnvStorage() Storage {
+	driver := strings.ToLower(os.Getenv("STORAGE_DRIVER"))
+	if driver == "" {
+		driver = StorageDriverCloudinary
+	}
+
+	maxSize, err := strconv.ParseInt(os.Getenv("MEDIA_MAX_SIZE"), 10, 64)
+	if err != nil || maxSize <= 0 {
+		maxSize = defaultMediaMaxSize
+	}
+
+	localDir := os.Getenv("LOCAL_STORAGE_DIR")
+	if localDir == "" {
+		localDir = "uploads"
+	}
+
+	localRoute := os.Getenv("LOCAL_STORAGE_ROUTE")
+	if localRoute == "" {
+		localRoute = "/media"
+	}
+
+	s3Region := os.Getenv("S3_REGION")
+	if s3Region == "" {
+		s3Region = "us-east-1"
+	}
+
+	pathStyle, err := strconv.ParseBool(os.Getenv("S3_USE_PATH_STYLE"))
+	if err != nil {
+		pathStyle = true
+	}
+
+	return Storage{
+		Driver:       driver,
+		MaxSize:      maxSize,
+		LocalDir:     localDir,
+		LocalURL:     strings.TrimSuffix(os.Getenv("LOCAL_STORAGE_URL"), "/"),
+		LocalRoute:   localRoute,
+		S3Endpoint:   strings.TrimSuffix(os.Getenv("S3_ENDPOINT"), "/"),
+		S3Region:     s3Region,
+		S3Bucket:     os.Getenv("S3_BUCKET"),
+		S3AccessKey:  os.Getenv("S3_ACCESS_KEY"),
+		S3SecretKey:  os.Getenv("S3_SECRET_KEY"),
+		S3PublicURL:  strings.TrimSuffix(os.Getenv("S3_PUBLIC_URL"), "/"),
+		S3PathStyle:  pathStyle,
+		S3UploadPath: strings.Trim(os.Getenv("S3_UPLOAD_PATH"), "/"),
+	}
+}
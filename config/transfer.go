@@ -0,0 +1,41 @@
+package config
+
+import (
+	"os"
+	"strconv"
+)
+
+const (
+	defaultTransferMinAmount  = 1000
+	defaultTransferDailyLimit = 500000
+	defaultTransferDailyCount = 20
+)
+
+type Transfer struct {
+	MinAmount  float64
+	DailyLimit float64
+	DailyCount int64
+}
+
+func EnvTransfer() Transfer {
+	minAmount, err := strconv.ParseFloat(os.Getenv("TRANSFER_MIN_AMOUNT"), 64)
+	if err != nil || minAmount <= 0 {
+		minAmount = defaultTransferMinAmount
+	}
+
+	dailyLimit, err := strconv.ParseFloat(os.Getenv("TRANSFER_DAILY_LIMIT"), 64)
+	if err != nil || dailyLimit <= 0 {
+		dailyLimit = defaultTransferDailyLimit
+	}
+
+	dailyCount, err := strconv.ParseInt(os.Getenv("TRANSFER_DAILY_COUNT"), 10, 64)
+	if err != nil || dailyCount <= 0 {
+		dailyCount = defaultTransferDailyCount
+	}
+
+	return Transfer{
+		MinAmount:  minAmount,
+		DailyLimit: dailyLimit,
+		DailyCount: dailyCount,
+	}
+}
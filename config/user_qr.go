@@ -0,0 +1,41 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"time"
+)
+
+const defaultUserQRTTL = time.Minute
+
+type UserQR struct {
+	// Secret dipakai untuk tanda tangan HMAC QR, wajib diisi dan berbeda dari SECRET_JWT
+	Secret string
+	TTL    time.Duration
+}
+
+func EnvUserQR() UserQR {
+	ttl, err := time.ParseDuration(os.Getenv("USER_QR_TTL"))
+	if err != nil || ttl <= 0 {
+		ttl = defaultUserQRTTL
+	}
+
+	return UserQR{
+		Secret: os.Getenv("USER_QR_SECRET"),
+		TTL:    ttl,
+	}
+}
+
+// Validate memastikan QR tidak ditandatangani dengan secret JWT, sehingga bocornya
+// salah satu secret tidak bisa dipakai untuk memalsukan yang lain
+func (c UserQR) Validate() error {
+	if c.Secret == "" {
+		return errors.New("USER_QR_SECRET is required")
+	}
+
+	if c.Secret == os.Getenv("SECRET_JWT") {
+		return errors.New("USER_QR_SECRET must be different from SECRET_JWT")
+	}
+
+	return nil
+}
@@ -0,0 +1,28 @@
+package config
+
+import "testing"
+
+func TestUserQRValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		secret  string
+		jwt     string
+		wantErr bool
+	}{
+		{name: "separate secret", secret: "qr-secret", jwt: "jwt-secret"},
+		{name: "missing secret", secret: "", jwt: "jwt-secret", wantErr: true},
+		{name: "reuses jwt secret", secret: "jwt-secret", jwt: "jwt-secret", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("USER_QR_SECRET", tt.secret)
+			t.Setenv("SECRET_JWT", tt.jwt)
+
+			err := EnvUserQR().Validate()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,35 @@
+package config
+
+import (
+	"os"
+	"strconv"
+	"time"
+)
+
+const (
+	defaultWithdrawalMinAmount = 10000
+	defaultWithdrawalExpiry    = 72 * time.Hour
+)
+
+type Withdrawal struct {
+	MinAmount float64
+	// Expiry batas menunggu persetujuan, setelah disetujui saldo tetap ditahan sampai dicairkan atau ditolak
+	Expiry time.Duration
+}
+
+func EnvWithdrawal() Withdrawal {
+	minAmount, err := strconv.ParseFloat(os.Getenv("WITHDRAWAL_MIN_AMOUNT"), 64)
+	if err != nil || minAmount <= 0 {
+		minAmount = defaultWithdrawalMinAmount
+	}
+
+	expiry, err := time.ParseDuration(os.Getenv("WITHDRAWAL_EXPIRY"))
+	if err != nil || expiry <= 0 {
+		expiry = defaultWithdrawalExpiry
+	}
+
+	return Withdrawal{
+		MinAmount: minAmount,
+		Expiry:    expiry,
+	}
+}
@@ -4,18 +4,21 @@ import (
 	"context"
 	"net/http"
 	"strconv"
+	"time"
 	"warunk-bem/domain"
 	"warunk-bem/dtos"
 	"warunk-bem/middlewares"
 
 	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
 type DashboardHandler struct {
 	DashboardUsecase domain.DashboardUsecase
 }
 
-func NewDashboardHandler(router *gin.RouterGroup, du domain.DashboardUsecase) {
+func NewDashboardHandler(router *gin.RouterGroup, protectedAdmin *gin.RouterGroup, du domain.DashboardUsecase) {
 	handler := &DashboardHandler{
 		DashboardUsecase: du,
 	}
@@ -23,6 +26,9 @@ func NewDashboardHandler(router *gin.RouterGroup, du domain.DashboardUsecase) {
 	api := router.Group("/dashboard")
 
 	api.GET("", handler.GetDashboardData)
+
+	protectedAdmin = protectedAdmin.Group("/dashboard")
+	protectedAdmin.GET("/analytics", handler.GetAdminAnalytics)
 }
 
 func (dh *DashboardHandler) GetDashboardData(c *gin.Context) {
@@ -39,23 +45,37 @@ func (dh *DashboardHandler) GetDashboardData(c *gin.Context) {
 		return
 	}
 
-	rp, _ := strconv.ParseInt(c.Query("rp"), 10, 64)
-	p, _ := strconv.ParseInt(c.Query("p"), 10, 64)
+	rp, err := strconv.ParseInt(c.Query("rp"), 10, 64)
+	if err != nil || rp <= 0 {
+		rp = 25
+	}
+
+	p, err := strconv.ParseInt(c.Query("p"), 10, 64)
+	if err != nil || p <= 0 {
+		p = 1
+	}
 
-	filter := make(map[string]interface{})
-	// Set filter based on query parameters
-	// Example: filter["field"] = value
+	filter := bson.M{"deleted_at": bson.M{"$in": []interface{}{nil, primitive.Null{}}}}
+	if category := c.Query("category"); category != "" {
+		filter["category"] = category
+	}
 
-	// Set sorting options
-	// setsort := make(map[string]interface{})
-	// Example: setsort["field"] = 1 // 1 for ascending, -1 for descending
+	var setsort interface{}
+	switch c.Query("sort") {
+	case "price_asc":
+		setsort = bson.D{{Key: "price", Value: 1}, {Key: "_id", Value: 1}}
+	case "price_desc":
+		setsort = bson.D{{Key: "price", Value: -1}, {Key: "_id", Value: 1}}
+	case "newest":
+		setsort = bson.D{{Key: "created_at", Value: -1}}
+	}
 
 	ctx := c.Request.Context()
 	if ctx == nil {
 		ctx = context.Background()
 	}
 
-	data, err := dh.DashboardUsecase.GetDashboardData(ctx, userID, rp, p, filter, nil)
+	data, err := dh.DashboardUsecase.GetDashboardData(ctx, userID, rp, p, filter, setsort)
 	if err != nil {
 		c.JSON(
 			http.StatusBadRequest,
@@ -77,3 +97,83 @@ func (dh *DashboardHandler) GetDashboardData(c *gin.Context) {
 		),
 	)
 }
+
+const analyticsDateFormat = "2006-01-02"
+
+func (dh *DashboardHandler) GetAdminAnalytics(c *gin.Context) {
+	_, err := middlewares.IsAdmin(c)
+	if err != nil {
+		c.JSON(
+			http.StatusUnauthorized,
+			dtos.NewErrorResponse(
+				http.StatusUnauthorized,
+				"Unauthorized",
+				dtos.GetErrorData(err),
+			),
+		)
+		return
+	}
+
+	// Rentang default 30 hari terakhir termasuk hari ini
+	now := time.Now()
+	to := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.Local).AddDate(0, 0, 1)
+	from := to.AddDate(0, 0, -30)
+
+	if q := c.Query("from"); q != "" {
+		from, err = time.ParseInLocation(analyticsDateFormat, q, time.Local)
+		if err != nil {
+			c.JSON(
+				http.StatusBadRequest,
+				dtos.NewErrorResponse(
+					http.StatusBadRequest,
+					"Invalid from date, use YYYY-MM-DD",
+					err.Error(),
+				),
+			)
+			return
+		}
+	}
+
+	if q := c.Query("to"); q != "" {
+		to, err = time.ParseInLocation(analyticsDateFormat, q, time.Local)
+		if err != nil {
+			c.JSON(
+				http.StatusBadRequest,
+				dtos.NewErrorResponse(
+					http.StatusBadRequest,
+					"Invalid to date, use YYYY-MM-DD",
+					err.Error(),
+				),
+			)
+			return
+		}
+		to = to.AddDate(0, 0, 1)
+	}
+
+	ctx := c.Request.Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	data, err := dh.DashboardUsecase.GetAdminAnalytics(ctx, from, to, c.Query("interval"))
+	if err != nil {
+		c.JSON(
+			http.StatusBadRequest,
+			dtos.NewErrorResponse(
+				http.StatusBadRequest,
+				"Failed to get analytics",
+				err.Error(),
+			),
+		)
+		return
+	}
+
+	c.JSON(
+		http.StatusOK,
+		dtos.NewResponse(
+			http.StatusOK,
+			"Success",
+			data,
+		),
+	)
+}
@@ -2,71 +2,414 @@ package repository
 
 import (
 	"context"
+	"time"
 	"warunk-bem/domain"
 	"warunk-bem/mongo"
 
 	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
 type dashboardRepository struct {
-	DB               mongo.Database
-	ProdukCollection mongo.Collection
-	UserCollection   mongo.Collection
+	DB                  mongo.Database
+	ProdukCollection    mongo.Collection
+	UserCollection      mongo.Collection
+	TransaksiCollection mongo.Collection
 }
 
 const (
-	produkCollectionName = "produk" // Ganti dengan nama koleksi produk yang sesuai
-	userCollectionName   = "user"   // Ganti dengan nama koleksi user yang sesuai
+	produkCollectionName     = "produk" // Ganti dengan nama koleksi produk yang sesuai
+	userCollectionName       = "user"   // Ganti dengan nama koleksi user yang sesuai
+	transaksiCollectionName  = "transaksi"
+	userAmountCollectionName = "user_amount"
+	topUpCollectionName      = "topup"
+	withdrawalCollectionName = "withdrawal"
+	preOrderCollectionName   = "preorder"
 )
 
+// Pendapatan transaksi adalah harga setelah diskon, harga transaksi lama diisi salesPipeline
+var revenueExpr = bson.M{"$subtract": []interface{}{"$harga", bson.M{"$ifNull": []interface{}{"$discount", 0}}}}
+
+// Transaksi lama belum punya order_id, setiap baris dianggap satu order
+var orderExpr = bson.M{"$ifNull": []interface{}{"$order_id", "$_id"}}
+
+// Penjualan kasir tanpa pembeli tidak menyimpan user_id, penjualan kasir lama menyimpan ObjectID kosong
+var buyerExpr = bson.M{"$cond": []interface{}{bson.M{"$eq": []interface{}{"$user_id", primitive.NilObjectID}}, "$$REMOVE", "$user_id"}}
+
+var dateFormats = map[string]string{
+	domain.AnalyticsIntervalDay:   "%Y-%m-%d",
+	domain.AnalyticsIntervalWeek:  "%G-W%V",
+	domain.AnalyticsIntervalMonth: "%Y-%m",
+}
+
+func matchSales(from time.Time, to time.Time) bson.M {
+	return bson.M{
+		"$match": bson.M{
+			"created_at": bson.M{"$gte": from, "$lt": to},
+			"status":     "Berhasil",
+		},
+	}
+}
+
+// salesPipeline memilih transaksi berhasil lalu menambahkan stages. Transaksi lama belum menyimpan
+// harga, harganya dihitung dari harga produk saat ini dikali jumlah seperti riwayat transaksi
+func salesPipeline(from time.Time, to time.Time, stages []bson.M) []bson.M {
+	pipeline := []bson.M{
+		matchSales(from, to),
+		{
+			"$lookup": bson.M{
+				"from":         produkCollectionName,
+				"localField":   "produk_id",
+				"foreignField": "_id",
+				"as":           "harga_produk",
+			},
+		},
+		{
+			"$addFields": bson.M{
+				"harga": bson.M{"$cond": []interface{}{
+					bson.M{"$gt": []interface{}{bson.M{"$ifNull": []interface{}{"$harga", 0}}, 0}},
+					"$harga",
+					bson.M{"$multiply": []interface{}{
+						"$total",
+						bson.M{"$ifNull": []interface{}{bson.M{"$arrayElemAt": []interface{}{"$harga_produk.price", 0}}, 0}},
+					}},
+				}},
+			},
+		},
+	}
+
+	return append(pipeline, stages...)
+}
+
 func NewDashboardRepository(DB mongo.Database) domain.DashboardRepository {
 	return &dashboardRepository{
 		DB,
 		DB.Collection(produkCollectionName),
 		DB.Collection(userCollectionName),
+		DB.Collection(transaksiCollectionName),
 	}
 }
 
-func (r *dashboardRepository) DashboardGetAll() ([]domain.Produk, *domain.User, error) {
-	ctx := context.TODO()
-
-	// Aggregation pipeline
+// GetTerlaris menjumlahkan produk terjual dari transaksi berhasil sejak waktu tertentu
+func (r *dashboardRepository) GetTerlaris(ctx context.Context, since time.Time, limit int64) ([]domain.ProdukTerlaris, error) {
 	pipeline := []bson.M{
+		{
+			"$match": bson.M{
+				"created_at": bson.M{"$gte": since},
+				"status":     "Berhasil",
+			},
+		},
+		{
+			"$group": bson.M{
+				"_id":     "$produk_id",
+				"terjual": bson.M{"$sum": "$total"},
+			},
+		},
+		{
+			"$sort": bson.D{{Key: "terjual", Value: -1}, {Key: "_id", Value: 1}},
+		},
 		{
 			"$lookup": bson.M{
-				"from":         userCollectionName,
-				"localField":   "user_id",
+				"from":         produkCollectionName,
+				"localField":   "_id",
 				"foreignField": "_id",
-				"as":           "user",
+				"as":           "produk",
 			},
 		},
 		{
-			"$unwind": "$user",
+			"$unwind": "$produk",
+		},
+		{
+			"$match": bson.M{
+				"produk.deleted_at": bson.M{"$in": []interface{}{nil, primitive.Null{}}},
+			},
+		},
+		{
+			"$limit": limit,
 		},
 	}
 
-	// Execute aggregation
-	cursor, err := r.ProdukCollection.Aggregate(ctx, pipeline)
+	cursor, err := r.TransaksiCollection.Aggregate(ctx, pipeline)
 	if err != nil {
-		return nil, nil, err
+		return nil, err
 	}
 	defer cursor.Close(ctx)
 
-	// Process aggregation results
-	var produk []domain.Produk
-	var user *domain.User
+	terlaris := []domain.ProdukTerlaris{}
 	for cursor.Next(ctx) {
-		var result struct {
-			Produk domain.Produk `bson:"_id"`
-			User   *domain.User  `bson:"user"`
+		var t domain.ProdukTerlaris
+		if err := cursor.Decode(&t); err != nil {
+			return nil, err
 		}
+
+		terlaris = append(terlaris, t)
+	}
+
+	return terlaris, nil
+}
+
+func (r *dashboardRepository) GetSalesSummary(ctx context.Context, from time.Time, to time.Time) (*domain.AnalyticsSummary, error) {
+	pipeline := salesPipeline(from, to, []bson.M{
+		{
+			"$group": bson.M{
+				"_id":      nil,
+				"revenue":  bson.M{"$sum": revenueExpr},
+				"discount": bson.M{"$sum": "$discount"},
+				"items":    bson.M{"$sum": "$total"},
+				"orders":   bson.M{"$addToSet": orderExpr},
+				"buyers":   bson.M{"$addToSet": buyerExpr},
+			},
+		},
+		{
+			"$project": bson.M{
+				"revenue":       1,
+				"discount":      1,
+				"items":         1,
+				"orders":        bson.M{"$size": "$orders"},
+				"active_buyers": bson.M{"$size": "$buyers"},
+			},
+		},
+	})
+
+	cursor, err := r.TransaksiCollection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	summary := &domain.AnalyticsSummary{}
+	if cursor.Next(ctx) {
+		if err := cursor.Decode(summary); err != nil {
+			return nil, err
+		}
+	}
+
+	return summary, nil
+}
+
+func (r *dashboardRepository) GetSalesSeries(ctx context.Context, from time.Time, to time.Time, interval string) ([]domain.AnalyticsPeriod, error) {
+	format, ok := dateFormats[interval]
+	if !ok {
+		format = dateFormats[domain.AnalyticsIntervalDay]
+	}
+
+	pipeline := salesPipeline(from, to, []bson.M{
+		{
+			"$group": bson.M{
+				"_id": bson.M{
+					"period": bson.M{"$dateToString": bson.M{
+						"format":   format,
+						"date":     "$created_at",
+						"timezone": from.Format("-07:00"),
+					}},
+					"order": orderExpr,
+				},
+				"revenue": bson.M{"$sum": revenueExpr},
+				"items":   bson.M{"$sum": "$total"},
+			},
+		},
+		{
+			"$group": bson.M{
+				"_id":     "$_id.period",
+				"revenue": bson.M{"$sum": "$revenue"},
+				"items":   bson.M{"$sum": "$items"},
+				"orders":  bson.M{"$sum": 1},
+			},
+		},
+		{
+			"$sort": bson.M{"_id": 1},
+		},
+	})
+
+	cursor, err := r.TransaksiCollection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	series := []domain.AnalyticsPeriod{}
+	if err := cursor.All(ctx, &series); err != nil {
+		return nil, err
+	}
+
+	return series, nil
+}
+
+func (r *dashboardRepository) GetTopProduk(ctx context.Context, from time.Time, to time.Time, limit int64) ([]domain.AnalyticsProduk, error) {
+	pipeline := salesPipeline(from, to, []bson.M{
+		{
+			"$group": bson.M{
+				"_id":     "$produk_id",
+				"terjual": bson.M{"$sum": "$total"},
+				"revenue": bson.M{"$sum": revenueExpr},
+			},
+		},
+		{
+			"$sort": bson.D{{Key: "terjual", Value: -1}, {Key: "revenue", Value: -1}},
+		},
+		{
+			"$limit": limit,
+		},
+		{
+			"$lookup": bson.M{
+				"from":         produkCollectionName,
+				"localField":   "_id",
+				"foreignField": "_id",
+				"as":           "produk",
+			},
+		},
+		{
+			"$unwind": bson.M{"path": "$produk", "preserveNullAndEmptyArrays": true},
+		},
+		{
+			"$project": bson.M{
+				"terjual":  1,
+				"revenue":  1,
+				"name":     "$produk.name",
+				"category": "$produk.category",
+			},
+		},
+	})
+
+	cursor, err := r.TransaksiCollection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	top := []domain.AnalyticsProduk{}
+	if err := cursor.All(ctx, &top); err != nil {
+		return nil, err
+	}
+
+	return top, nil
+}
+
+func (r *dashboardRepository) GetTopCategory(ctx context.Context, from time.Time, to time.Time, limit int64) ([]domain.AnalyticsCategory, error) {
+	pipeline := salesPipeline(from, to, []bson.M{
+		{
+			"$lookup": bson.M{
+				"from":         produkCollectionName,
+				"localField":   "produk_id",
+				"foreignField": "_id",
+				"as":           "produk",
+			},
+		},
+		{
+			"$unwind": "$produk",
+		},
+		{
+			"$group": bson.M{
+				"_id":     "$produk.category",
+				"terjual": bson.M{"$sum": "$total"},
+				"revenue": bson.M{"$sum": revenueExpr},
+			},
+		},
+		{
+			"$sort": bson.D{{Key: "revenue", Value: -1}, {Key: "terjual", Value: -1}},
+		},
+		{
+			"$limit": limit,
+		},
+	})
+
+	cursor, err := r.TransaksiCollection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	top := []domain.AnalyticsCategory{}
+	if err := cursor.All(ctx, &top); err != nil {
+		return nil, err
+	}
+
+	return top, nil
+}
+
+// GetOutstandingSaldo menjumlahkan seluruh saldo user saat ini ditambah saldo yang masih ditahan
+// penarikan saldo dan pre-order yang belum selesai, tidak bergantung rentang tanggal
+func (r *dashboardRepository) GetOutstandingSaldo(ctx context.Context) (float64, error) {
+	saldo, err := r.sum(ctx, userAmountCollectionName, bson.M{}, "$amount")
+	if err != nil {
+		return 0, err
+	}
+
+	withdrawal, err := r.sum(ctx, withdrawalCollectionName, bson.M{
+		"status": bson.M{"$in": []string{domain.WithdrawalStatusPending, domain.WithdrawalStatusApproved}},
+	}, "$amount")
+	if err != nil {
+		return 0, err
+	}
+
+	preOrder, err := r.sum(ctx, preOrderCollectionName, bson.M{"status": domain.PreOrderStatusPending}, "$total_bayar")
+	if err != nil {
+		return 0, err
+	}
+
+	return saldo + withdrawal + preOrder, nil
+}
+
+// sum menjumlahkan field dari dokumen collection yang cocok dengan filter
+func (r *dashboardRepository) sum(ctx context.Context, collection string, filter bson.M, field string) (float64, error) {
+	pipeline := []bson.M{
+		{
+			"$match": filter,
+		},
+		{
+			"$group": bson.M{
+				"_id":   nil,
+				"total": bson.M{"$sum": field},
+			},
+		},
+	}
+
+	cursor, err := r.DB.Collection(collection).Aggregate(ctx, pipeline)
+	if err != nil {
+		return 0, err
+	}
+	defer cursor.Close(ctx)
+
+	var result struct {
+		Total float64 `bson:"total"`
+	}
+	if cursor.Next(ctx) {
 		if err := cursor.Decode(&result); err != nil {
-			return nil, nil, err
+			return 0, err
 		}
+	}
+
+	return result.Total, nil
+}
+
+func (r *dashboardRepository) GetTopUpVolume(ctx context.Context, from time.Time, to time.Time) (*domain.AnalyticsTopUp, error) {
+	pipeline := []bson.M{
+		{
+			"$match": bson.M{
+				"created_at": bson.M{"$gte": from, "$lt": to},
+			},
+		},
+		{
+			"$group": bson.M{
+				"_id":   nil,
+				"total": bson.M{"$sum": "$amount"},
+				"count": bson.M{"$sum": 1},
+			},
+		},
+	}
 
-		produk = append(produk, result.Produk)
-		user = result.User
+	cursor, err := r.DB.Collection(topUpCollectionName).Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	topup := &domain.AnalyticsTopUp{}
+	if cursor.Next(ctx) {
+		if err := cursor.Decode(topup); err != nil {
+			return nil, err
+		}
 	}
 
-	return produk, user, nil
+	return topup, nil
 }
@@ -2,49 +2,75 @@ package usecase
 
 import (
 	"context"
-	"encoding/json"
 	"errors"
 	"time"
+	"warunk-bem/cache"
 	"warunk-bem/domain"
+	"warunk-bem/dtos"
 
-	"github.com/go-redis/redis/v8"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
 type dashboardUsecase struct {
-	DashboardRepo  domain.DashboardRepository
-	UserRepo       domain.UserRepository
-	UserAmountRepo domain.UserAmountRepository
-	ProdukRepo     domain.ProdukRepository
-	TransaksiRepo  domain.TransaksiRepository
-	RedisClient    *redis.Client
-	contextTimeout time.Duration
+	DashboardRepo    domain.DashboardRepository
+	UserRepo         domain.UserRepository
+	UserAmountRepo   domain.UserAmountRepository
+	ProdukRepo       domain.ProdukRepository
+	TransaksiRepo    domain.TransaksiRepository
+	WarunkRepo       domain.WarunkRepository
+	SavedListUsecase domain.SavedListUsecase
+	Cache            domain.Cache
+	contextTimeout   time.Duration
 }
 
-func NewDashboardUsecase(DashboardRepo domain.DashboardRepository, UserRepo domain.UserRepository, UserAmountRepo domain.UserAmountRepository, ProdukRepo domain.ProdukRepository, TransaksiRepo domain.TransaksiRepository, RedisClient *redis.Client, contextTimeout time.Duration) domain.DashboardUsecase {
+const (
+	dashboardRecentLimit   = 5
+	dashboardFavoriteLimit = 5
+	dashboardTerlarisLimit = 5
+)
+
+func NewDashboardUsecase(DashboardRepo domain.DashboardRepository, UserRepo domain.UserRepository, UserAmountRepo domain.UserAmountRepository, ProdukRepo domain.ProdukRepository, TransaksiRepo domain.TransaksiRepository, WarunkRepo domain.WarunkRepository, SavedListUsecase domain.SavedListUsecase, Cache domain.Cache, contextTimeout time.Duration) domain.DashboardUsecase {
 	return &dashboardUsecase{
-		DashboardRepo:  DashboardRepo,
-		UserRepo:       UserRepo,
-		UserAmountRepo: UserAmountRepo,
-		ProdukRepo:     ProdukRepo,
-		TransaksiRepo:  TransaksiRepo,
-		RedisClient:    RedisClient,
-		contextTimeout: contextTimeout,
+		DashboardRepo:    DashboardRepo,
+		UserRepo:         UserRepo,
+		UserAmountRepo:   UserAmountRepo,
+		ProdukRepo:       ProdukRepo,
+		TransaksiRepo:    TransaksiRepo,
+		WarunkRepo:       WarunkRepo,
+		SavedListUsecase: SavedListUsecase,
+		Cache:            Cache,
+		contextTimeout:   contextTimeout,
 	}
 }
 
+// GetDashboard godoc
+// @Summary      Get Dashboard
+// @Description  Get saldo, profile, produk, recent transaksi, favorite, terlaris hari ini and whether warunk is open
+// @Tags         User - Dashboard
+// @Accept       json
+// @Produce      json
+// @Param        rp query int false "rp"
+// @Param        p query int false "p"
+// @Param        category query string false "filter produk by category"
+// @Param        sort query string false "newest, price_asc or price_desc"
+// @Success      200 {object} dtos.DashboardOKResponse
+// @Failure      400 {object} dtos.BadRequestResponse
+// @Failure      401 {object} dtos.UnauthorizedResponse
+// @Failure      403 {object} dtos.ForbiddenResponse
+// @Failure      500 {object} dtos.InternalServerErrorResponse
+// @Router       /dashboard [get]
+// @Security BearerAuth
 func (du *dashboardUsecase) GetDashboardData(c context.Context, userID string, rp int64, p int64, filter interface{}, setsort interface{}) (*domain.DashboardData, error) {
+	// Key mengikuti versi produk dan user, sehingga perubahan stok atau saldo langsung terlihat
+	cacheKey := cache.Key(c, du.Cache, []string{cache.NamespaceProduk, cache.UserNamespace(userID)}, "dashboard", rp, p, cache.Hash(filter, setsort))
 
-	var res *domain.DashboardData
-
-	cachekey := "dashboard_data:" + userID
-	val, err := du.RedisClient.Get(c, cachekey).Result()
-	if err == nil {
-		if err := json.Unmarshal([]byte(val), &res); err != nil {
-			return nil, errors.New("failed to unmarshal cache")
-		}
-		return res, nil
-	}
+	return cache.Remember(c, du.Cache, cacheKey, cache.DefaultTTL, func() (*domain.DashboardData, error) {
+		return du.loadDashboardData(c, userID, rp, p, filter, setsort)
+	})
+}
 
+func (du *dashboardUsecase) loadDashboardData(c context.Context, userID string, rp int64, p int64, filter interface{}, setsort interface{}) (*domain.DashboardData, error) {
 	ctx, cancel := context.WithTimeout(c, du.contextTimeout)
 	defer cancel()
 
@@ -66,17 +92,180 @@ func (du *dashboardUsecase) GetDashboardData(c context.Context, userID string, r
 		return nil, errors.New("failed to get product list")
 	}
 
+	riwayat, err := du.recentTransaksi(ctx, profil.ID)
+	if err != nil {
+		return nil, errors.New("failed to get recent transaksi")
+	}
+
+	favorite, _, err := du.SavedListUsecase.GetItems(ctx, userID, domain.SavedListKindFavorite, dashboardFavoriteLimit, 1)
+	if err != nil {
+		return nil, errors.New("failed to get favorite")
+	}
+
+	// Terlaris dihitung sejak awal hari ini
+	now := time.Now()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	terlaris, err := du.DashboardRepo.GetTerlaris(ctx, today, dashboardTerlarisLimit)
+	if err != nil {
+		return nil, errors.New("failed to get terlaris hari ini")
+	}
+
+	warunkBuka, err := du.isWarunkOpen(ctx, today)
+	if err != nil {
+		return nil, errors.New("failed to get warunk status")
+	}
+
 	// Membentuk response data dashboard
 	dashboardData := &domain.DashboardData{
-		Saldo:  saldo,
-		Profil: profil,
-		Produk: produkList,
+		Saldo:            saldo,
+		Profil:           profil,
+		Produk:           produkList,
+		WarunkBuka:       warunkBuka,
+		RiwayatTransaksi: riwayat,
+		Favorite:         favorite,
+		TerlarisHariIni:  terlaris,
 	}
 
-	cacheValue, err := json.Marshal(dashboardData)
-	if err == nil {
-		du.RedisClient.Set(c, cachekey, cacheValue, 10*time.Minute)
+	return dashboardData, nil
+}
+
+func (du *dashboardUsecase) recentTransaksi(ctx context.Context, userID primitive.ObjectID) ([]*dtos.RiwayatTransaksiResponse, error) {
+	transaksis, _, err := du.TransaksiRepo.GetAllWithPage(ctx, dashboardRecentLimit, 1, bson.M{"user_id": userID}, bson.M{"created_at": -1})
+	if err != nil {
+		return nil, err
 	}
 
-	return dashboardData, nil
+	res := make([]*dtos.RiwayatTransaksiResponse, 0, len(transaksis))
+	for _, transaksi := range transaksis {
+		riwayat := &dtos.RiwayatTransaksiResponse{
+			CreatedAt: transaksi.CreatedAt.Format("2006-01-02"),
+			Waktu:     transaksi.CreatedAt.Format("15:04:05"),
+			Harga:     transaksi.Harga,
+			Discount:  transaksi.Discount,
+			Total:     transaksi.Total,
+		}
+
+		produk, err := du.ProdukRepo.FindOne(ctx, transaksi.ProdukID.Hex())
+		if err == nil {
+			riwayat.Name = produk.Name
+			riwayat.Image = produk.Image
+
+			// Transaksi lama belum menyimpan harga, gunakan harga produk saat ini
+			if riwayat.Harga == 0 {
+				riwayat.Harga = produk.Price * transaksi.Total
+			}
+		}
+
+		res = append(res, riwayat)
+	}
+
+	return res, nil
+}
+
+// isWarunkOpen warunk dianggap buka jika dibuka hari ini dan belum ditutup sesudahnya
+func (du *dashboardUsecase) isWarunkOpen(ctx context.Context, today time.Time) (bool, error) {
+	buka, err := du.WarunkRepo.FindLatestByStatus(ctx, "Buka")
+	if err != nil || buka == nil {
+		return false, err
+	}
+
+	if buka.CreatedAt.Before(today) {
+		return false, nil
+	}
+
+	tutup, err := du.WarunkRepo.FindLatestByStatus(ctx, "Tutup")
+	if err != nil {
+		return false, err
+	}
+
+	return tutup == nil || tutup.CreatedAt.Before(buka.CreatedAt), nil
+}
+
+const (
+	analyticsTopLimit = 10
+	analyticsMaxRange = 366 * 24 * time.Hour
+)
+
+// GetAdminAnalytics godoc
+// @Summary      Get Admin Analytics
+// @Description  Revenue and order series, top produk and category, basket size, active buyers, outstanding saldo and top-up volume
+// @Tags         Admin - Dashboard
+// @Accept       json
+// @Produce      json
+// @Param        from query string false "start date (YYYY-MM-DD), default 30 days ago"
+// @Param        to query string false "end date inclusive (YYYY-MM-DD), default today"
+// @Param        interval query string false "day, week or month"
+// @Success      200 {object} dtos.AdminAnalyticsOKResponse
+// @Failure      400 {object} dtos.BadRequestResponse
+// @Failure      401 {object} dtos.UnauthorizedResponse
+// @Failure      403 {object} dtos.ForbiddenResponse
+// @Failure      500 {object} dtos.InternalServerErrorResponse
+// @Router       /dashboard/analytics [get]
+// @Security BearerAuth
+func (du *dashboardUsecase) GetAdminAnalytics(c context.Context, from time.Time, to time.Time, interval string) (*domain.AdminAnalytics, error) {
+	switch interval {
+	case "":
+		interval = domain.AnalyticsIntervalDay
+	case domain.AnalyticsIntervalDay, domain.AnalyticsIntervalWeek, domain.AnalyticsIntervalMonth:
+	default:
+		return nil, errors.New("interval harus day, week atau month")
+	}
+
+	if !from.Before(to) {
+		return nil, errors.New("tanggal from harus sebelum to")
+	}
+
+	if to.Sub(from) > analyticsMaxRange {
+		return nil, errors.New("rentang tanggal maksimal 1 tahun")
+	}
+
+	ctx, cancel := context.WithTimeout(c, du.contextTimeout)
+	defer cancel()
+
+	summary, err := du.DashboardRepo.GetSalesSummary(ctx, from, to)
+	if err != nil {
+		return nil, errors.New("failed to get sales summary")
+	}
+
+	if summary.Orders > 0 {
+		summary.AverageBasketSize = float64(summary.Items) / float64(summary.Orders)
+		summary.AverageOrderValue = float64(summary.Revenue) / float64(summary.Orders)
+	}
+
+	series, err := du.DashboardRepo.GetSalesSeries(ctx, from, to, interval)
+	if err != nil {
+		return nil, errors.New("failed to get sales series")
+	}
+
+	topProduk, err := du.DashboardRepo.GetTopProduk(ctx, from, to, analyticsTopLimit)
+	if err != nil {
+		return nil, errors.New("failed to get top produk")
+	}
+
+	topCategory, err := du.DashboardRepo.GetTopCategory(ctx, from, to, analyticsTopLimit)
+	if err != nil {
+		return nil, errors.New("failed to get top category")
+	}
+
+	saldo, err := du.DashboardRepo.GetOutstandingSaldo(ctx)
+	if err != nil {
+		return nil, errors.New("failed to get outstanding saldo")
+	}
+
+	topup, err := du.DashboardRepo.GetTopUpVolume(ctx, from, to)
+	if err != nil {
+		return nil, errors.New("failed to get top up volume")
+	}
+
+	return &domain.AdminAnalytics{
+		From:             from,
+		To:               to,
+		Interval:         interval,
+		Summary:          *summary,
+		Series:           series,
+		TopProduk:        topProduk,
+		TopCategory:      topCategory,
+		OutstandingSaldo: saldo,
+		TopUp:            *topup,
+	}, nil
 }
@@ -0,0 +1,296 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+	"warunk-bem/cache"
+	"warunk-bem/domain"
+	"warunk-bem/dtos"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type mockDashboardRepo struct {
+	domain.DashboardRepository
+
+	summary    domain.AnalyticsSummary
+	saldo      float64
+	summaryErr error
+	interval   string
+}
+
+func (m *mockDashboardRepo) GetSalesSummary(ctx context.Context, from time.Time, to time.Time) (*domain.AnalyticsSummary, error) {
+	if m.summaryErr != nil {
+		return nil, m.summaryErr
+	}
+	summary := m.summary
+	return &summary, nil
+}
+
+func (m *mockDashboardRepo) GetSalesSeries(ctx context.Context, from time.Time, to time.Time, interval string) ([]domain.AnalyticsPeriod, error) {
+	m.interval = interval
+	return []domain.AnalyticsPeriod{}, nil
+}
+
+func (m *mockDashboardRepo) GetTopProduk(ctx context.Context, from time.Time, to time.Time, limit int64) ([]domain.AnalyticsProduk, error) {
+	return []domain.AnalyticsProduk{}, nil
+}
+
+func (m *mockDashboardRepo) GetTopCategory(ctx context.Context, from time.Time, to time.Time, limit int64) ([]domain.AnalyticsCategory, error) {
+	return []domain.AnalyticsCategory{}, nil
+}
+
+func (m *mockDashboardRepo) GetOutstandingSaldo(ctx context.Context) (float64, error) {
+	return m.saldo, nil
+}
+
+func (m *mockDashboardRepo) GetTopUpVolume(ctx context.Context, from time.Time, to time.Time) (*domain.AnalyticsTopUp, error) {
+	return &domain.AnalyticsTopUp{}, nil
+}
+
+func (m *mockDashboardRepo) GetTerlaris(ctx context.Context, from time.Time, limit int64) ([]domain.ProdukTerlaris, error) {
+	return []domain.ProdukTerlaris{}, nil
+}
+
+type mockUserAmountRepo struct {
+	domain.UserAmountRepository
+}
+
+func (m *mockUserAmountRepo) FindOne(ctx context.Context, userID string) (*domain.UserAmount, error) {
+	return &domain.UserAmount{Amount: 50000}, nil
+}
+
+type mockUserRepo struct {
+	domain.UserRepository
+}
+
+func (m *mockUserRepo) FindOne(ctx context.Context, id string) (*domain.User, error) {
+	userID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, err
+	}
+	return &domain.User{ID: userID}, nil
+}
+
+type mockProdukRepo struct {
+	domain.ProdukRepository
+
+	produks map[string]*domain.Produk
+	pages   []int64
+}
+
+func (m *mockProdukRepo) FindOne(ctx context.Context, id string) (*domain.Produk, error) {
+	produk, ok := m.produks[id]
+	if !ok {
+		return nil, errors.New("not found")
+	}
+	return produk, nil
+}
+
+func (m *mockProdukRepo) GetAllWithPage(ctx context.Context, rp int64, p int64, filter interface{}, setsort interface{}) ([]domain.Produk, int64, error) {
+	m.pages = append(m.pages, p)
+	return []domain.Produk{}, 0, nil
+}
+
+type mockTransaksiRepo struct {
+	domain.TransaksiRepository
+
+	transaksis []domain.Transaksi
+}
+
+func (m *mockTransaksiRepo) GetAllWithPage(ctx context.Context, rp int64, p int64, filter interface{}, setsort interface{}) ([]domain.Transaksi, int64, error) {
+	return m.transaksis, int64(len(m.transaksis)), nil
+}
+
+// mockWarunkRepo menyimpan waktu warunk terakhir dibuka dan ditutup, nil berarti belum pernah
+type mockWarunkRepo struct {
+	domain.WarunkRepository
+
+	latest map[string]*time.Time
+}
+
+func (m *mockWarunkRepo) FindLatestByStatus(ctx context.Context, status string) (*domain.Warunk, error) {
+	at := m.latest[status]
+	if at == nil {
+		return nil, nil
+	}
+	return &domain.Warunk{Status: status, CreatedAt: *at}, nil
+}
+
+type mockSavedListUsecase struct {
+	domain.SavedListUsecase
+}
+
+func (m *mockSavedListUsecase) GetItems(ctx context.Context, userID string, listRef string, rp int64, p int64) ([]*dtos.SavedListItemResponse, int64, error) {
+	return []*dtos.SavedListItemResponse{}, 0, nil
+}
+
+func TestGetDashboardDataWarunkBuka(t *testing.T) {
+	now := time.Now()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	pagi := today.Add(time.Minute)
+	siang := today.Add(2 * time.Minute)
+	kemarin := today.Add(-time.Hour)
+
+	tests := []struct {
+		name  string
+		buka  *time.Time
+		tutup *time.Time
+		want  bool
+	}{
+		{name: "never opened"},
+		{name: "opened today", buka: &pagi, want: true},
+		{name: "opened today after yesterday's close", buka: &pagi, tutup: &kemarin, want: true},
+		{name: "closed after opening today", buka: &pagi, tutup: &siang},
+		{name: "reopened after closing today", buka: &siang, tutup: &pagi, want: true},
+		{name: "opened yesterday and never closed", buka: &kemarin},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			warunkRepo := &mockWarunkRepo{latest: map[string]*time.Time{"Buka": tt.buka, "Tutup": tt.tutup}}
+			du := NewDashboardUsecase(&mockDashboardRepo{}, &mockUserRepo{}, &mockUserAmountRepo{}, &mockProdukRepo{}, &mockTransaksiRepo{}, warunkRepo, &mockSavedListUsecase{}, cache.NewMemoryCache(), time.Second)
+
+			res, err := du.GetDashboardData(context.Background(), primitive.NewObjectID().Hex(), 10, 1, nil, nil)
+			if err != nil {
+				t.Fatalf("err = %v", err)
+			}
+			if res.WarunkBuka != tt.want {
+				t.Fatalf("warunk buka = %v, want %v", res.WarunkBuka, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetDashboardDataRiwayat(t *testing.T) {
+	kopi := &domain.Produk{ID: primitive.NewObjectID(), Name: "Kopi", Price: 6000}
+	produkRepo := &mockProdukRepo{produks: map[string]*domain.Produk{kopi.ID.Hex(): kopi}}
+	transaksiRepo := &mockTransaksiRepo{transaksis: []domain.Transaksi{
+		{ProdukID: kopi.ID, Total: 2, Harga: 10000},
+		{ProdukID: kopi.ID, Total: 2},
+		{ProdukID: primitive.NewObjectID(), Total: 1},
+	}}
+	du := NewDashboardUsecase(&mockDashboardRepo{}, &mockUserRepo{}, &mockUserAmountRepo{}, produkRepo, transaksiRepo, &mockWarunkRepo{}, &mockSavedListUsecase{}, cache.NewMemoryCache(), time.Second)
+
+	res, err := du.GetDashboardData(context.Background(), primitive.NewObjectID().Hex(), 10, 1, nil, nil)
+	if err != nil {
+		t.Fatalf("err = %v", err)
+	}
+
+	want := []struct {
+		name  string
+		harga int64
+	}{
+		{name: "Kopi", harga: 10000},
+		{name: "Kopi", harga: 12000},
+		{name: "", harga: 0},
+	}
+	if len(res.RiwayatTransaksi) != len(want) {
+		t.Fatalf("riwayat = %d, want %d", len(res.RiwayatTransaksi), len(want))
+	}
+	for i, w := range want {
+		if got := res.RiwayatTransaksi[i]; got.Name != w.name || got.Harga != w.harga {
+			t.Errorf("riwayat %d = %s %d, want %s %d", i, got.Name, got.Harga, w.name, w.harga)
+		}
+	}
+}
+
+func TestGetDashboardDataCacheKey(t *testing.T) {
+	userID := primitive.NewObjectID().Hex()
+	produkRepo := &mockProdukRepo{}
+	c := cache.NewMemoryCache()
+	du := NewDashboardUsecase(&mockDashboardRepo{}, &mockUserRepo{}, &mockUserAmountRepo{}, produkRepo, &mockTransaksiRepo{}, &mockWarunkRepo{}, &mockSavedListUsecase{}, c, time.Second)
+
+	calls := []struct {
+		p      int64
+		filter interface{}
+	}{
+		{p: 1},
+		{p: 1},
+		{p: 2},
+		{p: 1, filter: map[string]string{"category": "Minuman"}},
+	}
+	for _, call := range calls {
+		if _, err := du.GetDashboardData(context.Background(), userID, 10, call.p, call.filter, nil); err != nil {
+			t.Fatalf("err = %v", err)
+		}
+	}
+	if len(produkRepo.pages) != 3 {
+		t.Fatalf("produk loaded %d times, want 3 (page and filter are part of the key)", len(produkRepo.pages))
+	}
+
+	// perubahan saldo user membuat dashboard dimuat ulang
+	cache.InvalidateUser(context.Background(), c, userID)
+	if _, err := du.GetDashboardData(context.Background(), userID, 10, 1, nil, nil); err != nil {
+		t.Fatalf("err = %v", err)
+	}
+	if len(produkRepo.pages) != 4 {
+		t.Fatalf("produk loaded %d times after invalidate, want 4", len(produkRepo.pages))
+	}
+}
+
+func TestGetAdminAnalytics(t *testing.T) {
+	to := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	from := to.AddDate(0, 0, -30)
+
+	tests := []struct {
+		name         string
+		from         time.Time
+		to           time.Time
+		interval     string
+		summary      domain.AnalyticsSummary
+		summaryErr   error
+		wantErr      bool
+		wantInterval string
+		wantBasket   float64
+		wantAOV      float64
+	}{
+		{
+			name:         "default interval and averages",
+			from:         from,
+			to:           to,
+			summary:      domain.AnalyticsSummary{Revenue: 90000, Orders: 3, Items: 7},
+			wantInterval: domain.AnalyticsIntervalDay,
+			wantBasket:   7.0 / 3.0,
+			wantAOV:      30000,
+		},
+		{
+			name:         "no orders",
+			from:         from,
+			to:           to,
+			interval:     domain.AnalyticsIntervalMonth,
+			wantInterval: domain.AnalyticsIntervalMonth,
+		},
+		{name: "unknown interval", from: from, to: to, interval: "hour", wantErr: true},
+		{name: "from after to", from: to, to: from, wantErr: true},
+		{name: "range over a year", from: to.AddDate(-2, 0, 0), to: to, wantErr: true},
+		{name: "repository failure", from: from, to: to, summaryErr: errors.New("mongo down"), wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &mockDashboardRepo{summary: tt.summary, summaryErr: tt.summaryErr, saldo: 125000}
+			du := NewDashboardUsecase(repo, nil, nil, nil, nil, nil, nil, cache.NewMemoryCache(), time.Second)
+
+			res, err := du.GetAdminAnalytics(context.Background(), tt.from, tt.to, tt.interval)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+
+			if res.Interval != tt.wantInterval || repo.interval != tt.wantInterval {
+				t.Errorf("interval = %s (repo %s), want %s", res.Interval, repo.interval, tt.wantInterval)
+			}
+			if res.Summary.AverageBasketSize != tt.wantBasket || res.Summary.AverageOrderValue != tt.wantAOV {
+				t.Errorf("basket = %v, aov = %v, want %v and %v", res.Summary.AverageBasketSize, res.Summary.AverageOrderValue, tt.wantBasket, tt.wantAOV)
+			}
+			if res.OutstandingSaldo != 125000 {
+				t.Errorf("outstanding saldo = %.0f, want 125000", res.OutstandingSaldo)
+			}
+		})
+	}
+}
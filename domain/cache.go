@@ -0,0 +1,28 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// Cache menyimpan data hasil baca sementara. Key yang memakai namespace berversi
+// dibatalkan sekaligus dengan menaikkan versi namespace lewat Bump.
+type Cache interface {
+	Get(ctx context.Context, key string) ([]byte, bool, error)
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+	Delete(ctx context.Context, keys ...string) error
+	Version(ctx context.Context, namespace string) (int64, error)
+	Bump(ctx context.Context, namespace string) error
+	Stats() CacheStats
+}
+
+type CacheStats struct {
+	Driver  string  `json:"driver"`
+	Hits    int64   `json:"hits"`
+	Misses  int64   `json:"misses"`
+	Sets    int64   `json:"sets"`
+	Deletes int64   `json:"deletes"`
+	Bumps   int64   `json:"bumps"`
+	Errors  int64   `json:"errors"`
+	HitRate float64 `json:"hit_rate"`
+}
@@ -1,16 +1,29 @@
 package domain
 
-import "mime/multipart"
+import (
+	"context"
+	"io"
+	"mime/multipart"
+)
 
 type File struct {
-	File multipart.File `json:"file,omitempty" validate:"required"`
+	File     multipart.File `json:"file,omitempty" validate:"required"`
+	Filename string         `json:"filename,omitempty"`
+	Size     int64          `json:"size,omitempty"`
 }
 
 type Url struct {
 	Url string `json:"url,omitempty" validate:"required"`
 }
 
+// MediaStorage adalah backend penyimpanan file (Cloudinary, disk lokal, S3/MinIO)
+type MediaStorage interface {
+	Upload(ctx context.Context, file io.Reader, filename string, contentType string) (string, error)
+	Delete(ctx context.Context, url string) error
+}
+
 type ClourdinaryUsecase interface {
 	FileUpload(file File) (string, error)
 	RemoteUpload(url Url) (string, error)
+	Destroy(url string) error
 }
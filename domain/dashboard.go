@@ -2,18 +2,93 @@ package domain
 
 import (
 	"context"
+	"time"
+	"warunk-bem/dtos"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
 type DashboardData struct {
-	Saldo  *UserAmount `json:"saldo"`
-	Profil *User       `json:"profil"`
-	Produk []Produk    `json:"produk"`
+	Saldo            *UserAmount                      `json:"saldo"`
+	Profil           *User                            `json:"profil"`
+	Produk           []Produk                         `json:"produk"`
+	WarunkBuka       bool                             `json:"warunk_buka"`
+	RiwayatTransaksi []*dtos.RiwayatTransaksiResponse `json:"riwayat_transaksi"`
+	Favorite         []*dtos.SavedListItemResponse    `json:"favorite"`
+	TerlarisHariIni  []ProdukTerlaris                 `json:"terlaris_hari_ini"`
+}
+
+type ProdukTerlaris struct {
+	Produk  Produk `bson:"produk" json:"produk"`
+	Terjual int64  `bson:"terjual" json:"terjual"`
+}
+
+const (
+	AnalyticsIntervalDay   = "day"
+	AnalyticsIntervalWeek  = "week"
+	AnalyticsIntervalMonth = "month"
+)
+
+// AdminAnalytics adalah ringkasan penjualan dan saldo untuk admin dalam rentang tanggal tertentu
+type AdminAnalytics struct {
+	From             time.Time           `json:"from"`
+	To               time.Time           `json:"to"`
+	Interval         string              `json:"interval"`
+	Summary          AnalyticsSummary    `json:"summary"`
+	Series           []AnalyticsPeriod   `json:"series"`
+	TopProduk        []AnalyticsProduk   `json:"top_produk"`
+	TopCategory      []AnalyticsCategory `json:"top_category"`
+	OutstandingSaldo float64             `json:"outstanding_saldo"`
+	TopUp            AnalyticsTopUp      `json:"topup"`
+}
+
+type AnalyticsSummary struct {
+	Revenue           int64   `bson:"revenue" json:"revenue"`
+	Discount          int64   `bson:"discount" json:"discount"`
+	Orders            int64   `bson:"orders" json:"orders"`
+	Items             int64   `bson:"items" json:"items"`
+	ActiveBuyers      int64   `bson:"active_buyers" json:"active_buyers"`
+	AverageBasketSize float64 `bson:"-" json:"average_basket_size"`
+	AverageOrderValue float64 `bson:"-" json:"average_order_value"`
+}
+
+type AnalyticsPeriod struct {
+	Period  string `bson:"_id" json:"period"`
+	Revenue int64  `bson:"revenue" json:"revenue"`
+	Orders  int64  `bson:"orders" json:"orders"`
+	Items   int64  `bson:"items" json:"items"`
+}
+
+type AnalyticsProduk struct {
+	ProdukID primitive.ObjectID `bson:"_id" json:"produk_id"`
+	Name     string             `bson:"name" json:"name"`
+	Category string             `bson:"category" json:"category"`
+	Terjual  int64              `bson:"terjual" json:"terjual"`
+	Revenue  int64              `bson:"revenue" json:"revenue"`
+}
+
+type AnalyticsCategory struct {
+	Category string `bson:"_id" json:"category"`
+	Terjual  int64  `bson:"terjual" json:"terjual"`
+	Revenue  int64  `bson:"revenue" json:"revenue"`
+}
+
+type AnalyticsTopUp struct {
+	Total float64 `bson:"total" json:"total"`
+	Count int64   `bson:"count" json:"count"`
 }
 
 type DashboardRepository interface {
-	DashboardGetAll() ([]Produk, *User, error)
+	GetTerlaris(ctx context.Context, since time.Time, limit int64) ([]ProdukTerlaris, error)
+	GetSalesSummary(ctx context.Context, from time.Time, to time.Time) (*AnalyticsSummary, error)
+	GetSalesSeries(ctx context.Context, from time.Time, to time.Time, interval string) ([]AnalyticsPeriod, error)
+	GetTopProduk(ctx context.Context, from time.Time, to time.Time, limit int64) ([]AnalyticsProduk, error)
+	GetTopCategory(ctx context.Context, from time.Time, to time.Time, limit int64) ([]AnalyticsCategory, error)
+	GetOutstandingSaldo(ctx context.Context) (float64, error)
+	GetTopUpVolume(ctx context.Context, from time.Time, to time.Time) (*AnalyticsTopUp, error)
 }
 
 type DashboardUsecase interface {
 	GetDashboardData(c context.Context, userID string, rp int64, p int64, filter interface{}, setsort interface{}) (*DashboardData, error)
+	GetAdminAnalytics(c context.Context, from time.Time, to time.Time, interval string) (*AdminAnalytics, error)
 }
@@ -3,6 +3,7 @@ package domain
 import (
 	"context"
 	"time"
+	"warunk-bem/dtos"
 
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
@@ -36,10 +37,11 @@ type DeleteProductKeranjangResponse struct {
 }
 
 type InsertKeranjangResponse struct {
-	ID     string   `json:"id"`
-	UserID string   `json:"user_id"`
-	Produk []Produk `json:"produk"`
-	Total  int      `json:"total"`
+	ID       string   `json:"id"`
+	UserID   string   `json:"user_id"`
+	Produk   []Produk `json:"produk"`
+	Total    int      `json:"total"`
+	Subtotal int64    `json:"subtotal"`
 }
 
 type KeranjangRepository interface {
@@ -58,6 +60,10 @@ type KeranjangUsecase interface {
 	FindOne(ctx context.Context, id string) (*InsertKeranjangResponse, error)
 	UpdateOne(ctx context.Context, id string, req *Keranjang) (*Keranjang, error)
 	RemoveProduct(ctx context.Context, keranjangID string, productID string) (*DeleteProductKeranjangResponse, error)
+	SetQuantity(ctx context.Context, userID string, produkID string, quantity int) (*InsertKeranjangResponse, error)
+	RemoveLine(ctx context.Context, userID string, produkID string) (*InsertKeranjangResponse, error)
+	Clear(ctx context.Context, userID string) error
+	Preview(ctx context.Context, userID string, promoCode string) (*dtos.KeranjangPreviewResponse, error)
 	// GetAllWithPage(ctx context.Context, rp int64, p int64, filter interface{}, setsort interface{}) ([]dtos.InsertKeranjangResponse, int64, error)
 	// DeleteOne(ctx context.Context, id string) error
 }
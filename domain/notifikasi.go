@@ -0,0 +1,50 @@
+package domain
+
+import (
+	"context"
+	"time"
+	"warunk-bem/dtos"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	NotifikasiTypeLowStock    = "low_stock"
+	NotifikasiTypeBackInStock = "back_in_stock"
+	NotifikasiTypePriceDrop   = "price_drop"
+	NotifikasiTypeTopUp       = "topup"
+	NotifikasiTypeTransfer    = "transfer"
+	NotifikasiTypePickup      = "pickup"
+	NotifikasiTypePreOrder    = "preorder"
+	NotifikasiTypeWithdrawal  = "withdrawal"
+)
+
+type Notifikasi struct {
+	ID          primitive.ObjectID `bson:"_id" json:"id"`
+	CreatedAt   time.Time          `bson:"created_at" json:"created_at"`
+	UserID      primitive.ObjectID `bson:"user_id" json:"user_id"`
+	Type        string             `bson:"type" json:"type"`
+	Title       string             `bson:"title" json:"title"`
+	Message     string             `bson:"message" json:"message"`
+	ReferenceID primitive.ObjectID `bson:"reference_id,omitempty" json:"reference_id"`
+	ReadAt      *time.Time         `bson:"read_at,omitempty" json:"read_at"`
+}
+
+type NotifikasiRepository interface {
+	InsertMany(ctx context.Context, req []*Notifikasi) error
+	GetAllWithPage(ctx context.Context, rp int64, p int64, filter interface{}, setsort interface{}) ([]Notifikasi, int64, error)
+	CountUnread(ctx context.Context, userID string) (int64, error)
+	MarkRead(ctx context.Context, id string, userID string) error
+	MarkAllRead(ctx context.Context, userID string) error
+}
+
+type NotifikasiUsecase interface {
+	GetAllWithPage(ctx context.Context, userID string, rp int64, p int64, unreadOnly bool) ([]*dtos.NotifikasiResponse, int64, int64, error)
+	MarkRead(ctx context.Context, id string, userID string) error
+	MarkAllRead(ctx context.Context, userID string) error
+	NotifyLowStock(ctx context.Context, produks []Produk) error
+	NotifyWishlist(ctx context.Context, before *Produk, after *Produk) error
+	NotifyUser(ctx context.Context, notifikasi *Notifikasi) error
+	GetPreference(ctx context.Context, userID string) (*dtos.NotifikasiPreferenceResponse, error)
+	UpdatePreference(ctx context.Context, userID string, req *dtos.NotifikasiPreferenceRequest) (*dtos.NotifikasiPreferenceResponse, error)
+}
@@ -0,0 +1,104 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+	"warunk-bem/dtos"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	PaymentMethodQRIS = "qris"
+	PaymentMethodVA   = "va"
+)
+
+const (
+	PaymentStatusPending = "pending"
+	PaymentStatusPaid    = "paid"
+	PaymentStatusExpired = "expired"
+	PaymentStatusFailed  = "failed"
+)
+
+const TopUpSourceGateway = "gateway"
+
+// ErrPaymentStatusChanged dikembalikan saat status pembayaran sudah diubah proses lain
+var ErrPaymentStatusChanged = errors.New("status pembayaran sudah berubah")
+
+// Payment adalah top up online yang dibuat lewat payment gateway,
+// saldo baru bertambah setelah webhook pembayaran berhasil diterima
+type Payment struct {
+	ID          primitive.ObjectID `bson:"_id" json:"id"`
+	CreatedAt   time.Time          `bson:"created_at" json:"created_at"`
+	UpdatedAt   time.Time          `bson:"updated_at" json:"updated_at"`
+	UserID      primitive.ObjectID `bson:"user_id" json:"user_id"`
+	Provider    string             `bson:"provider" json:"provider"`
+	ProviderRef string             `bson:"provider_ref" json:"provider_ref"`
+	Method      string             `bson:"method" json:"method"`
+	Amount      float64            `bson:"amount" json:"amount"`
+	Status      string             `bson:"status" json:"status"`
+	Instruction PaymentInstruction `bson:"instruction" json:"instruction"`
+	ExpiresAt   time.Time          `bson:"expires_at" json:"expires_at"`
+	PaidAt      *time.Time         `bson:"paid_at,omitempty" json:"paid_at"`
+}
+
+// PaymentInstruction adalah cara bayar yang ditampilkan ke user, isinya tergantung method
+type PaymentInstruction struct {
+	QRString string `bson:"qr_string,omitempty" json:"qr_string,omitempty"`
+	Bank     string `bson:"bank,omitempty" json:"bank,omitempty"`
+	VANumber string `bson:"va_number,omitempty" json:"va_number,omitempty"`
+}
+
+// PaymentCharge adalah permintaan tagihan ke provider, OrderID adalah ID Payment
+type PaymentCharge struct {
+	OrderID   string
+	Amount    float64
+	Method    string
+	Bank      string
+	ExpiresAt time.Time
+}
+
+type PaymentChargeResult struct {
+	ProviderRef string
+	Instruction PaymentInstruction
+}
+
+// PaymentWebhook adalah isi notifikasi provider yang sudah diverifikasi tanda tangannya
+type PaymentWebhook struct {
+	OrderID     string  `json:"order_id"`
+	ProviderRef string  `json:"provider_ref"`
+	Status      string  `json:"status"`
+	Amount      float64 `json:"amount"`
+}
+
+// PaymentProvider membungkus payment gateway (Midtrans, Xendit, atau fake untuk development)
+type PaymentProvider interface {
+	Name() string
+	CreateCharge(ctx context.Context, charge *PaymentCharge) (*PaymentChargeResult, error)
+	ParseWebhook(header http.Header, body []byte) (*PaymentWebhook, error)
+}
+
+// PaymentSimulator hanya diimplementasikan provider fake untuk mensimulasikan webhook secara offline
+type PaymentSimulator interface {
+	SignWebhook(webhook *PaymentWebhook) (http.Header, []byte, error)
+}
+
+type PaymentRepository interface {
+	EnsureIndexes(ctx context.Context) error
+	InsertOne(ctx context.Context, req *Payment) (*Payment, error)
+	FindOne(ctx context.Context, id string) (*Payment, error)
+	GetAllWithPage(ctx context.Context, rp int64, p int64, filter interface{}, setsort interface{}) ([]Payment, int64, error)
+	UpdateStatus(ctx context.Context, payment *Payment, fromStatus ...string) error
+	ExpirePending(ctx context.Context, now time.Time) (int64, error)
+}
+
+type PaymentUsecase interface {
+	CreateTopUp(ctx context.Context, userID string, req *dtos.PaymentTopUpRequest) (*dtos.PaymentResponse, error)
+	FindOne(ctx context.Context, id string, userID string) (*dtos.PaymentResponse, error)
+	GetAllWithPage(ctx context.Context, userID string, rp int64, p int64) ([]*dtos.PaymentResponse, int64, error)
+	HandleWebhook(ctx context.Context, header http.Header, body []byte) error
+	Simulate(ctx context.Context, id string, status string) (*dtos.PaymentResponse, error)
+	ExpirePending(ctx context.Context) (int64, error)
+}
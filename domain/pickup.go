@@ -0,0 +1,70 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"time"
+	"warunk-bem/dtos"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	PickupStatusPending  = "pending"
+	PickupStatusPickedUp = "picked_up"
+	PickupStatusRefunded = "refunded"
+)
+
+// PickupQRPrefix ditambahkan di depan kode pickup sebagai isi QR yang ditunjukkan pembeli ke staff
+const PickupQRPrefix = "WARUNK-PICKUP:"
+
+var (
+	// ErrPickupStatusChanged dikembalikan saat tiket sudah diambil atau direfund proses lain
+	ErrPickupStatusChanged = errors.New("status pickup sudah berubah")
+	// ErrPickupCodeTaken dikembalikan saat kode acak sudah dipakai tiket pending lain
+	ErrPickupCodeTaken = errors.New("kode pickup sudah dipakai")
+)
+
+// Pickup adalah tiket pengambilan barang yang dibuat setiap checkout,
+// staff menyerahkan barang setelah pembeli menunjukkan kode atau QR
+type Pickup struct {
+	ID            primitive.ObjectID `bson:"_id" json:"id"`
+	CreatedAt     time.Time          `bson:"created_at" json:"created_at"`
+	UpdatedAt     time.Time          `bson:"updated_at" json:"updated_at"`
+	OrderID       primitive.ObjectID `bson:"order_id" json:"order_id"`
+	ReceiptNumber string             `bson:"receipt_number" json:"receipt_number"`
+	UserID        primitive.ObjectID `bson:"user_id" json:"user_id"`
+	UserName      string             `bson:"user_name" json:"user_name"`
+	Code          string             `bson:"code" json:"code"`
+	Items         []PickupItem       `bson:"items" json:"items"`
+	TotalBayar    int64              `bson:"total_bayar" json:"total_bayar"`
+	Status        string             `bson:"status" json:"status"`
+	PickedUpAt    *time.Time         `bson:"picked_up_at,omitempty" json:"picked_up_at"`
+	PickedUpBy    primitive.ObjectID `bson:"picked_up_by,omitempty" json:"picked_up_by"`
+	RefundedAt    *time.Time         `bson:"refunded_at,omitempty" json:"refunded_at"`
+}
+
+type PickupItem struct {
+	ProdukID   primitive.ObjectID `bson:"produk_id" json:"produk_id"`
+	ProdukName string             `bson:"produk_name" json:"produk_name"`
+	Qty        int64              `bson:"qty" json:"qty"`
+}
+
+type PickupRepository interface {
+	EnsureIndexes(ctx context.Context) error
+	InsertOne(ctx context.Context, req *Pickup) (*Pickup, error)
+	FindPendingByCode(ctx context.Context, code string) (*Pickup, error)
+	FindAllByStatus(ctx context.Context, status string) ([]Pickup, error)
+	GetAllWithPage(ctx context.Context, rp int64, p int64, filter interface{}, setsort interface{}) ([]Pickup, int64, error)
+	UpdateStatus(ctx context.Context, pickup *Pickup, fromStatus string) error
+}
+
+type PickupUsecase interface {
+	// Create membuat tiket pickup untuk checkout yang sudah dibayar dan mengisi kodenya
+	Create(ctx context.Context, pickup *Pickup) (*Pickup, error)
+	GetMine(ctx context.Context, userID string, rp int64, p int64) ([]*dtos.PickupResponse, int64, error)
+	GetQueue(ctx context.Context, rp int64, p int64) ([]*dtos.PickupResponse, int64, error)
+	MarkPickedUp(ctx context.Context, code string, staffID string) (*dtos.PickupResponse, error)
+	// RefundPending mengembalikan saldo dan stok semua tiket yang belum diambil saat warunk tutup
+	RefundPending(ctx context.Context, actorID string) (int64, error)
+}
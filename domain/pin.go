@@ -0,0 +1,61 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"time"
+	"warunk-bem/dtos"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+var (
+	ErrPinNotSet   = errors.New("PIN transaksi belum diatur")
+	ErrPinRequired = errors.New("PIN transaksi wajib diisi")
+)
+
+// Jenis percobaan yang dihitung terpisah, kunci PIN tetap bisa dibuka lewat reset PIN dengan password
+const (
+	PinAttemptPin      = "pin"
+	PinAttemptPassword = "password"
+)
+
+// TransactionPin disimpan terpisah dari User agar hash PIN tidak ikut terbaca di endpoint profil
+type TransactionPin struct {
+	ID             primitive.ObjectID `bson:"_id" json:"id"`
+	CreatedAt      time.Time          `bson:"created_at" json:"created_at"`
+	UpdatedAt      time.Time          `bson:"updated_at" json:"updated_at"`
+	UserID         primitive.ObjectID `bson:"user_id" json:"user_id"`
+	PinHash        string             `bson:"pin_hash" json:"-"`
+	FailedAttempts int64              `bson:"failed_attempts" json:"failed_attempts"`
+	LockedUntil    *time.Time         `bson:"locked_until,omitempty" json:"locked_until"`
+	// PasswordFailedAttempts dan PasswordLockedUntil menghitung password salah saat password dipakai sebagai konfirmasi
+	PasswordFailedAttempts int64      `bson:"password_failed_attempts" json:"-"`
+	PasswordLockedUntil    *time.Time `bson:"password_locked_until,omitempty" json:"-"`
+}
+
+type PinRepository interface {
+	EnsureIndexes(ctx context.Context) error
+	FindByUser(ctx context.Context, userID primitive.ObjectID) (*TransactionPin, error)
+	// FindAttempts mengembalikan status percobaan user walaupun PIN belum diatur
+	FindAttempts(ctx context.Context, userID primitive.ObjectID) (*TransactionPin, error)
+	Save(ctx context.Context, pin *TransactionPin) error
+	IncrementFailure(ctx context.Context, userID primitive.ObjectID, kind string) (int64, error)
+	Lock(ctx context.Context, userID primitive.ObjectID, kind string, until time.Time) error
+	ResetFailure(ctx context.Context, userID primitive.ObjectID, kind string) error
+}
+
+type PinUsecase interface {
+	GetStatus(ctx context.Context, userID string) (*dtos.PinStatusResponse, error)
+	SetPin(ctx context.Context, userID string, req *dtos.SetPinRequest) error
+	ChangePin(ctx context.Context, userID string, req *dtos.ChangePinRequest) error
+	ResetPin(ctx context.Context, userID string, req *dtos.ResetPinRequest) error
+	// Verify memeriksa PIN dan mencatat percobaan gagal, ErrPinNotSet jika user belum mengatur PIN
+	Verify(ctx context.Context, userID string, pin string) error
+	// Confirm memakai PIN jika user sudah mengatur PIN, selain itu memakai password akun
+	Confirm(ctx context.Context, userID string, pin string, password string) error
+	// RequireForCheckout hanya meminta PIN jika user sudah mengatur PIN dan total melewati batas
+	RequireForCheckout(ctx context.Context, userID string, pin string, total float64) error
+	// ConfirmForCheckout menjalankan Confirm (PIN atau password) hanya jika total melewati batas
+	ConfirmForCheckout(ctx context.Context, userID string, pin string, password string, total float64) error
+}
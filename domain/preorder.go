@@ -0,0 +1,93 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"time"
+	"warunk-bem/dtos"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	PreOrderStatusPending   = "pending"
+	PreOrderStatusFulfilled = "fulfilled"
+	PreOrderStatusReleased  = "released"
+)
+
+var (
+	// ErrPreOrderStatusChanged dikembalikan saat pre-order sudah dipenuhi atau dilepas proses lain
+	ErrPreOrderStatusChanged = errors.New("status pre-order sudah berubah")
+	// ErrPreOrderStockTaken dikembalikan saat stok katalog sudah habis dipesan pre-order pending lain
+	ErrPreOrderStockTaken = errors.New("stok pre-order produk ini sudah habis dipesan")
+)
+
+// PreOrder adalah pesanan yang dibuat saat warunk tutup untuk sesi berikutnya,
+// saldo ditahan (langsung dipotong) sampai pre-order dipenuhi atau dilepas
+type PreOrder struct {
+	ID         primitive.ObjectID `bson:"_id" json:"id"`
+	CreatedAt  time.Time          `bson:"created_at" json:"created_at"`
+	UpdatedAt  time.Time          `bson:"updated_at" json:"updated_at"`
+	UserID     primitive.ObjectID `bson:"user_id" json:"user_id"`
+	ProdukID   primitive.ObjectID `bson:"produk_id" json:"produk_id"`
+	ProdukName string             `bson:"produk_name" json:"produk_name"`
+	Qty        int64              `bson:"qty" json:"qty"`
+	Price      int64              `bson:"price" json:"price"`
+	TotalBayar int64              `bson:"total_bayar" json:"total_bayar"`
+	// SaldoBefore dan SaldoAfter adalah saldo user saat saldo ditahan
+	SaldoBefore float64 `bson:"saldo_before" json:"saldo_before"`
+	SaldoAfter  float64 `bson:"saldo_after" json:"saldo_after"`
+	Status      string  `bson:"status" json:"status"`
+	// ExpiresAt batas menunggu sesi berikutnya dibuka, lewat dari ini saldo dikembalikan
+	ExpiresAt     time.Time          `bson:"expires_at" json:"expires_at"`
+	WarunkID      primitive.ObjectID `bson:"warunk_id,omitempty" json:"warunk_id"`
+	OrderID       primitive.ObjectID `bson:"order_id,omitempty" json:"order_id"`
+	FulfilledAt   *time.Time         `bson:"fulfilled_at,omitempty" json:"fulfilled_at"`
+	ReleasedAt    *time.Time         `bson:"released_at,omitempty" json:"released_at"`
+	ReleaseReason string             `bson:"release_reason,omitempty" json:"release_reason"`
+}
+
+// PreOrderDemand adalah hasil agregasi qty pre-order pending per produk
+type PreOrderDemand struct {
+	ProdukID   primitive.ObjectID `bson:"_id"`
+	ProdukName string             `bson:"produk_name"`
+	Qty        int64              `bson:"qty"`
+	Orders     int64              `bson:"orders"`
+}
+
+// PreOrderReserved adalah jumlah qty pre-order pending satu produk, diubah dengan $inc bersyarat
+// agar pre-order bersamaan tidak bisa melebihi stok katalog sesi warunk
+type PreOrderReserved struct {
+	ID        primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	UpdatedAt time.Time          `bson:"updated_at" json:"updated_at"`
+	ProdukID  primitive.ObjectID `bson:"produk_id" json:"produk_id"`
+	Qty       int64              `bson:"qty" json:"qty"`
+}
+
+type PreOrderRepository interface {
+	EnsureIndexes(ctx context.Context) error
+	InsertOne(ctx context.Context, req *PreOrder) (*PreOrder, error)
+	FindOne(ctx context.Context, id string) (*PreOrder, error)
+	// FindPending mengembalikan pre-order pending yang dibuat sebelum waktu tertentu, terlama lebih dulu
+	FindPending(ctx context.Context, createdBefore time.Time) ([]PreOrder, error)
+	FindExpired(ctx context.Context, now time.Time) ([]PreOrder, error)
+	GetAllWithPage(ctx context.Context, rp int64, p int64, filter interface{}, setsort interface{}) ([]PreOrder, int64, error)
+	// Demand menjumlahkan qty pre-order pending per produk untuk perencanaan stok sesi berikutnya
+	Demand(ctx context.Context) ([]PreOrderDemand, error)
+	UpdateStatus(ctx context.Context, preOrder *PreOrder, fromStatus string) error
+	// ReserveQty mengembalikan ErrPreOrderStockTaken jika qty yang sudah dipesan ditambah qty melebihi maxQty
+	ReserveQty(ctx context.Context, produkID primitive.ObjectID, qty int64, maxQty int64) error
+	ReleaseQty(ctx context.Context, produkID primitive.ObjectID, qty int64) error
+}
+
+type PreOrderUsecase interface {
+	Create(ctx context.Context, userID string, req *dtos.PreOrderRequest) (*dtos.PreOrderResponse, error)
+	GetMine(ctx context.Context, userID string, rp int64, p int64) ([]*dtos.PreOrderResponse, int64, error)
+	Cancel(ctx context.Context, id string, userID string) (*dtos.PreOrderResponse, error)
+	GetDemand(ctx context.Context) ([]dtos.PreOrderDemandResponse, error)
+	// FulfillPending memenuhi pre-order dari stok pembukaan sesi, pre-order yang produknya
+	// tidak ada di katalog atau stoknya tidak cukup dilepas dan saldonya dikembalikan
+	FulfillPending(ctx context.Context, warunk *Warunk, actorID string) (*dtos.PreOrderFulfillResponse, error)
+	// ReleaseExpired mengembalikan saldo pre-order yang sesinya tidak pernah dibuka
+	ReleaseExpired(ctx context.Context) (int64, error)
+}
@@ -2,24 +2,41 @@ package domain
 
 import (
 	"context"
+	"errors"
 	"time"
 	"warunk-bem/dtos"
 
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
+var (
+	// ErrInvalidStockDebit dikembalikan saat qty yang dipotong tidak positif, pemotongan seperti itu justru menambah stok
+	ErrInvalidStockDebit = errors.New("jumlah stok yang dipotong harus lebih dari 0")
+	// ErrStockChanged dikembalikan SetStock saat stok sudah diubah proses lain sejak terakhir dibaca
+	ErrStockChanged = errors.New("stok produk sudah berubah")
+)
+
 type Produk struct {
-	ID        primitive.ObjectID `bson:"_id" json:"id"`
-	CreatedAt time.Time          `bson:"created_at" json:"created_at"`
-	UpdatedAt time.Time          `bson:"updated_at" json:"updated_at"`
-	DeletedAt *time.Time         `bson:"deleted_at,omitempty" json:"deleted_at"`
-	Slug      string             `bson:"slug" json:"slug"`
-	Name      string             `bson:"name" json:"name"`
-	Detail    string             `bson:"detail" json:"detail"`
-	Price     int64              `bson:"price" json:"price"`
-	Stock     int64              `bson:"stock" json:"stock"`
-	Category  string             `bson:"category" json:"category"`
-	Image     string             `bson:"image" json:"image" form:"image"`
+	ID               primitive.ObjectID `bson:"_id" json:"id"`
+	CreatedAt        time.Time          `bson:"created_at" json:"created_at"`
+	UpdatedAt        time.Time          `bson:"updated_at" json:"updated_at"`
+	DeletedAt        *time.Time         `bson:"deleted_at,omitempty" json:"deleted_at"`
+	Slug             string             `bson:"slug" json:"slug"`
+	Name             string             `bson:"name" json:"name"`
+	Detail           string             `bson:"detail" json:"detail"`
+	Price            int64              `bson:"price" json:"price"`
+	Stock            int64              `bson:"stock" json:"stock"`
+	ReorderThreshold int64              `bson:"reorder_threshold" json:"reorder_threshold"`
+	Category         string             `bson:"category" json:"category"`
+	Image            string             `bson:"image" json:"image" form:"image"`
+	Images           []ProdukImage      `bson:"images,omitempty" json:"images"`
+}
+
+type ProdukImage struct {
+	ID      primitive.ObjectID `bson:"_id" json:"id"`
+	URL     string             `bson:"url" json:"url"`
+	Primary bool               `bson:"primary" json:"primary"`
+	Order   int                `bson:"order" json:"order"`
 }
 
 type ProdukRepository interface {
@@ -29,6 +46,11 @@ type ProdukRepository interface {
 	GetAllWithPage(ctx context.Context, rp int64, p int64, filter interface{}, setsort interface{}) ([]Produk, int64, error)
 	UpdateOne(ctx context.Context, produk *Produk, id string) (*Produk, error)
 	DeleteOne(ctx context.Context, id string) error
+	IncrementStock(ctx context.Context, id string, delta int64) (*Produk, error)
+	// DecrementStock memotong stok sebesar qty untuk penjualan, ErrInvalidStockDebit jika qty tidak positif
+	DecrementStock(ctx context.Context, id string, qty int64) (*Produk, error)
+	// SetStock menimpa stok hanya jika stok masih fromStock, ErrStockChanged jika stok sudah berubah
+	SetStock(ctx context.Context, id string, fromStock int64, stock int64) (*Produk, error)
 }
 
 type ProdukUsecase interface {
@@ -37,4 +59,14 @@ type ProdukUsecase interface {
 	GetAllWithPage(ctx context.Context, rp int64, p int64, filter interface{}, setsort interface{}) ([]*dtos.ProdukDetailResponse, int64, error)
 	UpdateOne(ctx context.Context, req *dtos.ProdukUpdateRequest, id string) (*dtos.ProdukDetailResponse, error)
 	DeleteOne(ctx context.Context, id string, idAdmin string, req dtos.DeleteProdukRequest) (res dtos.ResponseMessage, err error)
+	AddImages(ctx context.Context, id string, urls []string) (*dtos.ProdukDetailResponse, error)
+	ReorderImages(ctx context.Context, id string, req *dtos.ReorderProdukImageRequest) (*dtos.ProdukDetailResponse, error)
+	SetPrimaryImage(ctx context.Context, id string, imageID string) (*dtos.ProdukDetailResponse, error)
+	DeleteImage(ctx context.Context, id string, imageID string) (*dtos.ProdukDetailResponse, error)
+	GetLowStock(ctx context.Context, rp int64, p int64) ([]*dtos.ProdukDetailResponse, int64, error)
+}
+
+// IsLowStock bernilai true jika batas reorder diisi dan stok sudah di bawahnya
+func (p *Produk) IsLowStock() bool {
+	return p.ReorderThreshold > 0 && p.Stock < p.ReorderThreshold
 }
@@ -0,0 +1,104 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"time"
+	"warunk-bem/dtos"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	PromoTypePercentage = "percentage"
+	PromoTypeFixed      = "fixed"
+	PromoTypeBuyXGetY   = "buy_x_get_y"
+)
+
+// ErrPromoUserLimitReached dikembalikan saat user sudah memakai promo sebanyak batas per user
+var ErrPromoUserLimitReached = errors.New("batas penggunaan promo untuk akun ini telah tercapai")
+
+type Promo struct {
+	ID           primitive.ObjectID   `bson:"_id" json:"id"`
+	CreatedAt    time.Time            `bson:"created_at" json:"created_at"`
+	UpdatedAt    time.Time            `bson:"updated_at" json:"updated_at"`
+	DeletedAt    *time.Time           `bson:"deleted_at,omitempty" json:"deleted_at"`
+	Code         string               `bson:"code" json:"code"`
+	Name         string               `bson:"name" json:"name"`
+	Type         string               `bson:"type" json:"type"`
+	Value        int64                `bson:"value" json:"value"`
+	MaxDiscount  int64                `bson:"max_discount" json:"max_discount"`
+	BuyQty       int64                `bson:"buy_qty" json:"buy_qty"`
+	GetQty       int64                `bson:"get_qty" json:"get_qty"`
+	ProdukIDs    []primitive.ObjectID `bson:"produk_ids" json:"produk_ids"`
+	Categories   []string             `bson:"categories" json:"categories"`
+	MinSpend     int64                `bson:"min_spend" json:"min_spend"`
+	StartAt      time.Time            `bson:"start_at" json:"start_at"`
+	EndAt        time.Time            `bson:"end_at" json:"end_at"`
+	UsageLimit   int64                `bson:"usage_limit" json:"usage_limit"`
+	UsagePerUser int64                `bson:"usage_per_user" json:"usage_per_user"`
+	UsedCount    int64                `bson:"used_count" json:"used_count"`
+	Active       bool                 `bson:"active" json:"active"`
+}
+
+type PromoUsage struct {
+	ID          primitive.ObjectID `bson:"_id" json:"id"`
+	CreatedAt   time.Time          `bson:"created_at" json:"created_at"`
+	PromoID     primitive.ObjectID `bson:"promo_id" json:"promo_id"`
+	UserID      primitive.ObjectID `bson:"user_id" json:"user_id"`
+	TransaksiID primitive.ObjectID `bson:"transaksi_id" json:"transaksi_id"`
+	Discount    int64              `bson:"discount" json:"discount"`
+}
+
+// PromoItem adalah satu baris belanja yang akan dihitung diskonnya
+type PromoItem struct {
+	ProdukID primitive.ObjectID
+	Category string
+	Price    int64
+	Qty      int64
+}
+
+// PromoResult adalah hasil perhitungan promo untuk satu checkout
+type PromoResult struct {
+	PromoID primitive.ObjectID
+	UserID  primitive.ObjectID
+	// UsagePerUser disalin dari promo agar Redeem bisa mengambil jatah user tanpa membaca ulang promo
+	UsagePerUser int64
+	Code         string
+	Discount     int64
+	LineDiscount map[primitive.ObjectID]int64
+}
+
+type PromoRepository interface {
+	EnsureIndexes(ctx context.Context) error
+	InsertOne(ctx context.Context, req *Promo) (*Promo, error)
+	FindOne(ctx context.Context, id string) (*Promo, error)
+	FindCode(ctx context.Context, code string) (*Promo, error)
+	GetAllWithPage(ctx context.Context, rp int64, p int64, filter interface{}, setsort interface{}) ([]Promo, int64, error)
+	UpdateOne(ctx context.Context, promo *Promo, id string) (*Promo, error)
+	DeleteOne(ctx context.Context, id string) error
+	IncrementUsage(ctx context.Context, id string) error
+	DecrementUsage(ctx context.Context, id string) error
+	InsertUsage(ctx context.Context, req *PromoUsage) (*PromoUsage, error)
+	CountUsageByUser(ctx context.Context, promoID string, userID string) (int64, error)
+	// ReserveUserUsage menambah pemakaian promo oleh user secara atomik, ErrPromoUserLimitReached jika
+	// pemakaian sudah mencapai maxUsage, maxUsage 0 berarti tanpa batas
+	ReserveUserUsage(ctx context.Context, promoID primitive.ObjectID, userID primitive.ObjectID, maxUsage int64) error
+	ReleaseUserUsage(ctx context.Context, promoID primitive.ObjectID, userID primitive.ObjectID) error
+	// DeleteUsageByTransaksi mengembalikan nil jika transaksi tidak memakai promo atau sudah dihapus proses lain
+	DeleteUsageByTransaksi(ctx context.Context, transaksiID primitive.ObjectID) (*PromoUsage, error)
+}
+
+type PromoUsecase interface {
+	InsertOne(ctx context.Context, req *dtos.InsertPromoRequest) (*dtos.PromoDetailResponse, error)
+	FindOne(ctx context.Context, id string) (*dtos.PromoDetailResponse, error)
+	GetAllWithPage(ctx context.Context, rp int64, p int64, filter interface{}, setsort interface{}) ([]*dtos.PromoDetailResponse, int64, error)
+	UpdateOne(ctx context.Context, req *dtos.InsertPromoRequest, id string) (*dtos.PromoDetailResponse, error)
+	DeleteOne(ctx context.Context, id string) error
+	Apply(ctx context.Context, code string, userID string, items []PromoItem) (*PromoResult, error)
+	Redeem(ctx context.Context, result *PromoResult) error
+	Release(ctx context.Context, result *PromoResult) error
+	RecordUsage(ctx context.Context, result *PromoResult, userID string, transaksiID primitive.ObjectID) error
+	// RevertUsage menghapus pemakaian promo transaksi yang dikembalikan dan mengembalikan kuotanya
+	RevertUsage(ctx context.Context, transaksiIDs []primitive.ObjectID) error
+}
@@ -0,0 +1,61 @@
+package domain
+
+import (
+	"context"
+	"time"
+	"warunk-bem/dtos"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	ReviewStatusVisible = "visible"
+	ReviewStatusHidden  = "hidden"
+
+	ReviewMinRating = 1
+	ReviewMaxRating = 5
+)
+
+// Review hanya bisa dibuat dari transaksi berhasil milik user, satu review untuk satu transaksi
+type Review struct {
+	ID          primitive.ObjectID `bson:"_id" json:"id"`
+	CreatedAt   time.Time          `bson:"created_at" json:"created_at"`
+	UpdatedAt   time.Time          `bson:"updated_at" json:"updated_at"`
+	UserID      primitive.ObjectID `bson:"user_id" json:"user_id"`
+	UserName    string             `bson:"user_name" json:"user_name"`
+	ProdukID    primitive.ObjectID `bson:"produk_id" json:"produk_id"`
+	TransaksiID primitive.ObjectID `bson:"transaksi_id" json:"transaksi_id"`
+	Rating      int64              `bson:"rating" json:"rating"`
+	Comment     string             `bson:"comment" json:"comment"`
+	Status      string             `bson:"status" json:"status"`
+	// HiddenReason, ModeratedBy dan ModeratedAt diisi admin saat moderasi
+	HiddenReason string             `bson:"hidden_reason,omitempty" json:"hidden_reason"`
+	ModeratedBy  primitive.ObjectID `bson:"moderated_by,omitempty" json:"moderated_by"`
+	ModeratedAt  *time.Time         `bson:"moderated_at,omitempty" json:"moderated_at"`
+}
+
+// RatingSummary adalah rata-rata rating dan jumlah review yang tampil untuk satu produk
+type RatingSummary struct {
+	ProdukID primitive.ObjectID `bson:"_id" json:"produk_id"`
+	Average  float64            `bson:"average" json:"average"`
+	Count    int64              `bson:"count" json:"count"`
+}
+
+type ReviewRepository interface {
+	EnsureIndexes(ctx context.Context) error
+	InsertOne(ctx context.Context, req *Review) (*Review, error)
+	FindOne(ctx context.Context, id string) (*Review, error)
+	GetAllWithPage(ctx context.Context, rp int64, p int64, filter interface{}, setsort interface{}) ([]Review, int64, error)
+	UpdateStatus(ctx context.Context, review *Review) (*Review, error)
+	GetReviewedTransaksi(ctx context.Context, transaksiIDs []primitive.ObjectID) (map[primitive.ObjectID]bool, error)
+	GetRatingSummary(ctx context.Context, produkIDs []primitive.ObjectID) (map[primitive.ObjectID]RatingSummary, error)
+}
+
+type ReviewUsecase interface {
+	InsertOne(ctx context.Context, userID string, req *dtos.InsertReviewRequest) (*dtos.ReviewResponse, error)
+	GetPending(ctx context.Context, userID string) ([]*dtos.ReviewPendingResponse, error)
+	GetByProduk(ctx context.Context, produkID string, rp int64, p int64) ([]*dtos.ReviewResponse, int64, error)
+	GetAllWithPage(ctx context.Context, rp int64, p int64, filter interface{}) ([]*dtos.ReviewResponse, int64, error)
+	Hide(ctx context.Context, id string, adminID string, req *dtos.HideReviewRequest) (*dtos.ReviewResponse, error)
+	Unhide(ctx context.Context, id string, adminID string) (*dtos.ReviewResponse, error)
+}
@@ -0,0 +1,74 @@
+package domain
+
+import (
+	"context"
+	"time"
+	"warunk-bem/dtos"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	SavedListKindFavorite = "favorite"
+	SavedListKindWishlist = "wishlist"
+	SavedListKindCustom   = "custom"
+)
+
+// SavedList adalah daftar produk tersimpan milik user, favorite dan wishlist
+// adalah list bawaan yang selalu ada satu per user
+type SavedList struct {
+	ID        primitive.ObjectID `bson:"_id" json:"id"`
+	CreatedAt time.Time          `bson:"created_at" json:"created_at"`
+	UpdatedAt time.Time          `bson:"updated_at" json:"updated_at"`
+	UserID    primitive.ObjectID `bson:"user_id" json:"user_id"`
+	Name      string             `bson:"name" json:"name"`
+	Kind      string             `bson:"kind" json:"kind"`
+}
+
+type SavedListItem struct {
+	ID         primitive.ObjectID `bson:"_id" json:"id"`
+	CreatedAt  time.Time          `bson:"created_at" json:"created_at"`
+	ListID     primitive.ObjectID `bson:"list_id" json:"list_id"`
+	UserID     primitive.ObjectID `bson:"user_id" json:"user_id"`
+	ProdukID   primitive.ObjectID `bson:"produk_id" json:"produk_id"`
+	SavedPrice int64              `bson:"saved_price" json:"saved_price"`
+}
+
+// LegacySavedList adalah bentuk dokumen lama di collection favorite dan wishlist
+type LegacySavedList struct {
+	ID        primitive.ObjectID `bson:"_id"`
+	CreatedAt time.Time          `bson:"created_at"`
+	UserID    primitive.ObjectID `bson:"user_id"`
+	Produk    []Produk           `bson:"produk"`
+}
+
+type SavedListRepository interface {
+	EnsureIndexes(ctx context.Context) error
+	InsertList(ctx context.Context, req *SavedList) (*SavedList, error)
+	FindList(ctx context.Context, id string, userID string) (*SavedList, error)
+	FindListByKind(ctx context.Context, userID string, kind string) (*SavedList, error)
+	FindListByName(ctx context.Context, userID string, name string) (*SavedList, error)
+	GetLists(ctx context.Context, userID string) ([]SavedList, error)
+	UpdateList(ctx context.Context, list *SavedList) (*SavedList, error)
+	DeleteList(ctx context.Context, id string) error
+	InsertItem(ctx context.Context, req *SavedListItem) (bool, error)
+	GetItemsWithPage(ctx context.Context, rp int64, p int64, filter interface{}, setsort interface{}) ([]SavedListItem, int64, error)
+	CountItems(ctx context.Context, listID primitive.ObjectID) (int64, error)
+	DeleteItem(ctx context.Context, listID primitive.ObjectID, produkID string) (int64, error)
+	DeleteItems(ctx context.Context, listID primitive.ObjectID) error
+	GetLegacy(ctx context.Context, kind string) ([]LegacySavedList, error)
+	GetUserIDsByProduk(ctx context.Context, produkID primitive.ObjectID, kind string) ([]primitive.ObjectID, error)
+}
+
+type SavedListUsecase interface {
+	GetLists(ctx context.Context, userID string) ([]*dtos.SavedListResponse, error)
+	CreateList(ctx context.Context, userID string, req *dtos.SavedListRequest) (*dtos.SavedListResponse, error)
+	RenameList(ctx context.Context, userID string, listRef string, req *dtos.SavedListRequest) (*dtos.SavedListResponse, error)
+	DeleteList(ctx context.Context, userID string, listRef string) error
+	GetItems(ctx context.Context, userID string, listRef string, rp int64, p int64) ([]*dtos.SavedListItemResponse, int64, error)
+	AddItem(ctx context.Context, userID string, listRef string, produkID string) (*dtos.SavedListItemResponse, error)
+	RemoveItem(ctx context.Context, userID string, listRef string, produkID string) error
+	ClearItems(ctx context.Context, userID string, listRef string) error
+	MoveToCart(ctx context.Context, userID string, listRef string, produkID string, quantity int) (*InsertKeranjangResponse, error)
+	Migrate(ctx context.Context) (*dtos.SavedListMigrationResponse, error)
+}
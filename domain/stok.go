@@ -0,0 +1,61 @@
+package domain
+
+import (
+	"context"
+	"time"
+	"warunk-bem/dtos"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	StokMovementOpening    = "opening"
+	StokMovementSale       = "sale"
+	StokMovementRefund     = "refund"
+	StokMovementRestock    = "restock"
+	StokMovementSpoilage   = "spoilage"
+	StokMovementCorrection = "correction"
+)
+
+// StokMovement adalah satu baris jurnal perubahan stok produk,
+// jumlah seluruh Delta sebuah produk harus sama dengan stok saat ini
+type StokMovement struct {
+	ID          primitive.ObjectID `bson:"_id" json:"id"`
+	CreatedAt   time.Time          `bson:"created_at" json:"created_at"`
+	ProdukID    primitive.ObjectID `bson:"produk_id" json:"produk_id"`
+	Type        string             `bson:"type" json:"type"`
+	Delta       int64              `bson:"delta" json:"delta"`
+	StockBefore int64              `bson:"stock_before" json:"stock_before"`
+	StockAfter  int64              `bson:"stock_after" json:"stock_after"`
+	Reason      string             `bson:"reason" json:"reason"`
+	ActorID     primitive.ObjectID `bson:"actor_id,omitempty" json:"actor_id"`
+	ReferenceID primitive.ObjectID `bson:"reference_id,omitempty" json:"reference_id"`
+}
+
+// NewStokMovement membuat jurnal untuk produk yang stoknya sudah diubah dari stockBefore
+func NewStokMovement(produk *Produk, movementType string, stockBefore int64, reason string, actorID primitive.ObjectID, referenceID primitive.ObjectID) *StokMovement {
+	return &StokMovement{
+		ID:          primitive.NewObjectID(),
+		CreatedAt:   time.Now(),
+		ProdukID:    produk.ID,
+		Type:        movementType,
+		Delta:       produk.Stock - stockBefore,
+		StockBefore: stockBefore,
+		StockAfter:  produk.Stock,
+		Reason:      reason,
+		ActorID:     actorID,
+		ReferenceID: referenceID,
+	}
+}
+
+type StokRepository interface {
+	InsertOne(ctx context.Context, req *StokMovement) (*StokMovement, error)
+	GetAllWithPage(ctx context.Context, rp int64, p int64, filter interface{}, setsort interface{}) ([]StokMovement, int64, error)
+	SumDelta(ctx context.Context, produkID string) (int64, int64, error)
+}
+
+type StokUsecase interface {
+	Adjust(ctx context.Context, req *dtos.StokAdjustRequest, adminID string) (*dtos.StokMovementResponse, error)
+	GetHistory(ctx context.Context, produkID string, rp int64, p int64) ([]*dtos.StokMovementResponse, int64, error)
+	Reconcile(ctx context.Context, produkID string) (*dtos.StokReconcileResponse, error)
+}
@@ -2,26 +2,72 @@ package domain
 
 import (
 	"context"
+	"strings"
 	"time"
 	"warunk-bem/dtos"
 
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
+// TransaksiStatusRefunded dipakai saat pesanan tidak diambil dan saldo dikembalikan
+const TransaksiStatusRefunded = "Dikembalikan"
+
+// TransaksiChannelKasir menandai penjualan langsung di meja warunk, transaksi dari aplikasi tidak punya channel
+const TransaksiChannelKasir = "kasir"
+
+// TransaksiChannelPreOrder menandai transaksi dari pre-order yang dipenuhi saat sesi warunk dibuka
+const TransaksiChannelPreOrder = "preorder"
+
+const (
+	PaymentMethodCash  = "cash"
+	PaymentMethodSaldo = "saldo"
+)
+
 type Transaksi struct {
 	ID        primitive.ObjectID `bson:"_id" json:"id"`
 	CreatedAt time.Time          `bson:"created_at" json:"created_at"`
 	UpdatedAt time.Time          `bson:"updated_at" json:"updated_at"`
-	UserID    primitive.ObjectID `bson:"user_id" json:"user_id"`
+	// UserID tidak disimpan untuk penjualan kasir tunai atau QRIS karena pembelinya tidak dikenal
+	UserID primitive.ObjectID `bson:"user_id,omitempty" json:"user_id,omitempty"`
+	// OrderID sama untuk semua baris dari satu checkout keranjang
+	OrderID   primitive.ObjectID `bson:"order_id,omitempty" json:"order_id"`
 	ProdukID  primitive.ObjectID `bson:"produk_id" json:"produk_id"`
 	Total     int64              `bson:"total" json:"total"`
+	Harga     int64              `bson:"harga" json:"harga"`
+	Discount  int64              `bson:"discount" json:"discount"`
+	PromoCode string             `bson:"promo_code,omitempty" json:"promo_code,omitempty"`
 	Status    string             `bson:"status" json:"status"`
+	// SaldoBefore dan SaldoAfter adalah saldo user sebelum dan sesudah checkout, kosong untuk transaksi lama
+	SaldoBefore *float64 `bson:"saldo_before,omitempty" json:"saldo_before,omitempty"`
+	SaldoAfter  *float64 `bson:"saldo_after,omitempty" json:"saldo_after,omitempty"`
+	// Channel diisi untuk penjualan kasir dan pre-order, PaymentMethod, PaymentRef dan CashierID
+	// hanya untuk penjualan kasir
+	Channel       string             `bson:"channel,omitempty" json:"channel,omitempty"`
+	PaymentMethod string             `bson:"payment_method,omitempty" json:"payment_method,omitempty"`
+	PaymentRef    string             `bson:"payment_ref,omitempty" json:"payment_ref,omitempty"`
+	CashierID     primitive.ObjectID `bson:"cashier_id,omitempty" json:"cashier_id,omitempty"`
+}
+
+// Order mengembalikan ID checkout, transaksi lama tanpa OrderID memakai ID-nya sendiri
+func (t *Transaksi) Order() primitive.ObjectID {
+	if t.OrderID.IsZero() {
+		return t.ID
+	}
+	return t.OrderID
+}
+
+// ReceiptNumber adalah nomor struk yang mudah dibaca, misalnya WB-20240131-9F3A1C
+func (t *Transaksi) ReceiptNumber() string {
+	order := t.Order().Hex()
+	return "WB-" + t.CreatedAt.Format("20060102") + "-" + strings.ToUpper(order[len(order)-6:])
 }
 
 type TransaksiRepository interface {
 	InsertOne(ctx context.Context, req *Transaksi) (*Transaksi, error)
 	FindOne(ctx context.Context, id string) (*Transaksi, error)
 	FindAllByUserId(ctx context.Context, id string) ([]*Transaksi, error)
+	FindByOrder(ctx context.Context, orderID string) ([]Transaksi, error)
+	UpdateStatusByOrder(ctx context.Context, orderID primitive.ObjectID, status string) error
 	GetAllWithPage(ctx context.Context, rp int64, p int64, filter interface{}, setsort interface{}) ([]Transaksi, int64, error)
 	UpdateOne(ctx context.Context, transaksi *Transaksi, id string) (*Transaksi, error)
 	DeleteOne(ctx context.Context, id string) error
@@ -31,6 +77,10 @@ type TransaksiUsecase interface {
 	InsertOne(ctx context.Context, req *dtos.InsertTransaksiRequest) (*dtos.InsertTransaksiResponse, error)
 	InsertByKeranjang(ctx context.Context, req *dtos.InsertTransaksiKeranjangRequest) (*dtos.InsertTransaksiResponse, error)
 	FindAll(ctx context.Context, id string) (res []*dtos.RiwayatTransaksiResponse, err error)
+	GetReceipt(ctx context.Context, orderID string, userID string) (*dtos.ReceiptResponse, error)
+	GetReceiptPDF(ctx context.Context, orderID string, userID string) (*dtos.ReceiptResponse, []byte, error)
+	EmailReceipt(ctx context.Context, orderID string, userID string) error
+	InsertKasir(ctx context.Context, cashierID string, req *dtos.KasirSaleRequest) (*dtos.KasirSaleResponse, error)
 	// GetAllWithPage(ctx context.Context, rp int64, p int64, filter interface{}, setsort interface{}) ([]dtos.UserProfileResponse, int64, error)
 	// UpdateOne(ctx context.Context, user *dtos.UpdateUserRequest, id string) (*dtos.UpdateUserResponse, error)
 	// DeleteOne(c context.Context, id string, req dtos.DeleteUserRequest) (res dtos.ResponseMessage, err error)
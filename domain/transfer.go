@@ -0,0 +1,58 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"time"
+	"warunk-bem/dtos"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	TransferDirectionIn  = "in"
+	TransferDirectionOut = "out"
+)
+
+// ErrTransferLimitReached dikembalikan saat transfer melebihi limit nominal atau jumlah transfer harian
+var ErrTransferLimitReached = errors.New("limit transfer harian sudah tercapai")
+
+// Transfer adalah perpindahan saldo antar user, dicatat sekali dan tampil di riwayat pengirim dan penerima
+type Transfer struct {
+	ID               primitive.ObjectID `bson:"_id" json:"id"`
+	CreatedAt        time.Time          `bson:"created_at" json:"created_at"`
+	SenderID         primitive.ObjectID `bson:"sender_id" json:"sender_id"`
+	SenderUsername   string             `bson:"sender_username" json:"sender_username"`
+	ReceiverID       primitive.ObjectID `bson:"receiver_id" json:"receiver_id"`
+	ReceiverUsername string             `bson:"receiver_username" json:"receiver_username"`
+	Amount           float64            `bson:"amount" json:"amount"`
+	Note             string             `bson:"note" json:"note"`
+}
+
+// TransferDaily adalah pemakaian limit transfer satu user dalam satu hari (Day berformat 2006-01-02),
+// diubah dengan $inc bersyarat agar dua transfer bersamaan tidak bisa sama-sama lolos limit
+type TransferDaily struct {
+	ID        primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	UpdatedAt time.Time          `bson:"updated_at" json:"updated_at"`
+	UserID    primitive.ObjectID `bson:"user_id" json:"user_id"`
+	Day       string             `bson:"day" json:"day"`
+	Amount    float64            `bson:"amount" json:"amount"`
+	Count     int64              `bson:"count" json:"count"`
+}
+
+type TransferRepository interface {
+	EnsureIndexes(ctx context.Context) error
+	InsertOne(ctx context.Context, req *Transfer) (*Transfer, error)
+	// ReserveDaily menambah pemakaian limit harian, ErrTransferLimitReached jika melewati maxAmount atau maxCount
+	ReserveDaily(ctx context.Context, senderID primitive.ObjectID, day string, amount float64, maxAmount float64, maxCount int64) error
+	// ReleaseDaily mengembalikan pemakaian limit harian ketika transfer gagal setelah limit dipesan
+	ReleaseDaily(ctx context.Context, senderID primitive.ObjectID, day string, amount float64) error
+	GetDaily(ctx context.Context, senderID primitive.ObjectID, day string) (float64, int64, error)
+	GetAllWithPage(ctx context.Context, rp int64, p int64, filter interface{}, setsort interface{}) ([]Transfer, int64, error)
+}
+
+type TransferUsecase interface {
+	Transfer(ctx context.Context, userID string, req *dtos.TransferRequest) (*dtos.TransferResponse, error)
+	GetAllWithPage(ctx context.Context, userID string, rp int64, p int64) ([]*dtos.TransferResponse, int64, error)
+	GetLimit(ctx context.Context, userID string) (*dtos.TransferLimitResponse, error)
+}
@@ -22,6 +22,14 @@ type User struct {
 	VerificationCode int                `bson:"verification" json:"verification"`
 	ActivationCode   int                `bson:"activation_code" json:"activation_code"`
 	Role             string             `bson:"role" json:"role" validate:"required"`
+	// NotifikasiPreference nilai kosong berarti user menerima semua notifikasi
+	NotifikasiPreference NotifikasiPreference `bson:"notifikasi_preference" json:"notifikasi_preference"`
+}
+
+type NotifikasiPreference struct {
+	MuteBackInStock bool `bson:"mute_back_in_stock" json:"mute_back_in_stock"`
+	MutePriceDrop   bool `bson:"mute_price_drop" json:"mute_price_drop"`
+	MuteEmail       bool `bson:"mute_email" json:"mute_email"`
 }
 
 type UserRepository interface {
@@ -2,12 +2,16 @@ package domain
 
 import (
 	"context"
+	"errors"
 	"time"
 	"warunk-bem/dtos"
 
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
+// ErrNegativeDebit dikembalikan saat jumlah yang dipotong negatif, pemotongan seperti itu justru menambah saldo
+var ErrNegativeDebit = errors.New("jumlah saldo yang dipotong tidak boleh negatif")
+
 type UserAmount struct {
 	ID        primitive.ObjectID `bson:"_id" json:"id"`
 	CreatedAt time.Time          `bson:"created_at" json:"created_at"`
@@ -16,12 +20,62 @@ type UserAmount struct {
 	Amount    float64            `bson:"amount" json:"amount"`
 }
 
+const (
+	TopUpSourceAdmin   = "admin"
+	TopUpSourceRequest = "request"
+)
+
+const (
+	TopUpRequestStatusPending  = "pending"
+	TopUpRequestStatusApproved = "approved"
+	TopUpRequestStatusRejected = "rejected"
+)
+
+// TopUp mencatat setiap saldo yang masuk ke akun user
+type TopUp struct {
+	ID        primitive.ObjectID `bson:"_id" json:"id"`
+	CreatedAt time.Time          `bson:"created_at" json:"created_at"`
+	UserID    primitive.ObjectID `bson:"user_id" json:"user_id"`
+	Amount    float64            `bson:"amount" json:"amount"`
+	Source    string             `bson:"source" json:"source"`
+	// ReferenceID menunjuk dokumen asal top up, misalnya TopUpRequest
+	ReferenceID primitive.ObjectID `bson:"reference_id,omitempty" json:"reference_id"`
+}
+
+// TopUpRequest adalah pengajuan top up dari user yang menunggu persetujuan bendahara
+type TopUpRequest struct {
+	ID           primitive.ObjectID `bson:"_id" json:"id"`
+	CreatedAt    time.Time          `bson:"created_at" json:"created_at"`
+	UpdatedAt    time.Time          `bson:"updated_at" json:"updated_at"`
+	UserID       primitive.ObjectID `bson:"user_id" json:"user_id"`
+	Amount       float64            `bson:"amount" json:"amount"`
+	Note         string             `bson:"note" json:"note"`
+	ReceiptURL   string             `bson:"receipt_url" json:"receipt_url"`
+	Status       string             `bson:"status" json:"status"`
+	RejectReason string             `bson:"reject_reason,omitempty" json:"reject_reason"`
+	ReviewedBy   primitive.ObjectID `bson:"reviewed_by,omitempty" json:"reviewed_by"`
+	ReviewedAt   *time.Time         `bson:"reviewed_at,omitempty" json:"reviewed_at"`
+}
+
 type UserAmountRepository interface {
 	InsertOne(ctx context.Context, req *UserAmount) (res *UserAmount, err error)
 	FindOne(ctx context.Context, id string) (res *UserAmount, err error)
-	UpdateOne(ctx context.Context, amount *UserAmount, id string) (res *UserAmount, err error)
+	// IncrementAmount dan DecrementAmount adalah satu-satunya cara mengubah saldo agar perubahan yang bersamaan tidak saling menimpa
+	IncrementAmount(ctx context.Context, userID string, delta float64) (res *UserAmount, err error)
+	// DecrementAmount memotong saldo sebesar amount, ErrNegativeDebit jika amount negatif
+	DecrementAmount(ctx context.Context, userID string, amount float64) (res *UserAmount, err error)
+	InsertTopUp(ctx context.Context, req *TopUp) error
+	InsertTopUpRequest(ctx context.Context, req *TopUpRequest) (*TopUpRequest, error)
+	FindTopUpRequest(ctx context.Context, id string) (*TopUpRequest, error)
+	GetTopUpRequestsWithPage(ctx context.Context, rp int64, p int64, filter interface{}, setsort interface{}) ([]TopUpRequest, int64, error)
+	UpdateTopUpRequestStatus(ctx context.Context, req *TopUpRequest, fromStatus string) error
 }
 
 type UserAmountUsecase interface {
 	TopUpSaldo(ctx context.Context, req *dtos.TopUpSaldoRequest) (res *dtos.TopUpSaldoResponse, err error)
+	RequestTopUp(ctx context.Context, userID string, req *dtos.TopUpRequestRequest, receiptURL string) (*dtos.TopUpRequestResponse, error)
+	GetMyTopUpRequests(ctx context.Context, userID string, rp int64, p int64) ([]*dtos.TopUpRequestResponse, int64, error)
+	GetTopUpRequests(ctx context.Context, rp int64, p int64, filter interface{}) ([]*dtos.TopUpRequestResponse, int64, error)
+	ApproveTopUpRequest(ctx context.Context, id string, adminID string) (*dtos.TopUpRequestResponse, error)
+	RejectTopUpRequest(ctx context.Context, id string, adminID string, req *dtos.RejectTopUpRequestRequest) (*dtos.TopUpRequestResponse, error)
 }
@@ -0,0 +1,55 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"time"
+	"warunk-bem/dtos"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// UserQRPrefix penanda isi QR identitas user agar tidak tertukar dengan QR pickup
+const UserQRPrefix = "WARUNK-PAY:"
+
+var (
+	ErrUserQRInvalid = errors.New("QR pembayaran tidak valid")
+	ErrUserQRExpired = errors.New("QR pembayaran sudah kedaluwarsa, minta pembeli memuat ulang QR")
+	ErrUserQRUsed    = errors.New("QR pembayaran sudah dipakai, minta pembeli memuat ulang QR")
+)
+
+// UserQRNonce mencatat nonce setiap QR yang diterbitkan, nonce hanya bisa dipakai sekali
+// sehingga screenshot QR tidak bisa dipakai ulang
+type UserQRNonce struct {
+	ID        primitive.ObjectID `bson:"_id" json:"id"`
+	CreatedAt time.Time          `bson:"created_at" json:"created_at"`
+	UserID    primitive.ObjectID `bson:"user_id" json:"user_id"`
+	Nonce     string             `bson:"nonce" json:"nonce"`
+	ExpiresAt time.Time          `bson:"expires_at" json:"expires_at"`
+	UsedAt    *time.Time         `bson:"used_at,omitempty" json:"used_at"`
+}
+
+// UserQRClaims adalah isi QR yang ditandatangani
+type UserQRClaims struct {
+	UserID    string `json:"uid"`
+	ExpiresAt int64  `json:"exp"`
+	Nonce     string `json:"nonce"`
+}
+
+type UserQRRepository interface {
+	EnsureIndexes(ctx context.Context) error
+	InsertOne(ctx context.Context, nonce *UserQRNonce) error
+	FindByNonce(ctx context.Context, nonce string) (*UserQRNonce, error)
+	// Consume menandai nonce terpakai secara atomik, ErrUserQRUsed jika nonce sudah dipakai
+	Consume(ctx context.Context, nonce string, usedAt time.Time) error
+}
+
+type UserQRUsecase interface {
+	Generate(ctx context.Context, userID string) (*dtos.UserQRResponse, error)
+	// Resolve hanya membaca pemilik QR tanpa memakai nonce, untuk ditampilkan ke kasir
+	Resolve(ctx context.Context, payload string) (*dtos.UserQRResolveResponse, error)
+	// Verify memeriksa QR dan mengembalikan pemiliknya tanpa memakai nonce
+	Verify(ctx context.Context, payload string) (*User, error)
+	// Consume memakai nonce QR, dipanggil setelah saldo berhasil dipotong agar QR tidak hangus saat penjualan gagal
+	Consume(ctx context.Context, payload string) error
+}
@@ -3,6 +3,7 @@ package domain
 import (
 	"context"
 	"time"
+	"warunk-bem/dtos"
 
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
@@ -37,6 +38,8 @@ type InsertWarunkResponse struct {
 	UserID string   `json:"user_id"`
 	Produk []Produk `json:"produk"`
 	Status string   `json:"status"`
+	// PreOrder jumlah pre-order yang dipenuhi dan dilepas saat warunk dibuka
+	PreOrder *dtos.PreOrderFulfillResponse `json:"preorder,omitempty"`
 }
 
 type WarunkRepository interface {
@@ -0,0 +1,107 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"time"
+	"warunk-bem/dtos"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	WithdrawalStatusPending   = "pending"
+	WithdrawalStatusApproved  = "approved"
+	WithdrawalStatusPaid      = "paid"
+	WithdrawalStatusRejected  = "rejected"
+	WithdrawalStatusCancelled = "cancelled"
+	WithdrawalStatusExpired   = "expired"
+)
+
+const (
+	WithdrawalMethodCash     = "cash"
+	WithdrawalMethodTransfer = "transfer"
+)
+
+const (
+	WithdrawalLedgerHold    = "hold"
+	WithdrawalLedgerApprove = "approve"
+	WithdrawalLedgerPayout  = "payout"
+	WithdrawalLedgerRelease = "release"
+)
+
+var (
+	// ErrWithdrawalStatusChanged dikembalikan saat pengajuan sudah diproses proses lain
+	ErrWithdrawalStatusChanged = errors.New("status penarikan saldo sudah berubah")
+	// ErrWithdrawalOpenExists dikembalikan saat user masih punya pengajuan pending atau approved
+	ErrWithdrawalOpenExists = errors.New("masih ada penarikan saldo yang belum selesai")
+)
+
+// Withdrawal adalah pengajuan penarikan saldo, saldo ditahan (langsung dipotong) sejak diajukan
+// dan dikembalikan jika ditolak, dibatalkan, atau tidak disetujui sampai ExpiresAt
+type Withdrawal struct {
+	ID            primitive.ObjectID `bson:"_id" json:"id"`
+	CreatedAt     time.Time          `bson:"created_at" json:"created_at"`
+	UpdatedAt     time.Time          `bson:"updated_at" json:"updated_at"`
+	UserID        primitive.ObjectID `bson:"user_id" json:"user_id"`
+	Amount        float64            `bson:"amount" json:"amount"`
+	Method        string             `bson:"method" json:"method"`
+	BankName      string             `bson:"bank_name,omitempty" json:"bank_name"`
+	AccountNumber string             `bson:"account_number,omitempty" json:"account_number"`
+	AccountName   string             `bson:"account_name,omitempty" json:"account_name"`
+	Note          string             `bson:"note" json:"note"`
+	Status        string             `bson:"status" json:"status"`
+	// Open true selama pending atau approved, unique index parsial pada user_id
+	// menjamin user hanya punya satu pengajuan terbuka
+	Open bool `bson:"open" json:"-"`
+	// ExpiresAt batas menunggu persetujuan, pengajuan yang sudah disetujui tidak pernah expired
+	ExpiresAt    time.Time          `bson:"expires_at" json:"expires_at"`
+	RejectReason string             `bson:"reject_reason,omitempty" json:"reject_reason"`
+	ReviewedBy   primitive.ObjectID `bson:"reviewed_by,omitempty" json:"reviewed_by"`
+	ReviewedAt   *time.Time         `bson:"reviewed_at,omitempty" json:"reviewed_at"`
+	PayoutRef    string             `bson:"payout_ref,omitempty" json:"payout_ref"`
+	PaidBy       primitive.ObjectID `bson:"paid_by,omitempty" json:"paid_by"`
+	PaidAt       *time.Time         `bson:"paid_at,omitempty" json:"paid_at"`
+	ReleasedAt   *time.Time         `bson:"released_at,omitempty" json:"released_at"`
+}
+
+// WithdrawalLedger mencatat setiap langkah penarikan saldo, Amount adalah perubahan saldo user
+// (negatif saat ditahan, positif saat dikembalikan, nol saat disetujui dan dicairkan)
+type WithdrawalLedger struct {
+	ID           primitive.ObjectID `bson:"_id" json:"id"`
+	CreatedAt    time.Time          `bson:"created_at" json:"created_at"`
+	WithdrawalID primitive.ObjectID `bson:"withdrawal_id" json:"withdrawal_id"`
+	UserID       primitive.ObjectID `bson:"user_id" json:"user_id"`
+	Type         string             `bson:"type" json:"type"`
+	Amount       float64            `bson:"amount" json:"amount"`
+	BalanceAfter *float64           `bson:"balance_after,omitempty" json:"balance_after"`
+	ActorID      primitive.ObjectID `bson:"actor_id,omitempty" json:"actor_id"`
+	Note         string             `bson:"note,omitempty" json:"note"`
+}
+
+type WithdrawalRepository interface {
+	EnsureIndexes(ctx context.Context) error
+	InsertOne(ctx context.Context, req *Withdrawal) (*Withdrawal, error)
+	FindOne(ctx context.Context, id string) (*Withdrawal, error)
+	// CountOpenByUser menghitung pengajuan user yang masih pending atau menunggu pencairan
+	// InsertOne mengembalikan ErrWithdrawalOpenExists jika user sudah punya pengajuan terbuka
+	CountOpenByUser(ctx context.Context, userID primitive.ObjectID) (int64, error)
+	FindExpired(ctx context.Context, now time.Time) ([]Withdrawal, error)
+	GetAllWithPage(ctx context.Context, rp int64, p int64, filter interface{}, setsort interface{}) ([]Withdrawal, int64, error)
+	UpdateStatus(ctx context.Context, withdrawal *Withdrawal, fromStatus string) error
+	InsertLedger(ctx context.Context, ledger *WithdrawalLedger) error
+	FindLedger(ctx context.Context, withdrawalID primitive.ObjectID) ([]WithdrawalLedger, error)
+}
+
+type WithdrawalUsecase interface {
+	Request(ctx context.Context, userID string, req *dtos.WithdrawalRequest) (*dtos.WithdrawalResponse, error)
+	GetMine(ctx context.Context, userID string, rp int64, p int64) ([]*dtos.WithdrawalResponse, int64, error)
+	Cancel(ctx context.Context, id string, userID string) (*dtos.WithdrawalResponse, error)
+	GetAll(ctx context.Context, rp int64, p int64, filter interface{}) ([]*dtos.WithdrawalResponse, int64, error)
+	Approve(ctx context.Context, id string, adminID string) (*dtos.WithdrawalResponse, error)
+	Reject(ctx context.Context, id string, adminID string, req *dtos.RejectWithdrawalRequest) (*dtos.WithdrawalResponse, error)
+	MarkPaid(ctx context.Context, id string, adminID string, req *dtos.PayoutWithdrawalRequest) (*dtos.WithdrawalResponse, error)
+	GetLedger(ctx context.Context, id string) ([]dtos.WithdrawalLedgerResponse, error)
+	// ExpirePending mengembalikan saldo pengajuan pending yang tidak disetujui sampai batas waktu
+	ExpirePending(ctx context.Context) (int64, error)
+}
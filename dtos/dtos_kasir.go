@@ -0,0 +1,35 @@
+package dtos
+
+type KasirSaleRequest struct {
+	Items         []KasirSaleItem `json:"items" validate:"required,min=1,dive"`
+	PaymentMethod string          `json:"payment_method" validate:"required,oneof=cash saldo qris" example:"cash"`
+	// CashReceived wajib untuk pembayaran cash, kembalian dihitung dari nilai ini
+	CashReceived int64 `json:"cash_received" validate:"gte=0" example:"20000"`
+	// PaymentRef adalah nomor referensi dari aplikasi merchant QRIS, wajib untuk pembayaran qris
+	PaymentRef string `json:"payment_ref" validate:"max=100" example:"QRIS-0001234"`
+	// BuyerQR adalah isi QR dari profil pembeli, jika diisi BuyerUsername tidak diperlukan
+	// dan Pin atau Password hanya diminta jika total melewati batas checkout PIN
+	BuyerQR string `json:"buyer_qr" example:"WARUNK-PAY:eyJ1aWQiOi4uLn0.c2lnbmF0dXJl"`
+	// Tanpa BuyerQR, BuyerUsername dan Pin (atau Password jika pembeli belum mengatur PIN) wajib untuk pembayaran saldo
+	BuyerUsername string `json:"buyer_username" example:"r4ha"`
+	Pin           string `json:"pin" validate:"omitempty,len=6,numeric" example:"123456"`
+	Password      string `json:"password" example:"rahadinabudimansundara"`
+}
+
+type KasirSaleItem struct {
+	ProdukID string `json:"produk_id" validate:"required" example:"64a1f0c2e4b0a1b2c3d4e5f6"`
+	Qty      int64  `json:"qty" validate:"gt=0" example:"2"`
+}
+
+type KasirSaleResponse struct {
+	OrderID       string                `json:"order_id"`
+	ReceiptNumber string                `json:"receipt_number"`
+	PaymentMethod string                `json:"payment_method"`
+	BuyerName     string                `json:"buyer_name,omitempty"`
+	Items         []ReceiptItemResponse `json:"items"`
+	TotalBayar    int64                 `json:"total_bayar"`
+	CashReceived  int64                 `json:"cash_received,omitempty"`
+	Change        int64                 `json:"change,omitempty"`
+	PaymentRef    string                `json:"payment_ref,omitempty"`
+	SaldoAfter    *float64              `json:"saldo_after,omitempty"`
+}
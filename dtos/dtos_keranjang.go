@@ -1,10 +1,11 @@
 package dtos
 
 type DetailKeranjang struct {
-	ID     string               `bson:"_id" json:"id"`
-	UserID string               `bson:"user_id" json:"user_id"`
-	Produk ProdukDetailResponse `bson:"produk" json:"produk"`
-	Total  int                  `bson:"total" json:"total"`
+	ID       string               `bson:"_id" json:"id"`
+	UserID   string               `bson:"user_id" json:"user_id"`
+	Produk   ProdukDetailResponse `bson:"produk" json:"produk"`
+	Total    int                  `bson:"total" json:"total"`
+	Subtotal int64                `json:"subtotal"`
 }
 
 type InsertKeranjangRequest struct {
@@ -17,6 +18,10 @@ type UpdateKeranjangRequest struct {
 	Produk ProdukDetailResponse `json:"produk"`
 }
 
+type SetQuantityKeranjangRequest struct {
+	Quantity int `json:"quantity" validate:"min=0" example:"2"`
+}
+
 type DeleteProductKeranjangRequest struct {
 	KeranjangID string `json:"keranjang_id"`
 	ProdukID    string `json:"produk_id"`
@@ -25,3 +30,34 @@ type DeleteProductKeranjangRequest struct {
 type DeleteProductKeranjang struct {
 	Name string `json:"name"`
 }
+
+type KeranjangPreviewItem struct {
+	ProdukID          string `json:"produk_id"`
+	Name              string `json:"name"`
+	Image             string `json:"image"`
+	Quantity          int64  `json:"quantity"`
+	CartPrice         int64  `json:"cart_price"`
+	CurrentPrice      int64  `json:"current_price"`
+	Stock             int64  `json:"stock"`
+	LineTotal         int64  `json:"line_total"`
+	PriceChanged      bool   `json:"price_changed"`
+	OutOfStock        bool   `json:"out_of_stock"`
+	InsufficientStock bool   `json:"insufficient_stock"`
+	Deleted           bool   `json:"deleted"`
+	Available         bool   `json:"available"`
+}
+
+type KeranjangPreviewResponse struct {
+	ID          string                 `json:"id"`
+	Items       []KeranjangPreviewItem `json:"items"`
+	TotalItem   int64                  `json:"total_item"`
+	Subtotal    int64                  `json:"subtotal"`
+	Discount    int64                  `json:"discount"`
+	TotalBayar  int64                  `json:"total_bayar"`
+	PromoCode   string                 `json:"promo_code,omitempty"`
+	PromoError  string                 `json:"promo_error,omitempty"`
+	Saldo       float64                `json:"saldo"`
+	SaldoAfter  float64                `json:"saldo_after"`
+	SaldoCukup  bool                   `json:"saldo_cukup"`
+	CanCheckout bool                   `json:"can_checkout"`
+}
@@ -0,0 +1,8 @@
+package dtos
+
+// NotifikasiPreferenceRequest field yang tidak dikirim tidak diubah
+type NotifikasiPreferenceRequest struct {
+	MuteBackInStock *bool `json:"mute_back_in_stock" example:"false"`
+	MutePriceDrop   *bool `json:"mute_price_drop" example:"false"`
+	MuteEmail       *bool `json:"mute_email" example:"true"`
+}
@@ -0,0 +1,30 @@
+package dtos
+
+import "time"
+
+type NotifikasiResponse struct {
+	ID          string     `json:"id"`
+	CreatedAt   time.Time  `json:"created_at"`
+	Type        string     `json:"type"`
+	Title       string     `json:"title"`
+	Message     string     `json:"message"`
+	ReferenceID string     `json:"reference_id,omitempty"`
+	ReadAt      *time.Time `json:"read_at"`
+}
+
+type GetAllNotifikasiResponse struct {
+	Total       int64                 `json:"total"`
+	Unread      int64                 `json:"unread"`
+	PerPage     int64                 `json:"per_page"`
+	CurrentPage int64                 `json:"current_page"`
+	LastPage    int64                 `json:"last_page"`
+	From        int64                 `json:"from"`
+	To          int64                 `json:"to"`
+	Notifikasi  []*NotifikasiResponse `json:"notifikasi"`
+}
+
+type NotifikasiPreferenceResponse struct {
+	MuteBackInStock bool `json:"mute_back_in_stock"`
+	MutePriceDrop   bool `json:"mute_price_drop"`
+	MuteEmail       bool `json:"mute_email"`
+}
@@ -0,0 +1,45 @@
+package dtos
+
+import "time"
+
+type PaymentTopUpRequest struct {
+	Amount float64 `json:"amount" validate:"required,gt=0" example:"50000"`
+	Method string  `json:"method" validate:"required,oneof=qris va" example:"qris"`
+	Bank   string  `json:"bank" validate:"required_if=Method va,omitempty,oneof=bca bni bri mandiri" example:"bca"`
+}
+
+type PaymentSimulateRequest struct {
+	Status string `json:"status" validate:"required,oneof=paid expired failed" example:"paid"`
+}
+
+type PaymentInstructionResponse struct {
+	QRString string `json:"qr_string,omitempty"`
+	Bank     string `json:"bank,omitempty"`
+	VANumber string `json:"va_number,omitempty"`
+}
+
+type PaymentResponse struct {
+	ID          string                     `json:"id"`
+	CreatedAt   time.Time                  `json:"created_at"`
+	Provider    string                     `json:"provider"`
+	Method      string                     `json:"method"`
+	Amount      float64                    `json:"amount"`
+	Status      string                     `json:"status"`
+	Instruction PaymentInstructionResponse `json:"instruction"`
+	ExpiresAt   time.Time                  `json:"expires_at"`
+	PaidAt      *time.Time                 `json:"paid_at,omitempty"`
+}
+
+type GetAllPaymentResponse struct {
+	Total       int64              `json:"total"`
+	PerPage     int64              `json:"per_page"`
+	CurrentPage int64              `json:"current_page"`
+	LastPage    int64              `json:"last_page"`
+	From        int64              `json:"from"`
+	To          int64              `json:"to"`
+	Payment     []*PaymentResponse `json:"payments"`
+}
+
+type PaymentExpireResponse struct {
+	Expired int64 `json:"expired"`
+}
@@ -0,0 +1,37 @@
+package dtos
+
+import "time"
+
+type PickupResponse struct {
+	ID            string               `json:"id"`
+	CreatedAt     time.Time            `json:"created_at"`
+	OrderID       string               `json:"order_id"`
+	ReceiptNumber string               `json:"receipt_number"`
+	UserName      string               `json:"user_name"`
+	Code          string               `json:"code"`
+	QRString      string               `json:"qr_string"`
+	Items         []PickupItemResponse `json:"items"`
+	TotalBayar    int64                `json:"total_bayar"`
+	Status        string               `json:"status"`
+	PickedUpAt    *time.Time           `json:"picked_up_at"`
+	RefundedAt    *time.Time           `json:"refunded_at"`
+}
+
+type PickupItemResponse struct {
+	ProdukName string `json:"produk_name"`
+	Qty        int64  `json:"qty"`
+}
+
+type GetAllPickupResponse struct {
+	Total       int64             `json:"total"`
+	PerPage     int64             `json:"per_page"`
+	CurrentPage int64             `json:"current_page"`
+	LastPage    int64             `json:"last_page"`
+	From        int64             `json:"from"`
+	To          int64             `json:"to"`
+	Pickup      []*PickupResponse `json:"pickups"`
+}
+
+type PickupRefundResponse struct {
+	Refunded int64 `json:"refunded"`
+}
@@ -0,0 +1,26 @@
+package dtos
+
+import "time"
+
+type SetPinRequest struct {
+	Pin      string `json:"pin" validate:"required,len=6,numeric" example:"123456"`
+	Password string `json:"password" validate:"required" example:"rahadinabudimansundara"`
+}
+
+type ChangePinRequest struct {
+	OldPin string `json:"old_pin" validate:"required,len=6,numeric" example:"123456"`
+	NewPin string `json:"new_pin" validate:"required,len=6,numeric" example:"654321"`
+}
+
+// ResetPinRequest dipakai saat user lupa PIN atau PIN terkunci, dikonfirmasi dengan password akun
+type ResetPinRequest struct {
+	Password string `json:"password" validate:"required" example:"rahadinabudimansundara"`
+	NewPin   string `json:"new_pin" validate:"required,len=6,numeric" example:"654321"`
+}
+
+type PinStatusResponse struct {
+	Enabled           bool       `json:"enabled"`
+	RemainingAttempts int64      `json:"remaining_attempts"`
+	LockedUntil       *time.Time `json:"locked_until"`
+	CheckoutThreshold float64    `json:"checkout_threshold"`
+}
@@ -0,0 +1,48 @@
+package dtos
+
+import "time"
+
+type PreOrderRequest struct {
+	ProdukID string `json:"produk_id" validate:"required" example:"64a1f0c2e4b0a1b2c3d4e5f6"`
+	Qty      int64  `json:"qty" validate:"required,gt=0" example:"2"`
+	// Pin wajib jika user sudah mengatur PIN dan total melewati batas checkout
+	Pin string `json:"pin" validate:"omitempty,len=6,numeric" example:"123456"`
+}
+
+type PreOrderResponse struct {
+	ID            string     `json:"id"`
+	CreatedAt     time.Time  `json:"created_at"`
+	ProdukID      string     `json:"produk_id"`
+	ProdukName    string     `json:"produk_name"`
+	Qty           int64      `json:"qty"`
+	Price         int64      `json:"price"`
+	TotalBayar    int64      `json:"total_bayar"`
+	Status        string     `json:"status"`
+	ExpiresAt     time.Time  `json:"expires_at"`
+	OrderID       string     `json:"order_id,omitempty"`
+	FulfilledAt   *time.Time `json:"fulfilled_at"`
+	ReleasedAt    *time.Time `json:"released_at"`
+	ReleaseReason string     `json:"release_reason,omitempty"`
+}
+
+type GetAllPreOrderResponse struct {
+	Total       int64               `json:"total"`
+	PerPage     int64               `json:"per_page"`
+	CurrentPage int64               `json:"current_page"`
+	LastPage    int64               `json:"last_page"`
+	From        int64               `json:"from"`
+	To          int64               `json:"to"`
+	PreOrder    []*PreOrderResponse `json:"preorders"`
+}
+
+type PreOrderDemandResponse struct {
+	ProdukID   string `json:"produk_id"`
+	ProdukName string `json:"produk_name"`
+	Qty        int64  `json:"qty"`
+	Orders     int64  `json:"orders"`
+}
+
+type PreOrderFulfillResponse struct {
+	Fulfilled int64 `json:"fulfilled"`
+	Released  int64 `json:"released"`
+}
@@ -8,17 +8,18 @@ import (
 )
 
 type InsertProdukRequest struct {
-	ID        primitive.ObjectID    `bson:"_id" json:"id"`
-	CreatedAt time.Time             `json:"created_at"`
-	UpdatedAt time.Time             `json:"updated_at"`
-	DeletedAt primitive.ObjectID    `bson:"deleted_at" json:"deleted_at"`
-	Slug      string                `bson:"slug" json:"slug" form:"slug"`
-	Name      string                `bson:"name" json:"name" form:"name" validate:"required"`
-	Detail    string                `bson:"detail" json:"detail" form:"detail" validate:"required"`
-	Price     int64                 `bson:"price" json:"price" form:"price" validate:"required"`
-	Stock     int64                 `bson:"stock" json:"stock" form:"stock" validate:"required"`
-	Category  string                `bson:"category" json:"category" form:"category" validate:"required"`
-	Image     *multipart.FileHeader `bson:"image" json:"image" form:"image" validate:"required"`
+	ID               primitive.ObjectID    `bson:"_id" json:"id"`
+	CreatedAt        time.Time             `json:"created_at"`
+	UpdatedAt        time.Time             `json:"updated_at"`
+	DeletedAt        primitive.ObjectID    `bson:"deleted_at" json:"deleted_at"`
+	Slug             string                `bson:"slug" json:"slug" form:"slug"`
+	Name             string                `bson:"name" json:"name" form:"name" validate:"required"`
+	Detail           string                `bson:"detail" json:"detail" form:"detail" validate:"required"`
+	Price            int64                 `bson:"price" json:"price" form:"price" validate:"required"`
+	Stock            int64                 `bson:"stock" json:"stock" form:"stock" validate:"required"`
+	ReorderThreshold int64                 `bson:"reorder_threshold" json:"reorder_threshold" form:"reorder_threshold" validate:"min=0"`
+	Category         string                `bson:"category" json:"category" form:"category" validate:"required"`
+	Image            *multipart.FileHeader `bson:"image" json:"image" form:"image" validate:"required"`
 }
 
 type ImageProdukRequest struct {
@@ -26,12 +27,17 @@ type ImageProdukRequest struct {
 }
 
 type ProdukUpdateRequest struct {
-	Name     string `bson:"name" json:"name"`
-	Detail   string `bson:"detail" json:"detail"`
-	Price    int64  `bson:"price" json:"price"`
-	Stock    int64  `bson:"stock" json:"stock"`
-	Category string `bson:"category" json:"category"`
-	Image    string `bson:"image" json:"image"`
+	Name             string `bson:"name" json:"name"`
+	Detail           string `bson:"detail" json:"detail"`
+	Price            int64  `bson:"price" json:"price"`
+	Stock            int64  `bson:"stock" json:"stock"`
+	ReorderThreshold int64  `bson:"reorder_threshold" json:"reorder_threshold"`
+	Category         string `bson:"category" json:"category"`
+	Image            string `bson:"image" json:"image"`
+}
+
+type ReorderProdukImageRequest struct {
+	ImageIDs []string `json:"image_ids" validate:"required,min=1"`
 }
 
 type DeleteProdukRequest struct {
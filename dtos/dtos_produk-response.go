@@ -1,24 +1,39 @@
 package dtos
 
 type InsertProdukResponse struct {
-	Name     string `bson:"name" json:"name"`
-	Slug     string `bson:"slug" json:"slug"`
-	Detail   string `bson:"detail" json:"detail"`
-	Price    int64  `bson:"price" json:"price"`
-	Stock    int64  `bson:"stock" json:"stock"`
-	Category string `bson:"category" json:"category"`
-	Image    string `bson:"image" json:"image"`
+	Name             string `bson:"name" json:"name"`
+	Slug             string `bson:"slug" json:"slug"`
+	Detail           string `bson:"detail" json:"detail"`
+	Price            int64  `bson:"price" json:"price"`
+	Stock            int64  `bson:"stock" json:"stock"`
+	ReorderThreshold int64  `bson:"reorder_threshold" json:"reorder_threshold"`
+	Category         string `bson:"category" json:"category"`
+	Image            string `bson:"image" json:"image"`
 }
 
 type ProdukDetailResponse struct {
-	ID       string `bson:"_id" json:"id"`
-	Name     string `bson:"name" json:"name"`
-	Slug     string `bson:"slug" json:"slug"`
-	Detail   string `bson:"detail" json:"detail"`
-	Price    int64  `bson:"price" json:"price"`
-	Stock    int64  `bson:"stock" json:"stock"`
-	Category string `bson:"category" json:"category"`
-	Image    string `bson:"image" json:"image"`
+	ID               string                `bson:"_id" json:"id"`
+	Name             string                `bson:"name" json:"name"`
+	Slug             string                `bson:"slug" json:"slug"`
+	Detail           string                `bson:"detail" json:"detail"`
+	Price            int64                 `bson:"price" json:"price"`
+	Stock            int64                 `bson:"stock" json:"stock"`
+	ReorderThreshold int64                 `bson:"reorder_threshold" json:"reorder_threshold"`
+	LowStock         bool                  `json:"low_stock"`
+	Category         string                `bson:"category" json:"category"`
+	Image            string                `bson:"image" json:"image"`
+	Images           []ProdukImageResponse `bson:"images" json:"images"`
+	RatingAverage    float64               `json:"rating_average"`
+	RatingCount      int64                 `json:"rating_count"`
+}
+
+type ProdukImageResponse struct {
+	ID        string `json:"id"`
+	URL       string `json:"url"`
+	Thumbnail string `json:"thumbnail,omitempty"`
+	Medium    string `json:"medium,omitempty"`
+	Primary   bool   `json:"primary"`
+	Order     int    `json:"order"`
 }
 
 type GetAllProdukResponse struct {
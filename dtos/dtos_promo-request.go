@@ -0,0 +1,21 @@
+package dtos
+
+import "time"
+
+type InsertPromoRequest struct {
+	Code         string    `json:"code" validate:"required" example:"JUMATBERKAH"`
+	Name         string    `json:"name" validate:"required" example:"Jumat Berkah"`
+	Type         string    `json:"type" validate:"required,oneof=percentage fixed buy_x_get_y" example:"percentage"`
+	Value        int64     `json:"value" example:"10"`
+	MaxDiscount  int64     `json:"max_discount" example:"5000"`
+	BuyQty       int64     `json:"buy_qty" example:"2"`
+	GetQty       int64     `json:"get_qty" example:"1"`
+	ProdukIDs    []string  `json:"produk_ids"`
+	Categories   []string  `json:"categories"`
+	MinSpend     int64     `json:"min_spend" example:"10000"`
+	StartAt      time.Time `json:"start_at" validate:"required"`
+	EndAt        time.Time `json:"end_at" validate:"required"`
+	UsageLimit   int64     `json:"usage_limit" example:"100"`
+	UsagePerUser int64     `json:"usage_per_user" example:"1"`
+	Active       bool      `json:"active" example:"true"`
+}
@@ -0,0 +1,33 @@
+package dtos
+
+import "time"
+
+type PromoDetailResponse struct {
+	ID           string    `json:"id"`
+	Code         string    `json:"code"`
+	Name         string    `json:"name"`
+	Type         string    `json:"type"`
+	Value        int64     `json:"value"`
+	MaxDiscount  int64     `json:"max_discount"`
+	BuyQty       int64     `json:"buy_qty"`
+	GetQty       int64     `json:"get_qty"`
+	ProdukIDs    []string  `json:"produk_ids"`
+	Categories   []string  `json:"categories"`
+	MinSpend     int64     `json:"min_spend"`
+	StartAt      time.Time `json:"start_at"`
+	EndAt        time.Time `json:"end_at"`
+	UsageLimit   int64     `json:"usage_limit"`
+	UsagePerUser int64     `json:"usage_per_user"`
+	UsedCount    int64     `json:"used_count"`
+	Active       bool      `json:"active"`
+}
+
+type GetAllPromoResponse struct {
+	Total       int64                  `json:"total"`
+	PerPage     int64                  `json:"per_page"`
+	CurrentPage int64                  `json:"current_page"`
+	LastPage    int64                  `json:"last_page"`
+	From        int64                  `json:"from"`
+	To          int64                  `json:"to"`
+	Promo       []*PromoDetailResponse `json:"promos"`
+}
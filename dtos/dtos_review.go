@@ -0,0 +1,45 @@
+package dtos
+
+import "time"
+
+type InsertReviewRequest struct {
+	TransaksiID string `json:"transaksi_id" validate:"required" example:"65a1f0c2e4b0a1b2c3d4e5f6"`
+	Rating      int64  `json:"rating" validate:"required,min=1,max=5" example:"5"`
+	Comment     string `json:"comment" validate:"max=500" example:"Keripiknya renyah, recommended"`
+}
+
+type HideReviewRequest struct {
+	Reason string `json:"reason" validate:"required,max=200" example:"Mengandung kata kasar"`
+}
+
+type ReviewResponse struct {
+	ID           string     `json:"id"`
+	CreatedAt    time.Time  `json:"created_at"`
+	UserID       string     `json:"user_id"`
+	UserName     string     `json:"user_name"`
+	ProdukID     string     `json:"produk_id"`
+	TransaksiID  string     `json:"transaksi_id"`
+	Rating       int64      `json:"rating"`
+	Comment      string     `json:"comment"`
+	Status       string     `json:"status"`
+	HiddenReason string     `json:"hidden_reason,omitempty"`
+	ModeratedAt  *time.Time `json:"moderated_at,omitempty"`
+}
+
+type ReviewPendingResponse struct {
+	TransaksiID string    `json:"transaksi_id"`
+	CreatedAt   time.Time `json:"created_at"`
+	ProdukID    string    `json:"produk_id"`
+	ProdukName  string    `json:"produk_name"`
+	Image       string    `json:"image"`
+}
+
+type GetAllReviewResponse struct {
+	Total       int64             `json:"total"`
+	PerPage     int64             `json:"per_page"`
+	CurrentPage int64             `json:"current_page"`
+	LastPage    int64             `json:"last_page"`
+	From        int64             `json:"from"`
+	To          int64             `json:"to"`
+	Review      []*ReviewResponse `json:"reviews"`
+}
@@ -0,0 +1,57 @@
+package dtos
+
+import "time"
+
+type SavedListRequest struct {
+	Name string `json:"name" validate:"required,max=50" example:"Jajan Jumat"`
+}
+
+type SavedListItemRequest struct {
+	ProdukID string `json:"produk_id" validate:"required" example:"64a1f0c2e4b0a1b2c3d4e5f6"`
+}
+
+type MoveToCartRequest struct {
+	Quantity int `json:"quantity" example:"1"`
+}
+
+type SavedListResponse struct {
+	ID        string    `json:"id"`
+	Name      string    `json:"name"`
+	Kind      string    `json:"kind"`
+	TotalItem int64     `json:"total_item"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type SavedListItemResponse struct {
+	ID         string    `json:"id"`
+	ListID     string    `json:"list_id"`
+	ProdukID   string    `json:"produk_id"`
+	Name       string    `json:"name"`
+	Slug       string    `json:"slug"`
+	Image      string    `json:"image"`
+	Category   string    `json:"category"`
+	Price      int64     `json:"price"`
+	SavedPrice int64     `json:"saved_price"`
+	Stock      int64     `json:"stock"`
+	Available  bool      `json:"available"`
+	Deleted    bool      `json:"deleted"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+type GetAllSavedListItemResponse struct {
+	Total       int64                    `json:"total"`
+	PerPage     int64                    `json:"per_page"`
+	CurrentPage int64                    `json:"current_page"`
+	LastPage    int64                    `json:"last_page"`
+	From        int64                    `json:"from"`
+	To          int64                    `json:"to"`
+	Items       []*SavedListItemResponse `json:"items"`
+}
+
+type SavedListMigrationResponse struct {
+	Lists         int64 `json:"lists"`
+	Items         int64 `json:"items"`
+	SkippedItems  int64 `json:"skipped_items"`
+	LegacyRecords int64 `json:"legacy_records"`
+}
@@ -0,0 +1,8 @@
+package dtos
+
+type StokAdjustRequest struct {
+	ProdukID string `json:"produk_id" validate:"required" example:"64a1f0c2e4b0a1b2c3d4e5f6"`
+	Type     string `json:"type" validate:"required,oneof=restock spoilage correction" example:"restock"`
+	Quantity int64  `json:"quantity" validate:"required" example:"10"`
+	Reason   string `json:"reason" validate:"required" example:"Restock dari supplier"`
+}
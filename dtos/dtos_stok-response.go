@@ -0,0 +1,36 @@
+package dtos
+
+import "time"
+
+type StokMovementResponse struct {
+	ID          string    `json:"id"`
+	CreatedAt   time.Time `json:"created_at"`
+	ProdukID    string    `json:"produk_id"`
+	Type        string    `json:"type"`
+	Delta       int64     `json:"delta"`
+	StockBefore int64     `json:"stock_before"`
+	StockAfter  int64     `json:"stock_after"`
+	Reason      string    `json:"reason"`
+	ActorID     string    `json:"actor_id,omitempty"`
+	ReferenceID string    `json:"reference_id,omitempty"`
+}
+
+type GetAllStokMovementResponse struct {
+	Total       int64                   `json:"total"`
+	PerPage     int64                   `json:"per_page"`
+	CurrentPage int64                   `json:"current_page"`
+	LastPage    int64                   `json:"last_page"`
+	From        int64                   `json:"from"`
+	To          int64                   `json:"to"`
+	Movement    []*StokMovementResponse `json:"movement"`
+}
+
+type StokReconcileResponse struct {
+	ProdukID      string `json:"produk_id"`
+	Name          string `json:"name"`
+	Stock         int64  `json:"stock"`
+	JournalStock  int64  `json:"journal_stock"`
+	Difference    int64  `json:"difference"`
+	MovementCount int64  `json:"movement_count"`
+	Balanced      bool   `json:"balanced"`
+}
@@ -0,0 +1,40 @@
+package dtos
+
+import (
+	"mime/multipart"
+	"time"
+)
+
+type TopUpRequestRequest struct {
+	Amount  float64               `json:"amount" form:"amount" validate:"required,gt=0" example:"50000"`
+	Note    string                `json:"note" form:"note" validate:"max=200" example:"Transfer 50k ke rekening BEM"`
+	Receipt *multipart.FileHeader `json:"receipt" form:"receipt" swaggerignore:"true"`
+}
+
+type RejectTopUpRequestRequest struct {
+	Reason string `json:"reason" validate:"required,max=200" example:"Bukti transfer tidak terbaca"`
+}
+
+type TopUpRequestResponse struct {
+	ID           string     `json:"id"`
+	CreatedAt    time.Time  `json:"created_at"`
+	UserID       string     `json:"user_id"`
+	Name         string     `json:"name,omitempty"`
+	Email        string     `json:"email,omitempty"`
+	Amount       float64    `json:"amount"`
+	Note         string     `json:"note"`
+	ReceiptURL   string     `json:"receipt_url"`
+	Status       string     `json:"status"`
+	RejectReason string     `json:"reject_reason,omitempty"`
+	ReviewedAt   *time.Time `json:"reviewed_at,omitempty"`
+}
+
+type GetAllTopUpRequestResponse struct {
+	Total       int64                   `json:"total"`
+	PerPage     int64                   `json:"per_page"`
+	CurrentPage int64                   `json:"current_page"`
+	LastPage    int64                   `json:"last_page"`
+	From        int64                   `json:"from"`
+	To          int64                   `json:"to"`
+	Request     []*TopUpRequestResponse `json:"requests"`
+}
@@ -12,12 +12,27 @@ type InsertTransaksiRequest struct {
 	UpdatedAt time.Time          `json:"updated_at"`
 	UserID    primitive.ObjectID `bson:"user_id" json:"user_id"`
 	ProdukID  primitive.ObjectID `bson:"produk_id" json:"produk_id"`
-	Total     int                `bson:"total" json:"total"`
+	Total     int                `bson:"total" json:"total" validate:"gt=0"`
+	PromoCode string             `json:"promo_code"`
+	Pin       string             `json:"pin"`
+	// EmailReceipt mengirim struk ke email user setelah checkout berhasil
+	EmailReceipt bool `json:"email_receipt"`
 }
 
 type InsertTransaksiKeranjangRequest struct {
-	ID     primitive.ObjectID `bson:"_id" json:"id"`
-	UserID primitive.ObjectID `bson:"user_id" json:"user_id"`
+	ID        primitive.ObjectID       `bson:"_id" json:"id"`
+	UserID    primitive.ObjectID       `bson:"user_id" json:"user_id"`
+	PromoCode string                   `json:"promo_code"`
+	Items     []TransaksiKeranjangItem `json:"items"`
+	Pin       string                   `json:"pin"`
+	// EmailReceipt mengirim struk ke email user setelah checkout berhasil
+	EmailReceipt bool `json:"email_receipt" example:"true"`
+}
+
+// TransaksiKeranjangItem memilih baris keranjang yang dibeli, quantity 0 berarti seluruh jumlah di keranjang
+type TransaksiKeranjangItem struct {
+	ProdukID string `json:"produk_id" example:"64a1f0c2e4b0a1b2c3d4e5f6"`
+	Quantity int64  `json:"quantity" example:"1"`
 }
 
 type GetTransaksiByUSerIDRequest struct {
@@ -25,6 +40,10 @@ type GetTransaksiByUSerIDRequest struct {
 }
 
 type TransaksiRequest struct {
-	ProdukID string `json:"produk_id"`
-	Total    int    `json:"total"`
+	ProdukID  string `json:"produk_id"`
+	Total     int    `json:"total" validate:"gt=0" example:"1"`
+	PromoCode string `json:"promo_code" example:"JUMATBERKAH"`
+	// Pin wajib jika user sudah mengatur PIN transaksi dan total belanja melewati batas
+	Pin          string `json:"pin" example:"123456"`
+	EmailReceipt bool   `json:"email_receipt" example:"true"`
 }
@@ -1,16 +1,55 @@
 package dtos
 
+import "time"
+
 type InsertTransaksiResponse struct {
-	Name       string `json:"name"`
-	ProdukName string `json:"produk_name"`
-	Total      int64  `json:"total"`
+	OrderID       string `json:"order_id"`
+	ReceiptNumber string `json:"receipt_number"`
+	Name          string `json:"name"`
+	ProdukName    string `json:"produk_name"`
+	Total         int64  `json:"total"`
+	Subtotal      int64  `json:"subtotal"`
+	Discount      int64  `json:"discount"`
+	TotalBayar    int64  `json:"total_bayar"`
+	PromoCode     string `json:"promo_code,omitempty"`
+	// PickupCode ditunjukkan ke staff warunk saat mengambil barang, PickupQR adalah isi QR untuk kode yang sama
+	PickupCode string `json:"pickup_code,omitempty"`
+	PickupQR   string `json:"pickup_qr,omitempty"`
 }
 
 type RiwayatTransaksiResponse struct {
-	Name      string `json:"name"`
-	CreatedAt string `json:"created_at"`
-	Waktu     string `json:"waktu"`
-	Harga     int64  `json:"harga"`
-	Total     int64  `json:"total"`
-	Image     string `json:"image"`
+	ID            string `json:"id"`
+	OrderID       string `json:"order_id"`
+	ReceiptNumber string `json:"receipt_number"`
+	Name          string `json:"name"`
+	CreatedAt     string `json:"created_at"`
+	Waktu         string `json:"waktu"`
+	Harga         int64  `json:"harga"`
+	Discount      int64  `json:"discount"`
+	Total         int64  `json:"total"`
+	Image         string `json:"image"`
+}
+
+type ReceiptResponse struct {
+	OrderID       string                `json:"order_id"`
+	ReceiptNumber string                `json:"receipt_number"`
+	CreatedAt     time.Time             `json:"created_at"`
+	Name          string                `json:"name"`
+	Email         string                `json:"email"`
+	Status        string                `json:"status"`
+	Items         []ReceiptItemResponse `json:"items"`
+	Subtotal      int64                 `json:"subtotal"`
+	Discount      int64                 `json:"discount"`
+	TotalBayar    int64                 `json:"total_bayar"`
+	PromoCode     string                `json:"promo_code,omitempty"`
+	SaldoBefore   *float64              `json:"saldo_before"`
+	SaldoAfter    *float64              `json:"saldo_after"`
+}
+
+type ReceiptItemResponse struct {
+	ProdukName string `json:"produk_name"`
+	Qty        int64  `json:"qty"`
+	Price      int64  `json:"price"`
+	Subtotal   int64  `json:"subtotal"`
+	Discount   int64  `json:"discount"`
 }
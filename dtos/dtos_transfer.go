@@ -0,0 +1,40 @@
+package dtos
+
+import "time"
+
+type TransferRequest struct {
+	Username string  `json:"username" validate:"required" example:"r4ha"`
+	Amount   float64 `json:"amount" validate:"required,gt=0" example:"5000"`
+	Note     string  `json:"note" validate:"max=100" example:"Bayar gorengan"`
+	// Pin wajib jika user sudah mengatur PIN transaksi, selain itu konfirmasi memakai Password
+	Pin      string `json:"pin" validate:"omitempty,len=6,numeric" example:"123456"`
+	Password string `json:"password" example:"rahadinabudimansundara"`
+}
+
+type TransferResponse struct {
+	ID           string    `json:"id"`
+	CreatedAt    time.Time `json:"created_at"`
+	Direction    string    `json:"direction"`
+	Counterparty string    `json:"counterparty"`
+	Amount       float64   `json:"amount"`
+	Note         string    `json:"note"`
+}
+
+type GetAllTransferResponse struct {
+	Total       int64               `json:"total"`
+	PerPage     int64               `json:"per_page"`
+	CurrentPage int64               `json:"current_page"`
+	LastPage    int64               `json:"last_page"`
+	From        int64               `json:"from"`
+	To          int64               `json:"to"`
+	Transfer    []*TransferResponse `json:"transfers"`
+}
+
+type TransferLimitResponse struct {
+	DailyLimit     float64 `json:"daily_limit"`
+	DailyCount     int64   `json:"daily_count"`
+	UsedAmount     float64 `json:"used_amount"`
+	UsedCount      int64   `json:"used_count"`
+	RemainingLimit float64 `json:"remaining_limit"`
+	RemainingCount int64   `json:"remaining_count"`
+}
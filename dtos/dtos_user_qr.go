@@ -0,0 +1,21 @@
+package dtos
+
+import "time"
+
+type UserQRResponse struct {
+	// Payload adalah isi QR yang ditampilkan ke kasir
+	Payload   string    `json:"payload"`
+	ExpiresAt time.Time `json:"expires_at"`
+	ExpiresIn int64     `json:"expires_in"`
+}
+
+type ResolveUserQRRequest struct {
+	Payload string `json:"payload" validate:"required" example:"WARUNK-PAY:eyJ1aWQiOi4uLn0.c2lnbmF0dXJl"`
+}
+
+type UserQRResolveResponse struct {
+	UserID    string    `json:"user_id"`
+	Name      string    `json:"name"`
+	Username  string    `json:"username"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
@@ -0,0 +1,65 @@
+package dtos
+
+import "time"
+
+type WithdrawalRequest struct {
+	Amount float64 `json:"amount" validate:"required,gt=0" example:"50000"`
+	// Method cash diambil di sekretariat, transfer dikirim ke rekening yang diisi
+	Method        string `json:"method" validate:"required,oneof=cash transfer" example:"transfer"`
+	BankName      string `json:"bank_name" validate:"required_if=Method transfer,max=50" example:"BCA"`
+	AccountNumber string `json:"account_number" validate:"required_if=Method transfer,max=30" example:"1234567890"`
+	AccountName   string `json:"account_name" validate:"required_if=Method transfer,max=100" example:"Rahadina Budiman Sundara"`
+	Note          string `json:"note" validate:"max=200" example:"Sudah lulus"`
+	// Pin wajib jika user sudah mengatur PIN, selain itu Password akun wajib diisi
+	Pin      string `json:"pin" validate:"omitempty,len=6,numeric" example:"123456"`
+	Password string `json:"password" example:"rahadinabudimansundara"`
+}
+
+type RejectWithdrawalRequest struct {
+	Reason string `json:"reason" validate:"required,max=200" example:"Nomor rekening tidak sesuai"`
+}
+
+type PayoutWithdrawalRequest struct {
+	// PayoutRef nomor referensi transfer atau catatan penyerahan tunai
+	PayoutRef string `json:"payout_ref" validate:"max=100" example:"TRF-20240131-0001"`
+}
+
+type WithdrawalResponse struct {
+	ID            string     `json:"id"`
+	CreatedAt     time.Time  `json:"created_at"`
+	UserID        string     `json:"user_id"`
+	Name          string     `json:"name,omitempty"`
+	Email         string     `json:"email,omitempty"`
+	Amount        float64    `json:"amount"`
+	Method        string     `json:"method"`
+	BankName      string     `json:"bank_name,omitempty"`
+	AccountNumber string     `json:"account_number,omitempty"`
+	AccountName   string     `json:"account_name,omitempty"`
+	Note          string     `json:"note"`
+	Status        string     `json:"status"`
+	ExpiresAt     time.Time  `json:"expires_at"`
+	RejectReason  string     `json:"reject_reason,omitempty"`
+	ReviewedAt    *time.Time `json:"reviewed_at,omitempty"`
+	PayoutRef     string     `json:"payout_ref,omitempty"`
+	PaidAt        *time.Time `json:"paid_at,omitempty"`
+	ReleasedAt    *time.Time `json:"released_at,omitempty"`
+}
+
+type GetAllWithdrawalResponse struct {
+	Total       int64                 `json:"total"`
+	PerPage     int64                 `json:"per_page"`
+	CurrentPage int64                 `json:"current_page"`
+	LastPage    int64                 `json:"last_page"`
+	From        int64                 `json:"from"`
+	To          int64                 `json:"to"`
+	Withdrawal  []*WithdrawalResponse `json:"withdrawals"`
+}
+
+type WithdrawalLedgerResponse struct {
+	CreatedAt    time.Time `json:"created_at"`
+	Type         string    `json:"type"`
+	Amount       float64   `json:"amount"`
+	BalanceAfter *float64  `json:"balance_after,omitempty"`
+	ActorID      string    `json:"actor_id,omitempty"`
+	Note         string    `json:"note,omitempty"`
+}
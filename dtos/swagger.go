@@ -75,48 +75,24 @@ type ProdukCreatedResponse struct {
 	Data       ProdukDetailResponse `json:"data"`
 }
 
-type FavoriteCreatedResponse struct {
-	StatusCode int                    `json:"status_code" example:"201"`
-	Message    string                 `json:"message" example:"Successfully registered"`
-	Data       DetailFavoriteResponse `json:"data"`
-}
-
 type TransaksiCreatedResponse struct {
 	StatusCode int                     `json:"status_code" example:"201"`
 	Message    string                  `json:"message" example:"Successfully registered"`
 	Data       InsertTransaksiResponse `json:"data"`
 }
 
+type KasirSaleCreatedResponse struct {
+	StatusCode int               `json:"status_code" example:"201"`
+	Message    string            `json:"message" example:"Penjualan Berhasil"`
+	Data       KasirSaleResponse `json:"data"`
+}
+
 type TransaksiAllByUserIDResponse struct {
 	StatusCode int                        `json:"status_code" example:"201"`
 	Message    string                     `json:"message" example:"Successfully registered"`
 	Data       []RiwayatTransaksiResponse `json:"data"`
 }
 
-type WishlistCreatedResponse struct {
-	StatusCode int                    `json:"status_code" example:"201"`
-	Message    string                 `json:"message" example:"Successfully registered"`
-	Data       DetailWishlistResponse `json:"data"`
-}
-
-type FavoriteOKResponse struct {
-	StatusCode int                    `json:"status_code" example:"200"`
-	Message    string                 `json:"message" example:"Successfully registered"`
-	Data       DetailFavoriteResponse `json:"data"`
-}
-
-type FavoriteUpdatedResponse struct {
-	StatusCode int                    `json:"status_code" example:"200"`
-	Message    string                 `json:"message" example:"Successfully registered"`
-	Data       DetailFavoriteResponse `json:"data"`
-}
-
-type DeleteProductFavoriteResponse struct {
-	StatusCode int                     `json:"status_code" example:"201"`
-	Message    string                  `json:"message" example:"Successfully deleted"`
-	Data       DelelteFavoriteResponse `json:"data"`
-}
-
 type DeleteProductKeranjangResponse struct {
 	StatusCode int                    `json:"status_code" example:"201"`
 	Message    string                 `json:"message" example:"Successfully deleted"`
@@ -147,6 +123,228 @@ type GetAllUserResponses struct {
 	Data       UserDetailResponse `json:"data"`
 }
 
+type PromoOKResponse struct {
+	StatusCode int                 `json:"status_code" example:"200"`
+	Message    string              `json:"message" example:"Successfully"`
+	Data       PromoDetailResponse `json:"data"`
+}
+
+type KeranjangPreviewOKResponse struct {
+	StatusCode int                      `json:"status_code" example:"200"`
+	Message    string                   `json:"message" example:"Successfully"`
+	Data       KeranjangPreviewResponse `json:"data"`
+}
+
+type SavedListsOKResponse struct {
+	StatusCode int                 `json:"status_code" example:"200"`
+	Message    string              `json:"message" example:"Success Get Saved List"`
+	Data       []SavedListResponse `json:"data"`
+}
+
+type SavedListOKResponse struct {
+	StatusCode int               `json:"status_code" example:"200"`
+	Message    string            `json:"message" example:"Success Create Saved List"`
+	Data       SavedListResponse `json:"data"`
+}
+
+type SavedListItemsOKResponse struct {
+	StatusCode int                         `json:"status_code" example:"200"`
+	Message    string                      `json:"message" example:"Success Get Saved List"`
+	Data       GetAllSavedListItemResponse `json:"data"`
+}
+
+type SavedListItemOKResponse struct {
+	StatusCode int                   `json:"status_code" example:"201"`
+	Message    string                `json:"message" example:"Success"`
+	Data       SavedListItemResponse `json:"data"`
+}
+
+type SavedListMigrationOKResponse struct {
+	StatusCode int                        `json:"status_code" example:"200"`
+	Message    string                     `json:"message" example:"Success Migrate Saved List"`
+	Data       SavedListMigrationResponse `json:"data"`
+}
+
+type NotifikasiPreferenceOKResponse struct {
+	StatusCode int                          `json:"status_code" example:"200"`
+	Message    string                       `json:"message" example:"Success Get Notifikasi Preference"`
+	Data       NotifikasiPreferenceResponse `json:"data"`
+}
+
+type DashboardOKResponse struct {
+	StatusCode int                    `json:"status_code" example:"200"`
+	Message    string                 `json:"message" example:"Success"`
+	Data       map[string]interface{} `json:"data"`
+}
+
+type AdminAnalyticsOKResponse struct {
+	StatusCode int                    `json:"status_code" example:"200"`
+	Message    string                 `json:"message" example:"Success"`
+	Data       map[string]interface{} `json:"data"`
+}
+
+type CacheStatsOKResponse struct {
+	StatusCode int                    `json:"status_code" example:"200"`
+	Message    string                 `json:"message" example:"success"`
+	Data       map[string]interface{} `json:"data"`
+}
+
+type ReviewOKResponse struct {
+	StatusCode int            `json:"status_code" example:"200"`
+	Message    string         `json:"message" example:"Success Insert Review"`
+	Data       ReviewResponse `json:"data"`
+}
+
+type ReviewsOKResponse struct {
+	StatusCode int                  `json:"status_code" example:"200"`
+	Message    string               `json:"message" example:"Success Get Review"`
+	Data       GetAllReviewResponse `json:"data"`
+}
+
+type ReviewPendingOKResponse struct {
+	StatusCode int                      `json:"status_code" example:"200"`
+	Message    string                   `json:"message" example:"Success Get Pending Review"`
+	Data       []*ReviewPendingResponse `json:"data"`
+}
+
+type TopUpRequestOKResponse struct {
+	StatusCode int                  `json:"status_code" example:"200"`
+	Message    string               `json:"message" example:"Success Request Top Up"`
+	Data       TopUpRequestResponse `json:"data"`
+}
+
+type TopUpRequestsOKResponse struct {
+	StatusCode int                        `json:"status_code" example:"200"`
+	Message    string                     `json:"message" example:"Success Get Top Up Request"`
+	Data       GetAllTopUpRequestResponse `json:"data"`
+}
+
+type PaymentOKResponse struct {
+	StatusCode int             `json:"status_code" example:"200"`
+	Message    string          `json:"message" example:"Success Create Payment"`
+	Data       PaymentResponse `json:"data"`
+}
+
+type PaymentsOKResponse struct {
+	StatusCode int                   `json:"status_code" example:"200"`
+	Message    string                `json:"message" example:"Success Get Payment"`
+	Data       GetAllPaymentResponse `json:"data"`
+}
+
+type PaymentExpireOKResponse struct {
+	StatusCode int                   `json:"status_code" example:"200"`
+	Message    string                `json:"message" example:"Success Expire Payment"`
+	Data       PaymentExpireResponse `json:"data"`
+}
+
+type TransferOKResponse struct {
+	StatusCode int              `json:"status_code" example:"201"`
+	Message    string           `json:"message" example:"Success Transfer Saldo"`
+	Data       TransferResponse `json:"data"`
+}
+
+type TransfersOKResponse struct {
+	StatusCode int                    `json:"status_code" example:"200"`
+	Message    string                 `json:"message" example:"Success Get Transfer"`
+	Data       GetAllTransferResponse `json:"data"`
+}
+
+type TransferLimitOKResponse struct {
+	StatusCode int                   `json:"status_code" example:"200"`
+	Message    string                `json:"message" example:"Success Get Transfer Limit"`
+	Data       TransferLimitResponse `json:"data"`
+}
+
+type PinStatusOKResponse struct {
+	StatusCode int               `json:"status_code" example:"200"`
+	Message    string            `json:"message" example:"Success Get PIN Status"`
+	Data       PinStatusResponse `json:"data"`
+}
+
+type ReceiptOKResponse struct {
+	StatusCode int             `json:"status_code" example:"200"`
+	Message    string          `json:"message" example:"Success Get Receipt"`
+	Data       ReceiptResponse `json:"data"`
+}
+
+type PickupOKResponse struct {
+	StatusCode int            `json:"status_code" example:"200"`
+	Message    string         `json:"message" example:"Success Mark Pickup"`
+	Data       PickupResponse `json:"data"`
+}
+
+type PickupsOKResponse struct {
+	StatusCode int                  `json:"status_code" example:"200"`
+	Message    string               `json:"message" example:"Success Get Pickup"`
+	Data       GetAllPickupResponse `json:"data"`
+}
+
+type PickupRefundOKResponse struct {
+	StatusCode int                  `json:"status_code" example:"200"`
+	Message    string               `json:"message" example:"Success Refund Pickup"`
+	Data       PickupRefundResponse `json:"data"`
+}
+
+type UserQROKResponse struct {
+	StatusCode int            `json:"status_code" example:"200"`
+	Message    string         `json:"message" example:"Success Generate QR"`
+	Data       UserQRResponse `json:"data"`
+}
+
+type UserQRResolveOKResponse struct {
+	StatusCode int                   `json:"status_code" example:"200"`
+	Message    string                `json:"message" example:"Success Resolve QR"`
+	Data       UserQRResolveResponse `json:"data"`
+}
+
+type PreOrderCreatedResponse struct {
+	StatusCode int              `json:"status_code" example:"201"`
+	Message    string           `json:"message" example:"Pre-Order Berhasil"`
+	Data       PreOrderResponse `json:"data"`
+}
+
+type PreOrderOKResponse struct {
+	StatusCode int              `json:"status_code" example:"200"`
+	Message    string           `json:"message" example:"Success Cancel Pre-Order"`
+	Data       PreOrderResponse `json:"data"`
+}
+
+type PreOrdersOKResponse struct {
+	StatusCode int                    `json:"status_code" example:"200"`
+	Message    string                 `json:"message" example:"Success Get Pre-Order"`
+	Data       GetAllPreOrderResponse `json:"data"`
+}
+
+type PreOrderDemandOKResponse struct {
+	StatusCode int                      `json:"status_code" example:"200"`
+	Message    string                   `json:"message" example:"Success Get Pre-Order Demand"`
+	Data       []PreOrderDemandResponse `json:"data"`
+}
+
+type WithdrawalCreatedResponse struct {
+	StatusCode int                `json:"status_code" example:"201"`
+	Message    string             `json:"message" example:"Pengajuan Penarikan Saldo Berhasil"`
+	Data       WithdrawalResponse `json:"data"`
+}
+
+type WithdrawalOKResponse struct {
+	StatusCode int                `json:"status_code" example:"200"`
+	Message    string             `json:"message" example:"Success Approve Withdrawal"`
+	Data       WithdrawalResponse `json:"data"`
+}
+
+type WithdrawalsOKResponse struct {
+	StatusCode int                      `json:"status_code" example:"200"`
+	Message    string                   `json:"message" example:"Success Get Withdrawal"`
+	Data       GetAllWithdrawalResponse `json:"data"`
+}
+
+type WithdrawalLedgerOKResponse struct {
+	StatusCode int                        `json:"status_code" example:"200"`
+	Message    string                     `json:"message" example:"Success Get Withdrawal Ledger"`
+	Data       []WithdrawalLedgerResponse `json:"data"`
+}
+
 type StatusOKDeletedResponse struct {
 	StatusCode int         `json:"status_code" example:"200"`
 	Message    string      `json:"message" example:"Successfully deleted"`
@@ -1,28 +1,43 @@
 package helpers
 
 import (
-	"context"
-	"time"
-	"warunk-bem/config"
+	"path"
+	"strings"
+)
 
-	"github.com/cloudinary/cloudinary-go/v2"
-	"github.com/cloudinary/cloudinary-go/v2/api/uploader"
+const (
+	ImageVariantThumbnail = "c_fill,w_150,h_150,q_auto,f_auto"
+	ImageVariantMedium    = "c_limit,w_600,h_600,q_auto,f_auto"
 )
 
-func ImageUploadHelper(input interface{}) (string, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-	defer cancel()
+// ImagePublicID mengambil public id Cloudinary dari secure url,
+// contoh: .../image/upload/v1690000000/folder/nama.jpg -> folder/nama
+func ImagePublicID(url string) string {
+	parts := strings.SplitN(url, "/upload/", 2)
+	if len(parts) != 2 {
+		return ""
+	}
 
-	//create cloudinary instance
-	cld, err := cloudinary.NewFromParams(config.EnvCloudName(), config.EnvCloudAPIKey(), config.EnvCloudAPISecret())
-	if err != nil {
-		return "", err
+	segments := strings.Split(parts[1], "/")
+	if len(segments) > 1 && strings.HasPrefix(segments[0], "v") {
+		segments = segments[1:]
 	}
 
-	//upload file
-	uploadParam, err := cld.Upload.Upload(ctx, input, uploader.UploadParams{Folder: config.EnvCloudUploadFolder()})
-	if err != nil {
-		return "", err
+	publicID := strings.Join(segments, "/")
+	return strings.TrimSuffix(publicID, path.Ext(publicID))
+}
+
+// ImageVariantURL menyisipkan transformasi Cloudinary ke url gambar,
+// storage lain tidak membuat varian sehingga hasilnya kosong dan field varian tidak dikirim
+func ImageVariantURL(url string, transformation string) string {
+	if !strings.Contains(url, "res.cloudinary.com") {
+		return ""
 	}
-	return uploadParam.SecureURL, nil
+
+	parts := strings.SplitN(url, "/upload/", 2)
+	if len(parts) != 2 {
+		return ""
+	}
+
+	return parts[0] + "/upload/" + transformation + "/" + parts[1]
 }
@@ -0,0 +1,48 @@
+package helpers
+
+import "testing"
+
+func TestImagePublicID(t *testing.T) {
+	tests := []struct {
+		name string
+		url  string
+		want string
+	}{
+		{name: "versioned", url: "https://res.cloudinary.com/demo/image/upload/v1690000000/warunk/kopi.jpg", want: "warunk/kopi"},
+		{name: "without version", url: "https://res.cloudinary.com/demo/image/upload/warunk/kopi.png", want: "warunk/kopi"},
+		{name: "not cloudinary", url: "http://localhost:8080/media/kopi.jpg", want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ImagePublicID(tt.url); got != tt.want {
+				t.Fatalf("ImagePublicID(%q) = %q, want %q", tt.url, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestImageVariantURL(t *testing.T) {
+	tests := []struct {
+		name string
+		url  string
+		want string
+	}{
+		{
+			name: "cloudinary",
+			url:  "https://res.cloudinary.com/demo/image/upload/v1690000000/warunk/kopi.jpg",
+			want: "https://res.cloudinary.com/demo/image/upload/" + ImageVariantThumbnail + "/v1690000000/warunk/kopi.jpg",
+		},
+		{name: "local storage", url: "http://localhost:8080/media/kopi.jpg", want: ""},
+		{name: "s3 storage", url: "https://warunk.s3.ap-southeast-1.amazonaws.com/produk/kopi.jpg", want: ""},
+		{name: "cloudinary without upload path", url: "https://res.cloudinary.com/demo/kopi.jpg", want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ImageVariantURL(tt.url, ImageVariantThumbnail); got != tt.want {
+				t.Fatalf("ImageVariantURL(%q) = %q, want %q", tt.url, got, tt.want)
+			}
+		})
+	}
+}
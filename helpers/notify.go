@@ -0,0 +1,22 @@
+package helpers
+
+import (
+	"context"
+	"log"
+	"warunk-bem/domain"
+)
+
+// NotifyAsync mengirim notifikasi di background agar request tidak menunggu email terkirim,
+// kegagalan hanya dicatat di log dengan nama fitur pengirim
+func NotifyAsync(usecase domain.NotifikasiUsecase, feature string, notifikasi *domain.Notifikasi) {
+	if usecase == nil {
+		return
+	}
+
+	go func() {
+		err := usecase.NotifyUser(context.Background(), notifikasi)
+		if err != nil {
+			log.Println("cannot send "+feature+" notification: ", err.Error())
+		}
+	}()
+}
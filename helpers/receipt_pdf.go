@@ -0,0 +1,105 @@
+package helpers
+
+import (
+	"bytes"
+	"fmt"
+	"warunk-bem/dtos"
+
+	"github.com/go-pdf/fpdf"
+)
+
+// ReceiptPDF menulis struk transaksi ke PDF ukuran A6 dengan font bawaan fpdf
+func ReceiptPDF(receipt *dtos.ReceiptResponse) ([]byte, error) {
+	pdf := fpdf.New("P", "mm", "A6", "")
+	pdf.SetMargins(8, 8, 8)
+	pdf.SetAutoPageBreak(true, 8)
+	pdf.SetTitle("Struk "+receipt.ReceiptNumber, true)
+	pdf.SetCreator("Warunk-BEM", true)
+	pdf.AddPage()
+
+	tr := pdf.UnicodeTranslatorFromDescriptor("")
+	width, _ := pdf.GetPageSize()
+	left, _, right, _ := pdf.GetMargins()
+	contentWidth := width - left - right
+
+	pdf.SetFont("Helvetica", "B", 13)
+	pdf.CellFormat(contentWidth, 7, "Warunk-BEM", "", 1, "C", false, 0, "")
+	pdf.SetFont("Helvetica", "", 8)
+	pdf.CellFormat(contentWidth, 4, "Struk Pembelian", "", 1, "C", false, 0, "")
+	pdf.Ln(2)
+
+	row := func(label string, value string) {
+		pdf.CellFormat(contentWidth*0.4, 4.5, label, "", 0, "L", false, 0, "")
+		pdf.CellFormat(contentWidth*0.6, 4.5, tr(value), "", 1, "R", false, 0, "")
+	}
+
+	row("No. Struk", receipt.ReceiptNumber)
+	row("Tanggal", receipt.CreatedAt.Format("02-01-2006 15:04:05"))
+	row("Pembeli", receipt.Name)
+	row("Status", receipt.Status)
+	pdf.Ln(1)
+
+	nameWidth := contentWidth * 0.46
+	qtyWidth := contentWidth * 0.12
+	priceWidth := contentWidth * 0.21
+
+	pdf.SetFont("Helvetica", "B", 8)
+	pdf.CellFormat(nameWidth, 5, "Produk", "TB", 0, "L", false, 0, "")
+	pdf.CellFormat(qtyWidth, 5, "Qty", "TB", 0, "C", false, 0, "")
+	pdf.CellFormat(priceWidth, 5, "Harga", "TB", 0, "R", false, 0, "")
+	pdf.CellFormat(priceWidth, 5, "Subtotal", "TB", 1, "R", false, 0, "")
+
+	pdf.SetFont("Helvetica", "", 8)
+	for _, item := range receipt.Items {
+		pdf.CellFormat(nameWidth, 5, fitText(pdf, tr(item.ProdukName), nameWidth-1), "", 0, "L", false, 0, "")
+		pdf.CellFormat(qtyWidth, 5, fmt.Sprint(item.Qty), "", 0, "C", false, 0, "")
+		pdf.CellFormat(priceWidth, 5, FormatRupiah(float64(item.Price)), "", 0, "R", false, 0, "")
+		pdf.CellFormat(priceWidth, 5, FormatRupiah(float64(item.Subtotal)), "", 1, "R", false, 0, "")
+	}
+	pdf.CellFormat(contentWidth, 1, "", "T", 1, "", false, 0, "")
+
+	row("Subtotal", "Rp"+FormatRupiah(float64(receipt.Subtotal)))
+	if receipt.Discount > 0 {
+		label := "Diskon"
+		if receipt.PromoCode != "" {
+			label += " (" + receipt.PromoCode + ")"
+		}
+		row(label, "-Rp"+FormatRupiah(float64(receipt.Discount)))
+	}
+
+	pdf.SetFont("Helvetica", "B", 9)
+	row("Total Bayar", "Rp"+FormatRupiah(float64(receipt.TotalBayar)))
+	pdf.SetFont("Helvetica", "", 8)
+	pdf.Ln(1)
+
+	if receipt.SaldoBefore != nil && receipt.SaldoAfter != nil {
+		row("Saldo Awal", "Rp"+FormatRupiah(*receipt.SaldoBefore))
+		row("Saldo Akhir", "Rp"+FormatRupiah(*receipt.SaldoAfter))
+		pdf.Ln(1)
+	}
+
+	pdf.SetFont("Helvetica", "I", 7)
+	pdf.CellFormat(contentWidth, 4, "Terima kasih sudah berbelanja di Warunk-BEM", "", 1, "C", false, 0, "")
+
+	var buf bytes.Buffer
+	err := pdf.Output(&buf)
+	if err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// fitText memotong teks dengan "..." agar tidak melebihi lebar kolom
+func fitText(pdf *fpdf.Fpdf, text string, width float64) string {
+	if pdf.GetStringWidth(text) <= width {
+		return text
+	}
+
+	runes := []rune(text)
+	for len(runes) > 0 && pdf.GetStringWidth(string(runes)+"...") > width {
+		runes = runes[:len(runes)-1]
+	}
+
+	return string(runes) + "..."
+}
@@ -0,0 +1,71 @@
+package helpers
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+	"warunk-bem/dtos"
+
+	"github.com/go-pdf/fpdf"
+)
+
+func TestReceiptPDF(t *testing.T) {
+	before, after := 50000.0, 27000.0
+
+	tests := []struct {
+		name    string
+		receipt *dtos.ReceiptResponse
+	}{
+		{
+			name: "full receipt",
+			receipt: &dtos.ReceiptResponse{
+				ReceiptNumber: "WB-20240301-ABC123",
+				CreatedAt:     time.Date(2024, 3, 1, 9, 30, 0, 0, time.UTC),
+				Name:          "Budi",
+				Status:        "Berhasil",
+				Items: []dtos.ReceiptItemResponse{
+					{ProdukName: "Kopi Susu Gula Aren Ukuran Besar Dengan Topping Cincau", Qty: 2, Price: 12000, Subtotal: 24000},
+					{ProdukName: "Roti Bakar Cokelat Keju ½ Porsi", Qty: 1, Price: 5000, Subtotal: 5000},
+				},
+				Subtotal:    29000,
+				Discount:    2000,
+				TotalBayar:  27000,
+				PromoCode:   "HEMAT",
+				SaldoBefore: &before,
+				SaldoAfter:  &after,
+			},
+		},
+		{
+			name:    "legacy receipt without saldo and items",
+			receipt: &dtos.ReceiptResponse{ReceiptNumber: "WB-20230101-000001", Status: "Berhasil"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pdf, err := ReceiptPDF(tt.receipt)
+			if err != nil {
+				t.Fatalf("err = %v", err)
+			}
+			if !bytes.HasPrefix(pdf, []byte("%PDF")) {
+				t.Fatal("output does not start with %PDF")
+			}
+		})
+	}
+}
+
+func TestFitText(t *testing.T) {
+	pdf := fpdf.New("P", "mm", "A6", "")
+	pdf.SetFont("Helvetica", "", 8)
+
+	if got := fitText(pdf, "Kopi", 30); got != "Kopi" {
+		t.Errorf("short text = %q, want unchanged", got)
+	}
+
+	long := strings.Repeat("Keripik Singkong ", 5)
+	got := fitText(pdf, long, 30)
+	if !strings.HasSuffix(got, "...") || pdf.GetStringWidth(got) > 30 {
+		t.Errorf("long text = %q (width %.1f), want truncated to 30mm", got, pdf.GetStringWidth(got))
+	}
+}
@@ -0,0 +1,32 @@
+package helpers
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RequestContext meneruskan context request ke usecase agar request yang dibatalkan klien ikut berhenti
+func RequestContext(c *gin.Context) context.Context {
+	ctx := c.Request.Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return ctx
+}
+
+// Pagination membaca query rp (jumlah per halaman, default 25) dan p (halaman, default 1)
+func Pagination(c *gin.Context) (int64, int64) {
+	rp, err := strconv.ParseInt(c.Query("rp"), 10, 64)
+	if err != nil || rp < 1 {
+		rp = 25
+	}
+
+	page, err := strconv.ParseInt(c.Query("p"), 10, 64)
+	if err != nil || page < 1 {
+		page = 1
+	}
+
+	return rp, page
+}
@@ -0,0 +1,28 @@
+package helpers
+
+import (
+	"math"
+	"strconv"
+)
+
+// FormatRupiah menulis nominal dengan pemisah ribuan titik, misalnya 50000 menjadi 50.000
+func FormatRupiah(amount float64) string {
+	n := int64(math.Round(amount))
+
+	sign := ""
+	if n < 0 {
+		sign = "-"
+		n = -n
+	}
+
+	digits := strconv.FormatInt(n, 10)
+	res := make([]byte, 0, len(digits)+len(digits)/3)
+	for i := range digits {
+		if i > 0 && (len(digits)-i)%3 == 0 {
+			res = append(res, '.')
+		}
+		res = append(res, digits[i])
+	}
+
+	return sign + string(res)
+}
@@ -27,10 +27,14 @@ func NewKeranjangHandler(protected *gin.RouterGroup, protectedAdmin *gin.RouterG
 
 	protected.POST("", handler.InsertOne)
 	protected.GET("", handler.FindOne)
+	protected.GET("/preview", handler.Preview)
 	protected.POST("/deleteproduct", handler.RemoveProduct)
+	protected.PUT("/:id", handler.SetQuantity)
+	protected.DELETE("/:id", handler.RemoveLine)
+	protected.DELETE("", handler.Clear)
 }
 
-func isRequestValid(m *domain.InsertKeranjangRequest) (bool, error) {
+func isRequestValid(m interface{}) (bool, error) {
 	validate := validator.New()
 	err := validate.Struct(m)
 	if err != nil {
@@ -159,7 +163,20 @@ func (tc *KeranjangHandler) InsertOne(c *gin.Context) {
 			Total:  check.Total + keranjang.Total,
 		}
 
-		res, err := tc.KeranjangUsecase.UpdateOne(c, idUser, keranjangBaruBanget)
+		_, err = tc.KeranjangUsecase.UpdateOne(c, idUser, keranjangBaruBanget)
+		if err != nil {
+			c.JSON(
+				http.StatusBadRequest,
+				dtos.NewErrorResponse(
+					http.StatusBadRequest,
+					"Invalid request",
+					dtos.GetErrorData(err),
+				),
+			)
+			return
+		}
+
+		res, err := tc.KeranjangUsecase.FindOne(c, idUser)
 		if err != nil {
 			c.JSON(
 				http.StatusBadRequest,
@@ -271,3 +288,176 @@ func (tc *KeranjangHandler) RemoveProduct(c *gin.Context) {
 		),
 	)
 }
+
+func (tc *KeranjangHandler) SetQuantity(c *gin.Context) {
+	idUser, err := middlewares.IsUser(c)
+	if err != nil {
+		c.JSON(
+			http.StatusUnauthorized,
+			dtos.NewErrorResponse(
+				http.StatusUnauthorized,
+				"Unauthorized",
+				dtos.GetErrorData(err),
+			),
+		)
+		return
+	}
+
+	var req dtos.SetQuantityKeranjangRequest
+	err = c.ShouldBindJSON(&req)
+	if err != nil {
+		c.JSON(
+			http.StatusUnprocessableEntity,
+			dtos.NewErrorResponse(
+				http.StatusUnprocessableEntity,
+				"Invalid request",
+				dtos.GetErrorData(err),
+			),
+		)
+		return
+	}
+
+	if ok, err := isRequestValid(&req); !ok {
+		c.JSON(
+			http.StatusBadRequest,
+			dtos.NewErrorResponse(
+				http.StatusBadRequest,
+				"Invalid request",
+				dtos.GetErrorData(err),
+			),
+		)
+		return
+	}
+
+	res, err := tc.KeranjangUsecase.SetQuantity(c, idUser, c.Param("id"), req.Quantity)
+	if err != nil {
+		c.JSON(
+			http.StatusBadRequest,
+			dtos.NewErrorResponse(
+				http.StatusBadRequest,
+				"Invalid request",
+				dtos.GetErrorData(err),
+			),
+		)
+		return
+	}
+
+	c.JSON(
+		http.StatusOK,
+		dtos.NewResponse(
+			http.StatusOK,
+			"Success",
+			res,
+		),
+	)
+}
+
+func (tc *KeranjangHandler) RemoveLine(c *gin.Context) {
+	idUser, err := middlewares.IsUser(c)
+	if err != nil {
+		c.JSON(
+			http.StatusUnauthorized,
+			dtos.NewErrorResponse(
+				http.StatusUnauthorized,
+				"Unauthorized",
+				dtos.GetErrorData(err),
+			),
+		)
+		return
+	}
+
+	res, err := tc.KeranjangUsecase.RemoveLine(c, idUser, c.Param("id"))
+	if err != nil {
+		c.JSON(
+			http.StatusBadRequest,
+			dtos.NewErrorResponse(
+				http.StatusBadRequest,
+				"Invalid request",
+				dtos.GetErrorData(err),
+			),
+		)
+		return
+	}
+
+	c.JSON(
+		http.StatusOK,
+		dtos.NewResponse(
+			http.StatusOK,
+			"Produk berhasil dihapus",
+			res,
+		),
+	)
+}
+
+func (tc *KeranjangHandler) Clear(c *gin.Context) {
+	idUser, err := middlewares.IsUser(c)
+	if err != nil {
+		c.JSON(
+			http.StatusUnauthorized,
+			dtos.NewErrorResponse(
+				http.StatusUnauthorized,
+				"Unauthorized",
+				dtos.GetErrorData(err),
+			),
+		)
+		return
+	}
+
+	err = tc.KeranjangUsecase.Clear(c, idUser)
+	if err != nil {
+		c.JSON(
+			http.StatusBadRequest,
+			dtos.NewErrorResponse(
+				http.StatusBadRequest,
+				"Invalid request",
+				dtos.GetErrorData(err),
+			),
+		)
+		return
+	}
+
+	c.JSON(
+		http.StatusOK,
+		dtos.NewResponseMessage(
+			http.StatusOK,
+			"Keranjang berhasil dikosongkan",
+		),
+	)
+}
+
+func (tc *KeranjangHandler) Preview(c *gin.Context) {
+	idUser, err := middlewares.IsUser(c)
+	if err != nil {
+		c.JSON(
+			http.StatusUnauthorized,
+			dtos.NewErrorResponse(
+				http.StatusUnauthorized,
+				"Unauthorized",
+				dtos.GetErrorData(err),
+			),
+		)
+		return
+	}
+
+	res, err := tc.KeranjangUsecase.Preview(c, idUser, c.Query("promo_code"))
+	if err != nil {
+		c.JSON(
+			http.StatusBadRequest,
+			dtos.NewErrorResponse(
+				http.StatusBadRequest,
+				"Invalid request",
+				dtos.GetErrorData(err),
+			),
+		)
+		return
+	}
+
+	c.JSON(
+		http.StatusOK,
+		dtos.NewResponse(
+			http.StatusOK,
+			"Success",
+			res,
+		),
+	)
+}
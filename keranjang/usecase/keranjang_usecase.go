@@ -3,8 +3,10 @@ package usecase
 import (
 	"context"
 	"errors"
+	"fmt"
 	"time"
 	"warunk-bem/domain"
+	"warunk-bem/dtos"
 
 	"github.com/go-redis/redis/v8"
 	"go.mongodb.org/mongo-driver/bson/primitive"
@@ -14,20 +16,50 @@ type KeranjangUsecase struct {
 	KeranjangRepo  domain.KeranjangRepository
 	ProdukRepo     domain.ProdukRepository
 	UserRepo       domain.UserRepository
+	UserAmountRepo domain.UserAmountRepository
+	PromoUsecase   domain.PromoUsecase
 	RedisClient    *redis.Client
 	contextTimeout time.Duration
 }
 
-func NewKeranjangUsecase(KeranjangRepo domain.KeranjangRepository, ProdukRepo domain.ProdukRepository, UserRepo domain.UserRepository, RedisClient *redis.Client, contextTimeout time.Duration) domain.KeranjangUsecase {
+func NewKeranjangUsecase(KeranjangRepo domain.KeranjangRepository, ProdukRepo domain.ProdukRepository, UserRepo domain.UserRepository, UserAmountRepo domain.UserAmountRepository, PromoUsecase domain.PromoUsecase, RedisClient *redis.Client, contextTimeout time.Duration) domain.KeranjangUsecase {
 	return &KeranjangUsecase{
 		KeranjangRepo:  KeranjangRepo,
 		ProdukRepo:     ProdukRepo,
 		UserRepo:       UserRepo,
+		UserAmountRepo: UserAmountRepo,
+		PromoUsecase:   PromoUsecase,
 		RedisClient:    RedisClient,
 		contextTimeout: contextTimeout,
 	}
 }
 
+// recountKeranjang menghitung ulang jumlah item dari baris keranjang, stock pada baris keranjang adalah jumlah yang dibeli
+func recountKeranjang(keranjang *domain.Keranjang) {
+	total := 0
+	for _, v := range keranjang.Produk {
+		total += int(v.Stock)
+	}
+	keranjang.Total = total
+}
+
+func toKeranjangResponse(keranjang *domain.Keranjang) *domain.InsertKeranjangResponse {
+	recountKeranjang(keranjang)
+
+	var subtotal int64
+	for _, v := range keranjang.Produk {
+		subtotal += v.Price * v.Stock
+	}
+
+	return &domain.InsertKeranjangResponse{
+		ID:       keranjang.ID.Hex(),
+		UserID:   keranjang.UserID.Hex(),
+		Produk:   keranjang.Produk,
+		Total:    keranjang.Total,
+		Subtotal: subtotal,
+	}
+}
+
 // AddKeranjang godoc
 // @Summary      Add Keranjang
 // @Description  Add Keranjang
@@ -49,11 +81,19 @@ func (ku *KeranjangUsecase) InsertOne(ctx context.Context, req *domain.InsertKer
 	ctx, cancel := context.WithTimeout(ctx, ku.contextTimeout)
 	defer cancel()
 
+	if req.Total <= 0 {
+		return res, errors.New("jumlah produk minimal 1")
+	}
+
 	produk, err := ku.ProdukRepo.FindOne(ctx, req.ProdukID)
 	if err != nil {
 		return res, err
 	}
 
+	if produk.Stock < int64(req.Total) {
+		return res, fmt.Errorf("stok %s tidak mencukupi, tersisa %d", produk.Name, produk.Stock)
+	}
+
 	user, err := ku.UserRepo.FindOne(ctx, req.UserID)
 	if err != nil {
 		return res, err
@@ -63,7 +103,7 @@ func (ku *KeranjangUsecase) InsertOne(ctx context.Context, req *domain.InsertKer
 	req.CreatedAt = time.Now()
 	req.UpdatedAt = time.Now()
 
-	_, err = ku.KeranjangRepo.InsertOne(ctx, &domain.Keranjang{
+	keranjang, err := ku.KeranjangRepo.InsertOne(ctx, &domain.Keranjang{
 		ID:        req.ID,
 		CreatedAt: req.CreatedAt,
 		UpdatedAt: req.UpdatedAt,
@@ -85,7 +125,7 @@ func (ku *KeranjangUsecase) InsertOne(ctx context.Context, req *domain.InsertKer
 		return res, err
 	}
 
-	return res, nil
+	return toKeranjangResponse(keranjang), nil
 }
 
 // GetKeranjang godoc
@@ -111,13 +151,7 @@ func (ku *KeranjangUsecase) FindOne(ctx context.Context, id string) (*domain.Ins
 		return nil, err
 	}
 
-	res := &domain.InsertKeranjangResponse{
-		ID:     keranjang.ID.Hex(),
-		UserID: keranjang.UserID.Hex(),
-		Produk: keranjang.Produk,
-	}
-
-	return res, nil
+	return toKeranjangResponse(keranjang), nil
 }
 
 // RemoveProduct godoc
@@ -204,11 +238,24 @@ func (ku *KeranjangUsecase) UpdateOne(ctx context.Context, id string, req *domai
 		return nil, err
 	}
 
+	if len(req.Produk) == 0 || req.Produk[0].Stock <= 0 {
+		return nil, errors.New("jumlah produk minimal 1")
+	}
+
+	produk, err := ku.ProdukRepo.FindOne(ctx, req.Produk[0].ID.Hex())
+	if err != nil {
+		return nil, errors.New("produk tidak ditemukan")
+	}
+
 	// Jika produk sudah ada di keranjang, tambahkan stoknya saja jangan tambahkan array produknya
 	for i, v := range result.Produk {
 		if v.ID == req.Produk[0].ID {
+			if produk.Stock < v.Stock+req.Produk[0].Stock {
+				return nil, fmt.Errorf("stok %s tidak mencukupi, tersisa %d", produk.Name, produk.Stock)
+			}
+
 			result.Produk[i].Stock += req.Produk[0].Stock
-			result.Total += int(req.Produk[0].Stock)
+			recountKeranjang(result)
 			_, err = ku.KeranjangRepo.UpdateOne(ctx, result, id)
 			if err != nil {
 				return nil, err
@@ -217,8 +264,12 @@ func (ku *KeranjangUsecase) UpdateOne(ctx context.Context, id string, req *domai
 		}
 	}
 
+	if produk.Stock < req.Produk[0].Stock {
+		return nil, fmt.Errorf("stok %s tidak mencukupi, tersisa %d", produk.Name, produk.Stock)
+	}
+
 	result.Produk = append(result.Produk, req.Produk...)
-	result.Total += req.Total
+	recountKeranjang(result)
 
 	_, err = ku.KeranjangRepo.UpdateOne(ctx, result, id)
 	if err != nil {
@@ -227,3 +278,260 @@ func (ku *KeranjangUsecase) UpdateOne(ctx context.Context, id string, req *domai
 
 	return result, nil
 }
+
+// SetQuantityKeranjang godoc
+// @Summary      Set Quantity Produk in Keranjang
+// @Description  Set quantity of one line in Keranjang, quantity 0 removes the line
+// @Tags         User - Keranjang
+// @Accept       json
+// @Produce      json
+// @Param        id path string true "id produk"
+// @Param        request body dtos.SetQuantityKeranjangRequest true "Payload Body [RAW]"
+// @Success      200 {object} dtos.KeranjangOKResponse
+// @Failure      400 {object} dtos.BadRequestResponse
+// @Failure      401 {object} dtos.UnauthorizedResponse
+// @Failure      403 {object} dtos.ForbiddenResponse
+// @Failure      404 {object} dtos.NotFoundResponse
+// @Failure      500 {object} dtos.InternalServerErrorResponse
+// @Router       /keranjang/{id} [put]
+// @Security BearerAuth
+func (ku *KeranjangUsecase) SetQuantity(ctx context.Context, userID string, produkID string, quantity int) (*domain.InsertKeranjangResponse, error) {
+	if quantity < 0 {
+		return nil, errors.New("quantity tidak boleh negatif")
+	}
+
+	if quantity == 0 {
+		return ku.RemoveLine(ctx, userID, produkID)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, ku.contextTimeout)
+	defer cancel()
+
+	keranjang, err := ku.KeranjangRepo.FindOne(ctx, userID)
+	if err != nil {
+		return nil, errors.New("keranjang tidak ditemukan")
+	}
+
+	index := -1
+	for i, v := range keranjang.Produk {
+		if v.ID.Hex() == produkID {
+			index = i
+			break
+		}
+	}
+
+	if index == -1 {
+		return nil, errors.New("produk tidak ada di keranjang")
+	}
+
+	produk, err := ku.ProdukRepo.FindOne(ctx, produkID)
+	if err != nil {
+		return nil, errors.New("produk tidak ditemukan")
+	}
+
+	if produk.Stock < int64(quantity) {
+		return nil, fmt.Errorf("stok %s tidak mencukupi, tersisa %d", produk.Name, produk.Stock)
+	}
+
+	// Perubahan jumlah sekaligus memperbarui snapshot produk di baris ini
+	keranjang.Produk[index].Stock = int64(quantity)
+	keranjang.Produk[index].Price = produk.Price
+	keranjang.Produk[index].Name = produk.Name
+	keranjang.Produk[index].Slug = produk.Slug
+	keranjang.Produk[index].Image = produk.Image
+	keranjang.UpdatedAt = time.Now()
+	recountKeranjang(keranjang)
+
+	keranjang, err = ku.KeranjangRepo.UpdateOne(ctx, keranjang, userID)
+	if err != nil {
+		return nil, errors.New("tidak dapat mengupdate keranjang")
+	}
+
+	return toKeranjangResponse(keranjang), nil
+}
+
+// RemoveLineKeranjang godoc
+// @Summary      Remove Produk Line from Keranjang
+// @Description  Remove one produk line from Keranjang, the Keranjang is deleted when it becomes empty
+// @Tags         User - Keranjang
+// @Accept       json
+// @Produce      json
+// @Param        id path string true "id produk"
+// @Success      200 {object} dtos.KeranjangOKResponse
+// @Failure      400 {object} dtos.BadRequestResponse
+// @Failure      401 {object} dtos.UnauthorizedResponse
+// @Failure      403 {object} dtos.ForbiddenResponse
+// @Failure      404 {object} dtos.NotFoundResponse
+// @Failure      500 {object} dtos.InternalServerErrorResponse
+// @Router       /keranjang/{id} [delete]
+// @Security BearerAuth
+func (ku *KeranjangUsecase) RemoveLine(ctx context.Context, userID string, produkID string) (*domain.InsertKeranjangResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, ku.contextTimeout)
+	defer cancel()
+
+	keranjang, err := ku.KeranjangRepo.FindOne(ctx, userID)
+	if err != nil {
+		return nil, errors.New("keranjang tidak ditemukan")
+	}
+
+	produks := make([]domain.Produk, 0, len(keranjang.Produk))
+	for _, v := range keranjang.Produk {
+		if v.ID.Hex() != produkID {
+			produks = append(produks, v)
+		}
+	}
+
+	if len(produks) == len(keranjang.Produk) {
+		return nil, errors.New("produk tidak ada di keranjang")
+	}
+
+	if len(produks) == 0 {
+		err = ku.KeranjangRepo.DeleteOne(ctx, keranjang.ID.Hex())
+		if err != nil {
+			return nil, errors.New("tidak dapat menghapus keranjang")
+		}
+
+		keranjang.Produk = produks
+		return toKeranjangResponse(keranjang), nil
+	}
+
+	keranjang.Produk = produks
+	keranjang.UpdatedAt = time.Now()
+	recountKeranjang(keranjang)
+
+	keranjang, err = ku.KeranjangRepo.UpdateOne(ctx, keranjang, userID)
+	if err != nil {
+		return nil, errors.New("tidak dapat mengupdate keranjang")
+	}
+
+	return toKeranjangResponse(keranjang), nil
+}
+
+// ClearKeranjang godoc
+// @Summary      Clear Keranjang
+// @Description  Remove every produk from Keranjang
+// @Tags         User - Keranjang
+// @Accept       json
+// @Produce      json
+// @Success      200 {object} dtos.StatusOKResponse
+// @Failure      400 {object} dtos.BadRequestResponse
+// @Failure      401 {object} dtos.UnauthorizedResponse
+// @Failure      403 {object} dtos.ForbiddenResponse
+// @Failure      404 {object} dtos.NotFoundResponse
+// @Failure      500 {object} dtos.InternalServerErrorResponse
+// @Router       /keranjang [delete]
+// @Security BearerAuth
+func (ku *KeranjangUsecase) Clear(ctx context.Context, userID string) error {
+	ctx, cancel := context.WithTimeout(ctx, ku.contextTimeout)
+	defer cancel()
+
+	keranjang, err := ku.KeranjangRepo.FindOne(ctx, userID)
+	if err != nil {
+		return errors.New("keranjang tidak ditemukan")
+	}
+
+	return ku.KeranjangRepo.DeleteOne(ctx, keranjang.ID.Hex())
+}
+
+// PreviewKeranjang godoc
+// @Summary      Preview Keranjang
+// @Description  Re-read current produk for every line, flag price changes, out of stock and deleted produk, and show the exact amount checkout would charge against the current saldo
+// @Tags         User - Keranjang
+// @Accept       json
+// @Produce      json
+// @Param        promo_code query string false "Promo code"
+// @Success      200 {object} dtos.KeranjangPreviewOKResponse
+// @Failure      400 {object} dtos.BadRequestResponse
+// @Failure      401 {object} dtos.UnauthorizedResponse
+// @Failure      403 {object} dtos.ForbiddenResponse
+// @Failure      404 {object} dtos.NotFoundResponse
+// @Failure      500 {object} dtos.InternalServerErrorResponse
+// @Router       /keranjang/preview [get]
+// @Security BearerAuth
+func (ku *KeranjangUsecase) Preview(ctx context.Context, userID string, promoCode string) (*dtos.KeranjangPreviewResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, ku.contextTimeout)
+	defer cancel()
+
+	keranjang, err := ku.KeranjangRepo.FindOne(ctx, userID)
+	if err != nil {
+		return nil, errors.New("keranjang tidak ditemukan")
+	}
+
+	saldo, err := ku.UserAmountRepo.FindOne(ctx, userID)
+	if err != nil {
+		return nil, errors.New("cannot get user amount")
+	}
+
+	res := &dtos.KeranjangPreviewResponse{
+		ID:          keranjang.ID.Hex(),
+		Items:       make([]dtos.KeranjangPreviewItem, 0, len(keranjang.Produk)),
+		Saldo:       saldo.Amount,
+		CanCheckout: len(keranjang.Produk) > 0,
+	}
+
+	// Preview hanya membaca, snapshot harga di keranjang tidak diubah agar
+	// tanda perubahan harga tetap muncul sampai user mengubah keranjang atau checkout
+	items := make([]domain.PromoItem, 0, len(keranjang.Produk))
+	for _, line := range keranjang.Produk {
+		item := dtos.KeranjangPreviewItem{
+			ProdukID:  line.ID.Hex(),
+			Name:      line.Name,
+			Image:     line.Image,
+			Quantity:  line.Stock,
+			CartPrice: line.Price,
+		}
+
+		produk, err := ku.ProdukRepo.FindOne(ctx, line.ID.Hex())
+		if err != nil || produk.DeletedAt != nil {
+			item.Deleted = true
+			res.CanCheckout = false
+			res.Items = append(res.Items, item)
+			continue
+		}
+
+		item.Name = produk.Name
+		item.Image = produk.Image
+		item.CurrentPrice = produk.Price
+		item.Stock = produk.Stock
+		item.LineTotal = produk.Price * line.Stock
+		item.PriceChanged = produk.Price != line.Price
+		item.OutOfStock = produk.Stock == 0
+		item.InsufficientStock = !item.OutOfStock && produk.Stock < line.Stock
+		item.Available = !item.OutOfStock && !item.InsufficientStock
+
+		if !item.Available {
+			res.CanCheckout = false
+		}
+
+		res.Items = append(res.Items, item)
+		res.TotalItem += line.Stock
+		res.Subtotal += item.LineTotal
+		items = append(items, domain.PromoItem{
+			ProdukID: produk.ID,
+			Category: produk.Category,
+			Price:    produk.Price,
+			Qty:      line.Stock,
+		})
+	}
+
+	res.TotalBayar = res.Subtotal
+	if promoCode != "" && len(items) > 0 {
+		promo, err := ku.PromoUsecase.Apply(ctx, promoCode, userID, items)
+		if err != nil {
+			res.PromoError = err.Error()
+			res.CanCheckout = false
+		} else {
+			res.PromoCode = promo.Code
+			res.Discount = promo.Discount
+			res.TotalBayar -= promo.Discount
+		}
+	}
+
+	res.SaldoAfter = saldo.Amount - float64(res.TotalBayar)
+	res.SaldoCukup = res.SaldoAfter >= 0
+	if !res.SaldoCukup {
+		res.CanCheckout = false
+	}
+
+	return res, nil
+}
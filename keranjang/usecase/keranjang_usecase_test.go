@@ -0,0 +1,294 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+	"warunk-bem/domain"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type mockKeranjangRepo struct {
+	domain.KeranjangRepository
+
+	keranjang *domain.Keranjang
+	updates   int
+	deleted   bool
+}
+
+func (m *mockKeranjangRepo) FindOne(ctx context.Context, id string) (*domain.Keranjang, error) {
+	if m.keranjang == nil {
+		return nil, errors.New("not found")
+	}
+	res := *m.keranjang
+	res.Produk = append([]domain.Produk(nil), m.keranjang.Produk...)
+	return &res, nil
+}
+
+func (m *mockKeranjangRepo) UpdateOne(ctx context.Context, keranjang *domain.Keranjang, id string) (*domain.Keranjang, error) {
+	m.updates++
+	m.keranjang = keranjang
+	return keranjang, nil
+}
+
+func (m *mockKeranjangRepo) DeleteOne(ctx context.Context, id string) error {
+	if m.keranjang == nil || m.keranjang.ID.Hex() != id {
+		return errors.New("not found")
+	}
+	m.deleted = true
+	m.keranjang = nil
+	return nil
+}
+
+type mockProdukRepo struct {
+	domain.ProdukRepository
+
+	produks map[string]*domain.Produk
+}
+
+func (m *mockProdukRepo) FindOne(ctx context.Context, id string) (*domain.Produk, error) {
+	p, ok := m.produks[id]
+	if !ok {
+		return nil, errors.New("not found")
+	}
+	return p, nil
+}
+
+type mockUserAmountRepo struct {
+	domain.UserAmountRepository
+
+	amount float64
+}
+
+func (m *mockUserAmountRepo) FindOne(ctx context.Context, userID string) (*domain.UserAmount, error) {
+	return &domain.UserAmount{Amount: m.amount}, nil
+}
+
+func newTestKeranjang(userID primitive.ObjectID, lines ...domain.Produk) *domain.Keranjang {
+	return &domain.Keranjang{ID: primitive.NewObjectID(), UserID: userID, Produk: lines}
+}
+
+func TestPreviewDoesNotPersistPrices(t *testing.T) {
+	userID := primitive.NewObjectID()
+	produk := &domain.Produk{ID: primitive.NewObjectID(), Name: "Kopi", Price: 6000, Stock: 10}
+	line := domain.Produk{ID: produk.ID, Name: "Kopi", Price: 5000, Stock: 2}
+
+	keranjangRepo := &mockKeranjangRepo{keranjang: newTestKeranjang(userID, line)}
+	produkRepo := &mockProdukRepo{produks: map[string]*domain.Produk{produk.ID.Hex(): produk}}
+	ku := NewKeranjangUsecase(keranjangRepo, produkRepo, nil, &mockUserAmountRepo{amount: 20000}, nil, nil, time.Second)
+
+	// Preview dipanggil dua kali, tanda perubahan harga harus tetap muncul
+	for i := 0; i < 2; i++ {
+		res, err := ku.Preview(context.Background(), userID.Hex(), "")
+		if err != nil {
+			t.Fatalf("preview %d: unexpected error: %v", i, err)
+		}
+		item := res.Items[0]
+		if !item.PriceChanged || item.CartPrice != 5000 || item.CurrentPrice != 6000 {
+			t.Fatalf("preview %d: item = %+v, want price change 5000 -> 6000", i, item)
+		}
+		if res.TotalBayar != 12000 {
+			t.Fatalf("preview %d: total bayar = %d, want 12000", i, res.TotalBayar)
+		}
+	}
+
+	if keranjangRepo.updates != 0 {
+		t.Fatalf("preview updated keranjang %d times, want 0", keranjangRepo.updates)
+	}
+	if keranjangRepo.keranjang.Produk[0].Price != 5000 {
+		t.Fatalf("stored cart price = %d, want 5000", keranjangRepo.keranjang.Produk[0].Price)
+	}
+}
+
+func TestPreview(t *testing.T) {
+	userID := primitive.NewObjectID()
+	kopi := &domain.Produk{ID: primitive.NewObjectID(), Name: "Kopi", Price: 5000, Stock: 1}
+	teh := &domain.Produk{ID: primitive.NewObjectID(), Name: "Teh", Price: 3000, Stock: 0}
+	dihapus := &domain.Produk{ID: primitive.NewObjectID(), Name: "Roti", Price: 4000, Stock: 5, DeletedAt: &time.Time{}}
+
+	tests := []struct {
+		name            string
+		lines           []domain.Produk
+		saldo           float64
+		wantCanCheckout bool
+		wantSaldoCukup  bool
+	}{
+		{
+			name:            "available",
+			lines:           []domain.Produk{{ID: kopi.ID, Price: 5000, Stock: 1}},
+			saldo:           5000,
+			wantCanCheckout: true,
+			wantSaldoCukup:  true,
+		},
+		{
+			name:            "insufficient stock",
+			lines:           []domain.Produk{{ID: kopi.ID, Price: 5000, Stock: 2}},
+			saldo:           50000,
+			wantCanCheckout: false,
+			wantSaldoCukup:  true,
+		},
+		{
+			name:            "out of stock",
+			lines:           []domain.Produk{{ID: teh.ID, Price: 3000, Stock: 1}},
+			saldo:           50000,
+			wantCanCheckout: false,
+			wantSaldoCukup:  true,
+		},
+		{
+			name:            "deleted produk",
+			lines:           []domain.Produk{{ID: dihapus.ID, Price: 4000, Stock: 1}},
+			saldo:           50000,
+			wantCanCheckout: false,
+			wantSaldoCukup:  true,
+		},
+		{
+			name:            "saldo kurang",
+			lines:           []domain.Produk{{ID: kopi.ID, Price: 5000, Stock: 1}},
+			saldo:           4999,
+			wantCanCheckout: false,
+			wantSaldoCukup:  false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			produkRepo := &mockProdukRepo{produks: map[string]*domain.Produk{
+				kopi.ID.Hex():    kopi,
+				teh.ID.Hex():     teh,
+				dihapus.ID.Hex(): dihapus,
+			}}
+			keranjangRepo := &mockKeranjangRepo{keranjang: newTestKeranjang(userID, tt.lines...)}
+			ku := NewKeranjangUsecase(keranjangRepo, produkRepo, nil, &mockUserAmountRepo{amount: tt.saldo}, nil, nil, time.Second)
+
+			res, err := ku.Preview(context.Background(), userID.Hex(), "")
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if res.CanCheckout != tt.wantCanCheckout {
+				t.Fatalf("can checkout = %v, want %v", res.CanCheckout, tt.wantCanCheckout)
+			}
+			if res.SaldoCukup != tt.wantSaldoCukup {
+				t.Fatalf("saldo cukup = %v, want %v", res.SaldoCukup, tt.wantSaldoCukup)
+			}
+		})
+	}
+}
+
+func TestSetQuantity(t *testing.T) {
+	userID := primitive.NewObjectID()
+	produk := &domain.Produk{ID: primitive.NewObjectID(), Name: "Kopi Susu", Price: 6000, Stock: 3}
+
+	tests := []struct {
+		name      string
+		quantity  int
+		wantErr   bool
+		wantQty   int64
+		wantPrice int64
+	}{
+		{name: "refreshes snapshot", quantity: 2, wantQty: 2, wantPrice: 6000},
+		{name: "over stock", quantity: 4, wantErr: true, wantQty: 1, wantPrice: 5000},
+		{name: "negative", quantity: -1, wantErr: true, wantQty: 1, wantPrice: 5000},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			line := domain.Produk{ID: produk.ID, Name: "Kopi", Price: 5000, Stock: 1}
+			keranjangRepo := &mockKeranjangRepo{keranjang: newTestKeranjang(userID, line)}
+			produkRepo := &mockProdukRepo{produks: map[string]*domain.Produk{produk.ID.Hex(): produk}}
+			ku := NewKeranjangUsecase(keranjangRepo, produkRepo, nil, nil, nil, nil, time.Second)
+
+			_, err := ku.SetQuantity(context.Background(), userID.Hex(), produk.ID.Hex(), tt.quantity)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			stored := keranjangRepo.keranjang.Produk[0]
+			if stored.Stock != tt.wantQty || stored.Price != tt.wantPrice {
+				t.Fatalf("stored line = qty %d price %d, want qty %d price %d", stored.Stock, stored.Price, tt.wantQty, tt.wantPrice)
+			}
+		})
+	}
+}
+
+func TestRemoveLine(t *testing.T) {
+	userID := primitive.NewObjectID()
+	kopi := domain.Produk{ID: primitive.NewObjectID(), Name: "Kopi", Price: 5000, Stock: 2}
+	roti := domain.Produk{ID: primitive.NewObjectID(), Name: "Roti", Price: 3000, Stock: 1}
+
+	tests := []struct {
+		name        string
+		lines       []domain.Produk
+		produkID    string
+		wantErr     bool
+		wantDeleted bool
+		wantLines   int
+		wantTotal   int
+	}{
+		{name: "removes one line and recounts", lines: []domain.Produk{kopi, roti}, produkID: kopi.ID.Hex(), wantLines: 1, wantTotal: 1},
+		{name: "last line deletes keranjang", lines: []domain.Produk{kopi}, produkID: kopi.ID.Hex(), wantDeleted: true},
+		{name: "produk not in keranjang", lines: []domain.Produk{kopi, roti}, produkID: primitive.NewObjectID().Hex(), wantErr: true, wantLines: 2, wantTotal: 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			keranjang := newTestKeranjang(userID, tt.lines...)
+			keranjang.Total = 3
+			keranjangRepo := &mockKeranjangRepo{keranjang: keranjang}
+			ku := NewKeranjangUsecase(keranjangRepo, &mockProdukRepo{}, nil, nil, nil, nil, time.Second)
+
+			_, err := ku.RemoveLine(context.Background(), userID.Hex(), tt.produkID)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if keranjangRepo.deleted != tt.wantDeleted {
+				t.Fatalf("deleted = %v, want %v", keranjangRepo.deleted, tt.wantDeleted)
+			}
+			if tt.wantDeleted {
+				return
+			}
+			if got := len(keranjangRepo.keranjang.Produk); got != tt.wantLines {
+				t.Errorf("lines = %d, want %d", got, tt.wantLines)
+			}
+			if keranjangRepo.keranjang.Total != tt.wantTotal {
+				t.Errorf("total = %d, want %d", keranjangRepo.keranjang.Total, tt.wantTotal)
+			}
+		})
+	}
+}
+
+func TestSetQuantityZeroRemovesLine(t *testing.T) {
+	userID := primitive.NewObjectID()
+	kopi := domain.Produk{ID: primitive.NewObjectID(), Name: "Kopi", Price: 5000, Stock: 2}
+	roti := domain.Produk{ID: primitive.NewObjectID(), Name: "Roti", Price: 3000, Stock: 1}
+	keranjangRepo := &mockKeranjangRepo{keranjang: newTestKeranjang(userID, kopi, roti)}
+	ku := NewKeranjangUsecase(keranjangRepo, &mockProdukRepo{}, nil, nil, nil, nil, time.Second)
+
+	_, err := ku.SetQuantity(context.Background(), userID.Hex(), kopi.ID.Hex(), 0)
+	if err != nil {
+		t.Fatalf("err = %v", err)
+	}
+
+	if lines := keranjangRepo.keranjang.Produk; len(lines) != 1 || lines[0].ID != roti.ID {
+		t.Fatalf("lines = %+v, want only roti", lines)
+	}
+}
+
+func TestClear(t *testing.T) {
+	userID := primitive.NewObjectID()
+
+	keranjangRepo := &mockKeranjangRepo{keranjang: newTestKeranjang(userID, domain.Produk{ID: primitive.NewObjectID(), Stock: 1})}
+	ku := NewKeranjangUsecase(keranjangRepo, &mockProdukRepo{}, nil, nil, nil, nil, time.Second)
+	if err := ku.Clear(context.Background(), userID.Hex()); err != nil {
+		t.Fatalf("err = %v", err)
+	}
+	if !keranjangRepo.deleted {
+		t.Fatal("keranjang not deleted")
+	}
+
+	if err := ku.Clear(context.Background(), userID.Hex()); err == nil {
+		t.Fatal("clearing a missing keranjang should fail")
+	}
+}
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"net/http"
@@ -9,35 +10,72 @@ import (
 	_authHttp "warunk-bem/auth/delivery/http"
 	_authUsecase "warunk-bem/auth/usecase"
 	"warunk-bem/author"
+	"warunk-bem/cache"
+	_cacheHttp "warunk-bem/cache/delivery/http"
+	_cloudinaryUsecase "warunk-bem/cloudinary/usecase"
+	"warunk-bem/config"
 	_dashboardHttp "warunk-bem/dashboard/delivery/http"
 	_dashboardRepo "warunk-bem/dashboard/repository"
 	_dashboardUcase "warunk-bem/dashboard/usecase"
-	_favoriteHttp "warunk-bem/favorite/delivery/http"
-	_favoriteRepo "warunk-bem/favorite/repository"
-	_favoriteUsecase "warunk-bem/favorite/usecase"
+	"warunk-bem/domain"
 	"warunk-bem/helpers"
 	_keranjangHttp "warunk-bem/keranjang/delivery/http"
 	_keranjangRepo "warunk-bem/keranjang/repository"
 	_keranjangUcase "warunk-bem/keranjang/usecase"
 	"warunk-bem/middlewares"
+	_notifikasiHttp "warunk-bem/notifikasi/delivery/http"
+	_notifikasiRepo "warunk-bem/notifikasi/repository"
+	_notifikasiUsecase "warunk-bem/notifikasi/usecase"
+	_paymentHttp "warunk-bem/payment/delivery/http"
+	_paymentProvider "warunk-bem/payment/provider"
+	_paymentRepo "warunk-bem/payment/repository"
+	_paymentUsecase "warunk-bem/payment/usecase"
+	_pickupHttp "warunk-bem/pickup/delivery/http"
+	_pickupRepo "warunk-bem/pickup/repository"
+	_pickupUsecase "warunk-bem/pickup/usecase"
+	_pinHttp "warunk-bem/pin/delivery/http"
+	_pinRepo "warunk-bem/pin/repository"
+	_pinUsecase "warunk-bem/pin/usecase"
+	_preOrderHttp "warunk-bem/preorder/delivery/http"
+	_preOrderRepo "warunk-bem/preorder/repository"
+	_preOrderUsecase "warunk-bem/preorder/usecase"
 	_produkHttp "warunk-bem/produk/delivery/http"
 	_produkRepo "warunk-bem/produk/repository"
 	_produkUsecase "warunk-bem/produk/usecase"
+	_promoHttp "warunk-bem/promo/delivery/http"
+	_promoRepo "warunk-bem/promo/repository"
+	_promoUsecase "warunk-bem/promo/usecase"
+	_reviewHttp "warunk-bem/review/delivery/http"
+	_reviewRepo "warunk-bem/review/repository"
+	_reviewUsecase "warunk-bem/review/usecase"
+	_savedListHttp "warunk-bem/savedlist/delivery/http"
+	_savedListRepo "warunk-bem/savedlist/repository"
+	_savedListUsecase "warunk-bem/savedlist/usecase"
+	_stokHttp "warunk-bem/stok/delivery/http"
+	_stokRepo "warunk-bem/stok/repository"
+	_stokUsecase "warunk-bem/stok/usecase"
+	"warunk-bem/storage"
 	_transaksihttp "warunk-bem/transaksi/delivery/http"
 	_transaksiRepo "warunk-bem/transaksi/repository"
 	_transaksiUsecase "warunk-bem/transaksi/usecase"
+	_transferHttp "warunk-bem/transfer/delivery/http"
+	_transferRepo "warunk-bem/transfer/repository"
+	_transferUsecase "warunk-bem/transfer/usecase"
 	_userHttp "warunk-bem/user/delivery/http"
 	_userRepo "warunk-bem/user/repository"
 	_userUcase "warunk-bem/user/usecase"
 	_userAmounthttp "warunk-bem/user_amount/delivery/http"
 	_userAmountRepo "warunk-bem/user_amount/repository"
 	_userAmountUsecase "warunk-bem/user_amount/usecase"
+	_userQRHttp "warunk-bem/user_qr/delivery/http"
+	_userQRRepo "warunk-bem/user_qr/repository"
+	_userQRUsecase "warunk-bem/user_qr/usecase"
 	_warunkHttp "warunk-bem/warunk/delivery/http"
 	_warunkRepo "warunk-bem/warunk/repository"
 	_warunktUsecase "warunk-bem/warunk/usecase"
-	_wishlistHttp "warunk-bem/wishlist/delivery/http"
-	_wishlistRepo "warunk-bem/wishlist/repository"
-	_wishlistUsecase "warunk-bem/wishlist/usecase"
+	_withdrawalHttp "warunk-bem/withdrawal/delivery/http"
+	_withdrawalRepo "warunk-bem/withdrawal/repository"
+	_withdrawalUsecase "warunk-bem/withdrawal/usecase"
 
 	docs "warunk-bem/docs"
 
@@ -102,13 +140,14 @@ func main() {
 		log.Fatal(err)
 	}
 	redisclient := author.InitRedisClient()
+	appCache := cache.NewRedisCache(redisclient)
 
 	timeoutContext := time.Duration(CONTEXT_TIMEOUT) * time.Second
 	database := author.App.Mongo.Database(os.Getenv("MONGODB_NAME"))
 	userAmountRepo := _userAmountRepo.NewUserAmountRepository(database)
 
 	userRepo := _userRepo.NewUserRepository(database)
-	usrUsecase := _userUcase.NewUserUsecase(userRepo, userAmountRepo, redisclient, timeoutContext)
+	usrUsecase := _userUcase.NewUserUsecase(userRepo, userAmountRepo, appCache, timeoutContext)
 
 	// Main Routes API
 	api := r.Group("/api/v1")
@@ -122,36 +161,166 @@ func main() {
 	loginUsecase := _authUsecase.NewAuthUsecase(userRepo, redisclient, timeoutContext)
 	_authHttp.NewAuthHandler(api, protected, loginUsecase)
 
+	storageConfig := config.EnvStorage()
+	mediaStorage, err := storage.New(storageConfig)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if storageConfig.Driver == config.StorageDriverLocal {
+		r.Static(storageConfig.LocalRoute, storageConfig.LocalDir)
+	}
+	MediaUsecase := _cloudinaryUsecase.NewMediaUpload(mediaStorage, storageConfig.MaxSize)
+
+	StokRepository := _stokRepo.NewStokRepository(database)
+
+	SavedListRepository := _savedListRepo.NewSavedListRepository(database)
+	if err := SavedListRepository.EnsureIndexes(context.Background()); err != nil {
+		log.Println("cannot create saved list indexes:", err)
+	}
+	NotifikasiRepository := _notifikasiRepo.NewNotifikasiRepository(database)
+	NotifikasiUsecase := _notifikasiUsecase.NewNotifikasiUsecase(NotifikasiRepository, userRepo, SavedListRepository, redisclient, timeoutContext)
+	_notifikasiHttp.NewNotifikasiHandler(protected, NotifikasiUsecase)
+
+	ReviewRepository := _reviewRepo.NewReviewRepository(database)
+	if err := ReviewRepository.EnsureIndexes(context.Background()); err != nil {
+		log.Println("cannot create review indexes:", err)
+	}
+
 	ProdukRepository := _produkRepo.NewProdukRepository(database)
-	ProdukUsecase := _produkUsecase.NewProdukUsecase(ProdukRepository, userRepo, redisclient, timeoutContext)
-	_produkHttp.NewProdukHandler(api, protectedAdmin, ProdukUsecase)
+	ProdukUsecase := _produkUsecase.NewProdukUsecase(ProdukRepository, userRepo, StokRepository, MediaUsecase, NotifikasiUsecase, ReviewRepository, appCache, timeoutContext)
+	_produkHttp.NewProdukHandler(api, protectedAdmin, ProdukUsecase, MediaUsecase)
+
+	StokUsecase := _stokUsecase.NewStokUsecase(StokRepository, ProdukRepository, NotifikasiUsecase, appCache, timeoutContext)
+	_stokHttp.NewStokHandler(protectedAdmin, StokUsecase)
+
+	PromoRepository := _promoRepo.NewPromoRepository(database)
+	if err := PromoRepository.EnsureIndexes(context.Background()); err != nil {
+		log.Println("cannot create promo indexes:", err)
+	}
+	PromoUsecase := _promoUsecase.NewPromoUsecase(PromoRepository, timeoutContext)
+	_promoHttp.NewPromoHandler(protectedAdmin, PromoUsecase)
+
+	pinConfig := config.EnvPin()
+	PinRepository := _pinRepo.NewPinRepository(database)
+	if err := PinRepository.EnsureIndexes(context.Background()); err != nil {
+		log.Println("cannot create pin indexes:", err)
+	}
+	PinUsecase := _pinUsecase.NewPinUsecase(PinRepository, userRepo, pinConfig.MaxAttempts, pinConfig.LockDuration, pinConfig.CheckoutThreshold, timeoutContext)
+	_pinHttp.NewPinHandler(protected, PinUsecase)
 
 	KeranjangRepository := _keranjangRepo.NewKeranjangRepository(database)
-	KeranjangUsecase := _keranjangUcase.NewKeranjangUsecase(KeranjangRepository, ProdukRepository, userRepo, redisclient, timeoutContext)
+	KeranjangUsecase := _keranjangUcase.NewKeranjangUsecase(KeranjangRepository, ProdukRepository, userRepo, userAmountRepo, PromoUsecase, redisclient, timeoutContext)
 	_keranjangHttp.NewKeranjangHandler(protected, protectedAdmin, KeranjangUsecase, ProdukUsecase)
 
-	FavoriteRepository := _favoriteRepo.NewFavoriteRepository(database)
-	FavoriteUsecase := _favoriteUsecase.NewFavoriteUsecase(FavoriteRepository, ProdukRepository, userRepo, redisclient, timeoutContext)
-	_favoriteHttp.NewFavoriteHandler(protected, protectedAdmin, FavoriteUsecase, ProdukUsecase)
+	SavedListUsecase := _savedListUsecase.NewSavedListUsecase(SavedListRepository, ProdukRepository, userRepo, KeranjangUsecase, appCache, timeoutContext)
+	_savedListHttp.NewSavedListHandler(protected, protectedAdmin, SavedListUsecase)
+
+	TransaksiRepository := _transaksiRepo.NewTransaksiRepository(database)
 
-	WishlistRepository := _wishlistRepo.NewWishlistRepository(database)
-	WishlistUsecase := _wishlistUsecase.NewWishlistUsecase(WishlistRepository, ProdukRepository, userRepo, redisclient, timeoutContext)
-	_wishlistHttp.NewWishlistHandler(protected, protectedAdmin, WishlistUsecase, ProdukUsecase)
+	PickupRepository := _pickupRepo.NewPickupRepository(database)
+	if err := PickupRepository.EnsureIndexes(context.Background()); err != nil {
+		log.Println("cannot create pickup indexes:", err)
+	}
+	PickupUsecase := _pickupUsecase.NewPickupUsecase(PickupRepository, TransaksiRepository, ProdukRepository, StokRepository, userAmountRepo, PromoUsecase, NotifikasiUsecase, appCache, timeoutContext)
+	_pickupHttp.NewPickupHandler(protected, protectedAdmin, PickupUsecase)
 
 	WarunkRepository := _warunkRepo.NewWarunkRepository(database)
-	WarunkUsecase := _warunktUsecase.NewWarunkUsecase(WarunkRepository, ProdukRepository, userRepo, redisclient, timeoutContext)
+
+	preOrderConfig := config.EnvPreOrder()
+	PreOrderRepository := _preOrderRepo.NewPreOrderRepository(database)
+	if err := PreOrderRepository.EnsureIndexes(context.Background()); err != nil {
+		log.Println("cannot create pre-order indexes:", err)
+	}
+	PreOrderUsecase := _preOrderUsecase.NewPreOrderUsecase(PreOrderRepository, ProdukRepository, userRepo, userAmountRepo, WarunkRepository, TransaksiRepository, StokRepository, PinUsecase, PickupUsecase, NotifikasiUsecase, appCache, preOrderConfig.HoldDuration, timeoutContext)
+	_preOrderHttp.NewPreOrderHandler(protected, protectedAdmin, PreOrderUsecase)
+
+	// Saldo pre-order yang sesinya tidak pernah dibuka dikembalikan secara berkala
+	go func() {
+		for range time.Tick(time.Minute) {
+			if _, err := PreOrderUsecase.ReleaseExpired(context.Background()); err != nil {
+				log.Println("cannot release pre-order:", err)
+			}
+		}
+	}()
+
+	WarunkUsecase := _warunktUsecase.NewWarunkUsecase(WarunkRepository, ProdukRepository, userRepo, StokRepository, PickupUsecase, PreOrderUsecase, NotifikasiUsecase, appCache, timeoutContext)
 	_warunkHttp.NewWarunkHandler(protectedAdmin, WarunkUsecase, ProdukUsecase)
 
-	TransaksiRepository := _transaksiRepo.NewTransaksiRepository(database)
-	TransaksiUsecase := _transaksiUsecase.NewTransaksiUsecase(TransaksiRepository, KeranjangRepository, ProdukRepository, userRepo, userAmountRepo, WarunkRepository, redisclient, timeoutContext)
+	userQRConfig := config.EnvUserQR()
+	if err := userQRConfig.Validate(); err != nil {
+		log.Fatal(err)
+	}
+	UserQRRepository := _userQRRepo.NewUserQRRepository(database)
+	if err := UserQRRepository.EnsureIndexes(context.Background()); err != nil {
+		log.Println("cannot create user qr indexes:", err)
+	}
+	UserQRUsecase := _userQRUsecase.NewUserQRUsecase(UserQRRepository, userRepo, userQRConfig.Secret, userQRConfig.TTL, timeoutContext)
+	_userQRHttp.NewUserQRHandler(protected, protectedAdmin, UserQRUsecase)
+
+	TransaksiUsecase := _transaksiUsecase.NewTransaksiUsecase(TransaksiRepository, KeranjangRepository, ProdukRepository, userRepo, userAmountRepo, WarunkRepository, StokRepository, PromoUsecase, PinUsecase, PickupUsecase, UserQRUsecase, NotifikasiUsecase, appCache, timeoutContext)
 	_transaksihttp.NewUserHandler(protected, protectedAdmin, TransaksiUsecase)
 
+	ReviewUsecase := _reviewUsecase.NewReviewUsecase(ReviewRepository, TransaksiRepository, ProdukRepository, userRepo, appCache, timeoutContext)
+	_reviewHttp.NewReviewHandler(api, protected, protectedAdmin, ReviewUsecase)
+
 	DashboardRepository := _dashboardRepo.NewDashboardRepository(database)
-	DashboardUsecase := _dashboardUcase.NewDashboardUsecase(DashboardRepository, userRepo, userAmountRepo, ProdukRepository, TransaksiRepository, redisclient, timeoutContext)
-	_dashboardHttp.NewDashboardHandler(protected, DashboardUsecase)
+	DashboardUsecase := _dashboardUcase.NewDashboardUsecase(DashboardRepository, userRepo, userAmountRepo, ProdukRepository, TransaksiRepository, WarunkRepository, SavedListUsecase, appCache, timeoutContext)
+	_dashboardHttp.NewDashboardHandler(protected, protectedAdmin, DashboardUsecase)
+
+	UserAmountUsecase := _userAmountUsecase.NewUserAmountUsecase(userAmountRepo, userRepo, NotifikasiUsecase, appCache, timeoutContext)
+	_userAmounthttp.NewUserAmountHandler(protected, protectedAdmin, UserAmountUsecase, MediaUsecase)
+
+	// Pembayaran online hanya aktif jika PAYMENT_PROVIDER diisi
+	paymentConfig := config.EnvPayment()
+	if paymentConfig.Provider != "" {
+		paymentProvider, err := _paymentProvider.New(paymentConfig)
+		if err != nil {
+			log.Fatal(err)
+		}
+		_, paymentSimulate := paymentProvider.(domain.PaymentSimulator)
+		PaymentRepository := _paymentRepo.NewPaymentRepository(database)
+		if err := PaymentRepository.EnsureIndexes(context.Background()); err != nil {
+			log.Println("cannot create payment indexes:", err)
+		}
+		PaymentUsecase := _paymentUsecase.NewPaymentUsecase(PaymentRepository, userAmountRepo, NotifikasiUsecase, paymentProvider, appCache, paymentConfig.Expiry, paymentConfig.MinAmount, timeoutContext)
+		_paymentHttp.NewPaymentHandler(api, protected, protectedAdmin, PaymentUsecase, paymentSimulate && paymentConfig.Simulate)
+
+		// Pembayaran yang melewati batas waktu ditandai expired secara berkala
+		go func() {
+			for range time.Tick(time.Minute) {
+				if _, err := PaymentUsecase.ExpirePending(context.Background()); err != nil {
+					log.Println("cannot expire payment:", err)
+				}
+			}
+		}()
+	}
+
+	transferConfig := config.EnvTransfer()
+	TransferRepository := _transferRepo.NewTransferRepository(database)
+	if err := TransferRepository.EnsureIndexes(context.Background()); err != nil {
+		log.Println("cannot create transfer indexes:", err)
+	}
+	TransferUsecase := _transferUsecase.NewTransferUsecase(TransferRepository, userRepo, userAmountRepo, PinUsecase, NotifikasiUsecase, appCache, transferConfig.MinAmount, transferConfig.DailyLimit, transferConfig.DailyCount, timeoutContext)
+	_transferHttp.NewTransferHandler(protected, TransferUsecase)
+
+	withdrawalConfig := config.EnvWithdrawal()
+	WithdrawalRepository := _withdrawalRepo.NewWithdrawalRepository(database)
+	if err := WithdrawalRepository.EnsureIndexes(context.Background()); err != nil {
+		log.Println("cannot create withdrawal indexes:", err)
+	}
+	WithdrawalUsecase := _withdrawalUsecase.NewWithdrawalUsecase(WithdrawalRepository, userRepo, userAmountRepo, PinUsecase, NotifikasiUsecase, appCache, withdrawalConfig.MinAmount, withdrawalConfig.Expiry, timeoutContext)
+	_withdrawalHttp.NewWithdrawalHandler(protected, protectedAdmin, WithdrawalUsecase)
+
+	// Saldo penarikan yang tidak diproses sampai batas waktu dikembalikan secara berkala
+	go func() {
+		for range time.Tick(time.Minute) {
+			if _, err := WithdrawalUsecase.ExpirePending(context.Background()); err != nil {
+				log.Println("cannot expire withdrawal:", err)
+			}
+		}
+	}()
 
-	UserAmountUsecase := _userAmountUsecase.NewUserAmountUsecase(userAmountRepo, userRepo, redisclient, timeoutContext)
-	_userAmounthttp.NewUserAmountHandler(protectedAdmin, UserAmountUsecase)
+	_cacheHttp.NewCacheHandler(protectedAdmin, appCache)
 
 	api.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerfiles.Handler))
 	api.GET("/healthchecker", func(ctx *gin.Context) {
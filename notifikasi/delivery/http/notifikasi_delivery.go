@@ -0,0 +1,276 @@
+package http
+
+import (
+	"context"
+	"math"
+	"net/http"
+	"strconv"
+	"warunk-bem/domain"
+	"warunk-bem/dtos"
+	"warunk-bem/middlewares"
+
+	"github.com/gin-gonic/gin"
+)
+
+type NotifikasiHandler struct {
+	NotifikasiUsecase domain.NotifikasiUsecase
+}
+
+func NewNotifikasiHandler(protected *gin.RouterGroup, nu domain.NotifikasiUsecase) {
+	handler := &NotifikasiHandler{
+		NotifikasiUsecase: nu,
+	}
+
+	protected = protected.Group("/notifikasi")
+
+	protected.GET("", handler.GetAllWithPage)
+	protected.PUT("/read", handler.MarkAllRead)
+	protected.GET("/preference", handler.GetPreference)
+	protected.PUT("/preference", handler.UpdatePreference)
+	protected.PUT("/:id/read", handler.MarkRead)
+}
+
+func (nh *NotifikasiHandler) GetAllWithPage(c *gin.Context) {
+	idUser, err := middlewares.IsUser(c)
+	if err != nil {
+		c.JSON(
+			http.StatusUnauthorized,
+			dtos.NewErrorResponse(
+				http.StatusUnauthorized,
+				"Unauthorized",
+				dtos.GetErrorData(err),
+			),
+		)
+		return
+	}
+
+	rp, err := strconv.ParseInt(c.Query("rp"), 10, 64)
+	if err != nil {
+		rp = 25
+	}
+
+	page, err := strconv.ParseInt(c.Query("p"), 10, 64)
+	if err != nil {
+		page = 1
+	}
+
+	unreadOnly, _ := strconv.ParseBool(c.Query("unread"))
+
+	ctx := c.Request.Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	res, count, unread, err := nh.NotifikasiUsecase.GetAllWithPage(ctx, idUser, rp, page, unreadOnly)
+	if err != nil {
+		c.JSON(
+			http.StatusBadRequest,
+			dtos.NewErrorResponse(
+				http.StatusBadRequest,
+				"Cannot Get Notifikasi",
+				err.Error(),
+			),
+		)
+		return
+	}
+
+	result := dtos.GetAllNotifikasiResponse{
+		Total:       count,
+		Unread:      unread,
+		PerPage:     rp,
+		CurrentPage: page,
+		LastPage:    int64(math.Ceil(float64(count) / float64(rp))),
+		From:        (page * rp) - rp + 1,
+		To:          page * rp,
+		Notifikasi:  res,
+	}
+
+	c.JSON(
+		http.StatusOK,
+		dtos.NewResponse(
+			http.StatusOK,
+			"Success Get Notifikasi",
+			result,
+		),
+	)
+}
+
+func (nh *NotifikasiHandler) MarkRead(c *gin.Context) {
+	idUser, err := middlewares.IsUser(c)
+	if err != nil {
+		c.JSON(
+			http.StatusUnauthorized,
+			dtos.NewErrorResponse(
+				http.StatusUnauthorized,
+				"Unauthorized",
+				dtos.GetErrorData(err),
+			),
+		)
+		return
+	}
+
+	ctx := c.Request.Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	err = nh.NotifikasiUsecase.MarkRead(ctx, c.Param("id"), idUser)
+	if err != nil {
+		c.JSON(
+			http.StatusNotFound,
+			dtos.NewErrorResponse(
+				http.StatusNotFound,
+				"Cannot Read Notifikasi",
+				err.Error(),
+			),
+		)
+		return
+	}
+
+	c.JSON(
+		http.StatusOK,
+		dtos.NewResponseMessage(
+			http.StatusOK,
+			"Success Read Notifikasi",
+		),
+	)
+}
+
+func (nh *NotifikasiHandler) MarkAllRead(c *gin.Context) {
+	idUser, err := middlewares.IsUser(c)
+	if err != nil {
+		c.JSON(
+			http.StatusUnauthorized,
+			dtos.NewErrorResponse(
+				http.StatusUnauthorized,
+				"Unauthorized",
+				dtos.GetErrorData(err),
+			),
+		)
+		return
+	}
+
+	ctx := c.Request.Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	err = nh.NotifikasiUsecase.MarkAllRead(ctx, idUser)
+	if err != nil {
+		c.JSON(
+			http.StatusBadRequest,
+			dtos.NewErrorResponse(
+				http.StatusBadRequest,
+				"Cannot Read Notifikasi",
+				err.Error(),
+			),
+		)
+		return
+	}
+
+	c.JSON(
+		http.StatusOK,
+		dtos.NewResponseMessage(
+			http.StatusOK,
+			"Success Read All Notifikasi",
+		),
+	)
+}
+
+func (nh *NotifikasiHandler) GetPreference(c *gin.Context) {
+	idUser, err := middlewares.IsUser(c)
+	if err != nil {
+		c.JSON(
+			http.StatusUnauthorized,
+			dtos.NewErrorResponse(
+				http.StatusUnauthorized,
+				"Unauthorized",
+				dtos.GetErrorData(err),
+			),
+		)
+		return
+	}
+
+	ctx := c.Request.Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	res, err := nh.NotifikasiUsecase.GetPreference(ctx, idUser)
+	if err != nil {
+		c.JSON(
+			http.StatusNotFound,
+			dtos.NewErrorResponse(
+				http.StatusNotFound,
+				"Cannot Get Notifikasi Preference",
+				err.Error(),
+			),
+		)
+		return
+	}
+
+	c.JSON(
+		http.StatusOK,
+		dtos.NewResponse(
+			http.StatusOK,
+			"Success Get Notifikasi Preference",
+			res,
+		),
+	)
+}
+
+func (nh *NotifikasiHandler) UpdatePreference(c *gin.Context) {
+	idUser, err := middlewares.IsUser(c)
+	if err != nil {
+		c.JSON(
+			http.StatusUnauthorized,
+			dtos.NewErrorResponse(
+				http.StatusUnauthorized,
+				"Unauthorized",
+				dtos.GetErrorData(err),
+			),
+		)
+		return
+	}
+
+	var req dtos.NotifikasiPreferenceRequest
+	err = c.ShouldBindJSON(&req)
+	if err != nil {
+		c.JSON(
+			http.StatusUnprocessableEntity,
+			dtos.NewErrorResponse(
+				http.StatusUnprocessableEntity,
+				"Invalid Request",
+				dtos.GetErrorData(err),
+			),
+		)
+		return
+	}
+
+	ctx := c.Request.Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	res, err := nh.NotifikasiUsecase.UpdatePreference(ctx, idUser, &req)
+	if err != nil {
+		c.JSON(
+			http.StatusBadRequest,
+			dtos.NewErrorResponse(
+				http.StatusBadRequest,
+				"Cannot Update Notifikasi Preference",
+				err.Error(),
+			),
+		)
+		return
+	}
+
+	c.JSON(
+		http.StatusOK,
+		dtos.NewResponse(
+			http.StatusOK,
+			"Success Update Notifikasi Preference",
+			res,
+		),
+	)
+}
@@ -0,0 +1,115 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+	"warunk-bem/domain"
+	"warunk-bem/mongo"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type notifikasiRepository struct {
+	DB         mongo.Database
+	Collection mongo.Collection
+}
+
+const (
+	timeFormat     = "2006-01-02T15:04:05.999Z07:00" // reduce precision from RFC3339Nano as date format
+	collectionName = "notifikasi"
+)
+
+func NewNotifikasiRepository(DB mongo.Database) domain.NotifikasiRepository {
+	return &notifikasiRepository{DB, DB.Collection(collectionName)}
+}
+
+func (r *notifikasiRepository) InsertMany(ctx context.Context, req []*domain.Notifikasi) error {
+	if len(req) == 0 {
+		return nil
+	}
+
+	docs := make([]interface{}, 0, len(req))
+	for _, v := range req {
+		docs = append(docs, v)
+	}
+
+	_, err := r.Collection.InsertMany(ctx, docs)
+	return err
+}
+
+func (r *notifikasiRepository) GetAllWithPage(ctx context.Context, rp int64, p int64, filter interface{}, setsort interface{}) ([]domain.Notifikasi, int64, error) {
+	var (
+		notifikasi []domain.Notifikasi
+		err        error
+	)
+
+	findOptions := options.Find()
+	findOptions.SetLimit(rp)
+	findOptions.SetSkip((p - 1) * rp)
+	if setsort != nil {
+		findOptions.SetSort(setsort)
+	}
+
+	cursor, err := r.Collection.Find(ctx, filter, findOptions)
+	if err != nil {
+		return notifikasi, 0, err
+	}
+
+	err = cursor.All(ctx, &notifikasi)
+	if err != nil {
+		return notifikasi, 0, err
+	}
+
+	total, err := r.Collection.CountDocuments(ctx, filter)
+	if err != nil {
+		return notifikasi, 0, err
+	}
+
+	return notifikasi, total, nil
+}
+
+func (r *notifikasiRepository) CountUnread(ctx context.Context, userID string) (int64, error) {
+	userHex, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return 0, err
+	}
+
+	return r.Collection.CountDocuments(ctx, bson.M{"user_id": userHex, "read_at": bson.M{"$in": []interface{}{nil, primitive.Null{}}}})
+}
+
+func (r *notifikasiRepository) MarkRead(ctx context.Context, id string, userID string) error {
+	idHex, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return err
+	}
+
+	userHex, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return err
+	}
+
+	result, err := r.Collection.UpdateOne(ctx, bson.M{"_id": idHex, "user_id": userHex}, bson.M{"$set": bson.M{"read_at": time.Now()}})
+	if err != nil {
+		return err
+	}
+
+	if result.MatchedCount == 0 {
+		return errors.New("notifikasi tidak ditemukan")
+	}
+
+	return nil
+}
+
+func (r *notifikasiRepository) MarkAllRead(ctx context.Context, userID string) error {
+	userHex, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return err
+	}
+
+	filter := bson.M{"user_id": userHex, "read_at": bson.M{"$in": []interface{}{nil, primitive.Null{}}}}
+	_, err = r.Collection.UpdateMany(ctx, filter, bson.M{"$set": bson.M{"read_at": time.Now()}})
+	return err
+}
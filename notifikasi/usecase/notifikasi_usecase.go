@@ -0,0 +1,406 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+	"warunk-bem/domain"
+	"warunk-bem/dtos"
+	"warunk-bem/utils"
+
+	"github.com/go-redis/redis/v8"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type notifikasiUsecase struct {
+	NotifikasiRepo domain.NotifikasiRepository
+	UserRepo       domain.UserRepository
+	SavedListRepo  domain.SavedListRepository
+	RedisClient    *redis.Client
+	contextTimeout time.Duration
+}
+
+func NewNotifikasiUsecase(NotifikasiRepo domain.NotifikasiRepository, UserRepo domain.UserRepository, SavedListRepo domain.SavedListRepository, RedisClient *redis.Client, contextTimeout time.Duration) domain.NotifikasiUsecase {
+	return &notifikasiUsecase{
+		NotifikasiRepo: NotifikasiRepo,
+		UserRepo:       UserRepo,
+		SavedListRepo:  SavedListRepo,
+		RedisClient:    RedisClient,
+		contextTimeout: contextTimeout,
+	}
+}
+
+func toNotifikasiResponse(notifikasi *domain.Notifikasi) *dtos.NotifikasiResponse {
+	res := &dtos.NotifikasiResponse{
+		ID:        notifikasi.ID.Hex(),
+		CreatedAt: notifikasi.CreatedAt,
+		Type:      notifikasi.Type,
+		Title:     notifikasi.Title,
+		Message:   notifikasi.Message,
+		ReadAt:    notifikasi.ReadAt,
+	}
+
+	if !notifikasi.ReferenceID.IsZero() {
+		res.ReferenceID = notifikasi.ReferenceID.Hex()
+	}
+
+	return res
+}
+
+// GetNotifikasi godoc
+// @Summary      Get Notifikasi
+// @Description  Get in-app notification feed of the logged in user
+// @Tags         User - Notifikasi
+// @Accept       json
+// @Produce      json
+// @Param        unread query bool false "Only unread notification"
+// @Success      200 {object} dtos.GetAllNotifikasiResponse
+// @Failure      400 {object} dtos.BadRequestResponse
+// @Failure      401 {object} dtos.UnauthorizedResponse
+// @Failure      403 {object} dtos.ForbiddenResponse
+// @Failure      404 {object} dtos.NotFoundResponse
+// @Failure      500 {object} dtos.InternalServerErrorResponse
+// @Router       /notifikasi [get]
+// @Security BearerAuth
+func (nu *notifikasiUsecase) GetAllWithPage(c context.Context, userID string, rp int64, p int64, unreadOnly bool) ([]*dtos.NotifikasiResponse, int64, int64, error) {
+	res := []*dtos.NotifikasiResponse{}
+
+	ctx, cancel := context.WithTimeout(c, nu.contextTimeout)
+	defer cancel()
+
+	userHex, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return res, 0, 0, err
+	}
+
+	filter := bson.M{"user_id": userHex}
+	if unreadOnly {
+		filter["read_at"] = bson.M{"$in": []interface{}{nil, primitive.Null{}}}
+	}
+
+	notifikasis, count, err := nu.NotifikasiRepo.GetAllWithPage(ctx, rp, p, filter, bson.M{"created_at": -1})
+	if err != nil {
+		return res, 0, 0, err
+	}
+
+	unread, err := nu.NotifikasiRepo.CountUnread(ctx, userID)
+	if err != nil {
+		return res, 0, 0, err
+	}
+
+	for i := range notifikasis {
+		res = append(res, toNotifikasiResponse(&notifikasis[i]))
+	}
+
+	return res, count, unread, nil
+}
+
+// ReadNotifikasi godoc
+// @Summary      Read Notifikasi
+// @Description  Mark one notification as read
+// @Tags         User - Notifikasi
+// @Accept       json
+// @Produce      json
+// @Param        id path string true "id notifikasi"
+// @Success      200 {object} dtos.StatusOKResponse
+// @Failure      400 {object} dtos.BadRequestResponse
+// @Failure      401 {object} dtos.UnauthorizedResponse
+// @Failure      403 {object} dtos.ForbiddenResponse
+// @Failure      404 {object} dtos.NotFoundResponse
+// @Failure      500 {object} dtos.InternalServerErrorResponse
+// @Router       /notifikasi/{id}/read [put]
+// @Security BearerAuth
+func (nu *notifikasiUsecase) MarkRead(c context.Context, id string, userID string) error {
+	ctx, cancel := context.WithTimeout(c, nu.contextTimeout)
+	defer cancel()
+
+	return nu.NotifikasiRepo.MarkRead(ctx, id, userID)
+}
+
+// ReadAllNotifikasi godoc
+// @Summary      Read All Notifikasi
+// @Description  Mark every notification of the logged in user as read
+// @Tags         User - Notifikasi
+// @Accept       json
+// @Produce      json
+// @Success      200 {object} dtos.StatusOKResponse
+// @Failure      400 {object} dtos.BadRequestResponse
+// @Failure      401 {object} dtos.UnauthorizedResponse
+// @Failure      403 {object} dtos.ForbiddenResponse
+// @Failure      404 {object} dtos.NotFoundResponse
+// @Failure      500 {object} dtos.InternalServerErrorResponse
+// @Router       /notifikasi/read [put]
+// @Security BearerAuth
+func (nu *notifikasiUsecase) MarkAllRead(c context.Context, userID string) error {
+	ctx, cancel := context.WithTimeout(c, nu.contextTimeout)
+	defer cancel()
+
+	return nu.NotifikasiRepo.MarkAllRead(ctx, userID)
+}
+
+// NotifyLowStock mengirim notifikasi in-app dan email ke semua admin
+// untuk produk yang stoknya baru saja turun di bawah batas reorder
+func (nu *notifikasiUsecase) NotifyLowStock(c context.Context, produks []domain.Produk) error {
+	if len(produks) == 0 {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(c, nu.contextTimeout)
+	defer cancel()
+
+	admins, _, err := nu.UserRepo.GetAllWithPage(ctx, 100, 1, bson.M{"role": "Admin"}, nil)
+	if err != nil {
+		return err
+	}
+
+	notifikasis := make([]*domain.Notifikasi, 0, len(admins)*len(produks))
+	for _, admin := range admins {
+		for _, produk := range produks {
+			notifikasis = append(notifikasis, &domain.Notifikasi{
+				ID:          primitive.NewObjectID(),
+				CreatedAt:   time.Now(),
+				UserID:      admin.ID,
+				Type:        domain.NotifikasiTypeLowStock,
+				Title:       "Stok menipis: " + produk.Name,
+				Message:     fmt.Sprintf("Stok %s tersisa %d, di bawah batas reorder %d", produk.Name, produk.Stock, produk.ReorderThreshold),
+				ReferenceID: produk.ID,
+			})
+		}
+	}
+
+	err = nu.NotifikasiRepo.InsertMany(ctx, notifikasis)
+	if err != nil {
+		return err
+	}
+
+	for i := range admins {
+		emailData := utils.EmailData{
+			FirstName: admins[i].Name,
+			Subject:   "Stok Produk Menipis",
+			Template:  "lowStock.html",
+			Data:      produks,
+		}
+
+		utils.SendEmail(&admins[i], &emailData)
+	}
+
+	return nil
+}
+
+// wishlistAlert adalah data template email wishlistAlert.html
+type wishlistAlert struct {
+	Produk      domain.Produk
+	BackInStock bool
+	PriceDrop   bool
+	OldPrice    int64
+}
+
+// NotifyWishlist memberi tahu user yang menyimpan produk di wishlist
+// ketika stok produk kembali tersedia atau harganya turun
+func (nu *notifikasiUsecase) NotifyWishlist(c context.Context, before *domain.Produk, after *domain.Produk) error {
+	if before == nil || after == nil || after.DeletedAt != nil {
+		return nil
+	}
+
+	alert := wishlistAlert{
+		Produk:      *after,
+		BackInStock: before.Stock <= 0 && after.Stock > 0,
+		PriceDrop:   after.Price < before.Price,
+		OldPrice:    before.Price,
+	}
+
+	if !alert.BackInStock && !alert.PriceDrop {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(c, nu.contextTimeout)
+	defer cancel()
+
+	userIDs, err := nu.SavedListRepo.GetUserIDsByProduk(ctx, after.ID, domain.SavedListKindWishlist)
+	if err != nil {
+		return err
+	}
+
+	notifikasis := make([]*domain.Notifikasi, 0, len(userIDs)*2)
+	recipients := make([]domain.User, 0, len(userIDs))
+	for _, userID := range userIDs {
+		user, err := nu.UserRepo.FindOne(ctx, userID.Hex())
+		if err != nil {
+			continue
+		}
+
+		pref := user.NotifikasiPreference
+		backInStock := alert.BackInStock && !pref.MuteBackInStock
+		priceDrop := alert.PriceDrop && !pref.MutePriceDrop
+
+		if backInStock {
+			notifikasis = append(notifikasis, &domain.Notifikasi{
+				ID:          primitive.NewObjectID(),
+				CreatedAt:   time.Now(),
+				UserID:      user.ID,
+				Type:        domain.NotifikasiTypeBackInStock,
+				Title:       "Tersedia lagi: " + after.Name,
+				Message:     fmt.Sprintf("%s di wishlist kamu sudah tersedia lagi, stok %d", after.Name, after.Stock),
+				ReferenceID: after.ID,
+			})
+		}
+
+		if priceDrop {
+			notifikasis = append(notifikasis, &domain.Notifikasi{
+				ID:          primitive.NewObjectID(),
+				CreatedAt:   time.Now(),
+				UserID:      user.ID,
+				Type:        domain.NotifikasiTypePriceDrop,
+				Title:       "Harga turun: " + after.Name,
+				Message:     fmt.Sprintf("Harga %s di wishlist kamu turun dari %d menjadi %d", after.Name, before.Price, after.Price),
+				ReferenceID: after.ID,
+			})
+		}
+
+		if (backInStock || priceDrop) && !pref.MuteEmail {
+			recipients = append(recipients, *user)
+		}
+	}
+
+	if len(notifikasis) == 0 {
+		return nil
+	}
+
+	err = nu.NotifikasiRepo.InsertMany(ctx, notifikasis)
+	if err != nil {
+		return err
+	}
+
+	for i := range recipients {
+		// isi email mengikuti preferensi masing-masing user
+		data := alert
+		data.BackInStock = alert.BackInStock && !recipients[i].NotifikasiPreference.MuteBackInStock
+		data.PriceDrop = alert.PriceDrop && !recipients[i].NotifikasiPreference.MutePriceDrop
+
+		emailData := utils.EmailData{
+			FirstName: recipients[i].Name,
+			Subject:   "Kabar Wishlist: " + after.Name,
+			Template:  "wishlistAlert.html",
+			Data:      data,
+		}
+
+		utils.SendEmail(&recipients[i], &emailData)
+	}
+
+	return nil
+}
+
+// NotifyUser menyimpan satu notifikasi in-app lalu mengirim email yang sama kecuali user mematikan email
+func (nu *notifikasiUsecase) NotifyUser(c context.Context, notifikasi *domain.Notifikasi) error {
+	ctx, cancel := context.WithTimeout(c, nu.contextTimeout)
+	defer cancel()
+
+	user, err := nu.UserRepo.FindOne(ctx, notifikasi.UserID.Hex())
+	if err != nil {
+		return err
+	}
+
+	if notifikasi.ID.IsZero() {
+		notifikasi.ID = primitive.NewObjectID()
+	}
+	if notifikasi.CreatedAt.IsZero() {
+		notifikasi.CreatedAt = time.Now()
+	}
+
+	err = nu.NotifikasiRepo.InsertMany(ctx, []*domain.Notifikasi{notifikasi})
+	if err != nil {
+		return err
+	}
+
+	if user.NotifikasiPreference.MuteEmail {
+		return nil
+	}
+
+	emailData := utils.EmailData{
+		FirstName: user.Name,
+		Subject:   notifikasi.Title,
+		Template:  "notifikasi.html",
+		Data:      notifikasi,
+	}
+
+	utils.SendEmail(user, &emailData)
+
+	return nil
+}
+
+func toNotifikasiPreferenceResponse(pref domain.NotifikasiPreference) *dtos.NotifikasiPreferenceResponse {
+	return &dtos.NotifikasiPreferenceResponse{
+		MuteBackInStock: pref.MuteBackInStock,
+		MutePriceDrop:   pref.MutePriceDrop,
+		MuteEmail:       pref.MuteEmail,
+	}
+}
+
+// GetNotifikasiPreference godoc
+// @Summary      Get Notifikasi Preference
+// @Description  Get wishlist notification opt-out settings of the logged in user
+// @Tags         User - Notifikasi
+// @Accept       json
+// @Produce      json
+// @Success      200 {object} dtos.NotifikasiPreferenceOKResponse
+// @Failure      401 {object} dtos.UnauthorizedResponse
+// @Failure      403 {object} dtos.ForbiddenResponse
+// @Failure      404 {object} dtos.NotFoundResponse
+// @Failure      500 {object} dtos.InternalServerErrorResponse
+// @Router       /notifikasi/preference [get]
+// @Security BearerAuth
+func (nu *notifikasiUsecase) GetPreference(c context.Context, userID string) (*dtos.NotifikasiPreferenceResponse, error) {
+	ctx, cancel := context.WithTimeout(c, nu.contextTimeout)
+	defer cancel()
+
+	user, err := nu.UserRepo.FindOne(ctx, userID)
+	if err != nil {
+		return nil, errors.New("user not found")
+	}
+
+	return toNotifikasiPreferenceResponse(user.NotifikasiPreference), nil
+}
+
+// UpdateNotifikasiPreference godoc
+// @Summary      Update Notifikasi Preference
+// @Description  Opt out of back-in-stock, price-drop or email notification, omitted fields are unchanged
+// @Tags         User - Notifikasi
+// @Accept       json
+// @Produce      json
+// @Param        request body dtos.NotifikasiPreferenceRequest true "Payload Body [RAW]"
+// @Success      200 {object} dtos.NotifikasiPreferenceOKResponse
+// @Failure      400 {object} dtos.BadRequestResponse
+// @Failure      401 {object} dtos.UnauthorizedResponse
+// @Failure      403 {object} dtos.ForbiddenResponse
+// @Failure      404 {object} dtos.NotFoundResponse
+// @Failure      500 {object} dtos.InternalServerErrorResponse
+// @Router       /notifikasi/preference [put]
+// @Security BearerAuth
+func (nu *notifikasiUsecase) UpdatePreference(c context.Context, userID string, req *dtos.NotifikasiPreferenceRequest) (*dtos.NotifikasiPreferenceResponse, error) {
+	ctx, cancel := context.WithTimeout(c, nu.contextTimeout)
+	defer cancel()
+
+	user, err := nu.UserRepo.FindOne(ctx, userID)
+	if err != nil {
+		return nil, errors.New("user not found")
+	}
+
+	if req.MuteBackInStock != nil {
+		user.NotifikasiPreference.MuteBackInStock = *req.MuteBackInStock
+	}
+	if req.MutePriceDrop != nil {
+		user.NotifikasiPreference.MutePriceDrop = *req.MutePriceDrop
+	}
+	if req.MuteEmail != nil {
+		user.NotifikasiPreference.MuteEmail = *req.MuteEmail
+	}
+
+	_, err = nu.UserRepo.UpdateOne(ctx, user, userID)
+	if err != nil {
+		return nil, errors.New("cannot update preference")
+	}
+
+	return toNotifikasiPreferenceResponse(user.NotifikasiPreference), nil
+}
@@ -0,0 +1,249 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+	"warunk-bem/domain"
+	"warunk-bem/dtos"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type mockNotifikasiRepo struct {
+	domain.NotifikasiRepository
+
+	inserted []*domain.Notifikasi
+}
+
+func (m *mockNotifikasiRepo) InsertMany(ctx context.Context, req []*domain.Notifikasi) error {
+	m.inserted = append(m.inserted, req...)
+	return nil
+}
+
+type mockUserRepo struct {
+	domain.UserRepository
+
+	users       map[string]*domain.User
+	adminFilter interface{}
+	adminsErr   error
+}
+
+func (m *mockUserRepo) FindOne(ctx context.Context, id string) (*domain.User, error) {
+	user, ok := m.users[id]
+	if !ok {
+		return nil, errors.New("not found")
+	}
+	return user, nil
+}
+
+func (m *mockUserRepo) GetAllWithPage(ctx context.Context, rp int64, p int64, filter interface{}, setsort interface{}) ([]domain.User, int64, error) {
+	m.adminFilter = filter
+	if m.adminsErr != nil {
+		return nil, 0, m.adminsErr
+	}
+	return []domain.User{}, 0, nil
+}
+
+func (m *mockUserRepo) UpdateOne(ctx context.Context, user *domain.User, id string) (*domain.User, error) {
+	m.users[id] = user
+	return user, nil
+}
+
+type mockSavedListRepo struct {
+	domain.SavedListRepository
+
+	userIDs []primitive.ObjectID
+}
+
+func (m *mockSavedListRepo) GetUserIDsByProduk(ctx context.Context, produkID primitive.ObjectID, kind string) ([]primitive.ObjectID, error) {
+	return m.userIDs, nil
+}
+
+// Pengiriman email membaca .env lewat utils.SendEmail, sehingga test hanya memakai
+// kasus tanpa penerima email
+func TestNotifyLowStock(t *testing.T) {
+	produk := domain.Produk{ID: primitive.NewObjectID(), Name: "Kopi", Stock: 2, ReorderThreshold: 5}
+
+	tests := []struct {
+		name        string
+		produks     []domain.Produk
+		adminsErr   error
+		wantErr     bool
+		wantQueried bool
+	}{
+		{name: "nothing to notify", produks: nil, wantQueried: false},
+		{name: "no admin", produks: []domain.Produk{produk}, wantQueried: true},
+		{name: "cannot load admin", produks: []domain.Produk{produk}, adminsErr: errors.New("mongo down"), wantErr: true, wantQueried: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &mockNotifikasiRepo{}
+			userRepo := &mockUserRepo{adminsErr: tt.adminsErr}
+			nu := NewNotifikasiUsecase(repo, userRepo, nil, nil, time.Second)
+
+			err := nu.NotifyLowStock(context.Background(), tt.produks)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if queried := userRepo.adminFilter != nil; queried != tt.wantQueried {
+				t.Fatalf("admin queried = %v, want %v", queried, tt.wantQueried)
+			}
+			if tt.wantQueried {
+				if filter, ok := userRepo.adminFilter.(bson.M); !ok || filter["role"] != "Admin" {
+					t.Fatalf("admin filter = %v, want role Admin", userRepo.adminFilter)
+				}
+			}
+			if len(repo.inserted) != 0 {
+				t.Fatalf("inserted %d notifikasi, want 0", len(repo.inserted))
+			}
+		})
+	}
+}
+
+func TestNotifyWishlist(t *testing.T) {
+	produkID := primitive.NewObjectID()
+	deletedAt := time.Now()
+
+	tests := []struct {
+		name      string
+		before    domain.Produk
+		after     domain.Produk
+		pref      domain.NotifikasiPreference
+		wantTypes []string
+	}{
+		{
+			name:      "back in stock",
+			before:    domain.Produk{Stock: 0, Price: 5000},
+			after:     domain.Produk{Stock: 4, Price: 5000},
+			wantTypes: []string{domain.NotifikasiTypeBackInStock},
+		},
+		{
+			name:      "price drop",
+			before:    domain.Produk{Stock: 3, Price: 5000},
+			after:     domain.Produk{Stock: 3, Price: 4000},
+			wantTypes: []string{domain.NotifikasiTypePriceDrop},
+		},
+		{
+			name:      "back in stock and cheaper",
+			before:    domain.Produk{Stock: 0, Price: 5000},
+			after:     domain.Produk{Stock: 4, Price: 4000},
+			wantTypes: []string{domain.NotifikasiTypeBackInStock, domain.NotifikasiTypePriceDrop},
+		},
+		{
+			name:      "muted back in stock keeps price drop",
+			before:    domain.Produk{Stock: 0, Price: 5000},
+			after:     domain.Produk{Stock: 4, Price: 4000},
+			pref:      domain.NotifikasiPreference{MuteBackInStock: true},
+			wantTypes: []string{domain.NotifikasiTypePriceDrop},
+		},
+		{
+			name:   "muted price drop",
+			before: domain.Produk{Stock: 3, Price: 5000},
+			after:  domain.Produk{Stock: 3, Price: 4000},
+			pref:   domain.NotifikasiPreference{MutePriceDrop: true},
+		},
+		{
+			name:   "restock of available produk",
+			before: domain.Produk{Stock: 3, Price: 5000},
+			after:  domain.Produk{Stock: 10, Price: 5000},
+		},
+		{
+			name:   "price increase",
+			before: domain.Produk{Stock: 3, Price: 5000},
+			after:  domain.Produk{Stock: 3, Price: 6000},
+		},
+		{
+			name:   "deleted produk",
+			before: domain.Produk{Stock: 0, Price: 5000},
+			after:  domain.Produk{Stock: 4, Price: 4000, DeletedAt: &deletedAt},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			user := &domain.User{ID: primitive.NewObjectID(), NotifikasiPreference: tt.pref}
+			user.NotifikasiPreference.MuteEmail = true
+			repo := &mockNotifikasiRepo{}
+			userRepo := &mockUserRepo{users: map[string]*domain.User{user.ID.Hex(): user}}
+			savedListRepo := &mockSavedListRepo{userIDs: []primitive.ObjectID{user.ID, primitive.NewObjectID()}}
+			nu := NewNotifikasiUsecase(repo, userRepo, savedListRepo, nil, time.Second)
+
+			tt.before.ID, tt.after.ID = produkID, produkID
+			err := nu.NotifyWishlist(context.Background(), &tt.before, &tt.after)
+			if err != nil {
+				t.Fatalf("err = %v", err)
+			}
+
+			if len(repo.inserted) != len(tt.wantTypes) {
+				t.Fatalf("inserted %d notifikasi, want %d", len(repo.inserted), len(tt.wantTypes))
+			}
+			for i, n := range repo.inserted {
+				if n.Type != tt.wantTypes[i] || n.UserID != user.ID || n.ReferenceID != produkID {
+					t.Errorf("notifikasi %d = %+v, want type %s for the wishlist owner", i, n, tt.wantTypes[i])
+				}
+			}
+		})
+	}
+}
+
+func TestNotifyUser(t *testing.T) {
+	user := &domain.User{ID: primitive.NewObjectID(), NotifikasiPreference: domain.NotifikasiPreference{MuteEmail: true}}
+	repo := &mockNotifikasiRepo{}
+	nu := NewNotifikasiUsecase(repo, &mockUserRepo{users: map[string]*domain.User{user.ID.Hex(): user}}, nil, nil, time.Second)
+
+	err := nu.NotifyUser(context.Background(), &domain.Notifikasi{UserID: user.ID, Title: "Pesanan siap"})
+	if err != nil {
+		t.Fatalf("err = %v", err)
+	}
+	if len(repo.inserted) != 1 || repo.inserted[0].ID.IsZero() || repo.inserted[0].CreatedAt.IsZero() {
+		t.Fatalf("inserted = %+v, want one notifikasi with id and created_at", repo.inserted)
+	}
+
+	err = nu.NotifyUser(context.Background(), &domain.Notifikasi{UserID: primitive.NewObjectID()})
+	if err == nil {
+		t.Fatal("unknown user should fail")
+	}
+	if len(repo.inserted) != 1 {
+		t.Fatalf("inserted %d notifikasi, want 1", len(repo.inserted))
+	}
+}
+
+func TestUpdatePreference(t *testing.T) {
+	mute := true
+	unmute := false
+	user := &domain.User{ID: primitive.NewObjectID(), NotifikasiPreference: domain.NotifikasiPreference{MutePriceDrop: true}}
+	userRepo := &mockUserRepo{users: map[string]*domain.User{user.ID.Hex(): user}}
+	nu := NewNotifikasiUsecase(&mockNotifikasiRepo{}, userRepo, nil, nil, time.Second)
+
+	res, err := nu.UpdatePreference(context.Background(), user.ID.Hex(), &dtos.NotifikasiPreferenceRequest{MuteBackInStock: &mute})
+	if err != nil {
+		t.Fatalf("err = %v", err)
+	}
+	want := dtos.NotifikasiPreferenceResponse{MuteBackInStock: true, MutePriceDrop: true}
+	if *res != want {
+		t.Fatalf("preference = %+v, want %+v (omitted fields unchanged)", *res, want)
+	}
+
+	res, err = nu.UpdatePreference(context.Background(), user.ID.Hex(), &dtos.NotifikasiPreferenceRequest{MutePriceDrop: &unmute, MuteEmail: &mute})
+	if err != nil {
+		t.Fatalf("err = %v", err)
+	}
+	want = dtos.NotifikasiPreferenceResponse{MuteBackInStock: true, MuteEmail: true}
+	if *res != want {
+		t.Fatalf("preference = %+v, want %+v", *res, want)
+	}
+
+	stored, _ := nu.GetPreference(context.Background(), user.ID.Hex())
+	if *stored != want {
+		t.Fatalf("stored preference = %+v, want %+v", *stored, want)
+	}
+
+	if _, err := nu.UpdatePreference(context.Background(), primitive.NewObjectID().Hex(), &dtos.NotifikasiPreferenceRequest{}); err == nil {
+		t.Fatal("unknown user should fail")
+	}
+}
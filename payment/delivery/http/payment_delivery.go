@@ -0,0 +1,315 @@
+package http
+
+import (
+	"io"
+	"math"
+	"net/http"
+	"warunk-bem/domain"
+	"warunk-bem/dtos"
+	"warunk-bem/helpers"
+	"warunk-bem/middlewares"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+)
+
+// maxWebhookBody membatasi ukuran body webhook yang dibaca
+const maxWebhookBody = 1 << 20
+
+type PaymentHandler struct {
+	PaymentUsecase domain.PaymentUsecase
+}
+
+// NewPaymentHandler mendaftarkan endpoint simulasi untuk admin hanya jika simulate aktif
+// (provider fake dan APP_ENV development)
+func NewPaymentHandler(router *gin.RouterGroup, protected *gin.RouterGroup, protectedAdmin *gin.RouterGroup, pu domain.PaymentUsecase, simulate bool) {
+	handler := &PaymentHandler{
+		PaymentUsecase: pu,
+	}
+
+	router.POST("/payment/webhook", handler.Webhook)
+
+	protected = protected.Group("/payment/topup")
+	protected.POST("", handler.CreateTopUp)
+	protected.GET("", handler.GetAllWithPage)
+	protected.GET("/:id", handler.FindOne)
+
+	protectedAdmin.POST("/payment/expire", handler.ExpirePending)
+	if simulate {
+		protectedAdmin.POST("/payment/topup/:id/simulate", handler.Simulate)
+	}
+}
+
+func isRequestValid(m interface{}) (bool, error) {
+	validate := validator.New()
+	err := validate.Struct(m)
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func unauthorized(c *gin.Context, err error) {
+	c.JSON(
+		http.StatusUnauthorized,
+		dtos.NewErrorResponse(
+			http.StatusUnauthorized,
+			"Please login first to access this pages",
+			dtos.GetErrorData(err),
+		),
+	)
+}
+
+func (ph *PaymentHandler) CreateTopUp(c *gin.Context) {
+	var req dtos.PaymentTopUpRequest
+
+	idUser, err := middlewares.IsUser(c)
+	if err != nil {
+		unauthorized(c, err)
+		return
+	}
+
+	err = c.ShouldBindJSON(&req)
+	if err != nil {
+		c.JSON(
+			http.StatusUnprocessableEntity,
+			dtos.NewErrorResponse(
+				http.StatusUnprocessableEntity,
+				"Filed Cannot Be Empty",
+				dtos.GetErrorData(err),
+			),
+		)
+		return
+	}
+
+	if ok, err := isRequestValid(&req); !ok {
+		c.JSON(
+			http.StatusBadRequest,
+			dtos.NewErrorResponse(
+				http.StatusBadRequest,
+				"Bad Request",
+				dtos.GetErrorData(err),
+			),
+		)
+		return
+	}
+
+	result, err := ph.PaymentUsecase.CreateTopUp(helpers.RequestContext(c), idUser, &req)
+	if err != nil {
+		c.JSON(
+			http.StatusBadRequest,
+			dtos.NewErrorResponse(
+				http.StatusBadRequest,
+				"Cannot Create Payment",
+				err.Error(),
+			),
+		)
+		return
+	}
+
+	c.JSON(
+		http.StatusCreated,
+		dtos.NewResponse(
+			http.StatusCreated,
+			"Success Create Payment",
+			result,
+		),
+	)
+}
+
+func (ph *PaymentHandler) GetAllWithPage(c *gin.Context) {
+	idUser, err := middlewares.IsUser(c)
+	if err != nil {
+		unauthorized(c, err)
+		return
+	}
+
+	rp, page := helpers.Pagination(c)
+
+	res, count, err := ph.PaymentUsecase.GetAllWithPage(helpers.RequestContext(c), idUser, rp, page)
+	if err != nil {
+		c.JSON(
+			http.StatusBadRequest,
+			dtos.NewErrorResponse(
+				http.StatusBadRequest,
+				"Cannot Get Payment",
+				err.Error(),
+			),
+		)
+		return
+	}
+
+	result := dtos.GetAllPaymentResponse{
+		Total:       count,
+		PerPage:     rp,
+		CurrentPage: page,
+		LastPage:    int64(math.Ceil(float64(count) / float64(rp))),
+		From:        (page * rp) - rp + 1,
+		To:          page * rp,
+		Payment:     res,
+	}
+
+	c.JSON(
+		http.StatusOK,
+		dtos.NewResponse(
+			http.StatusOK,
+			"Success Get Payment",
+			result,
+		),
+	)
+}
+
+func (ph *PaymentHandler) FindOne(c *gin.Context) {
+	idUser, err := middlewares.IsUser(c)
+	if err != nil {
+		unauthorized(c, err)
+		return
+	}
+
+	result, err := ph.PaymentUsecase.FindOne(helpers.RequestContext(c), c.Param("id"), idUser)
+	if err != nil {
+		c.JSON(
+			http.StatusNotFound,
+			dtos.NewErrorResponse(
+				http.StatusNotFound,
+				"Cannot Find Payment",
+				err.Error(),
+			),
+		)
+		return
+	}
+
+	c.JSON(
+		http.StatusOK,
+		dtos.NewResponse(
+			http.StatusOK,
+			"Success Find Payment",
+			result,
+		),
+	)
+}
+
+// Webhook membaca body mentah karena tanda tangan dihitung dari byte yang dikirim provider
+func (ph *PaymentHandler) Webhook(c *gin.Context) {
+	body, err := io.ReadAll(io.LimitReader(c.Request.Body, maxWebhookBody))
+	if err != nil {
+		c.JSON(
+			http.StatusBadRequest,
+			dtos.NewErrorResponse(
+				http.StatusBadRequest,
+				"Cannot Read Webhook",
+				err.Error(),
+			),
+		)
+		return
+	}
+
+	err = ph.PaymentUsecase.HandleWebhook(helpers.RequestContext(c), c.Request.Header, body)
+	if err != nil {
+		c.JSON(
+			http.StatusBadRequest,
+			dtos.NewErrorResponse(
+				http.StatusBadRequest,
+				"Cannot Handle Webhook",
+				err.Error(),
+			),
+		)
+		return
+	}
+
+	c.JSON(
+		http.StatusOK,
+		dtos.NewResponseMessage(
+			http.StatusOK,
+			"Success Handle Webhook",
+		),
+	)
+}
+
+func (ph *PaymentHandler) Simulate(c *gin.Context) {
+	var req dtos.PaymentSimulateRequest
+
+	_, err := middlewares.IsAdmin(c)
+	if err != nil {
+		c.JSON(
+			http.StatusForbidden,
+			dtos.NewErrorResponse(
+				http.StatusForbidden,
+				"Only admin can simulate payment",
+				dtos.GetErrorData(err),
+			),
+		)
+		return
+	}
+
+	err = c.ShouldBindJSON(&req)
+	if err != nil {
+		c.JSON(
+			http.StatusUnprocessableEntity,
+			dtos.NewErrorResponse(
+				http.StatusUnprocessableEntity,
+				"Filed Cannot Be Empty",
+				dtos.GetErrorData(err),
+			),
+		)
+		return
+	}
+
+	if ok, err := isRequestValid(&req); !ok {
+		c.JSON(
+			http.StatusBadRequest,
+			dtos.NewErrorResponse(
+				http.StatusBadRequest,
+				"Bad Request",
+				dtos.GetErrorData(err),
+			),
+		)
+		return
+	}
+
+	result, err := ph.PaymentUsecase.Simulate(helpers.RequestContext(c), c.Param("id"), req.Status)
+	if err != nil {
+		c.JSON(
+			http.StatusBadRequest,
+			dtos.NewErrorResponse(
+				http.StatusBadRequest,
+				"Cannot Simulate Payment",
+				err.Error(),
+			),
+		)
+		return
+	}
+
+	c.JSON(
+		http.StatusOK,
+		dtos.NewResponse(
+			http.StatusOK,
+			"Success Simulate Payment",
+			result,
+		),
+	)
+}
+
+func (ph *PaymentHandler) ExpirePending(c *gin.Context) {
+	expired, err := ph.PaymentUsecase.ExpirePending(helpers.RequestContext(c))
+	if err != nil {
+		c.JSON(
+			http.StatusInternalServerError,
+			dtos.NewErrorResponse(
+				http.StatusInternalServerError,
+				"Cannot Expire Payment",
+				err.Error(),
+			),
+		)
+		return
+	}
+
+	c.JSON(
+		http.StatusOK,
+		dtos.NewResponse(
+			http.StatusOK,
+			"Success Expire Payment",
+			dtos.PaymentExpireResponse{Expired: expired},
+		),
+	)
+}
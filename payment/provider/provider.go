@@ -0,0 +1,23 @@
+package provider
+
+import (
+	"errors"
+	"fmt"
+	"warunk-bem/config"
+	"warunk-bem/domain"
+)
+
+// New memilih payment gateway berdasarkan PAYMENT_PROVIDER, secret webhook wajib diisi
+// karena webhook diterima tanpa login
+func New(cfg config.Payment) (domain.PaymentProvider, error) {
+	if cfg.WebhookSecret == "" {
+		return nil, errors.New("PAYMENT_WEBHOOK_SECRET is required when PAYMENT_PROVIDER is set")
+	}
+
+	switch cfg.Provider {
+	case config.PaymentProviderFake:
+		return NewFakeProvider(cfg.WebhookSecret), nil
+	default:
+		return nil, fmt.Errorf("unknown payment provider: %s", cfg.Provider)
+	}
+}
@@ -0,0 +1,140 @@
+package repository
+
+import (
+	"context"
+	"time"
+	"warunk-bem/domain"
+	"warunk-bem/mongo"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	mongodriver "go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type paymentRepository struct {
+	DB         mongo.Database
+	Collection mongo.Collection
+}
+
+const (
+	timeFormat     = "2006-01-02T15:04:05.999Z07:00" // reduce precision from RFC3339Nano as date format
+	collectionName = "payment"
+)
+
+func NewPaymentRepository(DB mongo.Database) domain.PaymentRepository {
+	return &paymentRepository{DB, DB.Collection(collectionName)}
+}
+
+func (r *paymentRepository) EnsureIndexes(ctx context.Context) error {
+	_, err := r.Collection.CreateIndexes(ctx, []mongodriver.IndexModel{
+		{
+			Keys: bson.D{{Key: "user_id", Value: 1}, {Key: "created_at", Value: -1}},
+		},
+		{
+			Keys: bson.D{{Key: "status", Value: 1}, {Key: "expires_at", Value: 1}},
+		},
+	})
+	return err
+}
+
+func (r *paymentRepository) InsertOne(ctx context.Context, req *domain.Payment) (*domain.Payment, error) {
+	_, err := r.Collection.InsertOne(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	return req, nil
+}
+
+func (r *paymentRepository) FindOne(ctx context.Context, id string) (*domain.Payment, error) {
+	var payment domain.Payment
+
+	idHex, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, err
+	}
+
+	err = r.Collection.FindOne(ctx, bson.M{"_id": idHex}).Decode(&payment)
+	if err != nil {
+		return nil, err
+	}
+
+	return &payment, nil
+}
+
+func (r *paymentRepository) GetAllWithPage(ctx context.Context, rp int64, p int64, filter interface{}, setsort interface{}) ([]domain.Payment, int64, error) {
+	var (
+		payment []domain.Payment
+		err     error
+	)
+
+	findOptions := options.Find()
+	findOptions.SetLimit(rp)
+	findOptions.SetSkip((p - 1) * rp)
+	if setsort != nil {
+		findOptions.SetSort(setsort)
+	}
+
+	cursor, err := r.Collection.Find(ctx, filter, findOptions)
+	if err != nil {
+		return payment, 0, err
+	}
+
+	err = cursor.All(ctx, &payment)
+	if err != nil {
+		return payment, 0, err
+	}
+
+	total, err := r.Collection.CountDocuments(ctx, filter)
+	if err != nil {
+		return payment, 0, err
+	}
+
+	return payment, total, nil
+}
+
+// UpdateStatus hanya berhasil jika status di database masih salah satu fromStatus,
+// webhook yang datang bersamaan hanya bisa memenangkan satu perubahan status
+func (r *paymentRepository) UpdateStatus(ctx context.Context, payment *domain.Payment, fromStatus ...string) error {
+	payment.UpdatedAt = time.Now()
+
+	update := bson.M{"$set": bson.M{
+		"updated_at":   payment.UpdatedAt,
+		"status":       payment.Status,
+		"provider_ref": payment.ProviderRef,
+		"paid_at":      payment.PaidAt,
+	}}
+
+	filter := bson.M{"_id": payment.ID, "status": bson.M{"$in": fromStatus}}
+
+	result, err := r.Collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return err
+	}
+
+	if result.MatchedCount == 0 {
+		return domain.ErrPaymentStatusChanged
+	}
+
+	return nil
+}
+
+// ExpirePending menandai semua pembayaran pending yang melewati batas waktu sebagai expired
+func (r *paymentRepository) ExpirePending(ctx context.Context, now time.Time) (int64, error) {
+	filter := bson.M{
+		"status":     domain.PaymentStatusPending,
+		"expires_at": bson.M{"$lte": now},
+	}
+	update := bson.M{"$set": bson.M{
+		"status":     domain.PaymentStatusExpired,
+		"updated_at": now,
+	}}
+
+	result, err := r.Collection.UpdateMany(ctx, filter, update)
+	if err != nil {
+		return 0, err
+	}
+
+	return result.ModifiedCount, nil
+}
@@ -0,0 +1,399 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+	"warunk-bem/cache"
+	"warunk-bem/domain"
+	"warunk-bem/dtos"
+	"warunk-bem/helpers"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type paymentUsecase struct {
+	PaymentRepo       domain.PaymentRepository
+	UserAmountRepo    domain.UserAmountRepository
+	NotifikasiUsecase domain.NotifikasiUsecase
+	Provider          domain.PaymentProvider
+	Cache             domain.Cache
+	expiry            time.Duration
+	minAmount         float64
+	contextTimeout    time.Duration
+}
+
+func NewPaymentUsecase(PaymentRepo domain.PaymentRepository, UserAmountRepo domain.UserAmountRepository, NotifikasiUsecase domain.NotifikasiUsecase, Provider domain.PaymentProvider, Cache domain.Cache, expiry time.Duration, minAmount float64, contextTimeout time.Duration) domain.PaymentUsecase {
+	return &paymentUsecase{
+		PaymentRepo:       PaymentRepo,
+		UserAmountRepo:    UserAmountRepo,
+		NotifikasiUsecase: NotifikasiUsecase,
+		Provider:          Provider,
+		Cache:             Cache,
+		expiry:            expiry,
+		minAmount:         minAmount,
+		contextTimeout:    contextTimeout,
+	}
+}
+
+func toPaymentResponse(payment *domain.Payment) *dtos.PaymentResponse {
+	return &dtos.PaymentResponse{
+		ID:        payment.ID.Hex(),
+		CreatedAt: payment.CreatedAt,
+		Provider:  payment.Provider,
+		Method:    payment.Method,
+		Amount:    payment.Amount,
+		Status:    payment.Status,
+		Instruction: dtos.PaymentInstructionResponse{
+			QRString: payment.Instruction.QRString,
+			Bank:     payment.Instruction.Bank,
+			VANumber: payment.Instruction.VANumber,
+		},
+		ExpiresAt: payment.ExpiresAt,
+		PaidAt:    payment.PaidAt,
+	}
+}
+
+// CreatePaymentTopUp godoc
+// @Summary      Create Online Top Up
+// @Description  Create a pending top up through the payment gateway and return QRIS or VA payment instructions
+// @Tags         User - Payment
+// @Accept       json
+// @Produce      json
+// @Param        request body dtos.PaymentTopUpRequest true "Payload Body [RAW]"
+// @Success      201 {object} dtos.PaymentOKResponse
+// @Failure      400 {object} dtos.BadRequestResponse
+// @Failure      401 {object} dtos.UnauthorizedResponse
+// @Failure      403 {object} dtos.ForbiddenResponse
+// @Failure      500 {object} dtos.InternalServerErrorResponse
+// @Router       /payment/topup [post]
+// @Security BearerAuth
+func (pu *paymentUsecase) CreateTopUp(c context.Context, userID string, req *dtos.PaymentTopUpRequest) (*dtos.PaymentResponse, error) {
+	ctx, cancel := context.WithTimeout(c, pu.contextTimeout)
+	defer cancel()
+
+	userHex, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return nil, errors.New("user tidak ditemukan")
+	}
+
+	if req.Amount < pu.minAmount {
+		return nil, fmt.Errorf("minimal top up Rp%s", helpers.FormatRupiah(pu.minAmount))
+	}
+
+	now := time.Now()
+	payment := &domain.Payment{
+		ID:        primitive.NewObjectID(),
+		CreatedAt: now,
+		UpdatedAt: now,
+		UserID:    userHex,
+		Provider:  pu.Provider.Name(),
+		Method:    req.Method,
+		Amount:    req.Amount,
+		Status:    domain.PaymentStatusPending,
+		ExpiresAt: now.Add(pu.expiry),
+	}
+
+	result, err := pu.Provider.CreateCharge(ctx, &domain.PaymentCharge{
+		OrderID:   payment.ID.Hex(),
+		Amount:    payment.Amount,
+		Method:    payment.Method,
+		Bank:      req.Bank,
+		ExpiresAt: payment.ExpiresAt,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	payment.ProviderRef = result.ProviderRef
+	payment.Instruction = result.Instruction
+
+	payment, err = pu.PaymentRepo.InsertOne(ctx, payment)
+	if err != nil {
+		return nil, errors.New("tidak dapat membuat pembayaran")
+	}
+
+	return toPaymentResponse(payment), nil
+}
+
+// GetPaymentTopUp godoc
+// @Summary      Get Online Top Up
+// @Description  Get one online top up of the logged in user
+// @Tags         User - Payment
+// @Accept       json
+// @Produce      json
+// @Param id path string true "ID Payment"
+// @Success      200 {object} dtos.PaymentOKResponse
+// @Failure      400 {object} dtos.BadRequestResponse
+// @Failure      401 {object} dtos.UnauthorizedResponse
+// @Failure      403 {object} dtos.ForbiddenResponse
+// @Failure      404 {object} dtos.NotFoundResponse
+// @Failure      500 {object} dtos.InternalServerErrorResponse
+// @Router       /payment/topup/{id} [get]
+// @Security BearerAuth
+func (pu *paymentUsecase) FindOne(c context.Context, id string, userID string) (*dtos.PaymentResponse, error) {
+	ctx, cancel := context.WithTimeout(c, pu.contextTimeout)
+	defer cancel()
+
+	payment, err := pu.findOwned(ctx, id, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	pu.expireIfDue(ctx, payment)
+
+	return toPaymentResponse(payment), nil
+}
+
+// GetAllPaymentTopUp godoc
+// @Summary      Get All Online Top Up
+// @Description  Get online top up history of the logged in user, newest first
+// @Tags         User - Payment
+// @Accept       json
+// @Produce      json
+// @Param        rp query int false "rp"
+// @Param        p query int false "p"
+// @Success      200 {object} dtos.PaymentsOKResponse
+// @Failure      400 {object} dtos.BadRequestResponse
+// @Failure      401 {object} dtos.UnauthorizedResponse
+// @Failure      403 {object} dtos.ForbiddenResponse
+// @Failure      500 {object} dtos.InternalServerErrorResponse
+// @Router       /payment/topup [get]
+// @Security BearerAuth
+func (pu *paymentUsecase) GetAllWithPage(c context.Context, userID string, rp int64, p int64) ([]*dtos.PaymentResponse, int64, error) {
+	res := []*dtos.PaymentResponse{}
+
+	ctx, cancel := context.WithTimeout(c, pu.contextTimeout)
+	defer cancel()
+
+	userHex, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return res, 0, err
+	}
+
+	payments, count, err := pu.PaymentRepo.GetAllWithPage(ctx, rp, p, bson.M{"user_id": userHex}, bson.M{"created_at": -1})
+	if err != nil {
+		return res, 0, err
+	}
+
+	for i := range payments {
+		pu.expireIfDue(ctx, &payments[i])
+		res = append(res, toPaymentResponse(&payments[i]))
+	}
+
+	return res, count, nil
+}
+
+// PaymentWebhook godoc
+// @Summary      Payment Webhook
+// @Description  Signed notification from the payment gateway, duplicate notifications are acknowledged without crediting saldo twice
+// @Tags         Payment
+// @Accept       json
+// @Produce      json
+// @Success      200 {object} dtos.StatusOKResponse
+// @Failure      400 {object} dtos.BadRequestResponse
+// @Failure      401 {object} dtos.UnauthorizedResponse
+// @Failure      500 {object} dtos.InternalServerErrorResponse
+// @Router       /payment/webhook [post]
+func (pu *paymentUsecase) HandleWebhook(c context.Context, header http.Header, body []byte) error {
+	ctx, cancel := context.WithTimeout(c, pu.contextTimeout)
+	defer cancel()
+
+	webhook, err := pu.Provider.ParseWebhook(header, body)
+	if err != nil {
+		return err
+	}
+
+	payment, err := pu.PaymentRepo.FindOne(ctx, webhook.OrderID)
+	if err != nil {
+		return errors.New("pembayaran tidak ditemukan")
+	}
+
+	if payment.Provider != pu.Provider.Name() {
+		return errors.New("provider pembayaran tidak sesuai")
+	}
+
+	switch webhook.Status {
+	case domain.PaymentStatusPaid:
+		if webhook.Amount != payment.Amount {
+			return errors.New("nominal pembayaran tidak sesuai")
+		}
+		return pu.markPaid(ctx, payment, webhook)
+	case domain.PaymentStatusExpired, domain.PaymentStatusFailed:
+		if payment.Status != domain.PaymentStatusPending {
+			return nil
+		}
+
+		payment.Status = webhook.Status
+		err = pu.PaymentRepo.UpdateStatus(ctx, payment, domain.PaymentStatusPending)
+		if err != nil && err != domain.ErrPaymentStatusChanged {
+			return err
+		}
+		return nil
+	default:
+		return fmt.Errorf("status pembayaran %s tidak dikenal", webhook.Status)
+	}
+}
+
+// markPaid menambah saldo tepat satu kali, webhook duplikat berhenti di perubahan status
+func (pu *paymentUsecase) markPaid(ctx context.Context, payment *domain.Payment, webhook *domain.PaymentWebhook) error {
+	if payment.Status == domain.PaymentStatusPaid {
+		log.Println("duplicate payment webhook: ", payment.ID.Hex())
+		return nil
+	}
+
+	// Uang sudah diterima gateway, pembayaran yang terlanjur expired tetap dikreditkan
+	previousStatus := payment.Status
+	now := time.Now()
+	payment.Status = domain.PaymentStatusPaid
+	payment.PaidAt = &now
+	if webhook.ProviderRef != "" {
+		payment.ProviderRef = webhook.ProviderRef
+	}
+
+	err := pu.PaymentRepo.UpdateStatus(ctx, payment, domain.PaymentStatusPending, domain.PaymentStatusExpired, domain.PaymentStatusFailed)
+	if err == domain.ErrPaymentStatusChanged {
+		log.Println("duplicate payment webhook: ", payment.ID.Hex())
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	_, err = pu.UserAmountRepo.IncrementAmount(ctx, payment.UserID.Hex(), payment.Amount)
+	if err != nil {
+		// Status dikembalikan agar gateway mengirim ulang webhook dan saldo dicoba lagi
+		payment.Status = previousStatus
+		payment.PaidAt = nil
+		if rollbackErr := pu.PaymentRepo.UpdateStatus(ctx, payment, domain.PaymentStatusPaid); rollbackErr != nil {
+			log.Println("cannot rollback payment status: ", rollbackErr.Error())
+		}
+		return errors.New("tidak dapat menambahkan saldo")
+	}
+
+	err = pu.UserAmountRepo.InsertTopUp(ctx, &domain.TopUp{
+		ID:          primitive.NewObjectID(),
+		CreatedAt:   now,
+		UserID:      payment.UserID,
+		Amount:      payment.Amount,
+		Source:      domain.TopUpSourceGateway,
+		ReferenceID: payment.ID,
+	})
+	if err != nil {
+		log.Println("cannot record top up: ", err.Error())
+	}
+
+	cache.InvalidateUser(ctx, pu.Cache, payment.UserID.Hex())
+
+	helpers.NotifyAsync(pu.NotifikasiUsecase, "payment", &domain.Notifikasi{
+		UserID:      payment.UserID,
+		Type:        domain.NotifikasiTypeTopUp,
+		Title:       "Top up berhasil",
+		Message:     fmt.Sprintf("Top up sebesar Rp%s sudah masuk ke saldo kamu", helpers.FormatRupiah(payment.Amount)),
+		ReferenceID: payment.ID,
+	})
+
+	return nil
+}
+
+// SimulatePayment godoc
+// @Summary      Simulate Payment
+// @Description  Only available with the fake provider when APP_ENV is development, sends a signed webhook for the payment so the flow can be tested offline
+// @Tags         Admin - Payment
+// @Accept       json
+// @Produce      json
+// @Param id path string true "ID Payment"
+// @Param        request body dtos.PaymentSimulateRequest true "Payload Body [RAW]"
+// @Success      200 {object} dtos.PaymentOKResponse
+// @Failure      400 {object} dtos.BadRequestResponse
+// @Failure      401 {object} dtos.UnauthorizedResponse
+// @Failure      403 {object} dtos.ForbiddenResponse
+// @Failure      404 {object} dtos.NotFoundResponse
+// @Failure      500 {object} dtos.InternalServerErrorResponse
+// @Router       /payment/topup/{id}/simulate [post]
+// @Security BearerAuth
+func (pu *paymentUsecase) Simulate(c context.Context, id string, status string) (*dtos.PaymentResponse, error) {
+	simulator, ok := pu.Provider.(domain.PaymentSimulator)
+	if !ok {
+		return nil, errors.New("simulasi hanya tersedia untuk provider fake")
+	}
+
+	payment, err := pu.PaymentRepo.FindOne(c, id)
+	if err != nil {
+		return nil, errors.New("pembayaran tidak ditemukan")
+	}
+
+	header, body, err := simulator.SignWebhook(&domain.PaymentWebhook{
+		OrderID:     payment.ID.Hex(),
+		ProviderRef: payment.ProviderRef,
+		Status:      status,
+		Amount:      payment.Amount,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	err = pu.HandleWebhook(c, header, body)
+	if err != nil {
+		return nil, err
+	}
+
+	payment, err = pu.PaymentRepo.FindOne(c, id)
+	if err != nil {
+		return nil, errors.New("pembayaran tidak ditemukan")
+	}
+
+	return toPaymentResponse(payment), nil
+}
+
+// ExpirePayment godoc
+// @Summary      Expire Payment
+// @Description  Mark every pending online top up past its deadline as expired, also run periodically in the background
+// @Tags         Admin - Payment
+// @Accept       json
+// @Produce      json
+// @Success      200 {object} dtos.PaymentExpireOKResponse
+// @Failure      401 {object} dtos.UnauthorizedResponse
+// @Failure      403 {object} dtos.ForbiddenResponse
+// @Failure      500 {object} dtos.InternalServerErrorResponse
+// @Router       /payment/expire [post]
+// @Security BearerAuth
+func (pu *paymentUsecase) ExpirePending(c context.Context) (int64, error) {
+	ctx, cancel := context.WithTimeout(c, pu.contextTimeout)
+	defer cancel()
+
+	return pu.PaymentRepo.ExpirePending(ctx, time.Now())
+}
+
+func (pu *paymentUsecase) findOwned(ctx context.Context, id string, userID string) (*domain.Payment, error) {
+	payment, err := pu.PaymentRepo.FindOne(ctx, id)
+	if err != nil || payment.UserID.Hex() != userID {
+		return nil, errors.New("pembayaran tidak ditemukan")
+	}
+
+	return payment, nil
+}
+
+// expireIfDue menandai pembayaran expired saat dibaca tanpa menunggu proses background
+func (pu *paymentUsecase) expireIfDue(ctx context.Context, payment *domain.Payment) {
+	if payment.Status != domain.PaymentStatusPending || time.Now().Before(payment.ExpiresAt) {
+		return
+	}
+
+	payment.Status = domain.PaymentStatusExpired
+	err := pu.PaymentRepo.UpdateStatus(ctx, payment, domain.PaymentStatusPending)
+	if err == domain.ErrPaymentStatusChanged {
+		// Webhook lebih dulu mengubah status, tampilkan status terbaru
+		if latest, findErr := pu.PaymentRepo.FindOne(ctx, payment.ID.Hex()); findErr == nil {
+			*payment = *latest
+		}
+		return
+	}
+	if err != nil {
+		log.Println("cannot expire payment: ", err.Error())
+	}
+}
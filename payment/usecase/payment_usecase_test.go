@@ -0,0 +1,245 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+	"warunk-bem/cache"
+	"warunk-bem/domain"
+	"warunk-bem/payment/provider"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// mockPaymentRepo menyimpan pembayaran di memori, UpdateStatus meniru filter status di repository
+type mockPaymentRepo struct {
+	domain.PaymentRepository
+
+	mu       sync.Mutex
+	payments map[string]domain.Payment
+}
+
+func (m *mockPaymentRepo) FindOne(ctx context.Context, id string) (*domain.Payment, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	payment, ok := m.payments[id]
+	if !ok {
+		return nil, errors.New("not found")
+	}
+	return &payment, nil
+}
+
+func (m *mockPaymentRepo) UpdateStatus(ctx context.Context, payment *domain.Payment, fromStatus ...string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	stored := m.payments[payment.ID.Hex()]
+	for _, status := range fromStatus {
+		if stored.Status == status {
+			m.payments[payment.ID.Hex()] = *payment
+			return nil
+		}
+	}
+	return domain.ErrPaymentStatusChanged
+}
+
+type mockUserAmountRepo struct {
+	domain.UserAmountRepository
+
+	mu        sync.Mutex
+	creditErr error
+	credited  float64
+	credits   int
+}
+
+func (m *mockUserAmountRepo) IncrementAmount(ctx context.Context, userID string, delta float64) (*domain.UserAmount, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.creditErr != nil {
+		return nil, m.creditErr
+	}
+	m.credited += delta
+	m.credits++
+	return &domain.UserAmount{Amount: m.credited}, nil
+}
+
+func (m *mockUserAmountRepo) InsertTopUp(ctx context.Context, req *domain.TopUp) error {
+	return nil
+}
+
+type webhookCall struct {
+	status string
+	amount float64
+	// tamper mengubah body setelah ditandatangani
+	tamper bool
+}
+
+func TestHandleWebhook(t *testing.T) {
+	fake := provider.NewFakeProvider("rahasia")
+
+	tests := []struct {
+		name        string
+		status      string
+		creditErr   error
+		calls       []webhookCall
+		wantErr     []bool
+		wantCredits int
+		wantStatus  string
+	}{
+		{
+			name:        "paid credits saldo",
+			status:      domain.PaymentStatusPending,
+			calls:       []webhookCall{{status: domain.PaymentStatusPaid, amount: 50000}},
+			wantErr:     []bool{false},
+			wantCredits: 1,
+			wantStatus:  domain.PaymentStatusPaid,
+		},
+		{
+			name:   "duplicate paid webhook credits once",
+			status: domain.PaymentStatusPending,
+			calls: []webhookCall{
+				{status: domain.PaymentStatusPaid, amount: 50000},
+				{status: domain.PaymentStatusPaid, amount: 50000},
+				{status: domain.PaymentStatusPaid, amount: 50000},
+			},
+			wantErr:     []bool{false, false, false},
+			wantCredits: 1,
+			wantStatus:  domain.PaymentStatusPaid,
+		},
+		{
+			name:        "late payment on expired top up is still credited",
+			status:      domain.PaymentStatusExpired,
+			calls:       []webhookCall{{status: domain.PaymentStatusPaid, amount: 50000}},
+			wantErr:     []bool{false},
+			wantCredits: 1,
+			wantStatus:  domain.PaymentStatusPaid,
+		},
+		{
+			name:   "failed after paid is ignored",
+			status: domain.PaymentStatusPending,
+			calls: []webhookCall{
+				{status: domain.PaymentStatusPaid, amount: 50000},
+				{status: domain.PaymentStatusFailed, amount: 50000},
+			},
+			wantErr:     []bool{false, false},
+			wantCredits: 1,
+			wantStatus:  domain.PaymentStatusPaid,
+		},
+		{
+			name:       "failed webhook does not credit",
+			status:     domain.PaymentStatusPending,
+			calls:      []webhookCall{{status: domain.PaymentStatusFailed, amount: 50000}},
+			wantErr:    []bool{false},
+			wantStatus: domain.PaymentStatusFailed,
+		},
+		{
+			name:       "amount mismatch",
+			status:     domain.PaymentStatusPending,
+			calls:      []webhookCall{{status: domain.PaymentStatusPaid, amount: 10000}},
+			wantErr:    []bool{true},
+			wantStatus: domain.PaymentStatusPending,
+		},
+		{
+			name:       "invalid signature",
+			status:     domain.PaymentStatusPending,
+			calls:      []webhookCall{{status: domain.PaymentStatusPaid, amount: 50000, tamper: true}},
+			wantErr:    []bool{true},
+			wantStatus: domain.PaymentStatusPending,
+		},
+		{
+			name:       "credit failure keeps payment retryable",
+			status:     domain.PaymentStatusPending,
+			creditErr:  errors.New("mongo down"),
+			calls:      []webhookCall{{status: domain.PaymentStatusPaid, amount: 50000}},
+			wantErr:    []bool{true},
+			wantStatus: domain.PaymentStatusPending,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			payment := domain.Payment{
+				ID:       primitive.NewObjectID(),
+				UserID:   primitive.NewObjectID(),
+				Provider: fake.Name(),
+				Amount:   50000,
+				Status:   tt.status,
+			}
+			paymentRepo := &mockPaymentRepo{payments: map[string]domain.Payment{payment.ID.Hex(): payment}}
+			userAmountRepo := &mockUserAmountRepo{creditErr: tt.creditErr}
+			pu := NewPaymentUsecase(paymentRepo, userAmountRepo, nil, fake, cache.NewMemoryCache(), time.Hour, 10000, time.Second)
+
+			for i, call := range tt.calls {
+				header, body := signWebhook(t, fake, &domain.PaymentWebhook{
+					OrderID: payment.ID.Hex(),
+					Status:  call.status,
+					Amount:  call.amount,
+				})
+				if call.tamper {
+					body = append(body, ' ')
+				}
+
+				err := pu.HandleWebhook(context.Background(), header, body)
+				if (err != nil) != tt.wantErr[i] {
+					t.Fatalf("call %d: err = %v, wantErr %v", i, err, tt.wantErr[i])
+				}
+			}
+
+			if userAmountRepo.credits != tt.wantCredits {
+				t.Errorf("credits = %d, want %d", userAmountRepo.credits, tt.wantCredits)
+			}
+			if got := paymentRepo.payments[payment.ID.Hex()].Status; got != tt.wantStatus {
+				t.Errorf("status = %s, want %s", got, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestHandleWebhookConcurrentDuplicates(t *testing.T) {
+	fake := provider.NewFakeProvider("rahasia")
+	payment := domain.Payment{
+		ID:       primitive.NewObjectID(),
+		UserID:   primitive.NewObjectID(),
+		Provider: fake.Name(),
+		Amount:   50000,
+		Status:   domain.PaymentStatusPending,
+	}
+	paymentRepo := &mockPaymentRepo{payments: map[string]domain.Payment{payment.ID.Hex(): payment}}
+	userAmountRepo := &mockUserAmountRepo{}
+	pu := NewPaymentUsecase(paymentRepo, userAmountRepo, nil, fake, cache.NewMemoryCache(), time.Hour, 10000, time.Second)
+
+	header, body := signWebhook(t, fake, &domain.PaymentWebhook{
+		OrderID: payment.ID.Hex(),
+		Status:  domain.PaymentStatusPaid,
+		Amount:  payment.Amount,
+	})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_ = pu.HandleWebhook(context.Background(), header, body)
+		}()
+	}
+	wg.Wait()
+
+	if userAmountRepo.credits != 1 || userAmountRepo.credited != payment.Amount {
+		t.Errorf("credits = %d (Rp%.0f), want exactly one credit of Rp%.0f", userAmountRepo.credits, userAmountRepo.credited, payment.Amount)
+	}
+}
+
+func signWebhook(t *testing.T, fake domain.PaymentProvider, webhook *domain.PaymentWebhook) (http.Header, []byte) {
+	t.Helper()
+
+	header, body, err := fake.(domain.PaymentSimulator).SignWebhook(webhook)
+	if err != nil {
+		t.Fatalf("cannot sign webhook: %v", err)
+	}
+	return header, body
+}
@@ -0,0 +1,170 @@
+package http
+
+import (
+	"math"
+	"net/http"
+	"warunk-bem/domain"
+	"warunk-bem/dtos"
+	"warunk-bem/helpers"
+	"warunk-bem/middlewares"
+
+	"github.com/gin-gonic/gin"
+)
+
+type PickupHandler struct {
+	PickupUsecase domain.PickupUsecase
+}
+
+func NewPickupHandler(protected *gin.RouterGroup, protectedAdmin *gin.RouterGroup, pu domain.PickupUsecase) {
+	handler := &PickupHandler{
+		PickupUsecase: pu,
+	}
+
+	protected.GET("/pickup", handler.GetMine)
+
+	protectedAdmin = protectedAdmin.Group("/pickup")
+	protectedAdmin.GET("/queue", handler.GetQueue)
+	protectedAdmin.PUT("/:code/picked-up", handler.MarkPickedUp)
+	protectedAdmin.POST("/refund", handler.RefundPending)
+}
+
+func unauthorized(c *gin.Context, err error) {
+	c.JSON(
+		http.StatusUnauthorized,
+		dtos.NewErrorResponse(
+			http.StatusUnauthorized,
+			"Please login first to access this pages",
+			dtos.GetErrorData(err),
+		),
+	)
+}
+
+func pickupPage(res []*dtos.PickupResponse, count int64, rp int64, page int64) dtos.GetAllPickupResponse {
+	return dtos.GetAllPickupResponse{
+		Total:       count,
+		PerPage:     rp,
+		CurrentPage: page,
+		LastPage:    int64(math.Ceil(float64(count) / float64(rp))),
+		From:        (page * rp) - rp + 1,
+		To:          page * rp,
+		Pickup:      res,
+	}
+}
+
+func (ph *PickupHandler) GetMine(c *gin.Context) {
+	idUser, err := middlewares.IsUser(c)
+	if err != nil {
+		unauthorized(c, err)
+		return
+	}
+
+	rp, page := helpers.Pagination(c)
+
+	res, count, err := ph.PickupUsecase.GetMine(helpers.RequestContext(c), idUser, rp, page)
+	if err != nil {
+		c.JSON(
+			http.StatusBadRequest,
+			dtos.NewErrorResponse(
+				http.StatusBadRequest,
+				"Cannot Get Pickup",
+				err.Error(),
+			),
+		)
+		return
+	}
+
+	c.JSON(
+		http.StatusOK,
+		dtos.NewResponse(
+			http.StatusOK,
+			"Success Get Pickup",
+			pickupPage(res, count, rp, page),
+		),
+	)
+}
+
+func (ph *PickupHandler) GetQueue(c *gin.Context) {
+	rp, page := helpers.Pagination(c)
+
+	res, count, err := ph.PickupUsecase.GetQueue(helpers.RequestContext(c), rp, page)
+	if err != nil {
+		c.JSON(
+			http.StatusBadRequest,
+			dtos.NewErrorResponse(
+				http.StatusBadRequest,
+				"Cannot Get Pickup Queue",
+				err.Error(),
+			),
+		)
+		return
+	}
+
+	c.JSON(
+		http.StatusOK,
+		dtos.NewResponse(
+			http.StatusOK,
+			"Success Get Pickup Queue",
+			pickupPage(res, count, rp, page),
+		),
+	)
+}
+
+func (ph *PickupHandler) MarkPickedUp(c *gin.Context) {
+	idAdmin, err := middlewares.IsAdmin(c)
+	if err != nil {
+		unauthorized(c, err)
+		return
+	}
+
+	result, err := ph.PickupUsecase.MarkPickedUp(helpers.RequestContext(c), c.Param("code"), idAdmin)
+	if err != nil {
+		c.JSON(
+			http.StatusBadRequest,
+			dtos.NewErrorResponse(
+				http.StatusBadRequest,
+				"Cannot Mark Pickup",
+				err.Error(),
+			),
+		)
+		return
+	}
+
+	c.JSON(
+		http.StatusOK,
+		dtos.NewResponse(
+			http.StatusOK,
+			"Success Mark Pickup",
+			result,
+		),
+	)
+}
+
+func (ph *PickupHandler) RefundPending(c *gin.Context) {
+	idAdmin, err := middlewares.IsAdmin(c)
+	if err != nil {
+		unauthorized(c, err)
+		return
+	}
+
+	refunded, err := ph.PickupUsecase.RefundPending(helpers.RequestContext(c), idAdmin)
+	if err != nil {
+		c.JSON(
+			http.StatusInternalServerError,
+			dtos.NewErrorResponse(
+				http.StatusInternalServerError,
+				"Cannot Refund Pickup",
+				err.Error(),
+			),
+		)
+		return
+	}
+
+	c.JSON(
+		http.StatusOK,
+		dtos.NewResponse(
+			http.StatusOK,
+			"Success Refund Pickup",
+			dtos.PickupRefundResponse{Refunded: refunded},
+		),
+	)
+}
@@ -0,0 +1,147 @@
+package repository
+
+import (
+	"context"
+	"time"
+	"warunk-bem/domain"
+	"warunk-bem/mongo"
+
+	"go.mongodb.org/mongo-driver/bson"
+	mongodriver "go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type pickupRepository struct {
+	DB         mongo.Database
+	Collection mongo.Collection
+}
+
+const (
+	timeFormat     = "2006-01-02T15:04:05.999Z07:00" // reduce precision from RFC3339Nano as date format
+	collectionName = "pickup"
+)
+
+func NewPickupRepository(DB mongo.Database) domain.PickupRepository {
+	return &pickupRepository{DB, DB.Collection(collectionName)}
+}
+
+// EnsureIndexes kode hanya unik di antara tiket pending, kode tiket lama boleh dipakai ulang
+func (r *pickupRepository) EnsureIndexes(ctx context.Context) error {
+	_, err := r.Collection.CreateIndexes(ctx, []mongodriver.IndexModel{
+		{
+			Keys: bson.D{{Key: "code", Value: 1}},
+			Options: options.Index().SetUnique(true).SetPartialFilterExpression(bson.M{
+				"status": domain.PickupStatusPending,
+			}),
+		},
+		{
+			Keys: bson.D{{Key: "status", Value: 1}, {Key: "created_at", Value: 1}},
+		},
+		{
+			Keys: bson.D{{Key: "user_id", Value: 1}, {Key: "created_at", Value: -1}},
+		},
+	})
+	return err
+}
+
+func (r *pickupRepository) InsertOne(ctx context.Context, req *domain.Pickup) (*domain.Pickup, error) {
+	_, err := r.Collection.InsertOne(ctx, req)
+	if mongodriver.IsDuplicateKeyError(err) {
+		return nil, domain.ErrPickupCodeTaken
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return req, nil
+}
+
+func (r *pickupRepository) FindPendingByCode(ctx context.Context, code string) (*domain.Pickup, error) {
+	var pickup domain.Pickup
+
+	err := r.Collection.FindOne(ctx, bson.M{"code": code, "status": domain.PickupStatusPending}).Decode(&pickup)
+	if err != nil {
+		return nil, err
+	}
+
+	return &pickup, nil
+}
+
+func (r *pickupRepository) FindAllByStatus(ctx context.Context, status string) ([]domain.Pickup, error) {
+	var pickups []domain.Pickup
+
+	cursor, err := r.Collection.Find(ctx, bson.M{"status": status}, options.Find().SetSort(bson.M{"created_at": 1}))
+	if err != nil {
+		return pickups, err
+	}
+
+	err = cursor.All(ctx, &pickups)
+	if err != nil {
+		return pickups, err
+	}
+
+	return pickups, nil
+}
+
+func (r *pickupRepository) GetAllWithPage(ctx context.Context, rp int64, p int64, filter interface{}, setsort interface{}) ([]domain.Pickup, int64, error) {
+	var (
+		pickup []domain.Pickup
+		err    error
+	)
+
+	findOptions := options.Find()
+	findOptions.SetLimit(rp)
+	findOptions.SetSkip((p - 1) * rp)
+	if setsort != nil {
+		findOptions.SetSort(setsort)
+	}
+
+	cursor, err := r.Collection.Find(ctx, filter, findOptions)
+	if err != nil {
+		return pickup, 0, err
+	}
+
+	err = cursor.All(ctx, &pickup)
+	if err != nil {
+		return pickup, 0, err
+	}
+
+	total, err := r.Collection.CountDocuments(ctx, filter)
+	if err != nil {
+		return pickup, 0, err
+	}
+
+	return pickup, total, nil
+}
+
+// UpdateStatus hanya berhasil jika status di database masih fromStatus,
+// sehingga tiket tidak bisa diserahkan dan direfund sekaligus
+func (r *pickupRepository) UpdateStatus(ctx context.Context, pickup *domain.Pickup, fromStatus string) error {
+	pickup.UpdatedAt = time.Now()
+
+	set := bson.M{
+		"updated_at": pickup.UpdatedAt,
+		"status":     pickup.Status,
+	}
+	if pickup.PickedUpAt != nil {
+		set["picked_up_at"] = pickup.PickedUpAt
+		set["picked_up_by"] = pickup.PickedUpBy
+	}
+	update := bson.M{"$set": set}
+	if pickup.RefundedAt != nil {
+		set["refunded_at"] = pickup.RefundedAt
+	} else {
+		update["$unset"] = bson.M{"refunded_at": ""}
+	}
+
+	result, err := r.Collection.UpdateOne(ctx, bson.M{"_id": pickup.ID, "status": fromStatus}, update)
+	if err != nil {
+		return err
+	}
+
+	if result.MatchedCount == 0 {
+		return domain.ErrPickupStatusChanged
+	}
+
+	return nil
+}
@@ -0,0 +1,358 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math/rand"
+	"strings"
+	"time"
+	"warunk-bem/cache"
+	"warunk-bem/domain"
+	"warunk-bem/dtos"
+	"warunk-bem/helpers"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	// pickupCodeAlphabet tanpa huruf dan angka yang mirip (0/O, 1/I) agar mudah dibacakan ke staff
+	pickupCodeAlphabet = "ABCDEFGHJKLMNPQRSTUVWXYZ23456789"
+	pickupCodeLength   = 6
+	pickupCodeAttempts = 5
+)
+
+type pickupUsecase struct {
+	PickupRepo        domain.PickupRepository
+	TransaksiRepo     domain.TransaksiRepository
+	ProdukRepo        domain.ProdukRepository
+	StokRepo          domain.StokRepository
+	UserAmountRepo    domain.UserAmountRepository
+	PromoUsecase      domain.PromoUsecase
+	NotifikasiUsecase domain.NotifikasiUsecase
+	Cache             domain.Cache
+	contextTimeout    time.Duration
+}
+
+func NewPickupUsecase(PickupRepo domain.PickupRepository, TransaksiRepo domain.TransaksiRepository, ProdukRepo domain.ProdukRepository, StokRepo domain.StokRepository, UserAmountRepo domain.UserAmountRepository, PromoUsecase domain.PromoUsecase, NotifikasiUsecase domain.NotifikasiUsecase, Cache domain.Cache, contextTimeout time.Duration) domain.PickupUsecase {
+	return &pickupUsecase{
+		PickupRepo:        PickupRepo,
+		TransaksiRepo:     TransaksiRepo,
+		ProdukRepo:        ProdukRepo,
+		StokRepo:          StokRepo,
+		UserAmountRepo:    UserAmountRepo,
+		PromoUsecase:      PromoUsecase,
+		NotifikasiUsecase: NotifikasiUsecase,
+		Cache:             Cache,
+		contextTimeout:    contextTimeout,
+	}
+}
+
+func toPickupResponse(pickup *domain.Pickup) *dtos.PickupResponse {
+	res := &dtos.PickupResponse{
+		ID:            pickup.ID.Hex(),
+		CreatedAt:     pickup.CreatedAt,
+		OrderID:       pickup.OrderID.Hex(),
+		ReceiptNumber: pickup.ReceiptNumber,
+		UserName:      pickup.UserName,
+		Code:          pickup.Code,
+		QRString:      domain.PickupQRPrefix + pickup.Code,
+		Items:         make([]dtos.PickupItemResponse, 0, len(pickup.Items)),
+		TotalBayar:    pickup.TotalBayar,
+		Status:        pickup.Status,
+		PickedUpAt:    pickup.PickedUpAt,
+		RefundedAt:    pickup.RefundedAt,
+	}
+
+	for _, item := range pickup.Items {
+		res.Items = append(res.Items, dtos.PickupItemResponse{
+			ProdukName: item.ProdukName,
+			Qty:        item.Qty,
+		})
+	}
+
+	return res
+}
+
+func newPickupCode() string {
+	code := make([]byte, pickupCodeLength)
+	for i := range code {
+		code[i] = pickupCodeAlphabet[rand.Intn(len(pickupCodeAlphabet))]
+	}
+	return string(code)
+}
+
+func (pu *pickupUsecase) Create(c context.Context, pickup *domain.Pickup) (*domain.Pickup, error) {
+	ctx, cancel := context.WithTimeout(c, pu.contextTimeout)
+	defer cancel()
+
+	now := time.Now()
+	pickup.CreatedAt = now
+	pickup.UpdatedAt = now
+	pickup.Status = domain.PickupStatusPending
+
+	// Kode acak bisa bentrok dengan tiket pending lain, coba lagi dengan kode baru
+	for i := 0; i < pickupCodeAttempts; i++ {
+		pickup.ID = primitive.NewObjectID()
+		pickup.Code = newPickupCode()
+
+		res, err := pu.PickupRepo.InsertOne(ctx, pickup)
+		if errors.Is(err, domain.ErrPickupCodeTaken) {
+			continue
+		}
+
+		return res, err
+	}
+
+	return nil, errors.New("cannot generate pickup code")
+}
+
+// GetMyPickup godoc
+// @Summary      Get My Pickup
+// @Description  Get pickup tickets of the logged in user, pending tickets first
+// @Tags         User - Pickup
+// @Accept       json
+// @Produce      json
+// @Param        rp query int false "rp"
+// @Param        p query int false "p"
+// @Success      200 {object} dtos.PickupsOKResponse
+// @Failure      400 {object} dtos.BadRequestResponse
+// @Failure      401 {object} dtos.UnauthorizedResponse
+// @Failure      403 {object} dtos.ForbiddenResponse
+// @Failure      500 {object} dtos.InternalServerErrorResponse
+// @Router       /pickup [get]
+// @Security BearerAuth
+func (pu *pickupUsecase) GetMine(c context.Context, userID string, rp int64, p int64) ([]*dtos.PickupResponse, int64, error) {
+	res := []*dtos.PickupResponse{}
+
+	ctx, cancel := context.WithTimeout(c, pu.contextTimeout)
+	defer cancel()
+
+	userHex, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return res, 0, err
+	}
+
+	// "pending" lebih dulu dari "picked_up" dan "refunded" secara alfabet
+	sort := bson.D{{Key: "status", Value: 1}, {Key: "created_at", Value: -1}}
+
+	pickups, count, err := pu.PickupRepo.GetAllWithPage(ctx, rp, p, bson.M{"user_id": userHex}, sort)
+	if err != nil {
+		return res, 0, err
+	}
+
+	for i := range pickups {
+		res = append(res, toPickupResponse(&pickups[i]))
+	}
+
+	return res, count, nil
+}
+
+// GetPickupQueue godoc
+// @Summary      Get Pickup Queue
+// @Description  Get pending pickup tickets for the warunk counter, oldest first
+// @Tags         Admin - Pickup
+// @Accept       json
+// @Produce      json
+// @Param        rp query int false "rp"
+// @Param        p query int false "p"
+// @Success      200 {object} dtos.PickupsOKResponse
+// @Failure      400 {object} dtos.BadRequestResponse
+// @Failure      401 {object} dtos.UnauthorizedResponse
+// @Failure      403 {object} dtos.ForbiddenResponse
+// @Failure      500 {object} dtos.InternalServerErrorResponse
+// @Router       /pickup/queue [get]
+// @Security BearerAuth
+func (pu *pickupUsecase) GetQueue(c context.Context, rp int64, p int64) ([]*dtos.PickupResponse, int64, error) {
+	res := []*dtos.PickupResponse{}
+
+	ctx, cancel := context.WithTimeout(c, pu.contextTimeout)
+	defer cancel()
+
+	pickups, count, err := pu.PickupRepo.GetAllWithPage(ctx, rp, p, bson.M{"status": domain.PickupStatusPending}, bson.M{"created_at": 1})
+	if err != nil {
+		return res, 0, err
+	}
+
+	for i := range pickups {
+		res = append(res, toPickupResponse(&pickups[i]))
+	}
+
+	return res, count, nil
+}
+
+// MarkPickedUp godoc
+// @Summary      Mark Pickup As Picked Up
+// @Description  Hand over the order for a pending pickup code. The code may also be the scanned QR string
+// @Tags         Admin - Pickup
+// @Accept       json
+// @Produce      json
+// @Param        code path string true "pickup code"
+// @Success      200 {object} dtos.PickupOKResponse
+// @Failure      400 {object} dtos.BadRequestResponse
+// @Failure      401 {object} dtos.UnauthorizedResponse
+// @Failure      403 {object} dtos.ForbiddenResponse
+// @Failure      404 {object} dtos.NotFoundResponse
+// @Failure      500 {object} dtos.InternalServerErrorResponse
+// @Router       /pickup/{code}/picked-up [put]
+// @Security BearerAuth
+func (pu *pickupUsecase) MarkPickedUp(c context.Context, code string, staffID string) (*dtos.PickupResponse, error) {
+	ctx, cancel := context.WithTimeout(c, pu.contextTimeout)
+	defer cancel()
+
+	staffHex, err := primitive.ObjectIDFromHex(staffID)
+	if err != nil {
+		return nil, err
+	}
+
+	code = strings.ToUpper(strings.TrimPrefix(strings.TrimSpace(code), domain.PickupQRPrefix))
+
+	pickup, err := pu.PickupRepo.FindPendingByCode(ctx, code)
+	if err != nil {
+		return nil, errors.New("kode pickup tidak ditemukan atau sudah diambil")
+	}
+
+	now := time.Now()
+	pickup.Status = domain.PickupStatusPickedUp
+	pickup.PickedUpAt = &now
+	pickup.PickedUpBy = staffHex
+
+	err = pu.PickupRepo.UpdateStatus(ctx, pickup, domain.PickupStatusPending)
+	if err != nil {
+		return nil, err
+	}
+
+	return toPickupResponse(pickup), nil
+}
+
+// RefundPickup godoc
+// @Summary      Refund Pending Pickup
+// @Description  Refund saldo and restore stock of every pending pickup. Runs automatically when the warunk is closed
+// @Tags         Admin - Pickup
+// @Accept       json
+// @Produce      json
+// @Success      200 {object} dtos.PickupRefundOKResponse
+// @Failure      401 {object} dtos.UnauthorizedResponse
+// @Failure      403 {object} dtos.ForbiddenResponse
+// @Failure      500 {object} dtos.InternalServerErrorResponse
+// @Router       /pickup/refund [post]
+// @Security BearerAuth
+func (pu *pickupUsecase) RefundPending(c context.Context, actorID string) (int64, error) {
+	actorHex, err := primitive.ObjectIDFromHex(actorID)
+	if err != nil {
+		return 0, err
+	}
+
+	ctx, cancel := context.WithTimeout(c, pu.contextTimeout)
+	pickups, err := pu.PickupRepo.FindAllByStatus(ctx, domain.PickupStatusPending)
+	cancel()
+	if err != nil {
+		return 0, err
+	}
+
+	// Setiap tiket mendapat timeout sendiri agar antrean panjang tidak terpotong di tengah jalan
+	var refunded int64
+	for i := range pickups {
+		ctx, cancel := context.WithTimeout(c, pu.contextTimeout)
+		err = pu.refund(ctx, &pickups[i], actorHex)
+		cancel()
+		if errors.Is(err, domain.ErrPickupStatusChanged) {
+			continue
+		}
+		if err != nil {
+			log.Println("cannot refund pickup "+pickups[i].Code+": ", err.Error())
+			continue
+		}
+
+		refunded++
+	}
+
+	if refunded > 0 {
+		cache.InvalidateProduk(c, pu.Cache)
+	}
+
+	return refunded, nil
+}
+
+// refund mengunci tiket lebih dulu lalu mengembalikan saldo, status dikembalikan ke pending jika saldo gagal dikembalikan
+func (pu *pickupUsecase) refund(ctx context.Context, pickup *domain.Pickup, actorID primitive.ObjectID) error {
+	now := time.Now()
+	pickup.Status = domain.PickupStatusRefunded
+	pickup.RefundedAt = &now
+
+	err := pu.PickupRepo.UpdateStatus(ctx, pickup, domain.PickupStatusPending)
+	if err != nil {
+		return err
+	}
+
+	_, err = pu.UserAmountRepo.IncrementAmount(ctx, pickup.UserID.Hex(), float64(pickup.TotalBayar))
+	if err != nil {
+		pickup.Status = domain.PickupStatusPending
+		pickup.RefundedAt = nil
+		if rollbackErr := pu.PickupRepo.UpdateStatus(ctx, pickup, domain.PickupStatusRefunded); rollbackErr != nil {
+			log.Println("cannot rollback pickup status: ", rollbackErr.Error())
+		}
+		return err
+	}
+
+	// Barang yang tidak diambil masih ada di warunk, stoknya dikembalikan
+	for _, item := range pickup.Items {
+		produk, err := pu.ProdukRepo.IncrementStock(ctx, item.ProdukID.Hex(), item.Qty)
+		if err != nil {
+			log.Println("cannot restore stok produk: ", err.Error())
+			continue
+		}
+
+		movement := domain.NewStokMovement(produk, domain.StokMovementRefund, produk.Stock-item.Qty, "pickup tidak diambil", actorID, pickup.ID)
+		_, err = pu.StokRepo.InsertOne(ctx, movement)
+		if err != nil {
+			log.Println("cannot record stok movement: ", err.Error())
+		}
+	}
+
+	err = pu.TransaksiRepo.UpdateStatusByOrder(ctx, pickup.OrderID, domain.TransaksiStatusRefunded)
+	if err != nil {
+		log.Println("cannot update transaksi status: ", err.Error())
+	}
+
+	pu.revertPromo(ctx, pickup.OrderID)
+
+	cache.InvalidateUser(ctx, pu.Cache, pickup.UserID.Hex())
+
+	helpers.NotifyAsync(pu.NotifikasiUsecase, "pickup", &domain.Notifikasi{
+		UserID:      pickup.UserID,
+		Type:        domain.NotifikasiTypePickup,
+		Title:       "Pesanan " + pickup.ReceiptNumber + " dikembalikan",
+		Message:     fmt.Sprintf("Pesanan dengan kode %s tidak diambil sampai warunk tutup. Saldo Rp%s sudah dikembalikan.", pickup.Code, helpers.FormatRupiah(float64(pickup.TotalBayar))),
+		ReferenceID: pickup.ID,
+	})
+
+	return nil
+}
+
+// revertPromo mengembalikan kuota promo pesanan yang dikembalikan, kegagalan hanya dicatat karena saldo sudah dikembalikan
+func (pu *pickupUsecase) revertPromo(ctx context.Context, orderID primitive.ObjectID) {
+	transaksis, err := pu.TransaksiRepo.FindByOrder(ctx, orderID.Hex())
+	if err != nil {
+		log.Println("cannot find transaksi of order: ", err.Error())
+		return
+	}
+
+	transaksiIDs := make([]primitive.ObjectID, 0, len(transaksis))
+	for _, transaksi := range transaksis {
+		if transaksi.PromoCode != "" {
+			transaksiIDs = append(transaksiIDs, transaksi.ID)
+		}
+	}
+
+	if len(transaksiIDs) == 0 {
+		return
+	}
+
+	err = pu.PromoUsecase.RevertUsage(ctx, transaksiIDs)
+	if err != nil {
+		log.Println("cannot revert promo usage: ", err.Error())
+	}
+}
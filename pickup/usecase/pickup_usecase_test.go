@@ -0,0 +1,227 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+	"warunk-bem/cache"
+	"warunk-bem/domain"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// mockPickupRepo meniru update bersyarat pada status dan unique index kode pada tiket pending
+type mockPickupRepo struct {
+	domain.PickupRepository
+
+	pickups   map[primitive.ObjectID]*domain.Pickup
+	takenCode int
+}
+
+func (m *mockPickupRepo) InsertOne(ctx context.Context, req *domain.Pickup) (*domain.Pickup, error) {
+	if m.takenCode > 0 {
+		m.takenCode--
+		return nil, domain.ErrPickupCodeTaken
+	}
+	copied := *req
+	m.pickups[req.ID] = &copied
+	return req, nil
+}
+
+func (m *mockPickupRepo) FindPendingByCode(ctx context.Context, code string) (*domain.Pickup, error) {
+	for _, p := range m.pickups {
+		if p.Code == code && p.Status == domain.PickupStatusPending {
+			copied := *p
+			return &copied, nil
+		}
+	}
+	return nil, errors.New("not found")
+}
+
+func (m *mockPickupRepo) FindAllByStatus(ctx context.Context, status string) ([]domain.Pickup, error) {
+	res := []domain.Pickup{}
+	for _, p := range m.pickups {
+		if p.Status == status {
+			res = append(res, *p)
+		}
+	}
+	return res, nil
+}
+
+func (m *mockPickupRepo) UpdateStatus(ctx context.Context, pickup *domain.Pickup, fromStatus string) error {
+	stored, ok := m.pickups[pickup.ID]
+	if !ok || stored.Status != fromStatus {
+		return domain.ErrPickupStatusChanged
+	}
+	copied := *pickup
+	m.pickups[pickup.ID] = &copied
+	return nil
+}
+
+type mockTransaksiRepo struct {
+	domain.TransaksiRepository
+
+	status map[primitive.ObjectID]string
+}
+
+func (m *mockTransaksiRepo) UpdateStatusByOrder(ctx context.Context, orderID primitive.ObjectID, status string) error {
+	m.status[orderID] = status
+	return nil
+}
+
+func (m *mockTransaksiRepo) FindByOrder(ctx context.Context, orderID string) ([]domain.Transaksi, error) {
+	return []domain.Transaksi{}, nil
+}
+
+type mockProdukRepo struct {
+	domain.ProdukRepository
+
+	stock map[string]int64
+}
+
+func (m *mockProdukRepo) IncrementStock(ctx context.Context, id string, delta int64) (*domain.Produk, error) {
+	m.stock[id] += delta
+	produkID, _ := primitive.ObjectIDFromHex(id)
+	return &domain.Produk{ID: produkID, Stock: m.stock[id]}, nil
+}
+
+type mockStokRepo struct {
+	domain.StokRepository
+
+	movements []*domain.StokMovement
+}
+
+func (m *mockStokRepo) InsertOne(ctx context.Context, req *domain.StokMovement) (*domain.StokMovement, error) {
+	m.movements = append(m.movements, req)
+	return req, nil
+}
+
+// mockUserAmountRepo failUser meniru saldo yang gagal dikembalikan untuk satu user
+type mockUserAmountRepo struct {
+	domain.UserAmountRepository
+
+	saldo    map[string]float64
+	failUser string
+}
+
+func (m *mockUserAmountRepo) IncrementAmount(ctx context.Context, userID string, delta float64) (*domain.UserAmount, error) {
+	if userID == m.failUser {
+		return nil, errors.New("mongo down")
+	}
+	m.saldo[userID] += delta
+	return &domain.UserAmount{Amount: m.saldo[userID]}, nil
+}
+
+func TestCreate(t *testing.T) {
+	tests := []struct {
+		name      string
+		takenCode int
+		wantErr   bool
+	}{
+		{name: "unique code", takenCode: 0},
+		{name: "retries taken code", takenCode: pickupCodeAttempts - 1},
+		{name: "gives up after attempts", takenCode: pickupCodeAttempts, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &mockPickupRepo{pickups: map[primitive.ObjectID]*domain.Pickup{}, takenCode: tt.takenCode}
+			pu := NewPickupUsecase(repo, nil, nil, nil, nil, nil, nil, cache.NewMemoryCache(), time.Second)
+
+			res, err := pu.Create(context.Background(), &domain.Pickup{UserID: primitive.NewObjectID(), TotalBayar: 5000})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+
+			if len(res.Code) != pickupCodeLength || res.Status != domain.PickupStatusPending {
+				t.Fatalf("pickup = %+v, want pending with a %d character code", res, pickupCodeLength)
+			}
+		})
+	}
+}
+
+func TestMarkPickedUp(t *testing.T) {
+	staffID := primitive.NewObjectID().Hex()
+	pickup := &domain.Pickup{ID: primitive.NewObjectID(), Code: "ABC234", Status: domain.PickupStatusPending}
+	repo := &mockPickupRepo{pickups: map[primitive.ObjectID]*domain.Pickup{pickup.ID: pickup}}
+	pu := NewPickupUsecase(repo, nil, nil, nil, nil, nil, nil, cache.NewMemoryCache(), time.Second)
+
+	tests := []struct {
+		name    string
+		code    string
+		wantErr bool
+	}{
+		{name: "unknown code", code: "ZZZ999", wantErr: true},
+		{name: "qr payload in lower case", code: " " + domain.PickupQRPrefix + "abc234 "},
+		{name: "already picked up", code: "ABC234", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res, err := pu.MarkPickedUp(context.Background(), tt.code, staffID)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+
+			if res.Status != domain.PickupStatusPickedUp || res.PickedUpAt == nil {
+				t.Fatalf("pickup = %+v, want picked up", res)
+			}
+		})
+	}
+}
+
+func TestRefundPending(t *testing.T) {
+	produkID := primitive.NewObjectID()
+	buyer := primitive.NewObjectID()
+	gagal := primitive.NewObjectID()
+
+	pending := &domain.Pickup{ID: primitive.NewObjectID(), OrderID: primitive.NewObjectID(), UserID: buyer, Code: "AAA222", Status: domain.PickupStatusPending, TotalBayar: 12000, Items: []domain.PickupItem{{ProdukID: produkID, Qty: 2}}}
+	saldoGagal := &domain.Pickup{ID: primitive.NewObjectID(), OrderID: primitive.NewObjectID(), UserID: gagal, Code: "BBB333", Status: domain.PickupStatusPending, TotalBayar: 5000, Items: []domain.PickupItem{{ProdukID: produkID, Qty: 1}}}
+	diambil := &domain.Pickup{ID: primitive.NewObjectID(), OrderID: primitive.NewObjectID(), UserID: buyer, Code: "CCC444", Status: domain.PickupStatusPickedUp, TotalBayar: 8000}
+
+	repo := &mockPickupRepo{pickups: map[primitive.ObjectID]*domain.Pickup{pending.ID: pending, saldoGagal.ID: saldoGagal, diambil.ID: diambil}}
+	transaksiRepo := &mockTransaksiRepo{status: map[primitive.ObjectID]string{}}
+	produkRepo := &mockProdukRepo{stock: map[string]int64{produkID.Hex(): 3}}
+	stokRepo := &mockStokRepo{}
+	userAmountRepo := &mockUserAmountRepo{saldo: map[string]float64{}, failUser: gagal.Hex()}
+	pu := NewPickupUsecase(repo, transaksiRepo, produkRepo, stokRepo, userAmountRepo, nil, nil, cache.NewMemoryCache(), time.Second)
+
+	refunded, err := pu.RefundPending(context.Background(), primitive.NewObjectID().Hex())
+	if err != nil {
+		t.Fatalf("err = %v", err)
+	}
+	if refunded != 1 {
+		t.Fatalf("refunded = %d, want 1", refunded)
+	}
+
+	if got := userAmountRepo.saldo[buyer.Hex()]; got != 12000 {
+		t.Errorf("saldo = %.0f, want 12000", got)
+	}
+	if got := produkRepo.stock[produkID.Hex()]; got != 5 {
+		t.Errorf("stock = %d, want 5", got)
+	}
+	if len(stokRepo.movements) != 1 || stokRepo.movements[0].Type != domain.StokMovementRefund {
+		t.Errorf("movements = %+v, want one refund movement", stokRepo.movements)
+	}
+	if transaksiRepo.status[pending.OrderID] != domain.TransaksiStatusRefunded {
+		t.Errorf("transaksi status = %q, want %q", transaksiRepo.status[pending.OrderID], domain.TransaksiStatusRefunded)
+	}
+
+	// saldo gagal dikembalikan, tiket kembali pending dan stok tidak berubah
+	if got := repo.pickups[saldoGagal.ID].Status; got != domain.PickupStatusPending {
+		t.Errorf("failed refund status = %q, want pending", got)
+	}
+	if got := repo.pickups[diambil.ID].Status; got != domain.PickupStatusPickedUp {
+		t.Errorf("picked up status = %q, want unchanged", got)
+	}
+	if _, ok := transaksiRepo.status[saldoGagal.OrderID]; ok {
+		t.Error("transaksi of failed refund should keep its status")
+	}
+}
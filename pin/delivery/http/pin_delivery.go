@@ -0,0 +1,213 @@
+package http
+
+import (
+	"net/http"
+	"warunk-bem/domain"
+	"warunk-bem/dtos"
+	"warunk-bem/helpers"
+	"warunk-bem/middlewares"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+)
+
+type PinHandler struct {
+	PinUsecase domain.PinUsecase
+}
+
+func NewPinHandler(protected *gin.RouterGroup, pu domain.PinUsecase) {
+	handler := &PinHandler{
+		PinUsecase: pu,
+	}
+
+	protected = protected.Group("/pin")
+	protected.GET("", handler.GetStatus)
+	protected.POST("", handler.SetPin)
+	protected.PUT("", handler.ChangePin)
+	protected.POST("/reset", handler.ResetPin)
+}
+
+func isRequestValid(m interface{}) (bool, error) {
+	validate := validator.New()
+	err := validate.Struct(m)
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func unauthorized(c *gin.Context, err error) {
+	c.JSON(
+		http.StatusUnauthorized,
+		dtos.NewErrorResponse(
+			http.StatusUnauthorized,
+			"Please login first to access this pages",
+			dtos.GetErrorData(err),
+		),
+	)
+}
+
+// bindRequest menulis response error sendiri, handler cukup return jika hasilnya false
+func bindRequest(c *gin.Context, req interface{}) bool {
+	err := c.ShouldBindJSON(req)
+	if err != nil {
+		c.JSON(
+			http.StatusUnprocessableEntity,
+			dtos.NewErrorResponse(
+				http.StatusUnprocessableEntity,
+				"Filed Cannot Be Empty",
+				dtos.GetErrorData(err),
+			),
+		)
+		return false
+	}
+
+	if ok, err := isRequestValid(req); !ok {
+		c.JSON(
+			http.StatusBadRequest,
+			dtos.NewErrorResponse(
+				http.StatusBadRequest,
+				"Bad Request",
+				dtos.GetErrorData(err),
+			),
+		)
+		return false
+	}
+
+	return true
+}
+
+func (ph *PinHandler) GetStatus(c *gin.Context) {
+	idUser, err := middlewares.IsUser(c)
+	if err != nil {
+		unauthorized(c, err)
+		return
+	}
+
+	result, err := ph.PinUsecase.GetStatus(helpers.RequestContext(c), idUser)
+	if err != nil {
+		c.JSON(
+			http.StatusBadRequest,
+			dtos.NewErrorResponse(
+				http.StatusBadRequest,
+				"Cannot Get PIN Status",
+				err.Error(),
+			),
+		)
+		return
+	}
+
+	c.JSON(
+		http.StatusOK,
+		dtos.NewResponse(
+			http.StatusOK,
+			"Success Get PIN Status",
+			result,
+		),
+	)
+}
+
+func (ph *PinHandler) SetPin(c *gin.Context) {
+	var req dtos.SetPinRequest
+
+	idUser, err := middlewares.IsUser(c)
+	if err != nil {
+		unauthorized(c, err)
+		return
+	}
+
+	if !bindRequest(c, &req) {
+		return
+	}
+
+	err = ph.PinUsecase.SetPin(helpers.RequestContext(c), idUser, &req)
+	if err != nil {
+		c.JSON(
+			http.StatusBadRequest,
+			dtos.NewErrorResponse(
+				http.StatusBadRequest,
+				"Cannot Set PIN",
+				err.Error(),
+			),
+		)
+		return
+	}
+
+	c.JSON(
+		http.StatusOK,
+		dtos.NewResponseMessage(
+			http.StatusOK,
+			"Success Set PIN",
+		),
+	)
+}
+
+func (ph *PinHandler) ChangePin(c *gin.Context) {
+	var req dtos.ChangePinRequest
+
+	idUser, err := middlewares.IsUser(c)
+	if err != nil {
+		unauthorized(c, err)
+		return
+	}
+
+	if !bindRequest(c, &req) {
+		return
+	}
+
+	err = ph.PinUsecase.ChangePin(helpers.RequestContext(c), idUser, &req)
+	if err != nil {
+		c.JSON(
+			http.StatusBadRequest,
+			dtos.NewErrorResponse(
+				http.StatusBadRequest,
+				"Cannot Change PIN",
+				err.Error(),
+			),
+		)
+		return
+	}
+
+	c.JSON(
+		http.StatusOK,
+		dtos.NewResponseMessage(
+			http.StatusOK,
+			"Success Change PIN",
+		),
+	)
+}
+
+func (ph *PinHandler) ResetPin(c *gin.Context) {
+	var req dtos.ResetPinRequest
+
+	idUser, err := middlewares.IsUser(c)
+	if err != nil {
+		unauthorized(c, err)
+		return
+	}
+
+	if !bindRequest(c, &req) {
+		return
+	}
+
+	err = ph.PinUsecase.ResetPin(helpers.RequestContext(c), idUser, &req)
+	if err != nil {
+		c.JSON(
+			http.StatusBadRequest,
+			dtos.NewErrorResponse(
+				http.StatusBadRequest,
+				"Cannot Reset PIN",
+				err.Error(),
+			),
+		)
+		return
+	}
+
+	c.JSON(
+		http.StatusOK,
+		dtos.NewResponseMessage(
+			http.StatusOK,
+			"Success Reset PIN",
+		),
+	)
+}
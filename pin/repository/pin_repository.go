@@ -0,0 +1,151 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+	"warunk-bem/domain"
+	"warunk-bem/mongo"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	mongodriver "go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type pinRepository struct {
+	DB         mongo.Database
+	Collection mongo.Collection
+}
+
+const (
+	timeFormat     = "2006-01-02T15:04:05.999Z07:00" // reduce precision from RFC3339Nano as date format
+	collectionName = "transaction_pin"
+)
+
+func NewPinRepository(DB mongo.Database) domain.PinRepository {
+	return &pinRepository{DB, DB.Collection(collectionName)}
+}
+
+func (r *pinRepository) EnsureIndexes(ctx context.Context) error {
+	_, err := r.Collection.CreateIndexes(ctx, []mongodriver.IndexModel{
+		{
+			Keys:    bson.D{{Key: "user_id", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+	})
+	return err
+}
+
+// attemptFields memetakan jenis percobaan ke field penghitung dan kuncinya
+func attemptFields(kind string) (string, string) {
+	if kind == domain.PinAttemptPassword {
+		return "password_failed_attempts", "password_locked_until"
+	}
+	return "failed_attempts", "locked_until"
+}
+
+// FindByUser mengembalikan domain.ErrPinNotSet jika user belum pernah mengatur PIN
+func (r *pinRepository) FindByUser(ctx context.Context, userID primitive.ObjectID) (*domain.TransactionPin, error) {
+	pin, err := r.FindAttempts(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	// dokumen tanpa pin_hash hanya menyimpan percobaan password
+	if pin.PinHash == "" {
+		return nil, domain.ErrPinNotSet
+	}
+
+	return pin, nil
+}
+
+// FindAttempts mengembalikan dokumen kosong jika user belum pernah mengatur PIN maupun salah password
+func (r *pinRepository) FindAttempts(ctx context.Context, userID primitive.ObjectID) (*domain.TransactionPin, error) {
+	var pin domain.TransactionPin
+
+	err := r.Collection.FindOne(ctx, bson.M{"user_id": userID}).Decode(&pin)
+	if errors.Is(err, mongodriver.ErrNoDocuments) {
+		return &domain.TransactionPin{UserID: userID}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &pin, nil
+}
+
+// Save membuat atau mengganti PIN user sekaligus membuka kunci dan mengosongkan percobaan gagal
+func (r *pinRepository) Save(ctx context.Context, pin *domain.TransactionPin) error {
+	pin.UpdatedAt = time.Now()
+
+	update := bson.M{
+		"$set": bson.M{
+			"updated_at":      pin.UpdatedAt,
+			"pin_hash":        pin.PinHash,
+			"failed_attempts": 0,
+		},
+		"$unset": bson.M{"locked_until": ""},
+		"$setOnInsert": bson.M{
+			"_id":        primitive.NewObjectID(),
+			"created_at": pin.UpdatedAt,
+		},
+	}
+
+	_, err := r.Collection.UpdateOne(ctx, bson.M{"user_id": pin.UserID}, update, options.Update().SetUpsert(true))
+	return err
+}
+
+// IncrementFailure menambah percobaan gagal secara atomik dan mengembalikan jumlah terbaru,
+// dokumen dibuat tanpa pin_hash jika user belum mengatur PIN
+func (r *pinRepository) IncrementFailure(ctx context.Context, userID primitive.ObjectID, kind string) (int64, error) {
+	counter, _ := attemptFields(kind)
+	now := time.Now()
+
+	_, err := r.Collection.UpdateOne(ctx, bson.M{"user_id": userID}, bson.M{
+		"$inc": bson.M{counter: 1},
+		"$set": bson.M{"updated_at": now},
+		"$setOnInsert": bson.M{
+			"_id":        primitive.NewObjectID(),
+			"created_at": now,
+		},
+	}, options.Update().SetUpsert(true))
+	if err != nil {
+		return 0, err
+	}
+
+	pin, err := r.FindAttempts(ctx, userID)
+	if err != nil {
+		return 0, err
+	}
+
+	if kind == domain.PinAttemptPassword {
+		return pin.PasswordFailedAttempts, nil
+	}
+	return pin.FailedAttempts, nil
+}
+
+func (r *pinRepository) Lock(ctx context.Context, userID primitive.ObjectID, kind string, until time.Time) error {
+	counter, lock := attemptFields(kind)
+
+	_, err := r.Collection.UpdateOne(ctx, bson.M{"user_id": userID}, bson.M{"$set": bson.M{
+		"updated_at": time.Now(),
+		counter:      0,
+		lock:         until,
+	}})
+	return err
+}
+
+func (r *pinRepository) ResetFailure(ctx context.Context, userID primitive.ObjectID, kind string) error {
+	counter, lock := attemptFields(kind)
+
+	filter := bson.M{"user_id": userID, "$or": []bson.M{
+		{counter: bson.M{"$gt": 0}},
+		{lock: bson.M{"$exists": true}},
+	}}
+
+	_, err := r.Collection.UpdateOne(ctx, filter, bson.M{
+		"$set":   bson.M{counter: 0},
+		"$unset": bson.M{lock: ""},
+	})
+	return err
+}
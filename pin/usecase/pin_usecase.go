@@ -0,0 +1,326 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+	"warunk-bem/domain"
+	"warunk-bem/dtos"
+	"warunk-bem/helpers"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type pinUsecase struct {
+	PinRepo           domain.PinRepository
+	UserRepo          domain.UserRepository
+	maxAttempts       int64
+	lockDuration      time.Duration
+	checkoutThreshold float64
+	contextTimeout    time.Duration
+}
+
+func NewPinUsecase(PinRepo domain.PinRepository, UserRepo domain.UserRepository, maxAttempts int64, lockDuration time.Duration, checkoutThreshold float64, contextTimeout time.Duration) domain.PinUsecase {
+	return &pinUsecase{
+		PinRepo:           PinRepo,
+		UserRepo:          UserRepo,
+		maxAttempts:       maxAttempts,
+		lockDuration:      lockDuration,
+		checkoutThreshold: checkoutThreshold,
+		contextTimeout:    contextTimeout,
+	}
+}
+
+// GetPinStatus godoc
+// @Summary      Get PIN Status
+// @Description  Get whether the transaction PIN is set, remaining attempts and lockout time
+// @Tags         User - PIN
+// @Accept       json
+// @Produce      json
+// @Success      200 {object} dtos.PinStatusOKResponse
+// @Failure      400 {object} dtos.BadRequestResponse
+// @Failure      401 {object} dtos.UnauthorizedResponse
+// @Failure      403 {object} dtos.ForbiddenResponse
+// @Failure      500 {object} dtos.InternalServerErrorResponse
+// @Router       /pin [get]
+// @Security BearerAuth
+func (pu *pinUsecase) GetStatus(c context.Context, userID string) (*dtos.PinStatusResponse, error) {
+	ctx, cancel := context.WithTimeout(c, pu.contextTimeout)
+	defer cancel()
+
+	userHex, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	res := &dtos.PinStatusResponse{
+		RemainingAttempts: pu.maxAttempts,
+		CheckoutThreshold: pu.checkoutThreshold,
+	}
+
+	pin, err := pu.PinRepo.FindByUser(ctx, userHex)
+	if errors.Is(err, domain.ErrPinNotSet) {
+		return res, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	res.Enabled = true
+	res.RemainingAttempts = pu.maxAttempts - pin.FailedAttempts
+	if pin.LockedUntil != nil && time.Now().Before(*pin.LockedUntil) {
+		res.LockedUntil = pin.LockedUntil
+		res.RemainingAttempts = 0
+	}
+
+	return res, nil
+}
+
+// SetPin godoc
+// @Summary      Set PIN
+// @Description  Set a 6 digit transaction PIN for the first time, confirmed with the account password
+// @Tags         User - PIN
+// @Accept       json
+// @Produce      json
+// @Param        request body dtos.SetPinRequest true "Payload Body [RAW]"
+// @Success      200 {object} dtos.StatusOKResponse
+// @Failure      400 {object} dtos.BadRequestResponse
+// @Failure      401 {object} dtos.UnauthorizedResponse
+// @Failure      403 {object} dtos.ForbiddenResponse
+// @Failure      500 {object} dtos.InternalServerErrorResponse
+// @Router       /pin [post]
+// @Security BearerAuth
+func (pu *pinUsecase) SetPin(c context.Context, userID string, req *dtos.SetPinRequest) error {
+	ctx, cancel := context.WithTimeout(c, pu.contextTimeout)
+	defer cancel()
+
+	user, err := pu.checkPassword(ctx, userID, req.Password)
+	if err != nil {
+		return err
+	}
+
+	_, err = pu.PinRepo.FindByUser(ctx, user.ID)
+	if err == nil {
+		return errors.New("PIN sudah diatur, gunakan ubah PIN atau reset PIN")
+	}
+	if !errors.Is(err, domain.ErrPinNotSet) {
+		return err
+	}
+
+	return pu.save(ctx, user.ID, req.Pin)
+}
+
+// ChangePin godoc
+// @Summary      Change PIN
+// @Description  Change the transaction PIN using the current PIN, failed attempts count towards the lockout
+// @Tags         User - PIN
+// @Accept       json
+// @Produce      json
+// @Param        request body dtos.ChangePinRequest true "Payload Body [RAW]"
+// @Success      200 {object} dtos.StatusOKResponse
+// @Failure      400 {object} dtos.BadRequestResponse
+// @Failure      401 {object} dtos.UnauthorizedResponse
+// @Failure      403 {object} dtos.ForbiddenResponse
+// @Failure      500 {object} dtos.InternalServerErrorResponse
+// @Router       /pin [put]
+// @Security BearerAuth
+func (pu *pinUsecase) ChangePin(c context.Context, userID string, req *dtos.ChangePinRequest) error {
+	ctx, cancel := context.WithTimeout(c, pu.contextTimeout)
+	defer cancel()
+
+	userHex, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return err
+	}
+
+	pin, err := pu.PinRepo.FindByUser(ctx, userHex)
+	if err != nil {
+		return err
+	}
+
+	err = pu.verify(ctx, pin, req.OldPin)
+	if err != nil {
+		return err
+	}
+
+	if req.NewPin == req.OldPin {
+		return errors.New("PIN baru harus berbeda dengan PIN lama")
+	}
+
+	return pu.save(ctx, userHex, req.NewPin)
+}
+
+// ResetPin godoc
+// @Summary      Reset PIN
+// @Description  Replace a forgotten or locked transaction PIN, confirmed with the account password
+// @Tags         User - PIN
+// @Accept       json
+// @Produce      json
+// @Param        request body dtos.ResetPinRequest true "Payload Body [RAW]"
+// @Success      200 {object} dtos.StatusOKResponse
+// @Failure      400 {object} dtos.BadRequestResponse
+// @Failure      401 {object} dtos.UnauthorizedResponse
+// @Failure      403 {object} dtos.ForbiddenResponse
+// @Failure      500 {object} dtos.InternalServerErrorResponse
+// @Router       /pin/reset [post]
+// @Security BearerAuth
+func (pu *pinUsecase) ResetPin(c context.Context, userID string, req *dtos.ResetPinRequest) error {
+	ctx, cancel := context.WithTimeout(c, pu.contextTimeout)
+	defer cancel()
+
+	user, err := pu.checkPassword(ctx, userID, req.Password)
+	if err != nil {
+		return err
+	}
+
+	_, err = pu.PinRepo.FindByUser(ctx, user.ID)
+	if err != nil {
+		return err
+	}
+
+	return pu.save(ctx, user.ID, req.NewPin)
+}
+
+func (pu *pinUsecase) Verify(c context.Context, userID string, input string) error {
+	ctx, cancel := context.WithTimeout(c, pu.contextTimeout)
+	defer cancel()
+
+	userHex, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return err
+	}
+
+	pin, err := pu.PinRepo.FindByUser(ctx, userHex)
+	if err != nil {
+		return err
+	}
+
+	return pu.verify(ctx, pin, input)
+}
+
+func (pu *pinUsecase) Confirm(c context.Context, userID string, input string, password string) error {
+	ctx, cancel := context.WithTimeout(c, pu.contextTimeout)
+	defer cancel()
+
+	userHex, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return err
+	}
+
+	pin, err := pu.PinRepo.FindByUser(ctx, userHex)
+	if errors.Is(err, domain.ErrPinNotSet) {
+		if password == "" {
+			return errors.New("password wajib diisi")
+		}
+		_, err = pu.checkPassword(ctx, userID, password)
+		return err
+	}
+	if err != nil {
+		return err
+	}
+
+	return pu.verify(ctx, pin, input)
+}
+
+func (pu *pinUsecase) RequireForCheckout(c context.Context, userID string, input string, total float64) error {
+	if total <= pu.checkoutThreshold {
+		return nil
+	}
+
+	err := pu.Verify(c, userID, input)
+	if errors.Is(err, domain.ErrPinNotSet) {
+		return nil
+	}
+
+	return err
+}
+
+func (pu *pinUsecase) ConfirmForCheckout(c context.Context, userID string, input string, password string, total float64) error {
+	if total <= pu.checkoutThreshold {
+		return nil
+	}
+
+	return pu.Confirm(c, userID, input, password)
+}
+
+// verify menolak PIN selama masih terkunci, PIN salah ke-maxAttempts mengunci PIN selama lockDuration
+func (pu *pinUsecase) verify(ctx context.Context, pin *domain.TransactionPin, input string) error {
+	now := time.Now()
+	if pin.LockedUntil != nil && now.Before(*pin.LockedUntil) {
+		return fmt.Errorf("PIN terkunci sampai %s, gunakan reset PIN dengan password", pin.LockedUntil.Format("15:04"))
+	}
+
+	if input == "" {
+		return domain.ErrPinRequired
+	}
+
+	return pu.attempt(ctx, pin.UserID, domain.PinAttemptPin, input, pin.PinHash)
+}
+
+// checkPassword memakai penghitung percobaan terpisah dari PIN agar password tidak bisa ditebak berulang
+// lewat konfirmasi transaksi, atur PIN maupun reset PIN
+func (pu *pinUsecase) checkPassword(ctx context.Context, userID string, password string) (*domain.User, error) {
+	user, err := pu.UserRepo.FindOne(ctx, userID)
+	if err != nil {
+		return nil, errors.New("user tidak ditemukan")
+	}
+
+	state, err := pu.PinRepo.FindAttempts(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	if state.PasswordLockedUntil != nil && time.Now().Before(*state.PasswordLockedUntil) {
+		return nil, fmt.Errorf("password terkunci sampai %s", state.PasswordLockedUntil.Format("15:04"))
+	}
+
+	err = pu.attempt(ctx, user.ID, domain.PinAttemptPassword, password, user.Password)
+	if err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
+
+// attempt mencocokkan input dengan hash, input salah ke-maxAttempts mengunci jenis percobaan tersebut selama lockDuration
+func (pu *pinUsecase) attempt(ctx context.Context, userID primitive.ObjectID, kind string, input string, hash string) error {
+	label := "PIN"
+	if kind == domain.PinAttemptPassword {
+		label = "password"
+	}
+
+	err := helpers.ComparePassword(input, hash)
+	if err == nil {
+		return pu.PinRepo.ResetFailure(ctx, userID, kind)
+	}
+
+	failed, err := pu.PinRepo.IncrementFailure(ctx, userID, kind)
+	if err != nil {
+		return err
+	}
+
+	if failed >= pu.maxAttempts {
+		lockedUntil := time.Now().Add(pu.lockDuration)
+		err = pu.PinRepo.Lock(ctx, userID, kind, lockedUntil)
+		if err != nil {
+			return err
+		}
+
+		return fmt.Errorf("%s salah %d kali, %s terkunci sampai %s", label, failed, label, lockedUntil.Format("15:04"))
+	}
+
+	return fmt.Errorf("%s salah, sisa %d percobaan", label, pu.maxAttempts-failed)
+}
+
+func (pu *pinUsecase) save(ctx context.Context, userID primitive.ObjectID, input string) error {
+	hash, err := helpers.HashPassword(input)
+	if err != nil {
+		return err
+	}
+
+	return pu.PinRepo.Save(ctx, &domain.TransactionPin{
+		UserID:  userID,
+		PinHash: hash,
+	})
+}
@@ -0,0 +1,288 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+	"warunk-bem/domain"
+	"warunk-bem/dtos"
+	"warunk-bem/helpers"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// mockPinRepo menyimpan satu dokumen per user seperti koleksi transaction_pin,
+// dokumen tanpa pin_hash hanya berisi penghitung percobaan password
+type mockPinRepo struct {
+	domain.PinRepository
+
+	pins map[primitive.ObjectID]*domain.TransactionPin
+}
+
+func (m *mockPinRepo) FindByUser(ctx context.Context, userID primitive.ObjectID) (*domain.TransactionPin, error) {
+	pin, ok := m.pins[userID]
+	if !ok || pin.PinHash == "" {
+		return nil, domain.ErrPinNotSet
+	}
+	copied := *pin
+	return &copied, nil
+}
+
+func (m *mockPinRepo) FindAttempts(ctx context.Context, userID primitive.ObjectID) (*domain.TransactionPin, error) {
+	pin, ok := m.pins[userID]
+	if !ok {
+		return &domain.TransactionPin{UserID: userID}, nil
+	}
+	copied := *pin
+	return &copied, nil
+}
+
+func (m *mockPinRepo) Save(ctx context.Context, pin *domain.TransactionPin) error {
+	stored := m.upsert(pin.UserID)
+	stored.PinHash = pin.PinHash
+	stored.FailedAttempts = 0
+	stored.LockedUntil = nil
+	return nil
+}
+
+func (m *mockPinRepo) IncrementFailure(ctx context.Context, userID primitive.ObjectID, kind string) (int64, error) {
+	stored := m.upsert(userID)
+	if kind == domain.PinAttemptPassword {
+		stored.PasswordFailedAttempts++
+		return stored.PasswordFailedAttempts, nil
+	}
+	stored.FailedAttempts++
+	return stored.FailedAttempts, nil
+}
+
+func (m *mockPinRepo) Lock(ctx context.Context, userID primitive.ObjectID, kind string, until time.Time) error {
+	stored := m.upsert(userID)
+	if kind == domain.PinAttemptPassword {
+		stored.PasswordFailedAttempts = 0
+		stored.PasswordLockedUntil = &until
+		return nil
+	}
+	stored.FailedAttempts = 0
+	stored.LockedUntil = &until
+	return nil
+}
+
+func (m *mockPinRepo) ResetFailure(ctx context.Context, userID primitive.ObjectID, kind string) error {
+	stored, ok := m.pins[userID]
+	if !ok {
+		return nil
+	}
+	if kind == domain.PinAttemptPassword {
+		stored.PasswordFailedAttempts = 0
+		stored.PasswordLockedUntil = nil
+		return nil
+	}
+	stored.FailedAttempts = 0
+	stored.LockedUntil = nil
+	return nil
+}
+
+func (m *mockPinRepo) upsert(userID primitive.ObjectID) *domain.TransactionPin {
+	stored, ok := m.pins[userID]
+	if !ok {
+		stored = &domain.TransactionPin{UserID: userID}
+		m.pins[userID] = stored
+	}
+	return stored
+}
+
+type mockUserRepo struct {
+	domain.UserRepository
+
+	user *domain.User
+}
+
+func (m *mockUserRepo) FindOne(ctx context.Context, id string) (*domain.User, error) {
+	if m.user.ID.Hex() != id {
+		return nil, errors.New("not found")
+	}
+	return m.user, nil
+}
+
+const (
+	testPassword = "rahasia123"
+	testPin      = "123456"
+	testNewPin   = "654321"
+	wrongInput   = "000000"
+	testAttempts = 3
+)
+
+// pinStep adalah satu aksi user, wantErr berisi potongan pesan error atau kosong jika harus berhasil
+type pinStep struct {
+	action  string
+	pin     string
+	pass    string
+	wantErr string
+}
+
+func TestPinLockout(t *testing.T) {
+	passwordHash, err := helpers.HashPassword(testPassword)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pinHash, err := helpers.HashPassword(testPin)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name   string
+		hasPin bool
+		steps  []pinStep
+	}{
+		{
+			name:   "correct pin resets failures",
+			hasPin: true,
+			steps: []pinStep{
+				{action: "verify", pin: wrongInput, wantErr: "sisa 2"},
+				{action: "verify", pin: wrongInput, wantErr: "sisa 1"},
+				{action: "verify", pin: testPin},
+				{action: "verify", pin: wrongInput, wantErr: "sisa 2"},
+			},
+		},
+		{
+			name:   "pin locks after max attempts",
+			hasPin: true,
+			steps: []pinStep{
+				{action: "verify", pin: wrongInput, wantErr: "sisa 2"},
+				{action: "verify", pin: wrongInput, wantErr: "sisa 1"},
+				{action: "verify", pin: wrongInput, wantErr: "PIN terkunci"},
+				{action: "verify", pin: testPin, wantErr: "PIN terkunci"},
+				{action: "confirm", pin: testPin, pass: testPassword, wantErr: "PIN terkunci"},
+			},
+		},
+		{
+			name:   "locked pin can be reset with password",
+			hasPin: true,
+			steps: []pinStep{
+				{action: "verify", pin: wrongInput, wantErr: "sisa"},
+				{action: "verify", pin: wrongInput, wantErr: "sisa"},
+				{action: "verify", pin: wrongInput, wantErr: "terkunci"},
+				{action: "reset", pin: testNewPin, pass: testPassword},
+				{action: "verify", pin: testNewPin},
+			},
+		},
+		{
+			name:   "empty pin does not count as failure",
+			hasPin: true,
+			steps: []pinStep{
+				{action: "verify", pin: "", wantErr: domain.ErrPinRequired.Error()},
+				{action: "verify", pin: wrongInput, wantErr: "sisa 2"},
+			},
+		},
+		{
+			name: "password fallback locks after max attempts",
+			steps: []pinStep{
+				{action: "confirm", pass: "salah", wantErr: "password salah, sisa 2"},
+				{action: "confirm", pass: "salah", wantErr: "password salah, sisa 1"},
+				{action: "confirm", pass: "salah", wantErr: "password terkunci"},
+				{action: "confirm", pass: testPassword, wantErr: "password terkunci"},
+				{action: "set", pin: testPin, pass: testPassword, wantErr: "password terkunci"},
+			},
+		},
+		{
+			name: "password fallback succeeds and resets failures",
+			steps: []pinStep{
+				{action: "confirm", pass: "salah", wantErr: "sisa 2"},
+				{action: "confirm", pass: testPassword},
+				{action: "confirm", pass: "salah", wantErr: "sisa 2"},
+				{action: "confirm", pass: "", wantErr: "password wajib diisi"},
+			},
+		},
+		{
+			name:   "password lock blocks pin reset",
+			hasPin: true,
+			steps: []pinStep{
+				{action: "reset", pin: testNewPin, pass: "salah", wantErr: "sisa"},
+				{action: "reset", pin: testNewPin, pass: "salah", wantErr: "sisa"},
+				{action: "reset", pin: testNewPin, pass: "salah", wantErr: "password terkunci"},
+				{action: "reset", pin: testNewPin, pass: testPassword, wantErr: "password terkunci"},
+				{action: "verify", pin: testPin},
+			},
+		},
+		{
+			name: "password attempts do not enable pin",
+			steps: []pinStep{
+				{action: "confirm", pass: "salah", wantErr: "sisa"},
+				{action: "verify", pin: testPin, wantErr: domain.ErrPinNotSet.Error()},
+				{action: "set", pin: testPin, pass: testPassword},
+				{action: "verify", pin: testPin},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			user := &domain.User{ID: primitive.NewObjectID(), Password: passwordHash}
+			repo := &mockPinRepo{pins: map[primitive.ObjectID]*domain.TransactionPin{}}
+			if tt.hasPin {
+				repo.pins[user.ID] = &domain.TransactionPin{UserID: user.ID, PinHash: pinHash}
+			}
+			pu := NewPinUsecase(repo, &mockUserRepo{user: user}, testAttempts, 15*time.Minute, 50000, time.Second)
+
+			for i, step := range tt.steps {
+				var err error
+				switch step.action {
+				case "verify":
+					err = pu.Verify(context.Background(), user.ID.Hex(), step.pin)
+				case "confirm":
+					err = pu.Confirm(context.Background(), user.ID.Hex(), step.pin, step.pass)
+				case "set":
+					err = pu.SetPin(context.Background(), user.ID.Hex(), &dtos.SetPinRequest{Pin: step.pin, Password: step.pass})
+				case "reset":
+					err = pu.ResetPin(context.Background(), user.ID.Hex(), &dtos.ResetPinRequest{NewPin: step.pin, Password: step.pass})
+				default:
+					t.Fatalf("unknown action %s", step.action)
+				}
+
+				if step.wantErr == "" {
+					if err != nil {
+						t.Fatalf("step %d %s: unexpected error: %v", i, step.action, err)
+					}
+					continue
+				}
+				if err == nil || !strings.Contains(err.Error(), step.wantErr) {
+					t.Fatalf("step %d %s: err = %v, want %q", i, step.action, err, step.wantErr)
+				}
+			}
+		})
+	}
+}
+
+func TestConfirmForCheckout(t *testing.T) {
+	passwordHash, err := helpers.HashPassword(testPassword)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		total   float64
+		pass    string
+		wantErr bool
+	}{
+		{name: "below threshold skips confirmation", total: 50000, pass: ""},
+		{name: "above threshold requires password", total: 50001, pass: "", wantErr: true},
+		{name: "above threshold with password", total: 50001, pass: testPassword},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			user := &domain.User{ID: primitive.NewObjectID(), Password: passwordHash}
+			repo := &mockPinRepo{pins: map[primitive.ObjectID]*domain.TransactionPin{}}
+			pu := NewPinUsecase(repo, &mockUserRepo{user: user}, testAttempts, 15*time.Minute, 50000, time.Second)
+
+			err := pu.ConfirmForCheckout(context.Background(), user.ID.Hex(), "", tt.pass, tt.total)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,201 @@
+package http
+
+import (
+	"math"
+	"net/http"
+	"warunk-bem/domain"
+	"warunk-bem/dtos"
+	"warunk-bem/helpers"
+	"warunk-bem/middlewares"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+)
+
+type PreOrderHandler struct {
+	PreOrderUsecase domain.PreOrderUsecase
+}
+
+func NewPreOrderHandler(protected *gin.RouterGroup, protectedAdmin *gin.RouterGroup, pu domain.PreOrderUsecase) {
+	handler := &PreOrderHandler{
+		PreOrderUsecase: pu,
+	}
+
+	protected = protected.Group("/preorder")
+	protectedAdmin = protectedAdmin.Group("/preorder")
+
+	protected.POST("", handler.Create)
+	protected.GET("", handler.GetMine)
+	protected.DELETE("/:id", handler.Cancel)
+	protectedAdmin.GET("/demand", handler.GetDemand)
+}
+
+func isRequestValid(m interface{}) (bool, error) {
+	validate := validator.New()
+	err := validate.Struct(m)
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func unauthorized(c *gin.Context, err error) {
+	c.JSON(
+		http.StatusUnauthorized,
+		dtos.NewErrorResponse(
+			http.StatusUnauthorized,
+			"Please login first to access this pages",
+			dtos.GetErrorData(err),
+		),
+	)
+}
+
+func (ph *PreOrderHandler) Create(c *gin.Context) {
+	var req dtos.PreOrderRequest
+
+	idUser, err := middlewares.IsUser(c)
+	if err != nil {
+		unauthorized(c, err)
+		return
+	}
+
+	err = c.ShouldBindJSON(&req)
+	if err != nil {
+		c.JSON(
+			http.StatusUnprocessableEntity,
+			dtos.NewErrorResponse(
+				http.StatusUnprocessableEntity,
+				"Filed Cannot Be Empty",
+				dtos.GetErrorData(err),
+			),
+		)
+		return
+	}
+
+	if ok, err := isRequestValid(&req); !ok {
+		c.JSON(
+			http.StatusBadRequest,
+			dtos.NewErrorResponse(
+				http.StatusBadRequest,
+				"Bad Request",
+				dtos.GetErrorData(err),
+			),
+		)
+		return
+	}
+
+	result, err := ph.PreOrderUsecase.Create(helpers.RequestContext(c), idUser, &req)
+	if err != nil {
+		c.JSON(
+			http.StatusBadRequest,
+			dtos.NewErrorResponse(
+				http.StatusBadRequest,
+				"Cannot Create Pre-Order",
+				err.Error(),
+			),
+		)
+		return
+	}
+
+	c.JSON(
+		http.StatusCreated,
+		dtos.NewResponse(
+			http.StatusCreated,
+			"Pre-Order Berhasil",
+			result,
+		),
+	)
+}
+
+func (ph *PreOrderHandler) GetMine(c *gin.Context) {
+	idUser, err := middlewares.IsUser(c)
+	if err != nil {
+		unauthorized(c, err)
+		return
+	}
+
+	rp, page := helpers.Pagination(c)
+	res, count, err := ph.PreOrderUsecase.GetMine(helpers.RequestContext(c), idUser, rp, page)
+	if err != nil {
+		c.JSON(
+			http.StatusBadRequest,
+			dtos.NewErrorResponse(
+				http.StatusBadRequest,
+				"Cannot Get Pre-Order",
+				err.Error(),
+			),
+		)
+		return
+	}
+
+	c.JSON(
+		http.StatusOK,
+		dtos.NewResponse(
+			http.StatusOK,
+			"Success Get Pre-Order",
+			dtos.GetAllPreOrderResponse{
+				Total:       count,
+				PerPage:     rp,
+				CurrentPage: page,
+				LastPage:    int64(math.Ceil(float64(count) / float64(rp))),
+				From:        (page * rp) - rp + 1,
+				To:          page * rp,
+				PreOrder:    res,
+			},
+		),
+	)
+}
+
+func (ph *PreOrderHandler) Cancel(c *gin.Context) {
+	idUser, err := middlewares.IsUser(c)
+	if err != nil {
+		unauthorized(c, err)
+		return
+	}
+
+	result, err := ph.PreOrderUsecase.Cancel(helpers.RequestContext(c), c.Param("id"), idUser)
+	if err != nil {
+		c.JSON(
+			http.StatusBadRequest,
+			dtos.NewErrorResponse(
+				http.StatusBadRequest,
+				"Cannot Cancel Pre-Order",
+				err.Error(),
+			),
+		)
+		return
+	}
+
+	c.JSON(
+		http.StatusOK,
+		dtos.NewResponse(
+			http.StatusOK,
+			"Success Cancel Pre-Order",
+			result,
+		),
+	)
+}
+
+func (ph *PreOrderHandler) GetDemand(c *gin.Context) {
+	res, err := ph.PreOrderUsecase.GetDemand(helpers.RequestContext(c))
+	if err != nil {
+		c.JSON(
+			http.StatusInternalServerError,
+			dtos.NewErrorResponse(
+				http.StatusInternalServerError,
+				"Cannot Get Pre-Order Demand",
+				err.Error(),
+			),
+		)
+		return
+	}
+
+	c.JSON(
+		http.StatusOK,
+		dtos.NewResponse(
+			http.StatusOK,
+			"Success Get Pre-Order Demand",
+			res,
+		),
+	)
+}
@@ -0,0 +1,253 @@
+package repository
+
+import (
+	"context"
+	"time"
+	"warunk-bem/domain"
+	"warunk-bem/mongo"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	mongodriver "go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type preOrderRepository struct {
+	DB                 mongo.Database
+	Collection         mongo.Collection
+	ReservedCollection mongo.Collection
+}
+
+const (
+	timeFormat             = "2006-01-02T15:04:05.999Z07:00" // reduce precision from RFC3339Nano as date format
+	collectionName         = "preorder"
+	reservedCollectionName = "preorder_reserved"
+)
+
+func NewPreOrderRepository(DB mongo.Database) domain.PreOrderRepository {
+	return &preOrderRepository{DB, DB.Collection(collectionName), DB.Collection(reservedCollectionName)}
+}
+
+func (r *preOrderRepository) EnsureIndexes(ctx context.Context) error {
+	_, err := r.Collection.CreateIndexes(ctx, []mongodriver.IndexModel{
+		{
+			Keys: bson.D{{Key: "status", Value: 1}, {Key: "created_at", Value: 1}},
+		},
+		{
+			Keys: bson.D{{Key: "status", Value: 1}, {Key: "expires_at", Value: 1}},
+		},
+		{
+			Keys: bson.D{{Key: "user_id", Value: 1}, {Key: "created_at", Value: -1}},
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	// Unique index membuat upsert ReserveQty gagal, bukan membuat dokumen kedua, saat stok sudah habis dipesan
+	_, err = r.ReservedCollection.CreateIndexes(ctx, []mongodriver.IndexModel{
+		{
+			Keys:    bson.D{{Key: "produk_id", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+	})
+	return err
+}
+
+func (r *preOrderRepository) InsertOne(ctx context.Context, req *domain.PreOrder) (*domain.PreOrder, error) {
+	_, err := r.Collection.InsertOne(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	return req, nil
+}
+
+func (r *preOrderRepository) FindOne(ctx context.Context, id string) (*domain.PreOrder, error) {
+	var preOrder domain.PreOrder
+
+	idHex, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, err
+	}
+
+	err = r.Collection.FindOne(ctx, bson.M{"_id": idHex}).Decode(&preOrder)
+	if err != nil {
+		return nil, err
+	}
+
+	return &preOrder, nil
+}
+
+func (r *preOrderRepository) FindPending(ctx context.Context, createdBefore time.Time) ([]domain.PreOrder, error) {
+	filter := bson.M{
+		"status":     domain.PreOrderStatusPending,
+		"created_at": bson.M{"$lt": createdBefore},
+	}
+
+	return r.find(ctx, filter)
+}
+
+func (r *preOrderRepository) FindExpired(ctx context.Context, now time.Time) ([]domain.PreOrder, error) {
+	filter := bson.M{
+		"status":     domain.PreOrderStatusPending,
+		"expires_at": bson.M{"$lte": now},
+	}
+
+	return r.find(ctx, filter)
+}
+
+func (r *preOrderRepository) find(ctx context.Context, filter interface{}) ([]domain.PreOrder, error) {
+	var preOrders []domain.PreOrder
+
+	cursor, err := r.Collection.Find(ctx, filter, options.Find().SetSort(bson.M{"created_at": 1}))
+	if err != nil {
+		return preOrders, err
+	}
+
+	err = cursor.All(ctx, &preOrders)
+	if err != nil {
+		return preOrders, err
+	}
+
+	return preOrders, nil
+}
+
+func (r *preOrderRepository) GetAllWithPage(ctx context.Context, rp int64, p int64, filter interface{}, setsort interface{}) ([]domain.PreOrder, int64, error) {
+	var (
+		preOrder []domain.PreOrder
+		err      error
+	)
+
+	findOptions := options.Find()
+	findOptions.SetLimit(rp)
+	findOptions.SetSkip((p - 1) * rp)
+	if setsort != nil {
+		findOptions.SetSort(setsort)
+	}
+
+	cursor, err := r.Collection.Find(ctx, filter, findOptions)
+	if err != nil {
+		return preOrder, 0, err
+	}
+
+	err = cursor.All(ctx, &preOrder)
+	if err != nil {
+		return preOrder, 0, err
+	}
+
+	total, err := r.Collection.CountDocuments(ctx, filter)
+	if err != nil {
+		return preOrder, 0, err
+	}
+
+	return preOrder, total, nil
+}
+
+func (r *preOrderRepository) Demand(ctx context.Context) ([]domain.PreOrderDemand, error) {
+	pipeline := []bson.M{
+		{
+			"$match": bson.M{"status": domain.PreOrderStatusPending},
+		},
+		{
+			"$group": bson.M{
+				"_id":         "$produk_id",
+				"produk_name": bson.M{"$first": "$produk_name"},
+				"qty":         bson.M{"$sum": "$qty"},
+				"orders":      bson.M{"$sum": 1},
+			},
+		},
+		{
+			"$sort": bson.D{{Key: "qty", Value: -1}, {Key: "_id", Value: 1}},
+		},
+	}
+
+	cursor, err := r.Collection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	demand := []domain.PreOrderDemand{}
+	for cursor.Next(ctx) {
+		var d domain.PreOrderDemand
+		if err := cursor.Decode(&d); err != nil {
+			return nil, err
+		}
+
+		demand = append(demand, d)
+	}
+
+	return demand, nil
+}
+
+// UpdateStatus hanya berhasil jika status di database masih fromStatus,
+// sehingga pre-order tidak bisa dipenuhi dan dilepas sekaligus
+func (r *preOrderRepository) UpdateStatus(ctx context.Context, preOrder *domain.PreOrder, fromStatus string) error {
+	preOrder.UpdatedAt = time.Now()
+
+	set := bson.M{
+		"updated_at": preOrder.UpdatedAt,
+		"status":     preOrder.Status,
+	}
+	unset := bson.M{}
+	if preOrder.FulfilledAt != nil {
+		set["fulfilled_at"] = preOrder.FulfilledAt
+		set["warunk_id"] = preOrder.WarunkID
+		set["order_id"] = preOrder.OrderID
+	} else {
+		unset["fulfilled_at"] = ""
+	}
+	if preOrder.ReleasedAt != nil {
+		set["released_at"] = preOrder.ReleasedAt
+		set["release_reason"] = preOrder.ReleaseReason
+	} else {
+		unset["released_at"] = ""
+		unset["release_reason"] = ""
+	}
+
+	result, err := r.Collection.UpdateOne(ctx, bson.M{"_id": preOrder.ID, "status": fromStatus}, bson.M{"$set": set, "$unset": unset})
+	if err != nil {
+		return err
+	}
+
+	if result.MatchedCount == 0 {
+		return domain.ErrPreOrderStatusChanged
+	}
+
+	return nil
+}
+
+func (r *preOrderRepository) ReserveQty(ctx context.Context, produkID primitive.ObjectID, qty int64, maxQty int64) error {
+	if qty > maxQty {
+		return domain.ErrPreOrderStockTaken
+	}
+
+	filter := bson.M{
+		"produk_id": produkID,
+		"qty":       bson.M{"$lte": maxQty - qty},
+	}
+	update := bson.M{
+		"$inc": bson.M{"qty": qty},
+		"$set": bson.M{"updated_at": time.Now()},
+	}
+
+	// Dokumen produk yang sudah penuh tidak cocok dengan filter, upsert lalu ditolak unique index
+	_, err := r.ReservedCollection.UpdateOne(ctx, filter, update, options.Update().SetUpsert(true))
+	if mongodriver.IsDuplicateKeyError(err) {
+		return domain.ErrPreOrderStockTaken
+	}
+
+	return err
+}
+
+func (r *preOrderRepository) ReleaseQty(ctx context.Context, produkID primitive.ObjectID, qty int64) error {
+	_, err := r.ReservedCollection.UpdateOne(ctx,
+		bson.M{"produk_id": produkID, "qty": bson.M{"$gte": qty}},
+		bson.M{
+			"$inc": bson.M{"qty": -qty},
+			"$set": bson.M{"updated_at": time.Now()},
+		},
+	)
+	return err
+}
@@ -0,0 +1,550 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+	"warunk-bem/cache"
+	"warunk-bem/domain"
+	"warunk-bem/dtos"
+	"warunk-bem/helpers"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	releaseReasonCancelled   = "dibatalkan pembeli"
+	releaseReasonExpired     = "sesi warunk berikutnya tidak dibuka sebelum batas waktu"
+	releaseReasonNotStocked  = "produk tidak tersedia di sesi ini"
+	releaseReasonOutOfStock  = "stok sesi ini tidak mencukupi"
+	releaseReasonUnavailable = "produk sudah tidak tersedia"
+)
+
+type preOrderUsecase struct {
+	PreOrderRepo      domain.PreOrderRepository
+	ProdukRepo        domain.ProdukRepository
+	UserRepo          domain.UserRepository
+	UserAmountRepo    domain.UserAmountRepository
+	WarunkRepo        domain.WarunkRepository
+	TransaksiRepo     domain.TransaksiRepository
+	StokRepo          domain.StokRepository
+	PinUsecase        domain.PinUsecase
+	PickupUsecase     domain.PickupUsecase
+	NotifikasiUsecase domain.NotifikasiUsecase
+	Cache             domain.Cache
+	holdDuration      time.Duration
+	contextTimeout    time.Duration
+}
+
+func NewPreOrderUsecase(PreOrderRepo domain.PreOrderRepository, ProdukRepo domain.ProdukRepository, UserRepo domain.UserRepository, UserAmountRepo domain.UserAmountRepository, WarunkRepo domain.WarunkRepository, TransaksiRepo domain.TransaksiRepository, StokRepo domain.StokRepository, PinUsecase domain.PinUsecase, PickupUsecase domain.PickupUsecase, NotifikasiUsecase domain.NotifikasiUsecase, Cache domain.Cache, holdDuration time.Duration, contextTimeout time.Duration) domain.PreOrderUsecase {
+	return &preOrderUsecase{
+		PreOrderRepo:      PreOrderRepo,
+		ProdukRepo:        ProdukRepo,
+		UserRepo:          UserRepo,
+		UserAmountRepo:    UserAmountRepo,
+		WarunkRepo:        WarunkRepo,
+		TransaksiRepo:     TransaksiRepo,
+		StokRepo:          StokRepo,
+		PinUsecase:        PinUsecase,
+		PickupUsecase:     PickupUsecase,
+		NotifikasiUsecase: NotifikasiUsecase,
+		Cache:             Cache,
+		holdDuration:      holdDuration,
+		contextTimeout:    contextTimeout,
+	}
+}
+
+func toPreOrderResponse(preOrder *domain.PreOrder) *dtos.PreOrderResponse {
+	res := &dtos.PreOrderResponse{
+		ID:            preOrder.ID.Hex(),
+		CreatedAt:     preOrder.CreatedAt,
+		ProdukID:      preOrder.ProdukID.Hex(),
+		ProdukName:    preOrder.ProdukName,
+		Qty:           preOrder.Qty,
+		Price:         preOrder.Price,
+		TotalBayar:    preOrder.TotalBayar,
+		Status:        preOrder.Status,
+		ExpiresAt:     preOrder.ExpiresAt,
+		FulfilledAt:   preOrder.FulfilledAt,
+		ReleasedAt:    preOrder.ReleasedAt,
+		ReleaseReason: preOrder.ReleaseReason,
+	}
+
+	if !preOrder.OrderID.IsZero() {
+		res.OrderID = preOrder.OrderID.Hex()
+	}
+
+	return res
+}
+
+// CreatePreOrder godoc
+// @Summary      Create Pre-Order
+// @Description  Pre-order a produk for the next warunk session while the warunk is closed. Saldo is held until the pre-order is fulfilled or released, quantity is limited by the latest session catalog stock minus other pending pre-orders
+// @Tags         User - Pre-Order
+// @Accept       json
+// @Produce      json
+// @Param        request body dtos.PreOrderRequest true "Payload Body [RAW]"
+// @Success      201 {object} dtos.PreOrderCreatedResponse
+// @Failure      400 {object} dtos.BadRequestResponse
+// @Failure      401 {object} dtos.UnauthorizedResponse
+// @Failure      403 {object} dtos.ForbiddenResponse
+// @Failure      500 {object} dtos.InternalServerErrorResponse
+// @Router       /preorder [post]
+// @Security BearerAuth
+func (pu *preOrderUsecase) Create(c context.Context, userID string, req *dtos.PreOrderRequest) (*dtos.PreOrderResponse, error) {
+	ctx, cancel := context.WithTimeout(c, pu.contextTimeout)
+	defer cancel()
+
+	userHex, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	open, err := pu.isWarunkOpen(ctx)
+	if err != nil {
+		return nil, errors.New("cannot get warunk status")
+	}
+
+	if open {
+		return nil, errors.New("warunk sedang buka, silakan checkout langsung")
+	}
+
+	produk, err := pu.ProdukRepo.FindOne(ctx, req.ProdukID)
+	if err != nil {
+		return nil, errors.New("produk tidak ditemukan")
+	}
+
+	if produk.DeletedAt != nil {
+		return nil, errors.New("produk sudah tidak tersedia")
+	}
+
+	if req.Qty <= 0 {
+		return nil, errors.New("harap masukan jumlah produk yang ingin dipesan")
+	}
+
+	total := produk.Price * req.Qty
+
+	err = pu.PinUsecase.RequireForCheckout(ctx, userID, req.Pin, float64(total))
+	if err != nil {
+		return nil, err
+	}
+
+	catalogStock, err := pu.catalogStock(ctx, produk.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Qty dipesan lebih dulu agar pre-order bersamaan tidak melebihi stok katalog
+	err = pu.PreOrderRepo.ReserveQty(ctx, produk.ID, req.Qty, catalogStock)
+	if err != nil {
+		return nil, err
+	}
+
+	saldo, err := pu.UserAmountRepo.DecrementAmount(ctx, userID, float64(total))
+	if err != nil {
+		pu.releaseQty(ctx, produk.ID, req.Qty)
+		return nil, errors.New("saldo tidak mencukupi")
+	}
+
+	now := time.Now()
+	preOrder := &domain.PreOrder{
+		ID:          primitive.NewObjectID(),
+		CreatedAt:   now,
+		UpdatedAt:   now,
+		UserID:      userHex,
+		ProdukID:    produk.ID,
+		ProdukName:  produk.Name,
+		Qty:         req.Qty,
+		Price:       produk.Price,
+		TotalBayar:  total,
+		SaldoBefore: saldo.Amount + float64(total),
+		SaldoAfter:  saldo.Amount,
+		Status:      domain.PreOrderStatusPending,
+		ExpiresAt:   now.Add(pu.holdDuration),
+	}
+
+	_, err = pu.PreOrderRepo.InsertOne(ctx, preOrder)
+	if err != nil {
+		if _, refundErr := pu.UserAmountRepo.IncrementAmount(ctx, userID, float64(total)); refundErr != nil {
+			log.Println("cannot refund pre-order saldo: ", refundErr.Error())
+		}
+		pu.releaseQty(ctx, produk.ID, req.Qty)
+		return nil, errors.New("cannot create pre-order")
+	}
+
+	cache.InvalidateUser(ctx, pu.Cache, userID)
+
+	return toPreOrderResponse(preOrder), nil
+}
+
+// GetMyPreOrder godoc
+// @Summary      Get My Pre-Order
+// @Description  Get pre-orders of the logged in user, newest first
+// @Tags         User - Pre-Order
+// @Accept       json
+// @Produce      json
+// @Param        rp query int false "rp"
+// @Param        p query int false "p"
+// @Success      200 {object} dtos.PreOrdersOKResponse
+// @Failure      400 {object} dtos.BadRequestResponse
+// @Failure      401 {object} dtos.UnauthorizedResponse
+// @Failure      403 {object} dtos.ForbiddenResponse
+// @Failure      500 {object} dtos.InternalServerErrorResponse
+// @Router       /preorder [get]
+// @Security BearerAuth
+func (pu *preOrderUsecase) GetMine(c context.Context, userID string, rp int64, p int64) ([]*dtos.PreOrderResponse, int64, error) {
+	res := []*dtos.PreOrderResponse{}
+
+	ctx, cancel := context.WithTimeout(c, pu.contextTimeout)
+	defer cancel()
+
+	userHex, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return res, 0, err
+	}
+
+	preOrders, count, err := pu.PreOrderRepo.GetAllWithPage(ctx, rp, p, bson.M{"user_id": userHex}, bson.M{"created_at": -1})
+	if err != nil {
+		return res, 0, err
+	}
+
+	for i := range preOrders {
+		res = append(res, toPreOrderResponse(&preOrders[i]))
+	}
+
+	return res, count, nil
+}
+
+// CancelPreOrder godoc
+// @Summary      Cancel Pre-Order
+// @Description  Cancel a pending pre-order and release the held saldo
+// @Tags         User - Pre-Order
+// @Accept       json
+// @Produce      json
+// @Param        id path string true "pre-order id"
+// @Success      200 {object} dtos.PreOrderOKResponse
+// @Failure      400 {object} dtos.BadRequestResponse
+// @Failure      401 {object} dtos.UnauthorizedResponse
+// @Failure      403 {object} dtos.ForbiddenResponse
+// @Failure      404 {object} dtos.NotFoundResponse
+// @Failure      500 {object} dtos.InternalServerErrorResponse
+// @Router       /preorder/{id} [delete]
+// @Security BearerAuth
+func (pu *preOrderUsecase) Cancel(c context.Context, id string, userID string) (*dtos.PreOrderResponse, error) {
+	ctx, cancel := context.WithTimeout(c, pu.contextTimeout)
+	defer cancel()
+
+	preOrder, err := pu.PreOrderRepo.FindOne(ctx, id)
+	if err != nil || preOrder.UserID.Hex() != userID {
+		return nil, errors.New("pre-order tidak ditemukan")
+	}
+
+	if preOrder.Status != domain.PreOrderStatusPending {
+		return nil, errors.New("hanya pre-order pending yang bisa dibatalkan")
+	}
+
+	err = pu.release(ctx, preOrder, releaseReasonCancelled)
+	if err != nil {
+		return nil, err
+	}
+
+	return toPreOrderResponse(preOrder), nil
+}
+
+// GetPreOrderDemand godoc
+// @Summary      Get Pre-Order Demand
+// @Description  Get pending pre-order quantity per produk to plan the stock of the next warunk session
+// @Tags         Admin - Pre-Order
+// @Accept       json
+// @Produce      json
+// @Success      200 {object} dtos.PreOrderDemandOKResponse
+// @Failure      401 {object} dtos.UnauthorizedResponse
+// @Failure      403 {object} dtos.ForbiddenResponse
+// @Failure      500 {object} dtos.InternalServerErrorResponse
+// @Router       /preorder/demand [get]
+// @Security BearerAuth
+func (pu *preOrderUsecase) GetDemand(c context.Context) ([]dtos.PreOrderDemandResponse, error) {
+	ctx, cancel := context.WithTimeout(c, pu.contextTimeout)
+	defer cancel()
+
+	demand, err := pu.PreOrderRepo.Demand(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]dtos.PreOrderDemandResponse, 0, len(demand))
+	for _, d := range demand {
+		res = append(res, dtos.PreOrderDemandResponse{
+			ProdukID:   d.ProdukID.Hex(),
+			ProdukName: d.ProdukName,
+			Qty:        d.Qty,
+			Orders:     d.Orders,
+		})
+	}
+
+	return res, nil
+}
+
+func (pu *preOrderUsecase) FulfillPending(c context.Context, warunk *domain.Warunk, actorID string) (*dtos.PreOrderFulfillResponse, error) {
+	res := &dtos.PreOrderFulfillResponse{}
+
+	actorHex, err := primitive.ObjectIDFromHex(actorID)
+	if err != nil {
+		return res, err
+	}
+
+	ctx, cancel := context.WithTimeout(c, pu.contextTimeout)
+	preOrders, err := pu.PreOrderRepo.FindPending(ctx, warunk.CreatedAt)
+	cancel()
+	if err != nil {
+		return res, err
+	}
+
+	catalog := make(map[primitive.ObjectID]bool, len(warunk.Produk))
+	for _, produk := range warunk.Produk {
+		catalog[produk.ID] = produk.Stock > 0
+	}
+
+	// Pre-order terlama dipenuhi lebih dulu, setiap pre-order mendapat timeout sendiri
+	for i := range preOrders {
+		preOrder := &preOrders[i]
+
+		ctx, cancel := context.WithTimeout(c, pu.contextTimeout)
+		reason := ""
+		switch {
+		case !time.Now().Before(preOrder.ExpiresAt):
+			reason = releaseReasonExpired
+		case !catalog[preOrder.ProdukID]:
+			reason = releaseReasonNotStocked
+		default:
+			reason, err = pu.fulfill(ctx, preOrder, warunk.ID, actorHex)
+		}
+
+		if reason != "" {
+			err = pu.release(ctx, preOrder, reason)
+			if err == nil {
+				res.Released++
+			}
+		} else if err == nil {
+			res.Fulfilled++
+		}
+		cancel()
+
+		if err != nil && !errors.Is(err, domain.ErrPreOrderStatusChanged) {
+			log.Println("cannot process pre-order "+preOrder.ID.Hex()+": ", err.Error())
+		}
+	}
+
+	if res.Fulfilled > 0 {
+		cache.InvalidateProduk(c, pu.Cache)
+	}
+
+	return res, nil
+}
+
+func (pu *preOrderUsecase) ReleaseExpired(c context.Context) (int64, error) {
+	ctx, cancel := context.WithTimeout(c, pu.contextTimeout)
+	preOrders, err := pu.PreOrderRepo.FindExpired(ctx, time.Now())
+	cancel()
+	if err != nil {
+		return 0, err
+	}
+
+	var released int64
+	for i := range preOrders {
+		ctx, cancel := context.WithTimeout(c, pu.contextTimeout)
+		err = pu.release(ctx, &preOrders[i], releaseReasonExpired)
+		cancel()
+		if errors.Is(err, domain.ErrPreOrderStatusChanged) {
+			continue
+		}
+		if err != nil {
+			log.Println("cannot release pre-order "+preOrders[i].ID.Hex()+": ", err.Error())
+			continue
+		}
+
+		released++
+	}
+
+	return released, nil
+}
+
+// fulfill memotong stok sesi lalu mencatat transaksi dan tiket pickup dari saldo yang sudah ditahan,
+// reason tidak kosong berarti pre-order harus dilepas
+func (pu *preOrderUsecase) fulfill(ctx context.Context, preOrder *domain.PreOrder, warunkID primitive.ObjectID, actorID primitive.ObjectID) (string, error) {
+	produk, err := pu.ProdukRepo.FindOne(ctx, preOrder.ProdukID.Hex())
+	if err != nil || produk.DeletedAt != nil {
+		return releaseReasonUnavailable, nil
+	}
+
+	produk, err = pu.ProdukRepo.DecrementStock(ctx, preOrder.ProdukID.Hex(), preOrder.Qty)
+	if err != nil {
+		return releaseReasonOutOfStock, nil
+	}
+
+	now := time.Now()
+	preOrder.Status = domain.PreOrderStatusFulfilled
+	preOrder.FulfilledAt = &now
+	preOrder.WarunkID = warunkID
+	preOrder.OrderID = primitive.NewObjectID()
+
+	err = pu.PreOrderRepo.UpdateStatus(ctx, preOrder, domain.PreOrderStatusPending)
+	if err != nil {
+		if _, restoreErr := pu.ProdukRepo.IncrementStock(ctx, preOrder.ProdukID.Hex(), preOrder.Qty); restoreErr != nil {
+			log.Println("cannot restore stok produk: ", restoreErr.Error())
+		}
+		return "", err
+	}
+
+	pu.releaseQty(ctx, preOrder.ProdukID, preOrder.Qty)
+
+	// Saldo sudah dipotong saat pre-order dibuat, kegagalan berikutnya hanya bisa dicatat di log
+	transaksi := &domain.Transaksi{
+		ID:          preOrder.OrderID,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+		UserID:      preOrder.UserID,
+		OrderID:     preOrder.OrderID,
+		ProdukID:    preOrder.ProdukID,
+		Total:       preOrder.Qty,
+		Harga:       preOrder.TotalBayar,
+		Status:      "Berhasil",
+		SaldoBefore: &preOrder.SaldoBefore,
+		SaldoAfter:  &preOrder.SaldoAfter,
+		Channel:     domain.TransaksiChannelPreOrder,
+	}
+
+	_, err = pu.TransaksiRepo.InsertOne(ctx, transaksi)
+	if err != nil {
+		log.Println("cannot insert pre-order transaksi: ", err.Error())
+	}
+
+	movement := domain.NewStokMovement(produk, domain.StokMovementSale, produk.Stock+preOrder.Qty, "pre-order", actorID, transaksi.ID)
+	_, err = pu.StokRepo.InsertOne(ctx, movement)
+	if err != nil {
+		log.Println("cannot record stok movement: ", err.Error())
+	}
+
+	userName := ""
+	if user, err := pu.UserRepo.FindOne(ctx, preOrder.UserID.Hex()); err == nil {
+		userName = user.Name
+	}
+
+	message := fmt.Sprintf("Pre-order %s x%d sudah siap di warunk.", preOrder.ProdukName, preOrder.Qty)
+	pickup, err := pu.PickupUsecase.Create(ctx, &domain.Pickup{
+		OrderID:       transaksi.OrderID,
+		ReceiptNumber: transaksi.ReceiptNumber(),
+		UserID:        preOrder.UserID,
+		UserName:      userName,
+		Items: []domain.PickupItem{
+			{ProdukID: preOrder.ProdukID, ProdukName: preOrder.ProdukName, Qty: preOrder.Qty},
+		},
+		TotalBayar: preOrder.TotalBayar,
+	})
+	if err != nil {
+		log.Println("cannot create pickup: ", err.Error())
+	} else {
+		message += " Tunjukkan kode pickup " + pickup.Code + " ke staff warunk."
+	}
+
+	cache.InvalidateUser(ctx, pu.Cache, preOrder.UserID.Hex())
+
+	helpers.NotifyAsync(pu.NotifikasiUsecase, "pre-order", &domain.Notifikasi{
+		UserID:      preOrder.UserID,
+		Type:        domain.NotifikasiTypePreOrder,
+		Title:       "Pre-order " + transaksi.ReceiptNumber() + " siap diambil",
+		Message:     message,
+		ReferenceID: preOrder.ID,
+	})
+
+	return "", nil
+}
+
+// release mengunci pre-order lebih dulu lalu mengembalikan saldo, status dikembalikan ke pending jika saldo gagal dikembalikan
+func (pu *preOrderUsecase) release(ctx context.Context, preOrder *domain.PreOrder, reason string) error {
+	now := time.Now()
+	preOrder.Status = domain.PreOrderStatusReleased
+	preOrder.FulfilledAt = nil
+	preOrder.ReleasedAt = &now
+	preOrder.ReleaseReason = reason
+
+	err := pu.PreOrderRepo.UpdateStatus(ctx, preOrder, domain.PreOrderStatusPending)
+	if err != nil {
+		return err
+	}
+
+	_, err = pu.UserAmountRepo.IncrementAmount(ctx, preOrder.UserID.Hex(), float64(preOrder.TotalBayar))
+	if err != nil {
+		preOrder.Status = domain.PreOrderStatusPending
+		preOrder.ReleasedAt = nil
+		preOrder.ReleaseReason = ""
+		if rollbackErr := pu.PreOrderRepo.UpdateStatus(ctx, preOrder, domain.PreOrderStatusReleased); rollbackErr != nil {
+			log.Println("cannot rollback pre-order status: ", rollbackErr.Error())
+		}
+		return err
+	}
+
+	pu.releaseQty(ctx, preOrder.ProdukID, preOrder.Qty)
+
+	cache.InvalidateUser(ctx, pu.Cache, preOrder.UserID.Hex())
+
+	helpers.NotifyAsync(pu.NotifikasiUsecase, "pre-order", &domain.Notifikasi{
+		UserID:      preOrder.UserID,
+		Type:        domain.NotifikasiTypePreOrder,
+		Title:       "Pre-order " + preOrder.ProdukName + " dibatalkan",
+		Message:     fmt.Sprintf("Pre-order %s x%d dibatalkan karena %s. Saldo Rp%s sudah dikembalikan.", preOrder.ProdukName, preOrder.Qty, reason, helpers.FormatRupiah(float64(preOrder.TotalBayar))),
+		ReferenceID: preOrder.ID,
+	})
+
+	return nil
+}
+
+// isWarunkOpen warunk dianggap buka jika dibuka hari ini dan belum ditutup sesudahnya
+func (pu *preOrderUsecase) isWarunkOpen(ctx context.Context) (bool, error) {
+	buka, err := pu.WarunkRepo.FindLatestByStatus(ctx, "Buka")
+	if err != nil || buka == nil {
+		return false, err
+	}
+
+	now := time.Now()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	if buka.CreatedAt.Before(today) {
+		return false, nil
+	}
+
+	tutup, err := pu.WarunkRepo.FindLatestByStatus(ctx, "Tutup")
+	if err != nil {
+		return false, err
+	}
+
+	return tutup == nil || tutup.CreatedAt.Before(buka.CreatedAt), nil
+}
+
+// catalogStock mengembalikan stok produk di katalog sesi warunk terakhir sebagai batas pre-order sesi berikutnya
+func (pu *preOrderUsecase) catalogStock(ctx context.Context, produkID primitive.ObjectID) (int64, error) {
+	buka, err := pu.WarunkRepo.FindLatestByStatus(ctx, "Buka")
+	if err != nil {
+		return 0, errors.New("cannot get warunk catalog")
+	}
+
+	if buka != nil {
+		for _, produk := range buka.Produk {
+			if produk.ID == produkID {
+				return produk.Stock, nil
+			}
+		}
+	}
+
+	return 0, errors.New("produk tidak ada di katalog warunk")
+}
+
+// releaseQty melepas qty yang dipesan, kegagalan hanya dicatat karena saldo sudah diproses
+func (pu *preOrderUsecase) releaseQty(ctx context.Context, produkID primitive.ObjectID, qty int64) {
+	err := pu.PreOrderRepo.ReleaseQty(ctx, produkID, qty)
+	if err != nil {
+		log.Println("cannot release pre-order qty: ", err.Error())
+	}
+}
@@ -0,0 +1,395 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+	"warunk-bem/cache"
+	"warunk-bem/domain"
+	"warunk-bem/dtos"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// mockPreOrderRepo menyimpan pre-order dan qty yang dipesan di memori, UpdateStatus meniru filter status di repository
+type mockPreOrderRepo struct {
+	domain.PreOrderRepository
+
+	preOrders map[string]domain.PreOrder
+	reserved  map[primitive.ObjectID]int64
+	insertErr error
+}
+
+func (m *mockPreOrderRepo) InsertOne(ctx context.Context, req *domain.PreOrder) (*domain.PreOrder, error) {
+	if m.insertErr != nil {
+		return nil, m.insertErr
+	}
+	m.preOrders[req.ID.Hex()] = *req
+	return req, nil
+}
+
+func (m *mockPreOrderRepo) FindOne(ctx context.Context, id string) (*domain.PreOrder, error) {
+	preOrder, ok := m.preOrders[id]
+	if !ok {
+		return nil, errors.New("not found")
+	}
+	return &preOrder, nil
+}
+
+func (m *mockPreOrderRepo) FindPending(ctx context.Context, createdBefore time.Time) ([]domain.PreOrder, error) {
+	var res []domain.PreOrder
+	for _, preOrder := range m.preOrders {
+		if preOrder.Status == domain.PreOrderStatusPending && preOrder.CreatedAt.Before(createdBefore) {
+			res = append(res, preOrder)
+		}
+	}
+	return res, nil
+}
+
+func (m *mockPreOrderRepo) UpdateStatus(ctx context.Context, preOrder *domain.PreOrder, fromStatus string) error {
+	if m.preOrders[preOrder.ID.Hex()].Status != fromStatus {
+		return domain.ErrPreOrderStatusChanged
+	}
+	m.preOrders[preOrder.ID.Hex()] = *preOrder
+	return nil
+}
+
+func (m *mockPreOrderRepo) ReserveQty(ctx context.Context, produkID primitive.ObjectID, qty int64, maxQty int64) error {
+	if m.reserved[produkID]+qty > maxQty {
+		return domain.ErrPreOrderStockTaken
+	}
+	m.reserved[produkID] += qty
+	return nil
+}
+
+func (m *mockPreOrderRepo) ReleaseQty(ctx context.Context, produkID primitive.ObjectID, qty int64) error {
+	m.reserved[produkID] -= qty
+	return nil
+}
+
+// mockProdukRepo meniru IncrementStock yang menolak stok negatif
+type mockProdukRepo struct {
+	domain.ProdukRepository
+
+	produk *domain.Produk
+}
+
+func (m *mockProdukRepo) FindOne(ctx context.Context, id string) (*domain.Produk, error) {
+	if m.produk.ID.Hex() != id {
+		return nil, errors.New("not found")
+	}
+	copied := *m.produk
+	return &copied, nil
+}
+
+func (m *mockProdukRepo) IncrementStock(ctx context.Context, id string, delta int64) (*domain.Produk, error) {
+	if m.produk.ID.Hex() != id || m.produk.Stock+delta < 0 {
+		return nil, errors.New("stok tidak cukup")
+	}
+	m.produk.Stock += delta
+	copied := *m.produk
+	return &copied, nil
+}
+
+func (m *mockProdukRepo) DecrementStock(ctx context.Context, id string, qty int64) (*domain.Produk, error) {
+	if qty <= 0 {
+		return nil, domain.ErrInvalidStockDebit
+	}
+	return m.IncrementStock(ctx, id, -qty)
+}
+
+type mockWarunkRepo struct {
+	domain.WarunkRepository
+
+	buka *domain.Warunk
+}
+
+func (m *mockWarunkRepo) FindLatestByStatus(ctx context.Context, status string) (*domain.Warunk, error) {
+	if status == "Buka" {
+		return m.buka, nil
+	}
+	return nil, nil
+}
+
+// mockUserAmountRepo meniru IncrementAmount yang menolak saldo negatif
+type mockUserAmountRepo struct {
+	domain.UserAmountRepository
+
+	saldo     map[string]float64
+	refundErr error
+}
+
+func (m *mockUserAmountRepo) IncrementAmount(ctx context.Context, userID string, delta float64) (*domain.UserAmount, error) {
+	if delta > 0 && m.refundErr != nil {
+		return nil, m.refundErr
+	}
+	if m.saldo[userID]+delta < 0 {
+		return nil, errors.New("saldo tidak cukup")
+	}
+	m.saldo[userID] += delta
+	return &domain.UserAmount{Amount: m.saldo[userID]}, nil
+}
+
+func (m *mockUserAmountRepo) DecrementAmount(ctx context.Context, userID string, amount float64) (*domain.UserAmount, error) {
+	if amount < 0 {
+		return nil, domain.ErrNegativeDebit
+	}
+	return m.IncrementAmount(ctx, userID, -amount)
+}
+
+type mockUserRepo struct {
+	domain.UserRepository
+}
+
+func (m *mockUserRepo) FindOne(ctx context.Context, id string) (*domain.User, error) {
+	return &domain.User{Name: "Pembeli"}, nil
+}
+
+type mockTransaksiRepo struct {
+	domain.TransaksiRepository
+
+	inserted int
+}
+
+func (m *mockTransaksiRepo) InsertOne(ctx context.Context, req *domain.Transaksi) (*domain.Transaksi, error) {
+	m.inserted++
+	return req, nil
+}
+
+type mockStokRepo struct {
+	domain.StokRepository
+}
+
+func (m *mockStokRepo) InsertOne(ctx context.Context, req *domain.StokMovement) (*domain.StokMovement, error) {
+	return req, nil
+}
+
+type mockPinUsecase struct {
+	domain.PinUsecase
+}
+
+func (m *mockPinUsecase) RequireForCheckout(ctx context.Context, userID string, pin string, total float64) error {
+	return nil
+}
+
+type mockPickupUsecase struct {
+	domain.PickupUsecase
+}
+
+func (m *mockPickupUsecase) Create(ctx context.Context, pickup *domain.Pickup) (*domain.Pickup, error) {
+	pickup.Code = "ABC123"
+	return pickup, nil
+}
+
+// preOrderFixture menyiapkan warunk yang dibuka kemarin dengan katalog berisi satu produk
+type preOrderFixture struct {
+	userID         primitive.ObjectID
+	produk         *domain.Produk
+	preOrderRepo   *mockPreOrderRepo
+	produkRepo     *mockProdukRepo
+	userAmountRepo *mockUserAmountRepo
+	transaksiRepo  *mockTransaksiRepo
+	usecase        domain.PreOrderUsecase
+}
+
+const (
+	testPrice        = 5000
+	testCatalogStock = 5
+	testHold         = 24 * time.Hour
+)
+
+func newPreOrderFixture(saldo float64) *preOrderFixture {
+	f := &preOrderFixture{
+		userID: primitive.NewObjectID(),
+		produk: &domain.Produk{ID: primitive.NewObjectID(), Name: "Teh", Price: testPrice, Stock: testCatalogStock},
+	}
+	f.preOrderRepo = &mockPreOrderRepo{preOrders: map[string]domain.PreOrder{}, reserved: map[primitive.ObjectID]int64{}}
+	f.produkRepo = &mockProdukRepo{produk: f.produk}
+	f.userAmountRepo = &mockUserAmountRepo{saldo: map[string]float64{f.userID.Hex(): saldo}}
+	f.transaksiRepo = &mockTransaksiRepo{}
+
+	warunkRepo := &mockWarunkRepo{buka: &domain.Warunk{
+		ID:        primitive.NewObjectID(),
+		CreatedAt: time.Now().AddDate(0, 0, -1),
+		Status:    "Buka",
+		Produk:    []domain.Produk{*f.produk},
+	}}
+	f.usecase = NewPreOrderUsecase(f.preOrderRepo, f.produkRepo, &mockUserRepo{}, f.userAmountRepo, warunkRepo, f.transaksiRepo, &mockStokRepo{}, &mockPinUsecase{}, &mockPickupUsecase{}, nil, cache.NewMemoryCache(), testHold, time.Second)
+	return f
+}
+
+// pending menambahkan pre-order pending yang qty-nya sudah dipesan dan saldonya sudah ditahan
+func (f *preOrderFixture) pending(produkID primitive.ObjectID, qty int64, expiresAt time.Time) domain.PreOrder {
+	preOrder := domain.PreOrder{
+		ID:         primitive.NewObjectID(),
+		CreatedAt:  time.Now().Add(-time.Hour),
+		UserID:     f.userID,
+		ProdukID:   produkID,
+		ProdukName: f.produk.Name,
+		Qty:        qty,
+		Price:      testPrice,
+		TotalBayar: testPrice * qty,
+		Status:     domain.PreOrderStatusPending,
+		ExpiresAt:  expiresAt,
+	}
+	f.preOrderRepo.preOrders[preOrder.ID.Hex()] = preOrder
+	f.preOrderRepo.reserved[produkID] += qty
+	return preOrder
+}
+
+func TestCreate(t *testing.T) {
+	tests := []struct {
+		name         string
+		qty          int64
+		saldo        float64
+		reserved     int64
+		notInCatalog bool
+		insertErr    error
+		wantErr      error
+		wantSaldo    float64
+		wantReserved int64
+	}{
+		{name: "saldo is held and qty reserved", qty: 2, saldo: 50000, wantSaldo: 40000, wantReserved: 2},
+		{name: "up to catalog stock", qty: 2, saldo: 50000, reserved: 3, wantSaldo: 40000, wantReserved: 5},
+		{name: "stock taken by other pre-orders", qty: 2, saldo: 50000, reserved: 4, wantErr: domain.ErrPreOrderStockTaken, wantSaldo: 50000, wantReserved: 4},
+		{name: "produk not in catalog", qty: 1, saldo: 50000, notInCatalog: true, wantErr: errors.New("produk tidak ada di katalog warunk"), wantSaldo: 50000},
+		{name: "insufficient saldo releases qty", qty: 2, saldo: 5000, wantErr: errors.New("saldo tidak mencukupi"), wantSaldo: 5000},
+		{name: "insert failure refunds and releases qty", qty: 2, saldo: 50000, insertErr: errors.New("mongo down"), wantErr: errors.New("cannot create pre-order"), wantSaldo: 50000},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newPreOrderFixture(tt.saldo)
+			f.preOrderRepo.insertErr = tt.insertErr
+
+			produkID := f.produk.ID
+			if tt.notInCatalog {
+				// Produk masih ada tetapi tidak dijual di sesi terakhir
+				f.produk.ID = primitive.NewObjectID()
+				produkID = f.produk.ID
+			}
+			f.preOrderRepo.reserved[produkID] = tt.reserved
+
+			_, err := f.usecase.Create(context.Background(), f.userID.Hex(), &dtos.PreOrderRequest{ProdukID: produkID.Hex(), Qty: tt.qty})
+			if tt.wantErr == nil && err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if tt.wantErr != nil && (err == nil || err.Error() != tt.wantErr.Error()) {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+
+			if got := f.userAmountRepo.saldo[f.userID.Hex()]; got != tt.wantSaldo {
+				t.Errorf("saldo = %.0f, want %.0f", got, tt.wantSaldo)
+			}
+			if got := f.preOrderRepo.reserved[produkID]; got != tt.wantReserved {
+				t.Errorf("reserved = %d, want %d", got, tt.wantReserved)
+			}
+		})
+	}
+}
+
+func TestCancel(t *testing.T) {
+	tests := []struct {
+		name         string
+		status       string
+		refundErr    error
+		wantErr      bool
+		wantSaldo    float64
+		wantReserved int64
+		wantStatus   string
+	}{
+		{name: "pending is refunded and qty released", status: domain.PreOrderStatusPending, wantSaldo: 10000, wantStatus: domain.PreOrderStatusReleased},
+		{name: "already released is not refunded again", status: domain.PreOrderStatusReleased, wantErr: true, wantReserved: 2, wantStatus: domain.PreOrderStatusReleased},
+		{name: "already fulfilled", status: domain.PreOrderStatusFulfilled, wantErr: true, wantReserved: 2, wantStatus: domain.PreOrderStatusFulfilled},
+		{name: "refund failure keeps pre-order pending", status: domain.PreOrderStatusPending, refundErr: errors.New("mongo down"), wantErr: true, wantReserved: 2, wantStatus: domain.PreOrderStatusPending},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newPreOrderFixture(0)
+			f.userAmountRepo.refundErr = tt.refundErr
+
+			preOrder := f.pending(f.produk.ID, 2, time.Now().Add(testHold))
+			preOrder.Status = tt.status
+			f.preOrderRepo.preOrders[preOrder.ID.Hex()] = preOrder
+
+			_, err := f.usecase.Cancel(context.Background(), preOrder.ID.Hex(), f.userID.Hex())
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if got := f.userAmountRepo.saldo[f.userID.Hex()]; got != tt.wantSaldo {
+				t.Errorf("saldo = %.0f, want %.0f", got, tt.wantSaldo)
+			}
+			if got := f.preOrderRepo.reserved[f.produk.ID]; got != tt.wantReserved {
+				t.Errorf("reserved = %d, want %d", got, tt.wantReserved)
+			}
+			if got := f.preOrderRepo.preOrders[preOrder.ID.Hex()].Status; got != tt.wantStatus {
+				t.Errorf("status = %s, want %s", got, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestFulfillPending(t *testing.T) {
+	tests := []struct {
+		name          string
+		qty           int64
+		sessionStock  int64
+		notStocked    bool
+		expired       bool
+		wantFulfilled int64
+		wantReleased  int64
+		wantSaldo     float64
+		wantStock     int64
+		wantStatus    string
+	}{
+		{name: "fulfilled from session stock", qty: 2, sessionStock: 5, wantFulfilled: 1, wantStock: 3, wantStatus: domain.PreOrderStatusFulfilled},
+		{name: "session stock too low is released", qty: 2, sessionStock: 1, wantReleased: 1, wantSaldo: 10000, wantStock: 1, wantStatus: domain.PreOrderStatusReleased},
+		{name: "not in session catalog is released", qty: 2, sessionStock: 5, notStocked: true, wantReleased: 1, wantSaldo: 10000, wantStock: 5, wantStatus: domain.PreOrderStatusReleased},
+		{name: "expired is released", qty: 2, sessionStock: 5, expired: true, wantReleased: 1, wantSaldo: 10000, wantStock: 5, wantStatus: domain.PreOrderStatusReleased},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newPreOrderFixture(0)
+			f.produk.Stock = tt.sessionStock
+
+			expiresAt := time.Now().Add(testHold)
+			if tt.expired {
+				expiresAt = time.Now().Add(-time.Minute)
+			}
+			preOrder := f.pending(f.produk.ID, tt.qty, expiresAt)
+
+			warunk := &domain.Warunk{ID: primitive.NewObjectID(), CreatedAt: time.Now(), Status: "Buka"}
+			if !tt.notStocked {
+				warunk.Produk = []domain.Produk{*f.produk}
+			}
+
+			res, err := f.usecase.FulfillPending(context.Background(), warunk, primitive.NewObjectID().Hex())
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if res.Fulfilled != tt.wantFulfilled || res.Released != tt.wantReleased {
+				t.Errorf("fulfilled %d released %d, want %d and %d", res.Fulfilled, res.Released, tt.wantFulfilled, tt.wantReleased)
+			}
+			if got := f.userAmountRepo.saldo[f.userID.Hex()]; got != tt.wantSaldo {
+				t.Errorf("saldo = %.0f, want %.0f", got, tt.wantSaldo)
+			}
+			if f.produk.Stock != tt.wantStock {
+				t.Errorf("stock = %d, want %d", f.produk.Stock, tt.wantStock)
+			}
+			if got := f.preOrderRepo.reserved[f.produk.ID]; got != 0 {
+				t.Errorf("reserved = %d, want 0", got)
+			}
+			if got := f.preOrderRepo.preOrders[preOrder.ID.Hex()].Status; got != tt.wantStatus {
+				t.Errorf("status = %s, want %s", got, tt.wantStatus)
+			}
+			if f.transaksiRepo.inserted != int(tt.wantFulfilled) {
+				t.Errorf("transaksi = %d, want %d", f.transaksiRepo.inserted, tt.wantFulfilled)
+			}
+		})
+	}
+}
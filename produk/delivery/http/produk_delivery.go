@@ -4,9 +4,7 @@ import (
 	"context"
 	"math"
 	"net/http"
-	"regexp"
 	"strconv"
-	"warunk-bem/cloudinary/usecase"
 	"warunk-bem/domain"
 	"warunk-bem/dtos"
 	"warunk-bem/middlewares"
@@ -19,11 +17,13 @@ import (
 
 type ProdukHandler struct {
 	ProdukUsecase domain.ProdukUsecase
+	MediaUsecase  domain.ClourdinaryUsecase
 }
 
-func NewProdukHandler(router *gin.RouterGroup, protectedAdmin *gin.RouterGroup, pu domain.ProdukUsecase) {
+func NewProdukHandler(router *gin.RouterGroup, protectedAdmin *gin.RouterGroup, pu domain.ProdukUsecase, mu domain.ClourdinaryUsecase) {
 	handler := &ProdukHandler{
 		ProdukUsecase: pu,
+		MediaUsecase:  mu,
 	}
 
 	api := router.Group("/produk")
@@ -31,9 +31,14 @@ func NewProdukHandler(router *gin.RouterGroup, protectedAdmin *gin.RouterGroup,
 
 	api.GET("", handler.GetAllWithPage)
 	api.GET("/:id", handler.FindOne)
+	protectedAdmin.GET("/low-stock", handler.GetLowStock)
 	protectedAdmin.POST("", handler.InsertOne)
 	protectedAdmin.PUT("/:id", handler.UpdateOne)
 	protectedAdmin.DELETE("/:id", handler.DeleteOne)
+	protectedAdmin.POST("/:id/images", handler.AddImages)
+	protectedAdmin.PUT("/:id/images/order", handler.ReorderImages)
+	protectedAdmin.PUT("/:id/images/:imageId/primary", handler.SetPrimaryImage)
+	protectedAdmin.DELETE("/:id/images/:imageId", handler.DeleteImage)
 }
 
 func isRequestValid(m *dtos.InsertProdukRequest) (bool, error) {
@@ -101,6 +106,55 @@ func (cp *ProdukHandler) GetAllWithPage(c *gin.Context) {
 	)
 }
 
+func (cp *ProdukHandler) GetLowStock(c *gin.Context) {
+	rp, err := strconv.ParseInt(c.Query("rp"), 10, 64)
+	if err != nil {
+		rp = 25
+	}
+
+	page, err := strconv.ParseInt(c.Query("p"), 10, 64)
+	if err != nil {
+		page = 1
+	}
+
+	ctx := c.Request.Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	res, count, err := cp.ProdukUsecase.GetLowStock(ctx, rp, page)
+	if err != nil {
+		c.JSON(
+			http.StatusBadRequest,
+			dtos.NewErrorResponse(
+				http.StatusBadRequest,
+				"Cannot Get Low Stock Produk",
+				err.Error(),
+			),
+		)
+		return
+	}
+
+	result := dtos.GetAllProdukResponse{
+		Total:       count,
+		PerPage:     rp,
+		CurrentPage: page,
+		LastPage:    int64(math.Ceil(float64(count) / float64(rp))),
+		From:        (page * rp) - rp + 1,
+		To:          page * rp,
+		Produk:      res,
+	}
+
+	c.JSON(
+		http.StatusOK,
+		dtos.NewResponse(
+			http.StatusOK,
+			"Success Get Low Stock Produk",
+			result,
+		),
+	)
+}
+
 func (cp *ProdukHandler) InsertOne(c *gin.Context) {
 	var (
 		req dtos.InsertProdukRequest
@@ -179,24 +233,12 @@ func (cp *ProdukHandler) InsertOne(c *gin.Context) {
 	}
 	defer src.Close()
 
-	re := regexp.MustCompile(`.png|.jpeg|.jpg`)
-	if !re.MatchString(file.Filename) {
-		c.JSON(
-			http.StatusBadRequest,
-			dtos.NewResponseMessage(
-				http.StatusBadRequest,
-				"The provided file format is not allowed. Please upload a JPEG or PNG image",
-			),
-		)
-		return
-	}
-
-	uploadUrl, err := usecase.NewMediaUpload().FileUpload(domain.File{File: src})
+	uploadUrl, err := cp.MediaUsecase.FileUpload(domain.File{File: src, Filename: file.Filename, Size: file.Size})
 	if err != nil {
 		c.JSON(
-			http.StatusInternalServerError,
+			http.StatusBadRequest,
 			dtos.NewErrorResponse(
-				http.StatusInternalServerError,
+				http.StatusBadRequest,
 				"Error uploading photo",
 				dtos.GetErrorData(err),
 			),
@@ -363,3 +405,214 @@ func (cp *ProdukHandler) DeleteOne(c *gin.Context) {
 		),
 	)
 }
+
+func (cp *ProdukHandler) AddImages(c *gin.Context) {
+	id := c.Param("id")
+
+	ctx := c.Request.Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	form, err := c.MultipartForm()
+	if err != nil {
+		c.JSON(
+			http.StatusBadRequest,
+			dtos.NewErrorResponse(
+				http.StatusBadRequest,
+				"Cannot Upload Image",
+				dtos.GetErrorData(err),
+			),
+		)
+		return
+	}
+
+	files := form.File["images"]
+	if len(files) == 0 {
+		c.JSON(
+			http.StatusBadRequest,
+			dtos.NewResponseMessage(
+				http.StatusBadRequest,
+				"Select at least one image to upload",
+			),
+		)
+		return
+	}
+
+	urls := make([]string, 0, len(files))
+	for _, file := range files {
+		src, err := file.Open()
+		if err != nil {
+			c.JSON(
+				http.StatusBadRequest,
+				dtos.NewErrorResponse(
+					http.StatusBadRequest,
+					"Failed to open file",
+					dtos.GetErrorData(err),
+				),
+			)
+			return
+		}
+
+		uploadUrl, err := cp.MediaUsecase.FileUpload(domain.File{File: src, Filename: file.Filename, Size: file.Size})
+		src.Close()
+		if err != nil {
+			c.JSON(
+				http.StatusBadRequest,
+				dtos.NewErrorResponse(
+					http.StatusBadRequest,
+					"Error uploading photo",
+					dtos.GetErrorData(err),
+				),
+			)
+			return
+		}
+
+		urls = append(urls, uploadUrl)
+	}
+
+	result, err := cp.ProdukUsecase.AddImages(ctx, id, urls)
+	if err != nil {
+		c.JSON(
+			http.StatusBadRequest,
+			dtos.NewErrorResponse(
+				http.StatusBadRequest,
+				"Cannot Add Produk Images",
+				err.Error(),
+			),
+		)
+		return
+	}
+
+	c.JSON(
+		http.StatusOK,
+		dtos.NewResponse(
+			http.StatusOK,
+			"Success Add Produk Images",
+			result,
+		),
+	)
+}
+
+func (cp *ProdukHandler) ReorderImages(c *gin.Context) {
+	var req dtos.ReorderProdukImageRequest
+
+	id := c.Param("id")
+
+	ctx := c.Request.Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	err := c.ShouldBindJSON(&req)
+	if err != nil {
+		c.JSON(
+			http.StatusUnprocessableEntity,
+			dtos.NewErrorResponse(
+				http.StatusUnprocessableEntity,
+				"Filed Cannot Be Empty",
+				err.Error(),
+			),
+		)
+		return
+	}
+
+	err = validator.New().Struct(&req)
+	if err != nil {
+		c.JSON(
+			http.StatusBadRequest,
+			dtos.NewErrorResponse(
+				http.StatusBadRequest,
+				"Bad Request",
+				dtos.GetErrorData(err),
+			),
+		)
+		return
+	}
+
+	result, err := cp.ProdukUsecase.ReorderImages(ctx, id, &req)
+	if err != nil {
+		c.JSON(
+			http.StatusBadRequest,
+			dtos.NewErrorResponse(
+				http.StatusBadRequest,
+				"Cannot Reorder Produk Images",
+				err.Error(),
+			),
+		)
+		return
+	}
+
+	c.JSON(
+		http.StatusOK,
+		dtos.NewResponse(
+			http.StatusOK,
+			"Success Reorder Produk Images",
+			result,
+		),
+	)
+}
+
+func (cp *ProdukHandler) SetPrimaryImage(c *gin.Context) {
+	id := c.Param("id")
+	imageID := c.Param("imageId")
+
+	ctx := c.Request.Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	result, err := cp.ProdukUsecase.SetPrimaryImage(ctx, id, imageID)
+	if err != nil {
+		c.JSON(
+			http.StatusBadRequest,
+			dtos.NewErrorResponse(
+				http.StatusBadRequest,
+				"Cannot Set Primary Image",
+				err.Error(),
+			),
+		)
+		return
+	}
+
+	c.JSON(
+		http.StatusOK,
+		dtos.NewResponse(
+			http.StatusOK,
+			"Success Set Primary Image",
+			result,
+		),
+	)
+}
+
+func (cp *ProdukHandler) DeleteImage(c *gin.Context) {
+	id := c.Param("id")
+	imageID := c.Param("imageId")
+
+	ctx := c.Request.Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	result, err := cp.ProdukUsecase.DeleteImage(ctx, id, imageID)
+	if err != nil {
+		c.JSON(
+			http.StatusBadRequest,
+			dtos.NewErrorResponse(
+				http.StatusBadRequest,
+				"Cannot Delete Produk Image",
+				err.Error(),
+			),
+		)
+		return
+	}
+
+	c.JSON(
+		http.StatusOK,
+		dtos.NewResponse(
+			http.StatusOK,
+			"Success Delete Produk Image",
+			result,
+		),
+	)
+}
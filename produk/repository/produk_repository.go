@@ -2,6 +2,7 @@ package repository
 
 import (
 	"context"
+	"errors"
 	"time"
 	"warunk-bem/domain"
 	"warunk-bem/mongo"
@@ -125,7 +126,22 @@ func (r *produkRepository) UpdateOne(ctx context.Context, produk *domain.Produk,
 
 	produk.UpdatedAt = time.Now()
 
-	_, err = r.Collection.UpdateOne(ctx, bson.M{"_id": idHex}, bson.M{"$set": produk})
+	doc, err := bson.Marshal(produk)
+	if err != nil {
+		return produk, err
+	}
+
+	var set bson.M
+	err = bson.Unmarshal(doc, &set)
+	if err != nil {
+		return produk, err
+	}
+
+	// Stok hanya diubah lewat IncrementStock atau SetStock agar perubahan yang bersamaan tidak saling menimpa
+	delete(set, "_id")
+	delete(set, "stock")
+
+	_, err = r.Collection.UpdateOne(ctx, bson.M{"_id": idHex}, bson.M{"$set": set})
 	if err != nil {
 		return produk, err
 	}
@@ -152,3 +168,75 @@ func (r *produkRepository) DeleteOne(ctx context.Context, id string) error {
 
 	return nil
 }
+
+// IncrementStock mengubah stok secara atomik dan menolak perubahan yang membuat stok negatif
+func (r *produkRepository) IncrementStock(ctx context.Context, id string, delta int64) (*domain.Produk, error) {
+	var produk domain.Produk
+
+	idHex, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, err
+	}
+
+	filter := bson.M{"_id": idHex, "stock": bson.M{"$gte": -delta}}
+	update := bson.M{
+		"$inc": bson.M{"stock": delta},
+		"$set": bson.M{"updated_at": time.Now()},
+	}
+
+	result, err := r.Collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return nil, err
+	}
+
+	if result.MatchedCount == 0 {
+		return nil, errors.New("stok tidak mencukupi")
+	}
+
+	err = r.Collection.FindOne(ctx, bson.M{"_id": idHex}).Decode(&produk)
+	if err != nil {
+		return nil, err
+	}
+
+	return &produk, nil
+}
+
+// DecrementStock dipakai setiap penjualan, qty yang tidak positif ditolak agar penjualan tidak menambah stok
+func (r *produkRepository) DecrementStock(ctx context.Context, id string, qty int64) (*domain.Produk, error) {
+	if qty <= 0 {
+		return nil, domain.ErrInvalidStockDebit
+	}
+
+	return r.IncrementStock(ctx, id, -qty)
+}
+
+// SetStock menimpa stok dengan filter stok yang terakhir dibaca agar perubahan di antaranya tidak hilang
+func (r *produkRepository) SetStock(ctx context.Context, id string, fromStock int64, stock int64) (*domain.Produk, error) {
+	var produk domain.Produk
+
+	idHex, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, err
+	}
+
+	filter := bson.M{"_id": idHex, "stock": fromStock}
+	update := bson.M{
+		"$set": bson.M{"stock": stock, "updated_at": time.Now()},
+	}
+
+	result, err := r.Collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return nil, err
+	}
+
+	if result.MatchedCount == 0 {
+		return nil, domain.ErrStockChanged
+	}
+
+	err = r.Collection.FindOne(ctx, bson.M{"_id": idHex}).Decode(&produk)
+	if err != nil {
+		return nil, err
+	}
+
+	return &produk, nil
+}
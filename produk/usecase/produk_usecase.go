@@ -2,33 +2,158 @@ package usecase
 
 import (
 	"context"
-	"encoding/json"
 	"errors"
 	"fmt"
+	"log"
+	"math"
+	"sort"
 	"time"
+	"warunk-bem/cache"
 	"warunk-bem/domain"
 	"warunk-bem/dtos"
 	"warunk-bem/helpers"
 
-	"github.com/go-redis/redis/v8"
+	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"golang.org/x/crypto/bcrypt"
 )
 
 type produkUsecase struct {
-	ProdukRepo     domain.ProdukRepository
-	UserRepo       domain.UserRepository
-	RedisClient    *redis.Client
-	contextTimeout time.Duration
+	ProdukRepo        domain.ProdukRepository
+	UserRepo          domain.UserRepository
+	StokRepo          domain.StokRepository
+	Media             domain.ClourdinaryUsecase
+	NotifikasiUsecase domain.NotifikasiUsecase
+	ReviewRepo        domain.ReviewRepository
+	Cache             domain.Cache
+	contextTimeout    time.Duration
 }
 
-func NewProdukUsecase(ProdukRepo domain.ProdukRepository, UserRepo domain.UserRepository, RedisClient *redis.Client, contextTimeout time.Duration) domain.ProdukUsecase {
+const maxProdukImages = 10
+
+func NewProdukUsecase(ProdukRepo domain.ProdukRepository, UserRepo domain.UserRepository, StokRepo domain.StokRepository, Media domain.ClourdinaryUsecase, NotifikasiUsecase domain.NotifikasiUsecase, ReviewRepo domain.ReviewRepository, Cache domain.Cache, contextTimeout time.Duration) domain.ProdukUsecase {
 	return &produkUsecase{
-		ProdukRepo:     ProdukRepo,
-		UserRepo:       UserRepo,
-		RedisClient:    RedisClient,
-		contextTimeout: contextTimeout,
+		ProdukRepo:        ProdukRepo,
+		UserRepo:          UserRepo,
+		StokRepo:          StokRepo,
+		Media:             Media,
+		NotifikasiUsecase: NotifikasiUsecase,
+		ReviewRepo:        ReviewRepo,
+		Cache:             Cache,
+		contextTimeout:    contextTimeout,
+	}
+}
+
+func toProdukImageResponse(image domain.ProdukImage) dtos.ProdukImageResponse {
+	return dtos.ProdukImageResponse{
+		ID:        image.ID.Hex(),
+		URL:       image.URL,
+		Thumbnail: helpers.ImageVariantURL(image.URL, helpers.ImageVariantThumbnail),
+		Medium:    helpers.ImageVariantURL(image.URL, helpers.ImageVariantMedium),
+		Primary:   image.Primary,
+		Order:     image.Order,
+	}
+}
+
+func toProdukDetailResponse(produk *domain.Produk) *dtos.ProdukDetailResponse {
+	images := make([]dtos.ProdukImageResponse, 0, len(produk.Images))
+	for _, image := range produk.Images {
+		images = append(images, toProdukImageResponse(image))
+	}
+
+	// Produk lama hanya punya satu field image
+	if len(images) == 0 && produk.Image != "" {
+		images = append(images, toProdukImageResponse(domain.ProdukImage{URL: produk.Image, Primary: true}))
+	}
+
+	return &dtos.ProdukDetailResponse{
+		ID:               produk.ID.Hex(),
+		Name:             produk.Name,
+		Slug:             produk.Slug,
+		Detail:           produk.Detail,
+		Price:            produk.Price,
+		Stock:            produk.Stock,
+		ReorderThreshold: produk.ReorderThreshold,
+		LowStock:         produk.IsLowStock(),
+		Category:         produk.Category,
+		Image:            produk.Image,
+		Images:           images,
+	}
+}
+
+// withRating mengisi rata-rata rating dan jumlah review, kegagalan hanya dicatat agar produk tetap tampil
+func (pu *produkUsecase) withRating(ctx context.Context, res ...*dtos.ProdukDetailResponse) {
+	ids := make([]primitive.ObjectID, 0, len(res))
+	for _, produk := range res {
+		id, err := primitive.ObjectIDFromHex(produk.ID)
+		if err == nil {
+			ids = append(ids, id)
+		}
+	}
+
+	summary, err := pu.ReviewRepo.GetRatingSummary(ctx, ids)
+	if err != nil {
+		log.Println("cannot get produk rating: ", err.Error())
+		return
+	}
+
+	for _, produk := range res {
+		id, _ := primitive.ObjectIDFromHex(produk.ID)
+		if rating, ok := summary[id]; ok {
+			produk.RatingAverage = math.Round(rating.Average*10) / 10
+			produk.RatingCount = rating.Count
+		}
+	}
+}
+
+// ensureImages memindahkan image lama ke galeri agar bisa diatur ulang
+func ensureImages(produk *domain.Produk) {
+	if len(produk.Images) == 0 && produk.Image != "" {
+		produk.Images = []domain.ProdukImage{
+			{
+				ID:      primitive.NewObjectID(),
+				URL:     produk.Image,
+				Primary: true,
+				Order:   0,
+			},
+		}
+	}
+}
+
+// syncImages merapikan urutan galeri dan menyamakan field image dengan gambar utama
+func syncImages(produk *domain.Produk) {
+	sort.SliceStable(produk.Images, func(i, j int) bool {
+		return produk.Images[i].Order < produk.Images[j].Order
+	})
+
+	primary := -1
+	for i := range produk.Images {
+		produk.Images[i].Order = i
+		if produk.Images[i].Primary {
+			if primary == -1 {
+				primary = i
+			} else {
+				produk.Images[i].Primary = false
+			}
+		}
+	}
+
+	if primary == -1 && len(produk.Images) > 0 {
+		primary = 0
+		produk.Images[0].Primary = true
+	}
+
+	if primary == -1 {
+		produk.Image = ""
+		return
 	}
+
+	produk.Image = produk.Images[primary].URL
+}
+
+// invalidateCache membatalkan cache semua produk dan dashboard, detail dan daftar produk saling bergantung
+func (pu *produkUsecase) invalidateCache(c context.Context) {
+	cache.InvalidateProduk(c, pu.Cache)
 }
 
 // AddProduk godoc
@@ -68,16 +193,25 @@ func (pu *produkUsecase) InsertOne(c context.Context, req *dtos.InsertProdukRequ
 	}
 
 	CreateProduk := &domain.Produk{
-		ID:        req.ID,
-		CreatedAt: req.CreatedAt,
-		UpdatedAt: req.UpdatedAt,
-		Slug:      slug,
-		Name:      req.Name,
-		Detail:    req.Detail,
-		Price:     req.Price,
-		Stock:     req.Stock,
-		Category:  req.Category,
-		Image:     imageUrl,
+		ID:               req.ID,
+		CreatedAt:        req.CreatedAt,
+		UpdatedAt:        req.UpdatedAt,
+		Slug:             slug,
+		Name:             req.Name,
+		Detail:           req.Detail,
+		Price:            req.Price,
+		Stock:            req.Stock,
+		ReorderThreshold: req.ReorderThreshold,
+		Category:         req.Category,
+		Image:            imageUrl,
+		Images: []domain.ProdukImage{
+			{
+				ID:      primitive.NewObjectID(),
+				URL:     imageUrl,
+				Primary: true,
+				Order:   0,
+			},
+		},
 	}
 
 	createdProduk, err := pu.ProdukRepo.InsertOne(ctx, CreateProduk)
@@ -87,21 +221,20 @@ func (pu *produkUsecase) InsertOne(c context.Context, req *dtos.InsertProdukRequ
 
 	fmt.Println(createdProduk)
 
+	pu.recordStok(ctx, domain.NewStokMovement(createdProduk, domain.StokMovementRestock, 0, "stok awal produk", primitive.NilObjectID, primitive.NilObjectID))
+
 	res = &dtos.InsertProdukResponse{
-		Name:     createdProduk.Name,
-		Slug:     createdProduk.Slug,
-		Detail:   createdProduk.Detail,
-		Price:    createdProduk.Price,
-		Stock:    createdProduk.Stock,
-		Category: createdProduk.Category,
-		Image:    createdProduk.Image,
+		Name:             createdProduk.Name,
+		Slug:             createdProduk.Slug,
+		Detail:           createdProduk.Detail,
+		Price:            createdProduk.Price,
+		Stock:            createdProduk.Stock,
+		ReorderThreshold: createdProduk.ReorderThreshold,
+		Category:         createdProduk.Category,
+		Image:            createdProduk.Image,
 	}
 
-	cacheKey := "produk:" + CreateProduk.ID.Hex()
-	cacheValue, err := json.Marshal(CreateProduk)
-	if err == nil {
-		pu.RedisClient.Set(c, cacheKey, cacheValue, 10*time.Minute)
-	}
+	pu.invalidateCache(c)
 
 	return res, nil
 }
@@ -121,43 +254,22 @@ func (pu *produkUsecase) InsertOne(c context.Context, req *dtos.InsertProdukRequ
 // @Failure      500 {object} dtos.InternalServerErrorResponse
 // @Router       /produk/{id} [get]
 func (pu *produkUsecase) FindOne(c context.Context, id string) (res *dtos.ProdukDetailResponse, err error) {
-	// Check if the result exists in Redis cache
-	cacheKey := "produk:" + id
-	val, err := pu.RedisClient.Get(c, cacheKey).Result()
-	if err == nil {
-		// Cache hit, unmarshal the cached value and return it
-		res = &dtos.ProdukDetailResponse{}
-		if err := json.Unmarshal([]byte(val), res); err != nil {
-			return nil, err
-		}
-		return res, nil
-	}
-
-	ctx, cancel := context.WithTimeout(c, pu.contextTimeout)
-	defer cancel()
+	cacheKey := cache.Key(c, pu.Cache, []string{cache.NamespaceProduk}, "detail", id)
 
-	req, err := pu.ProdukRepo.FindOne(ctx, id)
-	if err != nil {
-		return res, err
-	}
+	return cache.Remember(c, pu.Cache, cacheKey, cache.DefaultTTL, func() (*dtos.ProdukDetailResponse, error) {
+		ctx, cancel := context.WithTimeout(c, pu.contextTimeout)
+		defer cancel()
 
-	res = &dtos.ProdukDetailResponse{
-		ID:       req.ID.Hex(),
-		Name:     req.Name,
-		Slug:     req.Slug,
-		Detail:   req.Detail,
-		Price:    req.Price,
-		Stock:    req.Stock,
-		Category: req.Category,
-		Image:    req.Image,
-	}
+		req, err := pu.ProdukRepo.FindOne(ctx, id)
+		if err != nil {
+			return nil, err
+		}
 
-	cacheValue, err := json.Marshal(res)
-	if err == nil {
-		pu.RedisClient.Set(c, cacheKey, cacheValue, 10*time.Minute)
-	}
+		res := toProdukDetailResponse(req)
+		pu.withRating(ctx, res)
 
-	return res, nil
+		return res, nil
+	})
 }
 
 // GetAllProduk godoc
@@ -174,47 +286,35 @@ func (pu *produkUsecase) FindOne(c context.Context, id string) (res *dtos.Produk
 // @Failure      500 {object} dtos.InternalServerErrorResponse
 // @Router       /produk [get]
 func (pu *produkUsecase) GetAllWithPage(c context.Context, rp int64, p int64, filter interface{}, setsort interface{}) ([]*dtos.ProdukDetailResponse, int64, error) {
-	var (
-		res []*dtos.ProdukDetailResponse
-		err error
-	)
+	type produkPage struct {
+		Produk []*dtos.ProdukDetailResponse `json:"produk"`
+		Total  int64                        `json:"total"`
+	}
 
-	cacheKey := "produk"
-	val, err := pu.RedisClient.Get(c, cacheKey).Result()
-	if err == nil {
-		if err := json.Unmarshal([]byte(val), &res); err != nil {
-			return nil, 0, err
+	cacheKey := cache.Key(c, pu.Cache, []string{cache.NamespaceProduk}, "list", rp, p, cache.Hash(filter, setsort))
+
+	page, err := cache.Remember(c, pu.Cache, cacheKey, cache.DefaultTTL, func() (*produkPage, error) {
+		ctx, cancel := context.WithTimeout(c, pu.contextTimeout)
+		defer cancel()
+
+		req, count, err := pu.ProdukRepo.GetAllWithPage(ctx, rp, p, filter, setsort)
+		if err != nil {
+			return nil, err
 		}
-		return res, int64(len(res)), nil
-	}
 
-	ctx, cancel := context.WithTimeout(c, pu.contextTimeout)
-	defer cancel()
+		page := &produkPage{Total: count}
+		for i := range req {
+			page.Produk = append(page.Produk, toProdukDetailResponse(&req[i]))
+		}
+		pu.withRating(ctx, page.Produk...)
 
-	req, count, err := pu.ProdukRepo.GetAllWithPage(ctx, rp, p, filter, setsort)
+		return page, nil
+	})
 	if err != nil {
-		return nil, count, err
-	}
-
-	for _, v := range req {
-		res = append(res, &dtos.ProdukDetailResponse{
-			ID:       v.ID.Hex(),
-			Name:     v.Name,
-			Slug:     v.Slug,
-			Detail:   v.Detail,
-			Price:    v.Price,
-			Stock:    v.Stock,
-			Category: v.Category,
-			Image:    v.Image,
-		})
+		return nil, 0, err
 	}
 
-	cacheValue, err := json.Marshal(res)
-	if err == nil {
-		pu.RedisClient.Set(c, cacheKey, cacheValue, 10*time.Minute)
-	}
-
-	return res, count, nil
+	return page.Produk, page.Total, nil
 }
 
 // ProdukUpdate godoc
@@ -243,36 +343,56 @@ func (pu *produkUsecase) UpdateOne(c context.Context, req *dtos.ProdukUpdateRequ
 		return res, err
 	}
 
+	before := *result
+
 	result.Name = req.Name
 	slug := helpers.CreateSlug(result.Name)
 	result.Slug = slug
 	result.Detail = req.Detail
 	result.Price = req.Price
-	result.Stock = req.Stock
+	stockBefore := result.Stock
 	result.Category = req.Category
-	result.Image = req.Image
+
+	if req.ReorderThreshold < 0 {
+		return res, errors.New("reorder threshold tidak boleh negatif")
+	}
+	result.ReorderThreshold = req.ReorderThreshold
+
+	// Gambar dikelola lewat endpoint galeri, image di sini hanya bisa memilih gambar utama yang sudah ada
+	if req.Image != "" && req.Image != result.Image {
+		ensureImages(result)
+		found := false
+		for i := range result.Images {
+			result.Images[i].Primary = result.Images[i].URL == req.Image
+			found = found || result.Images[i].Primary
+		}
+		if !found {
+			return res, errors.New("image tidak ada di galeri produk")
+		}
+		syncImages(result)
+	}
 
 	resp, err := pu.ProdukRepo.UpdateOne(ctx, result, id)
 	if err != nil {
 		return res, err
 	}
 
-	res = &dtos.ProdukDetailResponse{
-		Name:     resp.Name,
-		Slug:     resp.Slug,
-		Detail:   resp.Detail,
-		Price:    resp.Price,
-		Stock:    resp.Stock,
-		Category: resp.Category,
-		Image:    resp.Image,
-	}
+	// Stok dikoreksi sebagai selisih secara atomik, UpdateOne tidak pernah menulis stok
+	if delta := req.Stock - stockBefore; delta != 0 {
+		updated, err := pu.ProdukRepo.IncrementStock(ctx, id, delta)
+		if err != nil {
+			return res, errors.New("cannot update stock produk")
+		}
 
-	cacheKey := "produk:" + id
-	cacheValue, err := json.Marshal(res)
-	if err == nil {
-		pu.RedisClient.Set(c, cacheKey, cacheValue, 10*time.Minute)
+		resp.Stock = updated.Stock
+		pu.recordStok(ctx, domain.NewStokMovement(resp, domain.StokMovementCorrection, updated.Stock-delta, "update produk", primitive.NilObjectID, primitive.NilObjectID))
 	}
 
+	pu.notifyWishlist(before, *resp)
+	pu.invalidateCache(c)
+
+	res = toProdukDetailResponse(resp)
+
 	return res, nil
 }
 
@@ -310,5 +430,287 @@ func (pu *produkUsecase) DeleteOne(c context.Context, id string, idAdmin string,
 		return res, err
 	}
 
+	pu.invalidateCache(c)
+
 	return res, nil
 }
+
+// AddProdukImages godoc
+// @Summary      Add Produk Images
+// @Description  Upload one or more images to the Produk gallery
+// @Tags         Admin - Produk
+// @Accept       multipart/form-data
+// @Produce      json
+// @Param id path string true "ID Produk"
+// @Param images formData file true "Images"
+// @Success      200 {object} dtos.ProdukOKResponse
+// @Failure      400 {object} dtos.BadRequestResponse
+// @Failure      401 {object} dtos.UnauthorizedResponse
+// @Failure      403 {object} dtos.ForbiddenResponse
+// @Failure      404 {object} dtos.NotFoundResponse
+// @Failure      500 {object} dtos.InternalServerErrorResponse
+// @Router       /produk/{id}/images [post]
+// @Security BearerAuth
+func (pu *produkUsecase) AddImages(c context.Context, id string, urls []string) (*dtos.ProdukDetailResponse, error) {
+	ctx, cancel := context.WithTimeout(c, pu.contextTimeout)
+	defer cancel()
+
+	produk, err := pu.ProdukRepo.FindOne(ctx, id)
+	if err != nil {
+		return nil, errors.New("produk not found")
+	}
+
+	ensureImages(produk)
+
+	if len(produk.Images)+len(urls) > maxProdukImages {
+		return nil, fmt.Errorf("maksimal %d gambar per produk", maxProdukImages)
+	}
+
+	for _, url := range urls {
+		produk.Images = append(produk.Images, domain.ProdukImage{
+			ID:    primitive.NewObjectID(),
+			URL:   url,
+			Order: len(produk.Images),
+		})
+	}
+	syncImages(produk)
+
+	resp, err := pu.ProdukRepo.UpdateOne(ctx, produk, id)
+	if err != nil {
+		return nil, errors.New("cannot add produk images")
+	}
+
+	pu.invalidateCache(c)
+
+	return toProdukDetailResponse(resp), nil
+}
+
+// ReorderProdukImages godoc
+// @Summary      Reorder Produk Images
+// @Description  Reorder Produk gallery, the first image becomes the primary image
+// @Tags         Admin - Produk
+// @Accept       json
+// @Produce      json
+// @Param id path string true "ID Produk"
+// @Param        request body dtos.ReorderProdukImageRequest true "Payload Body [RAW]"
+// @Success      200 {object} dtos.ProdukOKResponse
+// @Failure      400 {object} dtos.BadRequestResponse
+// @Failure      401 {object} dtos.UnauthorizedResponse
+// @Failure      403 {object} dtos.ForbiddenResponse
+// @Failure      404 {object} dtos.NotFoundResponse
+// @Failure      500 {object} dtos.InternalServerErrorResponse
+// @Router       /produk/{id}/images/order [put]
+// @Security BearerAuth
+func (pu *produkUsecase) ReorderImages(c context.Context, id string, req *dtos.ReorderProdukImageRequest) (*dtos.ProdukDetailResponse, error) {
+	ctx, cancel := context.WithTimeout(c, pu.contextTimeout)
+	defer cancel()
+
+	produk, err := pu.ProdukRepo.FindOne(ctx, id)
+	if err != nil {
+		return nil, errors.New("produk not found")
+	}
+
+	ensureImages(produk)
+
+	if len(req.ImageIDs) != len(produk.Images) {
+		return nil, errors.New("image_ids harus berisi semua gambar produk")
+	}
+
+	position := make(map[string]int, len(req.ImageIDs))
+	for i, imageID := range req.ImageIDs {
+		if _, ok := position[imageID]; ok {
+			return nil, fmt.Errorf("image '%s' duplikat", imageID)
+		}
+		position[imageID] = i
+	}
+
+	for i := range produk.Images {
+		order, ok := position[produk.Images[i].ID.Hex()]
+		if !ok {
+			return nil, fmt.Errorf("image '%s' tidak ada di galeri produk", produk.Images[i].ID.Hex())
+		}
+		produk.Images[i].Order = order
+		produk.Images[i].Primary = order == 0
+	}
+	syncImages(produk)
+
+	resp, err := pu.ProdukRepo.UpdateOne(ctx, produk, id)
+	if err != nil {
+		return nil, errors.New("cannot reorder produk images")
+	}
+
+	pu.invalidateCache(c)
+
+	return toProdukDetailResponse(resp), nil
+}
+
+// SetPrimaryProdukImage godoc
+// @Summary      Set Primary Produk Image
+// @Description  Set Primary Produk Image
+// @Tags         Admin - Produk
+// @Accept       json
+// @Produce      json
+// @Param id path string true "ID Produk"
+// @Param imageId path string true "ID Image"
+// @Success      200 {object} dtos.ProdukOKResponse
+// @Failure      400 {object} dtos.BadRequestResponse
+// @Failure      401 {object} dtos.UnauthorizedResponse
+// @Failure      403 {object} dtos.ForbiddenResponse
+// @Failure      404 {object} dtos.NotFoundResponse
+// @Failure      500 {object} dtos.InternalServerErrorResponse
+// @Router       /produk/{id}/images/{imageId}/primary [put]
+// @Security BearerAuth
+func (pu *produkUsecase) SetPrimaryImage(c context.Context, id string, imageID string) (*dtos.ProdukDetailResponse, error) {
+	ctx, cancel := context.WithTimeout(c, pu.contextTimeout)
+	defer cancel()
+
+	produk, err := pu.ProdukRepo.FindOne(ctx, id)
+	if err != nil {
+		return nil, errors.New("produk not found")
+	}
+
+	ensureImages(produk)
+
+	found := false
+	for i := range produk.Images {
+		produk.Images[i].Primary = produk.Images[i].ID.Hex() == imageID
+		found = found || produk.Images[i].Primary
+	}
+
+	if !found {
+		return nil, errors.New("image tidak ada di galeri produk")
+	}
+	syncImages(produk)
+
+	resp, err := pu.ProdukRepo.UpdateOne(ctx, produk, id)
+	if err != nil {
+		return nil, errors.New("cannot update primary image")
+	}
+
+	pu.invalidateCache(c)
+
+	return toProdukDetailResponse(resp), nil
+}
+
+// DeleteProdukImage godoc
+// @Summary      Delete Produk Image
+// @Description  Delete an image from the Produk gallery and from the media storage
+// @Tags         Admin - Produk
+// @Accept       json
+// @Produce      json
+// @Param id path string true "ID Produk"
+// @Param imageId path string true "ID Image"
+// @Success      200 {object} dtos.ProdukOKResponse
+// @Failure      400 {object} dtos.BadRequestResponse
+// @Failure      401 {object} dtos.UnauthorizedResponse
+// @Failure      403 {object} dtos.ForbiddenResponse
+// @Failure      404 {object} dtos.NotFoundResponse
+// @Failure      500 {object} dtos.InternalServerErrorResponse
+// @Router       /produk/{id}/images/{imageId} [delete]
+// @Security BearerAuth
+func (pu *produkUsecase) DeleteImage(c context.Context, id string, imageID string) (*dtos.ProdukDetailResponse, error) {
+	ctx, cancel := context.WithTimeout(c, pu.contextTimeout)
+	defer cancel()
+
+	produk, err := pu.ProdukRepo.FindOne(ctx, id)
+	if err != nil {
+		return nil, errors.New("produk not found")
+	}
+
+	ensureImages(produk)
+
+	index := -1
+	for i, image := range produk.Images {
+		if image.ID.Hex() == imageID {
+			index = i
+			break
+		}
+	}
+
+	if index == -1 {
+		return nil, errors.New("image tidak ada di galeri produk")
+	}
+
+	if len(produk.Images) == 1 {
+		return nil, errors.New("produk harus memiliki minimal satu gambar")
+	}
+
+	removed := produk.Images[index]
+	produk.Images = append(produk.Images[:index], produk.Images[index+1:]...)
+	syncImages(produk)
+
+	resp, err := pu.ProdukRepo.UpdateOne(ctx, produk, id)
+	if err != nil {
+		return nil, errors.New("cannot delete produk image")
+	}
+
+	pu.invalidateCache(c)
+
+	// Kegagalan menghapus file di storage tidak membatalkan perubahan galeri
+	err = pu.Media.Destroy(removed.URL)
+	if err != nil {
+		log.Println("cannot destroy produk image:", err)
+	}
+
+	return toProdukDetailResponse(resp), nil
+}
+
+// GetLowStockProduk godoc
+// @Summary      Get Low Stock Produk
+// @Description  Get produk whose stock is below its reorder threshold
+// @Tags         Admin - Produk
+// @Accept       json
+// @Produce      json
+// @Success      200 {object} dtos.GetAllProdukResponse
+// @Failure      400 {object} dtos.BadRequestResponse
+// @Failure      401 {object} dtos.UnauthorizedResponse
+// @Failure      403 {object} dtos.ForbiddenResponse
+// @Failure      404 {object} dtos.NotFoundResponse
+// @Failure      500 {object} dtos.InternalServerErrorResponse
+// @Router       /produk/low-stock [get]
+// @Security BearerAuth
+func (pu *produkUsecase) GetLowStock(c context.Context, rp int64, p int64) ([]*dtos.ProdukDetailResponse, int64, error) {
+	res := []*dtos.ProdukDetailResponse{}
+
+	ctx, cancel := context.WithTimeout(c, pu.contextTimeout)
+	defer cancel()
+
+	filter := bson.M{
+		"reorder_threshold": bson.M{"$gt": 0},
+		"$expr":             bson.M{"$lt": []interface{}{"$stock", "$reorder_threshold"}},
+	}
+
+	produks, count, err := pu.ProdukRepo.GetAllWithPage(ctx, rp, p, filter, bson.M{"stock": 1})
+	if err != nil {
+		return res, 0, err
+	}
+
+	for i := range produks {
+		res = append(res, toProdukDetailResponse(&produks[i]))
+	}
+	pu.withRating(ctx, res...)
+
+	return res, count, nil
+}
+
+// recordStok mencatat jurnal stok, kegagalan hanya dicatat di log karena stok sudah terlanjur berubah
+func (pu *produkUsecase) recordStok(ctx context.Context, movement *domain.StokMovement) {
+	_, err := pu.StokRepo.InsertOne(ctx, movement)
+	if err != nil {
+		log.Println("cannot record stok movement: ", err.Error())
+	}
+}
+
+// notifyWishlist mengirim notifikasi wishlist di background agar request admin tidak menunggu email
+func (pu *produkUsecase) notifyWishlist(before domain.Produk, after domain.Produk) {
+	if pu.NotifikasiUsecase == nil {
+		return
+	}
+
+	go func() {
+		err := pu.NotifikasiUsecase.NotifyWishlist(context.Background(), &before, &after)
+		if err != nil {
+			log.Println("cannot send wishlist notification: ", err.Error())
+		}
+	}()
+}
@@ -0,0 +1,334 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+	"warunk-bem/cache"
+	"warunk-bem/domain"
+	"warunk-bem/dtos"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type mockProdukRepo struct {
+	domain.ProdukRepository
+
+	produk  *domain.Produk
+	updates int
+}
+
+func (m *mockProdukRepo) FindOne(ctx context.Context, id string) (*domain.Produk, error) {
+	if m.produk == nil || m.produk.ID.Hex() != id {
+		return nil, errors.New("not found")
+	}
+	res := *m.produk
+	res.Images = append([]domain.ProdukImage(nil), m.produk.Images...)
+	return &res, nil
+}
+
+func (m *mockProdukRepo) UpdateOne(ctx context.Context, produk *domain.Produk, id string) (*domain.Produk, error) {
+	m.updates++
+	m.produk = produk
+	return produk, nil
+}
+
+type mockMedia struct {
+	domain.ClourdinaryUsecase
+
+	destroyed []string
+	err       error
+}
+
+func (m *mockMedia) Destroy(url string) error {
+	m.destroyed = append(m.destroyed, url)
+	return m.err
+}
+
+func newGalleryProduk(urls ...string) *domain.Produk {
+	produk := &domain.Produk{ID: primitive.NewObjectID(), Name: "Kopi"}
+	for i, url := range urls {
+		produk.Images = append(produk.Images, domain.ProdukImage{ID: primitive.NewObjectID(), URL: url, Primary: i == 0, Order: i})
+	}
+	if len(urls) > 0 {
+		produk.Image = urls[0]
+	}
+	return produk
+}
+
+func newTestProdukUsecase(repo *mockProdukRepo, media *mockMedia) domain.ProdukUsecase {
+	return NewProdukUsecase(repo, nil, nil, media, nil, nil, cache.NewMemoryCache(), time.Second)
+}
+
+func imageURLs(images []dtos.ProdukImageResponse) []string {
+	urls := make([]string, 0, len(images))
+	for _, image := range images {
+		urls = append(urls, image.URL)
+	}
+	return urls
+}
+
+func equalURLs(a []string, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestAddImages(t *testing.T) {
+	tests := []struct {
+		name      string
+		produk    *domain.Produk
+		urls      []string
+		wantErr   bool
+		wantURLs  []string
+		wantImage string
+	}{
+		{
+			name:      "append to gallery",
+			produk:    newGalleryProduk("a.jpg"),
+			urls:      []string{"b.jpg", "c.jpg"},
+			wantURLs:  []string{"a.jpg", "b.jpg", "c.jpg"},
+			wantImage: "a.jpg",
+		},
+		{
+			name:      "legacy single image moves into gallery",
+			produk:    &domain.Produk{ID: primitive.NewObjectID(), Image: "lama.jpg"},
+			urls:      []string{"baru.jpg"},
+			wantURLs:  []string{"lama.jpg", "baru.jpg"},
+			wantImage: "lama.jpg",
+		},
+		{
+			name:      "first image becomes primary",
+			produk:    &domain.Produk{ID: primitive.NewObjectID()},
+			urls:      []string{"a.jpg"},
+			wantURLs:  []string{"a.jpg"},
+			wantImage: "a.jpg",
+		},
+		{
+			name:    "over limit",
+			produk:  newGalleryProduk("1", "2", "3", "4", "5", "6", "7", "8", "9"),
+			urls:    []string{"10", "11"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &mockProdukRepo{produk: tt.produk}
+			pu := newTestProdukUsecase(repo, &mockMedia{})
+
+			res, err := pu.AddImages(context.Background(), tt.produk.ID.Hex(), tt.urls)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				if repo.updates != 0 {
+					t.Fatalf("repository updated %d times on error", repo.updates)
+				}
+				return
+			}
+
+			if got := imageURLs(res.Images); !equalURLs(got, tt.wantURLs) {
+				t.Fatalf("images = %v, want %v", got, tt.wantURLs)
+			}
+			if repo.produk.Image != tt.wantImage {
+				t.Fatalf("primary image = %q, want %q", repo.produk.Image, tt.wantImage)
+			}
+		})
+	}
+}
+
+func TestReorderImages(t *testing.T) {
+	produk := newGalleryProduk("a.jpg", "b.jpg", "c.jpg")
+	a, b, c := produk.Images[0].ID.Hex(), produk.Images[1].ID.Hex(), produk.Images[2].ID.Hex()
+
+	tests := []struct {
+		name     string
+		imageIDs []string
+		wantErr  bool
+		wantURLs []string
+	}{
+		{name: "reorder", imageIDs: []string{c, a, b}, wantURLs: []string{"c.jpg", "a.jpg", "b.jpg"}},
+		{name: "missing image", imageIDs: []string{c, a}, wantErr: true},
+		{name: "duplicate image", imageIDs: []string{c, c, a}, wantErr: true},
+		{name: "unknown image", imageIDs: []string{c, a, primitive.NewObjectID().Hex()}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// FindOne mengembalikan salinan sehingga produk awal tetap utuh antar kasus
+			repo := &mockProdukRepo{produk: produk}
+			pu := newTestProdukUsecase(repo, &mockMedia{})
+
+			res, err := pu.ReorderImages(context.Background(), produk.ID.Hex(), &dtos.ReorderProdukImageRequest{ImageIDs: tt.imageIDs})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+
+			if got := imageURLs(res.Images); !equalURLs(got, tt.wantURLs) {
+				t.Fatalf("images = %v, want %v", got, tt.wantURLs)
+			}
+			if !res.Images[0].Primary || res.Images[1].Primary || repo.produk.Image != tt.wantURLs[0] {
+				t.Fatalf("first image must be the only primary image, got %+v", res.Images)
+			}
+		})
+	}
+}
+
+func TestSetPrimaryImage(t *testing.T) {
+	repo := &mockProdukRepo{produk: newGalleryProduk("a.jpg", "b.jpg")}
+	pu := newTestProdukUsecase(repo, &mockMedia{})
+	id := repo.produk.ID.Hex()
+
+	_, err := pu.SetPrimaryImage(context.Background(), id, primitive.NewObjectID().Hex())
+	if err == nil {
+		t.Fatal("expected error for unknown image")
+	}
+
+	res, err := pu.SetPrimaryImage(context.Background(), id, repo.produk.Images[1].ID.Hex())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if res.Images[0].Primary || !res.Images[1].Primary || repo.produk.Image != "b.jpg" {
+		t.Fatalf("primary = %+v, image = %q, want b.jpg", res.Images, repo.produk.Image)
+	}
+}
+
+func TestDeleteImage(t *testing.T) {
+	tests := []struct {
+		name          string
+		produk        *domain.Produk
+		index         int
+		destroyErr    error
+		wantErr       bool
+		wantURLs      []string
+		wantImage     string
+		wantDestroyed []string
+	}{
+		{
+			name:          "delete primary promotes next image",
+			produk:        newGalleryProduk("a.jpg", "b.jpg"),
+			index:         0,
+			wantURLs:      []string{"b.jpg"},
+			wantImage:     "b.jpg",
+			wantDestroyed: []string{"a.jpg"},
+		},
+		{
+			name:          "storage failure keeps gallery change",
+			produk:        newGalleryProduk("a.jpg", "b.jpg"),
+			index:         1,
+			destroyErr:    errors.New("storage down"),
+			wantURLs:      []string{"a.jpg"},
+			wantImage:     "a.jpg",
+			wantDestroyed: []string{"b.jpg"},
+		},
+		{
+			name:    "last image",
+			produk:  newGalleryProduk("a.jpg"),
+			index:   0,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &mockProdukRepo{produk: tt.produk}
+			media := &mockMedia{err: tt.destroyErr}
+			pu := newTestProdukUsecase(repo, media)
+
+			res, err := pu.DeleteImage(context.Background(), tt.produk.ID.Hex(), tt.produk.Images[tt.index].ID.Hex())
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				if len(media.destroyed) != 0 {
+					t.Fatalf("destroyed %v on error", media.destroyed)
+				}
+				return
+			}
+
+			if got := imageURLs(res.Images); !equalURLs(got, tt.wantURLs) {
+				t.Fatalf("images = %v, want %v", got, tt.wantURLs)
+			}
+			if repo.produk.Image != tt.wantImage {
+				t.Fatalf("primary image = %q, want %q", repo.produk.Image, tt.wantImage)
+			}
+			if !equalURLs(media.destroyed, tt.wantDestroyed) {
+				t.Fatalf("destroyed = %v, want %v", media.destroyed, tt.wantDestroyed)
+			}
+		})
+	}
+}
+
+// mockLowStockRepo mengembalikan produk apa adanya dan menyimpan filter yang dipakai usecase
+type mockLowStockRepo struct {
+	domain.ProdukRepository
+
+	produks []domain.Produk
+	filter  interface{}
+	sort    interface{}
+}
+
+func (m *mockLowStockRepo) GetAllWithPage(ctx context.Context, rp int64, p int64, filter interface{}, setsort interface{}) ([]domain.Produk, int64, error) {
+	m.filter = filter
+	m.sort = setsort
+	return m.produks, int64(len(m.produks)), nil
+}
+
+type mockReviewRepo struct {
+	domain.ReviewRepository
+
+	summary map[primitive.ObjectID]domain.RatingSummary
+}
+
+func (m *mockReviewRepo) GetRatingSummary(ctx context.Context, produkIDs []primitive.ObjectID) (map[primitive.ObjectID]domain.RatingSummary, error) {
+	return m.summary, nil
+}
+
+func TestGetLowStock(t *testing.T) {
+	kopi := domain.Produk{ID: primitive.NewObjectID(), Name: "Kopi", Stock: 1, ReorderThreshold: 5}
+	teh := domain.Produk{ID: primitive.NewObjectID(), Name: "Teh", Stock: 3, ReorderThreshold: 10}
+
+	repo := &mockLowStockRepo{produks: []domain.Produk{kopi, teh}}
+	reviewRepo := &mockReviewRepo{summary: map[primitive.ObjectID]domain.RatingSummary{
+		kopi.ID: {ProdukID: kopi.ID, Average: 4.26, Count: 3},
+	}}
+	pu := NewProdukUsecase(repo, nil, nil, nil, nil, reviewRepo, cache.NewMemoryCache(), time.Second)
+
+	res, count, err := pu.GetLowStock(context.Background(), 10, 1)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if count != 2 || len(res) != 2 {
+		t.Fatalf("got %d produk (count %d), want 2", len(res), count)
+	}
+	if res[0].ReorderThreshold != 5 || res[0].RatingAverage != 4.3 || res[0].RatingCount != 3 {
+		t.Fatalf("first produk = %+v, want threshold 5 and rating 4.3 from 3 reviews", res[0])
+	}
+
+	// Produk tanpa batas reorder tidak pernah dianggap menipis, hasil diurutkan dari stok terkecil
+	filter, ok := repo.filter.(bson.M)
+	if !ok {
+		t.Fatalf("filter = %T, want bson.M", repo.filter)
+	}
+	if threshold, ok := filter["reorder_threshold"].(bson.M); !ok || threshold["$gt"] != 0 {
+		t.Fatalf("reorder_threshold filter = %v, want $gt 0", filter["reorder_threshold"])
+	}
+	if _, ok := filter["$expr"]; !ok {
+		t.Fatal("filter must compare stock with reorder_threshold")
+	}
+	if sort, ok := repo.sort.(bson.M); !ok || sort["stock"] != 1 {
+		t.Fatalf("sort = %v, want stock ascending", repo.sort)
+	}
+}
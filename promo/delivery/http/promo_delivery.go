@@ -0,0 +1,268 @@
+package http
+
+import (
+	"context"
+	"math"
+	"net/http"
+	"strconv"
+	"warunk-bem/domain"
+	"warunk-bem/dtos"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type PromoHandler struct {
+	PromoUsecase domain.PromoUsecase
+}
+
+func NewPromoHandler(protectedAdmin *gin.RouterGroup, pu domain.PromoUsecase) {
+	handler := &PromoHandler{
+		PromoUsecase: pu,
+	}
+
+	protectedAdmin = protectedAdmin.Group("/promo")
+
+	protectedAdmin.GET("", handler.GetAllWithPage)
+	protectedAdmin.GET("/:id", handler.FindOne)
+	protectedAdmin.POST("", handler.InsertOne)
+	protectedAdmin.PUT("/:id", handler.UpdateOne)
+	protectedAdmin.DELETE("/:id", handler.DeleteOne)
+}
+
+func isRequestValid(m *dtos.InsertPromoRequest) (bool, error) {
+	validate := validator.New()
+	err := validate.Struct(m)
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func (ph *PromoHandler) GetAllWithPage(c *gin.Context) {
+	rp, err := strconv.ParseInt(c.Query("rp"), 10, 64)
+	if err != nil {
+		rp = 25
+	}
+
+	page, err := strconv.ParseInt(c.Query("p"), 10, 64)
+	if err != nil {
+		page = 1
+	}
+
+	filters := bson.D{{Key: "code", Value: primitive.Regex{Pattern: ".*" + c.Query("code") + ".*", Options: "i"}}}
+
+	ctx := c.Request.Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	res, count, err := ph.PromoUsecase.GetAllWithPage(ctx, rp, page, filters, bson.M{"created_at": -1})
+	if err != nil {
+		c.JSON(
+			http.StatusBadRequest,
+			dtos.NewErrorResponse(
+				http.StatusBadRequest,
+				"Cannot Get Promo",
+				err.Error(),
+			),
+		)
+		return
+	}
+
+	result := dtos.GetAllPromoResponse{
+		Total:       count,
+		PerPage:     rp,
+		CurrentPage: page,
+		LastPage:    int64(math.Ceil(float64(count) / float64(rp))),
+		From:        (page * rp) - rp + 1,
+		To:          page * rp,
+		Promo:       res,
+	}
+
+	c.JSON(
+		http.StatusOK,
+		dtos.NewResponse(
+			http.StatusOK,
+			"Success Get Promo",
+			result,
+		),
+	)
+}
+
+func (ph *PromoHandler) FindOne(c *gin.Context) {
+	id := c.Param("id")
+
+	ctx := c.Request.Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	result, err := ph.PromoUsecase.FindOne(ctx, id)
+	if err != nil {
+		c.JSON(
+			http.StatusNotFound,
+			dtos.NewErrorResponse(
+				http.StatusNotFound,
+				"Cannot find Promo",
+				err.Error(),
+			),
+		)
+		return
+	}
+
+	c.JSON(
+		http.StatusOK,
+		dtos.NewResponse(
+			http.StatusOK,
+			"Success find Promo",
+			result,
+		),
+	)
+}
+
+func (ph *PromoHandler) InsertOne(c *gin.Context) {
+	var req dtos.InsertPromoRequest
+
+	err := c.ShouldBindJSON(&req)
+	if err != nil {
+		c.JSON(
+			http.StatusUnprocessableEntity,
+			dtos.NewErrorResponse(
+				http.StatusUnprocessableEntity,
+				"Filed Cannot Be Empty",
+				dtos.GetErrorData(err),
+			),
+		)
+		return
+	}
+
+	if ok, err := isRequestValid(&req); !ok {
+		c.JSON(
+			http.StatusBadRequest,
+			dtos.NewErrorResponse(
+				http.StatusBadRequest,
+				"Bad Request",
+				dtos.GetErrorData(err),
+			),
+		)
+		return
+	}
+
+	ctx := c.Request.Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	result, err := ph.PromoUsecase.InsertOne(ctx, &req)
+	if err != nil {
+		c.JSON(
+			http.StatusBadRequest,
+			dtos.NewErrorResponse(
+				http.StatusBadRequest,
+				"Cannot Insert Promo",
+				dtos.GetErrorData(err),
+			),
+		)
+		return
+	}
+
+	c.JSON(
+		http.StatusCreated,
+		dtos.NewResponse(
+			http.StatusCreated,
+			"Success Insert Promo",
+			result,
+		),
+	)
+}
+
+func (ph *PromoHandler) UpdateOne(c *gin.Context) {
+	var req dtos.InsertPromoRequest
+
+	id := c.Param("id")
+
+	err := c.ShouldBindJSON(&req)
+	if err != nil {
+		c.JSON(
+			http.StatusUnprocessableEntity,
+			dtos.NewErrorResponse(
+				http.StatusUnprocessableEntity,
+				"Filed Cannot Be Empty",
+				dtos.GetErrorData(err),
+			),
+		)
+		return
+	}
+
+	if ok, err := isRequestValid(&req); !ok {
+		c.JSON(
+			http.StatusBadRequest,
+			dtos.NewErrorResponse(
+				http.StatusBadRequest,
+				"Bad Request",
+				dtos.GetErrorData(err),
+			),
+		)
+		return
+	}
+
+	ctx := c.Request.Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	result, err := ph.PromoUsecase.UpdateOne(ctx, &req, id)
+	if err != nil {
+		c.JSON(
+			http.StatusBadRequest,
+			dtos.NewErrorResponse(
+				http.StatusBadRequest,
+				"Cannot Update Promo",
+				err.Error(),
+			),
+		)
+		return
+	}
+
+	c.JSON(
+		http.StatusOK,
+		dtos.NewResponse(
+			http.StatusOK,
+			"Success Update Promo",
+			result,
+		),
+	)
+}
+
+func (ph *PromoHandler) DeleteOne(c *gin.Context) {
+	id := c.Param("id")
+
+	ctx := c.Request.Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	err := ph.PromoUsecase.DeleteOne(ctx, id)
+	if err != nil {
+		c.JSON(
+			http.StatusBadRequest,
+			dtos.NewErrorResponse(
+				http.StatusBadRequest,
+				"Cannot Delete Promo",
+				err.Error(),
+			),
+		)
+		return
+	}
+
+	c.JSON(
+		http.StatusOK,
+		dtos.NewResponseMessage(
+			http.StatusOK,
+			"Success Delete Promo",
+		),
+	)
+}
@@ -0,0 +1,315 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+	"warunk-bem/domain"
+	"warunk-bem/mongo"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	mongodriver "go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type promoRepository struct {
+	DB                  mongo.Database
+	Collection          mongo.Collection
+	UsageCollection     mongo.Collection
+	UserUsageCollection mongo.Collection
+}
+
+const (
+	timeFormat          = "2006-01-02T15:04:05.999Z07:00" // reduce precision from RFC3339Nano as date format
+	collectionName      = "promo"
+	usageCollectionName = "promo_usage"
+	// userUsageCollectionName menyimpan satu penghitung pemakaian per promo dan user
+	userUsageCollectionName = "promo_user_usage"
+)
+
+func NewPromoRepository(DB mongo.Database) domain.PromoRepository {
+	return &promoRepository{DB, DB.Collection(collectionName), DB.Collection(usageCollectionName), DB.Collection(userUsageCollectionName)}
+}
+
+func (r *promoRepository) EnsureIndexes(ctx context.Context) error {
+	// Unique index membuat upsert ReserveUserUsage gagal, bukan membuat dokumen kedua, saat batas user tercapai
+	_, err := r.UserUsageCollection.CreateIndexes(ctx, []mongodriver.IndexModel{
+		{
+			Keys:    bson.D{{Key: "promo_id", Value: 1}, {Key: "user_id", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+	})
+	return err
+}
+
+func (r *promoRepository) InsertOne(ctx context.Context, req *domain.Promo) (*domain.Promo, error) {
+	var err error
+
+	_, err = r.Collection.InsertOne(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	return req, nil
+}
+
+func (r *promoRepository) FindOne(ctx context.Context, id string) (*domain.Promo, error) {
+	var (
+		promo domain.Promo
+		err   error
+	)
+
+	idHex, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return &promo, err
+	}
+
+	err = r.Collection.FindOne(ctx, bson.M{"_id": idHex, "deleted_at": bson.M{"$in": []interface{}{nil, primitive.Null{}}}}).Decode(&promo)
+	if err != nil {
+		return &promo, err
+	}
+
+	return &promo, nil
+}
+
+func (r *promoRepository) FindCode(ctx context.Context, code string) (*domain.Promo, error) {
+	var (
+		promo domain.Promo
+		err   error
+	)
+
+	err = r.Collection.FindOne(ctx, bson.M{"code": code, "deleted_at": bson.M{"$in": []interface{}{nil, primitive.Null{}}}}).Decode(&promo)
+	if err != nil {
+		return nil, err
+	}
+
+	return &promo, nil
+}
+
+func (r *promoRepository) GetAllWithPage(ctx context.Context, rp int64, p int64, filter interface{}, setsort interface{}) ([]domain.Promo, int64, error) {
+	var (
+		promo []domain.Promo
+		err   error
+	)
+
+	nullOrNilCondition := bson.M{"$in": []interface{}{nil, primitive.Null{}}}
+	filterWithDeletedAt := bson.M{
+		"$and": []interface{}{
+			filter,
+			bson.M{"deleted_at": nullOrNilCondition},
+		},
+	}
+
+	findOptions := options.Find()
+	findOptions.SetLimit(rp)
+	findOptions.SetSkip((p - 1) * rp)
+	if setsort != nil {
+		findOptions.SetSort(setsort)
+	}
+
+	cursor, err := r.Collection.Find(ctx, filterWithDeletedAt, findOptions)
+	if err != nil {
+		return promo, 0, err
+	}
+
+	err = cursor.All(ctx, &promo)
+	if err != nil {
+		return promo, 0, err
+	}
+
+	total, err := r.Collection.CountDocuments(ctx, filterWithDeletedAt)
+	if err != nil {
+		return promo, 0, err
+	}
+
+	return promo, total, nil
+}
+
+func (r *promoRepository) UpdateOne(ctx context.Context, promo *domain.Promo, id string) (*domain.Promo, error) {
+	var err error
+
+	idHex, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return promo, err
+	}
+
+	promo.UpdatedAt = time.Now()
+
+	// used_count hanya diubah lewat IncrementUsage / DecrementUsage
+	update := bson.M{"$set": bson.M{
+		"updated_at":     promo.UpdatedAt,
+		"code":           promo.Code,
+		"name":           promo.Name,
+		"type":           promo.Type,
+		"value":          promo.Value,
+		"max_discount":   promo.MaxDiscount,
+		"buy_qty":        promo.BuyQty,
+		"get_qty":        promo.GetQty,
+		"produk_ids":     promo.ProdukIDs,
+		"categories":     promo.Categories,
+		"min_spend":      promo.MinSpend,
+		"start_at":       promo.StartAt,
+		"end_at":         promo.EndAt,
+		"usage_limit":    promo.UsageLimit,
+		"usage_per_user": promo.UsagePerUser,
+		"active":         promo.Active,
+	}}
+
+	_, err = r.Collection.UpdateOne(ctx, bson.M{"_id": idHex}, update)
+	if err != nil {
+		return promo, err
+	}
+
+	err = r.Collection.FindOne(ctx, bson.M{"_id": idHex}).Decode(promo)
+	if err != nil {
+		return promo, err
+	}
+
+	return promo, nil
+}
+
+func (r *promoRepository) DeleteOne(ctx context.Context, id string) error {
+	idHex, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return err
+	}
+
+	filter := bson.M{"_id": idHex}
+	update := bson.M{
+		"$set": bson.M{
+			"deleted_at": time.Now(),
+		},
+	}
+
+	_, err = r.Collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// IncrementUsage menambah used_count secara atomik selama kuota global belum habis
+func (r *promoRepository) IncrementUsage(ctx context.Context, id string) error {
+	idHex, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return err
+	}
+
+	filter := bson.M{
+		"_id": idHex,
+		"$or": []bson.M{
+			{"usage_limit": bson.M{"$lte": 0}},
+			{"$expr": bson.M{"$lt": []interface{}{"$used_count", "$usage_limit"}}},
+		},
+	}
+	update := bson.M{"$inc": bson.M{"used_count": 1}}
+
+	result, err := r.Collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return err
+	}
+
+	if result.MatchedCount == 0 {
+		return errors.New("kuota promo telah habis")
+	}
+
+	return nil
+}
+
+func (r *promoRepository) DecrementUsage(ctx context.Context, id string) error {
+	idHex, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return err
+	}
+
+	filter := bson.M{"_id": idHex, "used_count": bson.M{"$gt": 0}}
+	update := bson.M{"$inc": bson.M{"used_count": -1}}
+
+	_, err = r.Collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (r *promoRepository) InsertUsage(ctx context.Context, req *domain.PromoUsage) (*domain.PromoUsage, error) {
+	var err error
+
+	_, err = r.UsageCollection.InsertOne(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	return req, nil
+}
+
+func (r *promoRepository) CountUsageByUser(ctx context.Context, promoID string, userID string) (int64, error) {
+	promoHex, err := primitive.ObjectIDFromHex(promoID)
+	if err != nil {
+		return 0, err
+	}
+
+	userHex, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return 0, err
+	}
+
+	return r.UsageCollection.CountDocuments(ctx, bson.M{"promo_id": promoHex, "user_id": userHex})
+}
+
+func (r *promoRepository) ReserveUserUsage(ctx context.Context, promoID primitive.ObjectID, userID primitive.ObjectID, maxUsage int64) error {
+	filter := bson.M{"promo_id": promoID, "user_id": userID}
+	if maxUsage > 0 {
+		filter["count"] = bson.M{"$lt": maxUsage}
+	}
+	update := bson.M{
+		"$inc": bson.M{"count": 1},
+		"$set": bson.M{"updated_at": time.Now()},
+	}
+
+	// Dokumen user yang sudah mencapai batas tidak cocok dengan filter, upsert lalu ditolak unique index
+	_, err := r.UserUsageCollection.UpdateOne(ctx, filter, update, options.Update().SetUpsert(true))
+	if mongodriver.IsDuplicateKeyError(err) {
+		return domain.ErrPromoUserLimitReached
+	}
+
+	return err
+}
+
+func (r *promoRepository) ReleaseUserUsage(ctx context.Context, promoID primitive.ObjectID, userID primitive.ObjectID) error {
+	_, err := r.UserUsageCollection.UpdateOne(ctx,
+		bson.M{"promo_id": promoID, "user_id": userID, "count": bson.M{"$gt": 0}},
+		bson.M{
+			"$inc": bson.M{"count": -1},
+			"$set": bson.M{"updated_at": time.Now()},
+		},
+	)
+	return err
+}
+
+func (r *promoRepository) DeleteUsageByTransaksi(ctx context.Context, transaksiID primitive.ObjectID) (*domain.PromoUsage, error) {
+	var usage domain.PromoUsage
+
+	err := r.UsageCollection.FindOne(ctx, bson.M{"transaksi_id": transaksiID}).Decode(&usage)
+	if errors.Is(err, mongodriver.ErrNoDocuments) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// Hanya proses yang berhasil menghapus yang boleh mengembalikan kuota
+	deleted, err := r.UsageCollection.DeleteOne(ctx, bson.M{"_id": usage.ID})
+	if err != nil {
+		return nil, err
+	}
+
+	if deleted == 0 {
+		return nil, nil
+	}
+
+	return &usage, nil
+}
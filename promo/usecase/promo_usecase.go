@@ -0,0 +1,545 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+	"warunk-bem/domain"
+	"warunk-bem/dtos"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type promoUsecase struct {
+	PromoRepo      domain.PromoRepository
+	contextTimeout time.Duration
+}
+
+func NewPromoUsecase(PromoRepo domain.PromoRepository, contextTimeout time.Duration) domain.PromoUsecase {
+	return &promoUsecase{
+		PromoRepo:      PromoRepo,
+		contextTimeout: contextTimeout,
+	}
+}
+
+func normalizeCode(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
+
+func toPromoDetailResponse(promo *domain.Promo) *dtos.PromoDetailResponse {
+	produkIDs := make([]string, 0, len(promo.ProdukIDs))
+	for _, id := range promo.ProdukIDs {
+		produkIDs = append(produkIDs, id.Hex())
+	}
+
+	return &dtos.PromoDetailResponse{
+		ID:           promo.ID.Hex(),
+		Code:         promo.Code,
+		Name:         promo.Name,
+		Type:         promo.Type,
+		Value:        promo.Value,
+		MaxDiscount:  promo.MaxDiscount,
+		BuyQty:       promo.BuyQty,
+		GetQty:       promo.GetQty,
+		ProdukIDs:    produkIDs,
+		Categories:   promo.Categories,
+		MinSpend:     promo.MinSpend,
+		StartAt:      promo.StartAt,
+		EndAt:        promo.EndAt,
+		UsageLimit:   promo.UsageLimit,
+		UsagePerUser: promo.UsagePerUser,
+		UsedCount:    promo.UsedCount,
+		Active:       promo.Active,
+	}
+}
+
+func validatePromoRequest(req *dtos.InsertPromoRequest) error {
+	if !req.EndAt.After(req.StartAt) {
+		return errors.New("end_at harus setelah start_at")
+	}
+
+	switch req.Type {
+	case domain.PromoTypePercentage:
+		if req.Value <= 0 || req.Value > 100 {
+			return errors.New("nilai persentase harus antara 1 sampai 100")
+		}
+	case domain.PromoTypeFixed:
+		if req.Value <= 0 {
+			return errors.New("nilai potongan harus lebih dari 0")
+		}
+	case domain.PromoTypeBuyXGetY:
+		if req.BuyQty <= 0 || req.GetQty <= 0 {
+			return errors.New("buy_qty dan get_qty harus lebih dari 0")
+		}
+	default:
+		return errors.New("tipe promo tidak dikenal")
+	}
+
+	return nil
+}
+
+func parseProdukIDs(ids []string) ([]primitive.ObjectID, error) {
+	produkIDs := make([]primitive.ObjectID, 0, len(ids))
+	for _, id := range ids {
+		idHex, err := primitive.ObjectIDFromHex(id)
+		if err != nil {
+			return nil, fmt.Errorf("produk id '%s' tidak valid", id)
+		}
+		produkIDs = append(produkIDs, idHex)
+	}
+
+	return produkIDs, nil
+}
+
+// AddPromo godoc
+// @Summary      Add Promo
+// @Description  Add Promo
+// @Tags         Admin - Promo
+// @Accept       json
+// @Produce      json
+// @Param        request body dtos.InsertPromoRequest true "Payload Body [RAW]"
+// @Success      201 {object} dtos.PromoOKResponse
+// @Failure      400 {object} dtos.BadRequestResponse
+// @Failure      401 {object} dtos.UnauthorizedResponse
+// @Failure      403 {object} dtos.ForbiddenResponse
+// @Failure      404 {object} dtos.NotFoundResponse
+// @Failure      500 {object} dtos.InternalServerErrorResponse
+// @Router       /promo [post]
+// @Security BearerAuth
+func (pu *promoUsecase) InsertOne(c context.Context, req *dtos.InsertPromoRequest) (*dtos.PromoDetailResponse, error) {
+	ctx, cancel := context.WithTimeout(c, pu.contextTimeout)
+	defer cancel()
+
+	err := validatePromoRequest(req)
+	if err != nil {
+		return nil, err
+	}
+
+	code := normalizeCode(req.Code)
+	_, err = pu.PromoRepo.FindCode(ctx, code)
+	if err == nil {
+		return nil, errors.New("kode promo sudah digunakan")
+	}
+
+	produkIDs, err := parseProdukIDs(req.ProdukIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	promo := &domain.Promo{
+		ID:           primitive.NewObjectID(),
+		CreatedAt:    time.Now(),
+		UpdatedAt:    time.Now(),
+		Code:         code,
+		Name:         req.Name,
+		Type:         req.Type,
+		Value:        req.Value,
+		MaxDiscount:  req.MaxDiscount,
+		BuyQty:       req.BuyQty,
+		GetQty:       req.GetQty,
+		ProdukIDs:    produkIDs,
+		Categories:   req.Categories,
+		MinSpend:     req.MinSpend,
+		StartAt:      req.StartAt,
+		EndAt:        req.EndAt,
+		UsageLimit:   req.UsageLimit,
+		UsagePerUser: req.UsagePerUser,
+		Active:       req.Active,
+	}
+
+	created, err := pu.PromoRepo.InsertOne(ctx, promo)
+	if err != nil {
+		return nil, errors.New("failed to create promo")
+	}
+
+	return toPromoDetailResponse(created), nil
+}
+
+// GetPromoByID godoc
+// @Summary      Get Promo by ID
+// @Description  Get Promo by ID
+// @Tags         Admin - Promo
+// @Accept       json
+// @Produce      json
+// @Param id path string true "ID Promo"
+// @Success      200 {object} dtos.PromoOKResponse
+// @Failure      400 {object} dtos.BadRequestResponse
+// @Failure      401 {object} dtos.UnauthorizedResponse
+// @Failure      403 {object} dtos.ForbiddenResponse
+// @Failure      404 {object} dtos.NotFoundResponse
+// @Failure      500 {object} dtos.InternalServerErrorResponse
+// @Router       /promo/{id} [get]
+// @Security BearerAuth
+func (pu *promoUsecase) FindOne(c context.Context, id string) (*dtos.PromoDetailResponse, error) {
+	ctx, cancel := context.WithTimeout(c, pu.contextTimeout)
+	defer cancel()
+
+	promo, err := pu.PromoRepo.FindOne(ctx, id)
+	if err != nil {
+		return nil, errors.New("promo not found")
+	}
+
+	return toPromoDetailResponse(promo), nil
+}
+
+// GetAllPromo godoc
+// @Summary      Get All Promo
+// @Description  Get All Promo
+// @Tags         Admin - Promo
+// @Accept       json
+// @Produce      json
+// @Success      200 {object} dtos.GetAllPromoResponse
+// @Failure      400 {object} dtos.BadRequestResponse
+// @Failure      401 {object} dtos.UnauthorizedResponse
+// @Failure      403 {object} dtos.ForbiddenResponse
+// @Failure      404 {object} dtos.NotFoundResponse
+// @Failure      500 {object} dtos.InternalServerErrorResponse
+// @Router       /promo [get]
+// @Security BearerAuth
+func (pu *promoUsecase) GetAllWithPage(c context.Context, rp int64, p int64, filter interface{}, setsort interface{}) ([]*dtos.PromoDetailResponse, int64, error) {
+	var res []*dtos.PromoDetailResponse
+
+	ctx, cancel := context.WithTimeout(c, pu.contextTimeout)
+	defer cancel()
+
+	promos, count, err := pu.PromoRepo.GetAllWithPage(ctx, rp, p, filter, setsort)
+	if err != nil {
+		return nil, count, err
+	}
+
+	for i := range promos {
+		res = append(res, toPromoDetailResponse(&promos[i]))
+	}
+
+	return res, count, nil
+}
+
+// PromoUpdate godoc
+// @Summary      Update Promo
+// @Description  Update Promo
+// @Tags         Admin - Promo
+// @Accept       json
+// @Produce      json
+// @Param id path string true "ID Promo"
+// @Param        request body dtos.InsertPromoRequest true "Payload Body [RAW]"
+// @Success      200 {object} dtos.PromoOKResponse
+// @Failure      400 {object} dtos.BadRequestResponse
+// @Failure      401 {object} dtos.UnauthorizedResponse
+// @Failure      403 {object} dtos.ForbiddenResponse
+// @Failure      404 {object} dtos.NotFoundResponse
+// @Failure      500 {object} dtos.InternalServerErrorResponse
+// @Router       /promo/{id} [put]
+// @Security BearerAuth
+func (pu *promoUsecase) UpdateOne(c context.Context, req *dtos.InsertPromoRequest, id string) (*dtos.PromoDetailResponse, error) {
+	ctx, cancel := context.WithTimeout(c, pu.contextTimeout)
+	defer cancel()
+
+	err := validatePromoRequest(req)
+	if err != nil {
+		return nil, err
+	}
+
+	promo, err := pu.PromoRepo.FindOne(ctx, id)
+	if err != nil {
+		return nil, errors.New("promo not found")
+	}
+
+	code := normalizeCode(req.Code)
+	if code != promo.Code {
+		_, err = pu.PromoRepo.FindCode(ctx, code)
+		if err == nil {
+			return nil, errors.New("kode promo sudah digunakan")
+		}
+	}
+
+	produkIDs, err := parseProdukIDs(req.ProdukIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	promo.Code = code
+	promo.Name = req.Name
+	promo.Type = req.Type
+	promo.Value = req.Value
+	promo.MaxDiscount = req.MaxDiscount
+	promo.BuyQty = req.BuyQty
+	promo.GetQty = req.GetQty
+	promo.ProdukIDs = produkIDs
+	promo.Categories = req.Categories
+	promo.MinSpend = req.MinSpend
+	promo.StartAt = req.StartAt
+	promo.EndAt = req.EndAt
+	promo.UsageLimit = req.UsageLimit
+	promo.UsagePerUser = req.UsagePerUser
+	promo.Active = req.Active
+
+	updated, err := pu.PromoRepo.UpdateOne(ctx, promo, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return toPromoDetailResponse(updated), nil
+}
+
+// DeletePromo godoc
+// @Summary      Delete a Promo
+// @Description  Delete a Promo
+// @Tags         Admin - Promo
+// @Accept       json
+// @Produce      json
+// @Param id path string true "ID Promo"
+// @Success      200 {object} dtos.StatusOKDeletedResponse
+// @Failure      400 {object} dtos.BadRequestResponse
+// @Failure      401 {object} dtos.UnauthorizedResponse
+// @Failure      403 {object} dtos.ForbiddenResponse
+// @Failure      404 {object} dtos.NotFoundResponse
+// @Failure      500 {object} dtos.InternalServerErrorResponse
+// @Router       /promo/{id} [delete]
+// @Security BearerAuth
+func (pu *promoUsecase) DeleteOne(c context.Context, id string) error {
+	ctx, cancel := context.WithTimeout(c, pu.contextTimeout)
+	defer cancel()
+
+	_, err := pu.PromoRepo.FindOne(ctx, id)
+	if err != nil {
+		return errors.New("promo not found")
+	}
+
+	return pu.PromoRepo.DeleteOne(ctx, id)
+}
+
+func (pu *promoUsecase) isEligible(promo *domain.Promo, item domain.PromoItem) bool {
+	if len(promo.ProdukIDs) == 0 && len(promo.Categories) == 0 {
+		return true
+	}
+
+	for _, id := range promo.ProdukIDs {
+		if id == item.ProdukID {
+			return true
+		}
+	}
+
+	for _, category := range promo.Categories {
+		if strings.EqualFold(category, item.Category) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Apply menghitung potongan dari kode promo untuk daftar belanja tanpa menandai promo sebagai terpakai
+func (pu *promoUsecase) Apply(c context.Context, code string, userID string, items []domain.PromoItem) (*domain.PromoResult, error) {
+	ctx, cancel := context.WithTimeout(c, pu.contextTimeout)
+	defer cancel()
+
+	promo, err := pu.PromoRepo.FindCode(ctx, normalizeCode(code))
+	if err != nil {
+		return nil, errors.New("kode promo tidak ditemukan")
+	}
+
+	if !promo.Active {
+		return nil, errors.New("promo tidak aktif")
+	}
+
+	now := time.Now()
+	if now.Before(promo.StartAt) {
+		return nil, errors.New("promo belum berlaku")
+	}
+
+	if now.After(promo.EndAt) {
+		return nil, errors.New("promo sudah berakhir")
+	}
+
+	if promo.UsageLimit > 0 && promo.UsedCount >= promo.UsageLimit {
+		return nil, errors.New("kuota promo telah habis")
+	}
+
+	if promo.UsagePerUser > 0 {
+		used, err := pu.PromoRepo.CountUsageByUser(ctx, promo.ID.Hex(), userID)
+		if err != nil {
+			return nil, errors.New("cannot check promo usage")
+		}
+
+		if used >= promo.UsagePerUser {
+			return nil, domain.ErrPromoUserLimitReached
+		}
+	}
+
+	var (
+		subtotal         int64
+		eligibleSubtotal int64
+		eligible         []domain.PromoItem
+	)
+
+	for _, item := range items {
+		subtotal += item.Price * item.Qty
+		if pu.isEligible(promo, item) {
+			eligible = append(eligible, item)
+			eligibleSubtotal += item.Price * item.Qty
+		}
+	}
+
+	if len(eligible) == 0 {
+		return nil, errors.New("promo tidak berlaku untuk produk yang dibeli")
+	}
+
+	if subtotal < promo.MinSpend {
+		return nil, fmt.Errorf("minimal belanja untuk promo ini adalah %d", promo.MinSpend)
+	}
+
+	lineDiscount := make(map[primitive.ObjectID]int64)
+
+	switch promo.Type {
+	case domain.PromoTypeBuyXGetY:
+		// Setiap kelipatan (buy + get) unit, sebanyak get unit gratis
+		bundle := promo.BuyQty + promo.GetQty
+		for _, item := range eligible {
+			free := (item.Qty / bundle) * promo.GetQty
+			if free > 0 {
+				lineDiscount[item.ProdukID] += free * item.Price
+			}
+		}
+	case domain.PromoTypePercentage:
+		total := eligibleSubtotal * promo.Value / 100
+		if promo.MaxDiscount > 0 && total > promo.MaxDiscount {
+			total = promo.MaxDiscount
+		}
+		distributeDiscount(lineDiscount, eligible, eligibleSubtotal, total)
+	case domain.PromoTypeFixed:
+		total := promo.Value
+		if total > eligibleSubtotal {
+			total = eligibleSubtotal
+		}
+		distributeDiscount(lineDiscount, eligible, eligibleSubtotal, total)
+	default:
+		return nil, errors.New("tipe promo tidak dikenal")
+	}
+
+	var discount int64
+	for _, v := range lineDiscount {
+		discount += v
+	}
+
+	if discount <= 0 {
+		return nil, errors.New("promo tidak memberikan potongan untuk belanjaan ini")
+	}
+
+	userHex, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &domain.PromoResult{
+		PromoID:      promo.ID,
+		UserID:       userHex,
+		UsagePerUser: promo.UsagePerUser,
+		Code:         promo.Code,
+		Discount:     discount,
+		LineDiscount: lineDiscount,
+	}, nil
+}
+
+// distributeDiscount membagi potongan ke setiap baris sebanding dengan subtotal baris
+func distributeDiscount(lineDiscount map[primitive.ObjectID]int64, eligible []domain.PromoItem, eligibleSubtotal int64, total int64) {
+	if eligibleSubtotal <= 0 || total <= 0 {
+		return
+	}
+
+	remaining := total
+	for i, item := range eligible {
+		share := total * item.Price * item.Qty / eligibleSubtotal
+		if i == len(eligible)-1 {
+			share = remaining
+		}
+		lineDiscount[item.ProdukID] += share
+		remaining -= share
+	}
+}
+
+// Redeem mengambil jatah user lalu satu kuota global promo secara atomik sebelum saldo dipotong,
+// pengecekan di Apply hanya untuk pesan awal karena checkout bersamaan bisa lolos bersama
+func (pu *promoUsecase) Redeem(c context.Context, result *domain.PromoResult) error {
+	ctx, cancel := context.WithTimeout(c, pu.contextTimeout)
+	defer cancel()
+
+	err := pu.PromoRepo.ReserveUserUsage(ctx, result.PromoID, result.UserID, result.UsagePerUser)
+	if err != nil {
+		return err
+	}
+
+	err = pu.PromoRepo.IncrementUsage(ctx, result.PromoID.Hex())
+	if err != nil {
+		pu.releaseUserUsage(ctx, result.PromoID, result.UserID)
+		return err
+	}
+
+	return nil
+}
+
+// Release mengembalikan kuota promo dan jatah user jika checkout gagal setelah Redeem
+func (pu *promoUsecase) Release(c context.Context, result *domain.PromoResult) error {
+	ctx, cancel := context.WithTimeout(c, pu.contextTimeout)
+	defer cancel()
+
+	pu.releaseUserUsage(ctx, result.PromoID, result.UserID)
+
+	return pu.PromoRepo.DecrementUsage(ctx, result.PromoID.Hex())
+}
+
+// releaseUserUsage kegagalan hanya dicatat di log, jatah user yang tertahan lebih aman daripada kuota ganda
+func (pu *promoUsecase) releaseUserUsage(ctx context.Context, promoID primitive.ObjectID, userID primitive.ObjectID) {
+	err := pu.PromoRepo.ReleaseUserUsage(ctx, promoID, userID)
+	if err != nil {
+		log.Println("cannot release promo user usage: ", err.Error())
+	}
+}
+
+func (pu *promoUsecase) RecordUsage(c context.Context, result *domain.PromoResult, userID string, transaksiID primitive.ObjectID) error {
+	ctx, cancel := context.WithTimeout(c, pu.contextTimeout)
+	defer cancel()
+
+	userHex, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return err
+	}
+
+	_, err = pu.PromoRepo.InsertUsage(ctx, &domain.PromoUsage{
+		ID:          primitive.NewObjectID(),
+		CreatedAt:   time.Now(),
+		PromoID:     result.PromoID,
+		UserID:      userHex,
+		TransaksiID: transaksiID,
+		Discount:    result.Discount,
+	})
+
+	return err
+}
+
+func (pu *promoUsecase) RevertUsage(c context.Context, transaksiIDs []primitive.ObjectID) error {
+	ctx, cancel := context.WithTimeout(c, pu.contextTimeout)
+	defer cancel()
+
+	for _, transaksiID := range transaksiIDs {
+		usage, err := pu.PromoRepo.DeleteUsageByTransaksi(ctx, transaksiID)
+		if err != nil {
+			return err
+		}
+
+		if usage == nil {
+			continue
+		}
+
+		pu.releaseUserUsage(ctx, usage.PromoID, usage.UserID)
+
+		err = pu.PromoRepo.DecrementUsage(ctx, usage.PromoID.Hex())
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,310 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+	"warunk-bem/domain"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// mockPromoRepo hanya mengimplementasikan method yang dipakai test, method lain panic lewat interface kosong
+type mockPromoRepo struct {
+	domain.PromoRepository
+
+	mu         sync.Mutex
+	promo      *domain.Promo
+	userUsage  int64
+	usages     map[primitive.ObjectID]*domain.PromoUsage
+	decrements int
+	// reserved meniru penghitung promo_user_usage per user
+	reserved map[primitive.ObjectID]int64
+}
+
+func (m *mockPromoRepo) FindCode(ctx context.Context, code string) (*domain.Promo, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.promo == nil || m.promo.Code != code {
+		return nil, errors.New("not found")
+	}
+	copied := *m.promo
+	return &copied, nil
+}
+
+func (m *mockPromoRepo) CountUsageByUser(ctx context.Context, promoID string, userID string) (int64, error) {
+	return m.userUsage, nil
+}
+
+func (m *mockPromoRepo) DeleteUsageByTransaksi(ctx context.Context, transaksiID primitive.ObjectID) (*domain.PromoUsage, error) {
+	usage, ok := m.usages[transaksiID]
+	if !ok {
+		return nil, nil
+	}
+	delete(m.usages, transaksiID)
+	return usage, nil
+}
+
+func (m *mockPromoRepo) IncrementUsage(ctx context.Context, id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.promo.UsageLimit > 0 && m.promo.UsedCount >= m.promo.UsageLimit {
+		return errors.New("kuota promo telah habis")
+	}
+	m.promo.UsedCount++
+	return nil
+}
+
+func (m *mockPromoRepo) DecrementUsage(ctx context.Context, id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.decrements++
+	if m.promo != nil && m.promo.UsedCount > 0 {
+		m.promo.UsedCount--
+	}
+	return nil
+}
+
+func (m *mockPromoRepo) ReserveUserUsage(ctx context.Context, promoID primitive.ObjectID, userID primitive.ObjectID, maxUsage int64) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if maxUsage > 0 && m.reserved[userID] >= maxUsage {
+		return domain.ErrPromoUserLimitReached
+	}
+	m.reserved[userID]++
+	return nil
+}
+
+func (m *mockPromoRepo) ReleaseUserUsage(ctx context.Context, promoID primitive.ObjectID, userID primitive.ObjectID) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.reserved[userID] > 0 {
+		m.reserved[userID]--
+	}
+	return nil
+}
+
+func TestApply(t *testing.T) {
+	produkA := primitive.NewObjectID()
+	produkB := primitive.NewObjectID()
+	now := time.Now()
+
+	newPromo := func(edit func(p *domain.Promo)) *domain.Promo {
+		p := &domain.Promo{
+			ID:      primitive.NewObjectID(),
+			Code:    "HEMAT",
+			Type:    domain.PromoTypePercentage,
+			Value:   10,
+			StartAt: now.Add(-time.Hour),
+			EndAt:   now.Add(time.Hour),
+			Active:  true,
+		}
+		if edit != nil {
+			edit(p)
+		}
+		return p
+	}
+
+	items := []domain.PromoItem{
+		{ProdukID: produkA, Category: "minuman", Price: 5000, Qty: 2},
+		{ProdukID: produkB, Category: "makanan", Price: 10000, Qty: 1},
+	}
+
+	tests := []struct {
+		name      string
+		code      string
+		promo     *domain.Promo
+		userUsage int64
+		wantErr   bool
+		want      int64
+	}{
+		{name: "percentage", code: "hemat ", promo: newPromo(nil), want: 2000},
+		{name: "percentage capped by max discount", code: "HEMAT", promo: newPromo(func(p *domain.Promo) { p.MaxDiscount = 1500 }), want: 1500},
+		{name: "fixed capped by eligible subtotal", code: "HEMAT", promo: newPromo(func(p *domain.Promo) {
+			p.Type = domain.PromoTypeFixed
+			p.Value = 50000
+			p.ProdukIDs = []primitive.ObjectID{produkA}
+		}), want: 10000},
+		{name: "buy 1 get 1", code: "HEMAT", promo: newPromo(func(p *domain.Promo) {
+			p.Type = domain.PromoTypeBuyXGetY
+			p.BuyQty = 1
+			p.GetQty = 1
+		}), want: 5000},
+		{name: "unknown code", code: "LAIN", promo: newPromo(nil), wantErr: true},
+		{name: "inactive", code: "HEMAT", promo: newPromo(func(p *domain.Promo) { p.Active = false }), wantErr: true},
+		{name: "not started", code: "HEMAT", promo: newPromo(func(p *domain.Promo) { p.StartAt = now.Add(time.Hour) }), wantErr: true},
+		{name: "ended", code: "HEMAT", promo: newPromo(func(p *domain.Promo) { p.EndAt = now.Add(-time.Minute) }), wantErr: true},
+		{name: "global limit reached", code: "HEMAT", promo: newPromo(func(p *domain.Promo) {
+			p.UsageLimit = 10
+			p.UsedCount = 10
+		}), wantErr: true},
+		{name: "per user limit reached", code: "HEMAT", promo: newPromo(func(p *domain.Promo) { p.UsagePerUser = 1 }), userUsage: 1, wantErr: true},
+		{name: "per user limit not reached", code: "HEMAT", promo: newPromo(func(p *domain.Promo) { p.UsagePerUser = 2 }), userUsage: 1, want: 2000},
+		{name: "below min spend", code: "HEMAT", promo: newPromo(func(p *domain.Promo) { p.MinSpend = 50000 }), wantErr: true},
+		{name: "no eligible item", code: "HEMAT", promo: newPromo(func(p *domain.Promo) { p.Categories = []string{"snack"} }), wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &mockPromoRepo{promo: tt.promo, userUsage: tt.userUsage}
+			pu := NewPromoUsecase(repo, time.Second)
+
+			res, err := pu.Apply(context.Background(), tt.code, primitive.NewObjectID().Hex(), items)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("expected error, got discount %d", res.Discount)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if res.Discount != tt.want {
+				t.Errorf("discount = %d, want %d", res.Discount, tt.want)
+			}
+
+			var lines int64
+			for _, v := range res.LineDiscount {
+				lines += v
+			}
+			if lines != res.Discount {
+				t.Errorf("line discount sum = %d, want %d", lines, res.Discount)
+			}
+		})
+	}
+}
+
+func TestRevertUsage(t *testing.T) {
+	promoID := primitive.NewObjectID()
+	withPromo := primitive.NewObjectID()
+	withoutPromo := primitive.NewObjectID()
+
+	userID := primitive.NewObjectID()
+	repo := &mockPromoRepo{
+		usages: map[primitive.ObjectID]*domain.PromoUsage{
+			withPromo: {ID: primitive.NewObjectID(), PromoID: promoID, UserID: userID, TransaksiID: withPromo},
+		},
+		reserved: map[primitive.ObjectID]int64{userID: 1},
+	}
+	pu := NewPromoUsecase(repo, time.Second)
+
+	// Kasus dijalankan berurutan pada repo yang sama, refund kedua tidak boleh mengembalikan kuota lagi
+	tests := []struct {
+		name           string
+		transaksiIDs   []primitive.ObjectID
+		wantDecrements int
+		wantReserved   int64
+	}{
+		{name: "usage recorded", transaksiIDs: []primitive.ObjectID{withPromo, withoutPromo}, wantDecrements: 1},
+		{name: "already reverted", transaksiIDs: []primitive.ObjectID{withPromo}, wantDecrements: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := pu.RevertUsage(context.Background(), tt.transaksiIDs)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if repo.decrements != tt.wantDecrements {
+				t.Errorf("decrements = %d, want %d", repo.decrements, tt.wantDecrements)
+			}
+			if got := repo.reserved[userID]; got != tt.wantReserved {
+				t.Errorf("user usage = %d, want %d", got, tt.wantReserved)
+			}
+		})
+	}
+}
+
+func TestRedeem(t *testing.T) {
+	userID := primitive.NewObjectID()
+
+	tests := []struct {
+		name         string
+		usagePerUser int64
+		usageLimit   int64
+		usedCount    int64
+		userUsed     int64
+		wantErr      bool
+		wantUserUsed int64
+		wantUsed     int64
+	}{
+		{name: "reserves user and global quota", usagePerUser: 2, userUsed: 1, wantUserUsed: 2, wantUsed: 1},
+		{name: "unlimited per user is still counted", wantUserUsed: 1, wantUsed: 1},
+		{name: "per user limit reached", usagePerUser: 1, userUsed: 1, wantErr: true, wantUserUsed: 1},
+		{name: "global quota gone releases user slot", usagePerUser: 1, usageLimit: 5, usedCount: 5, wantErr: true, wantUsed: 5},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			promo := &domain.Promo{ID: primitive.NewObjectID(), UsageLimit: tt.usageLimit, UsedCount: tt.usedCount}
+			repo := &mockPromoRepo{promo: promo, reserved: map[primitive.ObjectID]int64{userID: tt.userUsed}}
+			pu := NewPromoUsecase(repo, time.Second)
+
+			err := pu.Redeem(context.Background(), &domain.PromoResult{PromoID: promo.ID, UserID: userID, UsagePerUser: tt.usagePerUser})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if got := repo.reserved[userID]; got != tt.wantUserUsed {
+				t.Errorf("user usage = %d, want %d", got, tt.wantUserUsed)
+			}
+			if promo.UsedCount != tt.wantUsed {
+				t.Errorf("used count = %d, want %d", promo.UsedCount, tt.wantUsed)
+			}
+		})
+	}
+}
+
+func TestRedeemConcurrentPerUser(t *testing.T) {
+	now := time.Now()
+	promo := &domain.Promo{
+		ID:           primitive.NewObjectID(),
+		Code:         "SEKALI",
+		Type:         domain.PromoTypeFixed,
+		Value:        1000,
+		StartAt:      now.Add(-time.Hour),
+		EndAt:        now.Add(time.Hour),
+		Active:       true,
+		UsagePerUser: 1,
+	}
+	repo := &mockPromoRepo{promo: promo, reserved: map[primitive.ObjectID]int64{}}
+	pu := NewPromoUsecase(repo, time.Second)
+	userID := primitive.NewObjectID().Hex()
+	items := []domain.PromoItem{{ProdukID: primitive.NewObjectID(), Price: 5000, Qty: 1}}
+
+	// Semua checkout lolos Apply karena pemakaian baru dicatat setelah transaksi, hanya satu yang boleh lolos Redeem
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		redeemed int
+	)
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			res, err := pu.Apply(context.Background(), "SEKALI", userID, items)
+			if err != nil {
+				return
+			}
+			if pu.Redeem(context.Background(), res) == nil {
+				mu.Lock()
+				redeemed++
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	if redeemed != 1 || promo.UsedCount != 1 {
+		t.Errorf("redeemed = %d (used count %d), want exactly one", redeemed, promo.UsedCount)
+	}
+}
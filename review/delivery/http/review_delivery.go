@@ -0,0 +1,336 @@
+package http
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"warunk-bem/domain"
+	"warunk-bem/dtos"
+	"warunk-bem/helpers"
+	"warunk-bem/middlewares"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type ReviewHandler struct {
+	ReviewUsecase domain.ReviewUsecase
+}
+
+func NewReviewHandler(router *gin.RouterGroup, protected *gin.RouterGroup, protectedAdmin *gin.RouterGroup, ru domain.ReviewUsecase) {
+	handler := &ReviewHandler{
+		ReviewUsecase: ru,
+	}
+
+	router.GET("/produk/:id/review", handler.GetByProduk)
+
+	protected.POST("/review", handler.InsertOne)
+	protected.GET("/review/pending", handler.GetPending)
+
+	protectedAdmin.GET("/review", handler.GetAllWithPage)
+	protectedAdmin.PUT("/review/:id/hide", handler.Hide)
+	protectedAdmin.PUT("/review/:id/unhide", handler.Unhide)
+}
+
+func isRequestValid(m interface{}) (bool, error) {
+	validate := validator.New()
+	err := validate.Struct(m)
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func reviewPage(res []*dtos.ReviewResponse, count int64, rp int64, page int64) dtos.GetAllReviewResponse {
+	return dtos.GetAllReviewResponse{
+		Total:       count,
+		PerPage:     rp,
+		CurrentPage: page,
+		LastPage:    int64(math.Ceil(float64(count) / float64(rp))),
+		From:        (page * rp) - rp + 1,
+		To:          page * rp,
+		Review:      res,
+	}
+}
+
+func (rh *ReviewHandler) InsertOne(c *gin.Context) {
+	var req dtos.InsertReviewRequest
+
+	idUser, err := middlewares.IsUser(c)
+	if err != nil {
+		c.JSON(
+			http.StatusUnauthorized,
+			dtos.NewErrorResponse(
+				http.StatusUnauthorized,
+				"Please login first to access this pages",
+				dtos.GetErrorData(err),
+			),
+		)
+		return
+	}
+
+	err = c.ShouldBindJSON(&req)
+	if err != nil {
+		c.JSON(
+			http.StatusUnprocessableEntity,
+			dtos.NewErrorResponse(
+				http.StatusUnprocessableEntity,
+				"Filed Cannot Be Empty",
+				dtos.GetErrorData(err),
+			),
+		)
+		return
+	}
+
+	if ok, err := isRequestValid(&req); !ok {
+		c.JSON(
+			http.StatusBadRequest,
+			dtos.NewErrorResponse(
+				http.StatusBadRequest,
+				"Bad Request",
+				dtos.GetErrorData(err),
+			),
+		)
+		return
+	}
+
+	result, err := rh.ReviewUsecase.InsertOne(helpers.RequestContext(c), idUser, &req)
+	if err != nil {
+		c.JSON(
+			http.StatusBadRequest,
+			dtos.NewErrorResponse(
+				http.StatusBadRequest,
+				"Cannot Insert Review",
+				err.Error(),
+			),
+		)
+		return
+	}
+
+	c.JSON(
+		http.StatusCreated,
+		dtos.NewResponse(
+			http.StatusCreated,
+			"Success Insert Review",
+			result,
+		),
+	)
+}
+
+func (rh *ReviewHandler) GetPending(c *gin.Context) {
+	idUser, err := middlewares.IsUser(c)
+	if err != nil {
+		c.JSON(
+			http.StatusUnauthorized,
+			dtos.NewErrorResponse(
+				http.StatusUnauthorized,
+				"Please login first to access this pages",
+				dtos.GetErrorData(err),
+			),
+		)
+		return
+	}
+
+	result, err := rh.ReviewUsecase.GetPending(helpers.RequestContext(c), idUser)
+	if err != nil {
+		c.JSON(
+			http.StatusBadRequest,
+			dtos.NewErrorResponse(
+				http.StatusBadRequest,
+				"Cannot Get Pending Review",
+				err.Error(),
+			),
+		)
+		return
+	}
+
+	c.JSON(
+		http.StatusOK,
+		dtos.NewResponse(
+			http.StatusOK,
+			"Success Get Pending Review",
+			result,
+		),
+	)
+}
+
+func (rh *ReviewHandler) GetByProduk(c *gin.Context) {
+	rp, page := helpers.Pagination(c)
+
+	res, count, err := rh.ReviewUsecase.GetByProduk(helpers.RequestContext(c), c.Param("id"), rp, page)
+	if err != nil {
+		c.JSON(
+			http.StatusBadRequest,
+			dtos.NewErrorResponse(
+				http.StatusBadRequest,
+				"Cannot Get Review",
+				err.Error(),
+			),
+		)
+		return
+	}
+
+	c.JSON(
+		http.StatusOK,
+		dtos.NewResponse(
+			http.StatusOK,
+			"Success Get Review",
+			reviewPage(res, count, rp, page),
+		),
+	)
+}
+
+func (rh *ReviewHandler) GetAllWithPage(c *gin.Context) {
+	rp, page := helpers.Pagination(c)
+
+	filter := bson.M{}
+	if status := c.Query("status"); status != "" {
+		filter["status"] = status
+	}
+
+	if produkID := c.Query("produk_id"); produkID != "" {
+		produkHex, err := primitive.ObjectIDFromHex(produkID)
+		if err != nil {
+			c.JSON(
+				http.StatusBadRequest,
+				dtos.NewErrorResponse(
+					http.StatusBadRequest,
+					"Cannot Get Review",
+					"produk_id tidak valid",
+				),
+			)
+			return
+		}
+		filter["produk_id"] = produkHex
+	}
+
+	// Review dengan rating rendah paling sering perlu dimoderasi
+	if maxRating, err := strconv.ParseInt(c.Query("max_rating"), 10, 64); err == nil {
+		filter["rating"] = bson.M{"$lte": maxRating}
+	}
+
+	res, count, err := rh.ReviewUsecase.GetAllWithPage(helpers.RequestContext(c), rp, page, filter)
+	if err != nil {
+		c.JSON(
+			http.StatusBadRequest,
+			dtos.NewErrorResponse(
+				http.StatusBadRequest,
+				"Cannot Get Review",
+				err.Error(),
+			),
+		)
+		return
+	}
+
+	c.JSON(
+		http.StatusOK,
+		dtos.NewResponse(
+			http.StatusOK,
+			"Success Get Review",
+			reviewPage(res, count, rp, page),
+		),
+	)
+}
+
+func (rh *ReviewHandler) Hide(c *gin.Context) {
+	var req dtos.HideReviewRequest
+
+	idAdmin, err := middlewares.IsAdmin(c)
+	if err != nil {
+		c.JSON(
+			http.StatusUnauthorized,
+			dtos.NewErrorResponse(
+				http.StatusUnauthorized,
+				"Unauthorized",
+				dtos.GetErrorData(err),
+			),
+		)
+		return
+	}
+
+	err = c.ShouldBindJSON(&req)
+	if err != nil {
+		c.JSON(
+			http.StatusUnprocessableEntity,
+			dtos.NewErrorResponse(
+				http.StatusUnprocessableEntity,
+				"Filed Cannot Be Empty",
+				dtos.GetErrorData(err),
+			),
+		)
+		return
+	}
+
+	if ok, err := isRequestValid(&req); !ok {
+		c.JSON(
+			http.StatusBadRequest,
+			dtos.NewErrorResponse(
+				http.StatusBadRequest,
+				"Bad Request",
+				dtos.GetErrorData(err),
+			),
+		)
+		return
+	}
+
+	result, err := rh.ReviewUsecase.Hide(helpers.RequestContext(c), c.Param("id"), idAdmin, &req)
+	if err != nil {
+		c.JSON(
+			http.StatusBadRequest,
+			dtos.NewErrorResponse(
+				http.StatusBadRequest,
+				"Cannot Hide Review",
+				err.Error(),
+			),
+		)
+		return
+	}
+
+	c.JSON(
+		http.StatusOK,
+		dtos.NewResponse(
+			http.StatusOK,
+			"Success Hide Review",
+			result,
+		),
+	)
+}
+
+func (rh *ReviewHandler) Unhide(c *gin.Context) {
+	idAdmin, err := middlewares.IsAdmin(c)
+	if err != nil {
+		c.JSON(
+			http.StatusUnauthorized,
+			dtos.NewErrorResponse(
+				http.StatusUnauthorized,
+				"Unauthorized",
+				dtos.GetErrorData(err),
+			),
+		)
+		return
+	}
+
+	result, err := rh.ReviewUsecase.Unhide(helpers.RequestContext(c), c.Param("id"), idAdmin)
+	if err != nil {
+		c.JSON(
+			http.StatusBadRequest,
+			dtos.NewErrorResponse(
+				http.StatusBadRequest,
+				"Cannot Unhide Review",
+				err.Error(),
+			),
+		)
+		return
+	}
+
+	c.JSON(
+		http.StatusOK,
+		dtos.NewResponse(
+			http.StatusOK,
+			"Success Unhide Review",
+			result,
+		),
+	)
+}
@@ -0,0 +1,189 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+	"warunk-bem/domain"
+	"warunk-bem/mongo"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	mongodriver "go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type reviewRepository struct {
+	DB         mongo.Database
+	Collection mongo.Collection
+}
+
+const (
+	timeFormat     = "2006-01-02T15:04:05.999Z07:00" // reduce precision from RFC3339Nano as date format
+	collectionName = "review"
+)
+
+func NewReviewRepository(DB mongo.Database) domain.ReviewRepository {
+	return &reviewRepository{DB, DB.Collection(collectionName)}
+}
+
+// EnsureIndexes membuat unique index transaksi_id agar satu pembelian hanya bisa direview sekali
+func (r *reviewRepository) EnsureIndexes(ctx context.Context) error {
+	_, err := r.Collection.CreateIndexes(ctx, []mongodriver.IndexModel{
+		{
+			Keys:    bson.D{{Key: "transaksi_id", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+		{
+			Keys: bson.D{{Key: "produk_id", Value: 1}, {Key: "status", Value: 1}, {Key: "created_at", Value: -1}},
+		},
+	})
+	return err
+}
+
+func (r *reviewRepository) InsertOne(ctx context.Context, req *domain.Review) (*domain.Review, error) {
+	_, err := r.Collection.InsertOne(ctx, req)
+	if err != nil {
+		if mongodriver.IsDuplicateKeyError(err) {
+			return nil, errors.New("transaksi sudah direview")
+		}
+		return nil, err
+	}
+
+	return req, nil
+}
+
+func (r *reviewRepository) FindOne(ctx context.Context, id string) (*domain.Review, error) {
+	var review domain.Review
+
+	idHex, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, err
+	}
+
+	err = r.Collection.FindOne(ctx, bson.M{"_id": idHex}).Decode(&review)
+	if err != nil {
+		return nil, err
+	}
+
+	return &review, nil
+}
+
+func (r *reviewRepository) GetAllWithPage(ctx context.Context, rp int64, p int64, filter interface{}, setsort interface{}) ([]domain.Review, int64, error) {
+	var (
+		review []domain.Review
+		err    error
+	)
+
+	findOptions := options.Find()
+	findOptions.SetLimit(rp)
+	findOptions.SetSkip((p - 1) * rp)
+	if setsort != nil {
+		findOptions.SetSort(setsort)
+	}
+
+	cursor, err := r.Collection.Find(ctx, filter, findOptions)
+	if err != nil {
+		return review, 0, err
+	}
+
+	err = cursor.All(ctx, &review)
+	if err != nil {
+		return review, 0, err
+	}
+
+	total, err := r.Collection.CountDocuments(ctx, filter)
+	if err != nil {
+		return review, 0, err
+	}
+
+	return review, total, nil
+}
+
+// UpdateStatus hanya mengubah field moderasi, rating dan komentar tidak bisa diubah admin
+func (r *reviewRepository) UpdateStatus(ctx context.Context, review *domain.Review) (*domain.Review, error) {
+	review.UpdatedAt = time.Now()
+
+	update := bson.M{
+		"$set": bson.M{
+			"updated_at":    review.UpdatedAt,
+			"status":        review.Status,
+			"hidden_reason": review.HiddenReason,
+			"moderated_by":  review.ModeratedBy,
+			"moderated_at":  review.ModeratedAt,
+		},
+	}
+
+	_, err := r.Collection.UpdateOne(ctx, bson.M{"_id": review.ID}, update)
+	if err != nil {
+		return nil, err
+	}
+
+	return review, nil
+}
+
+// GetReviewedTransaksi mengembalikan transaksi mana saja yang sudah punya review
+func (r *reviewRepository) GetReviewedTransaksi(ctx context.Context, transaksiIDs []primitive.ObjectID) (map[primitive.ObjectID]bool, error) {
+	reviewed := make(map[primitive.ObjectID]bool)
+	if len(transaksiIDs) == 0 {
+		return reviewed, nil
+	}
+
+	cursor, err := r.Collection.Find(ctx, bson.M{"transaksi_id": bson.M{"$in": transaksiIDs}}, options.Find().SetProjection(bson.M{"transaksi_id": 1}))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	for cursor.Next(ctx) {
+		var review domain.Review
+		if err := cursor.Decode(&review); err != nil {
+			return nil, err
+		}
+
+		reviewed[review.TransaksiID] = true
+	}
+
+	return reviewed, nil
+}
+
+// GetRatingSummary menghitung rata-rata rating dari review yang tidak disembunyikan
+func (r *reviewRepository) GetRatingSummary(ctx context.Context, produkIDs []primitive.ObjectID) (map[primitive.ObjectID]domain.RatingSummary, error) {
+	summary := make(map[primitive.ObjectID]domain.RatingSummary)
+	if len(produkIDs) == 0 {
+		return summary, nil
+	}
+
+	pipeline := []bson.M{
+		{
+			"$match": bson.M{
+				"produk_id": bson.M{"$in": produkIDs},
+				"status":    domain.ReviewStatusVisible,
+			},
+		},
+		{
+			"$group": bson.M{
+				"_id":     "$produk_id",
+				"average": bson.M{"$avg": "$rating"},
+				"count":   bson.M{"$sum": 1},
+			},
+		},
+	}
+
+	cursor, err := r.Collection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	for cursor.Next(ctx) {
+		var s domain.RatingSummary
+		if err := cursor.Decode(&s); err != nil {
+			return nil, err
+		}
+
+		summary[s.ProdukID] = s
+	}
+
+	return summary, nil
+}
@@ -0,0 +1,323 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+	"warunk-bem/cache"
+	"warunk-bem/domain"
+	"warunk-bem/dtos"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type reviewUsecase struct {
+	ReviewRepo     domain.ReviewRepository
+	TransaksiRepo  domain.TransaksiRepository
+	ProdukRepo     domain.ProdukRepository
+	UserRepo       domain.UserRepository
+	Cache          domain.Cache
+	contextTimeout time.Duration
+}
+
+func NewReviewUsecase(ReviewRepo domain.ReviewRepository, TransaksiRepo domain.TransaksiRepository, ProdukRepo domain.ProdukRepository, UserRepo domain.UserRepository, Cache domain.Cache, contextTimeout time.Duration) domain.ReviewUsecase {
+	return &reviewUsecase{
+		ReviewRepo:     ReviewRepo,
+		TransaksiRepo:  TransaksiRepo,
+		ProdukRepo:     ProdukRepo,
+		UserRepo:       UserRepo,
+		Cache:          Cache,
+		contextTimeout: contextTimeout,
+	}
+}
+
+func toReviewResponse(review *domain.Review) *dtos.ReviewResponse {
+	return &dtos.ReviewResponse{
+		ID:           review.ID.Hex(),
+		CreatedAt:    review.CreatedAt,
+		UserID:       review.UserID.Hex(),
+		UserName:     review.UserName,
+		ProdukID:     review.ProdukID.Hex(),
+		TransaksiID:  review.TransaksiID.Hex(),
+		Rating:       review.Rating,
+		Comment:      review.Comment,
+		Status:       review.Status,
+		HiddenReason: review.HiddenReason,
+		ModeratedAt:  review.ModeratedAt,
+	}
+}
+
+// AddReview godoc
+// @Summary      Add Review
+// @Description  Rate a produk from a successful transaksi, one review per transaksi
+// @Tags         User - Review
+// @Accept       json
+// @Produce      json
+// @Param        request body dtos.InsertReviewRequest true "Payload Body [RAW]"
+// @Success      201 {object} dtos.ReviewOKResponse
+// @Failure      400 {object} dtos.BadRequestResponse
+// @Failure      401 {object} dtos.UnauthorizedResponse
+// @Failure      403 {object} dtos.ForbiddenResponse
+// @Failure      500 {object} dtos.InternalServerErrorResponse
+// @Router       /review [post]
+// @Security BearerAuth
+func (ru *reviewUsecase) InsertOne(c context.Context, userID string, req *dtos.InsertReviewRequest) (*dtos.ReviewResponse, error) {
+	ctx, cancel := context.WithTimeout(c, ru.contextTimeout)
+	defer cancel()
+
+	if req.Rating < domain.ReviewMinRating || req.Rating > domain.ReviewMaxRating {
+		return nil, errors.New("rating harus antara 1 dan 5")
+	}
+
+	user, err := ru.UserRepo.FindOne(ctx, userID)
+	if err != nil {
+		return nil, errors.New("user not found")
+	}
+
+	transaksi, err := ru.TransaksiRepo.FindOne(ctx, req.TransaksiID)
+	if err != nil {
+		return nil, errors.New("transaksi not found")
+	}
+
+	// Hanya pembeli yang transaksinya berhasil yang boleh memberi review
+	if transaksi.UserID != user.ID {
+		return nil, errors.New("transaksi not found")
+	}
+
+	if transaksi.Status != "Berhasil" {
+		return nil, errors.New("hanya transaksi berhasil yang bisa direview")
+	}
+
+	review := &domain.Review{
+		ID:          primitive.NewObjectID(),
+		CreatedAt:   time.Now(),
+		UpdatedAt:   time.Now(),
+		UserID:      user.ID,
+		UserName:    user.Name,
+		ProdukID:    transaksi.ProdukID,
+		TransaksiID: transaksi.ID,
+		Rating:      req.Rating,
+		Comment:     strings.TrimSpace(req.Comment),
+		Status:      domain.ReviewStatusVisible,
+	}
+
+	review, err = ru.ReviewRepo.InsertOne(ctx, review)
+	if err != nil {
+		return nil, err
+	}
+
+	cache.InvalidateProduk(c, ru.Cache)
+
+	return toReviewResponse(review), nil
+}
+
+// GetPendingReview godoc
+// @Summary      Get Pending Review
+// @Description  Get successful transaksi that have not been reviewed yet
+// @Tags         User - Review
+// @Accept       json
+// @Produce      json
+// @Success      200 {object} dtos.ReviewPendingOKResponse
+// @Failure      400 {object} dtos.BadRequestResponse
+// @Failure      401 {object} dtos.UnauthorizedResponse
+// @Failure      403 {object} dtos.ForbiddenResponse
+// @Failure      500 {object} dtos.InternalServerErrorResponse
+// @Router       /review/pending [get]
+// @Security BearerAuth
+func (ru *reviewUsecase) GetPending(c context.Context, userID string) ([]*dtos.ReviewPendingResponse, error) {
+	res := []*dtos.ReviewPendingResponse{}
+
+	ctx, cancel := context.WithTimeout(c, ru.contextTimeout)
+	defer cancel()
+
+	transaksis, err := ru.TransaksiRepo.FindAllByUserId(ctx, userID)
+	if err != nil {
+		return res, errors.New("cannot get user transaksi")
+	}
+
+	ids := make([]primitive.ObjectID, 0, len(transaksis))
+	for _, transaksi := range transaksis {
+		ids = append(ids, transaksi.ID)
+	}
+
+	reviewed, err := ru.ReviewRepo.GetReviewedTransaksi(ctx, ids)
+	if err != nil {
+		return res, errors.New("cannot get review")
+	}
+
+	produks := make(map[primitive.ObjectID]*domain.Produk)
+	for _, transaksi := range transaksis {
+		if transaksi.Status != "Berhasil" || reviewed[transaksi.ID] {
+			continue
+		}
+
+		produk, ok := produks[transaksi.ProdukID]
+		if !ok {
+			produk, err = ru.ProdukRepo.FindOne(ctx, transaksi.ProdukID.Hex())
+			if err != nil {
+				continue
+			}
+			produks[transaksi.ProdukID] = produk
+		}
+
+		res = append(res, &dtos.ReviewPendingResponse{
+			TransaksiID: transaksi.ID.Hex(),
+			CreatedAt:   transaksi.CreatedAt,
+			ProdukID:    produk.ID.Hex(),
+			ProdukName:  produk.Name,
+			Image:       produk.Image,
+		})
+	}
+
+	return res, nil
+}
+
+// GetProdukReview godoc
+// @Summary      Get Produk Review
+// @Description  Get visible reviews of a produk, newest first
+// @Tags         Produk
+// @Accept       json
+// @Produce      json
+// @Param id path string true "ID Produk"
+// @Param        rp query int false "rp"
+// @Param        p query int false "p"
+// @Success      200 {object} dtos.ReviewsOKResponse
+// @Failure      400 {object} dtos.BadRequestResponse
+// @Failure      500 {object} dtos.InternalServerErrorResponse
+// @Router       /produk/{id}/review [get]
+func (ru *reviewUsecase) GetByProduk(c context.Context, produkID string, rp int64, p int64) ([]*dtos.ReviewResponse, int64, error) {
+	res := []*dtos.ReviewResponse{}
+
+	ctx, cancel := context.WithTimeout(c, ru.contextTimeout)
+	defer cancel()
+
+	produkHex, err := primitive.ObjectIDFromHex(produkID)
+	if err != nil {
+		return res, 0, errors.New("produk not found")
+	}
+
+	filter := bson.M{"produk_id": produkHex, "status": domain.ReviewStatusVisible}
+
+	reviews, count, err := ru.ReviewRepo.GetAllWithPage(ctx, rp, p, filter, bson.M{"created_at": -1})
+	if err != nil {
+		return res, 0, err
+	}
+
+	for i := range reviews {
+		res = append(res, toReviewResponse(&reviews[i]))
+	}
+
+	return res, count, nil
+}
+
+// GetAllReview godoc
+// @Summary      Get All Review
+// @Description  Get all reviews including hidden ones for moderation
+// @Tags         Admin - Review
+// @Accept       json
+// @Produce      json
+// @Param        rp query int false "rp"
+// @Param        p query int false "p"
+// @Param        status query string false "visible or hidden"
+// @Param        produk_id query string false "filter by produk"
+// @Param        max_rating query int false "only reviews with rating at most this value"
+// @Success      200 {object} dtos.ReviewsOKResponse
+// @Failure      400 {object} dtos.BadRequestResponse
+// @Failure      401 {object} dtos.UnauthorizedResponse
+// @Failure      403 {object} dtos.ForbiddenResponse
+// @Failure      500 {object} dtos.InternalServerErrorResponse
+// @Router       /review [get]
+// @Security BearerAuth
+func (ru *reviewUsecase) GetAllWithPage(c context.Context, rp int64, p int64, filter interface{}) ([]*dtos.ReviewResponse, int64, error) {
+	res := []*dtos.ReviewResponse{}
+
+	ctx, cancel := context.WithTimeout(c, ru.contextTimeout)
+	defer cancel()
+
+	reviews, count, err := ru.ReviewRepo.GetAllWithPage(ctx, rp, p, filter, bson.M{"created_at": -1})
+	if err != nil {
+		return res, 0, err
+	}
+
+	for i := range reviews {
+		res = append(res, toReviewResponse(&reviews[i]))
+	}
+
+	return res, count, nil
+}
+
+// HideReview godoc
+// @Summary      Hide Review
+// @Description  Hide an abusive review, hidden reviews are excluded from the produk rating
+// @Tags         Admin - Review
+// @Accept       json
+// @Produce      json
+// @Param id path string true "ID Review"
+// @Param        request body dtos.HideReviewRequest true "Payload Body [RAW]"
+// @Success      200 {object} dtos.ReviewOKResponse
+// @Failure      400 {object} dtos.BadRequestResponse
+// @Failure      401 {object} dtos.UnauthorizedResponse
+// @Failure      403 {object} dtos.ForbiddenResponse
+// @Failure      404 {object} dtos.NotFoundResponse
+// @Failure      500 {object} dtos.InternalServerErrorResponse
+// @Router       /review/{id}/hide [put]
+// @Security BearerAuth
+func (ru *reviewUsecase) Hide(c context.Context, id string, adminID string, req *dtos.HideReviewRequest) (*dtos.ReviewResponse, error) {
+	return ru.moderate(c, id, adminID, domain.ReviewStatusHidden, strings.TrimSpace(req.Reason))
+}
+
+// UnhideReview godoc
+// @Summary      Unhide Review
+// @Description  Show a previously hidden review again
+// @Tags         Admin - Review
+// @Accept       json
+// @Produce      json
+// @Param id path string true "ID Review"
+// @Success      200 {object} dtos.ReviewOKResponse
+// @Failure      400 {object} dtos.BadRequestResponse
+// @Failure      401 {object} dtos.UnauthorizedResponse
+// @Failure      403 {object} dtos.ForbiddenResponse
+// @Failure      404 {object} dtos.NotFoundResponse
+// @Failure      500 {object} dtos.InternalServerErrorResponse
+// @Router       /review/{id}/unhide [put]
+// @Security BearerAuth
+func (ru *reviewUsecase) Unhide(c context.Context, id string, adminID string) (*dtos.ReviewResponse, error) {
+	return ru.moderate(c, id, adminID, domain.ReviewStatusVisible, "")
+}
+
+func (ru *reviewUsecase) moderate(c context.Context, id string, adminID string, status string, reason string) (*dtos.ReviewResponse, error) {
+	ctx, cancel := context.WithTimeout(c, ru.contextTimeout)
+	defer cancel()
+
+	adminHex, err := primitive.ObjectIDFromHex(adminID)
+	if err != nil {
+		return nil, errors.New("admin not found")
+	}
+
+	review, err := ru.ReviewRepo.FindOne(ctx, id)
+	if err != nil {
+		return nil, errors.New("review not found")
+	}
+
+	if review.Status == status {
+		return toReviewResponse(review), nil
+	}
+
+	now := time.Now()
+	review.Status = status
+	review.HiddenReason = reason
+	review.ModeratedBy = adminHex
+	review.ModeratedAt = &now
+
+	review, err = ru.ReviewRepo.UpdateStatus(ctx, review)
+	if err != nil {
+		return nil, errors.New("cannot update review")
+	}
+
+	// Rating produk berubah karena review yang disembunyikan tidak dihitung
+	cache.InvalidateProduk(c, ru.Cache)
+
+	return toReviewResponse(review), nil
+}
@@ -0,0 +1,246 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+	"warunk-bem/cache"
+	"warunk-bem/domain"
+	"warunk-bem/dtos"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// mockReviewRepo meniru unique index transaksi_id pada InsertOne
+type mockReviewRepo struct {
+	domain.ReviewRepository
+
+	reviews []*domain.Review
+	updates int
+	filter  interface{}
+}
+
+func (m *mockReviewRepo) InsertOne(ctx context.Context, req *domain.Review) (*domain.Review, error) {
+	for _, r := range m.reviews {
+		if r.TransaksiID == req.TransaksiID {
+			return nil, errors.New("transaksi sudah direview")
+		}
+	}
+	m.reviews = append(m.reviews, req)
+	return req, nil
+}
+
+func (m *mockReviewRepo) FindOne(ctx context.Context, id string) (*domain.Review, error) {
+	for _, r := range m.reviews {
+		if r.ID.Hex() == id {
+			copied := *r
+			return &copied, nil
+		}
+	}
+	return nil, errors.New("not found")
+}
+
+func (m *mockReviewRepo) GetAllWithPage(ctx context.Context, rp int64, p int64, filter interface{}, setsort interface{}) ([]domain.Review, int64, error) {
+	m.filter = filter
+	res := []domain.Review{}
+	for _, r := range m.reviews {
+		res = append(res, *r)
+	}
+	return res, int64(len(res)), nil
+}
+
+func (m *mockReviewRepo) UpdateStatus(ctx context.Context, review *domain.Review) (*domain.Review, error) {
+	m.updates++
+	for i, r := range m.reviews {
+		if r.ID == review.ID {
+			m.reviews[i] = review
+		}
+	}
+	return review, nil
+}
+
+func (m *mockReviewRepo) GetReviewedTransaksi(ctx context.Context, transaksiIDs []primitive.ObjectID) (map[primitive.ObjectID]bool, error) {
+	res := map[primitive.ObjectID]bool{}
+	for _, r := range m.reviews {
+		res[r.TransaksiID] = true
+	}
+	return res, nil
+}
+
+type mockTransaksiRepo struct {
+	domain.TransaksiRepository
+
+	transaksis []*domain.Transaksi
+}
+
+func (m *mockTransaksiRepo) FindOne(ctx context.Context, id string) (*domain.Transaksi, error) {
+	for _, t := range m.transaksis {
+		if t.ID.Hex() == id {
+			return t, nil
+		}
+	}
+	return nil, errors.New("not found")
+}
+
+func (m *mockTransaksiRepo) FindAllByUserId(ctx context.Context, id string) ([]*domain.Transaksi, error) {
+	res := []*domain.Transaksi{}
+	for _, t := range m.transaksis {
+		if t.UserID.Hex() == id {
+			res = append(res, t)
+		}
+	}
+	return res, nil
+}
+
+type mockProdukRepo struct {
+	domain.ProdukRepository
+
+	produks map[string]*domain.Produk
+}
+
+func (m *mockProdukRepo) FindOne(ctx context.Context, id string) (*domain.Produk, error) {
+	produk, ok := m.produks[id]
+	if !ok {
+		return nil, errors.New("not found")
+	}
+	return produk, nil
+}
+
+type mockUserRepo struct {
+	domain.UserRepository
+}
+
+func (m *mockUserRepo) FindOne(ctx context.Context, id string) (*domain.User, error) {
+	userID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, err
+	}
+	return &domain.User{ID: userID, Name: "Budi"}, nil
+}
+
+func TestInsertOne(t *testing.T) {
+	userID := primitive.NewObjectID()
+	produkID := primitive.NewObjectID()
+	berhasil := &domain.Transaksi{ID: primitive.NewObjectID(), UserID: userID, ProdukID: produkID, Status: "Berhasil"}
+	gagal := &domain.Transaksi{ID: primitive.NewObjectID(), UserID: userID, ProdukID: produkID, Status: "Gagal"}
+	orangLain := &domain.Transaksi{ID: primitive.NewObjectID(), UserID: primitive.NewObjectID(), ProdukID: produkID, Status: "Berhasil"}
+
+	repo := &mockReviewRepo{}
+	transaksiRepo := &mockTransaksiRepo{transaksis: []*domain.Transaksi{berhasil, gagal, orangLain}}
+	ru := NewReviewUsecase(repo, transaksiRepo, &mockProdukRepo{}, &mockUserRepo{}, cache.NewMemoryCache(), time.Second)
+
+	tests := []struct {
+		name        string
+		transaksiID string
+		rating      int64
+		wantErr     bool
+	}{
+		{name: "rating below range", transaksiID: berhasil.ID.Hex(), rating: 0, wantErr: true},
+		{name: "rating above range", transaksiID: berhasil.ID.Hex(), rating: 6, wantErr: true},
+		{name: "failed transaksi", transaksiID: gagal.ID.Hex(), rating: 4, wantErr: true},
+		{name: "transaksi of another user", transaksiID: orangLain.ID.Hex(), rating: 4, wantErr: true},
+		{name: "unknown transaksi", transaksiID: primitive.NewObjectID().Hex(), rating: 4, wantErr: true},
+		{name: "verified purchase", transaksiID: berhasil.ID.Hex(), rating: 5},
+		{name: "once per transaksi", transaksiID: berhasil.ID.Hex(), rating: 3, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res, err := ru.InsertOne(context.Background(), userID.Hex(), &dtos.InsertReviewRequest{TransaksiID: tt.transaksiID, Rating: tt.rating, Comment: "  enak  "})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+
+			if res.ProdukID != produkID.Hex() || res.UserName != "Budi" || res.Comment != "enak" || res.Status != domain.ReviewStatusVisible {
+				t.Errorf("review = %+v", res)
+			}
+		})
+	}
+
+	if len(repo.reviews) != 1 {
+		t.Errorf("reviews = %d, want 1", len(repo.reviews))
+	}
+}
+
+func TestGetPending(t *testing.T) {
+	userID := primitive.NewObjectID()
+	kopi := &domain.Produk{ID: primitive.NewObjectID(), Name: "Kopi"}
+	reviewed := &domain.Transaksi{ID: primitive.NewObjectID(), UserID: userID, ProdukID: kopi.ID, Status: "Berhasil"}
+	pending := &domain.Transaksi{ID: primitive.NewObjectID(), UserID: userID, ProdukID: kopi.ID, Status: "Berhasil"}
+	gagal := &domain.Transaksi{ID: primitive.NewObjectID(), UserID: userID, ProdukID: kopi.ID, Status: "Gagal"}
+	hapus := &domain.Transaksi{ID: primitive.NewObjectID(), UserID: userID, ProdukID: primitive.NewObjectID(), Status: "Berhasil"}
+
+	repo := &mockReviewRepo{reviews: []*domain.Review{{ID: primitive.NewObjectID(), TransaksiID: reviewed.ID}}}
+	transaksiRepo := &mockTransaksiRepo{transaksis: []*domain.Transaksi{reviewed, pending, gagal, hapus}}
+	produkRepo := &mockProdukRepo{produks: map[string]*domain.Produk{kopi.ID.Hex(): kopi}}
+	ru := NewReviewUsecase(repo, transaksiRepo, produkRepo, &mockUserRepo{}, cache.NewMemoryCache(), time.Second)
+
+	res, err := ru.GetPending(context.Background(), userID.Hex())
+	if err != nil {
+		t.Fatalf("err = %v", err)
+	}
+	if len(res) != 1 || res[0].TransaksiID != pending.ID.Hex() || res[0].ProdukName != "Kopi" {
+		t.Fatalf("pending = %+v, want only the unreviewed successful transaksi", res)
+	}
+}
+
+func TestGetByProdukOnlyVisible(t *testing.T) {
+	produkID := primitive.NewObjectID()
+	repo := &mockReviewRepo{}
+	ru := NewReviewUsecase(repo, &mockTransaksiRepo{}, &mockProdukRepo{}, &mockUserRepo{}, cache.NewMemoryCache(), time.Second)
+
+	if _, _, err := ru.GetByProduk(context.Background(), produkID.Hex(), 10, 1); err != nil {
+		t.Fatalf("err = %v", err)
+	}
+	filter, ok := repo.filter.(bson.M)
+	if !ok || filter["produk_id"] != produkID || filter["status"] != domain.ReviewStatusVisible {
+		t.Fatalf("filter = %v, want visible reviews of the produk", repo.filter)
+	}
+
+	if _, _, err := ru.GetByProduk(context.Background(), "bukan-id", 10, 1); err == nil {
+		t.Fatal("invalid produk id should fail")
+	}
+}
+
+func TestModerate(t *testing.T) {
+	adminID := primitive.NewObjectID().Hex()
+	review := &domain.Review{ID: primitive.NewObjectID(), Rating: 1, Status: domain.ReviewStatusVisible}
+	repo := &mockReviewRepo{reviews: []*domain.Review{review}}
+	ru := NewReviewUsecase(repo, &mockTransaksiRepo{}, &mockProdukRepo{}, &mockUserRepo{}, cache.NewMemoryCache(), time.Second)
+
+	res, err := ru.Hide(context.Background(), review.ID.Hex(), adminID, &dtos.HideReviewRequest{Reason: " kasar "})
+	if err != nil {
+		t.Fatalf("err = %v", err)
+	}
+	if res.Status != domain.ReviewStatusHidden || res.HiddenReason != "kasar" || res.ModeratedAt == nil {
+		t.Fatalf("hidden review = %+v", res)
+	}
+
+	// menyembunyikan ulang tidak menulis apa pun
+	if _, err := ru.Hide(context.Background(), review.ID.Hex(), adminID, &dtos.HideReviewRequest{Reason: "lagi"}); err != nil {
+		t.Fatalf("err = %v", err)
+	}
+	if repo.updates != 1 {
+		t.Fatalf("updates = %d, want 1", repo.updates)
+	}
+
+	res, err = ru.Unhide(context.Background(), review.ID.Hex(), adminID)
+	if err != nil {
+		t.Fatalf("err = %v", err)
+	}
+	if res.Status != domain.ReviewStatusVisible || res.HiddenReason != "" {
+		t.Fatalf("visible review = %+v", res)
+	}
+
+	if _, err := ru.Unhide(context.Background(), primitive.NewObjectID().Hex(), adminID); err == nil {
+		t.Fatal("unknown review should fail")
+	}
+	if _, err := ru.Unhide(context.Background(), review.ID.Hex(), "bukan-id"); err == nil {
+		t.Fatal("invalid admin id should fail")
+	}
+}
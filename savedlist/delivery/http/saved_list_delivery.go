@@ -0,0 +1,466 @@
+package http
+
+import (
+	"math"
+	"net/http"
+	"warunk-bem/domain"
+	"warunk-bem/dtos"
+	"warunk-bem/helpers"
+	"warunk-bem/middlewares"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+)
+
+type SavedListHandler struct {
+	SavedListUsecase domain.SavedListUsecase
+}
+
+func NewSavedListHandler(protected *gin.RouterGroup, protectedAdmin *gin.RouterGroup, su domain.SavedListUsecase) {
+	handler := &SavedListHandler{
+		SavedListUsecase: su,
+	}
+
+	lists := protected.Group("/lists")
+
+	lists.GET("", handler.GetLists)
+	lists.POST("", handler.CreateList)
+	lists.PUT("/:id", handler.RenameList)
+	lists.DELETE("/:id", handler.DeleteList)
+	lists.GET("/:id/items", handler.GetItems)
+	lists.POST("/:id/items", handler.AddItem)
+	lists.DELETE("/:id/items", handler.ClearItems)
+	lists.DELETE("/:id/items/:produkId", handler.RemoveItem)
+	lists.POST("/:id/items/:produkId/move-to-cart", handler.MoveToCart)
+
+	// Endpoint favorite dan wishlist dilayani lewat list bawaan masing-masing
+	for _, kind := range []string{domain.SavedListKindFavorite, domain.SavedListKindWishlist} {
+		group := protected.Group("/" + kind)
+		group.GET("", handler.withKind(kind, handler.GetItems))
+		group.POST("", handler.withKind(kind, handler.AddItem))
+		group.DELETE("", handler.withKind(kind, handler.ClearItems))
+		group.DELETE("/:produkId", handler.withKind(kind, handler.RemoveItem))
+		group.POST("/:produkId/move-to-cart", handler.withKind(kind, handler.MoveToCart))
+	}
+
+	protectedAdmin.POST("/lists/migrate", handler.Migrate)
+}
+
+func isRequestValid(m interface{}) (bool, error) {
+	validate := validator.New()
+	err := validate.Struct(m)
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func unauthorized(c *gin.Context, err error) {
+	c.JSON(
+		http.StatusUnauthorized,
+		dtos.NewErrorResponse(
+			http.StatusUnauthorized,
+			"Please login first to access this pages",
+			dtos.GetErrorData(err),
+		),
+	)
+}
+
+func (sh *SavedListHandler) GetLists(c *gin.Context) {
+	idUser, err := middlewares.IsUser(c)
+	if err != nil {
+		unauthorized(c, err)
+		return
+	}
+
+	res, err := sh.SavedListUsecase.GetLists(helpers.RequestContext(c), idUser)
+	if err != nil {
+		c.JSON(
+			http.StatusInternalServerError,
+			dtos.NewErrorResponse(
+				http.StatusInternalServerError,
+				"Cannot Get Saved List",
+				err.Error(),
+			),
+		)
+		return
+	}
+
+	c.JSON(
+		http.StatusOK,
+		dtos.NewResponse(
+			http.StatusOK,
+			"Success Get Saved List",
+			res,
+		),
+	)
+}
+
+func (sh *SavedListHandler) CreateList(c *gin.Context) {
+	idUser, err := middlewares.IsUser(c)
+	if err != nil {
+		unauthorized(c, err)
+		return
+	}
+
+	var req dtos.SavedListRequest
+	err = c.ShouldBindJSON(&req)
+	if err != nil {
+		c.JSON(
+			http.StatusUnprocessableEntity,
+			dtos.NewErrorResponse(
+				http.StatusUnprocessableEntity,
+				"Invalid Request",
+				dtos.GetErrorData(err),
+			),
+		)
+		return
+	}
+
+	if ok, err := isRequestValid(&req); !ok {
+		c.JSON(
+			http.StatusBadRequest,
+			dtos.NewErrorResponse(
+				http.StatusBadRequest,
+				"Invalid request",
+				dtos.GetErrorData(err),
+			),
+		)
+		return
+	}
+
+	res, err := sh.SavedListUsecase.CreateList(helpers.RequestContext(c), idUser, &req)
+	if err != nil {
+		c.JSON(
+			http.StatusBadRequest,
+			dtos.NewErrorResponse(
+				http.StatusBadRequest,
+				"Cannot Create Saved List",
+				err.Error(),
+			),
+		)
+		return
+	}
+
+	c.JSON(
+		http.StatusCreated,
+		dtos.NewResponse(
+			http.StatusCreated,
+			"Success Create Saved List",
+			res,
+		),
+	)
+}
+
+func (sh *SavedListHandler) RenameList(c *gin.Context) {
+	idUser, err := middlewares.IsUser(c)
+	if err != nil {
+		unauthorized(c, err)
+		return
+	}
+
+	var req dtos.SavedListRequest
+	err = c.ShouldBindJSON(&req)
+	if err != nil {
+		c.JSON(
+			http.StatusUnprocessableEntity,
+			dtos.NewErrorResponse(
+				http.StatusUnprocessableEntity,
+				"Invalid Request",
+				dtos.GetErrorData(err),
+			),
+		)
+		return
+	}
+
+	if ok, err := isRequestValid(&req); !ok {
+		c.JSON(
+			http.StatusBadRequest,
+			dtos.NewErrorResponse(
+				http.StatusBadRequest,
+				"Invalid request",
+				dtos.GetErrorData(err),
+			),
+		)
+		return
+	}
+
+	res, err := sh.SavedListUsecase.RenameList(helpers.RequestContext(c), idUser, c.Param("id"), &req)
+	if err != nil {
+		c.JSON(
+			http.StatusBadRequest,
+			dtos.NewErrorResponse(
+				http.StatusBadRequest,
+				"Cannot Update Saved List",
+				err.Error(),
+			),
+		)
+		return
+	}
+
+	c.JSON(
+		http.StatusOK,
+		dtos.NewResponse(
+			http.StatusOK,
+			"Success Update Saved List",
+			res,
+		),
+	)
+}
+
+func (sh *SavedListHandler) DeleteList(c *gin.Context) {
+	idUser, err := middlewares.IsUser(c)
+	if err != nil {
+		unauthorized(c, err)
+		return
+	}
+
+	err = sh.SavedListUsecase.DeleteList(helpers.RequestContext(c), idUser, c.Param("id"))
+	if err != nil {
+		c.JSON(
+			http.StatusBadRequest,
+			dtos.NewErrorResponse(
+				http.StatusBadRequest,
+				"Cannot Delete Saved List",
+				err.Error(),
+			),
+		)
+		return
+	}
+
+	c.JSON(
+		http.StatusOK,
+		dtos.NewResponseMessage(
+			http.StatusOK,
+			"Success Delete Saved List",
+		),
+	)
+}
+
+func (sh *SavedListHandler) GetItems(c *gin.Context) {
+	idUser, err := middlewares.IsUser(c)
+	if err != nil {
+		unauthorized(c, err)
+		return
+	}
+
+	rp, page := helpers.Pagination(c)
+
+	res, count, err := sh.SavedListUsecase.GetItems(helpers.RequestContext(c), idUser, c.Param("id"), rp, page)
+	if err != nil {
+		c.JSON(
+			http.StatusNotFound,
+			dtos.NewErrorResponse(
+				http.StatusNotFound,
+				"Cannot Get Saved List",
+				err.Error(),
+			),
+		)
+		return
+	}
+
+	result := dtos.GetAllSavedListItemResponse{
+		Total:       count,
+		PerPage:     rp,
+		CurrentPage: page,
+		LastPage:    int64(math.Ceil(float64(count) / float64(rp))),
+		From:        (page * rp) - rp + 1,
+		To:          page * rp,
+		Items:       res,
+	}
+
+	c.JSON(
+		http.StatusOK,
+		dtos.NewResponse(
+			http.StatusOK,
+			"Success Get Saved List",
+			result,
+		),
+	)
+}
+
+// withKind mengisi param id dengan alias list bawaan agar handler /lists bisa dipakai ulang
+func (sh *SavedListHandler) withKind(kind string, next gin.HandlerFunc) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Params = append(c.Params, gin.Param{Key: "id", Value: kind})
+		next(c)
+	}
+}
+
+func (sh *SavedListHandler) AddItem(c *gin.Context) {
+	idUser, err := middlewares.IsUser(c)
+	if err != nil {
+		unauthorized(c, err)
+		return
+	}
+
+	var req dtos.SavedListItemRequest
+	err = c.ShouldBindJSON(&req)
+	if err != nil {
+		c.JSON(
+			http.StatusUnprocessableEntity,
+			dtos.NewErrorResponse(
+				http.StatusUnprocessableEntity,
+				"Invalid Request",
+				dtos.GetErrorData(err),
+			),
+		)
+		return
+	}
+
+	if ok, err := isRequestValid(&req); !ok {
+		c.JSON(
+			http.StatusBadRequest,
+			dtos.NewErrorResponse(
+				http.StatusBadRequest,
+				"Invalid request",
+				dtos.GetErrorData(err),
+			),
+		)
+		return
+	}
+
+	res, err := sh.SavedListUsecase.AddItem(helpers.RequestContext(c), idUser, c.Param("id"), req.ProdukID)
+	if err != nil {
+		c.JSON(
+			http.StatusBadRequest,
+			dtos.NewErrorResponse(
+				http.StatusBadRequest,
+				"Invalid request",
+				err.Error(),
+			),
+		)
+		return
+	}
+
+	c.JSON(
+		http.StatusCreated,
+		dtos.NewResponse(
+			http.StatusCreated,
+			"Success",
+			res,
+		),
+	)
+}
+
+func (sh *SavedListHandler) RemoveItem(c *gin.Context) {
+	idUser, err := middlewares.IsUser(c)
+	if err != nil {
+		unauthorized(c, err)
+		return
+	}
+
+	err = sh.SavedListUsecase.RemoveItem(helpers.RequestContext(c), idUser, c.Param("id"), c.Param("produkId"))
+	if err != nil {
+		c.JSON(
+			http.StatusNotFound,
+			dtos.NewErrorResponse(
+				http.StatusNotFound,
+				"Cannot Remove Produk",
+				err.Error(),
+			),
+		)
+		return
+	}
+
+	c.JSON(
+		http.StatusOK,
+		dtos.NewResponseMessage(
+			http.StatusOK,
+			"Success Remove Produk",
+		),
+	)
+}
+
+func (sh *SavedListHandler) ClearItems(c *gin.Context) {
+	idUser, err := middlewares.IsUser(c)
+	if err != nil {
+		unauthorized(c, err)
+		return
+	}
+
+	err = sh.SavedListUsecase.ClearItems(helpers.RequestContext(c), idUser, c.Param("id"))
+	if err != nil {
+		c.JSON(
+			http.StatusNotFound,
+			dtos.NewErrorResponse(
+				http.StatusNotFound,
+				"Cannot Clear Saved List",
+				err.Error(),
+			),
+		)
+		return
+	}
+
+	c.JSON(
+		http.StatusOK,
+		dtos.NewResponseMessage(
+			http.StatusOK,
+			"Success Clear Saved List",
+		),
+	)
+}
+
+func (sh *SavedListHandler) MoveToCart(c *gin.Context) {
+	idUser, err := middlewares.IsUser(c)
+	if err != nil {
+		unauthorized(c, err)
+		return
+	}
+
+	// body boleh kosong, jumlah default 1
+	var req dtos.MoveToCartRequest
+	_ = c.ShouldBindJSON(&req)
+
+	res, err := sh.SavedListUsecase.MoveToCart(helpers.RequestContext(c), idUser, c.Param("id"), c.Param("produkId"), req.Quantity)
+	if err != nil {
+		c.JSON(
+			http.StatusBadRequest,
+			dtos.NewErrorResponse(
+				http.StatusBadRequest,
+				"Cannot Move Produk to Keranjang",
+				err.Error(),
+			),
+		)
+		return
+	}
+
+	c.JSON(
+		http.StatusOK,
+		dtos.NewResponse(
+			http.StatusOK,
+			"Success Move Produk to Keranjang",
+			res,
+		),
+	)
+}
+
+func (sh *SavedListHandler) Migrate(c *gin.Context) {
+	_, err := middlewares.IsAdmin(c)
+	if err != nil {
+		unauthorized(c, err)
+		return
+	}
+
+	res, err := sh.SavedListUsecase.Migrate(helpers.RequestContext(c))
+	if err != nil {
+		c.JSON(
+			http.StatusInternalServerError,
+			dtos.NewErrorResponse(
+				http.StatusInternalServerError,
+				"Cannot Migrate Saved List",
+				err.Error(),
+			),
+		)
+		return
+	}
+
+	c.JSON(
+		http.StatusOK,
+		dtos.NewResponse(
+			http.StatusOK,
+			"Success Migrate Saved List",
+			res,
+		),
+	)
+}
@@ -0,0 +1,316 @@
+package repository
+
+import (
+	"context"
+	"time"
+	"warunk-bem/domain"
+	"warunk-bem/mongo"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	mongodriver "go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type SavedListRepository struct {
+	DB             mongo.Database
+	Collection     mongo.Collection
+	ItemCollection mongo.Collection
+}
+
+const (
+	timeFormat         = "2006-01-02T15:04:05.999Z07:00" // reduce precision from RFC3339Nano as date format
+	collectionName     = "saved_list"
+	itemCollectionName = "saved_list_item"
+)
+
+func NewSavedListRepository(DB mongo.Database) domain.SavedListRepository {
+	return &SavedListRepository{DB, DB.Collection(collectionName), DB.Collection(itemCollectionName)}
+}
+
+// EnsureIndexes membuat unique index agar satu produk hanya tersimpan sekali di tiap list
+// dan tiap user hanya punya satu list favorite dan wishlist
+func (sr *SavedListRepository) EnsureIndexes(ctx context.Context) error {
+	_, err := sr.ItemCollection.CreateIndexes(ctx, []mongodriver.IndexModel{
+		{
+			Keys:    bson.D{{Key: "list_id", Value: 1}, {Key: "produk_id", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+		{
+			Keys: bson.D{{Key: "produk_id", Value: 1}},
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = sr.Collection.CreateIndexes(ctx, []mongodriver.IndexModel{
+		{
+			Keys: bson.D{{Key: "user_id", Value: 1}, {Key: "kind", Value: 1}},
+			Options: options.Index().SetUnique(true).SetPartialFilterExpression(bson.M{
+				"kind": bson.M{"$in": []string{domain.SavedListKindFavorite, domain.SavedListKindWishlist}},
+			}),
+		},
+	})
+	return err
+}
+
+func (sr *SavedListRepository) InsertList(ctx context.Context, req *domain.SavedList) (*domain.SavedList, error) {
+	_, err := sr.Collection.InsertOne(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	return req, nil
+}
+
+func (sr *SavedListRepository) FindList(ctx context.Context, id string, userID string) (*domain.SavedList, error) {
+	var list domain.SavedList
+
+	idHex, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, err
+	}
+
+	userIDHex, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	err = sr.Collection.FindOne(ctx, bson.M{"_id": idHex, "user_id": userIDHex}).Decode(&list)
+	if err != nil {
+		return nil, err
+	}
+
+	return &list, nil
+}
+
+func (sr *SavedListRepository) FindListByKind(ctx context.Context, userID string, kind string) (*domain.SavedList, error) {
+	var list domain.SavedList
+
+	userIDHex, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	err = sr.Collection.FindOne(ctx, bson.M{"user_id": userIDHex, "kind": kind}).Decode(&list)
+	if err != nil {
+		return nil, err
+	}
+
+	return &list, nil
+}
+
+func (sr *SavedListRepository) FindListByName(ctx context.Context, userID string, name string) (*domain.SavedList, error) {
+	var list domain.SavedList
+
+	userIDHex, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	err = sr.Collection.FindOne(ctx, bson.M{"user_id": userIDHex, "name": name}).Decode(&list)
+	if err != nil {
+		return nil, err
+	}
+
+	return &list, nil
+}
+
+func (sr *SavedListRepository) GetLists(ctx context.Context, userID string) ([]domain.SavedList, error) {
+	var lists []domain.SavedList
+
+	userIDHex, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	opts := options.Find()
+	opts.SetSort(bson.M{"created_at": 1})
+
+	cur, err := sr.Collection.Find(ctx, bson.M{"user_id": userIDHex}, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cur.Close(ctx)
+
+	for cur.Next(ctx) {
+		var l domain.SavedList
+		err := cur.Decode(&l)
+		if err != nil {
+			return nil, err
+		}
+
+		lists = append(lists, l)
+	}
+
+	return lists, nil
+}
+
+func (sr *SavedListRepository) UpdateList(ctx context.Context, list *domain.SavedList) (*domain.SavedList, error) {
+	list.UpdatedAt = time.Now()
+
+	_, err := sr.Collection.UpdateOne(ctx, bson.M{"_id": list.ID}, bson.M{"$set": bson.M{
+		"name":       list.Name,
+		"updated_at": list.UpdatedAt,
+	}})
+	if err != nil {
+		return nil, err
+	}
+
+	return list, nil
+}
+
+func (sr *SavedListRepository) DeleteList(ctx context.Context, id string) error {
+	idHex, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return err
+	}
+
+	_, err = sr.ItemCollection.DeleteMany(ctx, bson.M{"list_id": idHex})
+	if err != nil {
+		return err
+	}
+
+	_, err = sr.Collection.DeleteOne(ctx, bson.M{"_id": idHex})
+	return err
+}
+
+// InsertItem menyimpan produk ke list, mengembalikan false jika produk sudah ada
+func (sr *SavedListRepository) InsertItem(ctx context.Context, req *domain.SavedListItem) (bool, error) {
+	_, err := sr.ItemCollection.InsertOne(ctx, req)
+	if err != nil {
+		if mongodriver.IsDuplicateKeyError(err) {
+			return false, nil
+		}
+		return false, err
+	}
+
+	return true, nil
+}
+
+func (sr *SavedListRepository) GetItemsWithPage(ctx context.Context, rp int64, p int64, filter interface{}, setsort interface{}) ([]domain.SavedListItem, int64, error) {
+	var (
+		items []domain.SavedListItem
+		total int64
+		err   error
+	)
+
+	total, err = sr.ItemCollection.CountDocuments(ctx, filter)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	opts := options.Find()
+	opts.SetSort(setsort)
+	if rp > 0 {
+		opts.SetLimit(rp)
+		opts.SetSkip((p - 1) * rp)
+	}
+
+	cur, err := sr.ItemCollection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer cur.Close(ctx)
+
+	for cur.Next(ctx) {
+		var i domain.SavedListItem
+		err := cur.Decode(&i)
+		if err != nil {
+			return nil, 0, err
+		}
+
+		items = append(items, i)
+	}
+
+	return items, total, nil
+}
+
+func (sr *SavedListRepository) CountItems(ctx context.Context, listID primitive.ObjectID) (int64, error) {
+	return sr.ItemCollection.CountDocuments(ctx, bson.M{"list_id": listID})
+}
+
+func (sr *SavedListRepository) DeleteItem(ctx context.Context, listID primitive.ObjectID, produkID string) (int64, error) {
+	produkIDHex, err := primitive.ObjectIDFromHex(produkID)
+	if err != nil {
+		return 0, err
+	}
+
+	return sr.ItemCollection.DeleteMany(ctx, bson.M{"list_id": listID, "produk_id": produkIDHex})
+}
+
+func (sr *SavedListRepository) DeleteItems(ctx context.Context, listID primitive.ObjectID) error {
+	_, err := sr.ItemCollection.DeleteMany(ctx, bson.M{"list_id": listID})
+	return err
+}
+
+// GetLegacy membaca dokumen lama di collection favorite atau wishlist
+func (sr *SavedListRepository) GetLegacy(ctx context.Context, kind string) ([]domain.LegacySavedList, error) {
+	var legacy []domain.LegacySavedList
+
+	cur, err := sr.DB.Collection(kind).Find(ctx, bson.M{})
+	if err != nil {
+		return nil, err
+	}
+	defer cur.Close(ctx)
+
+	for cur.Next(ctx) {
+		var l domain.LegacySavedList
+		err := cur.Decode(&l)
+		if err != nil {
+			return nil, err
+		}
+
+		legacy = append(legacy, l)
+	}
+
+	return legacy, nil
+}
+
+// GetUserIDsByProduk mengambil user yang menyimpan produk di list dengan kind tertentu
+func (sr *SavedListRepository) GetUserIDsByProduk(ctx context.Context, produkID primitive.ObjectID, kind string) ([]primitive.ObjectID, error) {
+	cur, err := sr.ItemCollection.Find(ctx, bson.M{"produk_id": produkID})
+	if err != nil {
+		return nil, err
+	}
+	defer cur.Close(ctx)
+
+	var listIDs []primitive.ObjectID
+	for cur.Next(ctx) {
+		var i domain.SavedListItem
+		err := cur.Decode(&i)
+		if err != nil {
+			return nil, err
+		}
+
+		listIDs = append(listIDs, i.ListID)
+	}
+
+	if len(listIDs) == 0 {
+		return nil, nil
+	}
+
+	listCur, err := sr.Collection.Find(ctx, bson.M{"_id": bson.M{"$in": listIDs}, "kind": kind})
+	if err != nil {
+		return nil, err
+	}
+	defer listCur.Close(ctx)
+
+	seen := make(map[primitive.ObjectID]bool)
+	var userIDs []primitive.ObjectID
+	for listCur.Next(ctx) {
+		var l domain.SavedList
+		err := listCur.Decode(&l)
+		if err != nil {
+			return nil, err
+		}
+
+		if !seen[l.UserID] {
+			seen[l.UserID] = true
+			userIDs = append(userIDs, l.UserID)
+		}
+	}
+
+	return userIDs, nil
+}
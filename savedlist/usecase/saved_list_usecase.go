@@ -0,0 +1,616 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+	"warunk-bem/cache"
+	"warunk-bem/domain"
+	"warunk-bem/dtos"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type SavedListUsecase struct {
+	SavedListRepo    domain.SavedListRepository
+	ProdukRepo       domain.ProdukRepository
+	UserRepo         domain.UserRepository
+	KeranjangUsecase domain.KeranjangUsecase
+	Cache            domain.Cache
+	contextTimeout   time.Duration
+}
+
+func NewSavedListUsecase(SavedListRepo domain.SavedListRepository, ProdukRepo domain.ProdukRepository, UserRepo domain.UserRepository, KeranjangUsecase domain.KeranjangUsecase, Cache domain.Cache, contextTimeout time.Duration) domain.SavedListUsecase {
+	return &SavedListUsecase{
+		SavedListRepo:    SavedListRepo,
+		ProdukRepo:       ProdukRepo,
+		UserRepo:         UserRepo,
+		KeranjangUsecase: KeranjangUsecase,
+		Cache:            Cache,
+		contextTimeout:   contextTimeout,
+	}
+}
+
+var defaultListName = map[string]string{
+	domain.SavedListKindFavorite: "Favorite",
+	domain.SavedListKindWishlist: "Wishlist",
+}
+
+// ensureDefaultList mengambil list favorite atau wishlist milik user, membuatnya jika belum ada
+func (su *SavedListUsecase) ensureDefaultList(ctx context.Context, userID string, kind string) (*domain.SavedList, error) {
+	list, err := su.SavedListRepo.FindListByKind(ctx, userID, kind)
+	if err == nil {
+		return list, nil
+	}
+
+	user, err := su.UserRepo.FindOne(ctx, userID)
+	if err != nil {
+		return nil, errors.New("user not found")
+	}
+
+	list = &domain.SavedList{
+		ID:        primitive.NewObjectID(),
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+		UserID:    user.ID,
+		Name:      defaultListName[kind],
+		Kind:      kind,
+	}
+
+	_, err = su.SavedListRepo.InsertList(ctx, list)
+	if err != nil {
+		// list bawaan bisa saja dibuat request lain secara bersamaan
+		if existing, findErr := su.SavedListRepo.FindListByKind(ctx, userID, kind); findErr == nil {
+			return existing, nil
+		}
+		return nil, err
+	}
+
+	return list, nil
+}
+
+// resolveList menerima id list atau alias "favorite" / "wishlist"
+func (su *SavedListUsecase) resolveList(ctx context.Context, userID string, listRef string) (*domain.SavedList, error) {
+	if _, ok := defaultListName[listRef]; ok {
+		return su.ensureDefaultList(ctx, userID, listRef)
+	}
+
+	list, err := su.SavedListRepo.FindList(ctx, listRef, userID)
+	if err != nil {
+		return nil, errors.New("list not found")
+	}
+
+	return list, nil
+}
+
+// invalidateDashboard dashboard menampilkan isi list favorite
+func (su *SavedListUsecase) invalidateDashboard(ctx context.Context, list *domain.SavedList) {
+	if list.Kind == domain.SavedListKindFavorite {
+		cache.InvalidateUser(ctx, su.Cache, list.UserID.Hex())
+	}
+}
+
+func (su *SavedListUsecase) toListResponse(ctx context.Context, list *domain.SavedList) *dtos.SavedListResponse {
+	total, _ := su.SavedListRepo.CountItems(ctx, list.ID)
+
+	return &dtos.SavedListResponse{
+		ID:        list.ID.Hex(),
+		Name:      list.Name,
+		Kind:      list.Kind,
+		TotalItem: total,
+		CreatedAt: list.CreatedAt,
+		UpdatedAt: list.UpdatedAt,
+	}
+}
+
+// toItemResponse menggabungkan item dengan harga dan stok produk saat ini,
+// produk nil berarti produk sudah tidak ada
+func toItemResponse(item *domain.SavedListItem, produk *domain.Produk) *dtos.SavedListItemResponse {
+	res := &dtos.SavedListItemResponse{
+		ID:         item.ID.Hex(),
+		ListID:     item.ListID.Hex(),
+		ProdukID:   item.ProdukID.Hex(),
+		SavedPrice: item.SavedPrice,
+		CreatedAt:  item.CreatedAt,
+	}
+
+	if produk == nil || produk.DeletedAt != nil {
+		res.Deleted = true
+		return res
+	}
+
+	res.Name = produk.Name
+	res.Slug = produk.Slug
+	res.Image = produk.Image
+	res.Category = produk.Category
+	res.Price = produk.Price
+	res.Stock = produk.Stock
+	res.Available = produk.Stock > 0
+
+	return res
+}
+
+// GetSavedLists godoc
+// @Summary      Get Saved Lists
+// @Description  Get all saved lists of the logged in user, favorite and wishlist are always present
+// @Tags         User - Saved List
+// @Accept       json
+// @Produce      json
+// @Success      200 {object} dtos.SavedListsOKResponse
+// @Failure      401 {object} dtos.UnauthorizedResponse
+// @Failure      403 {object} dtos.ForbiddenResponse
+// @Failure      500 {object} dtos.InternalServerErrorResponse
+// @Router       /lists [get]
+// @Security BearerAuth
+func (su *SavedListUsecase) GetLists(ctx context.Context, userID string) ([]*dtos.SavedListResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, su.contextTimeout)
+	defer cancel()
+
+	for _, kind := range []string{domain.SavedListKindFavorite, domain.SavedListKindWishlist} {
+		if _, err := su.ensureDefaultList(ctx, userID, kind); err != nil {
+			return nil, err
+		}
+	}
+
+	lists, err := su.SavedListRepo.GetLists(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]*dtos.SavedListResponse, 0, len(lists))
+	for i := range lists {
+		res = append(res, su.toListResponse(ctx, &lists[i]))
+	}
+
+	return res, nil
+}
+
+// CreateSavedList godoc
+// @Summary      Create Saved List
+// @Description  Create a custom saved list
+// @Tags         User - Saved List
+// @Accept       json
+// @Produce      json
+// @Param        request body dtos.SavedListRequest true "Payload Body [RAW]"
+// @Success      201 {object} dtos.SavedListOKResponse
+// @Failure      400 {object} dtos.BadRequestResponse
+// @Failure      401 {object} dtos.UnauthorizedResponse
+// @Failure      403 {object} dtos.ForbiddenResponse
+// @Failure      500 {object} dtos.InternalServerErrorResponse
+// @Router       /lists [post]
+// @Security BearerAuth
+func (su *SavedListUsecase) CreateList(ctx context.Context, userID string, req *dtos.SavedListRequest) (*dtos.SavedListResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, su.contextTimeout)
+	defer cancel()
+
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return nil, errors.New("nama list tidak boleh kosong")
+	}
+
+	if _, err := su.SavedListRepo.FindListByName(ctx, userID, name); err == nil {
+		return nil, errors.New("nama list sudah digunakan")
+	}
+
+	user, err := su.UserRepo.FindOne(ctx, userID)
+	if err != nil {
+		return nil, errors.New("user not found")
+	}
+
+	list := &domain.SavedList{
+		ID:        primitive.NewObjectID(),
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+		UserID:    user.ID,
+		Name:      name,
+		Kind:      domain.SavedListKindCustom,
+	}
+
+	_, err = su.SavedListRepo.InsertList(ctx, list)
+	if err != nil {
+		return nil, errors.New("cannot create list")
+	}
+
+	return su.toListResponse(ctx, list), nil
+}
+
+// RenameSavedList godoc
+// @Summary      Rename Saved List
+// @Description  Rename a saved list
+// @Tags         User - Saved List
+// @Accept       json
+// @Produce      json
+// @Param        id path string true "id list or favorite / wishlist"
+// @Param        request body dtos.SavedListRequest true "Payload Body [RAW]"
+// @Success      200 {object} dtos.SavedListOKResponse
+// @Failure      400 {object} dtos.BadRequestResponse
+// @Failure      401 {object} dtos.UnauthorizedResponse
+// @Failure      403 {object} dtos.ForbiddenResponse
+// @Failure      404 {object} dtos.NotFoundResponse
+// @Failure      500 {object} dtos.InternalServerErrorResponse
+// @Router       /lists/{id} [put]
+// @Security BearerAuth
+func (su *SavedListUsecase) RenameList(ctx context.Context, userID string, listRef string, req *dtos.SavedListRequest) (*dtos.SavedListResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, su.contextTimeout)
+	defer cancel()
+
+	list, err := su.resolveList(ctx, userID, listRef)
+	if err != nil {
+		return nil, err
+	}
+
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return nil, errors.New("nama list tidak boleh kosong")
+	}
+
+	if existing, err := su.SavedListRepo.FindListByName(ctx, userID, name); err == nil && existing.ID != list.ID {
+		return nil, errors.New("nama list sudah digunakan")
+	}
+
+	list.Name = name
+	list, err = su.SavedListRepo.UpdateList(ctx, list)
+	if err != nil {
+		return nil, errors.New("cannot update list")
+	}
+
+	return su.toListResponse(ctx, list), nil
+}
+
+// DeleteSavedList godoc
+// @Summary      Delete Saved List
+// @Description  Delete a custom saved list and its items, favorite and wishlist can only be cleared
+// @Tags         User - Saved List
+// @Accept       json
+// @Produce      json
+// @Param        id path string true "id list"
+// @Success      200 {object} dtos.StatusOKDeletedResponse
+// @Failure      400 {object} dtos.BadRequestResponse
+// @Failure      401 {object} dtos.UnauthorizedResponse
+// @Failure      403 {object} dtos.ForbiddenResponse
+// @Failure      404 {object} dtos.NotFoundResponse
+// @Failure      500 {object} dtos.InternalServerErrorResponse
+// @Router       /lists/{id} [delete]
+// @Security BearerAuth
+func (su *SavedListUsecase) DeleteList(ctx context.Context, userID string, listRef string) error {
+	ctx, cancel := context.WithTimeout(ctx, su.contextTimeout)
+	defer cancel()
+
+	list, err := su.resolveList(ctx, userID, listRef)
+	if err != nil {
+		return err
+	}
+
+	if list.Kind != domain.SavedListKindCustom {
+		return errors.New("list favorite dan wishlist tidak dapat dihapus")
+	}
+
+	return su.SavedListRepo.DeleteList(ctx, list.ID.Hex())
+}
+
+// GetSavedListItems godoc
+// @Summary      Get Saved List Items
+// @Description  Get items of a saved list with live price and stock
+// @Tags         User - Saved List
+// @Accept       json
+// @Produce      json
+// @Param        id path string true "id list or favorite / wishlist"
+// @Param        rp query int false "rp"
+// @Param        p query int false "p"
+// @Success      200 {object} dtos.SavedListItemsOKResponse
+// @Failure      400 {object} dtos.BadRequestResponse
+// @Failure      401 {object} dtos.UnauthorizedResponse
+// @Failure      403 {object} dtos.ForbiddenResponse
+// @Failure      404 {object} dtos.NotFoundResponse
+// @Failure      500 {object} dtos.InternalServerErrorResponse
+// @Router       /lists/{id}/items [get]
+// @Router       /favorite [get]
+// @Router       /wishlist [get]
+// @Security BearerAuth
+func (su *SavedListUsecase) GetItems(ctx context.Context, userID string, listRef string, rp int64, p int64) ([]*dtos.SavedListItemResponse, int64, error) {
+	ctx, cancel := context.WithTimeout(ctx, su.contextTimeout)
+	defer cancel()
+
+	list, err := su.resolveList(ctx, userID, listRef)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	items, total, err := su.SavedListRepo.GetItemsWithPage(ctx, rp, p, bson.M{"list_id": list.ID}, bson.M{"created_at": -1})
+	if err != nil {
+		return nil, 0, err
+	}
+
+	res := make([]*dtos.SavedListItemResponse, 0, len(items))
+	if len(items) == 0 {
+		return res, total, nil
+	}
+
+	// ambil data produk terbaru sekaligus, bukan salinan saat produk disimpan
+	ids := make([]primitive.ObjectID, 0, len(items))
+	for _, item := range items {
+		ids = append(ids, item.ProdukID)
+	}
+
+	produk, _, err := su.ProdukRepo.GetAllWithPage(ctx, int64(len(ids)), 1, bson.M{"_id": bson.M{"$in": ids}}, bson.M{"_id": 1})
+	if err != nil {
+		return nil, 0, err
+	}
+
+	produkByID := make(map[primitive.ObjectID]*domain.Produk, len(produk))
+	for i := range produk {
+		produkByID[produk[i].ID] = &produk[i]
+	}
+
+	for i := range items {
+		res = append(res, toItemResponse(&items[i], produkByID[items[i].ProdukID]))
+	}
+
+	return res, total, nil
+}
+
+// AddSavedListItem godoc
+// @Summary      Add Produk to Saved List
+// @Description  Add produk to a saved list, a produk can only be saved once per list
+// @Tags         User - Saved List
+// @Accept       json
+// @Produce      json
+// @Param        id path string true "id list or favorite / wishlist"
+// @Param        request body dtos.SavedListItemRequest true "Payload Body [RAW]"
+// @Success      201 {object} dtos.SavedListItemOKResponse
+// @Failure      400 {object} dtos.BadRequestResponse
+// @Failure      401 {object} dtos.UnauthorizedResponse
+// @Failure      403 {object} dtos.ForbiddenResponse
+// @Failure      404 {object} dtos.NotFoundResponse
+// @Failure      500 {object} dtos.InternalServerErrorResponse
+// @Router       /lists/{id}/items [post]
+// @Router       /favorite [post]
+// @Router       /wishlist [post]
+// @Security BearerAuth
+func (su *SavedListUsecase) AddItem(ctx context.Context, userID string, listRef string, produkID string) (*dtos.SavedListItemResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, su.contextTimeout)
+	defer cancel()
+
+	list, err := su.resolveList(ctx, userID, listRef)
+	if err != nil {
+		return nil, err
+	}
+
+	produk, err := su.ProdukRepo.FindOne(ctx, produkID)
+	if err != nil || produk.DeletedAt != nil {
+		return nil, errors.New("produk not found")
+	}
+
+	item := &domain.SavedListItem{
+		ID:         primitive.NewObjectID(),
+		CreatedAt:  time.Now(),
+		ListID:     list.ID,
+		UserID:     list.UserID,
+		ProdukID:   produk.ID,
+		SavedPrice: produk.Price,
+	}
+
+	inserted, err := su.SavedListRepo.InsertItem(ctx, item)
+	if err != nil {
+		return nil, errors.New("cannot add produk to list")
+	}
+
+	if !inserted {
+		return nil, errors.New("produk already in list")
+	}
+
+	su.invalidateDashboard(ctx, list)
+
+	return toItemResponse(item, produk), nil
+}
+
+// RemoveSavedListItem godoc
+// @Summary      Remove Produk from Saved List
+// @Description  Remove produk from a saved list
+// @Tags         User - Saved List
+// @Accept       json
+// @Produce      json
+// @Param        id path string true "id list or favorite / wishlist"
+// @Param        produkId path string true "id produk"
+// @Success      200 {object} dtos.StatusOKDeletedResponse
+// @Failure      400 {object} dtos.BadRequestResponse
+// @Failure      401 {object} dtos.UnauthorizedResponse
+// @Failure      403 {object} dtos.ForbiddenResponse
+// @Failure      404 {object} dtos.NotFoundResponse
+// @Failure      500 {object} dtos.InternalServerErrorResponse
+// @Router       /lists/{id}/items/{produkId} [delete]
+// @Router       /favorite/{produkId} [delete]
+// @Router       /wishlist/{produkId} [delete]
+// @Security BearerAuth
+func (su *SavedListUsecase) RemoveItem(ctx context.Context, userID string, listRef string, produkID string) error {
+	ctx, cancel := context.WithTimeout(ctx, su.contextTimeout)
+	defer cancel()
+
+	list, err := su.resolveList(ctx, userID, listRef)
+	if err != nil {
+		return err
+	}
+
+	deleted, err := su.SavedListRepo.DeleteItem(ctx, list.ID, produkID)
+	if err != nil {
+		return err
+	}
+
+	if deleted == 0 {
+		return errors.New("produk tidak ada di list")
+	}
+
+	su.invalidateDashboard(ctx, list)
+
+	return nil
+}
+
+// ClearSavedList godoc
+// @Summary      Clear Saved List
+// @Description  Remove every produk from a saved list
+// @Tags         User - Saved List
+// @Accept       json
+// @Produce      json
+// @Param        id path string true "id list or favorite / wishlist"
+// @Success      200 {object} dtos.StatusOKDeletedResponse
+// @Failure      401 {object} dtos.UnauthorizedResponse
+// @Failure      403 {object} dtos.ForbiddenResponse
+// @Failure      404 {object} dtos.NotFoundResponse
+// @Failure      500 {object} dtos.InternalServerErrorResponse
+// @Router       /lists/{id}/items [delete]
+// @Router       /favorite [delete]
+// @Router       /wishlist [delete]
+// @Security BearerAuth
+func (su *SavedListUsecase) ClearItems(ctx context.Context, userID string, listRef string) error {
+	ctx, cancel := context.WithTimeout(ctx, su.contextTimeout)
+	defer cancel()
+
+	list, err := su.resolveList(ctx, userID, listRef)
+	if err != nil {
+		return err
+	}
+
+	err = su.SavedListRepo.DeleteItems(ctx, list.ID)
+	if err != nil {
+		return err
+	}
+
+	su.invalidateDashboard(ctx, list)
+
+	return nil
+}
+
+// MoveSavedListItemToCart godoc
+// @Summary      Move Saved Produk to Keranjang
+// @Description  Add a saved produk to Keranjang and remove it from the list
+// @Tags         User - Saved List
+// @Accept       json
+// @Produce      json
+// @Param        id path string true "id list or favorite / wishlist"
+// @Param        produkId path string true "id produk"
+// @Param        request body dtos.MoveToCartRequest false "Payload Body [RAW]"
+// @Success      200 {object} dtos.KeranjangOKResponse
+// @Failure      400 {object} dtos.BadRequestResponse
+// @Failure      401 {object} dtos.UnauthorizedResponse
+// @Failure      403 {object} dtos.ForbiddenResponse
+// @Failure      404 {object} dtos.NotFoundResponse
+// @Failure      500 {object} dtos.InternalServerErrorResponse
+// @Router       /lists/{id}/items/{produkId}/move-to-cart [post]
+// @Router       /favorite/{produkId}/move-to-cart [post]
+// @Router       /wishlist/{produkId}/move-to-cart [post]
+// @Security BearerAuth
+func (su *SavedListUsecase) MoveToCart(ctx context.Context, userID string, listRef string, produkID string, quantity int) (*domain.InsertKeranjangResponse, error) {
+	if quantity <= 0 {
+		quantity = 1
+	}
+
+	list, err := su.resolveList(ctx, userID, listRef)
+	if err != nil {
+		return nil, err
+	}
+
+	produkIDHex, err := primitive.ObjectIDFromHex(produkID)
+	if err != nil {
+		return nil, errors.New("produk not found")
+	}
+
+	items, _, err := su.SavedListRepo.GetItemsWithPage(ctx, 1, 1, bson.M{"list_id": list.ID, "produk_id": produkIDHex}, bson.M{"created_at": -1})
+	if err != nil || len(items) == 0 {
+		return nil, errors.New("produk tidak ada di list")
+	}
+
+	// Keranjang usecase sudah memeriksa stok dan menggabungkan produk yang sama
+	if _, err := su.KeranjangUsecase.FindOne(ctx, userID); err != nil {
+		_, err = su.KeranjangUsecase.InsertOne(ctx, &domain.InsertKeranjangRequest{
+			UserID:   userID,
+			ProdukID: produkID,
+			Total:    quantity,
+		})
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		_, err = su.KeranjangUsecase.UpdateOne(ctx, userID, &domain.Keranjang{
+			Produk: []domain.Produk{{ID: produkIDHex, Stock: int64(quantity)}},
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if _, err := su.SavedListRepo.DeleteItem(ctx, list.ID, produkID); err != nil {
+		return nil, err
+	}
+
+	su.invalidateDashboard(ctx, list)
+
+	return su.KeranjangUsecase.FindOne(ctx, userID)
+}
+
+// MigrateSavedList godoc
+// @Summary      Migrate Favorite and Wishlist
+// @Description  Copy legacy favorite and wishlist documents into saved lists, safe to run more than once
+// @Tags         Admin - Saved List
+// @Accept       json
+// @Produce      json
+// @Success      200 {object} dtos.SavedListMigrationOKResponse
+// @Failure      401 {object} dtos.UnauthorizedResponse
+// @Failure      403 {object} dtos.ForbiddenResponse
+// @Failure      500 {object} dtos.InternalServerErrorResponse
+// @Router       /lists/migrate [post]
+// @Security BearerAuth
+func (su *SavedListUsecase) Migrate(ctx context.Context) (*dtos.SavedListMigrationResponse, error) {
+	res := &dtos.SavedListMigrationResponse{}
+
+	for _, kind := range []string{domain.SavedListKindFavorite, domain.SavedListKindWishlist} {
+		legacy, err := su.SavedListRepo.GetLegacy(ctx, kind)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, doc := range legacy {
+			res.LegacyRecords++
+
+			list, err := su.SavedListRepo.FindListByKind(ctx, doc.UserID.Hex(), kind)
+			if err != nil {
+				list = &domain.SavedList{
+					ID:        primitive.NewObjectID(),
+					CreatedAt: doc.CreatedAt,
+					UpdatedAt: time.Now(),
+					UserID:    doc.UserID,
+					Name:      defaultListName[kind],
+					Kind:      kind,
+				}
+
+				if _, err := su.SavedListRepo.InsertList(ctx, list); err != nil {
+					return nil, err
+				}
+				res.Lists++
+			}
+
+			for _, p := range doc.Produk {
+				inserted, err := su.SavedListRepo.InsertItem(ctx, &domain.SavedListItem{
+					ID:         primitive.NewObjectID(),
+					CreatedAt:  doc.CreatedAt,
+					ListID:     list.ID,
+					UserID:     doc.UserID,
+					ProdukID:   p.ID,
+					SavedPrice: p.Price,
+				})
+				if err != nil {
+					return nil, err
+				}
+
+				if inserted {
+					res.Items++
+				} else {
+					res.SkippedItems++
+				}
+			}
+		}
+	}
+
+	return res, nil
+}
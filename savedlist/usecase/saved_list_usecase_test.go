@@ -0,0 +1,520 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+	"warunk-bem/cache"
+	"warunk-bem/domain"
+	"warunk-bem/dtos"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// mockSavedListRepo menyimpan list dan item di memori, InsertItem meniru unique index list_id + produk_id
+type mockSavedListRepo struct {
+	domain.SavedListRepository
+
+	lists  []*domain.SavedList
+	items  []domain.SavedListItem
+	legacy map[string][]domain.LegacySavedList
+}
+
+func (m *mockSavedListRepo) InsertList(ctx context.Context, req *domain.SavedList) (*domain.SavedList, error) {
+	m.lists = append(m.lists, req)
+	return req, nil
+}
+
+func (m *mockSavedListRepo) FindList(ctx context.Context, id string, userID string) (*domain.SavedList, error) {
+	for _, l := range m.lists {
+		if l.ID.Hex() == id && l.UserID.Hex() == userID {
+			copied := *l
+			return &copied, nil
+		}
+	}
+	return nil, errors.New("not found")
+}
+
+func (m *mockSavedListRepo) FindListByKind(ctx context.Context, userID string, kind string) (*domain.SavedList, error) {
+	for _, l := range m.lists {
+		if l.Kind == kind && l.UserID.Hex() == userID {
+			copied := *l
+			return &copied, nil
+		}
+	}
+	return nil, errors.New("not found")
+}
+
+func (m *mockSavedListRepo) FindListByName(ctx context.Context, userID string, name string) (*domain.SavedList, error) {
+	for _, l := range m.lists {
+		if l.Name == name && l.UserID.Hex() == userID {
+			copied := *l
+			return &copied, nil
+		}
+	}
+	return nil, errors.New("not found")
+}
+
+func (m *mockSavedListRepo) UpdateList(ctx context.Context, list *domain.SavedList) (*domain.SavedList, error) {
+	for i, l := range m.lists {
+		if l.ID == list.ID {
+			m.lists[i] = list
+			return list, nil
+		}
+	}
+	return nil, errors.New("not found")
+}
+
+func (m *mockSavedListRepo) DeleteList(ctx context.Context, id string) error {
+	for i, l := range m.lists {
+		if l.ID.Hex() == id {
+			m.lists = append(m.lists[:i], m.lists[i+1:]...)
+			return nil
+		}
+	}
+	return errors.New("not found")
+}
+
+func (m *mockSavedListRepo) InsertItem(ctx context.Context, req *domain.SavedListItem) (bool, error) {
+	for _, item := range m.items {
+		if item.ListID == req.ListID && item.ProdukID == req.ProdukID {
+			return false, nil
+		}
+	}
+	m.items = append(m.items, *req)
+	return true, nil
+}
+
+func (m *mockSavedListRepo) GetItemsWithPage(ctx context.Context, rp int64, p int64, filter interface{}, setsort interface{}) ([]domain.SavedListItem, int64, error) {
+	f := filter.(bson.M)
+	res := []domain.SavedListItem{}
+	for _, item := range m.items {
+		if item.ListID != f["list_id"] {
+			continue
+		}
+		if produkID, ok := f["produk_id"]; ok && item.ProdukID != produkID {
+			continue
+		}
+		res = append(res, item)
+	}
+	return res, int64(len(res)), nil
+}
+
+func (m *mockSavedListRepo) CountItems(ctx context.Context, listID primitive.ObjectID) (int64, error) {
+	_, total, err := m.GetItemsWithPage(ctx, 0, 0, bson.M{"list_id": listID}, nil)
+	return total, err
+}
+
+func (m *mockSavedListRepo) DeleteItem(ctx context.Context, listID primitive.ObjectID, produkID string) (int64, error) {
+	for i, item := range m.items {
+		if item.ListID == listID && item.ProdukID.Hex() == produkID {
+			m.items = append(m.items[:i], m.items[i+1:]...)
+			return 1, nil
+		}
+	}
+	return 0, nil
+}
+
+func (m *mockSavedListRepo) DeleteItems(ctx context.Context, listID primitive.ObjectID) error {
+	items := m.items[:0]
+	for _, item := range m.items {
+		if item.ListID != listID {
+			items = append(items, item)
+		}
+	}
+	m.items = items
+	return nil
+}
+
+func (m *mockSavedListRepo) GetLegacy(ctx context.Context, kind string) ([]domain.LegacySavedList, error) {
+	return m.legacy[kind], nil
+}
+
+type mockProdukRepo struct {
+	domain.ProdukRepository
+
+	produks map[string]*domain.Produk
+}
+
+func (m *mockProdukRepo) FindOne(ctx context.Context, id string) (*domain.Produk, error) {
+	p, ok := m.produks[id]
+	if !ok {
+		return nil, errors.New("not found")
+	}
+	copied := *p
+	return &copied, nil
+}
+
+func (m *mockProdukRepo) GetAllWithPage(ctx context.Context, rp int64, p int64, filter interface{}, setsort interface{}) ([]domain.Produk, int64, error) {
+	ids := filter.(bson.M)["_id"].(bson.M)["$in"].([]primitive.ObjectID)
+	res := []domain.Produk{}
+	for _, id := range ids {
+		if produk, ok := m.produks[id.Hex()]; ok {
+			res = append(res, *produk)
+		}
+	}
+	return res, int64(len(res)), nil
+}
+
+type mockUserRepo struct {
+	domain.UserRepository
+}
+
+func (m *mockUserRepo) FindOne(ctx context.Context, id string) (*domain.User, error) {
+	userID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, err
+	}
+	return &domain.User{ID: userID}, nil
+}
+
+// mockKeranjangUsecase mencatat jumlah produk yang masuk keranjang per produk
+type mockKeranjangUsecase struct {
+	domain.KeranjangUsecase
+
+	exists bool
+	added  map[string]int
+	err    error
+}
+
+func (m *mockKeranjangUsecase) FindOne(ctx context.Context, id string) (*domain.InsertKeranjangResponse, error) {
+	if !m.exists {
+		return nil, errors.New("not found")
+	}
+	return &domain.InsertKeranjangResponse{}, nil
+}
+
+func (m *mockKeranjangUsecase) InsertOne(ctx context.Context, req *domain.InsertKeranjangRequest) (*domain.InsertKeranjangResponse, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+	m.exists = true
+	m.added[req.ProdukID] += req.Total
+	return &domain.InsertKeranjangResponse{}, nil
+}
+
+func (m *mockKeranjangUsecase) UpdateOne(ctx context.Context, id string, req *domain.Keranjang) (*domain.Keranjang, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+	for _, p := range req.Produk {
+		m.added[p.ID.Hex()] += int(p.Stock)
+	}
+	return req, nil
+}
+
+func newTestSavedListUsecase(repo *mockSavedListRepo, produks ...*domain.Produk) *SavedListUsecase {
+	produkRepo := &mockProdukRepo{produks: map[string]*domain.Produk{}}
+	for _, p := range produks {
+		produkRepo.produks[p.ID.Hex()] = p
+	}
+	return NewSavedListUsecase(repo, produkRepo, &mockUserRepo{}, &mockKeranjangUsecase{added: map[string]int{}}, cache.NewMemoryCache(), time.Second).(*SavedListUsecase)
+}
+
+func TestAddItem(t *testing.T) {
+	userID := primitive.NewObjectID().Hex()
+	kopi := &domain.Produk{ID: primitive.NewObjectID(), Name: "Kopi", Price: 5000, Stock: 3}
+	deletedAt := time.Now()
+	lama := &domain.Produk{ID: primitive.NewObjectID(), Name: "Lama", Price: 1000, DeletedAt: &deletedAt}
+
+	repo := &mockSavedListRepo{}
+	su := newTestSavedListUsecase(repo, kopi, lama)
+
+	res, err := su.AddItem(context.Background(), userID, domain.SavedListKindFavorite, kopi.ID.Hex())
+	if err != nil {
+		t.Fatalf("err = %v", err)
+	}
+	if res.SavedPrice != 5000 || !res.Available {
+		t.Errorf("item = %+v, want saved price 5000 and available", res)
+	}
+	if len(repo.lists) != 1 || repo.lists[0].Kind != domain.SavedListKindFavorite {
+		t.Fatalf("lists = %+v, want one favorite list created on first use", repo.lists)
+	}
+
+	tests := []struct {
+		name     string
+		listRef  string
+		produkID string
+		wantErr  bool
+	}{
+		{name: "same produk twice is rejected", listRef: domain.SavedListKindFavorite, produkID: kopi.ID.Hex(), wantErr: true},
+		{name: "same produk in another list", listRef: domain.SavedListKindWishlist, produkID: kopi.ID.Hex()},
+		{name: "deleted produk", listRef: domain.SavedListKindFavorite, produkID: lama.ID.Hex(), wantErr: true},
+		{name: "unknown produk", listRef: domain.SavedListKindFavorite, produkID: primitive.NewObjectID().Hex(), wantErr: true},
+		{name: "list of another user", listRef: primitive.NewObjectID().Hex(), produkID: kopi.ID.Hex(), wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := su.AddItem(context.Background(), userID, tt.listRef, tt.produkID)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	if len(repo.items) != 2 {
+		t.Errorf("items = %d, want 2", len(repo.items))
+	}
+}
+
+func TestCreateAndRenameList(t *testing.T) {
+	userID := primitive.NewObjectID().Hex()
+	repo := &mockSavedListRepo{}
+	su := newTestSavedListUsecase(repo)
+
+	list, err := su.CreateList(context.Background(), userID, &dtos.SavedListRequest{Name: "  Jajan  "})
+	if err != nil {
+		t.Fatalf("err = %v", err)
+	}
+	if list.Name != "Jajan" || list.Kind != domain.SavedListKindCustom {
+		t.Fatalf("list = %+v, want custom list Jajan", list)
+	}
+
+	if _, err := su.CreateList(context.Background(), userID, &dtos.SavedListRequest{Name: "Jajan"}); err == nil {
+		t.Error("duplicate name should fail")
+	}
+	if _, err := su.CreateList(context.Background(), userID, &dtos.SavedListRequest{Name: " "}); err == nil {
+		t.Error("empty name should fail")
+	}
+	if _, err := su.CreateList(context.Background(), primitive.NewObjectID().Hex(), &dtos.SavedListRequest{Name: "Jajan"}); err != nil {
+		t.Errorf("another user can use the same name, err = %v", err)
+	}
+
+	other, err := su.CreateList(context.Background(), userID, &dtos.SavedListRequest{Name: "Sarapan"})
+	if err != nil {
+		t.Fatalf("err = %v", err)
+	}
+	if _, err := su.RenameList(context.Background(), userID, other.ID, &dtos.SavedListRequest{Name: "Jajan"}); err == nil {
+		t.Error("renaming to a used name should fail")
+	}
+	if _, err := su.RenameList(context.Background(), userID, list.ID, &dtos.SavedListRequest{Name: "Jajan"}); err != nil {
+		t.Errorf("renaming to its own name, err = %v", err)
+	}
+	renamed, err := su.RenameList(context.Background(), userID, other.ID, &dtos.SavedListRequest{Name: "Makan Siang"})
+	if err != nil || renamed.Name != "Makan Siang" {
+		t.Errorf("rename = %+v, %v", renamed, err)
+	}
+}
+
+func TestDeleteList(t *testing.T) {
+	userID := primitive.NewObjectID().Hex()
+	repo := &mockSavedListRepo{}
+	su := newTestSavedListUsecase(repo)
+
+	list, err := su.CreateList(context.Background(), userID, &dtos.SavedListRequest{Name: "Jajan"})
+	if err != nil {
+		t.Fatalf("err = %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		listRef string
+		wantErr bool
+	}{
+		{name: "favorite cannot be deleted", listRef: domain.SavedListKindFavorite, wantErr: true},
+		{name: "wishlist cannot be deleted", listRef: domain.SavedListKindWishlist, wantErr: true},
+		{name: "custom list", listRef: list.ID},
+		{name: "deleted list is gone", listRef: list.ID, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := su.DeleteList(context.Background(), userID, tt.listRef)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestMoveToCart(t *testing.T) {
+	userID := primitive.NewObjectID().Hex()
+	kopi := &domain.Produk{ID: primitive.NewObjectID(), Name: "Kopi", Price: 5000, Stock: 3}
+
+	tests := []struct {
+		name         string
+		cartExists   bool
+		quantity     int
+		saved        bool
+		cartErr      error
+		wantErr      bool
+		wantAdded    int
+		wantRemained int
+	}{
+		{name: "new keranjang", saved: true, quantity: 2, wantAdded: 2},
+		{name: "existing keranjang", cartExists: true, saved: true, quantity: 1, wantAdded: 1},
+		{name: "zero quantity defaults to one", saved: true, wantAdded: 1},
+		{name: "produk not saved", wantErr: true},
+		{name: "keranjang rejects keeps item", saved: true, quantity: 5, cartErr: errors.New("stok tidak mencukupi"), wantErr: true, wantRemained: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &mockSavedListRepo{}
+			su := newTestSavedListUsecase(repo, kopi)
+			keranjang := &mockKeranjangUsecase{exists: tt.cartExists, added: map[string]int{}, err: tt.cartErr}
+			su.KeranjangUsecase = keranjang
+
+			if tt.saved {
+				if _, err := su.AddItem(context.Background(), userID, domain.SavedListKindWishlist, kopi.ID.Hex()); err != nil {
+					t.Fatalf("err = %v", err)
+				}
+			}
+
+			_, err := su.MoveToCart(context.Background(), userID, domain.SavedListKindWishlist, kopi.ID.Hex(), tt.quantity)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got := keranjang.added[kopi.ID.Hex()]; got != tt.wantAdded {
+				t.Errorf("added to keranjang = %d, want %d", got, tt.wantAdded)
+			}
+			if len(repo.items) != tt.wantRemained {
+				t.Errorf("items left = %d, want %d", len(repo.items), tt.wantRemained)
+			}
+		})
+	}
+}
+
+func TestMigrate(t *testing.T) {
+	userID := primitive.NewObjectID()
+	kopi := domain.Produk{ID: primitive.NewObjectID(), Price: 5000}
+	roti := domain.Produk{ID: primitive.NewObjectID(), Price: 3000}
+
+	repo := &mockSavedListRepo{legacy: map[string][]domain.LegacySavedList{
+		domain.SavedListKindFavorite: {{ID: primitive.NewObjectID(), UserID: userID, Produk: []domain.Produk{kopi, roti, kopi}}},
+		domain.SavedListKindWishlist: {{ID: primitive.NewObjectID(), UserID: userID, Produk: []domain.Produk{roti}}},
+	}}
+	su := newTestSavedListUsecase(repo)
+
+	res, err := su.Migrate(context.Background())
+	if err != nil {
+		t.Fatalf("err = %v", err)
+	}
+	want := dtos.SavedListMigrationResponse{Lists: 2, Items: 3, SkippedItems: 1, LegacyRecords: 2}
+	if *res != want {
+		t.Fatalf("first run = %+v, want %+v", *res, want)
+	}
+
+	// migrasi ulang tidak menggandakan list maupun item
+	res, err = su.Migrate(context.Background())
+	if err != nil {
+		t.Fatalf("err = %v", err)
+	}
+	want = dtos.SavedListMigrationResponse{Lists: 0, Items: 0, SkippedItems: 4, LegacyRecords: 2}
+	if *res != want {
+		t.Fatalf("second run = %+v, want %+v", *res, want)
+	}
+	if len(repo.lists) != 2 || len(repo.items) != 3 {
+		t.Errorf("lists = %d items = %d, want 2 and 3", len(repo.lists), len(repo.items))
+	}
+}
+
+func TestGetItems(t *testing.T) {
+	userID := primitive.NewObjectID().Hex()
+	kopi := &domain.Produk{ID: primitive.NewObjectID(), Name: "Kopi", Price: 5000, Stock: 3}
+	roti := &domain.Produk{ID: primitive.NewObjectID(), Name: "Roti", Price: 3000, Stock: 2}
+	hapus := &domain.Produk{ID: primitive.NewObjectID(), Name: "Hapus", Price: 1000, Stock: 1}
+
+	repo := &mockSavedListRepo{}
+	su := newTestSavedListUsecase(repo, kopi, roti, hapus)
+	for _, p := range []*domain.Produk{kopi, roti, hapus} {
+		if _, err := su.AddItem(context.Background(), userID, domain.SavedListKindWishlist, p.ID.Hex()); err != nil {
+			t.Fatalf("err = %v", err)
+		}
+	}
+
+	// harga naik, stok habis dan produk dihapus setelah disimpan
+	kopi.Price = 6000
+	roti.Stock = 0
+	delete(su.ProdukRepo.(*mockProdukRepo).produks, hapus.ID.Hex())
+
+	items, total, err := su.GetItems(context.Background(), userID, domain.SavedListKindWishlist, 10, 1)
+	if err != nil {
+		t.Fatalf("err = %v", err)
+	}
+	if total != 3 || len(items) != 3 {
+		t.Fatalf("total = %d items = %d, want 3", total, len(items))
+	}
+
+	byProduk := map[string]*dtos.SavedListItemResponse{}
+	for _, item := range items {
+		byProduk[item.ProdukID] = item
+	}
+
+	if got := byProduk[kopi.ID.Hex()]; got.Price != 6000 || got.SavedPrice != 5000 || !got.Available {
+		t.Errorf("kopi = %+v, want live price 6000, saved price 5000, available", got)
+	}
+	if got := byProduk[roti.ID.Hex()]; got.Available || got.Stock != 0 {
+		t.Errorf("roti = %+v, want unavailable", got)
+	}
+	if got := byProduk[hapus.ID.Hex()]; !got.Deleted {
+		t.Errorf("hapus = %+v, want deleted", got)
+	}
+
+	empty, total, err := su.GetItems(context.Background(), userID, domain.SavedListKindFavorite, 10, 1)
+	if err != nil || total != 0 || len(empty) != 0 {
+		t.Errorf("favorite = %v, %d, %v, want empty", empty, total, err)
+	}
+}
+
+func TestRemoveItem(t *testing.T) {
+	userID := primitive.NewObjectID().Hex()
+	kopi := &domain.Produk{ID: primitive.NewObjectID(), Name: "Kopi", Price: 5000, Stock: 3}
+
+	repo := &mockSavedListRepo{}
+	su := newTestSavedListUsecase(repo, kopi)
+	if _, err := su.AddItem(context.Background(), userID, domain.SavedListKindFavorite, kopi.ID.Hex()); err != nil {
+		t.Fatalf("err = %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		userID   string
+		listRef  string
+		produkID string
+		wantErr  bool
+	}{
+		{name: "produk in another list", userID: userID, listRef: domain.SavedListKindWishlist, produkID: kopi.ID.Hex(), wantErr: true},
+		{name: "list of another user", userID: userID, listRef: primitive.NewObjectID().Hex(), produkID: kopi.ID.Hex(), wantErr: true},
+		{name: "removes produk", userID: userID, listRef: domain.SavedListKindFavorite, produkID: kopi.ID.Hex()},
+		{name: "already removed", userID: userID, listRef: domain.SavedListKindFavorite, produkID: kopi.ID.Hex(), wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := su.RemoveItem(context.Background(), tt.userID, tt.listRef, tt.produkID)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestClearItems(t *testing.T) {
+	userID := primitive.NewObjectID().Hex()
+	kopi := &domain.Produk{ID: primitive.NewObjectID(), Name: "Kopi", Price: 5000, Stock: 3}
+	roti := &domain.Produk{ID: primitive.NewObjectID(), Name: "Roti", Price: 3000, Stock: 2}
+
+	repo := &mockSavedListRepo{}
+	su := newTestSavedListUsecase(repo, kopi, roti)
+	for _, listRef := range []string{domain.SavedListKindFavorite, domain.SavedListKindWishlist} {
+		for _, p := range []*domain.Produk{kopi, roti} {
+			if _, err := su.AddItem(context.Background(), userID, listRef, p.ID.Hex()); err != nil {
+				t.Fatalf("err = %v", err)
+			}
+		}
+	}
+
+	if err := su.ClearItems(context.Background(), userID, domain.SavedListKindFavorite); err != nil {
+		t.Fatalf("err = %v", err)
+	}
+
+	favorite, _, _ := su.GetItems(context.Background(), userID, domain.SavedListKindFavorite, 10, 1)
+	wishlist, _, _ := su.GetItems(context.Background(), userID, domain.SavedListKindWishlist, 10, 1)
+	if len(favorite) != 0 || len(wishlist) != 2 {
+		t.Errorf("favorite = %d wishlist = %d, want 0 and 2", len(favorite), len(wishlist))
+	}
+}
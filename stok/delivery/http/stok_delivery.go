@@ -0,0 +1,190 @@
+package http
+
+import (
+	"context"
+	"math"
+	"net/http"
+	"strconv"
+	"warunk-bem/domain"
+	"warunk-bem/dtos"
+	"warunk-bem/middlewares"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+)
+
+type StokHandler struct {
+	StokUsecase domain.StokUsecase
+}
+
+func NewStokHandler(protectedAdmin *gin.RouterGroup, su domain.StokUsecase) {
+	handler := &StokHandler{
+		StokUsecase: su,
+	}
+
+	protectedAdmin = protectedAdmin.Group("/stok")
+
+	protectedAdmin.POST("/adjust", handler.Adjust)
+	protectedAdmin.GET("/:id", handler.GetHistory)
+	protectedAdmin.GET("/:id/reconcile", handler.Reconcile)
+}
+
+func isRequestValid(m *dtos.StokAdjustRequest) (bool, error) {
+	validate := validator.New()
+	err := validate.Struct(m)
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func (sh *StokHandler) Adjust(c *gin.Context) {
+	var req dtos.StokAdjustRequest
+
+	idAdmin, err := middlewares.IsAdmin(c)
+	if err != nil {
+		c.JSON(
+			http.StatusUnauthorized,
+			dtos.NewErrorResponse(
+				http.StatusUnauthorized,
+				"Unauthorized",
+				dtos.GetErrorData(err),
+			),
+		)
+		return
+	}
+
+	err = c.ShouldBindJSON(&req)
+	if err != nil {
+		c.JSON(
+			http.StatusUnprocessableEntity,
+			dtos.NewErrorResponse(
+				http.StatusUnprocessableEntity,
+				"Filed Cannot Be Empty",
+				dtos.GetErrorData(err),
+			),
+		)
+		return
+	}
+
+	if ok, err := isRequestValid(&req); !ok {
+		c.JSON(
+			http.StatusBadRequest,
+			dtos.NewErrorResponse(
+				http.StatusBadRequest,
+				"Bad Request",
+				dtos.GetErrorData(err),
+			),
+		)
+		return
+	}
+
+	ctx := c.Request.Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	result, err := sh.StokUsecase.Adjust(ctx, &req, idAdmin)
+	if err != nil {
+		c.JSON(
+			http.StatusBadRequest,
+			dtos.NewErrorResponse(
+				http.StatusBadRequest,
+				"Cannot Adjust Stok",
+				err.Error(),
+			),
+		)
+		return
+	}
+
+	c.JSON(
+		http.StatusCreated,
+		dtos.NewResponse(
+			http.StatusCreated,
+			"Success Adjust Stok",
+			result,
+		),
+	)
+}
+
+func (sh *StokHandler) GetHistory(c *gin.Context) {
+	id := c.Param("id")
+
+	rp, err := strconv.ParseInt(c.Query("rp"), 10, 64)
+	if err != nil {
+		rp = 25
+	}
+
+	page, err := strconv.ParseInt(c.Query("p"), 10, 64)
+	if err != nil {
+		page = 1
+	}
+
+	ctx := c.Request.Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	res, count, err := sh.StokUsecase.GetHistory(ctx, id, rp, page)
+	if err != nil {
+		c.JSON(
+			http.StatusBadRequest,
+			dtos.NewErrorResponse(
+				http.StatusBadRequest,
+				"Cannot Get Stok History",
+				err.Error(),
+			),
+		)
+		return
+	}
+
+	result := dtos.GetAllStokMovementResponse{
+		Total:       count,
+		PerPage:     rp,
+		CurrentPage: page,
+		LastPage:    int64(math.Ceil(float64(count) / float64(rp))),
+		From:        (page * rp) - rp + 1,
+		To:          page * rp,
+		Movement:    res,
+	}
+
+	c.JSON(
+		http.StatusOK,
+		dtos.NewResponse(
+			http.StatusOK,
+			"Success Get Stok History",
+			result,
+		),
+	)
+}
+
+func (sh *StokHandler) Reconcile(c *gin.Context) {
+	id := c.Param("id")
+
+	ctx := c.Request.Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	result, err := sh.StokUsecase.Reconcile(ctx, id)
+	if err != nil {
+		c.JSON(
+			http.StatusNotFound,
+			dtos.NewErrorResponse(
+				http.StatusNotFound,
+				"Cannot Reconcile Stok",
+				err.Error(),
+			),
+		)
+		return
+	}
+
+	c.JSON(
+		http.StatusOK,
+		dtos.NewResponse(
+			http.StatusOK,
+			"Success Reconcile Stok",
+			result,
+		),
+	)
+}
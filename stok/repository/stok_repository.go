@@ -0,0 +1,103 @@
+package repository
+
+import (
+	"context"
+	"warunk-bem/domain"
+	"warunk-bem/mongo"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type stokRepository struct {
+	DB         mongo.Database
+	Collection mongo.Collection
+}
+
+const (
+	timeFormat     = "2006-01-02T15:04:05.999Z07:00" // reduce precision from RFC3339Nano as date format
+	collectionName = "stok_movement"
+)
+
+func NewStokRepository(DB mongo.Database) domain.StokRepository {
+	return &stokRepository{DB, DB.Collection(collectionName)}
+}
+
+func (r *stokRepository) InsertOne(ctx context.Context, req *domain.StokMovement) (*domain.StokMovement, error) {
+	var err error
+
+	_, err = r.Collection.InsertOne(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	return req, nil
+}
+
+func (r *stokRepository) GetAllWithPage(ctx context.Context, rp int64, p int64, filter interface{}, setsort interface{}) ([]domain.StokMovement, int64, error) {
+	var (
+		movement []domain.StokMovement
+		err      error
+	)
+
+	findOptions := options.Find()
+	findOptions.SetLimit(rp)
+	findOptions.SetSkip((p - 1) * rp)
+	if setsort != nil {
+		findOptions.SetSort(setsort)
+	}
+
+	cursor, err := r.Collection.Find(ctx, filter, findOptions)
+	if err != nil {
+		return movement, 0, err
+	}
+
+	err = cursor.All(ctx, &movement)
+	if err != nil {
+		return movement, 0, err
+	}
+
+	total, err := r.Collection.CountDocuments(ctx, filter)
+	if err != nil {
+		return movement, 0, err
+	}
+
+	return movement, total, nil
+}
+
+// SumDelta menjumlahkan seluruh delta jurnal sebuah produk, mengembalikan total delta dan jumlah baris
+func (r *stokRepository) SumDelta(ctx context.Context, produkID string) (int64, int64, error) {
+	idHex, err := primitive.ObjectIDFromHex(produkID)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	pipeline := []bson.M{
+		{"$match": bson.M{"produk_id": idHex}},
+		{"$group": bson.M{
+			"_id":   "$produk_id",
+			"total": bson.M{"$sum": "$delta"},
+			"count": bson.M{"$sum": 1},
+		}},
+	}
+
+	cursor, err := r.Collection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return 0, 0, err
+	}
+	defer cursor.Close(ctx)
+
+	var result struct {
+		Total int64 `bson:"total"`
+		Count int64 `bson:"count"`
+	}
+	if cursor.Next(ctx) {
+		err = cursor.Decode(&result)
+		if err != nil {
+			return 0, 0, err
+		}
+	}
+
+	return result.Total, result.Count, nil
+}
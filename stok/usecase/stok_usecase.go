@@ -0,0 +1,210 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+	"warunk-bem/cache"
+	"warunk-bem/domain"
+	"warunk-bem/dtos"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type stokUsecase struct {
+	StokRepo          domain.StokRepository
+	ProdukRepo        domain.ProdukRepository
+	NotifikasiUsecase domain.NotifikasiUsecase
+	Cache             domain.Cache
+	contextTimeout    time.Duration
+}
+
+func NewStokUsecase(StokRepo domain.StokRepository, ProdukRepo domain.ProdukRepository, NotifikasiUsecase domain.NotifikasiUsecase, Cache domain.Cache, contextTimeout time.Duration) domain.StokUsecase {
+	return &stokUsecase{
+		StokRepo:          StokRepo,
+		ProdukRepo:        ProdukRepo,
+		NotifikasiUsecase: NotifikasiUsecase,
+		Cache:             Cache,
+		contextTimeout:    contextTimeout,
+	}
+}
+
+func toStokMovementResponse(movement *domain.StokMovement) *dtos.StokMovementResponse {
+	res := &dtos.StokMovementResponse{
+		ID:          movement.ID.Hex(),
+		CreatedAt:   movement.CreatedAt,
+		ProdukID:    movement.ProdukID.Hex(),
+		Type:        movement.Type,
+		Delta:       movement.Delta,
+		StockBefore: movement.StockBefore,
+		StockAfter:  movement.StockAfter,
+		Reason:      movement.Reason,
+	}
+
+	if !movement.ActorID.IsZero() {
+		res.ActorID = movement.ActorID.Hex()
+	}
+
+	if !movement.ReferenceID.IsZero() {
+		res.ReferenceID = movement.ReferenceID.Hex()
+	}
+
+	return res
+}
+
+// AdjustStok godoc
+// @Summary      Adjust Stok
+// @Description  Restock, record spoilage or correct produk stock. Quantity is always positive for restock and spoilage, correction accepts a signed delta
+// @Tags         Admin - Stok
+// @Accept       json
+// @Produce      json
+// @Param        request body dtos.StokAdjustRequest true "Payload Body [RAW]"
+// @Success      201 {object} dtos.StokMovementResponse
+// @Failure      400 {object} dtos.BadRequestResponse
+// @Failure      401 {object} dtos.UnauthorizedResponse
+// @Failure      403 {object} dtos.ForbiddenResponse
+// @Failure      404 {object} dtos.NotFoundResponse
+// @Failure      500 {object} dtos.InternalServerErrorResponse
+// @Router       /stok/adjust [post]
+// @Security BearerAuth
+func (su *stokUsecase) Adjust(c context.Context, req *dtos.StokAdjustRequest, adminID string) (*dtos.StokMovementResponse, error) {
+	ctx, cancel := context.WithTimeout(c, su.contextTimeout)
+	defer cancel()
+
+	adminHex, err := primitive.ObjectIDFromHex(adminID)
+	if err != nil {
+		return nil, err
+	}
+
+	delta := req.Quantity
+	switch req.Type {
+	case domain.StokMovementRestock:
+		if delta <= 0 {
+			return nil, errors.New("quantity restock harus lebih dari 0")
+		}
+	case domain.StokMovementSpoilage:
+		if delta <= 0 {
+			return nil, errors.New("quantity spoilage harus lebih dari 0")
+		}
+		delta = -delta
+	case domain.StokMovementCorrection:
+		if delta == 0 {
+			return nil, errors.New("quantity koreksi tidak boleh 0")
+		}
+	default:
+		return nil, errors.New("type harus restock, spoilage atau correction")
+	}
+
+	produk, err := su.ProdukRepo.IncrementStock(ctx, req.ProdukID, delta)
+	if err != nil {
+		return nil, err
+	}
+
+	movement := domain.NewStokMovement(produk, req.Type, produk.Stock-delta, req.Reason, adminHex, primitive.NilObjectID)
+	_, err = su.StokRepo.InsertOne(ctx, movement)
+	if err != nil {
+		return nil, errors.New("cannot record stok movement")
+	}
+
+	cache.InvalidateProduk(c, su.Cache)
+
+	before := *produk
+	before.Stock = movement.StockBefore
+	su.notifyWishlist(before, *produk)
+
+	return toStokMovementResponse(movement), nil
+}
+
+// GetStokHistory godoc
+// @Summary      Get Stok History
+// @Description  Get stock movement journal of a produk, newest first
+// @Tags         Admin - Stok
+// @Accept       json
+// @Produce      json
+// @Param        id path string true "id produk"
+// @Success      200 {object} dtos.GetAllStokMovementResponse
+// @Failure      400 {object} dtos.BadRequestResponse
+// @Failure      401 {object} dtos.UnauthorizedResponse
+// @Failure      403 {object} dtos.ForbiddenResponse
+// @Failure      404 {object} dtos.NotFoundResponse
+// @Failure      500 {object} dtos.InternalServerErrorResponse
+// @Router       /stok/{id} [get]
+// @Security BearerAuth
+func (su *stokUsecase) GetHistory(c context.Context, produkID string, rp int64, p int64) ([]*dtos.StokMovementResponse, int64, error) {
+	res := []*dtos.StokMovementResponse{}
+
+	ctx, cancel := context.WithTimeout(c, su.contextTimeout)
+	defer cancel()
+
+	produkHex, err := primitive.ObjectIDFromHex(produkID)
+	if err != nil {
+		return res, 0, err
+	}
+
+	movements, count, err := su.StokRepo.GetAllWithPage(ctx, rp, p, bson.M{"produk_id": produkHex}, bson.D{{Key: "created_at", Value: -1}, {Key: "_id", Value: -1}})
+	if err != nil {
+		return res, 0, err
+	}
+
+	for i := range movements {
+		res = append(res, toStokMovementResponse(&movements[i]))
+	}
+
+	return res, count, nil
+}
+
+// ReconcileStok godoc
+// @Summary      Reconcile Stok
+// @Description  Compare current produk stock with the sum of its movement journal
+// @Tags         Admin - Stok
+// @Accept       json
+// @Produce      json
+// @Param        id path string true "id produk"
+// @Success      200 {object} dtos.StokReconcileResponse
+// @Failure      400 {object} dtos.BadRequestResponse
+// @Failure      401 {object} dtos.UnauthorizedResponse
+// @Failure      403 {object} dtos.ForbiddenResponse
+// @Failure      404 {object} dtos.NotFoundResponse
+// @Failure      500 {object} dtos.InternalServerErrorResponse
+// @Router       /stok/{id}/reconcile [get]
+// @Security BearerAuth
+func (su *stokUsecase) Reconcile(c context.Context, produkID string) (*dtos.StokReconcileResponse, error) {
+	ctx, cancel := context.WithTimeout(c, su.contextTimeout)
+	defer cancel()
+
+	produk, err := su.ProdukRepo.FindOne(ctx, produkID)
+	if err != nil {
+		return nil, errors.New("produk not found")
+	}
+
+	journalStock, count, err := su.StokRepo.SumDelta(ctx, produkID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &dtos.StokReconcileResponse{
+		ProdukID:      produk.ID.Hex(),
+		Name:          produk.Name,
+		Stock:         produk.Stock,
+		JournalStock:  journalStock,
+		Difference:    produk.Stock - journalStock,
+		MovementCount: count,
+		Balanced:      produk.Stock == journalStock,
+	}, nil
+}
+
+// notifyWishlist mengirim notifikasi wishlist di background agar request admin tidak menunggu email
+func (su *stokUsecase) notifyWishlist(before domain.Produk, after domain.Produk) {
+	if su.NotifikasiUsecase == nil {
+		return
+	}
+
+	go func() {
+		err := su.NotifikasiUsecase.NotifyWishlist(context.Background(), &before, &after)
+		if err != nil {
+			log.Println("cannot send wishlist notification: ", err.Error())
+		}
+	}()
+}
@@ -0,0 +1,151 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+	"warunk-bem/cache"
+	"warunk-bem/domain"
+	"warunk-bem/dtos"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// mockProdukRepo meniru IncrementStock yang menolak stok negatif
+type mockProdukRepo struct {
+	domain.ProdukRepository
+
+	produk *domain.Produk
+}
+
+func (m *mockProdukRepo) FindOne(ctx context.Context, id string) (*domain.Produk, error) {
+	if m.produk.ID.Hex() != id {
+		return nil, errors.New("not found")
+	}
+	res := *m.produk
+	return &res, nil
+}
+
+func (m *mockProdukRepo) IncrementStock(ctx context.Context, id string, delta int64) (*domain.Produk, error) {
+	if m.produk.ID.Hex() != id {
+		return nil, errors.New("not found")
+	}
+	if m.produk.Stock+delta < 0 {
+		return nil, errors.New("stok tidak mencukupi")
+	}
+	m.produk.Stock += delta
+	res := *m.produk
+	return &res, nil
+}
+
+type mockStokRepo struct {
+	domain.StokRepository
+
+	movements []domain.StokMovement
+}
+
+func (m *mockStokRepo) InsertOne(ctx context.Context, req *domain.StokMovement) (*domain.StokMovement, error) {
+	m.movements = append(m.movements, *req)
+	return req, nil
+}
+
+func (m *mockStokRepo) SumDelta(ctx context.Context, produkID string) (int64, int64, error) {
+	var sum int64
+	for _, movement := range m.movements {
+		if movement.ProdukID.Hex() == produkID {
+			sum += movement.Delta
+		}
+	}
+	return sum, int64(len(m.movements)), nil
+}
+
+func TestAdjust(t *testing.T) {
+	adminID := primitive.NewObjectID().Hex()
+
+	tests := []struct {
+		name      string
+		movement  string
+		quantity  int64
+		wantErr   bool
+		wantStock int64
+		wantDelta int64
+	}{
+		{name: "restock", movement: domain.StokMovementRestock, quantity: 5, wantStock: 15, wantDelta: 5},
+		{name: "negative restock", movement: domain.StokMovementRestock, quantity: -5, wantErr: true, wantStock: 10},
+		{name: "spoilage", movement: domain.StokMovementSpoilage, quantity: 3, wantStock: 7, wantDelta: -3},
+		{name: "negative spoilage", movement: domain.StokMovementSpoilage, quantity: -3, wantErr: true, wantStock: 10},
+		{name: "spoilage over stock", movement: domain.StokMovementSpoilage, quantity: 11, wantErr: true, wantStock: 10},
+		{name: "correction down", movement: domain.StokMovementCorrection, quantity: -2, wantStock: 8, wantDelta: -2},
+		{name: "zero correction", movement: domain.StokMovementCorrection, quantity: 0, wantErr: true, wantStock: 10},
+		{name: "sale is not an adjustment", movement: domain.StokMovementSale, quantity: 1, wantErr: true, wantStock: 10},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			produk := &domain.Produk{ID: primitive.NewObjectID(), Name: "Kopi", Stock: 10}
+			produkRepo := &mockProdukRepo{produk: produk}
+			stokRepo := &mockStokRepo{}
+			su := NewStokUsecase(stokRepo, produkRepo, nil, cache.NewMemoryCache(), time.Second)
+
+			res, err := su.Adjust(context.Background(), &dtos.StokAdjustRequest{ProdukID: produk.ID.Hex(), Type: tt.movement, Quantity: tt.quantity, Reason: "stock opname"}, adminID)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if produk.Stock != tt.wantStock {
+				t.Fatalf("stock = %d, want %d", produk.Stock, tt.wantStock)
+			}
+			if tt.wantErr {
+				if len(stokRepo.movements) != 0 {
+					t.Fatalf("recorded %d movements on error", len(stokRepo.movements))
+				}
+				return
+			}
+
+			movement := stokRepo.movements[0]
+			if movement.Delta != tt.wantDelta || movement.StockBefore != 10 || movement.StockAfter != tt.wantStock {
+				t.Fatalf("movement = %+v, want delta %d from 10 to %d", movement, tt.wantDelta, tt.wantStock)
+			}
+			if res.Delta != tt.wantDelta {
+				t.Fatalf("response delta = %d, want %d", res.Delta, tt.wantDelta)
+			}
+		})
+	}
+}
+
+func TestReconcile(t *testing.T) {
+	tests := []struct {
+		name         string
+		stock        int64
+		deltas       []int64
+		wantBalanced bool
+		wantDiff     int64
+	}{
+		{name: "balanced", stock: 7, deltas: []int64{10, -3}, wantBalanced: true},
+		{name: "stock changed outside journal", stock: 9, deltas: []int64{10, -3}, wantDiff: 2},
+		{name: "no journal yet", stock: 5, wantDiff: 5},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			produk := &domain.Produk{ID: primitive.NewObjectID(), Name: "Kopi", Stock: tt.stock}
+			stokRepo := &mockStokRepo{}
+			for _, delta := range tt.deltas {
+				stokRepo.movements = append(stokRepo.movements, domain.StokMovement{ProdukID: produk.ID, Delta: delta})
+			}
+			su := NewStokUsecase(stokRepo, &mockProdukRepo{produk: produk}, nil, cache.NewMemoryCache(), time.Second)
+
+			res, err := su.Reconcile(context.Background(), produk.ID.Hex())
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if res.Balanced != tt.wantBalanced || res.Difference != tt.wantDiff {
+				t.Fatalf("balanced = %v, difference = %d, want %v and %d", res.Balanced, res.Difference, tt.wantBalanced, tt.wantDiff)
+			}
+			if res.MovementCount != int64(len(tt.deltas)) {
+				t.Fatalf("movement count = %d, want %d", res.MovementCount, len(tt.deltas))
+			}
+		})
+	}
+}
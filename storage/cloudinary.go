@@ -0,0 +1,49 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"warunk-bem/domain"
+	"warunk-bem/helpers"
+
+	"github.com/cloudinary/cloudinary-go/v2"
+	"github.com/cloudinary/cloudinary-go/v2/api/uploader"
+)
+
+type cloudinaryStorage struct {
+	cld    *cloudinary.Cloudinary
+	folder string
+}
+
+func NewCloudinaryStorage(cloudName string, apiKey string, apiSecret string, folder string) (domain.MediaStorage, error) {
+	cld, err := cloudinary.NewFromParams(cloudName, apiKey, apiSecret)
+	if err != nil {
+		return nil, err
+	}
+
+	return &cloudinaryStorage{cld: cld, folder: folder}, nil
+}
+
+func (s *cloudinaryStorage) Upload(ctx context.Context, file io.Reader, filename string, contentType string) (string, error) {
+	uploadParam, err := s.cld.Upload.Upload(ctx, file, uploader.UploadParams{Folder: s.folder})
+	if err != nil {
+		return "", err
+	}
+
+	if uploadParam.Error.Message != "" {
+		return "", errors.New(uploadParam.Error.Message)
+	}
+
+	return uploadParam.SecureURL, nil
+}
+
+func (s *cloudinaryStorage) Delete(ctx context.Context, url string) error {
+	publicID := helpers.ImagePublicID(url)
+	if publicID == "" {
+		return errors.New("cannot get public id from url")
+	}
+
+	_, err := s.cld.Upload.Destroy(ctx, uploader.DestroyParams{PublicID: publicID})
+	return err
+}
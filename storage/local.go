@@ -0,0 +1,74 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"warunk-bem/domain"
+	"warunk-bem/helpers"
+)
+
+type localStorage struct {
+	dir     string
+	baseURL string
+}
+
+// NewLocalStorage menyimpan file di disk, file disajikan lewat route static dengan prefix baseURL
+func NewLocalStorage(dir string, baseURL string) (domain.MediaStorage, error) {
+	err := os.MkdirAll(dir, 0o755)
+	if err != nil {
+		return nil, err
+	}
+
+	return &localStorage{dir: dir, baseURL: strings.TrimSuffix(baseURL, "/")}, nil
+}
+
+func (s *localStorage) Upload(ctx context.Context, file io.Reader, filename string, contentType string) (string, error) {
+	name := objectName(filename, contentType)
+
+	dst, err := os.Create(filepath.Join(s.dir, name))
+	if err != nil {
+		return "", err
+	}
+	defer dst.Close()
+
+	_, err = io.Copy(dst, file)
+	if err != nil {
+		os.Remove(dst.Name())
+		return "", err
+	}
+
+	return s.baseURL + "/" + name, nil
+}
+
+func (s *localStorage) Delete(ctx context.Context, url string) error {
+	if !strings.HasPrefix(url, s.baseURL+"/") {
+		return errors.New("url is not managed by local storage")
+	}
+
+	name := filepath.Base(strings.TrimPrefix(url, s.baseURL+"/"))
+	err := os.Remove(filepath.Join(s.dir, name))
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	return nil
+}
+
+// objectName membuat nama file acak dengan ekstensi sesuai tipe konten
+func objectName(filename string, contentType string) string {
+	ext := strings.ToLower(filepath.Ext(filename))
+	switch contentType {
+	case "image/jpeg":
+		ext = ".jpg"
+	case "image/png":
+		ext = ".png"
+	case "image/webp":
+		ext = ".webp"
+	}
+
+	return strings.ToLower(helpers.RandomString(20)) + ext
+}
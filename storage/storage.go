@@ -0,0 +1,21 @@
+package storage
+
+import (
+	"fmt"
+	"warunk-bem/config"
+	"warunk-bem/domain"
+)
+
+// New memilih backend media berdasarkan STORAGE_DRIVER
+func New(cfg config.Storage) (domain.MediaStorage, error) {
+	switch cfg.Driver {
+	case config.StorageDriverCloudinary:
+		return NewCloudinaryStorage(config.EnvCloudName(), config.EnvCloudAPIKey(), config.EnvCloudAPISecret(), config.EnvCloudUploadFolder())
+	case config.StorageDriverLocal:
+		return NewLocalStorage(cfg.LocalDir, cfg.LocalURL+cfg.LocalRoute)
+	case config.StorageDriverS3:
+		return NewS3Storage(cfg)
+	default:
+		return nil, fmt.Errorf("unknown storage driver: %s", cfg.Driver)
+	}
+}
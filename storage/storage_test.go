@@ -0,0 +1,135 @@
+package storage
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"warunk-bem/config"
+)
+
+func TestLocalStorage(t *testing.T) {
+	dir := t.TempDir()
+	s, err := NewLocalStorage(dir, "http://localhost:8080/media/")
+	if err != nil {
+		t.Fatalf("NewLocalStorage: %v", err)
+	}
+
+	url, err := s.Upload(context.Background(), strings.NewReader("gambar"), "kopi.JPEG", "image/jpeg")
+	if err != nil {
+		t.Fatalf("Upload: %v", err)
+	}
+	if !strings.HasPrefix(url, "http://localhost:8080/media/") || !strings.HasSuffix(url, ".jpg") {
+		t.Fatalf("url = %q, want local media url with .jpg extension", url)
+	}
+
+	path := filepath.Join(dir, filepath.Base(url))
+	data, err := os.ReadFile(path)
+	if err != nil || string(data) != "gambar" {
+		t.Fatalf("stored file = %q, %v", data, err)
+	}
+
+	tests := []struct {
+		name    string
+		url     string
+		wantErr bool
+	}{
+		{name: "foreign url", url: "https://res.cloudinary.com/demo/image/upload/kopi.jpg", wantErr: true},
+		{name: "path traversal stays inside dir", url: "http://localhost:8080/media/../main.go", wantErr: false},
+		{name: "managed url", url: url, wantErr: false},
+		{name: "already deleted", url: url, wantErr: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := s.Delete(context.Background(), tt.url)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Delete(%q) err = %v, wantErr %v", tt.url, err, tt.wantErr)
+			}
+		})
+	}
+
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Fatalf("file still exists after delete: %v", err)
+	}
+}
+
+func TestNewS3StorageRequiresConfig(t *testing.T) {
+	_, err := NewS3Storage(config.Storage{S3Endpoint: "http://minio:9000", S3Bucket: "warunk"})
+	if err == nil {
+		t.Fatal("expected error for missing credentials")
+	}
+
+	_, err = NewS3Storage(config.Storage{S3Endpoint: "minio:9000", S3Bucket: "warunk", S3AccessKey: "a", S3SecretKey: "b"})
+	if err == nil {
+		t.Fatal("expected error for endpoint without scheme")
+	}
+}
+
+func TestS3Storage(t *testing.T) {
+	var mu sync.Mutex
+	objects := map[string]string{}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasPrefix(r.Header.Get("Authorization"), "AWS4-HMAC-SHA256 Credential=access/") {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+
+		mu.Lock()
+		defer mu.Unlock()
+		switch r.Method {
+		case http.MethodPut:
+			body, _ := io.ReadAll(r.Body)
+			objects[r.URL.Path] = string(body)
+		case http.MethodDelete:
+			delete(objects, r.URL.Path)
+			w.WriteHeader(http.StatusNoContent)
+		}
+	}))
+	defer server.Close()
+
+	s, err := NewS3Storage(config.Storage{
+		S3Endpoint:   server.URL,
+		S3Region:     "us-east-1",
+		S3Bucket:     "warunk",
+		S3AccessKey:  "access",
+		S3SecretKey:  "secret",
+		S3PathStyle:  true,
+		S3UploadPath: "produk",
+	})
+	if err != nil {
+		t.Fatalf("NewS3Storage: %v", err)
+	}
+
+	url, err := s.Upload(context.Background(), strings.NewReader("gambar"), "kopi.png", "image/png")
+	if err != nil {
+		t.Fatalf("Upload: %v", err)
+	}
+	if !strings.HasPrefix(url, server.URL+"/warunk/produk/") || !strings.HasSuffix(url, ".png") {
+		t.Fatalf("url = %q, want path-style object url", url)
+	}
+
+	key := strings.TrimPrefix(url, server.URL)
+	if objects[key] != "gambar" {
+		t.Fatalf("object %q = %q, want uploaded body", key, objects[key])
+	}
+
+	err = s.Delete(context.Background(), "http://localhost:8080/media/kopi.png")
+	if err == nil {
+		t.Fatal("expected error deleting url not managed by s3")
+	}
+
+	err = s.Delete(context.Background(), url)
+	if err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if _, ok := objects[key]; ok {
+		t.Fatalf("object %q still exists after delete", key)
+	}
+}
@@ -1,6 +1,7 @@
 package http
 
 import (
+	"fmt"
 	"net/http"
 	"warunk-bem/domain"
 	"warunk-bem/dtos"
@@ -27,6 +28,11 @@ func NewUserHandler(protected *gin.RouterGroup, protectedAdmin *gin.RouterGroup,
 	protected.POST("", handler.InsertOne)
 	protected.POST("/keranjang", handler.InsertByKeranjang)
 	protected.GET("", handler.FindOneUserID)
+	protected.GET("/:id/receipt", handler.GetReceipt)
+	protected.GET("/:id/receipt/pdf", handler.GetReceiptPDF)
+	protected.POST("/:id/receipt/email", handler.EmailReceipt)
+
+	protectedAdmin.POST("/kasir", handler.InsertKasir)
 }
 
 func isRequestValid(m *dtos.InsertTransaksiRequest) (bool, error) {
@@ -223,3 +229,174 @@ func (tc *TransaksiHandler) InsertByKeranjang(c *gin.Context) {
 		),
 	)
 }
+
+func (tc *TransaksiHandler) GetReceipt(c *gin.Context) {
+	idUser, err := middlewares.IsUser(c)
+	if err != nil {
+		c.JSON(
+			http.StatusUnauthorized,
+			dtos.NewErrorResponse(
+				http.StatusUnauthorized,
+				"Unauthorized",
+				dtos.GetErrorData(err),
+			),
+		)
+		return
+	}
+
+	res, err := tc.TransaksiUsecase.GetReceipt(c, c.Param("id"), idUser)
+	if err != nil {
+		c.JSON(
+			http.StatusNotFound,
+			dtos.NewErrorResponse(
+				http.StatusNotFound,
+				"Cannot Get Receipt",
+				err.Error(),
+			),
+		)
+		return
+	}
+
+	c.JSON(
+		http.StatusOK,
+		dtos.NewResponse(
+			http.StatusOK,
+			"Success Get Receipt",
+			res,
+		),
+	)
+}
+
+func (tc *TransaksiHandler) GetReceiptPDF(c *gin.Context) {
+	idUser, err := middlewares.IsUser(c)
+	if err != nil {
+		c.JSON(
+			http.StatusUnauthorized,
+			dtos.NewErrorResponse(
+				http.StatusUnauthorized,
+				"Unauthorized",
+				dtos.GetErrorData(err),
+			),
+		)
+		return
+	}
+
+	receipt, pdf, err := tc.TransaksiUsecase.GetReceiptPDF(c, c.Param("id"), idUser)
+	if err != nil {
+		c.JSON(
+			http.StatusNotFound,
+			dtos.NewErrorResponse(
+				http.StatusNotFound,
+				"Cannot Get Receipt",
+				err.Error(),
+			),
+		)
+		return
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=struk-%s.pdf", receipt.ReceiptNumber))
+	c.Data(http.StatusOK, "application/pdf", pdf)
+}
+
+func (tc *TransaksiHandler) EmailReceipt(c *gin.Context) {
+	idUser, err := middlewares.IsUser(c)
+	if err != nil {
+		c.JSON(
+			http.StatusUnauthorized,
+			dtos.NewErrorResponse(
+				http.StatusUnauthorized,
+				"Unauthorized",
+				dtos.GetErrorData(err),
+			),
+		)
+		return
+	}
+
+	err = tc.TransaksiUsecase.EmailReceipt(c, c.Param("id"), idUser)
+	if err != nil {
+		c.JSON(
+			http.StatusNotFound,
+			dtos.NewErrorResponse(
+				http.StatusNotFound,
+				"Cannot Send Receipt",
+				err.Error(),
+			),
+		)
+		return
+	}
+
+	c.JSON(
+		http.StatusOK,
+		dtos.NewResponseMessage(
+			http.StatusOK,
+			"Receipt Sent To Email",
+		),
+	)
+}
+
+func (tc *TransaksiHandler) InsertKasir(c *gin.Context) {
+	idAdmin, err := middlewares.IsAdmin(c)
+	if err != nil {
+		c.JSON(
+			http.StatusUnauthorized,
+			dtos.NewErrorResponse(
+				http.StatusUnauthorized,
+				"Unauthorized",
+				dtos.GetErrorData(err),
+			),
+		)
+		return
+	}
+
+	var (
+		req dtos.KasirSaleRequest
+	)
+
+	err = c.ShouldBindJSON(&req)
+	if err != nil {
+		c.JSON(
+			http.StatusUnprocessableEntity,
+			dtos.NewErrorResponse(
+				http.StatusUnprocessableEntity,
+				"Filed Cannot Be Empty",
+				dtos.GetErrorData(err),
+			),
+		)
+		return
+	}
+
+	err = validator.New().Struct(&req)
+	if err != nil {
+		c.JSON(
+			http.StatusBadRequest,
+			dtos.NewErrorResponse(
+				http.StatusBadRequest,
+				"Bad Request",
+				dtos.GetErrorData(err),
+			),
+		)
+		return
+	}
+
+	res, err := tc.TransaksiUsecase.InsertKasir(c, idAdmin, &req)
+	if err != nil {
+		c.JSON(
+			http.StatusBadRequest,
+			dtos.NewErrorResponse(
+				http.StatusBadRequest,
+				"Cannot Insert Penjualan Kasir",
+				dtos.GetErrorData(err),
+			),
+		)
+		return
+	}
+
+	c.JSON(
+		http.StatusCreated,
+		dtos.NewResponse(
+			http.StatusCreated,
+			"Penjualan Berhasil",
+			res,
+		),
+	)
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"sort"
+	"time"
 	"warunk-bem/domain"
 	"warunk-bem/mongo"
 
@@ -91,6 +92,47 @@ func (tr *transaksiRepository) FindAllByUserId(ctx context.Context, id string) (
 	return transaksis, nil
 }
 
+// FindByOrder mengambil semua baris dari satu checkout, transaksi lama tanpa order_id dicari dari _id
+func (tr *transaksiRepository) FindByOrder(ctx context.Context, orderID string) ([]domain.Transaksi, error) {
+	var transaksis []domain.Transaksi
+
+	idHex, err := primitive.ObjectIDFromHex(orderID)
+	if err != nil {
+		return transaksis, err
+	}
+
+	filter := bson.M{"$or": []bson.M{
+		{"order_id": idHex},
+		{"_id": idHex},
+	}}
+
+	cursor, err := tr.Collection.Find(ctx, filter, options.Find().SetSort(bson.M{"created_at": 1}))
+	if err != nil {
+		return transaksis, err
+	}
+
+	err = cursor.All(ctx, &transaksis)
+	if err != nil {
+		return transaksis, err
+	}
+
+	return transaksis, nil
+}
+
+// UpdateStatusByOrder mengubah status semua baris dari satu checkout
+func (tr *transaksiRepository) UpdateStatusByOrder(ctx context.Context, orderID primitive.ObjectID, status string) error {
+	filter := bson.M{"$or": []bson.M{
+		{"order_id": orderID},
+		{"_id": orderID},
+	}}
+
+	_, err := tr.Collection.UpdateMany(ctx, filter, bson.M{"$set": bson.M{
+		"status":     status,
+		"updated_at": time.Now(),
+	}})
+	return err
+}
+
 func (tr *transaksiRepository) GetAllWithPage(ctx context.Context, rp int64, p int64, filter interface{}, setsort interface{}) ([]domain.Transaksi, int64, error) {
 	var (
 		transaksi []domain.Transaksi
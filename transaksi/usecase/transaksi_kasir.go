@@ -0,0 +1,270 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+	"warunk-bem/cache"
+	"warunk-bem/domain"
+	"warunk-bem/dtos"
+	"warunk-bem/helpers"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// kasirLine adalah satu produk yang dijual kasir beserta stok sebelum dipotong
+type kasirLine struct {
+	produk      *domain.Produk
+	qty         int64
+	stockBefore int64
+}
+
+// AddKasirTransaction godoc
+// @Summary      Add Kasir Transaksi
+// @Description  Record a walk-in sale at the warunk table. Payment method is cash, saldo (buyer username + PIN or password, or buyer QR with PIN or password above the checkout threshold) or qris (merchant reference)
+// @Tags         Admin - Transaksi
+// @Accept       json
+// @Produce      json
+// @Param        request body dtos.KasirSaleRequest true "Payload Body [RAW]"
+// @Success      201 {object} dtos.KasirSaleCreatedResponse
+// @Failure      400 {object} dtos.BadRequestResponse
+// @Failure      401 {object} dtos.UnauthorizedResponse
+// @Failure      403 {object} dtos.ForbiddenResponse
+// @Failure      500 {object} dtos.InternalServerErrorResponse
+// @Router       /transaksi/kasir [post]
+// @Security BearerAuth
+func (tu *TransaksiUsecase) InsertKasir(c context.Context, cashierID string, req *dtos.KasirSaleRequest) (*dtos.KasirSaleResponse, error) {
+	ctx, cancel := context.WithTimeout(c, tu.contextTimeout)
+	defer cancel()
+
+	cashierHex, err := primitive.ObjectIDFromHex(cashierID)
+	if err != nil {
+		return nil, err
+	}
+
+	err = tu.ensureWarunkOpen(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	lines, total, err := tu.kasirLines(ctx, req.Items)
+	if err != nil {
+		return nil, err
+	}
+
+	var buyer *domain.User
+	switch req.PaymentMethod {
+	case domain.PaymentMethodCash:
+		if req.CashReceived < total {
+			return nil, fmt.Errorf("uang diterima kurang dari total Rp%s", helpers.FormatRupiah(float64(total)))
+		}
+	case domain.PaymentMethodQRIS:
+		if strings.TrimSpace(req.PaymentRef) == "" {
+			return nil, errors.New("nomor referensi QRIS wajib diisi")
+		}
+	case domain.PaymentMethodSaldo:
+		buyer, err = tu.kasirBuyer(ctx, req, total)
+		if err != nil {
+			return nil, err
+		}
+	default:
+		return nil, errors.New("payment_method harus cash, saldo atau qris")
+	}
+
+	// Stok dipotong lebih dulu secara atomik, jika salah satu gagal stok yang sudah dipotong dikembalikan
+	for i := range lines {
+		produk, err := tu.ProdukRepo.DecrementStock(ctx, lines[i].produk.ID.Hex(), lines[i].qty)
+		if err != nil {
+			tu.restoreKasirStock(ctx, lines[:i])
+			return nil, fmt.Errorf("stok produk '%s' tidak mencukupi", lines[i].produk.Name)
+		}
+
+		lines[i].stockBefore = produk.Stock + lines[i].qty
+		lines[i].produk = produk
+	}
+
+	res := &dtos.KasirSaleResponse{
+		PaymentMethod: req.PaymentMethod,
+		Items:         make([]dtos.ReceiptItemResponse, 0, len(lines)),
+		TotalBayar:    total,
+	}
+
+	var (
+		userID      primitive.ObjectID
+		saldoBefore *float64
+		saldoAfter  *float64
+	)
+	switch req.PaymentMethod {
+	case domain.PaymentMethodCash:
+		res.CashReceived = req.CashReceived
+		res.Change = req.CashReceived - total
+	case domain.PaymentMethodQRIS:
+		res.PaymentRef = strings.TrimSpace(req.PaymentRef)
+	case domain.PaymentMethodSaldo:
+		saldo, err := tu.UserAmountRepo.DecrementAmount(ctx, buyer.ID.Hex(), float64(total))
+		if err != nil {
+			tu.restoreKasirStock(ctx, lines)
+			return nil, errors.New("saldo pembeli tidak mencukupi")
+		}
+
+		// QR baru dipakai setelah saldo terpotong, jika QR sudah dipakai penjualan lain semuanya dikembalikan
+		if strings.TrimSpace(req.BuyerQR) != "" {
+			err = tu.UserQRUsecase.Consume(ctx, req.BuyerQR)
+			if err != nil {
+				if _, refundErr := tu.UserAmountRepo.IncrementAmount(ctx, buyer.ID.Hex(), float64(total)); refundErr != nil {
+					log.Println("cannot refund kasir saldo: ", refundErr.Error())
+				}
+				tu.restoreKasirStock(ctx, lines)
+				return nil, err
+			}
+		}
+
+		before := saldo.Amount + float64(total)
+		userID = buyer.ID
+		saldoBefore = &before
+		saldoAfter = &saldo.Amount
+		res.BuyerName = buyer.Name
+		res.SaldoAfter = saldoAfter
+	}
+
+	var (
+		orderID  = primitive.NewObjectID()
+		lowStock []domain.Produk
+	)
+	for i, line := range lines {
+		now := time.Now()
+		transaksi := &domain.Transaksi{
+			ID:            primitive.NewObjectID(),
+			CreatedAt:     now,
+			UpdatedAt:     now,
+			UserID:        userID,
+			OrderID:       orderID,
+			ProdukID:      line.produk.ID,
+			Total:         line.qty,
+			Harga:         line.produk.Price * line.qty,
+			Status:        "Berhasil",
+			SaldoBefore:   saldoBefore,
+			SaldoAfter:    saldoAfter,
+			Channel:       domain.TransaksiChannelKasir,
+			PaymentMethod: req.PaymentMethod,
+			PaymentRef:    res.PaymentRef,
+			CashierID:     cashierHex,
+		}
+
+		// Stok dan saldo sudah berubah, kegagalan mencatat transaksi hanya bisa dicatat di log
+		_, err = tu.TransaksiRepo.InsertOne(ctx, transaksi)
+		if err != nil {
+			log.Println("cannot insert kasir transaksi: ", err.Error())
+		}
+
+		if i == 0 {
+			res.OrderID = orderID.Hex()
+			res.ReceiptNumber = transaksi.ReceiptNumber()
+		}
+
+		tu.recordStok(ctx, domain.NewStokMovement(line.produk, domain.StokMovementSale, line.stockBefore, "penjualan kasir", cashierHex, transaksi.ID))
+
+		if crossedReorderThreshold(line.stockBefore, line.produk) {
+			lowStock = append(lowStock, *line.produk)
+		}
+
+		res.Items = append(res.Items, dtos.ReceiptItemResponse{
+			ProdukName: line.produk.Name,
+			Qty:        line.qty,
+			Price:      line.produk.Price,
+			Subtotal:   transaksi.Harga,
+		})
+	}
+
+	tu.notifyLowStock(lowStock)
+
+	cache.InvalidateProduk(ctx, tu.Cache)
+	if buyer != nil {
+		cache.InvalidateUser(ctx, tu.Cache, buyer.ID.Hex())
+	}
+
+	return res, nil
+}
+
+// kasirBuyer mencari pembeli untuk pembayaran saldo, QR dari profil pembeli menggantikan PIN
+// hanya sampai batas checkout, di atas batas pembeli tetap memasukkan PIN atau password
+func (tu *TransaksiUsecase) kasirBuyer(ctx context.Context, req *dtos.KasirSaleRequest, total int64) (*domain.User, error) {
+	if strings.TrimSpace(req.BuyerQR) != "" {
+		buyer, err := tu.UserQRUsecase.Verify(ctx, req.BuyerQR)
+		if err != nil {
+			return nil, err
+		}
+
+		err = tu.PinUsecase.ConfirmForCheckout(ctx, buyer.ID.Hex(), req.Pin, req.Password, float64(total))
+		if err != nil {
+			return nil, err
+		}
+
+		return buyer, nil
+	}
+
+	if strings.TrimSpace(req.BuyerUsername) == "" {
+		return nil, errors.New("buyer_qr atau buyer_username wajib diisi untuk pembayaran saldo")
+	}
+
+	buyer, err := tu.UserRepo.FindUsername(ctx, strings.TrimSpace(req.BuyerUsername))
+	if err != nil {
+		return nil, errors.New("username pembeli tidak ditemukan")
+	}
+
+	err = tu.PinUsecase.Confirm(ctx, buyer.ID.Hex(), req.Pin, req.Password)
+	if err != nil {
+		return nil, err
+	}
+
+	return buyer, nil
+}
+
+// kasirLines memvalidasi produk yang dijual kasir dan menghitung total harga
+func (tu *TransaksiUsecase) kasirLines(ctx context.Context, items []dtos.KasirSaleItem) ([]kasirLine, int64, error) {
+	if len(items) == 0 {
+		return nil, 0, errors.New("item penjualan kosong")
+	}
+
+	var total int64
+	lines := make([]kasirLine, 0, len(items))
+	seen := make(map[string]bool, len(items))
+	for _, item := range items {
+		if seen[item.ProdukID] {
+			return nil, 0, fmt.Errorf("produk %s dipilih lebih dari sekali", item.ProdukID)
+		}
+		seen[item.ProdukID] = true
+
+		if item.Qty <= 0 {
+			return nil, 0, errors.New("qty harus lebih dari 0")
+		}
+
+		produk, err := tu.ProdukRepo.FindOne(ctx, item.ProdukID)
+		if err != nil {
+			return nil, 0, fmt.Errorf("produk %s tidak ditemukan", item.ProdukID)
+		}
+
+		if produk.DeletedAt != nil {
+			return nil, 0, fmt.Errorf("produk '%s' sudah tidak tersedia", produk.Name)
+		}
+
+		if produk.Stock < item.Qty {
+			return nil, 0, fmt.Errorf("stok produk '%s' tidak mencukupi", produk.Name)
+		}
+
+		lines = append(lines, kasirLine{produk: produk, qty: item.Qty})
+		total += produk.Price * item.Qty
+	}
+
+	return lines, total, nil
+}
+
+// restoreKasirStock mengembalikan stok yang sudah dipotong ketika penjualan kasir batal
+func (tu *TransaksiUsecase) restoreKasirStock(ctx context.Context, lines []kasirLine) {
+	for _, line := range lines {
+		tu.restoreStock(ctx, line.produk.ID.Hex(), line.qty)
+	}
+}
@@ -0,0 +1,340 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+	"warunk-bem/cache"
+	"warunk-bem/domain"
+	"warunk-bem/dtos"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// mockProdukRepo meniru IncrementStock yang menolak stok negatif
+type mockProdukRepo struct {
+	domain.ProdukRepository
+
+	produks map[string]*domain.Produk
+	// failStock meniru stok produk yang habis dibeli checkout lain setelah dibaca
+	failStock string
+}
+
+func (m *mockProdukRepo) FindOne(ctx context.Context, id string) (*domain.Produk, error) {
+	produk, ok := m.produks[id]
+	if !ok {
+		return nil, errors.New("not found")
+	}
+	copied := *produk
+	return &copied, nil
+}
+
+func (m *mockProdukRepo) IncrementStock(ctx context.Context, id string, delta int64) (*domain.Produk, error) {
+	produk, ok := m.produks[id]
+	if !ok || id == m.failStock || produk.Stock+delta < 0 {
+		return nil, errors.New("stok tidak cukup")
+	}
+	produk.Stock += delta
+	copied := *produk
+	return &copied, nil
+}
+
+func (m *mockProdukRepo) DecrementStock(ctx context.Context, id string, qty int64) (*domain.Produk, error) {
+	if qty <= 0 {
+		return nil, domain.ErrInvalidStockDebit
+	}
+	return m.IncrementStock(ctx, id, -qty)
+}
+
+type mockTransaksiRepo struct {
+	domain.TransaksiRepository
+
+	inserted []*domain.Transaksi
+}
+
+func (m *mockTransaksiRepo) InsertOne(ctx context.Context, req *domain.Transaksi) (*domain.Transaksi, error) {
+	m.inserted = append(m.inserted, req)
+	return req, nil
+}
+
+type mockStokRepo struct {
+	domain.StokRepository
+}
+
+func (m *mockStokRepo) InsertOne(ctx context.Context, req *domain.StokMovement) (*domain.StokMovement, error) {
+	return req, nil
+}
+
+type mockUserRepo struct {
+	domain.UserRepository
+
+	user *domain.User
+}
+
+func (m *mockUserRepo) FindOne(ctx context.Context, id string) (*domain.User, error) {
+	if m.user.ID.Hex() != id {
+		return nil, errors.New("not found")
+	}
+	return m.user, nil
+}
+
+func (m *mockUserRepo) FindUsername(ctx context.Context, username string) (*domain.User, error) {
+	if m.user.Username != username {
+		return nil, errors.New("not found")
+	}
+	return m.user, nil
+}
+
+// mockUserAmountRepo meniru IncrementAmount yang menolak saldo negatif
+type mockUserAmountRepo struct {
+	domain.UserAmountRepository
+
+	saldo map[string]float64
+}
+
+func (m *mockUserAmountRepo) IncrementAmount(ctx context.Context, userID string, delta float64) (*domain.UserAmount, error) {
+	if m.saldo[userID]+delta < 0 {
+		return nil, errors.New("saldo tidak cukup")
+	}
+	m.saldo[userID] += delta
+	return &domain.UserAmount{Amount: m.saldo[userID]}, nil
+}
+
+func (m *mockUserAmountRepo) DecrementAmount(ctx context.Context, userID string, amount float64) (*domain.UserAmount, error) {
+	if amount < 0 {
+		return nil, domain.ErrNegativeDebit
+	}
+	return m.IncrementAmount(ctx, userID, -amount)
+}
+
+type mockPinUsecase struct {
+	domain.PinUsecase
+
+	err error
+}
+
+func (m *mockPinUsecase) Confirm(ctx context.Context, userID string, pin string, password string) error {
+	return m.err
+}
+
+func (m *mockPinUsecase) RequireForCheckout(ctx context.Context, userID string, pin string, total float64) error {
+	return m.err
+}
+
+func (m *mockPinUsecase) ConfirmForCheckout(ctx context.Context, userID string, pin string, password string, total float64) error {
+	return m.err
+}
+
+type mockUserQRUsecase struct {
+	domain.UserQRUsecase
+
+	user       *domain.User
+	consumeErr error
+	consumed   int
+}
+
+func (m *mockUserQRUsecase) Verify(ctx context.Context, payload string) (*domain.User, error) {
+	return m.user, nil
+}
+
+func (m *mockUserQRUsecase) Consume(ctx context.Context, payload string) error {
+	if m.consumeErr != nil {
+		return m.consumeErr
+	}
+	m.consumed++
+	return nil
+}
+
+func TestInsertKasir(t *testing.T) {
+	cashierID := primitive.NewObjectID().Hex()
+	buyer := &domain.User{ID: primitive.NewObjectID(), Username: "pembeli", Name: "Pembeli"}
+	produkA := primitive.NewObjectID().Hex()
+	produkB := primitive.NewObjectID().Hex()
+
+	items := []dtos.KasirSaleItem{{ProdukID: produkA, Qty: 2}, {ProdukID: produkB, Qty: 1}}
+
+	tests := []struct {
+		name          string
+		req           dtos.KasirSaleRequest
+		saldo         float64
+		failStock     string
+		pinErr        error
+		consumeErr    error
+		tutup         bool
+		bukaKemarin   bool
+		wantErr       bool
+		wantStockA    int64
+		wantStockB    int64
+		wantSaldo     float64
+		wantTransaksi int
+		wantBuyer     bool
+	}{
+		{
+			name:          "cash sale is anonymous",
+			req:           dtos.KasirSaleRequest{Items: items, PaymentMethod: domain.PaymentMethodCash, CashReceived: 20000},
+			wantStockA:    8,
+			wantStockB:    4,
+			wantTransaksi: 2,
+		},
+		{
+			name:       "warunk tutup",
+			req:        dtos.KasirSaleRequest{Items: items, PaymentMethod: domain.PaymentMethodCash, CashReceived: 20000},
+			tutup:      true,
+			wantErr:    true,
+			wantStockA: 10,
+			wantStockB: 5,
+		},
+		{
+			name:        "warunk opened yesterday",
+			req:         dtos.KasirSaleRequest{Items: items, PaymentMethod: domain.PaymentMethodSaldo, BuyerUsername: "pembeli", Pin: "123456"},
+			saldo:       50000,
+			bukaKemarin: true,
+			wantErr:     true,
+			wantStockA:  10,
+			wantStockB:  5,
+			wantSaldo:   50000,
+		},
+		{
+			name:       "cash received below total",
+			req:        dtos.KasirSaleRequest{Items: items, PaymentMethod: domain.PaymentMethodCash, CashReceived: 10000},
+			wantErr:    true,
+			wantStockA: 10,
+			wantStockB: 5,
+		},
+		{
+			name:       "zero qty",
+			req:        dtos.KasirSaleRequest{Items: []dtos.KasirSaleItem{{ProdukID: produkA, Qty: 0}}, PaymentMethod: domain.PaymentMethodCash, CashReceived: 20000},
+			wantErr:    true,
+			wantStockA: 10,
+			wantStockB: 5,
+		},
+		{
+			name:       "negative qty does not restock",
+			req:        dtos.KasirSaleRequest{Items: []dtos.KasirSaleItem{{ProdukID: produkA, Qty: 2}, {ProdukID: produkB, Qty: -1}}, PaymentMethod: domain.PaymentMethodCash, CashReceived: 20000},
+			wantErr:    true,
+			wantStockA: 10,
+			wantStockB: 5,
+		},
+		{
+			name:       "stock taken restores earlier lines",
+			req:        dtos.KasirSaleRequest{Items: items, PaymentMethod: domain.PaymentMethodCash, CashReceived: 20000},
+			failStock:  produkB,
+			wantErr:    true,
+			wantStockA: 10,
+			wantStockB: 5,
+		},
+		{
+			name:          "saldo by username",
+			req:           dtos.KasirSaleRequest{Items: items, PaymentMethod: domain.PaymentMethodSaldo, BuyerUsername: "pembeli", Pin: "123456"},
+			saldo:         50000,
+			wantStockA:    8,
+			wantStockB:    4,
+			wantSaldo:     30000,
+			wantTransaksi: 2,
+			wantBuyer:     true,
+		},
+		{
+			name:       "wrong pin changes nothing",
+			req:        dtos.KasirSaleRequest{Items: items, PaymentMethod: domain.PaymentMethodSaldo, BuyerUsername: "pembeli", Pin: "000000"},
+			saldo:      50000,
+			pinErr:     errors.New("PIN salah"),
+			wantErr:    true,
+			wantStockA: 10,
+			wantStockB: 5,
+			wantSaldo:  50000,
+		},
+		{
+			name:       "insufficient saldo restores stock",
+			req:        dtos.KasirSaleRequest{Items: items, PaymentMethod: domain.PaymentMethodSaldo, BuyerUsername: "pembeli", Pin: "123456"},
+			saldo:      10000,
+			wantErr:    true,
+			wantStockA: 10,
+			wantStockB: 5,
+			wantSaldo:  10000,
+		},
+		{
+			name:          "saldo by buyer qr",
+			req:           dtos.KasirSaleRequest{Items: items, PaymentMethod: domain.PaymentMethodSaldo, BuyerQR: "WARUNK-PAY:qr"},
+			saldo:         50000,
+			wantStockA:    8,
+			wantStockB:    4,
+			wantSaldo:     30000,
+			wantTransaksi: 2,
+			wantBuyer:     true,
+		},
+		{
+			name:       "used qr refunds saldo and restores stock",
+			req:        dtos.KasirSaleRequest{Items: items, PaymentMethod: domain.PaymentMethodSaldo, BuyerQR: "WARUNK-PAY:qr"},
+			saldo:      50000,
+			consumeErr: domain.ErrUserQRUsed,
+			wantErr:    true,
+			wantStockA: 10,
+			wantStockB: 5,
+			wantSaldo:  50000,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			produkRepo := &mockProdukRepo{
+				produks: map[string]*domain.Produk{
+					produkA: {ID: mustObjectID(t, produkA), Name: "Teh", Price: 5000, Stock: 10},
+					produkB: {ID: mustObjectID(t, produkB), Name: "Roti", Price: 10000, Stock: 5},
+				},
+				failStock: tt.failStock,
+			}
+			transaksiRepo := &mockTransaksiRepo{}
+			userAmountRepo := &mockUserAmountRepo{saldo: map[string]float64{buyer.ID.Hex(): tt.saldo}}
+			userQRUsecase := &mockUserQRUsecase{user: buyer, consumeErr: tt.consumeErr}
+			warunkRepo := &mockWarunkRepo{buka: &domain.Warunk{ID: primitive.NewObjectID(), CreatedAt: time.Now(), Status: "Buka"}}
+			if tt.bukaKemarin {
+				warunkRepo.buka.CreatedAt = time.Now().AddDate(0, 0, -1)
+			}
+			if tt.tutup {
+				warunkRepo.buka = nil
+			}
+			tu := NewTransaksiUsecase(transaksiRepo, nil, produkRepo, &mockUserRepo{user: buyer}, userAmountRepo, warunkRepo, &mockStokRepo{}, nil, &mockPinUsecase{err: tt.pinErr}, nil, userQRUsecase, nil, cache.NewMemoryCache(), time.Second)
+
+			res, err := tu.InsertKasir(context.Background(), cashierID, &tt.req)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if got := produkRepo.produks[produkA].Stock; got != tt.wantStockA {
+				t.Errorf("stock A = %d, want %d", got, tt.wantStockA)
+			}
+			if got := produkRepo.produks[produkB].Stock; got != tt.wantStockB {
+				t.Errorf("stock B = %d, want %d", got, tt.wantStockB)
+			}
+			if got := userAmountRepo.saldo[buyer.ID.Hex()]; got != tt.wantSaldo {
+				t.Errorf("saldo = %.0f, want %.0f", got, tt.wantSaldo)
+			}
+			if len(transaksiRepo.inserted) != tt.wantTransaksi {
+				t.Fatalf("transaksi = %d, want %d", len(transaksiRepo.inserted), tt.wantTransaksi)
+			}
+			if tt.req.BuyerQR != "" && !tt.wantErr && userQRUsecase.consumed != 1 {
+				t.Errorf("qr consumed = %d, want 1", userQRUsecase.consumed)
+			}
+
+			for _, transaksi := range transaksiRepo.inserted {
+				if got := !transaksi.UserID.IsZero(); got != tt.wantBuyer {
+					t.Errorf("transaksi has buyer = %v, want %v", got, tt.wantBuyer)
+				}
+				if transaksi.OrderID.Hex() != res.OrderID {
+					t.Errorf("transaksi order = %s, want %s", transaksi.OrderID.Hex(), res.OrderID)
+				}
+			}
+		})
+	}
+}
+
+func mustObjectID(t *testing.T, hex string) primitive.ObjectID {
+	t.Helper()
+
+	id, err := primitive.ObjectIDFromHex(hex)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return id
+}
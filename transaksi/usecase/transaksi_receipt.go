@@ -0,0 +1,230 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"log"
+	"warunk-bem/domain"
+	"warunk-bem/dtos"
+	"warunk-bem/helpers"
+	"warunk-bem/utils"
+)
+
+// receiptEmailData nominal sudah diformat karena template email tidak punya fungsi format rupiah
+type receiptEmailData struct {
+	Receipt     *dtos.ReceiptResponse
+	Tanggal     string
+	Items       []receiptEmailItem
+	Subtotal    string
+	Discount    string
+	TotalBayar  string
+	SaldoBefore string
+	SaldoAfter  string
+}
+
+type receiptEmailItem struct {
+	ProdukName string
+	Qty        int64
+	Price      string
+	Subtotal   string
+}
+
+// GetReceipt godoc
+// @Summary      Get Transaksi Receipt
+// @Description  Get the receipt of one checkout by order_id from the transaksi history
+// @Tags         User - Transaksi
+// @Accept       json
+// @Produce      json
+// @Param        id path string true "order_id"
+// @Success      200 {object} dtos.ReceiptOKResponse
+// @Failure      400 {object} dtos.BadRequestResponse
+// @Failure      401 {object} dtos.UnauthorizedResponse
+// @Failure      403 {object} dtos.ForbiddenResponse
+// @Failure      404 {object} dtos.NotFoundResponse
+// @Failure      500 {object} dtos.InternalServerErrorResponse
+// @Router       /transaksi/{id}/receipt [get]
+// @Security BearerAuth
+func (tu *TransaksiUsecase) GetReceipt(c context.Context, orderID string, userID string) (*dtos.ReceiptResponse, error) {
+	ctx, cancel := context.WithTimeout(c, tu.contextTimeout)
+	defer cancel()
+
+	res, _, err := tu.buildReceipt(ctx, orderID, userID)
+	return res, err
+}
+
+// GetReceiptPDF godoc
+// @Summary      Download Transaksi Receipt PDF
+// @Description  Download the receipt of one checkout as a PDF file
+// @Tags         User - Transaksi
+// @Produce      application/pdf
+// @Param        id path string true "order_id"
+// @Success      200 {file} file
+// @Failure      400 {object} dtos.BadRequestResponse
+// @Failure      401 {object} dtos.UnauthorizedResponse
+// @Failure      403 {object} dtos.ForbiddenResponse
+// @Failure      404 {object} dtos.NotFoundResponse
+// @Failure      500 {object} dtos.InternalServerErrorResponse
+// @Router       /transaksi/{id}/receipt/pdf [get]
+// @Security BearerAuth
+func (tu *TransaksiUsecase) GetReceiptPDF(c context.Context, orderID string, userID string) (*dtos.ReceiptResponse, []byte, error) {
+	ctx, cancel := context.WithTimeout(c, tu.contextTimeout)
+	defer cancel()
+
+	res, _, err := tu.buildReceipt(ctx, orderID, userID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	pdf, err := helpers.ReceiptPDF(res)
+	if err != nil {
+		return nil, nil, errors.New("cannot generate receipt pdf")
+	}
+
+	return res, pdf, nil
+}
+
+// EmailReceipt godoc
+// @Summary      Email Transaksi Receipt
+// @Description  Send the receipt of one checkout to the user's email with the PDF attached
+// @Tags         User - Transaksi
+// @Accept       json
+// @Produce      json
+// @Param        id path string true "order_id"
+// @Success      200 {object} dtos.StatusOKResponse
+// @Failure      400 {object} dtos.BadRequestResponse
+// @Failure      401 {object} dtos.UnauthorizedResponse
+// @Failure      403 {object} dtos.ForbiddenResponse
+// @Failure      404 {object} dtos.NotFoundResponse
+// @Failure      500 {object} dtos.InternalServerErrorResponse
+// @Router       /transaksi/{id}/receipt/email [post]
+// @Security BearerAuth
+func (tu *TransaksiUsecase) EmailReceipt(c context.Context, orderID string, userID string) error {
+	ctx, cancel := context.WithTimeout(c, tu.contextTimeout)
+	defer cancel()
+
+	res, user, err := tu.buildReceipt(ctx, orderID, userID)
+	if err != nil {
+		return err
+	}
+
+	go emailReceipt(user, res)
+
+	return nil
+}
+
+// buildReceipt menyusun struk dari semua baris transaksi satu checkout milik userID
+func (tu *TransaksiUsecase) buildReceipt(ctx context.Context, orderID string, userID string) (*dtos.ReceiptResponse, *domain.User, error) {
+	transaksis, err := tu.TransaksiRepo.FindByOrder(ctx, orderID)
+	if err != nil || len(transaksis) == 0 || transaksis[0].UserID.Hex() != userID {
+		return nil, nil, errors.New("transaksi tidak ditemukan")
+	}
+
+	user, err := tu.UserRepo.FindOne(ctx, userID)
+	if err != nil {
+		return nil, nil, errors.New("cannot get user")
+	}
+
+	first := transaksis[0]
+	res := &dtos.ReceiptResponse{
+		OrderID:       first.Order().Hex(),
+		ReceiptNumber: first.ReceiptNumber(),
+		CreatedAt:     first.CreatedAt,
+		Name:          user.Name,
+		Email:         user.Email,
+		Status:        first.Status,
+		PromoCode:     first.PromoCode,
+		SaldoBefore:   first.SaldoBefore,
+		SaldoAfter:    first.SaldoAfter,
+		Items:         make([]dtos.ReceiptItemResponse, 0, len(transaksis)),
+	}
+
+	for _, transaksi := range transaksis {
+		item := dtos.ReceiptItemResponse{
+			ProdukName: "Produk tidak ditemukan",
+			Qty:        transaksi.Total,
+			Subtotal:   transaksi.Harga,
+			Discount:   transaksi.Discount,
+		}
+
+		produk, err := tu.ProdukRepo.FindOne(ctx, transaksi.ProdukID.Hex())
+		if err == nil {
+			item.ProdukName = produk.Name
+
+			// Transaksi lama belum menyimpan harga, gunakan harga produk saat ini
+			if item.Subtotal == 0 {
+				item.Subtotal = produk.Price * transaksi.Total
+			}
+		}
+
+		if item.Qty > 0 {
+			item.Price = item.Subtotal / item.Qty
+		}
+
+		res.Items = append(res.Items, item)
+		res.Subtotal += item.Subtotal
+		res.Discount += item.Discount
+	}
+
+	res.TotalBayar = res.Subtotal - res.Discount
+
+	return res, user, nil
+}
+
+// sendReceipt mengirim struk checkout di background, kegagalan hanya dicatat di log
+func (tu *TransaksiUsecase) sendReceipt(orderID string, user *domain.User) {
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), tu.contextTimeout)
+		defer cancel()
+
+		res, _, err := tu.buildReceipt(ctx, orderID, user.ID.Hex())
+		if err != nil {
+			log.Println("cannot build receipt: ", err.Error())
+			return
+		}
+
+		emailReceipt(user, res)
+	}()
+}
+
+func emailReceipt(user *domain.User, receipt *dtos.ReceiptResponse) {
+	data := receiptEmailData{
+		Receipt:    receipt,
+		Tanggal:    receipt.CreatedAt.Format("02-01-2006 15:04:05"),
+		Items:      make([]receiptEmailItem, 0, len(receipt.Items)),
+		Subtotal:   helpers.FormatRupiah(float64(receipt.Subtotal)),
+		Discount:   helpers.FormatRupiah(float64(receipt.Discount)),
+		TotalBayar: helpers.FormatRupiah(float64(receipt.TotalBayar)),
+	}
+
+	for _, item := range receipt.Items {
+		data.Items = append(data.Items, receiptEmailItem{
+			ProdukName: item.ProdukName,
+			Qty:        item.Qty,
+			Price:      helpers.FormatRupiah(float64(item.Price)),
+			Subtotal:   helpers.FormatRupiah(float64(item.Subtotal)),
+		})
+	}
+
+	if receipt.SaldoBefore != nil && receipt.SaldoAfter != nil {
+		data.SaldoBefore = helpers.FormatRupiah(*receipt.SaldoBefore)
+		data.SaldoAfter = helpers.FormatRupiah(*receipt.SaldoAfter)
+	}
+
+	emailData := utils.EmailData{
+		FirstName: user.Name,
+		Subject:   "Struk Pembelian " + receipt.ReceiptNumber,
+		Template:  "receipt.html",
+		Data:      data,
+	}
+
+	pdf, err := helpers.ReceiptPDF(receipt)
+	if err != nil {
+		log.Println("cannot generate receipt pdf: ", err.Error())
+	} else {
+		emailData.Attachments = map[string][]byte{
+			"struk-" + receipt.ReceiptNumber + ".pdf": pdf,
+		}
+	}
+
+	utils.SendEmail(user, &emailData)
+}
@@ -0,0 +1,94 @@
+package usecase
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"time"
+	"warunk-bem/cache"
+	"warunk-bem/domain"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func (m *mockTransaksiRepo) FindByOrder(ctx context.Context, orderID string) ([]domain.Transaksi, error) {
+	res := []domain.Transaksi{}
+	for _, t := range m.inserted {
+		if t.Order().Hex() == orderID {
+			res = append(res, *t)
+		}
+	}
+	return res, nil
+}
+
+func TestGetReceipt(t *testing.T) {
+	buyer := &domain.User{ID: primitive.NewObjectID(), Name: "Budi", Email: "budi@example.com"}
+	kopi := &domain.Produk{ID: primitive.NewObjectID(), Name: "Kopi", Price: 6000}
+	roti := &domain.Produk{ID: primitive.NewObjectID(), Name: "Roti", Price: 4000}
+	orderID := primitive.NewObjectID()
+	createdAt := time.Date(2024, 3, 1, 9, 30, 0, 0, time.UTC)
+	before, after := 50000.0, 29000.0
+
+	transaksiRepo := &mockTransaksiRepo{inserted: []*domain.Transaksi{
+		{ID: primitive.NewObjectID(), OrderID: orderID, CreatedAt: createdAt, UserID: buyer.ID, ProdukID: kopi.ID, Total: 2, Harga: 12000, Discount: 2000, Status: "Berhasil", PromoCode: "HEMAT", SaldoBefore: &before, SaldoAfter: &after},
+		// baris lama tanpa harga memakai harga produk saat ini
+		{ID: primitive.NewObjectID(), OrderID: orderID, CreatedAt: createdAt, UserID: buyer.ID, ProdukID: roti.ID, Total: 3, Status: "Berhasil"},
+		{ID: primitive.NewObjectID(), OrderID: orderID, CreatedAt: createdAt, UserID: buyer.ID, ProdukID: primitive.NewObjectID(), Total: 1, Harga: 1000, Status: "Berhasil"},
+	}}
+	produkRepo := &mockProdukRepo{produks: map[string]*domain.Produk{kopi.ID.Hex(): kopi, roti.ID.Hex(): roti}}
+	tu := NewTransaksiUsecase(transaksiRepo, nil, produkRepo, &mockUserRepo{user: buyer}, nil, nil, &mockStokRepo{}, nil, nil, nil, nil, nil, cache.NewMemoryCache(), time.Second)
+
+	res, err := tu.GetReceipt(context.Background(), orderID.Hex(), buyer.ID.Hex())
+	if err != nil {
+		t.Fatalf("err = %v", err)
+	}
+
+	if res.OrderID != orderID.Hex() || res.ReceiptNumber != "WB-20240301-"+receiptSuffix(orderID) || res.Name != "Budi" || res.PromoCode != "HEMAT" {
+		t.Errorf("header = %+v", res)
+	}
+	if res.Subtotal != 25000 || res.Discount != 2000 || res.TotalBayar != 23000 {
+		t.Errorf("subtotal = %d discount = %d total = %d, want 25000, 2000, 23000", res.Subtotal, res.Discount, res.TotalBayar)
+	}
+	if res.SaldoBefore == nil || *res.SaldoBefore != before || res.SaldoAfter == nil || *res.SaldoAfter != after {
+		t.Errorf("saldo = %v -> %v, want %v -> %v", res.SaldoBefore, res.SaldoAfter, before, after)
+	}
+
+	wantItems := []struct {
+		name     string
+		price    int64
+		subtotal int64
+	}{
+		{name: "Kopi", price: 6000, subtotal: 12000},
+		{name: "Roti", price: 4000, subtotal: 12000},
+		{name: "Produk tidak ditemukan", price: 1000, subtotal: 1000},
+	}
+	if len(res.Items) != len(wantItems) {
+		t.Fatalf("items = %d, want %d", len(res.Items), len(wantItems))
+	}
+	for i, w := range wantItems {
+		if got := res.Items[i]; got.ProdukName != w.name || got.Price != w.price || got.Subtotal != w.subtotal {
+			t.Errorf("item %d = %+v, want %s %d %d", i, got, w.name, w.price, w.subtotal)
+		}
+	}
+
+	if _, err := tu.GetReceipt(context.Background(), orderID.Hex(), primitive.NewObjectID().Hex()); err == nil {
+		t.Error("receipt of another user should fail")
+	}
+	if _, err := tu.GetReceipt(context.Background(), primitive.NewObjectID().Hex(), buyer.ID.Hex()); err == nil {
+		t.Error("unknown order should fail")
+	}
+
+	_, pdf, err := tu.GetReceiptPDF(context.Background(), orderID.Hex(), buyer.ID.Hex())
+	if err != nil {
+		t.Fatalf("pdf err = %v", err)
+	}
+	if !bytes.HasPrefix(pdf, []byte("%PDF")) {
+		t.Error("receipt pdf does not start with %PDF")
+	}
+}
+
+func receiptSuffix(orderID primitive.ObjectID) string {
+	hex := orderID.Hex()
+	return strings.ToUpper(hex[len(hex)-6:])
+}
@@ -4,35 +4,48 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"log"
 	"time"
+	"warunk-bem/cache"
 	"warunk-bem/domain"
 	"warunk-bem/dtos"
 
-	"github.com/go-redis/redis/v8"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
 type TransaksiUsecase struct {
-	TransaksiRepo  domain.TransaksiRepository
-	KeranjangRepo  domain.KeranjangRepository
-	ProdukRepo     domain.ProdukRepository
-	UserRepo       domain.UserRepository
-	UserAmountRepo domain.UserAmountRepository
-	WarunkRepo     domain.WarunkRepository
-	RedisClient    *redis.Client
-	contextTimeout time.Duration
+	TransaksiRepo     domain.TransaksiRepository
+	KeranjangRepo     domain.KeranjangRepository
+	ProdukRepo        domain.ProdukRepository
+	UserRepo          domain.UserRepository
+	UserAmountRepo    domain.UserAmountRepository
+	WarunkRepo        domain.WarunkRepository
+	StokRepo          domain.StokRepository
+	PromoUsecase      domain.PromoUsecase
+	PinUsecase        domain.PinUsecase
+	PickupUsecase     domain.PickupUsecase
+	UserQRUsecase     domain.UserQRUsecase
+	NotifikasiUsecase domain.NotifikasiUsecase
+	Cache             domain.Cache
+	contextTimeout    time.Duration
 }
 
-func NewTransaksiUsecase(TransaksiRepo domain.TransaksiRepository, KeranjangRepo domain.KeranjangRepository, ProdukRepo domain.ProdukRepository, UserRepo domain.UserRepository, UserAmountRepo domain.UserAmountRepository, WarunkRepo domain.WarunkRepository, RedisClient *redis.Client, contextTimeout time.Duration) domain.TransaksiUsecase {
+func NewTransaksiUsecase(TransaksiRepo domain.TransaksiRepository, KeranjangRepo domain.KeranjangRepository, ProdukRepo domain.ProdukRepository, UserRepo domain.UserRepository, UserAmountRepo domain.UserAmountRepository, WarunkRepo domain.WarunkRepository, StokRepo domain.StokRepository, PromoUsecase domain.PromoUsecase, PinUsecase domain.PinUsecase, PickupUsecase domain.PickupUsecase, UserQRUsecase domain.UserQRUsecase, NotifikasiUsecase domain.NotifikasiUsecase, Cache domain.Cache, contextTimeout time.Duration) domain.TransaksiUsecase {
 	return &TransaksiUsecase{
-		TransaksiRepo:  TransaksiRepo,
-		KeranjangRepo:  KeranjangRepo,
-		ProdukRepo:     ProdukRepo,
-		UserRepo:       UserRepo,
-		UserAmountRepo: UserAmountRepo,
-		WarunkRepo:     WarunkRepo,
-		RedisClient:    RedisClient,
-		contextTimeout: contextTimeout,
+		TransaksiRepo:     TransaksiRepo,
+		KeranjangRepo:     KeranjangRepo,
+		ProdukRepo:        ProdukRepo,
+		UserRepo:          UserRepo,
+		UserAmountRepo:    UserAmountRepo,
+		WarunkRepo:        WarunkRepo,
+		StokRepo:          StokRepo,
+		PromoUsecase:      PromoUsecase,
+		PinUsecase:        PinUsecase,
+		PickupUsecase:     PickupUsecase,
+		UserQRUsecase:     UserQRUsecase,
+		NotifikasiUsecase: NotifikasiUsecase,
+		Cache:             Cache,
+		contextTimeout:    contextTimeout,
 	}
 }
 
@@ -65,15 +78,23 @@ func (tu *TransaksiUsecase) FindAll(c context.Context, id string) (res []*dtos.R
 			return nil, errors.New("cannot get produk")
 		}
 
-		totalharga := produk.Price * transaksi.Total
+		// Transaksi lama belum menyimpan harga, gunakan harga produk saat ini
+		totalharga := transaksi.Harga
+		if totalharga == 0 {
+			totalharga = produk.Price * transaksi.Total
+		}
 
 		riwayatTransaksi := &dtos.RiwayatTransaksiResponse{
-			Name:      produk.Name,
-			CreatedAt: transaksi.CreatedAt.Format("2006-01-02"),
-			Waktu:     transaksi.CreatedAt.Format("15:04:05"),
-			Harga:     totalharga,
-			Total:     transaksi.Total,
-			Image:     produk.Image,
+			ID:            transaksi.ID.Hex(),
+			OrderID:       transaksi.Order().Hex(),
+			ReceiptNumber: transaksi.ReceiptNumber(),
+			Name:          produk.Name,
+			CreatedAt:     transaksi.CreatedAt.Format("2006-01-02"),
+			Waktu:         transaksi.CreatedAt.Format("15:04:05"),
+			Harga:         totalharga,
+			Discount:      transaksi.Discount,
+			Total:         transaksi.Total,
+			Image:         produk.Image,
 		}
 
 		res = append(res, riwayatTransaksi)
@@ -82,6 +103,28 @@ func (tu *TransaksiUsecase) FindAll(c context.Context, id string) (res []*dtos.R
 	return res, nil
 }
 
+// ensureWarunkOpen memastikan warunk sudah dibuka hari ini sebelum penjualan dicatat
+func (tu *TransaksiUsecase) ensureWarunkOpen(ctx context.Context) error {
+	statusWarunk, err := tu.WarunkRepo.FindLatestByStatus(ctx, "Buka")
+	if err != nil {
+		return errors.New("cannot find warunk open")
+	}
+
+	if statusWarunk == nil {
+		return errors.New("warunk tutup")
+	}
+
+	tanggalBuka := statusWarunk.CreatedAt
+	tanggalBukaFormatted := tanggalBuka.Format("2006-01-02")
+	tanggalSekarang := time.Now().Format("2006-01-02")
+
+	if tanggalBukaFormatted != tanggalSekarang {
+		return errors.New("warunk is not open")
+	}
+
+	return nil
+}
+
 // AddTransaction godoc
 // @Summary      Add Transaksi
 // @Description  Add Transaksi
@@ -100,20 +143,17 @@ func (tu *TransaksiUsecase) FindAll(c context.Context, id string) (res []*dtos.R
 func (tu *TransaksiUsecase) InsertOne(ctx context.Context, req *dtos.InsertTransaksiRequest) (*dtos.InsertTransaksiResponse, error) {
 	var res *dtos.InsertTransaksiResponse
 
+	// Jumlah negatif akan menambah stok dan saldo, ditolak sebelum menyentuh repository
+	if req.Total <= 0 {
+		return nil, errors.New("harap masukan jumlah produk yang ingin dibeli")
+	}
+
 	ctx, cancel := context.WithTimeout(ctx, tu.contextTimeout)
 	defer cancel()
 
-	statusWarunk, err := tu.WarunkRepo.FindLatestByStatus(ctx, "Buka")
+	err := tu.ensureWarunkOpen(ctx)
 	if err != nil {
-		return nil, errors.New("cannot find warunk open")
-	}
-
-	tanggalBuka := statusWarunk.CreatedAt
-	tanggalBukaFormatted := tanggalBuka.Format("2006-01-02")
-	tanggalSekarang := time.Now().Format("2006-01-02")
-
-	if tanggalBukaFormatted != tanggalSekarang {
-		return nil, errors.New("warunk is not open")
+		return nil, err
 	}
 
 	produk, err := tu.ProdukRepo.FindOne(ctx, req.ProdukID.Hex())
@@ -126,8 +166,8 @@ func (tu *TransaksiUsecase) InsertOne(ctx context.Context, req *dtos.InsertTrans
 		return res, err
 	}
 
-	if req.Total == 0 {
-		return nil, errors.New("harap masukan jumlah produk yang ingin dibeli")
+	if produk.DeletedAt != nil {
+		return nil, errors.New("produk sudah tidak tersedia")
 	}
 
 	if produk.Stock == 0 {
@@ -139,31 +179,59 @@ func (tu *TransaksiUsecase) InsertOne(ctx context.Context, req *dtos.InsertTrans
 	}
 
 	hargaProduk := produk.Price
-	TotalBelanja := hargaProduk * int64(req.Total)
+	Subtotal := hargaProduk * int64(req.Total)
+	TotalBelanja := Subtotal
+
+	var promo *domain.PromoResult
+	if req.PromoCode != "" {
+		promo, err = tu.PromoUsecase.Apply(ctx, req.PromoCode, req.UserID.Hex(), []domain.PromoItem{
+			{
+				ProdukID: produk.ID,
+				Category: produk.Category,
+				Price:    produk.Price,
+				Qty:      int64(req.Total),
+			},
+		})
+		if err != nil {
+			return nil, err
+		}
 
-	saldo, err := tu.UserAmountRepo.FindOne(ctx, req.UserID.Hex())
+		TotalBelanja -= promo.Discount
+	}
+
+	err = tu.PinUsecase.RequireForCheckout(ctx, req.UserID.Hex(), req.Pin, float64(TotalBelanja))
 	if err != nil {
-		return res, errors.New("cannot get useramount")
+		return nil, err
 	}
 
-	if saldo.Amount < float64(TotalBelanja) {
-		return nil, errors.New("saldo tidak mencukupi")
+	// Stok dipotong secara atomik sebelum saldo, dikembalikan jika pembayaran gagal
+	produk, err = tu.ProdukRepo.DecrementStock(ctx, produk.ID.Hex(), int64(req.Total))
+	if err != nil {
+		return nil, errors.New("stok produk tidak mencukupi")
 	}
+	stockBefore := produk.Stock + int64(req.Total)
 
-	saldoAkhir := saldo.Amount - float64(TotalBelanja)
-	saldo.Amount = saldoAkhir
+	if promo != nil {
+		err = tu.PromoUsecase.Redeem(ctx, promo)
+		if err != nil {
+			tu.restoreStock(ctx, produk.ID.Hex(), int64(req.Total))
+			return nil, err
+		}
+	}
 
-	_, err = tu.UserAmountRepo.UpdateOne(ctx, saldo, saldo.ID.Hex())
+	// Saldo dipotong secara atomik, gagal jika saldo tidak mencukupi
+	saldo, err := tu.UserAmountRepo.DecrementAmount(ctx, req.UserID.Hex(), float64(TotalBelanja))
 	if err != nil {
-		return res, err
+		tu.releasePromo(ctx, promo)
+		tu.restoreStock(ctx, produk.ID.Hex(), int64(req.Total))
+		return nil, errors.New("saldo tidak mencukupi")
 	}
 
-	produk.Stock = produk.Stock - int64(req.Total)
-	produk.UpdatedAt = time.Now()
+	saldoAkhir := saldo.Amount
+	saldoAwal := saldoAkhir + float64(TotalBelanja)
 
-	_, err = tu.ProdukRepo.UpdateOne(ctx, produk, produk.ID.Hex())
-	if err != nil {
-		return res, err
+	if crossedReorderThreshold(stockBefore, produk) {
+		tu.notifyLowStock([]domain.Produk{*produk})
 	}
 
 	req.ID = primitive.NewObjectID()
@@ -171,24 +239,68 @@ func (tu *TransaksiUsecase) InsertOne(ctx context.Context, req *dtos.InsertTrans
 	req.UpdatedAt = time.Now()
 
 	transaksireq := &domain.Transaksi{
-		ID:        req.ID,
-		CreatedAt: req.CreatedAt,
-		UpdatedAt: req.UpdatedAt,
-		UserID:    req.UserID,
-		ProdukID:  req.ProdukID,
-		Total:     int64(req.Total),
-		Status:    "Berhasil",
+		ID:          req.ID,
+		CreatedAt:   req.CreatedAt,
+		UpdatedAt:   req.UpdatedAt,
+		UserID:      req.UserID,
+		OrderID:     req.ID,
+		ProdukID:    req.ProdukID,
+		Total:       int64(req.Total),
+		Harga:       Subtotal,
+		Status:      "Berhasil",
+		SaldoBefore: &saldoAwal,
+		SaldoAfter:  &saldoAkhir,
 	}
 
-	resp, err := tu.TransaksiRepo.InsertOne(ctx, transaksireq)
+	if promo != nil {
+		transaksireq.Discount = promo.Discount
+		transaksireq.PromoCode = promo.Code
+	}
+
+	// Saldo dan stok sudah berubah, kegagalan setelah titik ini hanya dicatat di log
+	// agar pembeli tidak checkout ulang dan terpotong dua kali
+	resp := transaksireq
+	_, err = tu.TransaksiRepo.InsertOne(ctx, transaksireq)
 	if err != nil {
-		return res, err
+		log.Println("cannot insert transaksi: ", err.Error())
+	}
+
+	tu.recordStok(ctx, domain.NewStokMovement(produk, domain.StokMovementSale, stockBefore, "checkout", req.UserID, resp.ID))
+
+	if promo != nil {
+		err = tu.PromoUsecase.RecordUsage(ctx, promo, req.UserID.Hex(), resp.ID)
+		if err != nil {
+			log.Println("cannot record promo usage: ", err.Error())
+		}
 	}
 
 	res = &dtos.InsertTransaksiResponse{
-		Name:       user.Name,
-		ProdukName: produk.Name,
-		Total:      resp.Total,
+		OrderID:       resp.OrderID.Hex(),
+		ReceiptNumber: resp.ReceiptNumber(),
+		Name:          user.Name,
+		ProdukName:    produk.Name,
+		Total:         resp.Total,
+		Subtotal:      Subtotal,
+		Discount:      resp.Discount,
+		TotalBayar:    TotalBelanja,
+		PromoCode:     resp.PromoCode,
+	}
+
+	tu.createPickup(ctx, &domain.Pickup{
+		OrderID:       resp.OrderID,
+		ReceiptNumber: resp.ReceiptNumber(),
+		UserID:        user.ID,
+		UserName:      user.Name,
+		Items: []domain.PickupItem{
+			{ProdukID: produk.ID, ProdukName: produk.Name, Qty: resp.Total},
+		},
+		TotalBayar: TotalBelanja,
+	}, res)
+
+	tu.invalidateCache(ctx, req.UserID.Hex())
+
+	if req.EmailReceipt {
+		tu.sendReceipt(resp.OrderID.Hex(), user)
 	}
 
 	return res, nil
@@ -196,7 +308,7 @@ func (tu *TransaksiUsecase) InsertOne(ctx context.Context, req *dtos.InsertTrans
 
 // AddTransaction godoc
 // @Summary      Add Transaksi By Keranjang
-// @Description  Add Transaksi By Keranjang
+// @Description  Checkout the whole Keranjang, or only the selected items. Items that are not bought stay in Keranjang
 // @Tags         User - Transaksi
 // @Accept       json
 // @Produce      json
@@ -221,26 +333,50 @@ func (tu *TransaksiUsecase) InsertByKeranjang(ctx context.Context, req *dtos.Ins
 		return res, errors.New("cannot get user")
 	}
 
-	// Dapatkan data keranjang berdasarkan ID
-	keranjang, err := tu.KeranjangRepo.FindOneKeranjang(ctx, req.ID.Hex())
+	// Dapatkan data keranjang berdasarkan ID, atau keranjang milik pengguna jika ID kosong
+	var keranjang *domain.Keranjang
+	if req.ID.IsZero() {
+		keranjang, err = tu.KeranjangRepo.FindOne(ctx, req.UserID.Hex())
+	} else {
+		keranjang, err = tu.KeranjangRepo.FindOneKeranjang(ctx, req.ID.Hex())
+	}
 	if err != nil {
 		return res, errors.New("cannot get keranjang")
 	}
 
-	// Periksa saldo pengguna:
-	saldo, err := tu.UserAmountRepo.FindOne(ctx, req.UserID.Hex())
+	if keranjang.UserID != req.UserID {
+		return res, errors.New("keranjang bukan milik pengguna")
+	}
+
+	// Baris yang dibeli, sisanya tetap di keranjang
+	lines, err := selectKeranjangLines(keranjang, req.Items)
 	if err != nil {
-		return res, errors.New("cannot get user amount")
+		return res, err
 	}
 
-	// Iterasi setiap produk dalam keranjang
-	for _, produk := range keranjang.Produk {
+	// Validasi setiap produk dalam keranjang sebelum memotong saldo
+	produks := make([]*domain.Produk, 0, len(lines))
+	items := make([]domain.PromoItem, 0, len(lines))
+	var (
+		subtotal  int64
+		totalItem int64
+	)
+	for _, produk := range lines {
 		// Dapatkan data produk berdasarkan ID produk
 		p, err := tu.ProdukRepo.FindOne(ctx, produk.ID.Hex())
 		if err != nil {
 			return res, err
 		}
 
+		if p.DeletedAt != nil {
+			return nil, fmt.Errorf("produk '%s' sudah tidak tersedia", p.Name)
+		}
+
+		// Jumlah di keranjang yang tidak positif akan menambah stok saat dipotong
+		if produk.Stock <= 0 {
+			return nil, fmt.Errorf("jumlah '%s' di keranjang harus lebih dari 0", p.Name)
+		}
+
 		// Periksa stok produk
 		if p.Stock == 0 {
 			return nil, fmt.Errorf("produk '%s' telah habis terjual", p.Name)
@@ -250,59 +386,316 @@ func (tu *TransaksiUsecase) InsertByKeranjang(ctx context.Context, req *dtos.Ins
 			return nil, fmt.Errorf("stok produk '%s' tidak mencukupi", p.Name)
 		}
 
-		// Hitung total belanja untuk produk ini
-		hargaProduk := p.Price
-		totalBelanja := hargaProduk * int64(produk.Stock)
+		produks = append(produks, p)
+		items = append(items, domain.PromoItem{
+			ProdukID: p.ID,
+			Category: p.Category,
+			Price:    p.Price,
+			Qty:      produk.Stock,
+		})
+		subtotal += p.Price * produk.Stock
+		totalItem += produk.Stock
+	}
 
-		// Periksa saldo pengguna
-		if saldo.Amount < float64(totalBelanja) {
-			return nil, fmt.Errorf("saldo tidak mencukupi untuk membeli produk '%s'", p.Name)
+	var promo *domain.PromoResult
+	totalBayar := subtotal
+	if req.PromoCode != "" {
+		promo, err = tu.PromoUsecase.Apply(ctx, req.PromoCode, req.UserID.Hex(), items)
+		if err != nil {
+			return nil, err
 		}
 
-		// Update saldo pengguna
-		saldo.Amount -= float64(totalBelanja)
-		_, err = tu.UserAmountRepo.UpdateOne(ctx, saldo, saldo.ID.Hex())
+		totalBayar -= promo.Discount
+	}
+
+	err = tu.PinUsecase.RequireForCheckout(ctx, req.UserID.Hex(), req.Pin, float64(totalBayar))
+	if err != nil {
+		return nil, err
+	}
+
+	// Stok semua baris dipotong lebih dulu secara atomik, jika salah satu gagal stok yang sudah dipotong dikembalikan
+	stockBefore := make([]int64, len(produks))
+	for i, p := range produks {
+		qty := items[i].Qty
+
+		updated, err := tu.ProdukRepo.DecrementStock(ctx, p.ID.Hex(), qty)
 		if err != nil {
-			return res, errors.New("cannot update saldo user")
+			tu.restoreKeranjangStock(ctx, produks[:i], items[:i])
+			return nil, fmt.Errorf("stok produk '%s' tidak mencukupi", p.Name)
 		}
 
-		// Update stok produk
-		p.Stock -= produk.Stock
-		p.UpdatedAt = time.Now()
-		_, err = tu.ProdukRepo.UpdateOne(ctx, p, p.ID.Hex())
+		produks[i] = updated
+		stockBefore[i] = updated.Stock + qty
+	}
+
+	if promo != nil {
+		err = tu.PromoUsecase.Redeem(ctx, promo)
 		if err != nil {
-			return res, errors.New("cannot update produk stock")
+			tu.restoreKeranjangStock(ctx, produks, items)
+			return nil, err
+		}
+	}
+
+	// Saldo dipotong secara atomik, gagal jika saldo tidak mencukupi
+	saldo, err := tu.UserAmountRepo.DecrementAmount(ctx, req.UserID.Hex(), float64(totalBayar))
+	if err != nil {
+		tu.releasePromo(ctx, promo)
+		tu.restoreKeranjangStock(ctx, produks, items)
+		return nil, errors.New("saldo tidak mencukupi")
+	}
+
+	saldoAkhir := saldo.Amount
+	saldoAwal := saldoAkhir + float64(totalBayar)
+
+	// Saldo dan stok sudah berubah, kegagalan setelah titik ini hanya dicatat di log
+	// agar pembeli tidak checkout ulang dan terpotong dua kali
+	var (
+		firstTransaksi *domain.Transaksi
+		orderID        = primitive.NewObjectID()
+		lowStock       []domain.Produk
+	)
+	for i, p := range produks {
+		qty := items[i].Qty
+
+		if crossedReorderThreshold(stockBefore[i], p) {
+			lowStock = append(lowStock, *p)
 		}
 
 		// Insert transaksi
 		transaksi := &domain.Transaksi{
-			ID:        primitive.NewObjectID(),
-			CreatedAt: time.Now(),
-			UpdatedAt: time.Now(),
-			UserID:    user.ID,
-			ProdukID:  p.ID,
-			Total:     produk.Stock,
-			Status:    "Berhasil",
+			ID:          primitive.NewObjectID(),
+			CreatedAt:   time.Now(),
+			UpdatedAt:   time.Now(),
+			UserID:      user.ID,
+			OrderID:     orderID,
+			ProdukID:    p.ID,
+			Total:       qty,
+			Harga:       p.Price * qty,
+			Status:      "Berhasil",
+			SaldoBefore: &saldoAwal,
+			SaldoAfter:  &saldoAkhir,
+		}
+
+		if promo != nil {
+			transaksi.Discount = promo.LineDiscount[p.ID]
+			transaksi.PromoCode = promo.Code
 		}
 
 		_, err = tu.TransaksiRepo.InsertOne(ctx, transaksi)
 		if err != nil {
-			return res, errors.New("transaksi gagal")
+			log.Println("cannot insert keranjang transaksi: ", err.Error())
+		}
+
+		tu.recordStok(ctx, domain.NewStokMovement(p, domain.StokMovementSale, stockBefore[i], "checkout keranjang", user.ID, transaksi.ID))
+
+		if i == 0 {
+			firstTransaksi = transaksi
 		}
 	}
 
-	// Delete Keranjang Jika Transaksi Berhasil
-	err = tu.KeranjangRepo.DeleteOne(ctx, keranjang.ID.Hex())
-	if err != nil {
-		return res, errors.New("cannot delete keranjang")
+	tu.notifyLowStock(lowStock)
+
+	if promo != nil {
+		err = tu.PromoUsecase.RecordUsage(ctx, promo, req.UserID.Hex(), firstTransaksi.ID)
+		if err != nil {
+			log.Println("cannot record promo usage: ", err.Error())
+		}
+	}
+
+	// Keranjang baru diubah setelah transaksi berhasil, baris yang tidak dibeli tetap tersimpan
+	remaining := remainingKeranjangLines(keranjang, lines)
+	if len(remaining) == 0 {
+		err = tu.KeranjangRepo.DeleteOne(ctx, keranjang.ID.Hex())
+		if err != nil {
+			log.Println("cannot delete keranjang: ", err.Error())
+		}
+	} else {
+		keranjang.Produk = remaining
+		keranjang.Total = 0
+		for _, v := range remaining {
+			keranjang.Total += int(v.Stock)
+		}
+		keranjang.UpdatedAt = time.Now()
+
+		_, err = tu.KeranjangRepo.UpdateOneKeranjang(ctx, keranjang, keranjang.ID.Hex())
+		if err != nil {
+			log.Println("cannot update keranjang: ", err.Error())
+		}
 	}
 
 	// Buat respons transaksi
 	res = &dtos.InsertTransaksiResponse{
-		Name:       user.Name,
-		ProdukName: keranjang.Produk[0].Name, // Ambil nama produk pertama dalam keranjang
-		Total:      int64(keranjang.Total),
+		OrderID:       orderID.Hex(),
+		ReceiptNumber: firstTransaksi.ReceiptNumber(),
+		Name:          user.Name,
+		ProdukName:    produks[0].Name, // Ambil nama produk pertama yang dibeli
+		Total:         totalItem,
+		Subtotal:      subtotal,
+		Discount:      subtotal - totalBayar,
+		TotalBayar:    totalBayar,
+	}
+
+	if promo != nil {
+		res.PromoCode = promo.Code
+	}
+
+	pickupItems := make([]domain.PickupItem, 0, len(produks))
+	for i, p := range produks {
+		pickupItems = append(pickupItems, domain.PickupItem{ProdukID: p.ID, ProdukName: p.Name, Qty: items[i].Qty})
+	}
+
+	tu.createPickup(ctx, &domain.Pickup{
+		OrderID:       orderID,
+		ReceiptNumber: firstTransaksi.ReceiptNumber(),
+		UserID:        user.ID,
+		UserName:      user.Name,
+		Items:         pickupItems,
+		TotalBayar:    totalBayar,
+	}, res)
+
+	tu.invalidateCache(ctx, req.UserID.Hex())
+
+	if req.EmailReceipt {
+		tu.sendReceipt(orderID.Hex(), user)
 	}
 
 	return res, nil
 }
+
+// selectKeranjangLines mengambil baris keranjang yang dipilih beserta jumlahnya,
+// tanpa pilihan seluruh isi keranjang dibeli
+func selectKeranjangLines(keranjang *domain.Keranjang, selected []dtos.TransaksiKeranjangItem) ([]domain.Produk, error) {
+	if len(keranjang.Produk) == 0 {
+		return nil, errors.New("keranjang kosong")
+	}
+
+	if len(selected) == 0 {
+		return keranjang.Produk, nil
+	}
+
+	lines := make([]domain.Produk, 0, len(selected))
+	seen := make(map[string]bool, len(selected))
+	for _, item := range selected {
+		if seen[item.ProdukID] {
+			return nil, fmt.Errorf("produk %s dipilih lebih dari sekali", item.ProdukID)
+		}
+		seen[item.ProdukID] = true
+
+		if item.Quantity < 0 {
+			return nil, errors.New("quantity tidak boleh negatif")
+		}
+
+		found := false
+		for _, line := range keranjang.Produk {
+			if line.ID.Hex() != item.ProdukID {
+				continue
+			}
+
+			if item.Quantity > line.Stock {
+				return nil, fmt.Errorf("jumlah '%s' melebihi jumlah di keranjang", line.Name)
+			}
+
+			if item.Quantity > 0 {
+				line.Stock = item.Quantity
+			}
+
+			lines = append(lines, line)
+			found = true
+			break
+		}
+
+		if !found {
+			return nil, fmt.Errorf("produk %s tidak ada di keranjang", item.ProdukID)
+		}
+	}
+
+	return lines, nil
+}
+
+// remainingKeranjangLines mengurangi baris keranjang dengan jumlah yang sudah dibeli
+func remainingKeranjangLines(keranjang *domain.Keranjang, bought []domain.Produk) []domain.Produk {
+	boughtQty := make(map[primitive.ObjectID]int64, len(bought))
+	for _, v := range bought {
+		boughtQty[v.ID] += v.Stock
+	}
+
+	remaining := make([]domain.Produk, 0, len(keranjang.Produk))
+	for _, line := range keranjang.Produk {
+		line.Stock -= boughtQty[line.ID]
+		if line.Stock > 0 {
+			remaining = append(remaining, line)
+		}
+	}
+
+	return remaining
+}
+
+// crossedReorderThreshold bernilai true hanya saat stok baru saja turun melewati batas reorder,
+// sehingga notifikasi tidak dikirim ulang di setiap checkout berikutnya
+func crossedReorderThreshold(stockBefore int64, produk *domain.Produk) bool {
+	return produk.ReorderThreshold > 0 && stockBefore >= produk.ReorderThreshold && produk.Stock < produk.ReorderThreshold
+}
+
+// notifyLowStock mengirim notifikasi stok menipis di background agar tidak memperlambat checkout
+func (tu *TransaksiUsecase) notifyLowStock(produks []domain.Produk) {
+	if len(produks) == 0 || tu.NotifikasiUsecase == nil {
+		return
+	}
+
+	go func() {
+		err := tu.NotifikasiUsecase.NotifyLowStock(context.Background(), produks)
+		if err != nil {
+			log.Println("cannot send low stock notification: ", err.Error())
+		}
+	}()
+}
+
+// recordStok mencatat jurnal stok, kegagalan hanya dicatat di log agar checkout yang sudah dibayar tidak gagal
+func (tu *TransaksiUsecase) recordStok(ctx context.Context, movement *domain.StokMovement) {
+	_, err := tu.StokRepo.InsertOne(ctx, movement)
+	if err != nil {
+		log.Println("cannot record stok movement: ", err.Error())
+	}
+}
+
+// restoreStock mengembalikan stok yang sudah dipotong ketika checkout batal
+func (tu *TransaksiUsecase) restoreStock(ctx context.Context, produkID string, qty int64) {
+	_, err := tu.ProdukRepo.IncrementStock(ctx, produkID, qty)
+	if err != nil {
+		log.Println("cannot restore stok produk: ", err.Error())
+	}
+}
+
+// restoreKeranjangStock mengembalikan stok baris keranjang yang sudah dipotong ketika checkout batal
+func (tu *TransaksiUsecase) restoreKeranjangStock(ctx context.Context, produks []*domain.Produk, items []domain.PromoItem) {
+	for i, p := range produks {
+		tu.restoreStock(ctx, p.ID.Hex(), items[i].Qty)
+	}
+}
+
+// releasePromo mengembalikan kuota promo yang sudah diambil ketika checkout gagal
+func (tu *TransaksiUsecase) releasePromo(ctx context.Context, promo *domain.PromoResult) {
+	if promo == nil {
+		return
+	}
+
+	_ = tu.PromoUsecase.Release(ctx, promo)
+}
+
+// createPickup kegagalan hanya dicatat di log karena saldo sudah terpotong, pembeli masih bisa menunjukkan struk
+func (tu *TransaksiUsecase) createPickup(ctx context.Context, pickup *domain.Pickup, res *dtos.InsertTransaksiResponse) {
+	pickup, err := tu.PickupUsecase.Create(ctx, pickup)
+	if err != nil {
+		log.Println("cannot create pickup: ", err.Error())
+		return
+	}
+
+	res.PickupCode = pickup.Code
+	res.PickupQR = domain.PickupQRPrefix + pickup.Code
+}
+
+// invalidateCache saldo pembeli dan stok produk berubah setelah checkout
+func (tu *TransaksiUsecase) invalidateCache(ctx context.Context, userID string) {
+	cache.InvalidateUser(ctx, tu.Cache, userID)
+	cache.InvalidateProduk(ctx, tu.Cache)
+}
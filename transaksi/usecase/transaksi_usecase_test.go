@@ -0,0 +1,237 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+	"time"
+	"warunk-bem/cache"
+	"warunk-bem/domain"
+	"warunk-bem/dtos"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type mockWarunkRepo struct {
+	domain.WarunkRepository
+
+	buka *domain.Warunk
+}
+
+func (m *mockWarunkRepo) FindLatestByStatus(ctx context.Context, status string) (*domain.Warunk, error) {
+	if status == "Buka" {
+		return m.buka, nil
+	}
+	return nil, nil
+}
+
+type mockPickupUsecase struct {
+	domain.PickupUsecase
+}
+
+func (m *mockPickupUsecase) Create(ctx context.Context, pickup *domain.Pickup) (*domain.Pickup, error) {
+	pickup.Code = "ABC123"
+	return pickup, nil
+}
+
+func TestInsertOne(t *testing.T) {
+	buyer := &domain.User{ID: primitive.NewObjectID(), Name: "Pembeli"}
+	bukaHariIni := &domain.Warunk{ID: primitive.NewObjectID(), CreatedAt: time.Now(), Status: "Buka"}
+	bukaKemarin := &domain.Warunk{ID: primitive.NewObjectID(), CreatedAt: time.Now().AddDate(0, 0, -1), Status: "Buka"}
+
+	tests := []struct {
+		name          string
+		total         int
+		buka          *domain.Warunk
+		saldo         float64
+		wantErr       bool
+		wantStock     int64
+		wantSaldo     float64
+		wantTransaksi int
+	}{
+		{name: "success", total: 2, buka: bukaHariIni, saldo: 50000, wantStock: 8, wantSaldo: 40000, wantTransaksi: 1},
+		{name: "zero quantity", total: 0, buka: bukaHariIni, saldo: 50000, wantErr: true, wantStock: 10, wantSaldo: 50000},
+		{name: "negative quantity does not restock or credit", total: -3, buka: bukaHariIni, saldo: 50000, wantErr: true, wantStock: 10, wantSaldo: 50000},
+		{name: "warunk never opened", total: 2, saldo: 50000, wantErr: true, wantStock: 10, wantSaldo: 50000},
+		{name: "warunk opened yesterday", total: 2, buka: bukaKemarin, saldo: 50000, wantErr: true, wantStock: 10, wantSaldo: 50000},
+		{name: "insufficient saldo restores stock", total: 2, buka: bukaHariIni, saldo: 5000, wantErr: true, wantStock: 10, wantSaldo: 5000},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			produk := &domain.Produk{ID: primitive.NewObjectID(), Name: "Teh", Price: 5000, Stock: 10}
+			produkRepo := &mockProdukRepo{produks: map[string]*domain.Produk{produk.ID.Hex(): produk}}
+			transaksiRepo := &mockTransaksiRepo{}
+			userAmountRepo := &mockUserAmountRepo{saldo: map[string]float64{buyer.ID.Hex(): tt.saldo}}
+			tu := NewTransaksiUsecase(transaksiRepo, nil, produkRepo, &mockUserRepo{user: buyer}, userAmountRepo, &mockWarunkRepo{buka: tt.buka}, &mockStokRepo{}, nil, &mockPinUsecase{}, &mockPickupUsecase{}, nil, nil, cache.NewMemoryCache(), time.Second)
+
+			_, err := tu.InsertOne(context.Background(), &dtos.InsertTransaksiRequest{UserID: buyer.ID, ProdukID: produk.ID, Total: tt.total})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if produk.Stock != tt.wantStock {
+				t.Errorf("stock = %d, want %d", produk.Stock, tt.wantStock)
+			}
+			if got := userAmountRepo.saldo[buyer.ID.Hex()]; got != tt.wantSaldo {
+				t.Errorf("saldo = %.0f, want %.0f", got, tt.wantSaldo)
+			}
+			if len(transaksiRepo.inserted) != tt.wantTransaksi {
+				t.Errorf("transaksi = %d, want %d", len(transaksiRepo.inserted), tt.wantTransaksi)
+			}
+		})
+	}
+}
+
+type mockKeranjangRepo struct {
+	domain.KeranjangRepository
+
+	keranjang *domain.Keranjang
+	deleted   bool
+}
+
+func (m *mockKeranjangRepo) FindOne(ctx context.Context, id string) (*domain.Keranjang, error) {
+	return m.keranjang, nil
+}
+
+func (m *mockKeranjangRepo) UpdateOneKeranjang(ctx context.Context, keranjang *domain.Keranjang, id string) (*domain.Keranjang, error) {
+	m.keranjang = keranjang
+	return keranjang, nil
+}
+
+func (m *mockKeranjangRepo) DeleteOne(ctx context.Context, id string) error {
+	m.deleted = true
+	return nil
+}
+
+func TestInsertByKeranjang(t *testing.T) {
+	buyer := &domain.User{ID: primitive.NewObjectID(), Name: "Pembeli"}
+
+	tests := []struct {
+		name        string
+		cartQty     int64
+		items       []dtos.TransaksiKeranjangItem
+		wantErr     bool
+		wantStock   int64
+		wantSaldo   float64
+		wantDeleted bool
+	}{
+		{name: "whole keranjang", cartQty: 2, wantStock: 8, wantSaldo: 40000, wantDeleted: true},
+		{name: "partial quantity keeps the rest", cartQty: 3, items: []dtos.TransaksiKeranjangItem{{Quantity: 1}}, wantStock: 9, wantSaldo: 45000},
+		{name: "negative selected quantity", cartQty: 2, items: []dtos.TransaksiKeranjangItem{{Quantity: -1}}, wantErr: true, wantStock: 10, wantSaldo: 50000},
+		{name: "negative keranjang quantity does not restock or credit", cartQty: -2, wantErr: true, wantStock: 10, wantSaldo: 50000},
+		{name: "zero keranjang quantity", cartQty: 0, wantErr: true, wantStock: 10, wantSaldo: 50000},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			produk := &domain.Produk{ID: primitive.NewObjectID(), Name: "Teh", Price: 5000, Stock: 10}
+			line := *produk
+			line.Stock = tt.cartQty
+			for i := range tt.items {
+				tt.items[i].ProdukID = produk.ID.Hex()
+			}
+
+			produkRepo := &mockProdukRepo{produks: map[string]*domain.Produk{produk.ID.Hex(): produk}}
+			keranjangRepo := &mockKeranjangRepo{keranjang: &domain.Keranjang{ID: primitive.NewObjectID(), UserID: buyer.ID, Produk: []domain.Produk{line}}}
+			userAmountRepo := &mockUserAmountRepo{saldo: map[string]float64{buyer.ID.Hex(): 50000}}
+			tu := NewTransaksiUsecase(&mockTransaksiRepo{}, keranjangRepo, produkRepo, &mockUserRepo{user: buyer}, userAmountRepo, nil, &mockStokRepo{}, nil, &mockPinUsecase{}, &mockPickupUsecase{}, nil, nil, cache.NewMemoryCache(), time.Second)
+
+			_, err := tu.InsertByKeranjang(context.Background(), &dtos.InsertTransaksiKeranjangRequest{UserID: buyer.ID, Items: tt.items})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if produk.Stock != tt.wantStock {
+				t.Errorf("stock = %d, want %d", produk.Stock, tt.wantStock)
+			}
+			if got := userAmountRepo.saldo[buyer.ID.Hex()]; got != tt.wantSaldo {
+				t.Errorf("saldo = %.0f, want %.0f", got, tt.wantSaldo)
+			}
+			if keranjangRepo.deleted != tt.wantDeleted {
+				t.Errorf("keranjang deleted = %v, want %v", keranjangRepo.deleted, tt.wantDeleted)
+			}
+		})
+	}
+}
+
+func TestCrossedReorderThreshold(t *testing.T) {
+	tests := []struct {
+		name        string
+		stockBefore int64
+		stockAfter  int64
+		threshold   int64
+		want        bool
+	}{
+		{name: "crosses threshold", stockBefore: 6, stockAfter: 4, threshold: 5, want: true},
+		{name: "lands on threshold", stockBefore: 7, stockAfter: 5, threshold: 5, want: false},
+		{name: "starts at threshold", stockBefore: 5, stockAfter: 4, threshold: 5, want: true},
+		{name: "already below threshold", stockBefore: 4, stockAfter: 3, threshold: 5, want: false},
+		{name: "threshold disabled", stockBefore: 1, stockAfter: 0, threshold: 0, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			produk := &domain.Produk{Stock: tt.stockAfter, ReorderThreshold: tt.threshold}
+			if got := crossedReorderThreshold(tt.stockBefore, produk); got != tt.want {
+				t.Fatalf("crossedReorderThreshold(%d -> %d, %d) = %v, want %v", tt.stockBefore, tt.stockAfter, tt.threshold, got, tt.want)
+			}
+		})
+	}
+}
+
+// mockNotifikasiUsecase meneruskan notifikasi stok menipis yang dikirim di background ke channel
+type mockNotifikasiUsecase struct {
+	domain.NotifikasiUsecase
+
+	lowStock chan []domain.Produk
+}
+
+func (m *mockNotifikasiUsecase) NotifyLowStock(ctx context.Context, produks []domain.Produk) error {
+	m.lowStock <- produks
+	return nil
+}
+
+func TestInsertOneNotifiesLowStock(t *testing.T) {
+	buyer := &domain.User{ID: primitive.NewObjectID(), Name: "Pembeli"}
+	buka := &domain.Warunk{ID: primitive.NewObjectID(), CreatedAt: time.Now(), Status: "Buka"}
+
+	tests := []struct {
+		name       string
+		stock      int64
+		threshold  int64
+		wantNotify bool
+	}{
+		{name: "crosses threshold", stock: 6, threshold: 5, wantNotify: true},
+		{name: "stays above threshold", stock: 10, threshold: 5, wantNotify: false},
+		{name: "already low", stock: 4, threshold: 5, wantNotify: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			produk := &domain.Produk{ID: primitive.NewObjectID(), Name: "Teh", Price: 5000, Stock: tt.stock, ReorderThreshold: tt.threshold}
+			produkRepo := &mockProdukRepo{produks: map[string]*domain.Produk{produk.ID.Hex(): produk}}
+			userAmountRepo := &mockUserAmountRepo{saldo: map[string]float64{buyer.ID.Hex(): 50000}}
+			notifikasi := &mockNotifikasiUsecase{lowStock: make(chan []domain.Produk, 1)}
+			tu := NewTransaksiUsecase(&mockTransaksiRepo{}, nil, produkRepo, &mockUserRepo{user: buyer}, userAmountRepo, &mockWarunkRepo{buka: buka}, &mockStokRepo{}, nil, &mockPinUsecase{}, &mockPickupUsecase{}, nil, notifikasi, cache.NewMemoryCache(), time.Second)
+
+			_, err := tu.InsertOne(context.Background(), &dtos.InsertTransaksiRequest{UserID: buyer.ID, ProdukID: produk.ID, Total: 2})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			select {
+			case produks := <-notifikasi.lowStock:
+				if !tt.wantNotify {
+					t.Fatalf("unexpected low stock notification for %v", produks)
+				}
+				if len(produks) != 1 || produks[0].ID != produk.ID || produks[0].Stock != tt.stock-2 {
+					t.Fatalf("notified %+v, want produk with stock %d", produks, tt.stock-2)
+				}
+			case <-time.After(100 * time.Millisecond):
+				if tt.wantNotify {
+					t.Fatal("low stock notification not sent")
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,143 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+	"warunk-bem/domain"
+	"warunk-bem/mongo"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	mongodriver "go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type transferRepository struct {
+	DB              mongo.Database
+	Collection      mongo.Collection
+	DailyCollection mongo.Collection
+}
+
+const (
+	timeFormat          = "2006-01-02T15:04:05.999Z07:00" // reduce precision from RFC3339Nano as date format
+	collectionName      = "transfer"
+	dailyCollectionName = "transfer_daily"
+)
+
+func NewTransferRepository(DB mongo.Database) domain.TransferRepository {
+	return &transferRepository{DB, DB.Collection(collectionName), DB.Collection(dailyCollectionName)}
+}
+
+func (r *transferRepository) EnsureIndexes(ctx context.Context) error {
+	_, err := r.Collection.CreateIndexes(ctx, []mongodriver.IndexModel{
+		{
+			Keys: bson.D{{Key: "sender_id", Value: 1}, {Key: "created_at", Value: -1}},
+		},
+		{
+			Keys: bson.D{{Key: "receiver_id", Value: 1}, {Key: "created_at", Value: -1}},
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	// Unique index membuat upsert ReserveDaily gagal, bukan membuat dokumen kedua, saat limit sudah penuh
+	_, err = r.DailyCollection.CreateIndexes(ctx, []mongodriver.IndexModel{
+		{
+			Keys:    bson.D{{Key: "user_id", Value: 1}, {Key: "day", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+	})
+	return err
+}
+
+func (r *transferRepository) InsertOne(ctx context.Context, req *domain.Transfer) (*domain.Transfer, error) {
+	_, err := r.Collection.InsertOne(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	return req, nil
+}
+
+func (r *transferRepository) ReserveDaily(ctx context.Context, senderID primitive.ObjectID, day string, amount float64, maxAmount float64, maxCount int64) error {
+	if amount > maxAmount || maxCount < 1 {
+		return domain.ErrTransferLimitReached
+	}
+
+	filter := bson.M{
+		"user_id": senderID,
+		"day":     day,
+		"amount":  bson.M{"$lte": maxAmount - amount},
+		"count":   bson.M{"$lt": maxCount},
+	}
+	update := bson.M{
+		"$inc": bson.M{"amount": amount, "count": 1},
+		"$set": bson.M{"updated_at": time.Now()},
+	}
+
+	// Dokumen hari ini yang sudah melewati limit tidak cocok dengan filter, upsert lalu ditolak unique index
+	_, err := r.DailyCollection.UpdateOne(ctx, filter, update, options.Update().SetUpsert(true))
+	if mongodriver.IsDuplicateKeyError(err) {
+		return domain.ErrTransferLimitReached
+	}
+
+	return err
+}
+
+func (r *transferRepository) ReleaseDaily(ctx context.Context, senderID primitive.ObjectID, day string, amount float64) error {
+	_, err := r.DailyCollection.UpdateOne(ctx,
+		bson.M{"user_id": senderID, "day": day},
+		bson.M{
+			"$inc": bson.M{"amount": -amount, "count": -1},
+			"$set": bson.M{"updated_at": time.Now()},
+		},
+	)
+	return err
+}
+
+func (r *transferRepository) GetDaily(ctx context.Context, senderID primitive.ObjectID, day string) (float64, int64, error) {
+	var daily domain.TransferDaily
+
+	err := r.DailyCollection.FindOne(ctx, bson.M{"user_id": senderID, "day": day}).Decode(&daily)
+	if errors.Is(err, mongodriver.ErrNoDocuments) {
+		return 0, 0, nil
+	}
+	if err != nil {
+		return 0, 0, err
+	}
+
+	return daily.Amount, daily.Count, nil
+}
+
+func (r *transferRepository) GetAllWithPage(ctx context.Context, rp int64, p int64, filter interface{}, setsort interface{}) ([]domain.Transfer, int64, error) {
+	var (
+		transfer []domain.Transfer
+		err      error
+	)
+
+	findOptions := options.Find()
+	findOptions.SetLimit(rp)
+	findOptions.SetSkip((p - 1) * rp)
+	if setsort != nil {
+		findOptions.SetSort(setsort)
+	}
+
+	cursor, err := r.Collection.Find(ctx, filter, findOptions)
+	if err != nil {
+		return transfer, 0, err
+	}
+
+	err = cursor.All(ctx, &transfer)
+	if err != nil {
+		return transfer, 0, err
+	}
+
+	total, err := r.Collection.CountDocuments(ctx, filter)
+	if err != nil {
+		return transfer, 0, err
+	}
+
+	return transfer, total, nil
+}
@@ -0,0 +1,285 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+	"warunk-bem/cache"
+	"warunk-bem/domain"
+	"warunk-bem/dtos"
+	"warunk-bem/helpers"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type transferUsecase struct {
+	TransferRepo      domain.TransferRepository
+	UserRepo          domain.UserRepository
+	UserAmountRepo    domain.UserAmountRepository
+	PinUsecase        domain.PinUsecase
+	NotifikasiUsecase domain.NotifikasiUsecase
+	Cache             domain.Cache
+	minAmount         float64
+	dailyLimit        float64
+	dailyCount        int64
+	contextTimeout    time.Duration
+}
+
+func NewTransferUsecase(TransferRepo domain.TransferRepository, UserRepo domain.UserRepository, UserAmountRepo domain.UserAmountRepository, PinUsecase domain.PinUsecase, NotifikasiUsecase domain.NotifikasiUsecase, Cache domain.Cache, minAmount float64, dailyLimit float64, dailyCount int64, contextTimeout time.Duration) domain.TransferUsecase {
+	return &transferUsecase{
+		TransferRepo:      TransferRepo,
+		UserRepo:          UserRepo,
+		UserAmountRepo:    UserAmountRepo,
+		PinUsecase:        PinUsecase,
+		NotifikasiUsecase: NotifikasiUsecase,
+		Cache:             Cache,
+		minAmount:         minAmount,
+		dailyLimit:        dailyLimit,
+		dailyCount:        dailyCount,
+		contextTimeout:    contextTimeout,
+	}
+}
+
+// toTransferResponse menampilkan transfer dari sisi userID, keluar untuk pengirim dan masuk untuk penerima
+func toTransferResponse(transfer *domain.Transfer, userID primitive.ObjectID) *dtos.TransferResponse {
+	res := &dtos.TransferResponse{
+		ID:           transfer.ID.Hex(),
+		CreatedAt:    transfer.CreatedAt,
+		Direction:    domain.TransferDirectionIn,
+		Counterparty: transfer.SenderUsername,
+		Amount:       transfer.Amount,
+		Note:         transfer.Note,
+	}
+
+	if transfer.SenderID == userID {
+		res.Direction = domain.TransferDirectionOut
+		res.Counterparty = transfer.ReceiverUsername
+	}
+
+	return res
+}
+
+// dayKey adalah kunci pemakaian limit harian, hari dihitung dengan zona waktu server
+func dayKey(t time.Time) string {
+	return t.Format("2006-01-02")
+}
+
+// TransferSaldo godoc
+// @Summary      Transfer Saldo
+// @Description  Send saldo to another user by username, confirmed with the transaction PIN (or the password if no PIN is set) and limited per day
+// @Tags         User - Transfer
+// @Accept       json
+// @Produce      json
+// @Param        request body dtos.TransferRequest true "Payload Body [RAW]"
+// @Success      201 {object} dtos.TransferOKResponse
+// @Failure      400 {object} dtos.BadRequestResponse
+// @Failure      401 {object} dtos.UnauthorizedResponse
+// @Failure      403 {object} dtos.ForbiddenResponse
+// @Failure      500 {object} dtos.InternalServerErrorResponse
+// @Router       /transfer [post]
+// @Security BearerAuth
+func (tu *transferUsecase) Transfer(c context.Context, userID string, req *dtos.TransferRequest) (*dtos.TransferResponse, error) {
+	ctx, cancel := context.WithTimeout(c, tu.contextTimeout)
+	defer cancel()
+
+	if req.Amount < tu.minAmount {
+		return nil, fmt.Errorf("minimal transfer Rp%s", helpers.FormatRupiah(tu.minAmount))
+	}
+
+	err := tu.PinUsecase.Confirm(ctx, userID, req.Pin, req.Password)
+	if err != nil {
+		return nil, err
+	}
+
+	sender, err := tu.UserRepo.FindOne(ctx, userID)
+	if err != nil {
+		return nil, errors.New("user tidak ditemukan")
+	}
+
+	receiver, err := tu.UserRepo.FindUsername(ctx, strings.TrimSpace(req.Username))
+	if err != nil {
+		return nil, errors.New("username penerima tidak ditemukan")
+	}
+
+	if receiver.ID == sender.ID {
+		return nil, errors.New("tidak bisa transfer ke akun sendiri")
+	}
+
+	// Limit harian dipesan lebih dulu secara atomik, lalu saldo dipindah dengan $inc bersyarat.
+	// Setiap langkah yang gagal mengembalikan langkah sebelumnya sehingga tidak butuh transaksi MongoDB (replica set)
+	day := dayKey(time.Now())
+	err = tu.TransferRepo.ReserveDaily(ctx, sender.ID, day, req.Amount, tu.dailyLimit, tu.dailyCount)
+	if errors.Is(err, domain.ErrTransferLimitReached) {
+		return nil, tu.limitError(ctx, sender.ID, day)
+	}
+	if err != nil {
+		return nil, errors.New("tidak dapat memeriksa limit transfer")
+	}
+
+	_, err = tu.UserAmountRepo.DecrementAmount(ctx, sender.ID.Hex(), req.Amount)
+	if err != nil {
+		tu.releaseDaily(ctx, sender.ID, day, req.Amount)
+		return nil, errors.New("saldo tidak cukup")
+	}
+
+	_, err = tu.UserAmountRepo.IncrementAmount(ctx, receiver.ID.Hex(), req.Amount)
+	if err != nil {
+		if _, refundErr := tu.UserAmountRepo.IncrementAmount(ctx, sender.ID.Hex(), req.Amount); refundErr != nil {
+			log.Println("cannot refund transfer sender: ", refundErr.Error())
+		}
+		tu.releaseDaily(ctx, sender.ID, day, req.Amount)
+		return nil, errors.New("saldo penerima tidak ditemukan")
+	}
+
+	transfer := &domain.Transfer{
+		ID:               primitive.NewObjectID(),
+		CreatedAt:        time.Now(),
+		SenderID:         sender.ID,
+		SenderUsername:   sender.Username,
+		ReceiverID:       receiver.ID,
+		ReceiverUsername: receiver.Username,
+		Amount:           req.Amount,
+		Note:             strings.TrimSpace(req.Note),
+	}
+
+	// Saldo sudah berpindah, kegagalan mencatat transfer hanya dicatat di log
+	_, err = tu.TransferRepo.InsertOne(ctx, transfer)
+	if err != nil {
+		log.Println("cannot record transfer: ", err.Error())
+	}
+
+	cache.InvalidateUser(ctx, tu.Cache, sender.ID.Hex())
+	cache.InvalidateUser(ctx, tu.Cache, receiver.ID.Hex())
+
+	message := fmt.Sprintf("Kamu menerima Rp%s dari @%s.", helpers.FormatRupiah(transfer.Amount), sender.Username)
+	if transfer.Note != "" {
+		message += " Catatan: " + transfer.Note
+	}
+
+	helpers.NotifyAsync(tu.NotifikasiUsecase, "transfer", &domain.Notifikasi{
+		UserID:      receiver.ID,
+		Type:        domain.NotifikasiTypeTransfer,
+		Title:       "Saldo masuk dari @" + sender.Username,
+		Message:     message,
+		ReferenceID: transfer.ID,
+	})
+
+	return toTransferResponse(transfer, sender.ID), nil
+}
+
+// GetTransfer godoc
+// @Summary      Get Transfer History
+// @Description  Get saldo transfers sent and received by the logged in user, newest first
+// @Tags         User - Transfer
+// @Accept       json
+// @Produce      json
+// @Param        rp query int false "rp"
+// @Param        p query int false "p"
+// @Success      200 {object} dtos.TransfersOKResponse
+// @Failure      400 {object} dtos.BadRequestResponse
+// @Failure      401 {object} dtos.UnauthorizedResponse
+// @Failure      403 {object} dtos.ForbiddenResponse
+// @Failure      500 {object} dtos.InternalServerErrorResponse
+// @Router       /transfer [get]
+// @Security BearerAuth
+func (tu *transferUsecase) GetAllWithPage(c context.Context, userID string, rp int64, p int64) ([]*dtos.TransferResponse, int64, error) {
+	res := []*dtos.TransferResponse{}
+
+	ctx, cancel := context.WithTimeout(c, tu.contextTimeout)
+	defer cancel()
+
+	userHex, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return res, 0, err
+	}
+
+	filter := bson.M{"$or": []bson.M{
+		{"sender_id": userHex},
+		{"receiver_id": userHex},
+	}}
+
+	transfers, count, err := tu.TransferRepo.GetAllWithPage(ctx, rp, p, filter, bson.M{"created_at": -1})
+	if err != nil {
+		return res, 0, err
+	}
+
+	for i := range transfers {
+		res = append(res, toTransferResponse(&transfers[i], userHex))
+	}
+
+	return res, count, nil
+}
+
+// GetTransferLimit godoc
+// @Summary      Get Transfer Limit
+// @Description  Get today's transfer limit and how much of it has been used
+// @Tags         User - Transfer
+// @Accept       json
+// @Produce      json
+// @Success      200 {object} dtos.TransferLimitOKResponse
+// @Failure      400 {object} dtos.BadRequestResponse
+// @Failure      401 {object} dtos.UnauthorizedResponse
+// @Failure      403 {object} dtos.ForbiddenResponse
+// @Failure      500 {object} dtos.InternalServerErrorResponse
+// @Router       /transfer/limit [get]
+// @Security BearerAuth
+func (tu *transferUsecase) GetLimit(c context.Context, userID string) (*dtos.TransferLimitResponse, error) {
+	ctx, cancel := context.WithTimeout(c, tu.contextTimeout)
+	defer cancel()
+
+	userHex, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	usedAmount, usedCount, err := tu.TransferRepo.GetDaily(ctx, userHex, dayKey(time.Now()))
+	if err != nil {
+		return nil, errors.New("tidak dapat memeriksa limit transfer")
+	}
+
+	res := &dtos.TransferLimitResponse{
+		DailyLimit: tu.dailyLimit,
+		DailyCount: tu.dailyCount,
+		UsedAmount: usedAmount,
+		UsedCount:  usedCount,
+	}
+
+	if usedAmount < tu.dailyLimit {
+		res.RemainingLimit = tu.dailyLimit - usedAmount
+	}
+	if usedCount < tu.dailyCount {
+		res.RemainingCount = tu.dailyCount - usedCount
+	}
+
+	return res, nil
+}
+
+// limitError menjelaskan limit mana yang tercapai setelah ReserveDaily menolak transfer
+func (tu *transferUsecase) limitError(ctx context.Context, senderID primitive.ObjectID, day string) error {
+	usedAmount, usedCount, err := tu.TransferRepo.GetDaily(ctx, senderID, day)
+	if err != nil {
+		return domain.ErrTransferLimitReached
+	}
+
+	if usedCount >= tu.dailyCount {
+		return fmt.Errorf("batas %d transfer per hari sudah tercapai", tu.dailyCount)
+	}
+
+	remaining := tu.dailyLimit - usedAmount
+	if remaining < 0 {
+		remaining = 0
+	}
+
+	return fmt.Errorf("melebihi limit transfer harian, sisa limit hari ini Rp%s", helpers.FormatRupiah(remaining))
+}
+
+func (tu *transferUsecase) releaseDaily(ctx context.Context, senderID primitive.ObjectID, day string, amount float64) {
+	err := tu.TransferRepo.ReleaseDaily(ctx, senderID, day, amount)
+	if err != nil {
+		log.Println("cannot release transfer limit: ", err.Error())
+	}
+}
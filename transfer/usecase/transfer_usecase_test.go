@@ -0,0 +1,214 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+	"warunk-bem/cache"
+	"warunk-bem/domain"
+	"warunk-bem/dtos"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// mockTransferRepo meniru $inc bersyarat ReserveDaily di memori
+type mockTransferRepo struct {
+	domain.TransferRepository
+
+	mu        sync.Mutex
+	amount    float64
+	count     int64
+	transfers int
+}
+
+func (m *mockTransferRepo) ReserveDaily(ctx context.Context, senderID primitive.ObjectID, day string, amount float64, maxAmount float64, maxCount int64) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.amount+amount > maxAmount || m.count >= maxCount {
+		return domain.ErrTransferLimitReached
+	}
+	m.amount += amount
+	m.count++
+	return nil
+}
+
+func (m *mockTransferRepo) ReleaseDaily(ctx context.Context, senderID primitive.ObjectID, day string, amount float64) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.amount -= amount
+	m.count--
+	return nil
+}
+
+func (m *mockTransferRepo) GetDaily(ctx context.Context, senderID primitive.ObjectID, day string) (float64, int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.amount, m.count, nil
+}
+
+func (m *mockTransferRepo) InsertOne(ctx context.Context, req *domain.Transfer) (*domain.Transfer, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.transfers++
+	return req, nil
+}
+
+type mockUserRepo struct {
+	domain.UserRepository
+
+	users map[string]*domain.User
+}
+
+func (m *mockUserRepo) FindOne(ctx context.Context, id string) (*domain.User, error) {
+	for _, user := range m.users {
+		if user.ID.Hex() == id {
+			return user, nil
+		}
+	}
+	return nil, errors.New("not found")
+}
+
+func (m *mockUserRepo) FindUsername(ctx context.Context, username string) (*domain.User, error) {
+	user, ok := m.users[username]
+	if !ok {
+		return nil, errors.New("not found")
+	}
+	return user, nil
+}
+
+// mockUserAmountRepo meniru IncrementAmount yang menolak saldo negatif
+type mockUserAmountRepo struct {
+	domain.UserAmountRepository
+
+	mu         sync.Mutex
+	saldo      map[string]float64
+	failCredit string
+}
+
+func (m *mockUserAmountRepo) IncrementAmount(ctx context.Context, userID string, delta float64) (*domain.UserAmount, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if userID == m.failCredit && delta > 0 {
+		return nil, errors.New("saldo not found")
+	}
+	if m.saldo[userID]+delta < 0 {
+		return nil, errors.New("saldo tidak cukup")
+	}
+	m.saldo[userID] += delta
+	return &domain.UserAmount{Amount: m.saldo[userID]}, nil
+}
+
+func (m *mockUserAmountRepo) DecrementAmount(ctx context.Context, userID string, amount float64) (*domain.UserAmount, error) {
+	if amount < 0 {
+		return nil, domain.ErrNegativeDebit
+	}
+	return m.IncrementAmount(ctx, userID, -amount)
+}
+
+type mockPinUsecase struct {
+	domain.PinUsecase
+
+	err error
+}
+
+func (m *mockPinUsecase) Confirm(ctx context.Context, userID string, pin string, password string) error {
+	return m.err
+}
+
+const (
+	testMinAmount  = 1000
+	testDailyLimit = 100000
+	testDailyCount = 3
+)
+
+func TestTransfer(t *testing.T) {
+	sender := &domain.User{ID: primitive.NewObjectID(), Username: "pengirim"}
+	receiver := &domain.User{ID: primitive.NewObjectID(), Username: "penerima"}
+
+	tests := []struct {
+		name         string
+		req          dtos.TransferRequest
+		pinErr       error
+		usedAmount   float64
+		usedCount    int64
+		senderSaldo  float64
+		failCredit   bool
+		wantErr      bool
+		wantSender   float64
+		wantReceiver float64
+		wantUsed     float64
+		wantCount    int64
+	}{
+		{name: "success", req: dtos.TransferRequest{Username: "penerima", Amount: 20000}, senderSaldo: 50000, wantSender: 30000, wantReceiver: 20000, wantUsed: 20000, wantCount: 1},
+		{name: "below minimum", req: dtos.TransferRequest{Username: "penerima", Amount: 500}, senderSaldo: 50000, wantErr: true, wantSender: 50000},
+		{name: "wrong pin", req: dtos.TransferRequest{Username: "penerima", Amount: 20000}, pinErr: errors.New("PIN salah"), senderSaldo: 50000, wantErr: true, wantSender: 50000},
+		{name: "unknown receiver", req: dtos.TransferRequest{Username: "siapa", Amount: 20000}, senderSaldo: 50000, wantErr: true, wantSender: 50000},
+		{name: "to self", req: dtos.TransferRequest{Username: "pengirim", Amount: 20000}, senderSaldo: 50000, wantErr: true, wantSender: 50000},
+		{name: "daily amount exceeded", req: dtos.TransferRequest{Username: "penerima", Amount: 20000}, usedAmount: 90000, usedCount: 1, senderSaldo: 50000, wantErr: true, wantSender: 50000, wantUsed: 90000, wantCount: 1},
+		{name: "daily count reached", req: dtos.TransferRequest{Username: "penerima", Amount: 1000}, usedAmount: 3000, usedCount: testDailyCount, senderSaldo: 50000, wantErr: true, wantSender: 50000, wantUsed: 3000, wantCount: testDailyCount},
+		{name: "insufficient saldo releases limit", req: dtos.TransferRequest{Username: "penerima", Amount: 20000}, senderSaldo: 10000, wantErr: true, wantSender: 10000},
+		{name: "receiver credit failure refunds sender", req: dtos.TransferRequest{Username: "penerima", Amount: 20000}, senderSaldo: 50000, failCredit: true, wantErr: true, wantSender: 50000},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			transferRepo := &mockTransferRepo{amount: tt.usedAmount, count: tt.usedCount}
+			userRepo := &mockUserRepo{users: map[string]*domain.User{"pengirim": sender, "penerima": receiver}}
+			userAmountRepo := &mockUserAmountRepo{saldo: map[string]float64{sender.ID.Hex(): tt.senderSaldo}}
+			if tt.failCredit {
+				userAmountRepo.failCredit = receiver.ID.Hex()
+			}
+			tu := NewTransferUsecase(transferRepo, userRepo, userAmountRepo, &mockPinUsecase{err: tt.pinErr}, nil, cache.NewMemoryCache(), testMinAmount, testDailyLimit, testDailyCount, time.Second)
+
+			_, err := tu.Transfer(context.Background(), sender.ID.Hex(), &tt.req)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if got := userAmountRepo.saldo[sender.ID.Hex()]; got != tt.wantSender {
+				t.Errorf("sender saldo = %.0f, want %.0f", got, tt.wantSender)
+			}
+			if got := userAmountRepo.saldo[receiver.ID.Hex()]; got != tt.wantReceiver {
+				t.Errorf("receiver saldo = %.0f, want %.0f", got, tt.wantReceiver)
+			}
+			if transferRepo.amount != tt.wantUsed || transferRepo.count != tt.wantCount {
+				t.Errorf("daily usage = Rp%.0f x%d, want Rp%.0f x%d", transferRepo.amount, transferRepo.count, tt.wantUsed, tt.wantCount)
+			}
+		})
+	}
+}
+
+func TestTransferConcurrentDailyLimit(t *testing.T) {
+	sender := &domain.User{ID: primitive.NewObjectID(), Username: "pengirim"}
+	receiver := &domain.User{ID: primitive.NewObjectID(), Username: "penerima"}
+
+	transferRepo := &mockTransferRepo{}
+	userRepo := &mockUserRepo{users: map[string]*domain.User{"pengirim": sender, "penerima": receiver}}
+	userAmountRepo := &mockUserAmountRepo{saldo: map[string]float64{sender.ID.Hex(): 1000000}}
+	tu := NewTransferUsecase(transferRepo, userRepo, userAmountRepo, &mockPinUsecase{}, nil, cache.NewMemoryCache(), testMinAmount, testDailyLimit, testDailyCount, time.Second)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, _ = tu.Transfer(context.Background(), sender.ID.Hex(), &dtos.TransferRequest{Username: "penerima", Amount: 40000})
+		}()
+	}
+	wg.Wait()
+
+	// 40.000 x 2 masih di bawah limit 100.000, transfer ketiga harus ditolak
+	if got := userAmountRepo.saldo[receiver.ID.Hex()]; got != 80000 {
+		t.Errorf("received = %.0f, want 80000", got)
+	}
+	if transferRepo.transfers != 2 {
+		t.Errorf("transfers = %d, want 2", transferRepo.transfers)
+	}
+}
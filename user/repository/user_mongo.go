@@ -118,16 +118,17 @@ func (m *userRepository) UpdateOne(ctx context.Context, user *domain.User, id st
 
 	filter := bson.M{"_id": idHex}
 	update := bson.M{"$set": bson.M{
-		"name":            user.Name,
-		"email":           user.Email,
-		"username":        user.Username,
-		"password":        user.Password,
-		"updated_at":      time.Now(),
-		"role":            user.Role,
-		"verified":        user.Verified,
-		"loginverif":      user.LoginVerif,
-		"verification":    user.VerificationCode,
-		"activation_code": user.ActivationCode,
+		"name":                  user.Name,
+		"email":                 user.Email,
+		"username":              user.Username,
+		"password":              user.Password,
+		"updated_at":            time.Now(),
+		"role":                  user.Role,
+		"verified":              user.Verified,
+		"loginverif":            user.LoginVerif,
+		"verification":          user.VerificationCode,
+		"activation_code":       user.ActivationCode,
+		"notifikasi_preference": user.NotifikasiPreference,
 	}}
 
 	_, err = m.Collection.UpdateOne(ctx, filter, update)
@@ -2,11 +2,11 @@ package usecase
 
 import (
 	"context"
-	"encoding/json"
 	"errors"
 	"strconv"
 	"strings"
 	"time"
+	"warunk-bem/cache"
 	"warunk-bem/domain"
 	"warunk-bem/dtos"
 	"warunk-bem/helpers"
@@ -14,7 +14,6 @@ import (
 	"warunk-bem/utils"
 
 	"github.com/gin-gonic/gin"
-	"github.com/go-redis/redis/v8"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"golang.org/x/crypto/bcrypt"
 )
@@ -22,15 +21,15 @@ import (
 type userUsecase struct {
 	userRepo       domain.UserRepository
 	UserAmountRepo domain.UserAmountRepository
-	RedisClient    *redis.Client
+	Cache          domain.Cache
 	contextTimeout time.Duration
 }
 
-func NewUserUsecase(u domain.UserRepository, ua domain.UserAmountRepository, RedisClient *redis.Client, to time.Duration) domain.UserUsecase {
+func NewUserUsecase(u domain.UserRepository, ua domain.UserAmountRepository, Cache domain.Cache, to time.Duration) domain.UserUsecase {
 	return &userUsecase{
 		userRepo:       u,
 		UserAmountRepo: ua,
-		RedisClient:    RedisClient,
+		Cache:          Cache,
 		contextTimeout: to,
 	}
 }
@@ -159,21 +158,23 @@ func (u *userUsecase) InsertOne(c context.Context, req *dtos.RegisterUserRequest
 // @Router       /user/profile [get]
 // @Security BearerAuth
 func (u *userUsecase) FindOne(c context.Context, id string) (res *dtos.UserProfileResponse, err error) {
-	ctx, cancel := context.WithTimeout(c, u.contextTimeout)
-	defer cancel()
-
-	req, err := u.userRepo.FindOne(ctx, id)
-	if err != nil {
-		return res, err
-	}
-
-	res = &dtos.UserProfileResponse{
-		Name:     req.Name,
-		Username: req.Username,
-		Email:    req.Email,
-	}
-
-	return res, nil
+	cacheKey := cache.Key(c, u.Cache, []string{cache.UserNamespace(id)}, "profile")
+
+	return cache.Remember(c, u.Cache, cacheKey, cache.DefaultTTL, func() (*dtos.UserProfileResponse, error) {
+		ctx, cancel := context.WithTimeout(c, u.contextTimeout)
+		defer cancel()
+
+		req, err := u.userRepo.FindOne(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+
+		return &dtos.UserProfileResponse{
+			Name:     req.Name,
+			Username: req.Username,
+			Email:    req.Email,
+		}, nil
+	})
 }
 
 // GetAllUsers godoc
@@ -255,11 +256,7 @@ func (u *userUsecase) UpdateOne(c context.Context, req *dtos.UpdateUserRequest,
 		Email:    resp.Email,
 	}
 
-	cacheKey := "user:" + id
-	cacheValue, err := json.Marshal(res)
-	if err == nil {
-		u.RedisClient.Set(ctx, cacheKey, cacheValue, 10*time.Minute)
-	}
+	cache.InvalidateUser(c, u.Cache, id)
 
 	return res, nil
 }
@@ -360,6 +357,8 @@ func (u *userUsecase) VerifyAccount(c context.Context, activation int) (res dtos
 		return res, err
 	}
 
+	cache.InvalidateUser(c, u.Cache, idUser)
+
 	res = dtos.VerifyEmailResponse{
 		Email:   result.Email,
 		Message: "Email has been verified",
@@ -402,5 +401,7 @@ func (u *userUsecase) DeleteOne(c context.Context, id string, req dtos.DeleteUse
 		return res, err
 	}
 
+	cache.InvalidateUser(c, u.Cache, id)
+
 	return res, nil
 }
@@ -2,29 +2,41 @@ package http
 
 import (
 	"context"
+	"math"
 	"net/http"
 	"warunk-bem/domain"
 	"warunk-bem/dtos"
+	"warunk-bem/helpers"
 	"warunk-bem/middlewares"
 
 	"github.com/gin-gonic/gin"
 	"github.com/go-playground/validator/v10"
+	"go.mongodb.org/mongo-driver/bson"
 )
 
 type UserAmountHandler struct {
 	UserAmountUsecase domain.UserAmountUsecase
+	MediaUsecase      domain.ClourdinaryUsecase
 }
 
-func NewUserAmountHandler(protectedAdmin *gin.RouterGroup, uu domain.UserAmountUsecase) {
+func NewUserAmountHandler(protected *gin.RouterGroup, protectedAdmin *gin.RouterGroup, uu domain.UserAmountUsecase, mu domain.ClourdinaryUsecase) {
 	handler := &UserAmountHandler{
 		UserAmountUsecase: uu,
+		MediaUsecase:      mu,
 	}
 
+	protected = protected.Group("/topup")
+	protected.POST("/request", handler.RequestTopUp)
+	protected.GET("/request/me", handler.GetMyTopUpRequests)
+
 	protectedAdmin = protectedAdmin.Group("/topup")
 	protectedAdmin.POST("", handler.TopUpSaldo)
+	protectedAdmin.GET("/request", handler.GetTopUpRequests)
+	protectedAdmin.PUT("/request/:id/approve", handler.ApproveTopUpRequest)
+	protectedAdmin.PUT("/request/:id/reject", handler.RejectTopUpRequest)
 }
 
-func isRequestValid(m *dtos.TopUpSaldoRequest) (bool, error) {
+func isRequestValid(m interface{}) (bool, error) {
 	validate := validator.New()
 	err := validate.Struct(m)
 	if err != nil {
@@ -104,3 +116,290 @@ func (uas *UserAmountHandler) TopUpSaldo(c *gin.Context) {
 		),
 	)
 }
+
+func topUpRequestPage(res []*dtos.TopUpRequestResponse, count int64, rp int64, page int64) dtos.GetAllTopUpRequestResponse {
+	return dtos.GetAllTopUpRequestResponse{
+		Total:       count,
+		PerPage:     rp,
+		CurrentPage: page,
+		LastPage:    int64(math.Ceil(float64(count) / float64(rp))),
+		From:        (page * rp) - rp + 1,
+		To:          page * rp,
+		Request:     res,
+	}
+}
+
+func (uas *UserAmountHandler) RequestTopUp(c *gin.Context) {
+	var req dtos.TopUpRequestRequest
+
+	idUser, err := middlewares.IsUser(c)
+	if err != nil {
+		c.JSON(
+			http.StatusUnauthorized,
+			dtos.NewErrorResponse(
+				http.StatusUnauthorized,
+				"Please login first to access this pages",
+				dtos.GetErrorData(err),
+			),
+		)
+		return
+	}
+
+	err = c.ShouldBind(&req)
+	if err != nil {
+		c.JSON(
+			http.StatusUnprocessableEntity,
+			dtos.NewErrorResponse(
+				http.StatusUnprocessableEntity,
+				"Field cannot be empty",
+				dtos.GetErrorData(err),
+			),
+		)
+		return
+	}
+
+	if ok, err := isRequestValid(&req); !ok {
+		c.JSON(
+			http.StatusBadRequest,
+			dtos.NewErrorResponse(
+				http.StatusBadRequest,
+				"Invalid request",
+				dtos.GetErrorData(err),
+			),
+		)
+		return
+	}
+
+	file, err := c.FormFile("receipt")
+	if err != nil {
+		c.JSON(
+			http.StatusBadRequest,
+			dtos.NewErrorResponse(
+				http.StatusBadRequest,
+				"Bukti transfer wajib diunggah",
+				dtos.GetErrorData(err),
+			),
+		)
+		return
+	}
+
+	src, err := file.Open()
+	if err != nil {
+		c.JSON(
+			http.StatusBadRequest,
+			dtos.NewErrorResponse(
+				http.StatusBadRequest,
+				"Failed to open file",
+				dtos.GetErrorData(err),
+			),
+		)
+		return
+	}
+	defer src.Close()
+
+	receiptURL, err := uas.MediaUsecase.FileUpload(domain.File{File: src, Filename: file.Filename, Size: file.Size})
+	if err != nil {
+		c.JSON(
+			http.StatusBadRequest,
+			dtos.NewErrorResponse(
+				http.StatusBadRequest,
+				"Error uploading receipt",
+				dtos.GetErrorData(err),
+			),
+		)
+		return
+	}
+
+	result, err := uas.UserAmountUsecase.RequestTopUp(helpers.RequestContext(c), idUser, &req, receiptURL)
+	if err != nil {
+		c.JSON(
+			http.StatusBadRequest,
+			dtos.NewErrorResponse(
+				http.StatusBadRequest,
+				"Cannot request top up",
+				err.Error(),
+			),
+		)
+		return
+	}
+
+	c.JSON(
+		http.StatusCreated,
+		dtos.NewResponse(
+			http.StatusCreated,
+			"Success request top up",
+			result,
+		),
+	)
+}
+
+func (uas *UserAmountHandler) GetMyTopUpRequests(c *gin.Context) {
+	idUser, err := middlewares.IsUser(c)
+	if err != nil {
+		c.JSON(
+			http.StatusUnauthorized,
+			dtos.NewErrorResponse(
+				http.StatusUnauthorized,
+				"Please login first to access this pages",
+				dtos.GetErrorData(err),
+			),
+		)
+		return
+	}
+
+	rp, page := helpers.Pagination(c)
+
+	res, count, err := uas.UserAmountUsecase.GetMyTopUpRequests(helpers.RequestContext(c), idUser, rp, page)
+	if err != nil {
+		c.JSON(
+			http.StatusBadRequest,
+			dtos.NewErrorResponse(
+				http.StatusBadRequest,
+				"Cannot get top up request",
+				err.Error(),
+			),
+		)
+		return
+	}
+
+	c.JSON(
+		http.StatusOK,
+		dtos.NewResponse(
+			http.StatusOK,
+			"Success get top up request",
+			topUpRequestPage(res, count, rp, page),
+		),
+	)
+}
+
+func (uas *UserAmountHandler) GetTopUpRequests(c *gin.Context) {
+	rp, page := helpers.Pagination(c)
+
+	status := c.Query("status")
+	if status == "" {
+		status = domain.TopUpRequestStatusPending
+	}
+
+	res, count, err := uas.UserAmountUsecase.GetTopUpRequests(helpers.RequestContext(c), rp, page, bson.M{"status": status})
+	if err != nil {
+		c.JSON(
+			http.StatusBadRequest,
+			dtos.NewErrorResponse(
+				http.StatusBadRequest,
+				"Cannot get top up request",
+				err.Error(),
+			),
+		)
+		return
+	}
+
+	c.JSON(
+		http.StatusOK,
+		dtos.NewResponse(
+			http.StatusOK,
+			"Success get top up request",
+			topUpRequestPage(res, count, rp, page),
+		),
+	)
+}
+
+func (uas *UserAmountHandler) ApproveTopUpRequest(c *gin.Context) {
+	idAdmin, err := middlewares.IsAdmin(c)
+	if err != nil {
+		c.JSON(
+			http.StatusForbidden,
+			dtos.NewErrorResponse(
+				http.StatusForbidden,
+				"Only admin can approve top up",
+				dtos.GetErrorData(err),
+			),
+		)
+		return
+	}
+
+	result, err := uas.UserAmountUsecase.ApproveTopUpRequest(helpers.RequestContext(c), c.Param("id"), idAdmin)
+	if err != nil {
+		c.JSON(
+			http.StatusBadRequest,
+			dtos.NewErrorResponse(
+				http.StatusBadRequest,
+				"Cannot approve top up",
+				err.Error(),
+			),
+		)
+		return
+	}
+
+	c.JSON(
+		http.StatusOK,
+		dtos.NewResponse(
+			http.StatusOK,
+			"Success approve top up",
+			result,
+		),
+	)
+}
+
+func (uas *UserAmountHandler) RejectTopUpRequest(c *gin.Context) {
+	var req dtos.RejectTopUpRequestRequest
+
+	idAdmin, err := middlewares.IsAdmin(c)
+	if err != nil {
+		c.JSON(
+			http.StatusForbidden,
+			dtos.NewErrorResponse(
+				http.StatusForbidden,
+				"Only admin can reject top up",
+				dtos.GetErrorData(err),
+			),
+		)
+		return
+	}
+
+	err = c.ShouldBindJSON(&req)
+	if err != nil {
+		c.JSON(
+			http.StatusUnprocessableEntity,
+			dtos.NewErrorResponse(
+				http.StatusUnprocessableEntity,
+				"Field cannot be empty",
+				dtos.GetErrorData(err),
+			),
+		)
+		return
+	}
+
+	if ok, err := isRequestValid(&req); !ok {
+		c.JSON(
+			http.StatusBadRequest,
+			dtos.NewErrorResponse(
+				http.StatusBadRequest,
+				"Invalid request",
+				dtos.GetErrorData(err),
+			),
+		)
+		return
+	}
+
+	result, err := uas.UserAmountUsecase.RejectTopUpRequest(helpers.RequestContext(c), c.Param("id"), idAdmin, &req)
+	if err != nil {
+		c.JSON(
+			http.StatusBadRequest,
+			dtos.NewErrorResponse(
+				http.StatusBadRequest,
+				"Cannot reject top up",
+				err.Error(),
+			),
+		)
+		return
+	}
+
+	c.JSON(
+		http.StatusOK,
+		dtos.NewResponse(
+			http.StatusOK,
+			"Success reject top up",
+			result,
+		),
+	)
+}
@@ -2,11 +2,14 @@ package repository
 
 import (
 	"context"
+	"errors"
+	"time"
 	"warunk-bem/domain"
 	"warunk-bem/mongo"
 
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
 type userAmountRepository struct {
@@ -15,8 +18,10 @@ type userAmountRepository struct {
 }
 
 const (
-	timeFormat     = "2006-01-02T15:04:05.999Z07:00" // reduce precision from RFC3339Nano as date format
-	collectionName = "user_amount"
+	timeFormat                 = "2006-01-02T15:04:05.999Z07:00" // reduce precision from RFC3339Nano as date format
+	collectionName             = "user_amount"
+	topUpCollectionName        = "topup"
+	topUpRequestCollectionName = "topup_request"
 )
 
 func NewUserAmountRepository(DB mongo.Database) domain.UserAmountRepository {
@@ -50,25 +55,130 @@ func (r *userAmountRepository) FindOne(ctx context.Context, id string) (res *dom
 	return &amount, nil
 }
 
-func (r *userAmountRepository) UpdateOne(ctx context.Context, amount *domain.UserAmount, id string) (res *domain.UserAmount, err error) {
+func (r *userAmountRepository) InsertTopUp(ctx context.Context, req *domain.TopUp) error {
+	_, err := r.DB.Collection(topUpCollectionName).InsertOne(ctx, req)
+	return err
+}
+
+// IncrementAmount mengubah saldo secara atomik, delta negatif ditolak jika saldo tidak cukup
+func (r *userAmountRepository) IncrementAmount(ctx context.Context, userID string, delta float64) (res *domain.UserAmount, err error) {
+	var amount domain.UserAmount
+
+	idHex, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	filter := bson.M{"user_id": idHex}
+	if delta < 0 {
+		filter["amount"] = bson.M{"$gte": -delta}
+	}
+
+	result, err := r.Collection.UpdateOne(ctx, filter, bson.M{"$inc": bson.M{"amount": delta}})
+	if err != nil {
+		return nil, err
+	}
+
+	if result.MatchedCount == 0 {
+		if delta < 0 {
+			return nil, errors.New("saldo tidak cukup")
+		}
+		return nil, errors.New("saldo user tidak ditemukan")
+	}
+
+	err = r.Collection.FindOne(ctx, bson.M{"user_id": idHex}).Decode(&amount)
+	if err != nil {
+		return nil, err
+	}
+
+	return &amount, nil
+}
+
+// DecrementAmount dipakai setiap pembayaran, amount negatif ditolak agar pemotongan tidak berubah menjadi penambahan
+func (r *userAmountRepository) DecrementAmount(ctx context.Context, userID string, amount float64) (*domain.UserAmount, error) {
+	if amount < 0 {
+		return nil, domain.ErrNegativeDebit
+	}
+
+	return r.IncrementAmount(ctx, userID, -amount)
+}
+
+func (r *userAmountRepository) InsertTopUpRequest(ctx context.Context, req *domain.TopUpRequest) (*domain.TopUpRequest, error) {
+	_, err := r.DB.Collection(topUpRequestCollectionName).InsertOne(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	return req, nil
+}
+
+func (r *userAmountRepository) FindTopUpRequest(ctx context.Context, id string) (*domain.TopUpRequest, error) {
+	var req domain.TopUpRequest
+
 	idHex, err := primitive.ObjectIDFromHex(id)
 	if err != nil {
-		return amount, err
+		return nil, err
+	}
+
+	err = r.DB.Collection(topUpRequestCollectionName).FindOne(ctx, bson.M{"_id": idHex}).Decode(&req)
+	if err != nil {
+		return nil, err
+	}
+
+	return &req, nil
+}
+
+func (r *userAmountRepository) GetTopUpRequestsWithPage(ctx context.Context, rp int64, p int64, filter interface{}, setsort interface{}) ([]domain.TopUpRequest, int64, error) {
+	var requests []domain.TopUpRequest
+
+	collection := r.DB.Collection(topUpRequestCollectionName)
+
+	findOptions := options.Find()
+	findOptions.SetLimit(rp)
+	findOptions.SetSkip((p - 1) * rp)
+	if setsort != nil {
+		findOptions.SetSort(setsort)
+	}
+
+	cursor, err := collection.Find(ctx, filter, findOptions)
+	if err != nil {
+		return requests, 0, err
 	}
 
-	filter := bson.M{"_id": idHex}
+	err = cursor.All(ctx, &requests)
+	if err != nil {
+		return requests, 0, err
+	}
+
+	total, err := collection.CountDocuments(ctx, filter)
+	if err != nil {
+		return requests, 0, err
+	}
+
+	return requests, total, nil
+}
+
+// UpdateTopUpRequestStatus hanya berhasil jika status di database masih fromStatus,
+// sehingga satu pengajuan tidak bisa disetujui dua kali oleh dua bendahara sekaligus
+func (r *userAmountRepository) UpdateTopUpRequestStatus(ctx context.Context, req *domain.TopUpRequest, fromStatus string) error {
+	req.UpdatedAt = time.Now()
+
 	update := bson.M{"$set": bson.M{
-		"amount": amount.Amount,
+		"updated_at":    req.UpdatedAt,
+		"status":        req.Status,
+		"reject_reason": req.RejectReason,
+		"reviewed_by":   req.ReviewedBy,
+		"reviewed_at":   req.ReviewedAt,
 	}}
 
-	_, err = r.Collection.UpdateOne(ctx, filter, update)
+	result, err := r.DB.Collection(topUpRequestCollectionName).UpdateOne(ctx, bson.M{"_id": req.ID, "status": fromStatus}, update)
 	if err != nil {
-		return amount, err
+		return err
 	}
 
-	err = r.Collection.FindOne(ctx, bson.M{"_id": idHex}).Decode(amount)
-	if err != nil {
-		return amount, err
+	if result.MatchedCount == 0 {
+		return errors.New("pengajuan top up sudah diproses")
 	}
-	return amount, nil
+
+	return nil
 }
@@ -3,26 +3,33 @@ package usecase
 import (
 	"context"
 	"errors"
+	"fmt"
+	"log"
 	"time"
+	"warunk-bem/cache"
 	"warunk-bem/domain"
 	"warunk-bem/dtos"
+	"warunk-bem/helpers"
 
-	"github.com/go-redis/redis/v8"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
 type UserAmountUsecase struct {
-	UserAmountRepo domain.UserAmountRepository
-	UserRepo       domain.UserRepository
-	RedisClient    *redis.Client
-	contextTimeout time.Duration
+	UserAmountRepo    domain.UserAmountRepository
+	UserRepo          domain.UserRepository
+	NotifikasiUsecase domain.NotifikasiUsecase
+	Cache             domain.Cache
+	contextTimeout    time.Duration
 }
 
-func NewUserAmountUsecase(ua domain.UserAmountRepository, u domain.UserRepository, RedisClient *redis.Client, timeout time.Duration) domain.UserAmountUsecase {
+func NewUserAmountUsecase(ua domain.UserAmountRepository, u domain.UserRepository, NotifikasiUsecase domain.NotifikasiUsecase, Cache domain.Cache, timeout time.Duration) domain.UserAmountUsecase {
 	return &UserAmountUsecase{
-		UserAmountRepo: ua,
-		UserRepo:       u,
-		RedisClient:    RedisClient,
-		contextTimeout: timeout,
+		UserAmountRepo:    ua,
+		UserRepo:          u,
+		NotifikasiUsecase: NotifikasiUsecase,
+		Cache:             Cache,
+		contextTimeout:    timeout,
 	}
 }
 
@@ -42,10 +49,7 @@ func NewUserAmountUsecase(ua domain.UserAmountRepository, u domain.UserRepositor
 // @Router       /topup [post]
 // @Security BearerAuth
 func (uas *UserAmountUsecase) TopUpSaldo(ctx context.Context, req *dtos.TopUpSaldoRequest) (res *dtos.TopUpSaldoResponse, err error) {
-	var (
-		userAmount *domain.UserAmount
-		user       *domain.User
-	)
+	var user *domain.User
 
 	ctx, cancel := context.WithTimeout(ctx, uas.contextTimeout)
 	defer cancel()
@@ -55,18 +59,25 @@ func (uas *UserAmountUsecase) TopUpSaldo(ctx context.Context, req *dtos.TopUpSal
 		return nil, errors.New("email tidak ditemukan")
 	}
 
-	userAmount, err = uas.UserAmountRepo.FindOne(ctx, user.ID.Hex())
+	_, err = uas.UserAmountRepo.IncrementAmount(ctx, user.ID.Hex(), req.Amount)
 	if err != nil {
-		return nil, errors.New("user tidak ditemukan")
+		return nil, errors.New("tidak dapat menambahkan saldo")
 	}
 
-	userAmount.Amount += req.Amount
-
-	_, err = uas.UserAmountRepo.UpdateOne(ctx, userAmount, userAmount.ID.Hex())
+	// Saldo sudah masuk, kegagalan pencatatan top up cukup dicatat di log
+	err = uas.UserAmountRepo.InsertTopUp(ctx, &domain.TopUp{
+		ID:        primitive.NewObjectID(),
+		CreatedAt: time.Now(),
+		UserID:    user.ID,
+		Amount:    req.Amount,
+		Source:    domain.TopUpSourceAdmin,
+	})
 	if err != nil {
-		return nil, errors.New("tidak dapat menambahkan saldo")
+		log.Println("cannot record top up: ", err.Error())
 	}
 
+	cache.InvalidateUser(ctx, uas.Cache, user.ID.Hex())
+
 	Message := "Saldo berhasil ditambahkan ke akun"
 
 	res = &dtos.TopUpSaldoResponse{
@@ -77,3 +88,290 @@ func (uas *UserAmountUsecase) TopUpSaldo(ctx context.Context, req *dtos.TopUpSal
 
 	return res, nil
 }
+
+func toTopUpRequestResponse(req *domain.TopUpRequest, user *domain.User) *dtos.TopUpRequestResponse {
+	res := &dtos.TopUpRequestResponse{
+		ID:           req.ID.Hex(),
+		CreatedAt:    req.CreatedAt,
+		UserID:       req.UserID.Hex(),
+		Amount:       req.Amount,
+		Note:         req.Note,
+		ReceiptURL:   req.ReceiptURL,
+		Status:       req.Status,
+		RejectReason: req.RejectReason,
+		ReviewedAt:   req.ReviewedAt,
+	}
+
+	if user != nil {
+		res.Name = user.Name
+		res.Email = user.Email
+	}
+
+	return res
+}
+
+// RequestTopUp godoc
+// @Summary      Request Top Up
+// @Description  Request a top up with a transfer receipt, the saldo is credited after a treasurer approves it
+// @Tags         User - Top Up
+// @Accept       multipart/form-data
+// @Produce      json
+// @Param        amount formData number true "Amount"
+// @Param        note formData string false "Note"
+// @Param        receipt formData file true "Transfer receipt image"
+// @Success      201 {object} dtos.TopUpRequestOKResponse
+// @Failure      400 {object} dtos.BadRequestResponse
+// @Failure      401 {object} dtos.UnauthorizedResponse
+// @Failure      403 {object} dtos.ForbiddenResponse
+// @Failure      500 {object} dtos.InternalServerErrorResponse
+// @Router       /topup/request [post]
+// @Security BearerAuth
+func (uas *UserAmountUsecase) RequestTopUp(c context.Context, userID string, req *dtos.TopUpRequestRequest, receiptURL string) (*dtos.TopUpRequestResponse, error) {
+	ctx, cancel := context.WithTimeout(c, uas.contextTimeout)
+	defer cancel()
+
+	if req.Amount <= 0 {
+		return nil, errors.New("amount harus lebih dari 0")
+	}
+
+	user, err := uas.UserRepo.FindOne(ctx, userID)
+	if err != nil {
+		return nil, errors.New("user tidak ditemukan")
+	}
+
+	topUpRequest := &domain.TopUpRequest{
+		ID:         primitive.NewObjectID(),
+		CreatedAt:  time.Now(),
+		UpdatedAt:  time.Now(),
+		UserID:     user.ID,
+		Amount:     req.Amount,
+		Note:       req.Note,
+		ReceiptURL: receiptURL,
+		Status:     domain.TopUpRequestStatusPending,
+	}
+
+	topUpRequest, err = uas.UserAmountRepo.InsertTopUpRequest(ctx, topUpRequest)
+	if err != nil {
+		return nil, errors.New("tidak dapat membuat pengajuan top up")
+	}
+
+	return toTopUpRequestResponse(topUpRequest, user), nil
+}
+
+// GetMyTopUpRequest godoc
+// @Summary      Get My Top Up Request
+// @Description  Get top up requests of the logged in user, newest first
+// @Tags         User - Top Up
+// @Accept       json
+// @Produce      json
+// @Param        rp query int false "rp"
+// @Param        p query int false "p"
+// @Success      200 {object} dtos.TopUpRequestsOKResponse
+// @Failure      400 {object} dtos.BadRequestResponse
+// @Failure      401 {object} dtos.UnauthorizedResponse
+// @Failure      403 {object} dtos.ForbiddenResponse
+// @Failure      500 {object} dtos.InternalServerErrorResponse
+// @Router       /topup/request/me [get]
+// @Security BearerAuth
+func (uas *UserAmountUsecase) GetMyTopUpRequests(c context.Context, userID string, rp int64, p int64) ([]*dtos.TopUpRequestResponse, int64, error) {
+	res := []*dtos.TopUpRequestResponse{}
+
+	ctx, cancel := context.WithTimeout(c, uas.contextTimeout)
+	defer cancel()
+
+	userHex, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return res, 0, err
+	}
+
+	requests, count, err := uas.UserAmountRepo.GetTopUpRequestsWithPage(ctx, rp, p, bson.M{"user_id": userHex}, bson.M{"created_at": -1})
+	if err != nil {
+		return res, 0, err
+	}
+
+	for i := range requests {
+		res = append(res, toTopUpRequestResponse(&requests[i], nil))
+	}
+
+	return res, count, nil
+}
+
+// GetTopUpRequest godoc
+// @Summary      Get Top Up Request
+// @Description  Top up request queue for treasurers, pending requests are returned oldest first
+// @Tags         Admin - Top Up
+// @Accept       json
+// @Produce      json
+// @Param        rp query int false "rp"
+// @Param        p query int false "p"
+// @Param        status query string false "pending (default), approved or rejected"
+// @Success      200 {object} dtos.TopUpRequestsOKResponse
+// @Failure      400 {object} dtos.BadRequestResponse
+// @Failure      401 {object} dtos.UnauthorizedResponse
+// @Failure      403 {object} dtos.ForbiddenResponse
+// @Failure      500 {object} dtos.InternalServerErrorResponse
+// @Router       /topup/request [get]
+// @Security BearerAuth
+func (uas *UserAmountUsecase) GetTopUpRequests(c context.Context, rp int64, p int64, filter interface{}) ([]*dtos.TopUpRequestResponse, int64, error) {
+	res := []*dtos.TopUpRequestResponse{}
+
+	ctx, cancel := context.WithTimeout(c, uas.contextTimeout)
+	defer cancel()
+
+	requests, count, err := uas.UserAmountRepo.GetTopUpRequestsWithPage(ctx, rp, p, filter, bson.M{"created_at": 1})
+	if err != nil {
+		return res, 0, err
+	}
+
+	users := make(map[primitive.ObjectID]*domain.User)
+	for i := range requests {
+		user, ok := users[requests[i].UserID]
+		if !ok {
+			user, err = uas.UserRepo.FindOne(ctx, requests[i].UserID.Hex())
+			if err != nil {
+				user = nil
+			}
+			users[requests[i].UserID] = user
+		}
+
+		res = append(res, toTopUpRequestResponse(&requests[i], user))
+	}
+
+	return res, count, nil
+}
+
+// ApproveTopUpRequest godoc
+// @Summary      Approve Top Up Request
+// @Description  Approve a pending top up request, credit the saldo and notify the user
+// @Tags         Admin - Top Up
+// @Accept       json
+// @Produce      json
+// @Param id path string true "ID Top Up Request"
+// @Success      200 {object} dtos.TopUpRequestOKResponse
+// @Failure      400 {object} dtos.BadRequestResponse
+// @Failure      401 {object} dtos.UnauthorizedResponse
+// @Failure      403 {object} dtos.ForbiddenResponse
+// @Failure      404 {object} dtos.NotFoundResponse
+// @Failure      500 {object} dtos.InternalServerErrorResponse
+// @Router       /topup/request/{id}/approve [put]
+// @Security BearerAuth
+func (uas *UserAmountUsecase) ApproveTopUpRequest(c context.Context, id string, adminID string) (*dtos.TopUpRequestResponse, error) {
+	ctx, cancel := context.WithTimeout(c, uas.contextTimeout)
+	defer cancel()
+
+	topUpRequest, adminHex, err := uas.findPendingTopUpRequest(ctx, id, adminID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	topUpRequest.Status = domain.TopUpRequestStatusApproved
+	topUpRequest.ReviewedBy = adminHex
+	topUpRequest.ReviewedAt = &now
+
+	// Status dikunci lebih dulu agar saldo tidak masuk dua kali
+	err = uas.UserAmountRepo.UpdateTopUpRequestStatus(ctx, topUpRequest, domain.TopUpRequestStatusPending)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = uas.UserAmountRepo.IncrementAmount(ctx, topUpRequest.UserID.Hex(), topUpRequest.Amount)
+	if err != nil {
+		topUpRequest.Status = domain.TopUpRequestStatusPending
+		topUpRequest.ReviewedBy = primitive.NilObjectID
+		topUpRequest.ReviewedAt = nil
+		if rollbackErr := uas.UserAmountRepo.UpdateTopUpRequestStatus(ctx, topUpRequest, domain.TopUpRequestStatusApproved); rollbackErr != nil {
+			log.Println("cannot rollback top up request: ", rollbackErr.Error())
+		}
+		return nil, errors.New("tidak dapat menambahkan saldo")
+	}
+
+	err = uas.UserAmountRepo.InsertTopUp(ctx, &domain.TopUp{
+		ID:          primitive.NewObjectID(),
+		CreatedAt:   now,
+		UserID:      topUpRequest.UserID,
+		Amount:      topUpRequest.Amount,
+		Source:      domain.TopUpSourceRequest,
+		ReferenceID: topUpRequest.ID,
+	})
+	if err != nil {
+		log.Println("cannot record top up: ", err.Error())
+	}
+
+	cache.InvalidateUser(ctx, uas.Cache, topUpRequest.UserID.Hex())
+
+	helpers.NotifyAsync(uas.NotifikasiUsecase, "top up", &domain.Notifikasi{
+		UserID:      topUpRequest.UserID,
+		Type:        domain.NotifikasiTypeTopUp,
+		Title:       "Top up disetujui",
+		Message:     fmt.Sprintf("Top up sebesar Rp%s sudah masuk ke saldo kamu", helpers.FormatRupiah(topUpRequest.Amount)),
+		ReferenceID: topUpRequest.ID,
+	})
+
+	return toTopUpRequestResponse(topUpRequest, nil), nil
+}
+
+// RejectTopUpRequest godoc
+// @Summary      Reject Top Up Request
+// @Description  Reject a pending top up request with a reason and notify the user
+// @Tags         Admin - Top Up
+// @Accept       json
+// @Produce      json
+// @Param id path string true "ID Top Up Request"
+// @Param        request body dtos.RejectTopUpRequestRequest true "Payload Body [RAW]"
+// @Success      200 {object} dtos.TopUpRequestOKResponse
+// @Failure      400 {object} dtos.BadRequestResponse
+// @Failure      401 {object} dtos.UnauthorizedResponse
+// @Failure      403 {object} dtos.ForbiddenResponse
+// @Failure      404 {object} dtos.NotFoundResponse
+// @Failure      500 {object} dtos.InternalServerErrorResponse
+// @Router       /topup/request/{id}/reject [put]
+// @Security BearerAuth
+func (uas *UserAmountUsecase) RejectTopUpRequest(c context.Context, id string, adminID string, req *dtos.RejectTopUpRequestRequest) (*dtos.TopUpRequestResponse, error) {
+	ctx, cancel := context.WithTimeout(c, uas.contextTimeout)
+	defer cancel()
+
+	topUpRequest, adminHex, err := uas.findPendingTopUpRequest(ctx, id, adminID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	topUpRequest.Status = domain.TopUpRequestStatusRejected
+	topUpRequest.RejectReason = req.Reason
+	topUpRequest.ReviewedBy = adminHex
+	topUpRequest.ReviewedAt = &now
+
+	err = uas.UserAmountRepo.UpdateTopUpRequestStatus(ctx, topUpRequest, domain.TopUpRequestStatusPending)
+	if err != nil {
+		return nil, err
+	}
+
+	helpers.NotifyAsync(uas.NotifikasiUsecase, "top up", &domain.Notifikasi{
+		UserID:      topUpRequest.UserID,
+		Type:        domain.NotifikasiTypeTopUp,
+		Title:       "Top up ditolak",
+		Message:     fmt.Sprintf("Top up sebesar Rp%s ditolak: %s", helpers.FormatRupiah(topUpRequest.Amount), req.Reason),
+		ReferenceID: topUpRequest.ID,
+	})
+
+	return toTopUpRequestResponse(topUpRequest, nil), nil
+}
+
+func (uas *UserAmountUsecase) findPendingTopUpRequest(ctx context.Context, id string, adminID string) (*domain.TopUpRequest, primitive.ObjectID, error) {
+	adminHex, err := primitive.ObjectIDFromHex(adminID)
+	if err != nil {
+		return nil, adminHex, errors.New("admin tidak ditemukan")
+	}
+
+	topUpRequest, err := uas.UserAmountRepo.FindTopUpRequest(ctx, id)
+	if err != nil {
+		return nil, adminHex, errors.New("pengajuan top up tidak ditemukan")
+	}
+
+	if topUpRequest.Status != domain.TopUpRequestStatusPending {
+		return nil, adminHex, errors.New("pengajuan top up sudah diproses")
+	}
+
+	return topUpRequest, adminHex, nil
+}
@@ -0,0 +1,149 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+	"warunk-bem/cache"
+	"warunk-bem/domain"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// mockUserAmountRepo menyimpan pengajuan top up di memori, UpdateTopUpRequestStatus meniru filter status di repository
+type mockUserAmountRepo struct {
+	domain.UserAmountRepository
+
+	mu        sync.Mutex
+	requests  map[string]domain.TopUpRequest
+	stale     bool
+	creditErr error
+	credited  float64
+	credits   int
+	topUps    int
+}
+
+func (m *mockUserAmountRepo) FindTopUpRequest(ctx context.Context, id string) (*domain.TopUpRequest, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	req, ok := m.requests[id]
+	if !ok {
+		return nil, errors.New("not found")
+	}
+	// stale meniru admin lain yang memproses pengajuan setelah dibaca
+	if m.stale {
+		req.Status = domain.TopUpRequestStatusPending
+	}
+	return &req, nil
+}
+
+func (m *mockUserAmountRepo) UpdateTopUpRequestStatus(ctx context.Context, req *domain.TopUpRequest, fromStatus string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	stored := m.requests[req.ID.Hex()]
+	if stored.Status != fromStatus {
+		return errors.New("pengajuan top up sudah diproses")
+	}
+	m.requests[req.ID.Hex()] = *req
+	return nil
+}
+
+func (m *mockUserAmountRepo) IncrementAmount(ctx context.Context, userID string, delta float64) (*domain.UserAmount, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.creditErr != nil {
+		return nil, m.creditErr
+	}
+	m.credited += delta
+	m.credits++
+	return &domain.UserAmount{Amount: m.credited}, nil
+}
+
+func (m *mockUserAmountRepo) InsertTopUp(ctx context.Context, req *domain.TopUp) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.topUps++
+	return nil
+}
+
+func newTopUpRequest(status string) domain.TopUpRequest {
+	return domain.TopUpRequest{
+		ID:     primitive.NewObjectID(),
+		UserID: primitive.NewObjectID(),
+		Amount: 50000,
+		Status: status,
+	}
+}
+
+func TestApproveTopUpRequest(t *testing.T) {
+	adminID := primitive.NewObjectID().Hex()
+
+	tests := []struct {
+		name        string
+		status      string
+		stale       bool
+		creditErr   error
+		wantErr     bool
+		wantCredits int
+		wantStatus  string
+	}{
+		{name: "pending request is credited", status: domain.TopUpRequestStatusPending, wantCredits: 1, wantStatus: domain.TopUpRequestStatusApproved},
+		{name: "already approved", status: domain.TopUpRequestStatusApproved, wantErr: true, wantStatus: domain.TopUpRequestStatusApproved},
+		{name: "already rejected", status: domain.TopUpRequestStatusRejected, wantErr: true, wantStatus: domain.TopUpRequestStatusRejected},
+		{name: "processed by another admin after read", status: domain.TopUpRequestStatusApproved, stale: true, wantErr: true, wantStatus: domain.TopUpRequestStatusApproved},
+		{name: "credit failure rolls back status", status: domain.TopUpRequestStatusPending, creditErr: errors.New("mongo down"), wantErr: true, wantStatus: domain.TopUpRequestStatusPending},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := newTopUpRequest(tt.status)
+			repo := &mockUserAmountRepo{
+				requests:  map[string]domain.TopUpRequest{req.ID.Hex(): req},
+				stale:     tt.stale,
+				creditErr: tt.creditErr,
+			}
+			uas := NewUserAmountUsecase(repo, nil, nil, cache.NewMemoryCache(), time.Second)
+
+			_, err := uas.ApproveTopUpRequest(context.Background(), req.ID.Hex(), adminID)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if repo.credits != tt.wantCredits {
+				t.Errorf("credits = %d, want %d", repo.credits, tt.wantCredits)
+			}
+			if repo.topUps != tt.wantCredits {
+				t.Errorf("top up records = %d, want %d", repo.topUps, tt.wantCredits)
+			}
+			if got := repo.requests[req.ID.Hex()].Status; got != tt.wantStatus {
+				t.Errorf("status = %s, want %s", got, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestApproveTopUpRequestConcurrent(t *testing.T) {
+	req := newTopUpRequest(domain.TopUpRequestStatusPending)
+	repo := &mockUserAmountRepo{requests: map[string]domain.TopUpRequest{req.ID.Hex(): req}}
+	uas := NewUserAmountUsecase(repo, nil, nil, cache.NewMemoryCache(), time.Second)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, _ = uas.ApproveTopUpRequest(context.Background(), req.ID.Hex(), primitive.NewObjectID().Hex())
+		}()
+	}
+	wg.Wait()
+
+	if repo.credits != 1 || repo.credited != req.Amount {
+		t.Errorf("credits = %d (Rp%.0f), want exactly one credit of Rp%.0f", repo.credits, repo.credited, req.Amount)
+	}
+}
@@ -0,0 +1,135 @@
+package http
+
+import (
+	"net/http"
+	"warunk-bem/domain"
+	"warunk-bem/dtos"
+	"warunk-bem/helpers"
+	"warunk-bem/middlewares"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+)
+
+type UserQRHandler struct {
+	UserQRUsecase domain.UserQRUsecase
+}
+
+func NewUserQRHandler(protected *gin.RouterGroup, protectedAdmin *gin.RouterGroup, qu domain.UserQRUsecase) {
+	handler := &UserQRHandler{
+		UserQRUsecase: qu,
+	}
+
+	protected = protected.Group("/user")
+	protectedAdmin = protectedAdmin.Group("/user")
+
+	protected.GET("/profile/qr", handler.Generate)
+	protectedAdmin.POST("/qr/resolve", handler.Resolve)
+}
+
+func isRequestValid(m interface{}) (bool, error) {
+	validate := validator.New()
+	err := validate.Struct(m)
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func unauthorized(c *gin.Context, err error) {
+	c.JSON(
+		http.StatusUnauthorized,
+		dtos.NewErrorResponse(
+			http.StatusUnauthorized,
+			"Please login first to access this pages",
+			dtos.GetErrorData(err),
+		),
+	)
+}
+
+func (qh *UserQRHandler) Generate(c *gin.Context) {
+	idUser, err := middlewares.IsUser(c)
+	if err != nil {
+		unauthorized(c, err)
+		return
+	}
+
+	result, err := qh.UserQRUsecase.Generate(helpers.RequestContext(c), idUser)
+	if err != nil {
+		c.JSON(
+			http.StatusBadRequest,
+			dtos.NewErrorResponse(
+				http.StatusBadRequest,
+				"Cannot Generate QR",
+				err.Error(),
+			),
+		)
+		return
+	}
+
+	c.JSON(
+		http.StatusOK,
+		dtos.NewResponse(
+			http.StatusOK,
+			"Success Generate QR",
+			result,
+		),
+	)
+}
+
+func (qh *UserQRHandler) Resolve(c *gin.Context) {
+	var req dtos.ResolveUserQRRequest
+
+	_, err := middlewares.IsAdmin(c)
+	if err != nil {
+		unauthorized(c, err)
+		return
+	}
+
+	err = c.ShouldBindJSON(&req)
+	if err != nil {
+		c.JSON(
+			http.StatusUnprocessableEntity,
+			dtos.NewErrorResponse(
+				http.StatusUnprocessableEntity,
+				"Filed Cannot Be Empty",
+				dtos.GetErrorData(err),
+			),
+		)
+		return
+	}
+
+	if ok, err := isRequestValid(&req); !ok {
+		c.JSON(
+			http.StatusBadRequest,
+			dtos.NewErrorResponse(
+				http.StatusBadRequest,
+				"Bad Request",
+				dtos.GetErrorData(err),
+			),
+		)
+		return
+	}
+
+	result, err := qh.UserQRUsecase.Resolve(helpers.RequestContext(c), req.Payload)
+	if err != nil {
+		c.JSON(
+			http.StatusBadRequest,
+			dtos.NewErrorResponse(
+				http.StatusBadRequest,
+				"Cannot Resolve QR",
+				err.Error(),
+			),
+		)
+		return
+	}
+
+	c.JSON(
+		http.StatusOK,
+		dtos.NewResponse(
+			http.StatusOK,
+			"Success Resolve QR",
+			result,
+		),
+	)
+}
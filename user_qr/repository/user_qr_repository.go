@@ -0,0 +1,81 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+	"warunk-bem/domain"
+	"warunk-bem/mongo"
+
+	"go.mongodb.org/mongo-driver/bson"
+	mongodriver "go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type userQRRepository struct {
+	DB         mongo.Database
+	Collection mongo.Collection
+}
+
+const (
+	timeFormat     = "2006-01-02T15:04:05.999Z07:00" // reduce precision from RFC3339Nano as date format
+	collectionName = "user_qr_nonce"
+
+	// nonceRetention nonce disimpan sehari setelah kedaluwarsa lalu dihapus oleh TTL index
+	nonceRetention = int32(24 * 60 * 60)
+)
+
+func NewUserQRRepository(DB mongo.Database) domain.UserQRRepository {
+	return &userQRRepository{DB, DB.Collection(collectionName)}
+}
+
+func (r *userQRRepository) EnsureIndexes(ctx context.Context) error {
+	_, err := r.Collection.CreateIndexes(ctx, []mongodriver.IndexModel{
+		{
+			Keys:    bson.D{{Key: "nonce", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+		{
+			Keys:    bson.D{{Key: "expires_at", Value: 1}},
+			Options: options.Index().SetExpireAfterSeconds(nonceRetention),
+		},
+	})
+	return err
+}
+
+func (r *userQRRepository) InsertOne(ctx context.Context, nonce *domain.UserQRNonce) error {
+	_, err := r.Collection.InsertOne(ctx, nonce)
+	return err
+}
+
+func (r *userQRRepository) FindByNonce(ctx context.Context, nonce string) (*domain.UserQRNonce, error) {
+	var res domain.UserQRNonce
+
+	err := r.Collection.FindOne(ctx, bson.M{"nonce": nonce}).Decode(&res)
+	if errors.Is(err, mongodriver.ErrNoDocuments) {
+		return nil, domain.ErrUserQRInvalid
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &res, nil
+}
+
+func (r *userQRRepository) Consume(ctx context.Context, nonce string, usedAt time.Time) error {
+	filter := bson.M{
+		"nonce":   nonce,
+		"used_at": bson.M{"$exists": false},
+	}
+
+	result, err := r.Collection.UpdateOne(ctx, filter, bson.M{"$set": bson.M{"used_at": usedAt}})
+	if err != nil {
+		return err
+	}
+
+	if result.MatchedCount == 0 {
+		return domain.ErrUserQRUsed
+	}
+
+	return nil
+}
@@ -0,0 +1,231 @@
+package usecase
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"strings"
+	"time"
+	"warunk-bem/domain"
+	"warunk-bem/dtos"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type userQRUsecase struct {
+	UserQRRepo     domain.UserQRRepository
+	UserRepo       domain.UserRepository
+	secret         []byte
+	ttl            time.Duration
+	contextTimeout time.Duration
+}
+
+func NewUserQRUsecase(UserQRRepo domain.UserQRRepository, UserRepo domain.UserRepository, secret string, ttl time.Duration, contextTimeout time.Duration) domain.UserQRUsecase {
+	return &userQRUsecase{
+		UserQRRepo:     UserQRRepo,
+		UserRepo:       UserRepo,
+		secret:         []byte(secret),
+		ttl:            ttl,
+		contextTimeout: contextTimeout,
+	}
+}
+
+// GetUserQR godoc
+// @Summary      Get Payment QR
+// @Description  Get a short-lived signed QR payload to pay with saldo at the warunk table. Each payload can only be used once
+// @Tags         User - Account
+// @Accept       json
+// @Produce      json
+// @Success      200 {object} dtos.UserQROKResponse
+// @Failure      400 {object} dtos.BadRequestResponse
+// @Failure      401 {object} dtos.UnauthorizedResponse
+// @Failure      403 {object} dtos.ForbiddenResponse
+// @Failure      500 {object} dtos.InternalServerErrorResponse
+// @Router       /user/profile/qr [get]
+// @Security BearerAuth
+func (qu *userQRUsecase) Generate(c context.Context, userID string) (*dtos.UserQRResponse, error) {
+	ctx, cancel := context.WithTimeout(c, qu.contextTimeout)
+	defer cancel()
+
+	user, err := qu.UserRepo.FindOne(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce, err := randomNonce()
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	expiresAt := now.Add(qu.ttl)
+	err = qu.UserQRRepo.InsertOne(ctx, &domain.UserQRNonce{
+		ID:        primitive.NewObjectID(),
+		CreatedAt: now,
+		UserID:    user.ID,
+		Nonce:     nonce,
+		ExpiresAt: expiresAt,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	payload, err := qu.sign(&domain.UserQRClaims{
+		UserID:    user.ID.Hex(),
+		ExpiresAt: expiresAt.Unix(),
+		Nonce:     nonce,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &dtos.UserQRResponse{
+		Payload:   payload,
+		ExpiresAt: expiresAt,
+		ExpiresIn: int64(qu.ttl.Seconds()),
+	}, nil
+}
+
+// ResolveUserQR godoc
+// @Summary      Resolve Payment QR
+// @Description  Show the owner of a payment QR to the cashier without using it. Charge the buyer with payment_method saldo and buyer_qr on POST /transaksi/kasir
+// @Tags         Admin - Transaksi
+// @Accept       json
+// @Produce      json
+// @Param        request body dtos.ResolveUserQRRequest true "Payload Body [RAW]"
+// @Success      200 {object} dtos.UserQRResolveOKResponse
+// @Failure      400 {object} dtos.BadRequestResponse
+// @Failure      401 {object} dtos.UnauthorizedResponse
+// @Failure      403 {object} dtos.ForbiddenResponse
+// @Failure      500 {object} dtos.InternalServerErrorResponse
+// @Router       /user/qr/resolve [post]
+// @Security BearerAuth
+func (qu *userQRUsecase) Resolve(c context.Context, payload string) (*dtos.UserQRResolveResponse, error) {
+	ctx, cancel := context.WithTimeout(c, qu.contextTimeout)
+	defer cancel()
+
+	claims, err := qu.verify(ctx, payload)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := qu.UserRepo.FindOne(ctx, claims.UserID)
+	if err != nil {
+		return nil, domain.ErrUserQRInvalid
+	}
+
+	return &dtos.UserQRResolveResponse{
+		UserID:    user.ID.Hex(),
+		Name:      user.Name,
+		Username:  user.Username,
+		ExpiresAt: time.Unix(claims.ExpiresAt, 0),
+	}, nil
+}
+
+func (qu *userQRUsecase) Verify(c context.Context, payload string) (*domain.User, error) {
+	ctx, cancel := context.WithTimeout(c, qu.contextTimeout)
+	defer cancel()
+
+	claims, err := qu.verify(ctx, payload)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := qu.UserRepo.FindOne(ctx, claims.UserID)
+	if err != nil {
+		return nil, domain.ErrUserQRInvalid
+	}
+
+	return user, nil
+}
+
+func (qu *userQRUsecase) Consume(c context.Context, payload string) error {
+	ctx, cancel := context.WithTimeout(c, qu.contextTimeout)
+	defer cancel()
+
+	claims, err := qu.verify(ctx, payload)
+	if err != nil {
+		return err
+	}
+
+	return qu.UserQRRepo.Consume(ctx, claims.Nonce, time.Now())
+}
+
+// verify memeriksa tanda tangan, masa berlaku dan nonce QR tanpa memakai nonce tersebut
+func (qu *userQRUsecase) verify(ctx context.Context, payload string) (*domain.UserQRClaims, error) {
+	body, ok := strings.CutPrefix(strings.TrimSpace(payload), domain.UserQRPrefix)
+	if !ok {
+		return nil, domain.ErrUserQRInvalid
+	}
+
+	encoded, signature, ok := strings.Cut(body, ".")
+	if !ok || !hmac.Equal([]byte(signature), []byte(qu.signature(encoded))) {
+		return nil, domain.ErrUserQRInvalid
+	}
+
+	raw, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, domain.ErrUserQRInvalid
+	}
+
+	var claims domain.UserQRClaims
+	err = json.Unmarshal(raw, &claims)
+	if err != nil {
+		return nil, domain.ErrUserQRInvalid
+	}
+
+	now := time.Now()
+	if now.Unix() >= claims.ExpiresAt {
+		return nil, domain.ErrUserQRExpired
+	}
+
+	// Nonce harus pernah diterbitkan untuk user yang sama dan belum dipakai
+	nonce, err := qu.UserQRRepo.FindByNonce(ctx, claims.Nonce)
+	if err != nil {
+		return nil, err
+	}
+
+	if nonce.UserID.Hex() != claims.UserID {
+		return nil, domain.ErrUserQRInvalid
+	}
+
+	if nonce.UsedAt != nil {
+		return nil, domain.ErrUserQRUsed
+	}
+
+	if !now.Before(nonce.ExpiresAt) {
+		return nil, domain.ErrUserQRExpired
+	}
+
+	return &claims, nil
+}
+
+func (qu *userQRUsecase) sign(claims *domain.UserQRClaims) (string, error) {
+	raw, err := json.Marshal(claims)
+	if err != nil {
+		return "", err
+	}
+
+	encoded := base64.RawURLEncoding.EncodeToString(raw)
+	return domain.UserQRPrefix + encoded + "." + qu.signature(encoded), nil
+}
+
+func (qu *userQRUsecase) signature(encoded string) string {
+	mac := hmac.New(sha256.New, qu.secret)
+	mac.Write([]byte(encoded))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func randomNonce() (string, error) {
+	b := make([]byte, 16)
+	_, err := rand.Read(b)
+	if err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(b), nil
+}
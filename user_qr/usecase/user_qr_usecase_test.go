@@ -0,0 +1,177 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+	"warunk-bem/domain"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// mockUserQRRepo meniru Consume yang hanya berhasil sekali untuk setiap nonce
+type mockUserQRRepo struct {
+	domain.UserQRRepository
+
+	mu     sync.Mutex
+	nonces map[string]*domain.UserQRNonce
+}
+
+func (m *mockUserQRRepo) InsertOne(ctx context.Context, nonce *domain.UserQRNonce) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.nonces[nonce.Nonce] = nonce
+	return nil
+}
+
+func (m *mockUserQRRepo) FindByNonce(ctx context.Context, nonce string) (*domain.UserQRNonce, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	res, ok := m.nonces[nonce]
+	if !ok {
+		return nil, domain.ErrUserQRInvalid
+	}
+	copied := *res
+	return &copied, nil
+}
+
+func (m *mockUserQRRepo) Consume(ctx context.Context, nonce string, usedAt time.Time) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	res, ok := m.nonces[nonce]
+	if !ok || res.UsedAt != nil {
+		return domain.ErrUserQRUsed
+	}
+	res.UsedAt = &usedAt
+	return nil
+}
+
+type mockUserRepo struct {
+	domain.UserRepository
+}
+
+func (m *mockUserRepo) FindOne(ctx context.Context, id string) (*domain.User, error) {
+	userID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, err
+	}
+	return &domain.User{ID: userID, Name: "Budi"}, nil
+}
+
+func TestVerify(t *testing.T) {
+	userID := primitive.NewObjectID().Hex()
+
+	tests := []struct {
+		name    string
+		ttl     time.Duration
+		payload func(qu *userQRUsecase, payload string) string
+		wantErr error
+	}{
+		{name: "valid", ttl: time.Minute, payload: func(qu *userQRUsecase, payload string) string { return " " + payload + " " }},
+		{name: "expired", ttl: -time.Second, wantErr: domain.ErrUserQRExpired},
+		{
+			name: "pickup qr",
+			ttl:  time.Minute,
+			payload: func(qu *userQRUsecase, payload string) string {
+				return domain.PickupQRPrefix + strings.TrimPrefix(payload, domain.UserQRPrefix)
+			},
+			wantErr: domain.ErrUserQRInvalid,
+		},
+		{
+			name:    "tampered signature",
+			ttl:     time.Minute,
+			payload: func(qu *userQRUsecase, payload string) string { return payload + "x" },
+			wantErr: domain.ErrUserQRInvalid,
+		},
+		{
+			name: "signed with another secret",
+			ttl:  time.Minute,
+			payload: func(qu *userQRUsecase, payload string) string {
+				other := NewUserQRUsecase(qu.UserQRRepo, qu.UserRepo, "secret-lain", time.Minute, time.Second).(*userQRUsecase)
+				res, _ := other.Generate(context.Background(), userID)
+				return res.Payload
+			},
+			wantErr: domain.ErrUserQRInvalid,
+		},
+		{
+			name: "nonce issued to another user",
+			ttl:  time.Minute,
+			payload: func(qu *userQRUsecase, payload string) string {
+				claims, _ := qu.verify(context.Background(), payload)
+				forged, _ := qu.sign(&domain.UserQRClaims{UserID: primitive.NewObjectID().Hex(), ExpiresAt: claims.ExpiresAt, Nonce: claims.Nonce})
+				return forged
+			},
+			wantErr: domain.ErrUserQRInvalid,
+		},
+		{
+			name: "nonce never issued",
+			ttl:  time.Minute,
+			payload: func(qu *userQRUsecase, payload string) string {
+				forged, _ := qu.sign(&domain.UserQRClaims{UserID: userID, ExpiresAt: time.Now().Add(time.Minute).Unix(), Nonce: "palsu"})
+				return forged
+			},
+			wantErr: domain.ErrUserQRInvalid,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			qu := NewUserQRUsecase(&mockUserQRRepo{nonces: map[string]*domain.UserQRNonce{}}, &mockUserRepo{}, "secret-qr", tt.ttl, time.Second).(*userQRUsecase)
+
+			res, err := qu.Generate(context.Background(), userID)
+			if err != nil {
+				t.Fatalf("generate err = %v", err)
+			}
+
+			payload := res.Payload
+			if tt.payload != nil {
+				payload = tt.payload(qu, payload)
+			}
+
+			user, err := qu.Verify(context.Background(), payload)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr == nil && user.ID.Hex() != userID {
+				t.Fatalf("user = %s, want %s", user.ID.Hex(), userID)
+			}
+		})
+	}
+}
+
+func TestConsumeOnce(t *testing.T) {
+	qu := NewUserQRUsecase(&mockUserQRRepo{nonces: map[string]*domain.UserQRNonce{}}, &mockUserRepo{}, "secret-qr", time.Minute, time.Second)
+
+	res, err := qu.Generate(context.Background(), primitive.NewObjectID().Hex())
+	if err != nil {
+		t.Fatalf("generate err = %v", err)
+	}
+
+	// dua kasir memindai QR yang sama bersamaan, hanya satu pembayaran yang boleh lolos
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	consumed := 0
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := qu.Consume(context.Background(), res.Payload); err == nil {
+				mu.Lock()
+				consumed++
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	if consumed != 1 {
+		t.Fatalf("consumed %d times, want 1", consumed)
+	}
+
+	if _, err := qu.Verify(context.Background(), res.Payload); !errors.Is(err, domain.ErrUserQRUsed) {
+		t.Fatalf("verify after consume err = %v, want %v", err, domain.ErrUserQRUsed)
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"fmt"
 	"html/template"
+	"io"
 	"log"
 	"os"
 	"path/filepath"
@@ -20,6 +21,10 @@ type EmailData struct {
 	Code      int
 	FirstName string
 	Subject   string
+	Template  string      // nama file di folder templates, default verificationCode.html
+	Data      interface{} // data tambahan untuk template selain verifikasi
+	// Attachments dilampirkan ke email dengan key sebagai nama file
+	Attachments map[string][]byte
 }
 
 func ParseTemplateDir(dir string) (*template.Template, error) {
@@ -71,7 +76,16 @@ func SendEmail(user *domain.User, data *EmailData) {
 		log.Fatal("Could not parse template", err)
 	}
 
-	template.ExecuteTemplate(&body, "verificationCode.html", &data)
+	templateName := data.Template
+	if templateName == "" {
+		templateName = "verificationCode.html"
+	}
+
+	err = template.ExecuteTemplate(&body, templateName, &data)
+	if err != nil {
+		log.Println("Could not execute template: ", err.Error())
+		return
+	}
 
 	mailer := gomail.NewMessage()
 	mailer.SetAddressHeader("From", from, fromName)
@@ -79,6 +93,13 @@ func SendEmail(user *domain.User, data *EmailData) {
 	mailer.SetHeader("Subject", data.Subject)
 	mailer.SetBody("text/html", body.String())
 	mailer.AddAlternative("text/plain", html2text.HTML2Text(body.String()))
+	for name, content := range data.Attachments {
+		content := content
+		mailer.Attach(name, gomail.SetCopyFunc(func(w io.Writer) error {
+			_, err := w.Write(content)
+			return err
+		}))
+	}
 
 	dialer := gomail.NewDialer(
 		smtpHost,
@@ -96,7 +117,8 @@ func SendEmail(user *domain.User, data *EmailData) {
 
 	err = dialer.DialAndSend(mailer)
 	if err != nil {
-		log.Fatal("Could not send email: ", err.Error())
+		log.Println("Could not send email: ", err.Error())
+		return
 	}
 
 	log.Println("Mail sent!")
@@ -3,28 +3,39 @@ package usecase
 import (
 	"context"
 	"errors"
+	"fmt"
+	"log"
 	"time"
+	"warunk-bem/cache"
 	"warunk-bem/domain"
+	"warunk-bem/dtos"
 
-	"github.com/go-redis/redis/v8"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
 type WarunkUsecase struct {
-	WarunkRepo     domain.WarunkRepository
-	ProdukRepo     domain.ProdukRepository
-	UserRepo       domain.UserRepository
-	RedisClient    *redis.Client
-	contextTimeout time.Duration
+	WarunkRepo        domain.WarunkRepository
+	ProdukRepo        domain.ProdukRepository
+	UserRepo          domain.UserRepository
+	StokRepo          domain.StokRepository
+	PickupUsecase     domain.PickupUsecase
+	PreOrderUsecase   domain.PreOrderUsecase
+	NotifikasiUsecase domain.NotifikasiUsecase
+	Cache             domain.Cache
+	contextTimeout    time.Duration
 }
 
-func NewWarunkUsecase(WarunkRepo domain.WarunkRepository, ProdukRepo domain.ProdukRepository, UserRepo domain.UserRepository, RedisClient *redis.Client, contextTimeout time.Duration) domain.WarunkUsecase {
+func NewWarunkUsecase(WarunkRepo domain.WarunkRepository, ProdukRepo domain.ProdukRepository, UserRepo domain.UserRepository, StokRepo domain.StokRepository, PickupUsecase domain.PickupUsecase, PreOrderUsecase domain.PreOrderUsecase, NotifikasiUsecase domain.NotifikasiUsecase, Cache domain.Cache, contextTimeout time.Duration) domain.WarunkUsecase {
 	return &WarunkUsecase{
-		WarunkRepo:     WarunkRepo,
-		ProdukRepo:     ProdukRepo,
-		UserRepo:       UserRepo,
-		RedisClient:    RedisClient,
-		contextTimeout: contextTimeout,
+		WarunkRepo:        WarunkRepo,
+		ProdukRepo:        ProdukRepo,
+		UserRepo:          UserRepo,
+		StokRepo:          StokRepo,
+		PickupUsecase:     PickupUsecase,
+		PreOrderUsecase:   PreOrderUsecase,
+		NotifikasiUsecase: NotifikasiUsecase,
+		Cache:             Cache,
+		contextTimeout:    contextTimeout,
 	}
 }
 
@@ -49,9 +60,25 @@ func (fu *WarunkUsecase) InsertOne(ctx context.Context, req *domain.InsertWarunk
 			return nil, errors.New("user not found")
 		}
 
+		req.ID = primitive.NewObjectID()
+		req.CreatedAt = time.Now()
+		req.UpdatedAt = time.Now()
+
+		// Cek duplikat sebelum stok produk ditimpa
+		tanggalBuat := req.CreatedAt
+		FormatTanggalBuat := tanggalBuat.Format("2006-01-02")
+		if FormatTanggalBuat == formattedTanggal && req.Status == StatusPembuatan {
+			return nil, errors.New("warunk already open")
+		}
+
 		// Create an array of domain.Produk from the req.Produk
 		produks := make([]domain.Produk, 0, len(req.Produk))
+		originals := make([]*domain.Produk, 0, len(req.Produk))
 		for _, v := range req.Produk {
+			if v.Stock < 0 {
+				return nil, errors.New("stok produk tidak boleh negatif")
+			}
+
 			produk, err := fu.ProdukRepo.FindOne(ctx, v.ID.Hex())
 			if err != nil {
 				return nil, errors.New("produk not found")
@@ -70,41 +97,35 @@ func (fu *WarunkUsecase) InsertOne(ctx context.Context, req *domain.InsertWarunk
 			}
 
 			produks = append(produks, newProduk)
-
-			if req.Status == "Buka" {
-				if v.ID == produk.ID {
-					produk.Stock = v.Stock
-					_, err := fu.ProdukRepo.UpdateOne(ctx, produk, produk.ID.Hex())
-					if err != nil {
-						return nil, errors.New("cannot update stock produk")
-					}
-				}
-			}
+			originals = append(originals, produk)
 		}
 
-		req.ID = primitive.NewObjectID()
-		req.CreatedAt = time.Now()
-		req.UpdatedAt = time.Now()
-
-		tanggalBuat := req.CreatedAt
-		FormatTanggalBuat := tanggalBuat.Format("2006-01-02")
-		if FormatTanggalBuat == formattedTanggal && req.Status == StatusPembuatan {
-			return nil, errors.New("warunk already open")
+		// Stok baru ditimpa setelah semua produk ditemukan
+		var opened []openedStock
+		if req.Status == "Buka" {
+			opened, err = fu.openStocks(ctx, originals, produks)
+			if err != nil {
+				return nil, err
+			}
 		}
 
-		_, err = fu.WarunkRepo.InsertOne(ctx, &domain.Warunk{
+		warunk := &domain.Warunk{
 			ID:        req.ID,
 			CreatedAt: req.CreatedAt,
 			UpdatedAt: req.UpdatedAt,
 			UserID:    user.ID,
 			Produk:    produks,
 			Status:    req.Status,
-		})
+		}
+		_, err = fu.WarunkRepo.InsertOne(ctx, warunk)
 
 		if err != nil {
+			fu.revertStocks(ctx, opened)
 			return nil, errors.New("cannot add produk to Warunk")
 		}
 
+		fu.recordOpenedStocks(ctx, opened, user.ID, req.ID)
+
 		res = &domain.InsertWarunkResponse{
 			ID:     req.ID.Hex(),
 			UserID: req.UserID,
@@ -112,6 +133,16 @@ func (fu *WarunkUsecase) InsertOne(ctx context.Context, req *domain.InsertWarunk
 			Status: req.Status,
 		}
 
+		if req.Status == "Buka" {
+			res.PreOrder = fu.fulfillPreOrders(warunk, user.ID.Hex())
+		}
+
+		if req.Status == "Tutup" {
+			fu.refundPickups(user.ID.Hex())
+		}
+
+		cache.InvalidateProduk(ctx, fu.Cache)
+
 		return res, nil
 	} else {
 		user, err := fu.UserRepo.FindOne(ctx, req.UserID)
@@ -119,9 +150,18 @@ func (fu *WarunkUsecase) InsertOne(ctx context.Context, req *domain.InsertWarunk
 			return nil, errors.New("user not found")
 		}
 
+		req.ID = primitive.NewObjectID()
+		req.CreatedAt = time.Now()
+		req.UpdatedAt = time.Now()
+
 		// Create an array of domain.Produk from the req.Produk
 		produks := make([]domain.Produk, 0, len(req.Produk))
+		originals := make([]*domain.Produk, 0, len(req.Produk))
 		for _, v := range req.Produk {
+			if v.Stock < 0 {
+				return nil, errors.New("stok produk tidak boleh negatif")
+			}
+
 			produk, err := fu.ProdukRepo.FindOne(ctx, v.ID.Hex())
 			if err != nil {
 				return nil, errors.New("produk not found")
@@ -140,34 +180,35 @@ func (fu *WarunkUsecase) InsertOne(ctx context.Context, req *domain.InsertWarunk
 			}
 
 			produks = append(produks, newProduk)
+			originals = append(originals, produk)
+		}
 
-			if req.Status == "Buka" {
-				if v.ID == produk.ID {
-					produk.Stock = v.Stock
-					_, err := fu.ProdukRepo.UpdateOne(ctx, produk, produk.ID.Hex())
-					if err != nil {
-						return nil, errors.New("cannot update stock produk")
-					}
-				}
+		// Stok baru ditimpa setelah semua produk ditemukan
+		var opened []openedStock
+		if req.Status == "Buka" {
+			opened, err = fu.openStocks(ctx, originals, produks)
+			if err != nil {
+				return nil, err
 			}
 		}
 
-		req.ID = primitive.NewObjectID()
-		req.CreatedAt = time.Now()
-		req.UpdatedAt = time.Now()
-		_, err = fu.WarunkRepo.InsertOne(ctx, &domain.Warunk{
+		warunk := &domain.Warunk{
 			ID:        req.ID,
 			CreatedAt: req.CreatedAt,
 			UpdatedAt: req.UpdatedAt,
 			UserID:    user.ID,
 			Produk:    produks,
 			Status:    req.Status,
-		})
+		}
+		_, err = fu.WarunkRepo.InsertOne(ctx, warunk)
 
 		if err != nil {
+			fu.revertStocks(ctx, opened)
 			return nil, errors.New("cannot add produk to Warunk")
 		}
 
+		fu.recordOpenedStocks(ctx, opened, user.ID, req.ID)
+
 		res = &domain.InsertWarunkResponse{
 			ID:     req.ID.Hex(),
 			UserID: req.UserID,
@@ -175,6 +216,16 @@ func (fu *WarunkUsecase) InsertOne(ctx context.Context, req *domain.InsertWarunk
 			Status: req.Status,
 		}
 
+		if req.Status == "Buka" {
+			res.PreOrder = fu.fulfillPreOrders(warunk, user.ID.Hex())
+		}
+
+		if req.Status == "Tutup" {
+			fu.refundPickups(user.ID.Hex())
+		}
+
+		cache.InvalidateProduk(ctx, fu.Cache)
+
 		return res, nil
 	}
 }
@@ -222,3 +273,121 @@ func (fu *WarunkUsecase) UpdateOne(ctx context.Context, id string, req *domain.W
 
 	return result, nil
 }
+
+// openedStock adalah stok produk sebelum dan sesudah ditimpa stok pembukaan warunk
+type openedStock struct {
+	before  domain.Produk
+	updated *domain.Produk
+}
+
+// openStockAttempts membatasi pembacaan ulang saat stok terus berubah oleh checkout yang berjalan bersamaan
+const openStockAttempts = 3
+
+// openStocks menimpa stok produk katalog dengan stok pembukaan, jika salah satu gagal stok yang sudah ditimpa dikembalikan
+func (fu *WarunkUsecase) openStocks(ctx context.Context, produks []*domain.Produk, catalog []domain.Produk) ([]openedStock, error) {
+	opened := make([]openedStock, 0, len(produks))
+	for i, produk := range produks {
+		before, updated, err := fu.openStock(ctx, produk, catalog[i].Stock)
+		if err != nil {
+			fu.revertStocks(ctx, opened)
+			return nil, err
+		}
+
+		opened = append(opened, openedStock{before: *before, updated: updated})
+	}
+
+	return opened, nil
+}
+
+// openStock menimpa stok dengan $set bersyarat pada stok yang terakhir dibaca,
+// stok dibaca ulang jika checkout atau restock mengubahnya di antara baca dan tulis
+func (fu *WarunkUsecase) openStock(ctx context.Context, produk *domain.Produk, stock int64) (*domain.Produk, *domain.Produk, error) {
+	for attempt := 0; attempt < openStockAttempts; attempt++ {
+		updated, err := fu.ProdukRepo.SetStock(ctx, produk.ID.Hex(), produk.Stock, stock)
+		if err == nil {
+			return produk, updated, nil
+		}
+
+		if !errors.Is(err, domain.ErrStockChanged) {
+			return nil, nil, errors.New("cannot update stock produk")
+		}
+
+		produk, err = fu.ProdukRepo.FindOne(ctx, produk.ID.Hex())
+		if err != nil {
+			return nil, nil, errors.New("produk not found")
+		}
+	}
+
+	return nil, nil, fmt.Errorf("stok produk '%s' terus berubah, silakan coba lagi", produk.Name)
+}
+
+// revertStocks mengembalikan stok sebagai selisih agar penjualan sesudah stok ditimpa tetap terhitung
+func (fu *WarunkUsecase) revertStocks(ctx context.Context, opened []openedStock) {
+	for _, o := range opened {
+		_, err := fu.ProdukRepo.IncrementStock(ctx, o.updated.ID.Hex(), o.before.Stock-o.updated.Stock)
+		if err != nil {
+			log.Println("cannot revert stok pembukaan: ", err.Error())
+		}
+	}
+}
+
+// recordOpenedStocks mencatat jurnal stok pembukaan dan mengirim notifikasi wishlist setelah warunk tersimpan
+func (fu *WarunkUsecase) recordOpenedStocks(ctx context.Context, opened []openedStock, actorID primitive.ObjectID, warunkID primitive.ObjectID) {
+	for _, o := range opened {
+		_, err := fu.StokRepo.InsertOne(ctx, domain.NewStokMovement(o.updated, domain.StokMovementOpening, o.before.Stock, "stok pembukaan warunk", actorID, warunkID))
+		if err != nil {
+			log.Println("cannot record stok movement: ", err.Error())
+		}
+
+		fu.notifyWishlist(o.before, *o.updated)
+	}
+}
+
+// fulfillPreOrders memenuhi pre-order dari stok pembukaan sebelum pembeli lain bisa checkout,
+// memakai context sendiri agar antrean pre-order tidak terpotong timeout request admin
+func (fu *WarunkUsecase) fulfillPreOrders(warunk *domain.Warunk, actorID string) *dtos.PreOrderFulfillResponse {
+	if fu.PreOrderUsecase == nil {
+		return nil
+	}
+
+	res, err := fu.PreOrderUsecase.FulfillPending(context.Background(), warunk, actorID)
+	if err != nil {
+		log.Println("cannot fulfill pre-order: ", err.Error())
+	}
+
+	return res
+}
+
+// refundPickups mengembalikan saldo pesanan yang belum diambil saat warunk tutup,
+// dijalankan di background karena jumlah tiket bisa banyak
+func (fu *WarunkUsecase) refundPickups(actorID string) {
+	if fu.PickupUsecase == nil {
+		return
+	}
+
+	go func() {
+		refunded, err := fu.PickupUsecase.RefundPending(context.Background(), actorID)
+		if err != nil {
+			log.Println("cannot refund pickup: ", err.Error())
+			return
+		}
+
+		if refunded > 0 {
+			log.Printf("refunded %d uncollected pickup\n", refunded)
+		}
+	}()
+}
+
+// notifyWishlist mengirim notifikasi wishlist di background agar request admin tidak menunggu email
+func (fu *WarunkUsecase) notifyWishlist(before domain.Produk, after domain.Produk) {
+	if fu.NotifikasiUsecase == nil {
+		return
+	}
+
+	go func() {
+		err := fu.NotifikasiUsecase.NotifyWishlist(context.Background(), &before, &after)
+		if err != nil {
+			log.Println("cannot send wishlist notification: ", err.Error())
+		}
+	}()
+}
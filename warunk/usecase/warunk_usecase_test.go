@@ -0,0 +1,255 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+	"warunk-bem/cache"
+	"warunk-bem/domain"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// mockProdukRepo meniru $set bersyarat SetStock, racingSales meniru checkout yang memotong stok
+// di antara pembacaan stok dan penulisan stok pembukaan, salesAfterOpen menghitung checkout sesudah stok ditimpa
+type mockProdukRepo struct {
+	domain.ProdukRepository
+
+	mu             sync.Mutex
+	produks        map[string]*domain.Produk
+	racingSales    map[string]int
+	opened         bool
+	salesAfterOpen int64
+}
+
+func (m *mockProdukRepo) FindOne(ctx context.Context, id string) (*domain.Produk, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	produk, ok := m.produks[id]
+	if !ok {
+		return nil, errors.New("not found")
+	}
+	copied := *produk
+	return &copied, nil
+}
+
+func (m *mockProdukRepo) SetStock(ctx context.Context, id string, fromStock int64, stock int64) (*domain.Produk, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	produk := m.produks[id]
+	if m.racingSales[id] > 0 {
+		m.racingSales[id]--
+		produk.Stock--
+	}
+	if produk.Stock != fromStock {
+		return nil, domain.ErrStockChanged
+	}
+	produk.Stock = stock
+	m.opened = true
+	copied := *produk
+	return &copied, nil
+}
+
+func (m *mockProdukRepo) IncrementStock(ctx context.Context, id string, delta int64) (*domain.Produk, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	produk := m.produks[id]
+	if produk.Stock+delta < 0 {
+		return nil, errors.New("stok tidak cukup")
+	}
+	produk.Stock += delta
+	if m.opened && delta < 0 {
+		m.salesAfterOpen -= delta
+	}
+	copied := *produk
+	return &copied, nil
+}
+
+type mockWarunkRepo struct {
+	domain.WarunkRepository
+
+	insertErr error
+	inserted  int
+}
+
+func (m *mockWarunkRepo) FindLatestByStatus(ctx context.Context, status string) (*domain.Warunk, error) {
+	return nil, nil
+}
+
+func (m *mockWarunkRepo) InsertOne(ctx context.Context, req *domain.Warunk) (*domain.Warunk, error) {
+	if m.insertErr != nil {
+		return nil, m.insertErr
+	}
+	m.inserted++
+	return req, nil
+}
+
+type mockUserRepo struct {
+	domain.UserRepository
+}
+
+func (m *mockUserRepo) FindOne(ctx context.Context, id string) (*domain.User, error) {
+	return &domain.User{ID: primitive.NewObjectID()}, nil
+}
+
+type mockStokRepo struct {
+	domain.StokRepository
+
+	movements []*domain.StokMovement
+}
+
+func (m *mockStokRepo) InsertOne(ctx context.Context, req *domain.StokMovement) (*domain.StokMovement, error) {
+	m.movements = append(m.movements, req)
+	return req, nil
+}
+
+func TestInsertOneOpenStock(t *testing.T) {
+	produkA := primitive.NewObjectID()
+	produkB := primitive.NewObjectID()
+
+	tests := []struct {
+		name          string
+		catalog       []domain.CatalogWarunk
+		racingSales   map[string]int
+		insertErr     error
+		wantErr       bool
+		wantStockA    int64
+		wantStockB    int64
+		wantWarunk    int
+		wantMovements int
+	}{
+		{
+			name:          "opening stock overwrites current stock",
+			catalog:       []domain.CatalogWarunk{{ID: produkA, Stock: 20}, {ID: produkB, Stock: 7}},
+			wantStockA:    20,
+			wantStockB:    7,
+			wantWarunk:    1,
+			wantMovements: 2,
+		},
+		{
+			name:          "checkout between read and write is re-read",
+			catalog:       []domain.CatalogWarunk{{ID: produkA, Stock: 20}, {ID: produkB, Stock: 7}},
+			racingSales:   map[string]int{produkA.Hex(): 2},
+			wantStockA:    20,
+			wantStockB:    7,
+			wantWarunk:    1,
+			wantMovements: 2,
+		},
+		{
+			name:        "stock that keeps changing reverts earlier products",
+			catalog:     []domain.CatalogWarunk{{ID: produkA, Stock: 20}, {ID: produkB, Stock: 7}},
+			racingSales: map[string]int{produkB.Hex(): openStockAttempts},
+			wantErr:     true,
+			wantStockA:  10,
+			wantStockB:  5 - openStockAttempts,
+		},
+		{
+			name:       "unknown produk changes nothing",
+			catalog:    []domain.CatalogWarunk{{ID: produkA, Stock: 20}, {ID: primitive.NewObjectID(), Stock: 7}},
+			wantErr:    true,
+			wantStockA: 10,
+			wantStockB: 5,
+		},
+		{
+			name:       "negative opening stock",
+			catalog:    []domain.CatalogWarunk{{ID: produkA, Stock: 20}, {ID: produkB, Stock: -1}},
+			wantErr:    true,
+			wantStockA: 10,
+			wantStockB: 5,
+		},
+		{
+			name:       "warunk insert failure reverts stock",
+			catalog:    []domain.CatalogWarunk{{ID: produkA, Stock: 20}, {ID: produkB, Stock: 7}},
+			insertErr:  errors.New("mongo down"),
+			wantErr:    true,
+			wantStockA: 10,
+			wantStockB: 5,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			produkRepo := &mockProdukRepo{
+				produks: map[string]*domain.Produk{
+					produkA.Hex(): {ID: produkA, Name: "Teh", Stock: 10},
+					produkB.Hex(): {ID: produkB, Name: "Roti", Stock: 5},
+				},
+				racingSales: tt.racingSales,
+			}
+			warunkRepo := &mockWarunkRepo{insertErr: tt.insertErr}
+			stokRepo := &mockStokRepo{}
+			wu := NewWarunkUsecase(warunkRepo, produkRepo, &mockUserRepo{}, stokRepo, nil, nil, nil, cache.NewMemoryCache(), time.Second)
+
+			_, err := wu.InsertOne(context.Background(), &domain.InsertWarunkRequest{UserID: primitive.NewObjectID().Hex(), Produk: tt.catalog, Status: "Buka"})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if got := produkRepo.produks[produkA.Hex()].Stock; got != tt.wantStockA {
+				t.Errorf("stock A = %d, want %d", got, tt.wantStockA)
+			}
+			if got := produkRepo.produks[produkB.Hex()].Stock; got != tt.wantStockB {
+				t.Errorf("stock B = %d, want %d", got, tt.wantStockB)
+			}
+			if warunkRepo.inserted != tt.wantWarunk {
+				t.Errorf("warunk inserted = %d, want %d", warunkRepo.inserted, tt.wantWarunk)
+			}
+			if len(stokRepo.movements) != tt.wantMovements {
+				t.Errorf("stok movements = %d, want %d", len(stokRepo.movements), tt.wantMovements)
+			}
+		})
+	}
+}
+
+func TestInsertOneOpenStockConcurrentCheckout(t *testing.T) {
+	const sales = 50
+
+	for i := 0; i < 20; i++ {
+		produkID := primitive.NewObjectID()
+		produkRepo := &mockProdukRepo{
+			produks: map[string]*domain.Produk{produkID.Hex(): {ID: produkID, Name: "Teh", Stock: sales}},
+		}
+		warunkRepo := &mockWarunkRepo{}
+		wu := NewWarunkUsecase(warunkRepo, produkRepo, &mockUserRepo{}, &mockStokRepo{}, nil, nil, nil, cache.NewMemoryCache(), time.Second)
+
+		var wg sync.WaitGroup
+		var sold int64
+		var soldMu sync.Mutex
+		for j := 0; j < sales; j++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				if _, err := produkRepo.IncrementStock(context.Background(), produkID.Hex(), -1); err == nil {
+					soldMu.Lock()
+					sold++
+					soldMu.Unlock()
+				}
+			}()
+		}
+
+		_, err := wu.InsertOne(context.Background(), &domain.InsertWarunkRequest{UserID: primitive.NewObjectID().Hex(), Produk: []domain.CatalogWarunk{{ID: produkID, Stock: 100}}, Status: "Buka"})
+		wg.Wait()
+
+		got := produkRepo.produks[produkID.Hex()].Stock
+		if err != nil {
+			// stok pembukaan batal ditulis, stok hanya berkurang oleh checkout
+			if want := sales - sold; got != want {
+				t.Fatalf("stock = %d, want %d after failed open", got, want)
+			}
+			if warunkRepo.inserted != 0 {
+				t.Fatalf("warunk inserted after failed open")
+			}
+			continue
+		}
+
+		// checkout sebelum stok ditimpa hilang bersama stok lama, checkout sesudahnya tetap terhitung
+		if want := 100 - produkRepo.salesAfterOpen; got != want {
+			t.Fatalf("stock = %d, want %d (%d sales after open)", got, want, produkRepo.salesAfterOpen)
+		}
+		if warunkRepo.inserted != 1 {
+			t.Fatalf("warunk inserted = %d, want 1", warunkRepo.inserted)
+		}
+	}
+}
@@ -0,0 +1,401 @@
+package http
+
+import (
+	"errors"
+	"io"
+	"math"
+	"net/http"
+	"warunk-bem/domain"
+	"warunk-bem/dtos"
+	"warunk-bem/helpers"
+	"warunk-bem/middlewares"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+type WithdrawalHandler struct {
+	WithdrawalUsecase domain.WithdrawalUsecase
+}
+
+func NewWithdrawalHandler(protected *gin.RouterGroup, protectedAdmin *gin.RouterGroup, wu domain.WithdrawalUsecase) {
+	handler := &WithdrawalHandler{
+		WithdrawalUsecase: wu,
+	}
+
+	protected = protected.Group("/withdrawal")
+	protectedAdmin = protectedAdmin.Group("/withdrawal")
+
+	protected.POST("", handler.Request)
+	protected.GET("/me", handler.GetMine)
+	protected.PUT("/:id/cancel", handler.Cancel)
+
+	protectedAdmin.GET("", handler.GetAll)
+	protectedAdmin.PUT("/:id/approve", handler.Approve)
+	protectedAdmin.PUT("/:id/reject", handler.Reject)
+	protectedAdmin.PUT("/:id/paid", handler.MarkPaid)
+	protectedAdmin.GET("/:id/ledger", handler.GetLedger)
+}
+
+func isRequestValid(m interface{}) (bool, error) {
+	validate := validator.New()
+	err := validate.Struct(m)
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func unauthorized(c *gin.Context, err error) {
+	c.JSON(
+		http.StatusUnauthorized,
+		dtos.NewErrorResponse(
+			http.StatusUnauthorized,
+			"Please login first to access this pages",
+			dtos.GetErrorData(err),
+		),
+	)
+}
+
+func forbidden(c *gin.Context, err error) {
+	c.JSON(
+		http.StatusForbidden,
+		dtos.NewErrorResponse(
+			http.StatusForbidden,
+			"Only admin can access this pages",
+			dtos.GetErrorData(err),
+		),
+	)
+}
+
+func withdrawalPage(res []*dtos.WithdrawalResponse, count int64, rp int64, page int64) dtos.GetAllWithdrawalResponse {
+	return dtos.GetAllWithdrawalResponse{
+		Total:       count,
+		PerPage:     rp,
+		CurrentPage: page,
+		LastPage:    int64(math.Ceil(float64(count) / float64(rp))),
+		From:        (page * rp) - rp + 1,
+		To:          page * rp,
+		Withdrawal:  res,
+	}
+}
+
+func (wh *WithdrawalHandler) Request(c *gin.Context) {
+	var req dtos.WithdrawalRequest
+
+	idUser, err := middlewares.IsUser(c)
+	if err != nil {
+		unauthorized(c, err)
+		return
+	}
+
+	err = c.ShouldBindJSON(&req)
+	if err != nil {
+		c.JSON(
+			http.StatusUnprocessableEntity,
+			dtos.NewErrorResponse(
+				http.StatusUnprocessableEntity,
+				"Filed Cannot Be Empty",
+				dtos.GetErrorData(err),
+			),
+		)
+		return
+	}
+
+	if ok, err := isRequestValid(&req); !ok {
+		c.JSON(
+			http.StatusBadRequest,
+			dtos.NewErrorResponse(
+				http.StatusBadRequest,
+				"Bad Request",
+				dtos.GetErrorData(err),
+			),
+		)
+		return
+	}
+
+	result, err := wh.WithdrawalUsecase.Request(helpers.RequestContext(c), idUser, &req)
+	if err != nil {
+		c.JSON(
+			http.StatusBadRequest,
+			dtos.NewErrorResponse(
+				http.StatusBadRequest,
+				"Cannot Request Withdrawal",
+				err.Error(),
+			),
+		)
+		return
+	}
+
+	c.JSON(
+		http.StatusCreated,
+		dtos.NewResponse(
+			http.StatusCreated,
+			"Pengajuan Penarikan Saldo Berhasil",
+			result,
+		),
+	)
+}
+
+func (wh *WithdrawalHandler) GetMine(c *gin.Context) {
+	idUser, err := middlewares.IsUser(c)
+	if err != nil {
+		unauthorized(c, err)
+		return
+	}
+
+	rp, page := helpers.Pagination(c)
+
+	res, count, err := wh.WithdrawalUsecase.GetMine(helpers.RequestContext(c), idUser, rp, page)
+	if err != nil {
+		c.JSON(
+			http.StatusBadRequest,
+			dtos.NewErrorResponse(
+				http.StatusBadRequest,
+				"Cannot Get Withdrawal",
+				err.Error(),
+			),
+		)
+		return
+	}
+
+	c.JSON(
+		http.StatusOK,
+		dtos.NewResponse(
+			http.StatusOK,
+			"Success Get Withdrawal",
+			withdrawalPage(res, count, rp, page),
+		),
+	)
+}
+
+func (wh *WithdrawalHandler) Cancel(c *gin.Context) {
+	idUser, err := middlewares.IsUser(c)
+	if err != nil {
+		unauthorized(c, err)
+		return
+	}
+
+	result, err := wh.WithdrawalUsecase.Cancel(helpers.RequestContext(c), c.Param("id"), idUser)
+	if err != nil {
+		c.JSON(
+			http.StatusBadRequest,
+			dtos.NewErrorResponse(
+				http.StatusBadRequest,
+				"Cannot Cancel Withdrawal",
+				err.Error(),
+			),
+		)
+		return
+	}
+
+	c.JSON(
+		http.StatusOK,
+		dtos.NewResponse(
+			http.StatusOK,
+			"Success Cancel Withdrawal",
+			result,
+		),
+	)
+}
+
+func (wh *WithdrawalHandler) GetAll(c *gin.Context) {
+	rp, page := helpers.Pagination(c)
+
+	status := c.Query("status")
+	if status == "" {
+		status = domain.WithdrawalStatusPending
+	}
+
+	res, count, err := wh.WithdrawalUsecase.GetAll(helpers.RequestContext(c), rp, page, bson.M{"status": status})
+	if err != nil {
+		c.JSON(
+			http.StatusBadRequest,
+			dtos.NewErrorResponse(
+				http.StatusBadRequest,
+				"Cannot Get Withdrawal",
+				err.Error(),
+			),
+		)
+		return
+	}
+
+	c.JSON(
+		http.StatusOK,
+		dtos.NewResponse(
+			http.StatusOK,
+			"Success Get Withdrawal",
+			withdrawalPage(res, count, rp, page),
+		),
+	)
+}
+
+func (wh *WithdrawalHandler) Approve(c *gin.Context) {
+	idAdmin, err := middlewares.IsAdmin(c)
+	if err != nil {
+		forbidden(c, err)
+		return
+	}
+
+	result, err := wh.WithdrawalUsecase.Approve(helpers.RequestContext(c), c.Param("id"), idAdmin)
+	if err != nil {
+		c.JSON(
+			http.StatusBadRequest,
+			dtos.NewErrorResponse(
+				http.StatusBadRequest,
+				"Cannot Approve Withdrawal",
+				err.Error(),
+			),
+		)
+		return
+	}
+
+	c.JSON(
+		http.StatusOK,
+		dtos.NewResponse(
+			http.StatusOK,
+			"Success Approve Withdrawal",
+			result,
+		),
+	)
+}
+
+func (wh *WithdrawalHandler) Reject(c *gin.Context) {
+	var req dtos.RejectWithdrawalRequest
+
+	idAdmin, err := middlewares.IsAdmin(c)
+	if err != nil {
+		forbidden(c, err)
+		return
+	}
+
+	err = c.ShouldBindJSON(&req)
+	if err != nil {
+		c.JSON(
+			http.StatusUnprocessableEntity,
+			dtos.NewErrorResponse(
+				http.StatusUnprocessableEntity,
+				"Filed Cannot Be Empty",
+				dtos.GetErrorData(err),
+			),
+		)
+		return
+	}
+
+	if ok, err := isRequestValid(&req); !ok {
+		c.JSON(
+			http.StatusBadRequest,
+			dtos.NewErrorResponse(
+				http.StatusBadRequest,
+				"Bad Request",
+				dtos.GetErrorData(err),
+			),
+		)
+		return
+	}
+
+	result, err := wh.WithdrawalUsecase.Reject(helpers.RequestContext(c), c.Param("id"), idAdmin, &req)
+	if err != nil {
+		c.JSON(
+			http.StatusBadRequest,
+			dtos.NewErrorResponse(
+				http.StatusBadRequest,
+				"Cannot Reject Withdrawal",
+				err.Error(),
+			),
+		)
+		return
+	}
+
+	c.JSON(
+		http.StatusOK,
+		dtos.NewResponse(
+			http.StatusOK,
+			"Success Reject Withdrawal",
+			result,
+		),
+	)
+}
+
+func (wh *WithdrawalHandler) MarkPaid(c *gin.Context) {
+	var req dtos.PayoutWithdrawalRequest
+
+	idAdmin, err := middlewares.IsAdmin(c)
+	if err != nil {
+		forbidden(c, err)
+		return
+	}
+
+	// Body boleh kosong, payout_ref hanya diisi untuk pencairan lewat transfer
+	err = c.ShouldBindJSON(&req)
+	if err != nil && !errors.Is(err, io.EOF) {
+		c.JSON(
+			http.StatusUnprocessableEntity,
+			dtos.NewErrorResponse(
+				http.StatusUnprocessableEntity,
+				"Filed Cannot Be Empty",
+				dtos.GetErrorData(err),
+			),
+		)
+		return
+	}
+
+	if ok, err := isRequestValid(&req); !ok {
+		c.JSON(
+			http.StatusBadRequest,
+			dtos.NewErrorResponse(
+				http.StatusBadRequest,
+				"Bad Request",
+				dtos.GetErrorData(err),
+			),
+		)
+		return
+	}
+
+	result, err := wh.WithdrawalUsecase.MarkPaid(helpers.RequestContext(c), c.Param("id"), idAdmin, &req)
+	if err != nil {
+		c.JSON(
+			http.StatusBadRequest,
+			dtos.NewErrorResponse(
+				http.StatusBadRequest,
+				"Cannot Confirm Withdrawal Payout",
+				err.Error(),
+			),
+		)
+		return
+	}
+
+	c.JSON(
+		http.StatusOK,
+		dtos.NewResponse(
+			http.StatusOK,
+			"Success Confirm Withdrawal Payout",
+			result,
+		),
+	)
+}
+
+func (wh *WithdrawalHandler) GetLedger(c *gin.Context) {
+	res, err := wh.WithdrawalUsecase.GetLedger(helpers.RequestContext(c), c.Param("id"))
+	if err != nil {
+		c.JSON(
+			http.StatusNotFound,
+			dtos.NewErrorResponse(
+				http.StatusNotFound,
+				"Cannot Get Withdrawal Ledger",
+				err.Error(),
+			),
+		)
+		return
+	}
+
+	c.JSON(
+		http.StatusOK,
+		dtos.NewResponse(
+			http.StatusOK,
+			"Success Get Withdrawal Ledger",
+			res,
+		),
+	)
+}
@@ -0,0 +1,206 @@
+package repository
+
+import (
+	"context"
+	"time"
+	"warunk-bem/domain"
+	"warunk-bem/mongo"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	mongodriver "go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type withdrawalRepository struct {
+	DB         mongo.Database
+	Collection mongo.Collection
+}
+
+const (
+	timeFormat           = "2006-01-02T15:04:05.999Z07:00" // reduce precision from RFC3339Nano as date format
+	collectionName       = "withdrawal"
+	ledgerCollectionName = "withdrawal_ledger"
+)
+
+func NewWithdrawalRepository(DB mongo.Database) domain.WithdrawalRepository {
+	return &withdrawalRepository{DB, DB.Collection(collectionName)}
+}
+
+func (r *withdrawalRepository) EnsureIndexes(ctx context.Context) error {
+	_, err := r.Collection.CreateIndexes(ctx, []mongodriver.IndexModel{
+		{
+			Keys: bson.D{{Key: "status", Value: 1}, {Key: "created_at", Value: 1}},
+		},
+		{
+			Keys: bson.D{{Key: "status", Value: 1}, {Key: "expires_at", Value: 1}},
+		},
+		{
+			Keys: bson.D{{Key: "user_id", Value: 1}, {Key: "created_at", Value: -1}},
+		},
+		{
+			// Satu pengajuan terbuka per user, pengajuan kedua yang lolos pengecekan bersamaan ditolak di sini
+			Keys:    bson.D{{Key: "user_id", Value: 1}},
+			Options: options.Index().SetUnique(true).SetPartialFilterExpression(bson.M{"open": true}),
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = r.DB.Collection(ledgerCollectionName).CreateIndexes(ctx, []mongodriver.IndexModel{
+		{
+			Keys: bson.D{{Key: "withdrawal_id", Value: 1}, {Key: "created_at", Value: 1}},
+		},
+	})
+	return err
+}
+
+// isOpen menentukan pengajuan yang masih menahan saldo dan menghalangi pengajuan baru
+func isOpen(status string) bool {
+	return status == domain.WithdrawalStatusPending || status == domain.WithdrawalStatusApproved
+}
+
+func (r *withdrawalRepository) InsertOne(ctx context.Context, req *domain.Withdrawal) (*domain.Withdrawal, error) {
+	req.Open = isOpen(req.Status)
+
+	_, err := r.Collection.InsertOne(ctx, req)
+	if mongodriver.IsDuplicateKeyError(err) {
+		return nil, domain.ErrWithdrawalOpenExists
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return req, nil
+}
+
+func (r *withdrawalRepository) FindOne(ctx context.Context, id string) (*domain.Withdrawal, error) {
+	var withdrawal domain.Withdrawal
+
+	idHex, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, err
+	}
+
+	err = r.Collection.FindOne(ctx, bson.M{"_id": idHex}).Decode(&withdrawal)
+	if err != nil {
+		return nil, err
+	}
+
+	return &withdrawal, nil
+}
+
+func (r *withdrawalRepository) CountOpenByUser(ctx context.Context, userID primitive.ObjectID) (int64, error) {
+	return r.Collection.CountDocuments(ctx, bson.M{
+		"user_id": userID,
+		"status":  bson.M{"$in": []string{domain.WithdrawalStatusPending, domain.WithdrawalStatusApproved}},
+	})
+}
+
+// FindExpired hanya mengambil pengajuan pending, pengajuan approved tetap ditahan
+// karena uangnya mungkin sudah diserahkan bendahara
+func (r *withdrawalRepository) FindExpired(ctx context.Context, now time.Time) ([]domain.Withdrawal, error) {
+	var withdrawals []domain.Withdrawal
+
+	filter := bson.M{
+		"status":     domain.WithdrawalStatusPending,
+		"expires_at": bson.M{"$lte": now},
+	}
+
+	cursor, err := r.Collection.Find(ctx, filter, options.Find().SetSort(bson.M{"created_at": 1}))
+	if err != nil {
+		return withdrawals, err
+	}
+
+	err = cursor.All(ctx, &withdrawals)
+	if err != nil {
+		return withdrawals, err
+	}
+
+	return withdrawals, nil
+}
+
+func (r *withdrawalRepository) GetAllWithPage(ctx context.Context, rp int64, p int64, filter interface{}, setsort interface{}) ([]domain.Withdrawal, int64, error) {
+	var (
+		withdrawal []domain.Withdrawal
+		err        error
+	)
+
+	findOptions := options.Find()
+	findOptions.SetLimit(rp)
+	findOptions.SetSkip((p - 1) * rp)
+	if setsort != nil {
+		findOptions.SetSort(setsort)
+	}
+
+	cursor, err := r.Collection.Find(ctx, filter, findOptions)
+	if err != nil {
+		return withdrawal, 0, err
+	}
+
+	err = cursor.All(ctx, &withdrawal)
+	if err != nil {
+		return withdrawal, 0, err
+	}
+
+	total, err := r.Collection.CountDocuments(ctx, filter)
+	if err != nil {
+		return withdrawal, 0, err
+	}
+
+	return withdrawal, total, nil
+}
+
+// UpdateStatus hanya berhasil jika status di database masih fromStatus,
+// sehingga saldo tidak bisa dicairkan dan dikembalikan sekaligus
+func (r *withdrawalRepository) UpdateStatus(ctx context.Context, withdrawal *domain.Withdrawal, fromStatus string) error {
+	withdrawal.UpdatedAt = time.Now()
+	withdrawal.Open = isOpen(withdrawal.Status)
+
+	update := bson.M{"$set": bson.M{
+		"updated_at":    withdrawal.UpdatedAt,
+		"status":        withdrawal.Status,
+		"open":          withdrawal.Open,
+		"expires_at":    withdrawal.ExpiresAt,
+		"reject_reason": withdrawal.RejectReason,
+		"reviewed_by":   withdrawal.ReviewedBy,
+		"reviewed_at":   withdrawal.ReviewedAt,
+		"payout_ref":    withdrawal.PayoutRef,
+		"paid_by":       withdrawal.PaidBy,
+		"paid_at":       withdrawal.PaidAt,
+		"released_at":   withdrawal.ReleasedAt,
+	}}
+
+	result, err := r.Collection.UpdateOne(ctx, bson.M{"_id": withdrawal.ID, "status": fromStatus}, update)
+	if err != nil {
+		return err
+	}
+
+	if result.MatchedCount == 0 {
+		return domain.ErrWithdrawalStatusChanged
+	}
+
+	return nil
+}
+
+func (r *withdrawalRepository) InsertLedger(ctx context.Context, ledger *domain.WithdrawalLedger) error {
+	_, err := r.DB.Collection(ledgerCollectionName).InsertOne(ctx, ledger)
+	return err
+}
+
+func (r *withdrawalRepository) FindLedger(ctx context.Context, withdrawalID primitive.ObjectID) ([]domain.WithdrawalLedger, error) {
+	var ledger []domain.WithdrawalLedger
+
+	cursor, err := r.DB.Collection(ledgerCollectionName).Find(ctx, bson.M{"withdrawal_id": withdrawalID}, options.Find().SetSort(bson.M{"created_at": 1}))
+	if err != nil {
+		return ledger, err
+	}
+
+	err = cursor.All(ctx, &ledger)
+	if err != nil {
+		return ledger, err
+	}
+
+	return ledger, nil
+}
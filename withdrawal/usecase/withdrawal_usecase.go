@@ -0,0 +1,579 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+	"warunk-bem/cache"
+	"warunk-bem/domain"
+	"warunk-bem/dtos"
+	"warunk-bem/helpers"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type withdrawalUsecase struct {
+	WithdrawalRepo    domain.WithdrawalRepository
+	UserRepo          domain.UserRepository
+	UserAmountRepo    domain.UserAmountRepository
+	PinUsecase        domain.PinUsecase
+	NotifikasiUsecase domain.NotifikasiUsecase
+	Cache             domain.Cache
+	minAmount         float64
+	expiry            time.Duration
+	contextTimeout    time.Duration
+}
+
+func NewWithdrawalUsecase(WithdrawalRepo domain.WithdrawalRepository, UserRepo domain.UserRepository, UserAmountRepo domain.UserAmountRepository, PinUsecase domain.PinUsecase, NotifikasiUsecase domain.NotifikasiUsecase, Cache domain.Cache, minAmount float64, expiry time.Duration, contextTimeout time.Duration) domain.WithdrawalUsecase {
+	return &withdrawalUsecase{
+		WithdrawalRepo:    WithdrawalRepo,
+		UserRepo:          UserRepo,
+		UserAmountRepo:    UserAmountRepo,
+		PinUsecase:        PinUsecase,
+		NotifikasiUsecase: NotifikasiUsecase,
+		Cache:             Cache,
+		minAmount:         minAmount,
+		expiry:            expiry,
+		contextTimeout:    contextTimeout,
+	}
+}
+
+func toWithdrawalResponse(withdrawal *domain.Withdrawal, user *domain.User) *dtos.WithdrawalResponse {
+	res := &dtos.WithdrawalResponse{
+		ID:            withdrawal.ID.Hex(),
+		CreatedAt:     withdrawal.CreatedAt,
+		UserID:        withdrawal.UserID.Hex(),
+		Amount:        withdrawal.Amount,
+		Method:        withdrawal.Method,
+		BankName:      withdrawal.BankName,
+		AccountNumber: withdrawal.AccountNumber,
+		AccountName:   withdrawal.AccountName,
+		Note:          withdrawal.Note,
+		Status:        withdrawal.Status,
+		ExpiresAt:     withdrawal.ExpiresAt,
+		RejectReason:  withdrawal.RejectReason,
+		ReviewedAt:    withdrawal.ReviewedAt,
+		PayoutRef:     withdrawal.PayoutRef,
+		PaidAt:        withdrawal.PaidAt,
+		ReleasedAt:    withdrawal.ReleasedAt,
+	}
+
+	if user != nil {
+		res.Name = user.Name
+		res.Email = user.Email
+	}
+
+	return res
+}
+
+// RequestWithdrawal godoc
+// @Summary      Request Withdrawal
+// @Description  Request to withdraw saldo as cash or bank transfer. The amount is held from the saldo until a treasurer pays it out, and released if the request is rejected, cancelled or expires
+// @Tags         User - Withdrawal
+// @Accept       json
+// @Produce      json
+// @Param        request body dtos.WithdrawalRequest true "Payload Body [RAW]"
+// @Success      201 {object} dtos.WithdrawalCreatedResponse
+// @Failure      400 {object} dtos.BadRequestResponse
+// @Failure      401 {object} dtos.UnauthorizedResponse
+// @Failure      403 {object} dtos.ForbiddenResponse
+// @Failure      500 {object} dtos.InternalServerErrorResponse
+// @Router       /withdrawal [post]
+// @Security BearerAuth
+func (wu *withdrawalUsecase) Request(c context.Context, userID string, req *dtos.WithdrawalRequest) (*dtos.WithdrawalResponse, error) {
+	ctx, cancel := context.WithTimeout(c, wu.contextTimeout)
+	defer cancel()
+
+	if req.Amount < wu.minAmount {
+		return nil, fmt.Errorf("minimal penarikan saldo Rp%s", helpers.FormatRupiah(wu.minAmount))
+	}
+
+	user, err := wu.UserRepo.FindOne(ctx, userID)
+	if err != nil {
+		return nil, errors.New("user tidak ditemukan")
+	}
+
+	// PIN wajib untuk penarikan jika user sudah mengatur PIN
+	err = wu.PinUsecase.Confirm(ctx, userID, req.Pin, req.Password)
+	if err != nil {
+		return nil, err
+	}
+
+	open, err := wu.WithdrawalRepo.CountOpenByUser(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	if open > 0 {
+		return nil, domain.ErrWithdrawalOpenExists
+	}
+
+	saldo, err := wu.UserAmountRepo.DecrementAmount(ctx, userID, req.Amount)
+	if err != nil {
+		return nil, errors.New("saldo tidak mencukupi")
+	}
+
+	now := time.Now()
+	withdrawal := &domain.Withdrawal{
+		ID:        primitive.NewObjectID(),
+		CreatedAt: now,
+		UpdatedAt: now,
+		UserID:    user.ID,
+		Amount:    req.Amount,
+		Method:    req.Method,
+		Note:      req.Note,
+		Status:    domain.WithdrawalStatusPending,
+		ExpiresAt: now.Add(wu.expiry),
+	}
+
+	if req.Method == domain.WithdrawalMethodTransfer {
+		withdrawal.BankName = strings.TrimSpace(req.BankName)
+		withdrawal.AccountNumber = strings.TrimSpace(req.AccountNumber)
+		withdrawal.AccountName = strings.TrimSpace(req.AccountName)
+	}
+
+	// Pengecekan di atas bisa lolos bersamaan, unique index pada InsertOne yang menjadi penentu
+	_, err = wu.WithdrawalRepo.InsertOne(ctx, withdrawal)
+	if err != nil {
+		if _, refundErr := wu.UserAmountRepo.IncrementAmount(ctx, userID, req.Amount); refundErr != nil {
+			log.Println("cannot refund withdrawal hold: ", refundErr.Error())
+		}
+		if errors.Is(err, domain.ErrWithdrawalOpenExists) {
+			return nil, err
+		}
+		return nil, errors.New("tidak dapat membuat pengajuan penarikan saldo")
+	}
+
+	balance := saldo.Amount
+	wu.recordLedger(ctx, withdrawal, domain.WithdrawalLedgerHold, -withdrawal.Amount, &balance, user.ID, "saldo ditahan")
+
+	cache.InvalidateUser(ctx, wu.Cache, userID)
+
+	return toWithdrawalResponse(withdrawal, user), nil
+}
+
+// GetMyWithdrawal godoc
+// @Summary      Get My Withdrawal
+// @Description  Get withdrawal requests of the logged in user, newest first
+// @Tags         User - Withdrawal
+// @Accept       json
+// @Produce      json
+// @Param        rp query int false "rp"
+// @Param        p query int false "p"
+// @Success      200 {object} dtos.WithdrawalsOKResponse
+// @Failure      400 {object} dtos.BadRequestResponse
+// @Failure      401 {object} dtos.UnauthorizedResponse
+// @Failure      403 {object} dtos.ForbiddenResponse
+// @Failure      500 {object} dtos.InternalServerErrorResponse
+// @Router       /withdrawal/me [get]
+// @Security BearerAuth
+func (wu *withdrawalUsecase) GetMine(c context.Context, userID string, rp int64, p int64) ([]*dtos.WithdrawalResponse, int64, error) {
+	res := []*dtos.WithdrawalResponse{}
+
+	ctx, cancel := context.WithTimeout(c, wu.contextTimeout)
+	defer cancel()
+
+	userHex, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return res, 0, err
+	}
+
+	withdrawals, count, err := wu.WithdrawalRepo.GetAllWithPage(ctx, rp, p, bson.M{"user_id": userHex}, bson.M{"created_at": -1})
+	if err != nil {
+		return res, 0, err
+	}
+
+	for i := range withdrawals {
+		res = append(res, toWithdrawalResponse(&withdrawals[i], nil))
+	}
+
+	return res, count, nil
+}
+
+// CancelWithdrawal godoc
+// @Summary      Cancel Withdrawal
+// @Description  Cancel a pending withdrawal request and release the held saldo
+// @Tags         User - Withdrawal
+// @Accept       json
+// @Produce      json
+// @Param id path string true "ID Withdrawal"
+// @Success      200 {object} dtos.WithdrawalOKResponse
+// @Failure      400 {object} dtos.BadRequestResponse
+// @Failure      401 {object} dtos.UnauthorizedResponse
+// @Failure      403 {object} dtos.ForbiddenResponse
+// @Failure      404 {object} dtos.NotFoundResponse
+// @Failure      500 {object} dtos.InternalServerErrorResponse
+// @Router       /withdrawal/{id}/cancel [put]
+// @Security BearerAuth
+func (wu *withdrawalUsecase) Cancel(c context.Context, id string, userID string) (*dtos.WithdrawalResponse, error) {
+	ctx, cancel := context.WithTimeout(c, wu.contextTimeout)
+	defer cancel()
+
+	withdrawal, err := wu.WithdrawalRepo.FindOne(ctx, id)
+	if err != nil || withdrawal.UserID.Hex() != userID {
+		return nil, errors.New("pengajuan penarikan saldo tidak ditemukan")
+	}
+
+	if withdrawal.Status != domain.WithdrawalStatusPending {
+		return nil, errors.New("hanya pengajuan pending yang bisa dibatalkan")
+	}
+
+	err = wu.release(ctx, withdrawal, domain.WithdrawalStatusCancelled, withdrawal.UserID, "dibatalkan user")
+	if err != nil {
+		return nil, err
+	}
+
+	return toWithdrawalResponse(withdrawal, nil), nil
+}
+
+// GetWithdrawal godoc
+// @Summary      Get Withdrawal
+// @Description  Withdrawal queue for treasurers, oldest first
+// @Tags         Admin - Withdrawal
+// @Accept       json
+// @Produce      json
+// @Param        rp query int false "rp"
+// @Param        p query int false "p"
+// @Param        status query string false "pending (default), approved, paid, rejected, cancelled or expired"
+// @Success      200 {object} dtos.WithdrawalsOKResponse
+// @Failure      400 {object} dtos.BadRequestResponse
+// @Failure      401 {object} dtos.UnauthorizedResponse
+// @Failure      403 {object} dtos.ForbiddenResponse
+// @Failure      500 {object} dtos.InternalServerErrorResponse
+// @Router       /withdrawal [get]
+// @Security BearerAuth
+func (wu *withdrawalUsecase) GetAll(c context.Context, rp int64, p int64, filter interface{}) ([]*dtos.WithdrawalResponse, int64, error) {
+	res := []*dtos.WithdrawalResponse{}
+
+	ctx, cancel := context.WithTimeout(c, wu.contextTimeout)
+	defer cancel()
+
+	withdrawals, count, err := wu.WithdrawalRepo.GetAllWithPage(ctx, rp, p, filter, bson.M{"created_at": 1})
+	if err != nil {
+		return res, 0, err
+	}
+
+	users := make(map[primitive.ObjectID]*domain.User)
+	for i := range withdrawals {
+		user, ok := users[withdrawals[i].UserID]
+		if !ok {
+			user, err = wu.UserRepo.FindOne(ctx, withdrawals[i].UserID.Hex())
+			if err != nil {
+				user = nil
+			}
+			users[withdrawals[i].UserID] = user
+		}
+
+		res = append(res, toWithdrawalResponse(&withdrawals[i], user))
+	}
+
+	return res, count, nil
+}
+
+// ApproveWithdrawal godoc
+// @Summary      Approve Withdrawal
+// @Description  Approve a pending withdrawal request. The saldo stays held until the payout is confirmed or the request is rejected, approved requests never expire
+// @Tags         Admin - Withdrawal
+// @Accept       json
+// @Produce      json
+// @Param id path string true "ID Withdrawal"
+// @Success      200 {object} dtos.WithdrawalOKResponse
+// @Failure      400 {object} dtos.BadRequestResponse
+// @Failure      401 {object} dtos.UnauthorizedResponse
+// @Failure      403 {object} dtos.ForbiddenResponse
+// @Failure      404 {object} dtos.NotFoundResponse
+// @Failure      500 {object} dtos.InternalServerErrorResponse
+// @Router       /withdrawal/{id}/approve [put]
+// @Security BearerAuth
+func (wu *withdrawalUsecase) Approve(c context.Context, id string, adminID string) (*dtos.WithdrawalResponse, error) {
+	ctx, cancel := context.WithTimeout(c, wu.contextTimeout)
+	defer cancel()
+
+	withdrawal, adminHex, err := wu.findWithdrawal(ctx, id, adminID, domain.WithdrawalStatusPending)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	withdrawal.Status = domain.WithdrawalStatusApproved
+	withdrawal.ReviewedBy = adminHex
+	withdrawal.ReviewedAt = &now
+
+	err = wu.WithdrawalRepo.UpdateStatus(ctx, withdrawal, domain.WithdrawalStatusPending)
+	if err != nil {
+		return nil, err
+	}
+
+	wu.recordLedger(ctx, withdrawal, domain.WithdrawalLedgerApprove, 0, nil, adminHex, "disetujui bendahara")
+
+	helpers.NotifyAsync(wu.NotifikasiUsecase, "withdrawal", &domain.Notifikasi{
+		UserID:      withdrawal.UserID,
+		Type:        domain.NotifikasiTypeWithdrawal,
+		Title:       "Penarikan saldo disetujui",
+		Message:     fmt.Sprintf("Penarikan saldo sebesar Rp%s disetujui dan sedang diproses", helpers.FormatRupiah(withdrawal.Amount)),
+		ReferenceID: withdrawal.ID,
+	})
+
+	return toWithdrawalResponse(withdrawal, nil), nil
+}
+
+// RejectWithdrawal godoc
+// @Summary      Reject Withdrawal
+// @Description  Reject a pending or approved withdrawal request with a reason and release the held saldo
+// @Tags         Admin - Withdrawal
+// @Accept       json
+// @Produce      json
+// @Param id path string true "ID Withdrawal"
+// @Param        request body dtos.RejectWithdrawalRequest true "Payload Body [RAW]"
+// @Success      200 {object} dtos.WithdrawalOKResponse
+// @Failure      400 {object} dtos.BadRequestResponse
+// @Failure      401 {object} dtos.UnauthorizedResponse
+// @Failure      403 {object} dtos.ForbiddenResponse
+// @Failure      404 {object} dtos.NotFoundResponse
+// @Failure      500 {object} dtos.InternalServerErrorResponse
+// @Router       /withdrawal/{id}/reject [put]
+// @Security BearerAuth
+func (wu *withdrawalUsecase) Reject(c context.Context, id string, adminID string, req *dtos.RejectWithdrawalRequest) (*dtos.WithdrawalResponse, error) {
+	ctx, cancel := context.WithTimeout(c, wu.contextTimeout)
+	defer cancel()
+
+	withdrawal, adminHex, err := wu.findWithdrawal(ctx, id, adminID, domain.WithdrawalStatusPending, domain.WithdrawalStatusApproved)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	withdrawal.RejectReason = req.Reason
+	withdrawal.ReviewedBy = adminHex
+	withdrawal.ReviewedAt = &now
+
+	err = wu.release(ctx, withdrawal, domain.WithdrawalStatusRejected, adminHex, "ditolak: "+req.Reason)
+	if err != nil {
+		return nil, err
+	}
+
+	return toWithdrawalResponse(withdrawal, nil), nil
+}
+
+// PayoutWithdrawal godoc
+// @Summary      Confirm Withdrawal Payout
+// @Description  Confirm that an approved withdrawal has been paid out in cash or by transfer
+// @Tags         Admin - Withdrawal
+// @Accept       json
+// @Produce      json
+// @Param id path string true "ID Withdrawal"
+// @Param        request body dtos.PayoutWithdrawalRequest true "Payload Body [RAW]"
+// @Success      200 {object} dtos.WithdrawalOKResponse
+// @Failure      400 {object} dtos.BadRequestResponse
+// @Failure      401 {object} dtos.UnauthorizedResponse
+// @Failure      403 {object} dtos.ForbiddenResponse
+// @Failure      404 {object} dtos.NotFoundResponse
+// @Failure      500 {object} dtos.InternalServerErrorResponse
+// @Router       /withdrawal/{id}/paid [put]
+// @Security BearerAuth
+func (wu *withdrawalUsecase) MarkPaid(c context.Context, id string, adminID string, req *dtos.PayoutWithdrawalRequest) (*dtos.WithdrawalResponse, error) {
+	ctx, cancel := context.WithTimeout(c, wu.contextTimeout)
+	defer cancel()
+
+	withdrawal, adminHex, err := wu.findWithdrawal(ctx, id, adminID, domain.WithdrawalStatusApproved)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	withdrawal.Status = domain.WithdrawalStatusPaid
+	withdrawal.PayoutRef = strings.TrimSpace(req.PayoutRef)
+	withdrawal.PaidBy = adminHex
+	withdrawal.PaidAt = &now
+
+	err = wu.WithdrawalRepo.UpdateStatus(ctx, withdrawal, domain.WithdrawalStatusApproved)
+	if err != nil {
+		return nil, err
+	}
+
+	note := fmt.Sprintf("dicairkan Rp%s", helpers.FormatRupiah(withdrawal.Amount))
+	if withdrawal.PayoutRef != "" {
+		note += " (" + withdrawal.PayoutRef + ")"
+	}
+	wu.recordLedger(ctx, withdrawal, domain.WithdrawalLedgerPayout, 0, nil, adminHex, note)
+
+	helpers.NotifyAsync(wu.NotifikasiUsecase, "withdrawal", &domain.Notifikasi{
+		UserID:      withdrawal.UserID,
+		Type:        domain.NotifikasiTypeWithdrawal,
+		Title:       "Penarikan saldo dicairkan",
+		Message:     fmt.Sprintf("Penarikan saldo sebesar Rp%s sudah dicairkan", helpers.FormatRupiah(withdrawal.Amount)),
+		ReferenceID: withdrawal.ID,
+	})
+
+	return toWithdrawalResponse(withdrawal, nil), nil
+}
+
+// GetWithdrawalLedger godoc
+// @Summary      Get Withdrawal Ledger
+// @Description  Get every step recorded for a withdrawal request, oldest first
+// @Tags         Admin - Withdrawal
+// @Accept       json
+// @Produce      json
+// @Param id path string true "ID Withdrawal"
+// @Success      200 {object} dtos.WithdrawalLedgerOKResponse
+// @Failure      400 {object} dtos.BadRequestResponse
+// @Failure      401 {object} dtos.UnauthorizedResponse
+// @Failure      403 {object} dtos.ForbiddenResponse
+// @Failure      404 {object} dtos.NotFoundResponse
+// @Failure      500 {object} dtos.InternalServerErrorResponse
+// @Router       /withdrawal/{id}/ledger [get]
+// @Security BearerAuth
+func (wu *withdrawalUsecase) GetLedger(c context.Context, id string) ([]dtos.WithdrawalLedgerResponse, error) {
+	ctx, cancel := context.WithTimeout(c, wu.contextTimeout)
+	defer cancel()
+
+	withdrawal, err := wu.WithdrawalRepo.FindOne(ctx, id)
+	if err != nil {
+		return nil, errors.New("pengajuan penarikan saldo tidak ditemukan")
+	}
+
+	ledger, err := wu.WithdrawalRepo.FindLedger(ctx, withdrawal.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]dtos.WithdrawalLedgerResponse, 0, len(ledger))
+	for _, entry := range ledger {
+		item := dtos.WithdrawalLedgerResponse{
+			CreatedAt:    entry.CreatedAt,
+			Type:         entry.Type,
+			Amount:       entry.Amount,
+			BalanceAfter: entry.BalanceAfter,
+			Note:         entry.Note,
+		}
+
+		if !entry.ActorID.IsZero() {
+			item.ActorID = entry.ActorID.Hex()
+		}
+
+		res = append(res, item)
+	}
+
+	return res, nil
+}
+
+func (wu *withdrawalUsecase) ExpirePending(c context.Context) (int64, error) {
+	ctx, cancel := context.WithTimeout(c, wu.contextTimeout)
+	withdrawals, err := wu.WithdrawalRepo.FindExpired(ctx, time.Now())
+	cancel()
+	if err != nil {
+		return 0, err
+	}
+
+	var expired int64
+	for i := range withdrawals {
+		ctx, cancel := context.WithTimeout(c, wu.contextTimeout)
+		err = wu.release(ctx, &withdrawals[i], domain.WithdrawalStatusExpired, primitive.NilObjectID, "melewati batas waktu")
+		cancel()
+		if errors.Is(err, domain.ErrWithdrawalStatusChanged) {
+			continue
+		}
+		if err != nil {
+			log.Println("cannot expire withdrawal "+withdrawals[i].ID.Hex()+": ", err.Error())
+			continue
+		}
+
+		expired++
+	}
+
+	return expired, nil
+}
+
+func (wu *withdrawalUsecase) findWithdrawal(ctx context.Context, id string, adminID string, statuses ...string) (*domain.Withdrawal, primitive.ObjectID, error) {
+	adminHex, err := primitive.ObjectIDFromHex(adminID)
+	if err != nil {
+		return nil, adminHex, errors.New("admin tidak ditemukan")
+	}
+
+	withdrawal, err := wu.WithdrawalRepo.FindOne(ctx, id)
+	if err != nil {
+		return nil, adminHex, errors.New("pengajuan penarikan saldo tidak ditemukan")
+	}
+
+	for _, status := range statuses {
+		if withdrawal.Status == status {
+			return withdrawal, adminHex, nil
+		}
+	}
+
+	return nil, adminHex, errors.New("pengajuan penarikan saldo sudah diproses")
+}
+
+// release mengunci status lebih dulu lalu mengembalikan saldo yang ditahan,
+// status dikembalikan seperti semula jika saldo gagal dikembalikan
+func (wu *withdrawalUsecase) release(ctx context.Context, withdrawal *domain.Withdrawal, status string, actorID primitive.ObjectID, note string) error {
+	previous := *withdrawal
+
+	now := time.Now()
+	withdrawal.Status = status
+	withdrawal.ReleasedAt = &now
+
+	err := wu.WithdrawalRepo.UpdateStatus(ctx, withdrawal, previous.Status)
+	if err != nil {
+		return err
+	}
+
+	saldo, err := wu.UserAmountRepo.IncrementAmount(ctx, withdrawal.UserID.Hex(), withdrawal.Amount)
+	if err != nil {
+		*withdrawal = previous
+		if rollbackErr := wu.WithdrawalRepo.UpdateStatus(ctx, withdrawal, status); rollbackErr != nil {
+			log.Println("cannot rollback withdrawal status: ", rollbackErr.Error())
+		}
+		return errors.New("tidak dapat mengembalikan saldo")
+	}
+
+	balance := saldo.Amount
+	wu.recordLedger(ctx, withdrawal, domain.WithdrawalLedgerRelease, withdrawal.Amount, &balance, actorID, note)
+
+	cache.InvalidateUser(ctx, wu.Cache, withdrawal.UserID.Hex())
+
+	helpers.NotifyAsync(wu.NotifikasiUsecase, "withdrawal", &domain.Notifikasi{
+		UserID:      withdrawal.UserID,
+		Type:        domain.NotifikasiTypeWithdrawal,
+		Title:       "Penarikan saldo " + withdrawalStatusLabel(status),
+		Message:     fmt.Sprintf("Penarikan saldo sebesar Rp%s %s (%s). Saldo sudah dikembalikan.", helpers.FormatRupiah(withdrawal.Amount), withdrawalStatusLabel(status), note),
+		ReferenceID: withdrawal.ID,
+	})
+
+	return nil
+}
+
+func withdrawalStatusLabel(status string) string {
+	switch status {
+	case domain.WithdrawalStatusRejected:
+		return "ditolak"
+	case domain.WithdrawalStatusCancelled:
+		return "dibatalkan"
+	case domain.WithdrawalStatusExpired:
+		return "kedaluwarsa"
+	default:
+		return status
+	}
+}
+
+// recordLedger mencatat langkah penarikan, saldo sudah berubah sehingga kegagalan hanya dicatat di log
+func (wu *withdrawalUsecase) recordLedger(ctx context.Context, withdrawal *domain.Withdrawal, ledgerType string, amount float64, balanceAfter *float64, actorID primitive.ObjectID, note string) {
+	err := wu.WithdrawalRepo.InsertLedger(ctx, &domain.WithdrawalLedger{
+		ID:           primitive.NewObjectID(),
+		CreatedAt:    time.Now(),
+		WithdrawalID: withdrawal.ID,
+		UserID:       withdrawal.UserID,
+		Type:         ledgerType,
+		Amount:       amount,
+		BalanceAfter: balanceAfter,
+		ActorID:      actorID,
+		Note:         note,
+	})
+	if err != nil {
+		log.Println("cannot record withdrawal ledger: ", err.Error())
+	}
+}
@@ -0,0 +1,351 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+	"warunk-bem/cache"
+	"warunk-bem/domain"
+	"warunk-bem/dtos"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// mockWithdrawalRepo menyimpan pengajuan di memori, UpdateStatus meniru filter status di repository
+// dan InsertOne meniru unique index pengajuan terbuka per user
+type mockWithdrawalRepo struct {
+	domain.WithdrawalRepository
+
+	mu          sync.Mutex
+	withdrawals map[string]domain.Withdrawal
+	open        int64
+	insertErr   error
+	// stale meniru job kedaluwarsa yang membaca pengajuan sebelum bendahara memprosesnya
+	stale  bool
+	ledger []string
+}
+
+func (m *mockWithdrawalRepo) InsertOne(ctx context.Context, req *domain.Withdrawal) (*domain.Withdrawal, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.insertErr != nil {
+		return nil, m.insertErr
+	}
+	for _, withdrawal := range m.withdrawals {
+		open := withdrawal.Status == domain.WithdrawalStatusPending || withdrawal.Status == domain.WithdrawalStatusApproved
+		if open && withdrawal.UserID == req.UserID {
+			return nil, domain.ErrWithdrawalOpenExists
+		}
+	}
+	m.withdrawals[req.ID.Hex()] = *req
+	return req, nil
+}
+
+func (m *mockWithdrawalRepo) FindOne(ctx context.Context, id string) (*domain.Withdrawal, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	withdrawal, ok := m.withdrawals[id]
+	if !ok {
+		return nil, errors.New("not found")
+	}
+	return &withdrawal, nil
+}
+
+func (m *mockWithdrawalRepo) CountOpenByUser(ctx context.Context, userID primitive.ObjectID) (int64, error) {
+	return m.open, nil
+}
+
+func (m *mockWithdrawalRepo) FindExpired(ctx context.Context, now time.Time) ([]domain.Withdrawal, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var res []domain.Withdrawal
+	for _, withdrawal := range m.withdrawals {
+		if m.stale {
+			withdrawal.Status = domain.WithdrawalStatusPending
+		}
+		if withdrawal.Status == domain.WithdrawalStatusPending && withdrawal.ExpiresAt.Before(now) {
+			res = append(res, withdrawal)
+		}
+	}
+	return res, nil
+}
+
+func (m *mockWithdrawalRepo) UpdateStatus(ctx context.Context, withdrawal *domain.Withdrawal, fromStatus string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.withdrawals[withdrawal.ID.Hex()].Status != fromStatus {
+		return domain.ErrWithdrawalStatusChanged
+	}
+	m.withdrawals[withdrawal.ID.Hex()] = *withdrawal
+	return nil
+}
+
+func (m *mockWithdrawalRepo) InsertLedger(ctx context.Context, ledger *domain.WithdrawalLedger) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.ledger = append(m.ledger, ledger.Type)
+	return nil
+}
+
+type mockUserRepo struct {
+	domain.UserRepository
+
+	user *domain.User
+}
+
+func (m *mockUserRepo) FindOne(ctx context.Context, id string) (*domain.User, error) {
+	if m.user.ID.Hex() != id {
+		return nil, errors.New("not found")
+	}
+	return m.user, nil
+}
+
+// mockUserAmountRepo meniru IncrementAmount yang menolak saldo negatif
+type mockUserAmountRepo struct {
+	domain.UserAmountRepository
+
+	mu        sync.Mutex
+	saldo     float64
+	refundErr error
+	refunds   int
+}
+
+func (m *mockUserAmountRepo) IncrementAmount(ctx context.Context, userID string, delta float64) (*domain.UserAmount, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if delta > 0 {
+		if m.refundErr != nil {
+			return nil, m.refundErr
+		}
+		m.refunds++
+	}
+	if m.saldo+delta < 0 {
+		return nil, errors.New("saldo tidak cukup")
+	}
+	m.saldo += delta
+	return &domain.UserAmount{Amount: m.saldo}, nil
+}
+
+func (m *mockUserAmountRepo) DecrementAmount(ctx context.Context, userID string, amount float64) (*domain.UserAmount, error) {
+	if amount < 0 {
+		return nil, domain.ErrNegativeDebit
+	}
+	return m.IncrementAmount(ctx, userID, -amount)
+}
+
+type mockPinUsecase struct {
+	domain.PinUsecase
+
+	err error
+}
+
+func (m *mockPinUsecase) Confirm(ctx context.Context, userID string, pin string, password string) error {
+	return m.err
+}
+
+const (
+	testMinAmount = 10000
+	testExpiry    = 72 * time.Hour
+)
+
+func TestRequest(t *testing.T) {
+	user := &domain.User{ID: primitive.NewObjectID(), Name: "Pemilik"}
+
+	tests := []struct {
+		name       string
+		amount     float64
+		saldo      float64
+		pinErr     error
+		open       int64
+		insertErr  error
+		wantErr    bool
+		wantSaldo  float64
+		wantLedger []string
+	}{
+		{name: "saldo is held", amount: 20000, saldo: 50000, wantSaldo: 30000, wantLedger: []string{domain.WithdrawalLedgerHold}},
+		{name: "below minimum", amount: 5000, saldo: 50000, wantErr: true, wantSaldo: 50000},
+		{name: "wrong pin", amount: 20000, saldo: 50000, pinErr: errors.New("PIN salah"), wantErr: true, wantSaldo: 50000},
+		{name: "open request exists", amount: 20000, saldo: 50000, open: 1, wantErr: true, wantSaldo: 50000},
+		{name: "insufficient saldo", amount: 20000, saldo: 10000, wantErr: true, wantSaldo: 10000},
+		{name: "insert failure refunds hold", amount: 20000, saldo: 50000, insertErr: errors.New("mongo down"), wantErr: true, wantSaldo: 50000},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &mockWithdrawalRepo{withdrawals: map[string]domain.Withdrawal{}, open: tt.open, insertErr: tt.insertErr}
+			userAmountRepo := &mockUserAmountRepo{saldo: tt.saldo}
+			wu := NewWithdrawalUsecase(repo, &mockUserRepo{user: user}, userAmountRepo, &mockPinUsecase{err: tt.pinErr}, nil, cache.NewMemoryCache(), testMinAmount, testExpiry, time.Second)
+
+			_, err := wu.Request(context.Background(), user.ID.Hex(), &dtos.WithdrawalRequest{Amount: tt.amount, Method: domain.WithdrawalMethodCash})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if userAmountRepo.saldo != tt.wantSaldo {
+				t.Errorf("saldo = %.0f, want %.0f", userAmountRepo.saldo, tt.wantSaldo)
+			}
+			if len(repo.ledger) != len(tt.wantLedger) {
+				t.Errorf("ledger = %v, want %v", repo.ledger, tt.wantLedger)
+			}
+		})
+	}
+}
+
+func TestRequestConcurrent(t *testing.T) {
+	user := &domain.User{ID: primitive.NewObjectID()}
+	// CountOpenByUser selalu 0 seperti pengajuan bersamaan yang membaca sebelum ada yang tersimpan
+	repo := &mockWithdrawalRepo{withdrawals: map[string]domain.Withdrawal{}}
+	userAmountRepo := &mockUserAmountRepo{saldo: 100000}
+	wu := NewWithdrawalUsecase(repo, &mockUserRepo{user: user}, userAmountRepo, &mockPinUsecase{}, nil, cache.NewMemoryCache(), testMinAmount, testExpiry, time.Second)
+
+	var (
+		wg        sync.WaitGroup
+		mu        sync.Mutex
+		succeeded int
+		rejected  int
+	)
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := wu.Request(context.Background(), user.ID.Hex(), &dtos.WithdrawalRequest{Amount: 20000, Method: domain.WithdrawalMethodCash})
+
+			mu.Lock()
+			defer mu.Unlock()
+			switch {
+			case err == nil:
+				succeeded++
+			case errors.Is(err, domain.ErrWithdrawalOpenExists):
+				rejected++
+			}
+		}()
+	}
+	wg.Wait()
+
+	if succeeded != 1 || rejected != 9 {
+		t.Fatalf("succeeded = %d, rejected = %d, want 1 and 9", succeeded, rejected)
+	}
+	if len(repo.withdrawals) != 1 {
+		t.Fatalf("stored %d withdrawals, want 1", len(repo.withdrawals))
+	}
+	if userAmountRepo.saldo != 80000 {
+		t.Fatalf("saldo = %.0f, want 80000 after refunding rejected holds", userAmountRepo.saldo)
+	}
+}
+
+func TestRelease(t *testing.T) {
+	user := &domain.User{ID: primitive.NewObjectID()}
+	adminID := primitive.NewObjectID().Hex()
+
+	tests := []struct {
+		name        string
+		status      string
+		expired     bool
+		stale       bool
+		refundErr   error
+		action      string
+		wantErr     bool
+		wantRefunds int
+		wantStatus  string
+	}{
+		{name: "reject pending", status: domain.WithdrawalStatusPending, action: "reject", wantRefunds: 1, wantStatus: domain.WithdrawalStatusRejected},
+		{name: "reject approved", status: domain.WithdrawalStatusApproved, action: "reject", wantRefunds: 1, wantStatus: domain.WithdrawalStatusRejected},
+		{name: "reject paid", status: domain.WithdrawalStatusPaid, action: "reject", wantErr: true, wantStatus: domain.WithdrawalStatusPaid},
+		{name: "reject already rejected", status: domain.WithdrawalStatusRejected, action: "reject", wantErr: true, wantStatus: domain.WithdrawalStatusRejected},
+		{name: "cancel pending", status: domain.WithdrawalStatusPending, action: "cancel", wantRefunds: 1, wantStatus: domain.WithdrawalStatusCancelled},
+		{name: "cancel approved", status: domain.WithdrawalStatusApproved, action: "cancel", wantErr: true, wantStatus: domain.WithdrawalStatusApproved},
+		{name: "paid keeps saldo held", status: domain.WithdrawalStatusApproved, action: "paid", wantStatus: domain.WithdrawalStatusPaid},
+		{name: "expire pending", status: domain.WithdrawalStatusPending, expired: true, action: "expire", wantRefunds: 1, wantStatus: domain.WithdrawalStatusExpired},
+		{name: "expire skips request approved after read", status: domain.WithdrawalStatusApproved, expired: true, stale: true, action: "expire", wantStatus: domain.WithdrawalStatusApproved},
+		{name: "refund failure restores status", status: domain.WithdrawalStatusApproved, refundErr: errors.New("mongo down"), action: "reject", wantErr: true, wantStatus: domain.WithdrawalStatusApproved},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			withdrawal := newWithdrawal(user.ID, tt.status, tt.expired)
+			repo := &mockWithdrawalRepo{withdrawals: map[string]domain.Withdrawal{withdrawal.ID.Hex(): withdrawal}, stale: tt.stale}
+			userAmountRepo := &mockUserAmountRepo{refundErr: tt.refundErr}
+			wu := NewWithdrawalUsecase(repo, &mockUserRepo{user: user}, userAmountRepo, &mockPinUsecase{}, nil, cache.NewMemoryCache(), testMinAmount, testExpiry, time.Second)
+
+			var err error
+			switch tt.action {
+			case "reject":
+				_, err = wu.Reject(context.Background(), withdrawal.ID.Hex(), adminID, &dtos.RejectWithdrawalRequest{Reason: "rekening salah"})
+			case "cancel":
+				_, err = wu.Cancel(context.Background(), withdrawal.ID.Hex(), user.ID.Hex())
+			case "paid":
+				_, err = wu.MarkPaid(context.Background(), withdrawal.ID.Hex(), adminID, &dtos.PayoutWithdrawalRequest{})
+			case "expire":
+				_, err = wu.ExpirePending(context.Background())
+			default:
+				t.Fatalf("unknown action %s", tt.action)
+			}
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if userAmountRepo.refunds != tt.wantRefunds {
+				t.Errorf("refunds = %d, want %d", userAmountRepo.refunds, tt.wantRefunds)
+			}
+			if got := repo.withdrawals[withdrawal.ID.Hex()].Status; got != tt.wantStatus {
+				t.Errorf("status = %s, want %s", got, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestReleaseConcurrent(t *testing.T) {
+	user := &domain.User{ID: primitive.NewObjectID()}
+	withdrawal := newWithdrawal(user.ID, domain.WithdrawalStatusPending, true)
+	repo := &mockWithdrawalRepo{withdrawals: map[string]domain.Withdrawal{withdrawal.ID.Hex(): withdrawal}}
+	userAmountRepo := &mockUserAmountRepo{}
+	wu := NewWithdrawalUsecase(repo, &mockUserRepo{user: user}, userAmountRepo, &mockPinUsecase{}, nil, cache.NewMemoryCache(), testMinAmount, testExpiry, time.Second)
+
+	// Bendahara menolak, user membatalkan dan job kedaluwarsa berjalan bersamaan
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(3)
+		go func() {
+			defer wg.Done()
+			_, _ = wu.Reject(context.Background(), withdrawal.ID.Hex(), primitive.NewObjectID().Hex(), &dtos.RejectWithdrawalRequest{Reason: "rekening salah"})
+		}()
+		go func() {
+			defer wg.Done()
+			_, _ = wu.Cancel(context.Background(), withdrawal.ID.Hex(), user.ID.Hex())
+		}()
+		go func() {
+			defer wg.Done()
+			_, _ = wu.ExpirePending(context.Background())
+		}()
+	}
+	wg.Wait()
+
+	if userAmountRepo.refunds != 1 || userAmountRepo.saldo != withdrawal.Amount {
+		t.Errorf("refunds = %d (Rp%.0f), want exactly one refund of Rp%.0f", userAmountRepo.refunds, userAmountRepo.saldo, withdrawal.Amount)
+	}
+}
+
+func newWithdrawal(userID primitive.ObjectID, status string, expired bool) domain.Withdrawal {
+	expiresAt := time.Now().Add(testExpiry)
+	if expired {
+		expiresAt = time.Now().Add(-time.Minute)
+	}
+
+	return domain.Withdrawal{
+		ID:        primitive.NewObjectID(),
+		CreatedAt: time.Now().Add(-testExpiry),
+		UserID:    userID,
+		Amount:    20000,
+		Method:    domain.WithdrawalMethodCash,
+		Status:    status,
+		ExpiresAt: expiresAt,
+	}
+}